@@ -70,6 +70,9 @@ func mainImpl() error {
 	if err != nil {
 		return fmt.Errorf("While summarizing %v into %v: %w", *in, *out, err)
 	}
+	for _, d := range summarize.Diagnose(root) {
+		fmt.Fprintf(os.Stderr, "%v: warning: %v\n", *fir, d)
+	}
 
 	if *suppressEmptyLibrary {
 		emptyLibRoot := fidlgen.Root{Name: root.Name}
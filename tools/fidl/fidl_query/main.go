@@ -0,0 +1,152 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// The program fidl_query selects elements of a set of FIDL libraries matching
+// queries, from their FIDL intermediate representation files.  Please refer to
+// README.md in this directory for more details.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlquery"
+)
+
+type queriesFlag []string
+
+func (q *queriesFlag) String() string {
+	return strings.Join(*q, ", ")
+}
+
+func (q *queriesFlag) Set(value string) error {
+	*q = append(*q, value)
+	return nil
+}
+
+var (
+	queries  queriesFlag
+	explains queriesFlag
+	irList   = flag.String("fidl-ir-list", "", "A file listing FIDL IR files to load, one per line, in addition to those given as arguments.")
+	renames  = flag.String("library-renames", "", "A JSON file mapping old library names to new ones, applied to the loaded IR.")
+	language = flag.String("language", "", "The bindings language for which --explain classifies types, honoring @bindings_denylist.")
+)
+
+func init() {
+	flag.Var(&queries, "query", "A query to run; may be repeated. See README.md for the syntax.")
+	flag.Var(&explains, "explain", "A fully qualified declaration name to explain; may be repeated.")
+}
+
+// usage prints a user-friendly usage message when the flag --help is provided.
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(),
+		`%v selects elements of FIDL libraries matching queries, and prints them as JSON lines.
+Alternatively, it pretty-prints everything known about given declarations.
+
+Usage: %v --query=QUERY... [--fidl-ir-list=FILE] [--library-renames=FILE] [FIDL_IR_FILE...]
+       %v --explain=NAME... [--language=LANGUAGE] [--fidl-ir-list=FILE] [--library-renames=FILE] [FIDL_IR_FILE...]
+`, os.Args[0], os.Args[0], os.Args[0])
+	flag.PrintDefaults()
+}
+
+// result is a single line of output.
+type result struct {
+	Query string `json:"query"`
+	fidlquery.Match
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := mainImpl(); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+func readIRList(filename string) ([]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open file: %v: %w", filename, err)
+	}
+	defer f.Close()
+	var filenames []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			filenames = append(filenames, line)
+		}
+	}
+	return filenames, scanner.Err()
+}
+
+func mainImpl() error {
+	if len(queries) == 0 && len(explains) == 0 {
+		return fmt.Errorf("The flag --query=... or --explain=... is required")
+	}
+	var parsed []fidlquery.Query
+	for _, q := range queries {
+		query, err := fidlquery.Parse(q)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, query)
+	}
+
+	filenames := flag.Args()
+	if *irList != "" {
+		listed, err := readIRList(*irList)
+		if err != nil {
+			return err
+		}
+		filenames = append(filenames, listed...)
+	}
+	if len(filenames) == 0 {
+		return fmt.Errorf("No FIDL IR files given")
+	}
+	var libraryRenames fidlgen.LibraryRenames
+	if *renames != "" {
+		var err error
+		if libraryRenames, err = fidlgen.ReadLibraryRenames(*renames); err != nil {
+			return err
+		}
+	}
+	var roots []fidlgen.Root
+	for _, filename := range filenames {
+		root, err := fidlgen.ReadJSONIr(filename)
+		if err != nil {
+			return err
+		}
+		roots = append(roots, root)
+	}
+	program, err := fidlgen.NewProgramWithRenames(roots, libraryRenames)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	for _, name := range explains {
+		e, err := program.Explain(fidlgen.EncodedCompoundIdentifier(name), *language)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, e.String())
+	}
+	enc := json.NewEncoder(out)
+	for i, query := range parsed {
+		if err := query.Run(program, func(m fidlquery.Match) error {
+			return enc.Encode(result{Query: queries[i], Match: m})
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen/keywords"
 )
 
 // Documented is embedded in structs for declarations that may hold documentation.
@@ -468,12 +469,8 @@ func init() {
 		unionMemberContext, unionMemberTagContext, constantContext,
 		declarationContext, methodContext, bitsMemberContext,
 	} {
-		ctx.ReserveNames([]string{"assert", "async", "await", "break", "case",
-			"catch", "class", "const", "continue", "default", "do", "else",
-			"enum", "extends", "false", "final", "finally", "for", "if", "in",
-			"is", "new", "null", "override", "rethrow", "return", "String",
-			"super", "switch", "this", "throw", "true", "try", "var", "void",
-			"while", "with", "yield"})
+		ctx.ReserveNames(keywords.Reserved(keywords.Dart))
+		ctx.ReserveNames([]string{"override", "String"})
 	}
 
 	constantContext.ReserveNames([]string{"dynamic", "int", "num"})
@@ -536,11 +533,11 @@ var typeForPrimitiveSubtype = map[fidlgen.PrimitiveSubtype]string{
 }
 
 var declForInternalType = map[fidlgen.InternalSubtype]string{
-	fidlgen.TransportErr: "$fidl.TransportErr",
+	fidlgen.FrameworkErr: "$fidl.TransportErr",
 }
 
 var typeForInternalSubtype = map[fidlgen.InternalSubtype]string{
-	fidlgen.TransportErr: "TransportErrType",
+	fidlgen.FrameworkErr: "TransportErrType",
 }
 
 func docStringLink(nameWithBars string) string {
@@ -697,10 +694,10 @@ func (c *compiler) typeExprForMethod(val fidlgen.Method, request []Parameter, re
 		responseSizeV2 = 0
 	)
 	if val.RequestPayload != nil {
-		requestSizeV2 = val.RequestPayload.TypeShapeV2.InlineSize
+		requestSizeV2 = val.RequestPayload.Shape(fidlgen.WireFormatVersionV2).InlineSize
 	}
 	if val.ResponsePayload != nil {
-		responseSizeV2 = val.ResponsePayload.TypeShapeV2.InlineSize
+		responseSizeV2 = val.ResponsePayload.Shape(fidlgen.WireFormatVersionV2).InlineSize
 	}
 
 	// request/response and requestInlineSize/responseInlineSize are null/0 for
@@ -1096,12 +1093,12 @@ func (c *compiler) compileBits(val fidlgen.Bits) Bits {
 }
 
 func (c *compiler) compileMethodResponse(method fidlgen.Method) MethodResponse {
-	if method.HasError || method.HasTransportError() {
+	if method.HasError || method.HasFrameworkError() {
 		response := MethodResponse{
 			WireParameters:    c.compileParameters(method.ResponsePayload),
 			MethodParameters:  c.compileParameters(method.ValueType),
 			HasError:          method.HasError,
-			HasTransportError: method.HasTransportError(),
+			HasTransportError: method.HasFrameworkError(),
 			ResultTypeName:    c.compileUpperCamelCompoundIdentifier(method.ResultType.Identifier.Parse(), "", declarationContext),
 			ResultTypeTagName: c.compileUpperCamelCompoundIdentifier(method.ResultType.Identifier.Parse(), "Tag", declarationContext),
 			ValueType:         c.compileType(*method.ValueType),
@@ -1203,37 +1200,41 @@ func (c *compiler) compileStructMember(val fidlgen.StructMember) StructMember {
 		defaultValue = c.compileConstant(*val.MaybeDefaultValue, &t)
 	}
 
+	offset := val.FieldShape(fidlgen.WireFormatVersionV2).Offset
 	return StructMember{
 		Type:         t,
 		TypeSymbol:   t.typeExpr,
 		Name:         c.compileLowerCamelIdentifier(val.Name, structMemberContext),
 		DefaultValue: defaultValue,
-		OffsetV2:     val.FieldShapeV2.Offset,
+		OffsetV2:     offset,
 		typeExpr: fmt.Sprintf("$fidl.MemberType<%s>(type: %s, offset: %v)",
-			t.Decl, t.typeExpr, val.FieldShapeV2.Offset),
+			t.Decl, t.typeExpr, offset),
 		Documented: docString(val),
 	}
 }
 
+// emptyStructRepresentation gives Dart empty structs a placeholder member, so
+// that they are encoded like a struct with a single uint8 member.
+var emptyStructRepresentation = fidlgen.EmptyStructRepresentation{PlaceholderName: "reserved"}
+
 func (c *compiler) compileStruct(val fidlgen.Struct) Struct {
 	ci := val.Name.Parse()
 	name := c.compileUpperCamelCompoundIdentifier(ci, "", declarationContext)
+	shape := val.Shape(fidlgen.WireFormatVersionV2)
 	r := Struct{
 		PayloadableName: PayloadableName{name},
 		Struct:          val,
 		TypeSymbol:      c.typeSymbolForCompoundIdentifier(ci),
 		TypeExpr: fmt.Sprintf(
 			`$fidl.StructType<%s>(inlineSize: %v, structDecode: %s._structDecode)`,
-			name, val.TypeShapeV2.InlineSize, name),
+			name, shape.InlineSize, name),
 		Documented: docString(val),
 	}
 
 	// Early exit for empty struct case.
-	if len(val.Members) == 0 {
+	if placeholder, ok := emptyStructRepresentation.Placeholder(val); ok {
 		r.isEmptyStruct = true
-		r.Members = []StructMember{
-			c.compileStructMember(fidlgen.EmptyStructMember("reserved")),
-		}
+		r.Members = []StructMember{c.compileStructMember(placeholder)}
 		r.Paddings = []StructPadding{
 			{
 				OffsetV2:  0,
@@ -1249,6 +1250,7 @@ func (c *compiler) compileStruct(val fidlgen.Struct) Struct {
 	)
 	for _, v := range val.Members {
 		member := c.compileStructMember(v)
+		fieldShape := v.FieldShape(fidlgen.WireFormatVersionV2)
 		if member.Type.Nullable {
 			r.HasNullableField = true
 		}
@@ -1257,14 +1259,14 @@ func (c *compiler) compileStruct(val fidlgen.Struct) Struct {
 			isFirst = false
 		} else {
 			r.Paddings = append(r.Paddings, StructPadding{
-				OffsetV2:  v.FieldShapeV2.Offset - previousPaddingV2,
+				OffsetV2:  fieldShape.Offset - previousPaddingV2,
 				PaddingV2: previousPaddingV2,
 			})
 		}
-		previousPaddingV2 = v.FieldShapeV2.Padding
+		previousPaddingV2 = fieldShape.Padding
 	}
 	r.Paddings = append(r.Paddings, StructPadding{
-		OffsetV2:  val.TypeShapeV2.InlineSize - previousPaddingV2,
+		OffsetV2:  shape.InlineSize - previousPaddingV2,
 		PaddingV2: previousPaddingV2,
 	})
 	return r
@@ -1306,7 +1308,7 @@ func (c *compiler) compileTable(val fidlgen.Table) Table {
   members: %s,
   ctor: %s._ctor,
   resource: %t,
-)`, r.Name, val.TypeShapeV2.InlineSize, formatTableMemberList(r.Members), r.Name, r.IsResourceType())
+)`, r.Name, val.Shape(fidlgen.WireFormatVersionV2).InlineSize, formatTableMemberList(r.Members), r.Name, r.IsResourceType())
 	return r
 }
 
@@ -1464,7 +1466,8 @@ func Compile(r fidlgen.Root) Root {
 	// For imported table and union payloads, generate the appropriate payloadable
 	// names.
 	for _, v := range r.Libraries {
-		for n, d := range v.Decls {
+		for _, n := range v.Decls.SortedNames() {
+			d := v.Decls[n]
 			if d.Type == fidlgen.TableDeclType || d.Type == fidlgen.UnionDeclType {
 				if _, ok := mtum[n]; ok {
 					ci := n.Parse()
@@ -0,0 +1,46 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package codegen
+
+import (
+	"embed"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	fidlgentemplates "go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen/templates"
+)
+
+//go:embed *.tmpl
+var templates embed.FS
+
+type Generator struct {
+	*fidlgen.Generator
+}
+
+// NewGenerator creates a Generator for the example backend. The digest is
+// plain text, so no external formatter is needed, and names are kept as
+// written in FIDL.
+func NewGenerator() Generator {
+	return Generator{fidlgen.NewGenerator("ExampleTemplates", templates, fidlgen.NewFormatter(""),
+		fidlgentemplates.Funcs(fidlgentemplates.Options{}))}
+}
+
+// NewCachingGenerator creates a Generator which renders each declaration
+// through the given cache, so that a long-lived process regenerating a digest
+// only renders the declarations which changed.
+func NewCachingGenerator(cache *fidlgen.RenderCache) Generator {
+	gen := NewGenerator()
+	gen.SetRenderCache(cache)
+	return gen
+}
+
+// GenerateDigest writes the digest of the given library to filename.
+func (gen Generator) GenerateDigest(tree Root, filename string) error {
+	return gen.GenerateFile(filename, "GenerateDigestFile", tree)
+}
+
+// Digest renders the digest of the given library in memory.
+func (gen Generator) Digest(tree Root) ([]byte, error) {
+	return gen.ExecuteTemplate("GenerateDigestFile", tree)
+}
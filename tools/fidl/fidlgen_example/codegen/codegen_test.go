@@ -0,0 +1,299 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package codegen
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+var (
+	updateGoldens = flag.Bool("update-goldens", false, "Whether to update goldens")
+	goldensDir    = flag.String("goldens-dir", "testdata", "Directory containing goldens")
+)
+
+func primitive(subtype fidlgen.PrimitiveSubtype) fidlgen.Type {
+	return fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: subtype}
+}
+
+func identifier(name fidlgen.EncodedCompoundIdentifier) *fidlgen.Type {
+	return &fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: name}
+}
+
+func literal(value string) fidlgen.Constant {
+	return fidlgen.Constant{
+		Kind:    fidlgen.LiteralConstant,
+		Literal: fidlgen.Literal{Kind: fidlgen.NumericLiteral, Value: value},
+		Value:   value,
+	}
+}
+
+func doc(text string) fidlgen.Attributes {
+	return fidlgen.Attributes{
+		Attributes: []fidlgen.Attribute{
+			{
+				Name: "doc",
+				Args: []fidlgen.AttributeArg{{Name: "value", Value: fidlgen.Constant{Value: text}}},
+			},
+		},
+	}
+}
+
+func layoutDecl(name fidlgen.EncodedCompoundIdentifier, nc ...string) fidlgen.LayoutDecl {
+	return fidlgen.LayoutDecl{
+		Decl:          fidlgen.Decl{Name: name},
+		NamingContext: nc,
+	}
+}
+
+// exampleLibrary is a hand-written equivalent of the IR that fidlc would
+// produce for:
+//
+//	library example;
+//
+//	const MAX_POINTS uint32 = 16;
+//	type Flags = strict bits : uint8 { READ = 1; WRITE = 2; };
+//	type Color = flexible enum : uint32 { RED = 1; };
+//	/// A point.
+//	type Point = struct { x int32; y int32; };
+//	type Settings = table { 1: color Color; 3: flags Flags; };
+//	type Shape = strict union { 1: point Point; };
+//	@bindings_denylist("example")
+//	type Hidden = struct {};
+//	protocol Canvas {
+//	    Draw(struct { points vector<Point>:MAX_POINTS; });
+//	    GetSettings() -> (Settings);
+//	    -> OnReady();
+//	};
+var exampleLibrary = fidlgen.Root{
+	Name: "example",
+	Consts: []fidlgen.Const{
+		{
+			Decl:  fidlgen.Decl{Name: "example/MAX_POINTS"},
+			Type:  primitive(fidlgen.Uint32),
+			Value: literal("16"),
+		},
+	},
+	Bits: []fidlgen.Bits{
+		{
+			LayoutDecl: layoutDecl("example/Flags", "Flags"),
+			Type:       primitive(fidlgen.Uint8),
+			Members: []fidlgen.BitsMember{
+				{Name: "READ", Value: literal("1")},
+				{Name: "WRITE", Value: literal("2")},
+			},
+			Strictness: fidlgen.IsStrict,
+		},
+	},
+	Enums: []fidlgen.Enum{
+		{
+			LayoutDecl: layoutDecl("example/Color", "Color"),
+			Type:       fidlgen.Uint32,
+			Members:    []fidlgen.EnumMember{{Name: "RED", Value: literal("1")}},
+			Strictness: fidlgen.IsFlexible,
+		},
+	},
+	Structs: []fidlgen.Struct{
+		{
+			ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+				LayoutDecl: fidlgen.LayoutDecl{
+					Decl:          fidlgen.Decl{Name: "example/Point", Attributes: doc(" A point.\n")},
+					NamingContext: []string{"Point"},
+				},
+			},
+			Members: []fidlgen.StructMember{
+				{Name: "x", Type: primitive(fidlgen.Int32)},
+				{Name: "y", Type: primitive(fidlgen.Int32)},
+			},
+		},
+		{
+			ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+				LayoutDecl: layoutDecl("example/CanvasDrawRequest", "Canvas", "Draw", "Request"),
+			},
+			Members: []fidlgen.StructMember{
+				{
+					Name: "points",
+					Type: fidlgen.Type{
+						Kind:         fidlgen.VectorType,
+						ElementType:  identifier("example/Point"),
+						ElementCount: func() *int { n := 16; return &n }(),
+					},
+				},
+			},
+		},
+		{
+			ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+				LayoutDecl: fidlgen.LayoutDecl{
+					Decl: fidlgen.Decl{
+						Name: "example/Hidden",
+						Attributes: fidlgen.Attributes{
+							Attributes: []fidlgen.Attribute{
+								{
+									Name: "bindings_denylist",
+									Args: []fidlgen.AttributeArg{{Name: "value", Value: fidlgen.Constant{Value: "example"}}},
+								},
+							},
+						},
+					},
+					NamingContext: []string{"Hidden"},
+				},
+			},
+		},
+	},
+	Tables: []fidlgen.Table{
+		{
+			ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+				LayoutDecl: layoutDecl("example/Settings", "Settings"),
+			},
+			Members: []fidlgen.TableMember{
+				{Name: "flags", Ordinal: 3, Type: *identifier("example/Flags")},
+				{Name: "color", Ordinal: 1, Type: *identifier("example/Color")},
+				{Name: "unused", Ordinal: 2, Reserved: true},
+			},
+		},
+	},
+	Unions: []fidlgen.Union{
+		{
+			ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+				LayoutDecl: layoutDecl("example/Shape", "Shape"),
+			},
+			Members: []fidlgen.UnionMember{
+				{Name: "point", Ordinal: 1, Type: *identifier("example/Point")},
+			},
+			Strictness: fidlgen.IsStrict,
+		},
+	},
+	Protocols: []fidlgen.Protocol{
+		{
+			Decl: fidlgen.Decl{Name: "example/Canvas"},
+			Methods: []fidlgen.Method{
+				{
+					Name:           "Draw",
+					Ordinal:        0x1234,
+					HasRequest:     true,
+					RequestPayload: identifier("example/CanvasDrawRequest"),
+				},
+				{
+					Name:            "GetSettings",
+					Ordinal:         0x2468,
+					HasRequest:      true,
+					HasResponse:     true,
+					ResponsePayload: identifier("example/Settings"),
+				},
+				{
+					Name:        "OnReady",
+					Ordinal:     0x5678,
+					HasResponse: true,
+				},
+			},
+		},
+	},
+	Decls: fidlgen.DeclMap{
+		"example/MAX_POINTS":        fidlgen.ConstDeclType,
+		"example/Flags":             fidlgen.BitsDeclType,
+		"example/Color":             fidlgen.EnumDeclType,
+		"example/Point":             fidlgen.StructDeclType,
+		"example/CanvasDrawRequest": fidlgen.StructDeclType,
+		"example/Hidden":            fidlgen.StructDeclType,
+		"example/Settings":          fidlgen.TableDeclType,
+		"example/Shape":             fidlgen.UnionDeclType,
+		"example/Canvas":            fidlgen.ProtocolDeclType,
+	},
+}
+
+func TestDigestGoldens(t *testing.T) {
+	testCases := []struct {
+		name string
+		root fidlgen.Root
+	}{
+		{
+			name: "example",
+			root: exampleLibrary,
+		},
+		{
+			name: "empty",
+			root: fidlgen.Root{Name: "empty"},
+		},
+	}
+
+	gen := NewGenerator()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := Compile(tc.root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := gen.Digest(tree)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			goldenFile := filepath.Join(*goldensDir, tc.name+".txt.golden")
+			if *updateGoldens {
+				if err := os.WriteFile(goldenFile, actual, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			expected, err := os.ReadFile(goldenFile)
+			if err != nil {
+				t.Fatalf("%v (run with -update-goldens to create it)", err)
+			}
+			if diff := cmp.Diff(string(expected), string(actual)); diff != "" {
+				t.Errorf("digest differs from %s (-want +got):\n%s", goldenFile, diff)
+			}
+		})
+	}
+}
+
+func TestDigestRenderCache(t *testing.T) {
+	tree, err := Compile(exampleLibrary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := NewGenerator().Digest(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := fidlgen.NewRenderCache(100)
+	gen := NewCachingGenerator(cache)
+	for i := 0; i < 2; i++ {
+		actual, err := gen.Digest(tree)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(string(expected), string(actual)); diff != "" {
+			t.Errorf("cached digest differs (-want +got):\n%s", diff)
+		}
+	}
+	// Each declaration is rendered once, then served from the cache.
+	stats := cache.Stats()
+	if stats.Misses == 0 || stats.Hits != stats.Misses || cache.Len() != stats.Misses {
+		t.Errorf("got stats %+v and %d cached outputs, want as many hits as misses and outputs", stats, cache.Len())
+	}
+}
+
+func TestCompileUnknownTypeKind(t *testing.T) {
+	root := fidlgen.Root{
+		Name: "example",
+		Consts: []fidlgen.Const{
+			{
+				Decl:  fidlgen.Decl{Name: "example/FUTURE"},
+				Type:  fidlgen.Type{Kind: "future_kind"},
+				Value: literal("1"),
+			},
+		},
+		Decls: fidlgen.DeclMap{"example/FUTURE": fidlgen.ConstDeclType},
+	}
+	if _, err := Compile(root); err == nil {
+		t.Error("expected an error for an unknown type kind")
+	}
+}
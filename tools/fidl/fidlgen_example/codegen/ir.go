@@ -0,0 +1,379 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// bindingsName is the name under which this backend is referred to in
+// @bindings_denylist attributes.
+const bindingsName = "example"
+
+// Root is the template-facing view of a FIDL library. Compared to
+// fidlgen.Root, names are already transformed into the digest's naming
+// conventions and types are already rendered into strings, so that templates
+// hold no logic beyond iteration.
+type Root struct {
+	Library   string
+	Consts    []Const
+	Bits      []Bits
+	Enums     []Enum
+	Structs   []Struct
+	Tables    []Table
+	Unions    []Union
+	Protocols []Protocol
+}
+
+// Const represents a constant declaration.
+type Const struct {
+	Name        string
+	Type        string
+	Value       string
+	DocComments []string
+}
+
+// Bits represents a bits declaration.
+type Bits struct {
+	Name        string
+	Type        string
+	Strictness  string
+	Members     []Member
+	DocComments []string
+}
+
+// Enum represents an enum declaration.
+type Enum struct {
+	Name        string
+	Type        string
+	Strictness  string
+	Members     []Member
+	DocComments []string
+}
+
+// Struct represents a struct declaration.
+type Struct struct {
+	Name        string
+	Anonymous   bool
+	Members     []Member
+	DocComments []string
+}
+
+// Table represents a table declaration.
+type Table struct {
+	Name        string
+	Anonymous   bool
+	Members     []Member
+	DocComments []string
+}
+
+// Union represents a union declaration.
+type Union struct {
+	Name        string
+	Anonymous   bool
+	Strictness  string
+	Members     []Member
+	DocComments []string
+}
+
+// Member represents a member of any of the layouts above. For bits and enums,
+// Value holds the member's value; for structs, tables, and unions, Type holds
+// the member's type, and Ordinal the member's ordinal if it has one.
+type Member struct {
+	Name        string
+	Type        string
+	Value       string
+	Ordinal     int
+	DocComments []string
+}
+
+// Protocol represents a protocol declaration.
+type Protocol struct {
+	Name        string
+	Methods     []Method
+	DocComments []string
+}
+
+// Method represents a method of a protocol. Request and Response render its
+// parameters: the members of a struct payload are flattened into a parameter
+// list, while a table or union payload is rendered by its type name.
+type Method struct {
+	Name        string
+	Ordinal     uint64
+	Kind        string
+	Request     string
+	Response    string
+	DocComments []string
+}
+
+func strictness(s fidlgen.Strictness) string {
+	if s.IsStrict() {
+		return "strict"
+	}
+	return "flexible"
+}
+
+// declName transforms a FIDL declaration name into the digest's naming
+// convention: UpperCamelCase, unqualified for local declarations, and
+// qualified with the library name otherwise.
+func (c *compiler) declName(eci fidlgen.EncodedCompoundIdentifier) string {
+	ci := eci.Parse()
+	name := fidlgen.ToUpperCamelCase(string(ci.Name))
+	if ci.Library.Encode() != c.library {
+		return fmt.Sprintf("%s.%s", ci.Library.Encode(), name)
+	}
+	return name
+}
+
+// typeName renders a type using FIDL-like syntax. An error is returned for
+// type kinds the digest does not know about.
+func (c *compiler) typeName(t fidlgen.Type) (string, error) {
+	var name string
+	switch t.Kind {
+	case fidlgen.PrimitiveType:
+		name = string(t.PrimitiveSubtype)
+	case fidlgen.StringType:
+		name = "string"
+	case fidlgen.ArrayType:
+		elem, err := c.typeName(*t.ElementType)
+		if err != nil {
+			return "", err
+		}
+		name = fmt.Sprintf("array<%s, %d>", elem, *t.ElementCount)
+	case fidlgen.VectorType:
+		elem, err := c.typeName(*t.ElementType)
+		if err != nil {
+			return "", err
+		}
+		name = fmt.Sprintf("vector<%s>", elem)
+	case fidlgen.HandleType:
+		name = fmt.Sprintf("handle<%s>", t.HandleSubtype)
+	case fidlgen.RequestType:
+		name = fmt.Sprintf("server_end<%s>", c.declName(t.RequestSubtype))
+	case fidlgen.IdentifierType:
+		name = c.declName(t.Identifier)
+		if c.decls[t.Identifier].Type == fidlgen.ProtocolDeclType {
+			name = fmt.Sprintf("client_end<%s>", name)
+		}
+	case fidlgen.InternalType:
+		name = string(t.InternalSubtype)
+	default:
+		return "", fmt.Errorf("unknown type kind: %s", t.Kind)
+	}
+	if (t.Kind == fidlgen.StringType || t.Kind == fidlgen.VectorType) && t.ElementCount != nil {
+		name = fmt.Sprintf("%s:%d", name, *t.ElementCount)
+	}
+	if t.Nullable {
+		name += "?"
+	}
+	return name, nil
+}
+
+// parameters renders the parameters of a request or response: a struct
+// payload is flattened into its members, e.g. `x int32, y int32`, while a
+// table or union payload is passed as a whole by its type name.
+func (c *compiler) parameters(params fidlgen.MethodParameters) (string, error) {
+	if !params.IsFlattened() {
+		if params.Payload == nil {
+			return "", nil
+		}
+		return c.typeName(*params.Payload)
+	}
+	var rendered []string
+	for _, p := range params.Parameters {
+		typ, err := c.typeName(p.Type)
+		if err != nil {
+			return "", err
+		}
+		rendered = append(rendered, fmt.Sprintf("%s %s", fidlgen.ToSnakeCase(string(p.Name)), typ))
+	}
+	return strings.Join(rendered, ", "), nil
+}
+
+type compiler struct {
+	library fidlgen.EncodedLibraryIdentifier
+	decls   fidlgen.DeclInfoMap
+	// resolver resolves method payloads into their declarations, to flatten
+	// their members into parameters.
+	resolver fidlgen.DeclResolver
+}
+
+// Compile transforms the IR of a library into the example backend's Root,
+// after having dropped the declarations denylisted for this backend. An error
+// is returned if the IR holds something the digest cannot render.
+func Compile(r fidlgen.Root) (Root, error) {
+	r = r.ForBindings(bindingsName)
+	c := compiler{
+		library:  r.Name,
+		decls:    r.DeclInfo(),
+		resolver: &r,
+	}
+	root := Root{Library: string(r.Name)}
+
+	for _, v := range r.Consts {
+		typ, err := c.typeName(v.Type)
+		if err != nil {
+			return Root{}, fmt.Errorf("%s: %w", v.Name, err)
+		}
+		root.Consts = append(root.Consts, Const{
+			Name:        fidlgen.ConstNameToAllCapsSnake(string(v.Name.Parse().Name)),
+			Type:        typ,
+			Value:       v.Value.Value,
+			DocComments: v.DocComments(),
+		})
+	}
+	for _, v := range r.Bits {
+		typ, err := c.typeName(v.Type)
+		if err != nil {
+			return Root{}, fmt.Errorf("%s: %w", v.Name, err)
+		}
+		b := Bits{
+			Name:        c.declName(v.Name),
+			Type:        typ,
+			Strictness:  strictness(v.Strictness),
+			DocComments: v.DocComments(),
+		}
+		for _, m := range v.Members {
+			b.Members = append(b.Members, Member{
+				Name:        fidlgen.ConstNameToAllCapsSnake(string(m.Name)),
+				Value:       m.Value.Value,
+				DocComments: m.DocComments(),
+			})
+		}
+		root.Bits = append(root.Bits, b)
+	}
+	for _, v := range r.Enums {
+		e := Enum{
+			Name:        c.declName(v.Name),
+			Type:        string(v.Type),
+			Strictness:  strictness(v.Strictness),
+			DocComments: v.DocComments(),
+		}
+		for _, m := range v.Members {
+			e.Members = append(e.Members, Member{
+				Name:        fidlgen.ConstNameToAllCapsSnake(string(m.Name)),
+				Value:       m.Value.Value,
+				DocComments: m.DocComments(),
+			})
+		}
+		root.Enums = append(root.Enums, e)
+	}
+	for _, v := range r.Structs {
+		s := Struct{
+			Name:        c.declName(v.Name),
+			Anonymous:   v.IsAnonymous(),
+			DocComments: v.DocComments(),
+		}
+		for _, m := range v.Members {
+			typ, err := c.typeName(m.Type)
+			if err != nil {
+				return Root{}, fmt.Errorf("%s.%s: %w", v.Name, m.Name, err)
+			}
+			s.Members = append(s.Members, Member{
+				Name:        fidlgen.ToSnakeCase(string(m.Name)),
+				Type:        typ,
+				DocComments: m.DocComments(),
+			})
+		}
+		root.Structs = append(root.Structs, s)
+	}
+	for _, v := range r.Tables {
+		t := Table{
+			Name:        c.declName(v.Name),
+			Anonymous:   v.IsAnonymous(),
+			DocComments: v.DocComments(),
+		}
+		for _, m := range v.SortedMembersNoReserved() {
+			typ, err := c.typeName(m.Type)
+			if err != nil {
+				return Root{}, fmt.Errorf("%s.%s: %w", v.Name, m.Name, err)
+			}
+			t.Members = append(t.Members, Member{
+				Name:        fidlgen.ToSnakeCase(string(m.Name)),
+				Type:        typ,
+				Ordinal:     m.Ordinal,
+				DocComments: m.DocComments(),
+			})
+		}
+		root.Tables = append(root.Tables, t)
+	}
+	for _, v := range r.Unions {
+		u := Union{
+			Name:        c.declName(v.Name),
+			Anonymous:   v.IsAnonymous(),
+			Strictness:  strictness(v.Strictness),
+			DocComments: v.DocComments(),
+		}
+		for _, m := range v.Members {
+			if m.Reserved {
+				continue
+			}
+			typ, err := c.typeName(m.Type)
+			if err != nil {
+				return Root{}, fmt.Errorf("%s.%s: %w", v.Name, m.Name, err)
+			}
+			u.Members = append(u.Members, Member{
+				Name:        fidlgen.ToSnakeCase(string(m.Name)),
+				Type:        typ,
+				Ordinal:     m.Ordinal,
+				DocComments: m.DocComments(),
+			})
+		}
+		root.Unions = append(root.Unions, u)
+	}
+	for _, v := range r.Protocols {
+		p := Protocol{
+			Name:        c.declName(v.Name),
+			DocComments: v.DocComments(),
+		}
+		for i := range v.Methods {
+			m := &v.Methods[i]
+			method := Method{
+				Name:        fidlgen.ToLowerCamelCase(string(m.Name)),
+				Ordinal:     m.Ordinal,
+				DocComments: m.DocComments(),
+			}
+			switch {
+			case m.HasRequest && m.HasResponse:
+				method.Kind = "two-way"
+			case m.HasRequest:
+				method.Kind = "one-way"
+			default:
+				method.Kind = "event"
+			}
+			request, err := m.RequestParameters(c.resolver)
+			if err != nil {
+				return Root{}, fmt.Errorf("%s: %w", v.Name, err)
+			}
+			if method.Request, err = c.parameters(request); err != nil {
+				return Root{}, fmt.Errorf("%s.%s request: %w", v.Name, m.Name, err)
+			}
+			response, err := m.ResponseParameters(c.resolver)
+			if err != nil {
+				return Root{}, fmt.Errorf("%s: %w", v.Name, err)
+			}
+			if method.Response, err = c.parameters(response); err != nil {
+				return Root{}, fmt.Errorf("%s.%s response: %w", v.Name, m.Name, err)
+			}
+			p.Methods = append(p.Methods, method)
+		}
+		root.Protocols = append(root.Protocols, p)
+	}
+
+	// Declarations are listed alphabetically within each kind, so that the
+	// digest is stable under reordering of the FIDL sources.
+	sort.Slice(root.Consts, func(i, j int) bool { return root.Consts[i].Name < root.Consts[j].Name })
+	sort.Slice(root.Bits, func(i, j int) bool { return root.Bits[i].Name < root.Bits[j].Name })
+	sort.Slice(root.Enums, func(i, j int) bool { return root.Enums[i].Name < root.Enums[j].Name })
+	sort.Slice(root.Structs, func(i, j int) bool { return root.Structs[i].Name < root.Structs[j].Name })
+	sort.Slice(root.Tables, func(i, j int) bool { return root.Tables[i].Name < root.Tables[j].Name })
+	sort.Slice(root.Unions, func(i, j int) bool { return root.Unions[i].Name < root.Unions[j].Name })
+	sort.Slice(root.Protocols, func(i, j int) bool { return root.Protocols[i].Name < root.Protocols[j].Name })
+	return root, nil
+}
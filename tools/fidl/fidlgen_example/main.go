@@ -0,0 +1,72 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// fidlgen_example is a minimal but complete FIDL backend. It produces a
+// plain-text digest of a library and exists as executable documentation of
+// the shared fidlgen libraries: new backends are encouraged to start from it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/fidlgen_example/codegen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+type flagsDef struct {
+	jsonPath   *string
+	outputPath *string
+}
+
+var flags = flagsDef{
+	jsonPath: flag.String("json", "",
+		"relative path to the FIDL intermediate representation."),
+	outputPath: flag.String("output", "",
+		"output path for the generated digest."),
+}
+
+// valid returns true if the parsed flags are valid.
+func (f flagsDef) valid() bool {
+	return *f.jsonPath != "" && *f.outputPath != ""
+}
+
+func printUsage() {
+	program := path.Base(os.Args[0])
+	message := `Usage: ` + program + ` [flags]
+
+Example FIDL backend, used to generate a plain-text digest from JSON IR input
+(the intermediate representation of a FIDL library).
+
+Flags:
+`
+	fmt.Fprint(flag.CommandLine.Output(), message)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = printUsage
+	flag.Parse()
+	if !flags.valid() {
+		printUsage()
+		os.Exit(1)
+	}
+
+	root, err := fidlgen.ReadJSONIr(*flags.jsonPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	generator := codegen.NewGenerator()
+	tree, err := codegen.Compile(root)
+	if err != nil {
+		log.Fatalf("Error compiling IR: %v", err)
+	}
+	if err := generator.GenerateDigest(tree, *flags.outputPath); err != nil {
+		log.Fatalf("Error generating digest: %v", err)
+	}
+}
@@ -6,7 +6,6 @@ package codegen
 
 import (
 	"embed"
-	"go/format"
 	"text/template"
 
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
@@ -15,20 +14,12 @@ import (
 //go:embed *.tmpl
 var templates embed.FS
 
-type formatter struct{}
-
-func (f formatter) Format(source []byte) ([]byte, error) {
-	return format.Source(source)
-}
-
-var gofmt fidlgen.Formatter = formatter{}
-
 type Generator struct {
 	*fidlgen.Generator
 }
 
 func NewGenerator() Generator {
-	return Generator{fidlgen.NewGenerator("GoTemplates", templates, gofmt,
+	return Generator{fidlgen.NewGenerator("GoTemplates", templates, fidlgen.GoFormatter,
 		template.FuncMap{})}
 }
 
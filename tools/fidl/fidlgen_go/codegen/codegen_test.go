@@ -0,0 +1,23 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package codegen
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestLintTemplates(t *testing.T) {
+	issues, err := fidlgen.LintTemplates(templates, fidlgen.TemplateLintOptions{
+		Models: []interface{}{Root{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		t.Error(issue)
+	}
+}
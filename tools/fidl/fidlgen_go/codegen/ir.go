@@ -697,7 +697,7 @@ var primitiveTypes = map[fidlgen.PrimitiveSubtype]string{
 }
 
 var internalTypes = map[fidlgen.InternalSubtype]string{
-	fidlgen.TransportErr: "_bindings.TransportErr",
+	fidlgen.FrameworkErr: "_bindings.TransportErr",
 }
 
 var handleTypes = map[fidlgen.HandleSubtype]string{
@@ -1007,7 +1007,7 @@ func (c *compiler) compileEnum(val fidlgen.Enum) Enum {
 
 func (c *compiler) compileStructMember(val fidlgen.StructMember) StructMember {
 	tags := Tags{
-		FidlOffsetV2Tag: val.FieldShapeV2.Offset,
+		FidlOffsetV2Tag: val.FieldShape(fidlgen.WireFormatVersionV2).Offset,
 	}
 	ty, rbtag := c.compileType(val.Type)
 	if !rbtag.IsEmpty() {
@@ -1033,10 +1033,11 @@ func (c *compiler) compileStruct(val fidlgen.Struct) Struct {
 	// required for fidl.CreateLazymarshaler which is called on all structs
 	c.usedLibraryDeps[BindingsPackage] = BindingsAlias
 
+	shape := val.Shape(fidlgen.WireFormatVersionV2)
 	tags := Tags{
 		FidlTag:            "s",
-		FidlSizeV2Tag:      val.TypeShapeV2.InlineSize,
-		FidlAlignmentV2Tag: val.TypeShapeV2.Alignment,
+		FidlSizeV2Tag:      shape.InlineSize,
+		FidlAlignmentV2Tag: shape.Alignment,
 	}
 
 	r := Struct{
@@ -1073,7 +1074,7 @@ func (c *compiler) compileUnion(val fidlgen.Union) Union {
 			tags[FidlBoundsTag] = rbtag.String()
 		}
 		if handleRights, ok := c.computeHandleRights(member.Type); ok {
-			tags[FidlHandleRightsTag] = handleRights
+			tags[FidlHandleRightsTag] = int(handleRights)
 		}
 		if handleSubtype, ok := c.computeHandleSubtype(member.Type); ok {
 			tags[FidlHandleSubtypeTag] = handleSubtype
@@ -1091,10 +1092,11 @@ func (c *compiler) compileUnion(val fidlgen.Union) Union {
 	if val.Strictness == fidlgen.IsStrict {
 		fidlTag += "!"
 	}
+	shape := val.Shape(fidlgen.WireFormatVersionV2)
 	tags := Tags{
 		FidlTag:            fidlTag,
-		FidlSizeV2Tag:      val.TypeShapeV2.InlineSize,
-		FidlAlignmentV2Tag: val.TypeShapeV2.Alignment,
+		FidlSizeV2Tag:      shape.InlineSize,
+		FidlAlignmentV2Tag: shape.Alignment,
 		FidlIsResourceTag:  val.IsResourceType(),
 	}
 	return Union{
@@ -1122,7 +1124,7 @@ func (c *compiler) compileTable(val fidlgen.Table) Table {
 			tags[FidlBoundsTag] = rbtag.String()
 		}
 		if handleRights, ok := c.computeHandleRights(member.Type); ok {
-			tags[FidlHandleRightsTag] = handleRights
+			tags[FidlHandleRightsTag] = int(handleRights)
 		}
 		if handleSubtype, ok := c.computeHandleSubtype(member.Type); ok {
 			tags[FidlHandleSubtypeTag] = handleSubtype
@@ -1142,10 +1144,11 @@ func (c *compiler) compileTable(val fidlgen.Table) Table {
 			Tags:              tags,
 		})
 	}
+	shape := val.Shape(fidlgen.WireFormatVersionV2)
 	tags := Tags{
 		FidlTag:            "t",
-		FidlSizeV2Tag:      val.TypeShapeV2.InlineSize,
-		FidlAlignmentV2Tag: val.TypeShapeV2.Alignment,
+		FidlSizeV2Tag:      shape.InlineSize,
+		FidlAlignmentV2Tag: shape.Alignment,
 		FidlIsResourceTag:  val.IsResourceType(),
 	}
 	return Table{
@@ -1345,7 +1348,8 @@ func Compile(fidlData fidlgen.Root) Root {
 
 	// For imported table and union payloads, generate the appropriate import references.
 	for _, v := range fidlData.Libraries {
-		for name, decl := range v.Decls {
+		for _, name := range v.Decls.SortedNames() {
+			decl := v.Decls[name]
 			if _, ok := mbtn[name]; ok {
 				if decl.Type == fidlgen.TableDeclType {
 					t := payloadableName{c.compileCompoundIdentifier(name, true, "")}
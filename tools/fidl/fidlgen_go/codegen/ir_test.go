@@ -6,8 +6,11 @@ package codegen
 
 import (
 	"math"
+	"strings"
 	"testing"
 
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen/builders"
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
 )
 
@@ -82,3 +85,56 @@ func TestBindingsPackageDependency(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleRightsTag(t *testing.T) {
+	vmo := builders.Handle(fidlgen.HandleSubtypeVmo, fidlgen.HandleRightsRead|fidlgen.HandleRightsWrite)
+	table := fidlgen.Table{
+		ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+			LayoutDecl:   fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/T"}, NamingContext: []string{"T"}},
+			Resourceness: fidlgen.IsResourceType,
+		},
+		Members: []fidlgen.TableMember{{Ordinal: 1, Name: "vmo", Type: vmo}},
+	}
+	root := Compile(fidlgen.Root{
+		Name: "example",
+		Structs: []fidlgen.Struct{
+			builders.NewStruct("example/S").Member("vmo", vmo).Resource().Build(),
+		},
+		Unions: []fidlgen.Union{
+			builders.NewUnion("example/U").Member(1, "vmo", vmo).Resource().Build(),
+		},
+		Tables: []fidlgen.Table{table},
+		Decls: fidlgen.DeclMap{
+			"example/S": fidlgen.StructDeclType,
+			"example/U": fidlgen.UnionDeclType,
+			"example/T": fidlgen.TableDeclType,
+		},
+	})
+
+	// Rights are encoded as numbers, READ | WRITE being 0xc.
+	const expected = `fidl_handle_rights:"12"`
+	var tags []Tags
+	for _, s := range root.Structs {
+		for _, m := range s.Members {
+			tags = append(tags, m.Tags)
+		}
+	}
+	for _, u := range root.Unions {
+		for _, m := range u.Members {
+			tags = append(tags, m.Tags)
+		}
+	}
+	for _, t := range root.Tables {
+		for _, m := range t.Members {
+			tags = append(tags, m.Tags)
+		}
+	}
+	if len(tags) != 3 {
+		t.Fatalf("got %d members, want 3", len(tags))
+	}
+	for _, tag := range tags {
+		if !strings.Contains(tag.String(), expected) {
+			t.Errorf("got tags `%s`, want them to contain `%s`", tag, expected)
+		}
+	}
+}
@@ -552,37 +552,6 @@ var handleSubtypes = map[fidlgen.HandleSubtype]string{
 	fidlgen.HandleSubtypeVmo:          "Vmo",
 }
 
-var handleSubtypeConsts = map[fidlgen.HandleSubtype]string{
-	fidlgen.HandleSubtypeBti:          "BTI",
-	fidlgen.HandleSubtypeChannel:      "CHANNEL",
-	fidlgen.HandleSubtypeClock:        "CLOCK",
-	fidlgen.HandleSubtypeDebugLog:     "DEBUGLOG",
-	fidlgen.HandleSubtypeEvent:        "EVENT",
-	fidlgen.HandleSubtypeEventpair:    "EVENTPAIR",
-	fidlgen.HandleSubtypeException:    "EXCEPTION",
-	fidlgen.HandleSubtypeFifo:         "FIFO",
-	fidlgen.HandleSubtypeGuest:        "GUEST",
-	fidlgen.HandleSubtypeInterrupt:    "INTERRUPT",
-	fidlgen.HandleSubtypeIommu:        "IOMMU",
-	fidlgen.HandleSubtypeJob:          "JOB",
-	fidlgen.HandleSubtypeMsi:          "MSI",
-	fidlgen.HandleSubtypeNone:         "NONE",
-	fidlgen.HandleSubtypePager:        "PAGER",
-	fidlgen.HandleSubtypePmt:          "PMT",
-	fidlgen.HandleSubtypePort:         "PORT",
-	fidlgen.HandleSubtypeProcess:      "PROCESS",
-	fidlgen.HandleSubtypeProfile:      "PROFILE",
-	fidlgen.HandleSubtypeResource:     "RESOURCE",
-	fidlgen.HandleSubtypeSocket:       "SOCKET",
-	fidlgen.HandleSubtypeStream:       "STREAM",
-	fidlgen.HandleSubtypeSuspendToken: "SUSPEND_TOKEN",
-	fidlgen.HandleSubtypeThread:       "THREAD",
-	fidlgen.HandleSubtypeTime:         "TIMER",
-	fidlgen.HandleSubtypeVcpu:         "VCPU",
-	fidlgen.HandleSubtypeVmar:         "VMAR",
-	fidlgen.HandleSubtypeVmo:          "VMO",
-}
-
 type compiler struct {
 	decls        fidlgen.DeclInfoMap
 	experiments  fidlgen.Experiments
@@ -645,6 +614,37 @@ func compileScreamingSnakeIdentifier(val fidlgen.Identifier) string {
 	return fidlgen.ConstNameToAllCapsSnake(changeIfReserved(val))
 }
 
+// Names converts the names of FIDL elements as the Rust bindings do, reserved
+// names included, for the utilities of fidlgen that produce names, e.g.
+// fidlgen.LinkLocalDecls.
+var Names fidlgen.NameTransformer = rustNames{}
+
+type rustNames struct{}
+
+func (rustNames) DeclName(name fidlgen.Identifier) string {
+	return compileCamelIdentifier(name)
+}
+
+func (rustNames) MemberName(name fidlgen.Identifier) string {
+	return compileSnakeIdentifier(name)
+}
+
+func (rustNames) EnumMemberName(name fidlgen.Identifier) string {
+	return compileCamelIdentifier(name)
+}
+
+func (rustNames) BitsMemberName(name fidlgen.Identifier) string {
+	return compileScreamingSnakeIdentifier(name)
+}
+
+func (rustNames) ConstName(name fidlgen.Identifier) string {
+	return compileScreamingSnakeIdentifier(name)
+}
+
+func (rustNames) MethodName(name fidlgen.Identifier) string {
+	return compileSnakeIdentifier(name)
+}
+
 // compileCompoundIdentifier produces a string Rust identifier which can be used
 // from the generated code to refer to the specified FIDL declaration or member.
 //
@@ -813,7 +813,9 @@ func compileHandleSubtype(val fidlgen.HandleSubtype) string {
 	if t, ok := handleSubtypes[val]; ok {
 		return t
 	}
-	panic(fmt.Sprintf("unknown handle type: %v", val))
+	// Fall back onto a generic handle if we don't support that particular
+	// handle subtype.
+	return handleSubtypes[fidlgen.HandleSubtypeNone]
 }
 
 type FieldHandleInformation struct {
@@ -861,12 +863,15 @@ func (c *compiler) fieldHandleInformation(val *fidlgen.Type) FieldHandleInformat
 			hasHandleMetadata: false,
 		}
 	}
-	subtype, ok := handleSubtypeConsts[val.HandleSubtype]
+	// Subtypes unknown to fidlgen are rendered from the object type in the IR.
+	subtype, ok := val.HandleSubtype.ConstName()
+	fullObjectType := fmt.Sprintf("fidl::ObjectType::%s", subtype)
 	if !ok {
-		panic(fmt.Sprintf("unknown handle type for const: %v", val))
+		subtype = fmt.Sprintf("%d", val.ObjType)
+		fullObjectType = fmt.Sprintf("fidl::ObjectType::from_raw(%d)", val.ObjType)
 	}
 	return FieldHandleInformation{
-		fullObjectType:    fmt.Sprintf("fidl::ObjectType::%s", subtype),
+		fullObjectType:    fullObjectType,
 		fullRights:        fmt.Sprintf("fidl::Rights::from_bits_const(%d).unwrap()", val.HandleRights),
 		shortObjectType:   subtype,
 		shortRights:       fmt.Sprintf("%d", val.HandleRights),
@@ -1127,7 +1132,7 @@ func (c *compiler) compileProtocol(val fidlgen.Protocol) Protocol {
 		if v.HasError {
 			compiledResponseParameterList = getParametersFromType(v.ResponsePayload)
 			foundResult = findResultType()
-		} else if v.HasTransportError() {
+		} else if v.HasFrameworkError() {
 			compiledResponseParameterList = getParametersFromType(v.ValueType)
 			foundResult = findResultType()
 		} else if v.ResponsePayload != nil {
@@ -1179,13 +1184,17 @@ func (c *compiler) compileService(val fidlgen.Service) Service {
 	}
 
 	for _, v := range val.Members {
+		clientEnd, ok := v.ClientEnd()
+		if !ok {
+			panic(fmt.Sprintf("service member %s.%s is not a client end", val.Name, v.Name))
+		}
 		m := ServiceMember{
 			ServiceMember:     v,
 			Name:              string(v.Name),
 			CamelName:         compileCamelIdentifier(v.Name),
 			SnakeName:         compileSnakeIdentifier(v.Name),
-			ProtocolType:      c.compileCamelCompoundIdentifier(v.Type.Identifier),
-			ProtocolTransport: v.Type.ProtocolTransport,
+			ProtocolType:      c.compileCamelCompoundIdentifier(clientEnd.Protocol),
+			ProtocolTransport: string(clientEnd.Transport),
 		}
 		r.Members = append(r.Members, m)
 	}
@@ -1200,8 +1209,8 @@ func (c *compiler) compileStructMember(val fidlgen.StructMember) StructMember {
 		Type:              c.compileType(val.Type),
 		OGType:            val.Type,
 		Name:              compileSnakeIdentifier(val.Name),
-		OffsetV1:          val.FieldShapeV1.Offset,
-		OffsetV2:          val.FieldShapeV2.Offset,
+		OffsetV1:          val.FieldShape(fidlgen.WireFormatVersionV1).Offset,
+		OffsetV2:          val.FieldShape(fidlgen.WireFormatVersionV2).Offset,
 		HasDefault:        false,
 		DefaultValue:      "", // TODO(cramertj) support defaults
 		HasHandleMetadata: hi.hasHandleMetadata,
@@ -1211,7 +1220,7 @@ func (c *compiler) compileStructMember(val fidlgen.StructMember) StructMember {
 }
 
 func (c *compiler) computeUseFidlStructCopyForStruct(st fidlgen.Struct) bool {
-	if len(st.Members) == 0 {
+	if st.IsEmpty() {
 		// In Rust, structs containing empty structs do not match the C++ struct layout
 		// since empty structs have size 0 in Rust -- even in repr(C).
 		return false
@@ -1324,20 +1333,21 @@ func (c *compiler) compileStruct(val fidlgen.Struct) Struct {
 		ECI:                       val.Name,
 		Name:                      name,
 		Members:                   []StructMember{},
-		SizeV1:                    val.TypeShapeV1.InlineSize,
-		SizeV2:                    val.TypeShapeV2.InlineSize,
-		AlignmentV1:               val.TypeShapeV1.Alignment,
-		AlignmentV2:               val.TypeShapeV2.Alignment,
+		SizeV1:                    val.Shape(fidlgen.WireFormatVersionV1).InlineSize,
+		SizeV2:                    val.Shape(fidlgen.WireFormatVersionV2).InlineSize,
+		AlignmentV1:               val.Shape(fidlgen.WireFormatVersionV1).Alignment,
+		AlignmentV2:               val.Shape(fidlgen.WireFormatVersionV2).Alignment,
 		PaddingMarkersV1:          toRustPaddingMarkers(val.BuildPaddingMarkers(fidlgen.WireFormatVersionV1)),
 		PaddingMarkersV2:          toRustPaddingMarkers(val.BuildPaddingMarkers(fidlgen.WireFormatVersionV2)),
 		FlattenedPaddingMarkersV1: toRustPaddingMarkers(val.BuildFlattenedPaddingMarkers(fidlgen.WireFormatVersionV1, c.resolveStruct)),
 		FlattenedPaddingMarkersV2: toRustPaddingMarkers(val.BuildFlattenedPaddingMarkers(fidlgen.WireFormatVersionV2, c.resolveStruct)),
 	}
 
-	for _, v := range val.Members {
+	// Empty structs are zero-sized in Rust, so need no placeholder member.
+	for _, v := range fidlgen.ZeroSizedEmptyStruct.Members(val) {
 		member := c.compileStructMember(v)
 		r.Members = append(r.Members, member)
-		r.HasPadding = r.HasPadding || (v.FieldShapeV1.Padding != 0)
+		r.HasPadding = r.HasPadding || (v.FieldShape(fidlgen.WireFormatVersionV1).Padding != 0)
 	}
 
 	r.UseFidlStructCopy = c.computeUseFidlStructCopyForStruct(val)
@@ -1387,7 +1397,7 @@ func (c *compiler) compileResultFromUnion(m fidlgen.Method, root Root) Result {
 	r := Result{
 		ECI:               m.ResultType.Identifier,
 		Name:              c.compileCamelCompoundIdentifier(m.ResultType.Identifier),
-		HasTransportError: m.HasTransportError(),
+		HasTransportError: m.HasFrameworkError(),
 	}
 
 	if m.HasError {
@@ -1645,7 +1655,7 @@ typeSwitch:
 		for _, member := range st.Members {
 			derivesOut = derivesOut.and(dc.fillDerivesForType(member.OGType))
 		}
-		if st.HasPadding || len(st.Members) == 0 {
+		if st.HasPadding || st.IsEmpty() {
 			derivesOut = derivesOut.remove(derivesAsBytes, derivesFromBytes)
 		}
 		st.Derives = derivesOut
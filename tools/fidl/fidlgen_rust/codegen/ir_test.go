@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
 )
 
@@ -104,3 +105,52 @@ func TestExperiments(t *testing.T) {
 		}
 	}
 }
+
+func TestFieldHandleInformation(t *testing.T) {
+	cases := []struct {
+		subtype                         fidlgen.HandleSubtype
+		objType                         uint32
+		fullObjectType, shortObjectType string
+	}{
+		{fidlgen.HandleSubtypeVmo, 3, "fidl::ObjectType::VMO", "VMO"},
+		{fidlgen.HandleSubtypeSuspendToken, 20, "fidl::ObjectType::SUSPEND_TOKEN", "SUSPEND_TOKEN"},
+		// Subtypes unknown to fidlgen are rendered from the IR's object type.
+		{"counter", 38, "fidl::ObjectType::from_raw(38)", "38"},
+	}
+	for _, ex := range cases {
+		typ := fidlgen.Type{
+			Kind:          fidlgen.HandleType,
+			HandleSubtype: ex.subtype,
+			ObjType:       ex.objType,
+			HandleRights:  fidlgen.HandleRightsSameRights,
+		}
+		hi := (&compiler{}).fieldHandleInformation(&typ)
+		if hi.fullObjectType != ex.fullObjectType || hi.shortObjectType != ex.shortObjectType {
+			t.Errorf("%s: expected %s and %s, found %s and %s", ex.subtype,
+				ex.fullObjectType, ex.shortObjectType, hi.fullObjectType, hi.shortObjectType)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	for _, tc := range []struct {
+		convert  func(fidlgen.Identifier) string
+		name     fidlgen.Identifier
+		expected string
+	}{
+		{Names.DeclName, "my_decl", "MyDecl"},
+		// Unlike fidlgen.RustNames, names the bindings reserve are escaped.
+		{Names.DeclName, "Result", "Result_"},
+		{Names.DeclName, "FooProxy", "FooProxy_"},
+		{Names.MemberName, "MyMember", "my_member"},
+		{Names.MemberName, "type", "type_"},
+		{Names.EnumMemberName, "BLUE_GREEN", "BlueGreen"},
+		{Names.BitsMemberName, "blue_green", "BLUE_GREEN"},
+		{Names.ConstName, "max_size", "MAX_SIZE"},
+		{Names.MethodName, "DoIt", "do_it"},
+	} {
+		if actual := tc.convert(tc.name); actual != tc.expected {
+			t.Errorf("%s: got %q, want %q", tc.name, actual, tc.expected)
+		}
+	}
+}
@@ -50,15 +50,15 @@ func getHandleDispositionEncoding(encodings []gidlir.HandleDispositionEncoding)
 	return gidlir.HandleDispositionEncoding{}, false
 }
 
-func getHandleObjectTypes(handles []gidlir.Handle, defs []gidlir.HandleDef) []fidlgen.ObjectType {
+func getHandleObjectTypes(handles []gidlir.Handle, defs []gidlir.HandleDef, objTypes fidlgen.HandleObjectTypes) []fidlgen.ObjectType {
 	var objectTypes []fidlgen.ObjectType
 	for _, h := range handles {
-		objectTypes = append(objectTypes, fidlgen.ObjectTypeFromHandleSubtype(defs[h].Subtype))
+		objectTypes = append(objectTypes, objTypes.ObjectType(defs[h].Subtype))
 	}
 	return objectTypes
 }
 
-func convertEncodeSuccesses(gtcs []gidlir.EncodeSuccess) []testCase {
+func convertEncodeSuccesses(gtcs []gidlir.EncodeSuccess, objTypes fidlgen.HandleObjectTypes) []testCase {
 	var tcs []testCase
 	for _, gtc := range gtcs {
 		encoding, ok := getHandleDispositionEncoding(gtc.Encodings)
@@ -68,7 +68,7 @@ func convertEncodeSuccesses(gtcs []gidlir.EncodeSuccess) []testCase {
 
 		tcs = append(tcs, testCase{
 			name:        fmt.Sprintf("EncodeSuccess_%s", gtc.Name),
-			objectTypes: getHandleObjectTypes(gidlir.GetHandlesFromHandleDispositions(encoding.HandleDispositions), gtc.HandleDefs),
+			objectTypes: getHandleObjectTypes(gidlir.GetHandlesFromHandleDispositions(encoding.HandleDispositions), gtc.HandleDefs, objTypes),
 			bytes:       encoding.Bytes,
 		})
 	}
@@ -76,7 +76,7 @@ func convertEncodeSuccesses(gtcs []gidlir.EncodeSuccess) []testCase {
 	return tcs
 }
 
-func convertDecodeSuccesses(gtcs []gidlir.DecodeSuccess) (tcs []testCase) {
+func convertDecodeSuccesses(gtcs []gidlir.DecodeSuccess, objTypes fidlgen.HandleObjectTypes) (tcs []testCase) {
 	for _, gtc := range gtcs {
 		encoding, ok := getEncoding(gtc.Encodings)
 		if !ok {
@@ -85,7 +85,7 @@ func convertDecodeSuccesses(gtcs []gidlir.DecodeSuccess) (tcs []testCase) {
 
 		tcs = append(tcs, testCase{
 			name:        fmt.Sprintf("DecodeSuccess_%s", gtc.Name),
-			objectTypes: getHandleObjectTypes(encoding.Handles, gtc.HandleDefs),
+			objectTypes: getHandleObjectTypes(encoding.Handles, gtc.HandleDefs, objTypes),
 			bytes:       encoding.Bytes,
 		})
 	}
@@ -93,7 +93,7 @@ func convertDecodeSuccesses(gtcs []gidlir.DecodeSuccess) (tcs []testCase) {
 	return tcs
 }
 
-func convertDecodeFailures(gtcs []gidlir.DecodeFailure) (tcs []testCase) {
+func convertDecodeFailures(gtcs []gidlir.DecodeFailure, objTypes fidlgen.HandleObjectTypes) (tcs []testCase) {
 	for _, gtc := range gtcs {
 		encoding, ok := getEncoding(gtc.Encodings)
 		if !ok {
@@ -102,7 +102,7 @@ func convertDecodeFailures(gtcs []gidlir.DecodeFailure) (tcs []testCase) {
 
 		tcs = append(tcs, testCase{
 			name:        fmt.Sprintf("DecodeFailure_%s", gtc.Name),
-			objectTypes: getHandleObjectTypes(encoding.Handles, gtc.HandleDefs),
+			objectTypes: getHandleObjectTypes(encoding.Handles, gtc.HandleDefs, objTypes),
 			bytes:       encoding.Bytes,
 		})
 	}
@@ -140,7 +140,7 @@ func writeTestCase(hostDir string, packageDataDir string, tc testCase) (distribu
 	}, err
 }
 
-func GenerateConformanceTests(gidl gidlir.All, _ fidlgen.Root, config gidlconfig.GeneratorConfig) ([]byte, error) {
+func GenerateConformanceTests(gidl gidlir.All, fidl fidlgen.Root, config gidlconfig.GeneratorConfig) ([]byte, error) {
 	if config.FuzzerCorpusHostDir == "" {
 		return nil, errors.New("Must specify --fuzzer-corpus-host-dir when generating fuzzer_corpus")
 	}
@@ -151,9 +151,9 @@ func GenerateConformanceTests(gidl gidlir.All, _ fidlgen.Root, config gidlconfig
 	var manifest []distributionEntry
 
 	for _, tcs := range [][]testCase{
-		convertEncodeSuccesses(gidl.EncodeSuccess),
-		convertDecodeSuccesses(gidl.DecodeSuccess),
-		convertDecodeFailures(gidl.DecodeFailure),
+		convertEncodeSuccesses(gidl.EncodeSuccess, fidl.HandleObjectTypes),
+		convertDecodeSuccesses(gidl.DecodeSuccess, fidl.HandleObjectTypes),
+		convertDecodeFailures(gidl.DecodeFailure, fidl.HandleObjectTypes),
 	} {
 		for _, tc := range tcs {
 			entry, err := writeTestCase(config.FuzzerCorpusHostDir, config.FuzzerCorpusPackageDataDir, tc)
@@ -5,6 +5,7 @@
 package ir
 
 import (
+	"regexp"
 	"strings"
 
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
@@ -99,33 +100,10 @@ func HandleSubtypeByName(s string) (fidlgen.HandleSubtype, bool) {
 	return "", false
 }
 
-// handleRightsByName is initialized in two phases, constants here, and combined
-// rights in `init`.
-var handleRightsByName = map[string]fidlgen.HandleRights{
-	"none":        fidlgen.HandleRightsNone,
-	"same_rights": fidlgen.HandleRightsSameRights,
-
-	"duplicate":      fidlgen.HandleRightsDuplicate,
-	"transfer":       fidlgen.HandleRightsTransfer,
-	"read":           fidlgen.HandleRightsRead,
-	"write":          fidlgen.HandleRightsWrite,
-	"execute":        fidlgen.HandleRightsExecute,
-	"map":            fidlgen.HandleRightsMap,
-	"get_property":   fidlgen.HandleRightsGetProperty,
-	"set_property":   fidlgen.HandleRightsSetProperty,
-	"enumerate":      fidlgen.HandleRightsEnumerate,
-	"destroy":        fidlgen.HandleRightsDestroy,
-	"set_policy":     fidlgen.HandleRightsSetPolicy,
-	"get_policy":     fidlgen.HandleRightsGetPolicy,
-	"signal":         fidlgen.HandleRightsSignal,
-	"signal_peer":    fidlgen.HandleRightsSignalPeer,
-	"wait":           fidlgen.HandleRightsWait,
-	"inspect":        fidlgen.HandleRightsInspect,
-	"manage_job":     fidlgen.HandleRightsManageJob,
-	"manage_process": fidlgen.HandleRightsManageProcess,
-	"manage_thread":  fidlgen.HandleRightsManageThread,
-	"apply_profile":  fidlgen.HandleRightsApplyProfile,
-}
+// handleRightsByName names the combinations of rights specific to GIDL. Names
+// of single rights, and of the combinations defined by the zx library, are
+// parsed by fidlgen.
+var handleRightsByName = map[string]fidlgen.HandleRights{}
 
 func init() {
 	combinedHandleRights := func(rightsNames ...string) fidlgen.HandleRights {
@@ -139,16 +117,24 @@ func init() {
 		}
 		return combinedRights
 	}
-	handleRightsByName["basic"] = combinedHandleRights("transfer", "duplicate", "wait", "inspect")
-	handleRightsByName["io"] = combinedHandleRights("read", "write")
 	handleRightsByName["channel_default"] = combinedHandleRights("transfer", "wait", "inspect", "io", "signal", "signal_peer")
 	handleRightsByName["event_default"] = combinedHandleRights("basic", "signal")
-
 }
 
+// handleRightsNamePattern matches the names GIDL spells rights with, e.g.
+// `same_rights`. fidlgen also parses upper case names, prefixed names such as
+// `ZX_RIGHT_READ`, hexadecimal values and unions, which GIDL does not accept.
+var handleRightsNamePattern = regexp.MustCompile(`^[a-z]+(_[a-z]+)*$`)
+
 func HandleRightsByName(rightsName string) (fidlgen.HandleRights, bool) {
-	rights, ok := handleRightsByName[rightsName]
-	return rights, ok
+	if rights, ok := handleRightsByName[rightsName]; ok {
+		return rights, true
+	}
+	if !handleRightsNamePattern.MatchString(rightsName) || strings.HasPrefix(rightsName, "zx_") {
+		return 0, false
+	}
+	rights, err := fidlgen.ParseHandleRights(rightsName)
+	return rights, err == nil
 }
 
 type HandleDisposition struct {
@@ -150,14 +150,15 @@ var flags = GIDLFlags{
 	FilterTypes: nil,
 }
 
-func parseGidlIr(filename string) gidlir.All {
+func parseGidlIr(filename string, objTypes fidlgen.HandleObjectTypes) gidlir.All {
 	f, err := os.Open(filename)
 	if err != nil {
 		panic(err)
 	}
 	config := gidlparser.Config{
-		Languages:   allLanguages,
-		WireFormats: allWireFormats,
+		Languages:         allLanguages,
+		WireFormats:       allWireFormats,
+		HandleObjectTypes: objTypes,
 	}
 	result, err := gidlparser.NewParser(filename, f, config).Parse()
 	if err != nil {
@@ -175,6 +176,7 @@ func parseFidlJSONIr(filename string) fidlgen.Root {
 	if err := json.Unmarshal(bytes, &result); err != nil {
 		panic(err)
 	}
+	result.MarkHandleObjectTypes()
 	return result
 }
 
@@ -206,7 +208,7 @@ func main() {
 
 	var parsedGidlFiles []gidlir.All
 	for _, path := range flag.Args() {
-		parsedGidlFiles = append(parsedGidlFiles, parseGidlIr(path))
+		parsedGidlFiles = append(parsedGidlFiles, parseGidlIr(path, ir.HandleObjectTypes))
 	}
 	gidl := gidlir.FilterByBinding(gidlir.Merge(parsedGidlFiles), *flags.Language)
 
@@ -456,7 +456,7 @@ type StructDecl struct {
 }
 
 func (decl *StructDecl) IsInlinableInEnvelope() bool {
-	return decl.structDecl.TypeShapeV2.InlineSize <= 4
+	return decl.structDecl.Shape(fidlgen.WireFormatVersionV2).InlineSize <= 4
 }
 
 func (decl *StructDecl) IsNullable() bool {
@@ -701,7 +701,7 @@ type ArrayDecl struct {
 }
 
 func (decl *ArrayDecl) IsInlinableInEnvelope() bool {
-	return decl.typ.TypeShapeV2.InlineSize <= 4
+	return decl.typ.Shape(fidlgen.WireFormatVersionV2).InlineSize <= 4
 }
 
 func (decl *ArrayDecl) Elem() Declaration {
@@ -28,6 +28,10 @@ type Config struct {
 	Languages ir.LanguageList
 	// All supported wire formats, used to validate `bytes` sections.
 	WireFormats ir.WireFormatList
+	// Object types of handle subtypes, as given by the FIDL IR, used to
+	// resolve the `type` of handle dispositions. Subtypes missing from it are
+	// looked up with fidlgen.ObjectTypeFromHandleSubtype.
+	HandleObjectTypes fidlgen.HandleObjectTypes
 }
 
 type handleInfo struct {
@@ -1153,7 +1157,7 @@ func (p *Parser) parseHandleDispositionList(info handleInfo) ([]ir.HandleDisposi
 				if err != nil {
 					return err
 				}
-				objectType = fidlgen.ObjectTypeFromHandleSubtype(fidlgen.HandleSubtype(valueTok.value))
+				objectType = p.config.HandleObjectTypes.ObjectType(fidlgen.HandleSubtype(valueTok.value))
 			case "rights":
 				rights, err = p.parseHandleRights()
 				if err != nil {
@@ -559,6 +559,26 @@ func TestParseHandles(t *testing.T) {
 	}
 }
 
+func TestParseHandleDispositionObjectTypes(t *testing.T) {
+	p := NewParser("", strings.NewReader(`{ alpha = [
+		{ #0, type: channel },
+		{ #1, type: counter },
+	] }`), Config{
+		WireFormats:       []ir.WireFormat{"alpha"},
+		HandleObjectTypes: fidlgen.HandleObjectTypes{"counter": 42},
+	})
+	value, err := p.parseHandleDispositionSection()
+	checkMatch(t, value, []encodingData{
+		{
+			WireFormat: "alpha",
+			HandleDispositions: []ir.HandleDisposition{
+				{Handle: 0, Type: fidlgen.ObjectTypeChannel, Rights: fidlgen.HandleRightsSameRights},
+				{Handle: 1, Type: 42, Rights: fidlgen.HandleRightsSameRights},
+			},
+		},
+	}, err)
+}
+
 func TestParseHandlesFailures(t *testing.T) {
 	type testCase struct {
 		gidl         string
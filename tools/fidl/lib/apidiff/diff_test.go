@@ -192,3 +192,19 @@ func TestIter(t *testing.T) {
 		})
 	}
 }
+
+func TestComputeTraceChange(t *testing.T) {
+	before := []summarize.ElementStr{
+		{Kind: "protocol/member", Name: "l/P.M", Decl: "()"},
+	}
+	after := []summarize.ElementStr{
+		{Kind: "protocol/member", Name: "l/P.M", Decl: "()", Trace: `@trace(category="fidl", name="M")`},
+	}
+	report, err := Compute(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.ApiDiff) != 1 || report.ApiDiff[0].Conclusion != SourceCompatible {
+		t.Errorf("expected a single SourceCompatible change, got %+v", report.ApiDiff)
+	}
+}
@@ -226,7 +226,13 @@ func (r *Report) compare(before, after *summarize.ElementStr) {
 			ret.Conclusion = Transitionable
 		}
 	case "protocol/member":
-		ret.Conclusion = APIBreaking
+		switch {
+		case before.Decl == after.Decl && before.Trace != after.Trace:
+			// Tracing only affects the instrumentation generated around calls.
+			ret.Conclusion = SourceCompatible
+		default:
+			ret.Conclusion = APIBreaking
+		}
 	case "protocol":
 		fallthrough
 	default:
@@ -0,0 +1,248 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A type's ABI string is a canonical description of its wire format: sizes,
+// alignments, offsets, ordinals, strictness and the values that a strict type
+// accepts. Everything that does not affect the wire format (names, docs,
+// attributes, resourceness) is left out, so that two types have the same ABI
+// string exactly when their encodings are interchangeable.
+//
+// The ABI string, or more conveniently its hash, can be embedded in the
+// bindings generated for different languages and compared at runtime, e.g. in
+// integration tests, to catch skew between artifacts that were generated
+// independently from different versions of a library.
+//
+// Only the V2 wire format is described. The grammar is a FIDL-like notation:
+//
+//	uint32, string:32?, vector<int8>:max, array<bool>:4
+//	handle:channel:0x8000000?, client_end:channel?, server_end:channel
+//	struct<size:align>{offset:T,...}, box<struct<...>{...}>
+//	strict union<size:align>{ordinal:T,...}?, table<size:align>{ordinal:T,...}
+//	strict enum<uint8>{1,2}, flexible bits<uint32>:mask
+//
+// Recursion through a declaration already being described is written `^N`,
+// where N counts the enclosing declarations to go up by.
+
+// abiWireFormat is the wire format described by ABI strings.
+const abiWireFormat = WireFormatVersionV2
+
+// TypeABIString returns the canonical ABI string of a type, looking up the
+// declarations it references through decls.
+func TypeABIString(decls DeclResolver, typ Type) (string, error) {
+	w := abiWriter{decls: decls}
+	if err := w.writeType(typ); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+// TypeABIString returns the canonical ABI string of a type of this library.
+// Types referencing declarations from dependency libraries can only be
+// described through a Compilation.
+func (r *Root) TypeABIString(typ Type) (string, error) {
+	return TypeABIString(r, typ)
+}
+
+// TypeABIString returns the canonical ABI string of a type of the target
+// library or of its dependencies.
+func (c *Compilation) TypeABIString(typ Type) (string, error) {
+	return TypeABIString(c, typ)
+}
+
+// ABIHash hashes an ABI string into a value suitable for embedding as an
+// integer constant in generated code: the first eight bytes of its SHA-256
+// digest, read as a big-endian integer.
+func ABIHash(abi string) uint64 {
+	sum := sha256.Sum256([]byte(abi))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+type abiWriter struct {
+	strings.Builder
+	decls DeclResolver
+	// stack holds the declarations being described, innermost last.
+	stack []EncodedCompoundIdentifier
+}
+
+func (w *abiWriter) writeNullable(nullable bool) {
+	if nullable {
+		w.WriteString("?")
+	}
+}
+
+func (w *abiWriter) writeBound(count *int) {
+	if count != nil {
+		fmt.Fprintf(w, ":%d", *count)
+	} else {
+		w.WriteString(":max")
+	}
+}
+
+func (w *abiWriter) writeType(typ Type) error {
+	switch typ.Kind {
+	case PrimitiveType:
+		w.WriteString(string(typ.PrimitiveSubtype))
+	case StringType:
+		w.WriteString("string")
+		w.writeBound(typ.ElementCount)
+		w.writeNullable(typ.Nullable)
+	case VectorType, ArrayType:
+		fmt.Fprintf(w, "%s<", typ.Kind)
+		if err := w.writeType(*typ.ElementType); err != nil {
+			return err
+		}
+		w.WriteString(">")
+		w.writeBound(typ.ElementCount)
+		w.writeNullable(typ.Nullable)
+	case HandleType:
+		fmt.Fprintf(w, "handle:%s:%#x", typ.HandleSubtype, uint32(typ.HandleRights))
+		w.writeNullable(typ.Nullable)
+	case RequestType:
+		fmt.Fprintf(w, "server_end:%s", w.transport(typ))
+		w.writeNullable(typ.Nullable)
+	case InternalType:
+		fmt.Fprintf(w, "internal:%s", typ.InternalSubtype)
+	case IdentifierType:
+		return w.writeIdentifierType(typ)
+	default:
+		return fmt.Errorf("unknown type kind: %s", typ.Kind)
+	}
+	return nil
+}
+
+func (w *abiWriter) transport(typ Type) string {
+	if typ.ProtocolTransport == "" {
+		return "channel"
+	}
+	return strings.ToLower(string(typ.ProtocolTransport))
+}
+
+func (w *abiWriter) writeIdentifierType(typ Type) error {
+	decl, ok := w.decls.LookupDecl(typ.Identifier)
+	if !ok {
+		return fmt.Errorf("%s not found", typ.Identifier)
+	}
+	if _, ok := decl.(*Protocol); ok {
+		fmt.Fprintf(w, "client_end:%s", w.transport(typ))
+		w.writeNullable(typ.Nullable)
+		return nil
+	}
+
+	name := decl.GetName()
+	for i := len(w.stack) - 1; i >= 0; i-- {
+		if w.stack[i] == name {
+			fmt.Fprintf(w, "^%d", len(w.stack)-1-i)
+			w.writeNullable(typ.Nullable)
+			return nil
+		}
+	}
+	w.stack = append(w.stack, name)
+	defer func() { w.stack = w.stack[:len(w.stack)-1] }()
+
+	switch decl := decl.(type) {
+	case *Struct:
+		if typ.Nullable {
+			w.WriteString("box<")
+		}
+		shape := decl.Shape(abiWireFormat)
+		fmt.Fprintf(w, "struct<%d:%d>{", shape.InlineSize, shape.Alignment)
+		for i, m := range decl.Members {
+			if i > 0 {
+				w.WriteString(",")
+			}
+			fmt.Fprintf(w, "%d:", m.FieldShape(abiWireFormat).Offset)
+			if err := w.writeType(m.Type); err != nil {
+				return err
+			}
+		}
+		w.WriteString("}")
+		if typ.Nullable {
+			w.WriteString(">")
+		}
+		return nil
+	case *Table:
+		shape := decl.Shape(abiWireFormat)
+		fmt.Fprintf(w, "table<%d:%d>{", shape.InlineSize, shape.Alignment)
+		var members []TableMember
+		for _, m := range decl.Members {
+			if !m.Reserved {
+				members = append(members, m)
+			}
+		}
+		sort.Sort(byTableOrdinal(members))
+		for i, m := range members {
+			if i > 0 {
+				w.WriteString(",")
+			}
+			fmt.Fprintf(w, "%d:", m.Ordinal)
+			if err := w.writeType(m.Type); err != nil {
+				return err
+			}
+		}
+		w.WriteString("}")
+		return nil
+	case *Union:
+		shape := decl.Shape(abiWireFormat)
+		fmt.Fprintf(w, "%s union<%d:%d>{", strictnessName(decl.Strictness), shape.InlineSize, shape.Alignment)
+		var members []UnionMember
+		for _, m := range decl.Members {
+			if !m.Reserved {
+				members = append(members, m)
+			}
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].Ordinal < members[j].Ordinal })
+		for i, m := range members {
+			if i > 0 {
+				w.WriteString(",")
+			}
+			fmt.Fprintf(w, "%d:", m.Ordinal)
+			if err := w.writeType(m.Type); err != nil {
+				return err
+			}
+		}
+		w.WriteString("}")
+		w.writeNullable(typ.Nullable)
+		return nil
+	case *Enum:
+		fmt.Fprintf(w, "%s enum<%s>", strictnessName(decl.Strictness), decl.Type)
+		// Only strict enums reject unknown values on decoding, so the set of
+		// members is only part of the ABI of those.
+		if decl.IsStrict() {
+			var values []string
+			for _, m := range decl.Members {
+				values = append(values, m.Value.Value)
+			}
+			sort.Strings(values)
+			fmt.Fprintf(w, "{%s}", strings.Join(values, ","))
+		}
+		return nil
+	case *Bits:
+		fmt.Fprintf(w, "%s bits<%s>", strictnessName(decl.Strictness), decl.Type.PrimitiveSubtype)
+		if decl.IsStrict() {
+			fmt.Fprintf(w, ":%s", decl.Mask)
+		}
+		return nil
+	case *NewType:
+		return w.writeType(decl.Type)
+	default:
+		return fmt.Errorf("%s does not name a type", typ.Identifier)
+	}
+}
+
+func strictnessName(s Strictness) string {
+	if s.IsStrict() {
+		return "strict"
+	}
+	return "flexible"
+}
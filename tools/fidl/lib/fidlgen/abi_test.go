@@ -0,0 +1,84 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestTypeABIString(t *testing.T) {
+	uint32Type := fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}
+	maybeNode := *identifierType("example/Node")
+	maybeNode.Nullable = true
+
+	node := structDecl("example/Node", "value", "next", "color")
+	node.TypeShapeV2 = fidlgen.TypeShape{InlineSize: 24, Alignment: 8}
+	node.Members[0].Type = uint32Type
+	node.Members[1].Type = maybeNode
+	node.Members[1].FieldShapeV2.Offset = 8
+	node.Members[2].Type = *identifierType("example/Color")
+	node.Members[2].FieldShapeV2.Offset = 16
+
+	renamed := node
+	renamed.Name = "example/Renamed"
+	renamed.Members = append([]fidlgen.StructMember(nil), node.Members...)
+	renamed.Members[1].Type.Identifier = "example/Renamed"
+
+	color := fidlgen.Enum{
+		LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Color"}},
+		Type:       fidlgen.Uint8,
+		Members: []fidlgen.EnumMember{
+			{Name: "RED", Value: fidlgen.Constant{Value: "2"}},
+			{Name: "BLUE", Value: fidlgen.Constant{Value: "1"}},
+		},
+		Strictness: fidlgen.IsStrict,
+	}
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{node, renamed},
+		Enums:   []fidlgen.Enum{color},
+	}
+
+	const expected = "struct<24:8>{0:uint32,8:^0?,16:strict enum<uint8>{1,2}}"
+	for _, name := range []fidlgen.EncodedCompoundIdentifier{"example/Node", "example/Renamed"} {
+		abi, err := root.TypeABIString(*identifierType(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if abi != expected {
+			t.Errorf("%s: got %q, want %q", name, abi, expected)
+		}
+	}
+
+	count := 4
+	vector := fidlgen.Type{
+		Kind:         fidlgen.VectorType,
+		ElementType:  &fidlgen.Type{Kind: fidlgen.StringType, ElementCount: &count},
+		ElementCount: &count,
+		Nullable:     true,
+	}
+	abi, err := root.TypeABIString(vector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if abi != "vector<string:4>:4?" {
+		t.Errorf("got %q", abi)
+	}
+
+	root.Enums[0].Strictness = fidlgen.IsFlexible
+	flexible, err := root.TypeABIString(*identifierType("example/Node"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fidlgen.ABIHash(flexible) == fidlgen.ABIHash(expected) {
+		t.Errorf("strictness change did not affect the ABI hash: %q", flexible)
+	}
+
+	if _, err := root.TypeABIString(*identifierType("example/Missing")); err == nil {
+		t.Errorf("expected an error for an unknown declaration")
+	}
+}
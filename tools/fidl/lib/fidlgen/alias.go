@@ -0,0 +1,240 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ResolvedAlias gives the type that an alias ultimately stands for.
+type ResolvedAlias struct {
+	// Chain lists the aliases traversed during resolution, starting with the
+	// resolved alias itself and ending with the one directly naming a
+	// non-alias type.
+	Chain []EncodedCompoundIdentifier
+	// Type is the underlying type, with the constraints given by each alias of
+	// the chain (optionality and size) applied.
+	Type Type
+}
+
+// ResolveAlias resolves an alias, or a chain of aliases, down to the type it
+// stands for, looking declarations up through decls. Backends that preserve
+// alias names in their output can use this to still know the underlying
+// type. Handle aliases resolve to unconstrained handles, as the IR of aliases
+// does not carry handle subtypes or rights. To resolve aliases of types from
+// dependency libraries, decls should be a Compilation or a Program.
+func ResolveAlias(decls DeclResolver, name EncodedCompoundIdentifier) (ResolvedAlias, error) {
+	decl, ok := decls.LookupDecl(name)
+	if !ok || decl.GetName() != name {
+		return ResolvedAlias{}, fmt.Errorf("alias %s not found", name)
+	}
+	alias, ok := decl.(*TypeAlias)
+	if !ok {
+		return ResolvedAlias{}, fmt.Errorf("%s is not an alias", name)
+	}
+	r := aliasResolver{decls: decls, seen: map[EncodedCompoundIdentifier]struct{}{name: {}}}
+	typ, err := r.resolve(alias.PartialTypeConstructor)
+	if err != nil {
+		return ResolvedAlias{}, fmt.Errorf("resolving alias %s: %w", name, err)
+	}
+	return ResolvedAlias{
+		Chain: append([]EncodedCompoundIdentifier{name}, r.chain...),
+		Type:  typ,
+	}, nil
+}
+
+// ResolveAlias resolves an alias declared by this library or one of its
+// dependencies.
+func (c *Compilation) ResolveAlias(name EncodedCompoundIdentifier) (ResolvedAlias, error) {
+	return ResolveAlias(c, name)
+}
+
+// ResolveAlias resolves an alias declared by this library. Aliases of types
+// from dependency libraries can only be resolved through a Compilation.
+func (r *Root) ResolveAlias(name EncodedCompoundIdentifier) (ResolvedAlias, error) {
+	return ResolveAlias(r, name)
+}
+
+type aliasResolver struct {
+	decls DeclResolver
+	// chain records the aliases traversed from the top-level constructor, not
+	// counting the alias being resolved.
+	chain []EncodedCompoundIdentifier
+	seen  map[EncodedCompoundIdentifier]struct{}
+}
+
+func sizeConstraint(size *Constant) (*int, error) {
+	if size == nil {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(size.Value)
+	if err != nil {
+		return nil, fmt.Errorf("malformed size %q: %w", size.Value, err)
+	}
+	return &n, nil
+}
+
+// clientEndType gives the type of client_end:P.
+func clientEndType(protocol EncodedCompoundIdentifier) Type {
+	return Type{
+		Kind:              IdentifierType,
+		Identifier:        protocol,
+		ProtocolTransport: ChannelTransport,
+		Endpoint:          &Endpoint{Role: ClientEndpoint, Protocol: protocol, Transport: ChannelTransport},
+	}
+}
+
+// serverEndType gives the type of server_end:P, which fidlc names "request".
+func serverEndType(protocol EncodedCompoundIdentifier) Type {
+	return Type{
+		Kind:              RequestType,
+		RequestSubtype:    protocol,
+		ProtocolTransport: ChannelTransport,
+		Endpoint:          &Endpoint{Role: ServerEndpoint, Protocol: protocol, Transport: ChannelTransport},
+	}
+}
+
+// externalDeclType gives the type of a declaration from a dependency library
+// that is listed by the declarations map of the IR but whose body is not
+// available.
+func (r *aliasResolver) externalDeclType(name EncodedCompoundIdentifier) (DeclType, bool) {
+	var roots []*Root
+	switch decls := r.decls.(type) {
+	case *Root:
+		roots = []*Root{decls}
+	case *Compilation:
+		roots = []*Root{&decls.Root}
+	case *Program:
+		for _, root := range decls.Libraries {
+			roots = append(roots, root)
+		}
+	}
+	for _, root := range roots {
+		if declType, ok := root.Decls[name.DeclName()]; ok {
+			return declType, true
+		}
+	}
+	return "", false
+}
+
+// resolve converts a type constructor to a type, resolving any aliases it
+// names along the way.
+func (r *aliasResolver) resolve(ctor PartialTypeConstructor) (Type, error) {
+	size, err := sizeConstraint(ctor.MaybeSize)
+	if err != nil {
+		return Type{}, err
+	}
+	elementType := func() (*Type, error) {
+		if len(ctor.Args) != 1 {
+			return nil, fmt.Errorf("%s expects a single type argument", ctor.Name)
+		}
+		// Aliases named within type arguments are not part of the chain.
+		nested := aliasResolver{decls: r.decls, seen: r.seen}
+		elem, err := nested.resolve(ctor.Args[0])
+		return &elem, err
+	}
+
+	var typ Type
+	if ctor.Name.IsBuiltIn() {
+		switch name := PrimitiveSubtype(ctor.Name); name {
+		case Bool, Int8, Int16, Int32, Int64, Uint8, Uint16, Uint32, Uint64, Float32, Float64:
+			typ = Type{Kind: PrimitiveType, PrimitiveSubtype: name}
+		case "string":
+			typ = Type{Kind: StringType, ElementCount: size}
+		case "vector", "array":
+			elem, err := elementType()
+			if err != nil {
+				return Type{}, err
+			}
+			typ = Type{Kind: VectorType, ElementType: elem, ElementCount: size}
+			if name == "array" {
+				typ.Kind = ArrayType
+			}
+		case "box":
+			elem, err := elementType()
+			if err != nil {
+				return Type{}, err
+			}
+			typ = *elem
+			typ.Nullable = true
+			typ.Boxed = true
+		case "client_end", "server_end", "request":
+			if len(ctor.Args) != 1 {
+				return Type{}, fmt.Errorf("%s expects a single protocol argument", ctor.Name)
+			}
+			if name == "client_end" {
+				typ = clientEndType(ctor.Args[0].Name)
+			} else {
+				typ = serverEndType(ctor.Args[0].Name)
+			}
+		default:
+			return Type{}, fmt.Errorf("unsupported type constructor: %s", ctor.Name)
+		}
+		typ.Nullable = typ.Nullable || ctor.Nullable
+		return typ, nil
+	}
+
+	decl, found := r.decls.LookupDecl(ctor.Name)
+	if alias, ok := decl.(*TypeAlias); ok {
+		if _, ok := r.seen[alias.Name]; ok {
+			return Type{}, fmt.Errorf("cyclic alias: %s", alias.Name)
+		}
+		r.seen[alias.Name] = struct{}{}
+		r.chain = append(r.chain, alias.Name)
+		typ, err = r.resolve(alias.PartialTypeConstructor)
+		delete(r.seen, alias.Name)
+		if err != nil {
+			return Type{}, err
+		}
+		// The referencing constructor may further constrain the aliased type.
+		if size != nil {
+			typ.ElementCount = size
+		}
+	} else {
+		var declType DeclType
+		switch decl.(type) {
+		case *Protocol:
+			declType = ProtocolDeclType
+		case *Resource:
+			declType = ResourceDeclType
+		default:
+			if !found {
+				// The IR of a library lists the declarations it uses from
+				// its dependencies without their bodies: these are named
+				// as is, but aliases among them can only be resolved
+				// through a Compilation.
+				if declType, found = r.externalDeclType(ctor.Name); !found {
+					return Type{}, fmt.Errorf("%s not found", ctor.Name)
+				}
+			}
+		}
+		switch declType {
+		case ProtocolDeclType:
+			// fidlc names the protocol itself for client_end:P.
+			typ = clientEndType(ctor.Name)
+		case ResourceDeclType:
+			// The partial type constructor of an alias does not carry the
+			// handle subtype or rights, so the handle is left unconstrained.
+			typ = Type{
+				Kind:               HandleType,
+				ResourceIdentifier: string(ctor.Name),
+				HandleSubtype:      HandleSubtypeNone,
+				HandleRights:       HandleRightsSameRights,
+			}
+		default:
+			typ = Type{Kind: IdentifierType, Identifier: ctor.Name}
+		}
+	}
+	if ctor.Nullable {
+		typ.Nullable = true
+	}
+	if typ.Nullable && typ.Kind == IdentifierType {
+		if decl, ok := r.decls.LookupDecl(typ.Identifier); ok {
+			_, typ.Boxed = decl.(*Struct)
+		}
+	}
+	return typ, nil
+}
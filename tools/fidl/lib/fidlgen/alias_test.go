@@ -0,0 +1,210 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func aliasDecl(name fidlgen.EncodedCompoundIdentifier, ctor fidlgen.PartialTypeConstructor) fidlgen.TypeAlias {
+	return fidlgen.TypeAlias{Decl: fidlgen.Decl{Name: name}, PartialTypeConstructor: ctor}
+}
+
+func sizeConstant(value string) *fidlgen.Constant {
+	return &fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: value}
+}
+
+// aliasLibrary is a hand-written equivalent of the IR that fidlc would produce
+// for:
+//
+//	library example;
+//
+//	using dep;
+//	using zx;
+//
+//	type Point = struct {};
+//	protocol Calculator {};
+//	alias Bytes = vector<uint8>;
+//	alias SmallBytes = Bytes:16;
+//	alias MaybeSmallBytes = SmallBytes:optional;
+//	alias Points = vector<MaybePoint>;
+//	alias MaybePoint = box<Point>;
+//	alias Name = string:32;
+//	alias Channel = zx.Handle:CHANNEL;
+//	alias Client = client_end:Calculator;
+//	alias Server = server_end:Calculator;
+//	alias RemotePoint = dep.Point;
+var aliasLibrary = fidlgen.Root{
+	Name:      "example",
+	Structs:   []fidlgen.Struct{structDecl("example/Point")},
+	Protocols: []fidlgen.Protocol{{Decl: fidlgen.Decl{Name: "example/Calculator"}}},
+	Decls: fidlgen.DeclMap{
+		"example/Point":      fidlgen.StructDeclType,
+		"example/Calculator": fidlgen.ProtocolDeclType,
+		"dep/Point":          fidlgen.StructDeclType,
+		"zx/Handle":          fidlgen.ResourceDeclType,
+	},
+	TypeAliases: []fidlgen.TypeAlias{
+		aliasDecl("example/Bytes", fidlgen.PartialTypeConstructor{
+			Name: "vector",
+			Args: []fidlgen.PartialTypeConstructor{{Name: "uint8"}},
+		}),
+		aliasDecl("example/SmallBytes", fidlgen.PartialTypeConstructor{
+			Name:      "example/Bytes",
+			MaybeSize: sizeConstant("16"),
+		}),
+		aliasDecl("example/MaybeSmallBytes", fidlgen.PartialTypeConstructor{
+			Name:     "example/SmallBytes",
+			Nullable: true,
+		}),
+		aliasDecl("example/Points", fidlgen.PartialTypeConstructor{
+			Name: "vector",
+			Args: []fidlgen.PartialTypeConstructor{{Name: "example/MaybePoint"}},
+		}),
+		aliasDecl("example/MaybePoint", fidlgen.PartialTypeConstructor{
+			Name: "box",
+			Args: []fidlgen.PartialTypeConstructor{{Name: "example/Point"}},
+		}),
+		aliasDecl("example/Name", fidlgen.PartialTypeConstructor{
+			Name:      "string",
+			MaybeSize: sizeConstant("32"),
+		}),
+		// fidlc does not record the handle subtype of aliases.
+		aliasDecl("example/Channel", fidlgen.PartialTypeConstructor{Name: "zx/Handle"}),
+		// fidlc names the protocol itself for client ends, and "request" for
+		// server ends.
+		aliasDecl("example/Client", fidlgen.PartialTypeConstructor{Name: "example/Calculator"}),
+		aliasDecl("example/Server", fidlgen.PartialTypeConstructor{
+			Name: "request",
+			Args: []fidlgen.PartialTypeConstructor{{Name: "example/Calculator"}},
+		}),
+		aliasDecl("example/RemotePoint", fidlgen.PartialTypeConstructor{Name: "dep/Point"}),
+	},
+}
+
+func TestResolveAlias(t *testing.T) {
+	sixteen, thirtyTwo := 16, 32
+	for _, tc := range []struct {
+		alias    fidlgen.EncodedCompoundIdentifier
+		expected fidlgen.ResolvedAlias
+	}{
+		{
+			alias: "example/MaybeSmallBytes",
+			expected: fidlgen.ResolvedAlias{
+				Chain: []fidlgen.EncodedCompoundIdentifier{
+					"example/MaybeSmallBytes", "example/SmallBytes", "example/Bytes",
+				},
+				Type: fidlgen.Type{
+					Kind:         fidlgen.VectorType,
+					ElementType:  &fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint8},
+					ElementCount: &sixteen,
+					Nullable:     true,
+				},
+			},
+		},
+		{
+			alias: "example/Points",
+			expected: fidlgen.ResolvedAlias{
+				Chain: []fidlgen.EncodedCompoundIdentifier{"example/Points"},
+				Type: fidlgen.Type{
+					Kind: fidlgen.VectorType,
+					ElementType: &fidlgen.Type{
+						Kind:       fidlgen.IdentifierType,
+						Identifier: "example/Point",
+						Nullable:   true,
+						Boxed:      true,
+					},
+				},
+			},
+		},
+		{
+			alias: "example/Name",
+			expected: fidlgen.ResolvedAlias{
+				Chain: []fidlgen.EncodedCompoundIdentifier{"example/Name"},
+				Type:  fidlgen.Type{Kind: fidlgen.StringType, ElementCount: &thirtyTwo},
+			},
+		},
+		{
+			alias: "example/Channel",
+			expected: fidlgen.ResolvedAlias{
+				Chain: []fidlgen.EncodedCompoundIdentifier{"example/Channel"},
+				Type: fidlgen.Type{
+					Kind:               fidlgen.HandleType,
+					ResourceIdentifier: "zx/Handle",
+					HandleSubtype:      fidlgen.HandleSubtypeNone,
+					HandleRights:       fidlgen.HandleRightsSameRights,
+				},
+			},
+		},
+		{
+			alias: "example/Client",
+			expected: fidlgen.ResolvedAlias{
+				Chain: []fidlgen.EncodedCompoundIdentifier{"example/Client"},
+				Type: fidlgen.Type{
+					Kind:              fidlgen.IdentifierType,
+					Identifier:        "example/Calculator",
+					ProtocolTransport: fidlgen.ChannelTransport,
+					Endpoint: &fidlgen.Endpoint{
+						Role:      fidlgen.ClientEndpoint,
+						Protocol:  "example/Calculator",
+						Transport: fidlgen.ChannelTransport,
+					},
+				},
+			},
+		},
+		{
+			alias: "example/Server",
+			expected: fidlgen.ResolvedAlias{
+				Chain: []fidlgen.EncodedCompoundIdentifier{"example/Server"},
+				Type: fidlgen.Type{
+					Kind:              fidlgen.RequestType,
+					RequestSubtype:    "example/Calculator",
+					ProtocolTransport: fidlgen.ChannelTransport,
+					Endpoint: &fidlgen.Endpoint{
+						Role:      fidlgen.ServerEndpoint,
+						Protocol:  "example/Calculator",
+						Transport: fidlgen.ChannelTransport,
+					},
+				},
+			},
+		},
+		{
+			alias: "example/RemotePoint",
+			expected: fidlgen.ResolvedAlias{
+				Chain: []fidlgen.EncodedCompoundIdentifier{"example/RemotePoint"},
+				Type:  fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "dep/Point"},
+			},
+		},
+	} {
+		t.Run(string(tc.alias), func(t *testing.T) {
+			actual, err := aliasLibrary.ResolveAlias(tc.alias)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestResolveAliasErrors(t *testing.T) {
+	root := aliasLibrary
+	root.TypeAliases = append([]fidlgen.TypeAlias{
+		aliasDecl("example/Loop", fidlgen.PartialTypeConstructor{Name: "example/Loop"}),
+		aliasDecl("example/Dangling", fidlgen.PartialTypeConstructor{Name: "dep/Missing"}),
+	}, root.TypeAliases...)
+
+	for _, name := range []fidlgen.EncodedCompoundIdentifier{
+		"example/Loop", "example/Dangling", "example/Point", "example/Missing",
+	} {
+		if _, err := root.ResolveAlias(name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Backends name anonymous layouts by flattening their naming context, e.g.
+// `FooBar` for ["Foo", "Bar"]. Flattening loses the boundaries between the
+// parts, so that distinct contexts such as ["Foo", "Bar"] and ["FooB", "ar"]
+// may yield the same name, which may also be that of a declared type. The
+// deconfliction below assigns unique names, the same way for all backends.
+
+// AnonymousLayoutNames assigns a unique name to each anonymous layout of the
+// library, keyed by the name of the layout declaration. Names are made by
+// flattening naming contexts with flatten, or NamingContext.Join if it is nil;
+// the names of other declarations are flattened the same way, as
+// single-part contexts, and take precedence. Layouts are considered in the
+// order of their naming contexts, part by part: the first layout flattened to
+// a name keeps it if no declaration has it, and the others get the lowest
+// numeric suffix, starting at 2, which yields a name no other layout or
+// declaration has.
+func (r *Root) AnonymousLayoutNames(flatten func(NamingContext) string) map[EncodedCompoundIdentifier]string {
+	if flatten == nil {
+		flatten = NamingContext.Join
+	}
+	taken := make(map[string]struct{})
+	var anonymous []LayoutDeclaration
+	r.ForEachDecl(func(decl Declaration) {
+		if decl.GetName().LibraryName() != r.Name {
+			return
+		}
+		if layout, ok := decl.(LayoutDeclaration); ok && layout.GetNamingContext().IsAnonymous() {
+			anonymous = append(anonymous, layout)
+			return
+		}
+		taken[flatten(NamingContext{string(decl.GetName().Parse().Name)})] = struct{}{}
+	})
+	sort.Slice(anonymous, func(i, j int) bool {
+		a, b := anonymous[i].GetNamingContext(), anonymous[j].GetNamingContext()
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return anonymous[i].GetName() < anonymous[j].GetName()
+	})
+
+	names := make(map[EncodedCompoundIdentifier]string, len(anonymous))
+	flattened := make([]string, len(anonymous))
+	var conflicting []int
+	for i, layout := range anonymous {
+		flattened[i] = flatten(layout.GetNamingContext())
+		if _, ok := taken[flattened[i]]; ok {
+			conflicting = append(conflicting, i)
+			continue
+		}
+		names[layout.GetName()] = flattened[i]
+		taken[flattened[i]] = struct{}{}
+	}
+	// Suffixed names must not be the unsuffixed name of a layout either, even
+	// one which was itself renamed.
+	for _, name := range flattened {
+		taken[name] = struct{}{}
+	}
+	for _, i := range conflicting {
+		for n := 2; ; n++ {
+			candidate := flattened[i] + strconv.Itoa(n)
+			if _, ok := taken[candidate]; !ok {
+				names[anonymous[i].GetName()] = candidate
+				taken[candidate] = struct{}{}
+				break
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func anonymousStruct(name fidlgen.EncodedCompoundIdentifier, namingContext ...string) fidlgen.Struct {
+	s := structDecl(name)
+	s.NamingContext = namingContext
+	return s
+}
+
+func TestAnonymousLayoutNames(t *testing.T) {
+	structs := []fidlgen.Struct{
+		structDecl("example/FooBar"),
+		structDecl("example/Thing"),
+		anonymousStruct("example/A", "Foo", "Bar"),
+		anonymousStruct("example/B", "FooB", "ar"),
+		anonymousStruct("example/C", "Baz", "Qux"),
+		anonymousStruct("example/D", "Thi", "ng"),
+		anonymousStruct("example/E", "Thing", "2"),
+	}
+	expected := map[fidlgen.EncodedCompoundIdentifier]string{
+		"example/A": "FooBar2",
+		"example/B": "FooBar3",
+		"example/C": "BazQux",
+		"example/D": "Thing3",
+		"example/E": "Thing2",
+	}
+
+	root := fidlgen.Root{Name: "example", Structs: structs}
+	if diff := cmp.Diff(expected, root.AnonymousLayoutNames(nil)); diff != "" {
+		t.Errorf("unexpected names (-want +got):\n%s", diff)
+	}
+
+	// The names do not depend on the order of declarations.
+	var reversed []fidlgen.Struct
+	for i := len(structs) - 1; i >= 0; i-- {
+		reversed = append(reversed, structs[i])
+	}
+	root.Structs = reversed
+	if diff := cmp.Diff(expected, root.AnonymousLayoutNames(nil)); diff != "" {
+		t.Errorf("unexpected names in reverse order (-want +got):\n%s", diff)
+	}
+
+	snake := func(nc fidlgen.NamingContext) string {
+		var parts []string
+		for _, part := range nc {
+			parts = append(parts, fidlgen.ToSnakeCase(part))
+		}
+		return strings.Join(parts, "_")
+	}
+	root.Structs = structs[:4]
+	expected = map[fidlgen.EncodedCompoundIdentifier]string{
+		"example/A": "foo_bar2",
+		"example/B": "foo_b_ar",
+	}
+	if diff := cmp.Diff(expected, root.AnonymousLayoutNames(snake)); diff != "" {
+		t.Errorf("unexpected snake case names (-want +got):\n%s", diff)
+	}
+}
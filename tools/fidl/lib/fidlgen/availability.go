@@ -0,0 +1,633 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Version is an API level, at which FIDL elements can be added, deprecated
+// and removed using the @available attribute.
+type Version uint64
+
+const (
+	// HeadVersion is the `HEAD` version, greater than all numbered versions.
+	HeadVersion Version = math.MaxUint64 - 1
+	// LegacyVersion is the `LEGACY` version, greater than all others.
+	LegacyVersion Version = math.MaxUint64
+)
+
+// ParseVersion parses a version, as written in an @available attribute.
+func ParseVersion(s string) (Version, error) {
+	switch s {
+	case "HEAD":
+		return HeadVersion, nil
+	case "LEGACY":
+		return LegacyVersion, nil
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || v == 0 || Version(v) >= HeadVersion {
+		return 0, fmt.Errorf("invalid version: %q", s)
+	}
+	return Version(v), nil
+}
+
+func (v Version) String() string {
+	switch v {
+	case HeadVersion:
+		return "HEAD"
+	case LegacyVersion:
+		return "LEGACY"
+	}
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+// Availability is the structured content of an @available attribute. Unset
+// versions are zero.
+type Availability struct {
+	Platform   string
+	Added      Version
+	Deprecated Version
+	Removed    Version
+	Note       string
+	// Legacy indicates whether a removed element is kept at the LEGACY
+	// version.
+	Legacy bool
+}
+
+// Availability parses the @available attribute, if any. Elements without one
+// have the zero Availability, i.e. are always present.
+func (el Attributes) Availability() (Availability, error) {
+	var a Availability
+	attr, ok := el.LookupAttribute("available")
+	if !ok {
+		return a, nil
+	}
+	for _, arg := range attr.Args {
+		value := arg.ValueString()
+		var err error
+		switch arg.Name {
+		case "platform":
+			a.Platform = value
+		case "added":
+			a.Added, err = ParseVersion(value)
+		case "deprecated":
+			a.Deprecated, err = ParseVersion(value)
+		case "removed":
+			a.Removed, err = ParseVersion(value)
+		case "note":
+			a.Note = value
+		case "legacy":
+			a.Legacy, err = strconv.ParseBool(value)
+		default:
+			err = fmt.Errorf("unknown argument %s", arg.Name)
+		}
+		if err != nil {
+			return Availability{}, fmt.Errorf("@available: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// Inherit fills the unset fields of an element's availability with those of
+// its parent, e.g. of a member with those of its declaration.
+func (a Availability) Inherit(parent Availability) Availability {
+	if a.Platform == "" {
+		a.Platform = parent.Platform
+	}
+	if a.Added == 0 {
+		a.Added = parent.Added
+	}
+	if a.Deprecated == 0 || (parent.Deprecated != 0 && parent.Deprecated < a.Deprecated) {
+		a.Deprecated = parent.Deprecated
+	}
+	if a.Removed == 0 || (parent.Removed != 0 && parent.Removed < a.Removed) {
+		a.Removed = parent.Removed
+		a.Legacy = parent.Legacy
+	}
+	return a
+}
+
+// IsPresentAt returns whether the element is present at the given version.
+func (a Availability) IsPresentAt(v Version) bool {
+	if a.Added != 0 && v < a.Added {
+		return false
+	}
+	if a.Removed != 0 && v >= a.Removed {
+		return v == LegacyVersion && a.Legacy
+	}
+	return true
+}
+
+// IsDeprecatedAt returns whether the element is present and deprecated at the
+// given version.
+func (a Availability) IsDeprecatedAt(v Version) bool {
+	return a.IsPresentAt(v) && a.Deprecated != 0 && v >= a.Deprecated
+}
+
+// availabilityFilter filters elements of a library by availability at a given
+// version.
+type availabilityFilter struct {
+	version Version
+	err     error
+}
+
+// present returns whether an element is present at the filter's version,
+// given its attributes and the availability of its parent. The first error
+// is recorded and causes all elements to be kept.
+func (f *availabilityFilter) present(attrs Attributes, parent Availability) (Availability, bool) {
+	a, err := attrs.Availability()
+	if err != nil {
+		if f.err == nil {
+			f.err = err
+		}
+		return Availability{}, true
+	}
+	a = a.Inherit(parent)
+	return a, a.IsPresentAt(f.version)
+}
+
+// AtVersion returns the library as it is at the given version, i.e. without
+// the declarations, members and compose clauses that @available marks as not
+// yet added or already removed. Removed table and union members are kept as
+// reserved, to preserve their ordinals. Composed methods are dropped along with
+// the compose clauses providing them, unless another clause may still provide
+// them, e.g. one composing a protocol of another library. The masks of
+// bits and the shapes of the layouts losing members, and of everything
+// embedding those, are recomputed.
+func (r *Root) AtVersion(version Version) (Root, error) {
+	f := availabilityFilter{version: version}
+	// changed records the layouts which lost members.
+	changed := make(map[EncodedCompoundIdentifier]bool)
+	res := Root{
+		Name:              r.Name,
+		Experiments:       r.Experiments,
+		Libraries:         r.Libraries,
+		Decls:             make(DeclMap, len(r.Decls)),
+		HandleObjectTypes: r.HandleObjectTypes,
+	}
+
+	r.ForEachDecl(func(decl Declaration) {
+		parent, ok := f.present(decl.GetAttributes(), Availability{})
+		if !ok {
+			return
+		}
+
+		switch v := decl.(type) {
+		case *Const:
+			res.Consts = append(res.Consts, *v)
+		case *Bits:
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				if _, ok := f.present(m.Attributes, parent); ok {
+					newV.Members = append(newV.Members, m)
+				}
+			}
+			if len(newV.Members) != len(v.Members) {
+				f.recomputeMask(&newV)
+			}
+			res.Bits = append(res.Bits, newV)
+		case *Enum:
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				if _, ok := f.present(m.Attributes, parent); ok {
+					newV.Members = append(newV.Members, m)
+				}
+			}
+			res.Enums = append(res.Enums, newV)
+		case *Resource:
+			res.Resources = append(res.Resources, *v)
+		case *Protocol:
+			newV := *v
+			newV.Composed = nil
+			var removed []Decl
+			for _, c := range v.Composed {
+				if _, ok := f.present(c.Attributes, parent); ok {
+					newV.Composed = append(newV.Composed, c)
+				} else {
+					removed = append(removed, c)
+				}
+			}
+			dropped := r.methodsOnlyComposedFrom(removed, newV.Composed)
+			newV.Methods = nil
+			for _, m := range v.Methods {
+				if _, ok := dropped[m.Name]; ok && m.IsComposed {
+					continue
+				}
+				if _, ok := f.present(m.Attributes, parent); ok {
+					newV.Methods = append(newV.Methods, m)
+				}
+			}
+			res.Protocols = append(res.Protocols, newV)
+		case *Service:
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				if _, ok := f.present(m.Attributes, parent); ok {
+					newV.Members = append(newV.Members, m)
+				}
+			}
+			res.Services = append(res.Services, newV)
+		case *Struct:
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				if _, ok := f.present(m.Attributes, parent); ok {
+					newV.Members = append(newV.Members, m)
+				}
+			}
+			changed[v.Name] = len(newV.Members) != len(v.Members)
+			if v.Name.LibraryName() == r.Name {
+				res.Structs = append(res.Structs, newV)
+			} else {
+				res.ExternalStructs = append(res.ExternalStructs, newV)
+			}
+		case *Table:
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				if _, ok := f.present(m.Attributes, parent); ok {
+					newV.Members = append(newV.Members, m)
+				} else {
+					changed[v.Name] = true
+					newV.Members = append(newV.Members, TableMember{
+						Attributes: m.Attributes,
+						Reserved:   true,
+						Name:       m.Name,
+						Ordinal:    m.Ordinal,
+					})
+				}
+			}
+			res.Tables = append(res.Tables, newV)
+		case *Union:
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				if _, ok := f.present(m.Attributes, parent); ok {
+					newV.Members = append(newV.Members, m)
+				} else {
+					changed[v.Name] = true
+					newV.Members = append(newV.Members, UnionMember{
+						Attributes: m.Attributes,
+						Reserved:   true,
+						Name:       m.Name,
+						Ordinal:    m.Ordinal,
+					})
+				}
+			}
+			res.Unions = append(res.Unions, newV)
+		case *TypeAlias:
+			res.TypeAliases = append(res.TypeAliases, *v)
+		case *NewType:
+			res.NewTypes = append(res.NewTypes, *v)
+		}
+		if info, ok := r.Decls[decl.GetName()]; ok {
+			res.Decls[decl.GetName()] = info
+		}
+	})
+	if f.err != nil {
+		return Root{}, f.err
+	}
+
+	for _, d := range r.DeclOrder {
+		if _, ok := res.Decls[d]; ok {
+			res.DeclOrder = append(res.DeclOrder, d)
+		}
+	}
+	if err := res.recomputeShapes(changed); err != nil {
+		return Root{}, err
+	}
+	return res, nil
+}
+
+// methodsOnlyComposedFrom returns the names of the methods gained through the
+// removed compose clauses that none of the kept ones provides. The composed
+// protocols are looked up in the library: if a kept clause composes a
+// protocol of another library, which may provide any method, none is
+// returned.
+func (r *Root) methodsOnlyComposedFrom(removed, kept []Decl) map[Identifier]struct{} {
+	if len(removed) == 0 {
+		return nil
+	}
+	methods := func(clauses []Decl) (map[Identifier]struct{}, bool) {
+		names := make(map[Identifier]struct{})
+		for _, c := range clauses {
+			decl, ok := r.LookupDecl(c.Name)
+			if !ok {
+				return nil, false
+			}
+			protocol, ok := decl.(*Protocol)
+			if !ok {
+				return nil, false
+			}
+			// The methods of a protocol include those it composes itself.
+			for _, m := range protocol.Methods {
+				names[m.Name] = struct{}{}
+			}
+		}
+		return names, true
+	}
+	keptMethods, ok := methods(kept)
+	if !ok {
+		return nil
+	}
+	// Methods of unresolved removed protocols cannot be dropped, since they
+	// are unknown.
+	dropped := make(map[Identifier]struct{})
+	for _, c := range removed {
+		names, ok := methods([]Decl{c})
+		if !ok {
+			continue
+		}
+		for name := range names {
+			if _, ok := keptMethods[name]; !ok {
+				dropped[name] = struct{}{}
+			}
+		}
+	}
+	return dropped
+}
+
+// recomputeMask recomputes the mask of bits from their members. The first
+// error is recorded.
+func (f *availabilityFilter) recomputeMask(b *Bits) {
+	var mask uint64
+	for _, m := range b.Members {
+		value, err := m.Value.AsUint64(b.Type.PrimitiveSubtype)
+		if err != nil {
+			if f.err == nil {
+				f.err = fmt.Errorf("%s.%s: %w", b.Name, m.Name, err)
+			}
+			return
+		}
+		mask |= value
+	}
+	b.Mask = strconv.FormatUint(mask, 10)
+}
+
+// recomputeShapes recomputes the shapes of the given changed layouts, and of
+// the layouts and method payloads embedding them. Layouts are visited in
+// declaration order, which lists the dependencies of each declaration before
+// it, so that a layout is only recomputed once the layouts it embeds are. The
+// shapes of other types are left as the IR gives them.
+func (r *Root) recomputeShapes(changed map[EncodedCompoundIdentifier]bool) error {
+	var layouts []Declaration
+	for i := range r.ExternalStructs {
+		layouts = append(layouts, &r.ExternalStructs[i])
+	}
+	for _, name := range r.DeclOrder {
+		if decl, ok := r.LookupDecl(name); ok {
+			layouts = append(layouts, decl)
+		}
+	}
+	for _, wf := range WireFormatVersions {
+		s := shapeRecomputer{TypeShapeCalculator{WireFormat: wf, Decls: r}, changed}
+		for _, decl := range layouts {
+			if err := s.layout(decl); err != nil {
+				return fmt.Errorf("%s: %w", decl.GetName(), err)
+			}
+		}
+		for i := range r.Protocols {
+			for j := range r.Protocols[i].Methods {
+				m := &r.Protocols[i].Methods[j]
+				for _, payload := range []**Type{&m.RequestPayload, &m.ResponsePayload} {
+					if *payload == nil {
+						continue
+					}
+					// The payload may be shared with the original library.
+					typ := **payload
+					if ok, err := s.typ(&typ); err != nil {
+						return fmt.Errorf("%s.%s: %w", r.Protocols[i].Name, m.Name, err)
+					} else if ok {
+						*payload = &typ
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// shapeRecomputer recomputes the shapes of one wire format for
+// recomputeShapes.
+type shapeRecomputer struct {
+	c       TypeShapeCalculator
+	changed map[EncodedCompoundIdentifier]bool
+}
+
+// typ recomputes the shape of a type if it embeds a changed layout, and
+// returns whether it did.
+func (s shapeRecomputer) typ(typ *Type) (bool, error) {
+	var shape TypeShape
+	switch typ.Kind {
+	case IdentifierType:
+		if !s.changed[typ.Identifier] {
+			return false, nil
+		}
+		var err error
+		if shape, err = s.c.Type(*typ); err != nil {
+			return false, err
+		}
+	case VectorType, ArrayType:
+		if typ.ElementType == nil {
+			return false, nil
+		}
+		elem := *typ.ElementType
+		if ok, err := s.typ(&elem); !ok || err != nil {
+			return false, err
+		}
+		typ.ElementType = &elem
+		if typ.Kind == VectorType {
+			shape = s.c.Vector(elem.Shape(s.c.WireFormat), typ.ElementCount)
+		} else if typ.ElementCount != nil {
+			shape = s.c.Array(elem.Shape(s.c.WireFormat), *typ.ElementCount)
+		} else {
+			return false, fmt.Errorf("array type without an element count")
+		}
+	default:
+		return false, nil
+	}
+	typ.SetShape(s.c.WireFormat, shape)
+	return true, nil
+}
+
+// layout recomputes the shape of a layout if it changed or embeds a changed
+// layout, in which case it is marked as changed itself.
+func (s shapeRecomputer) layout(decl Declaration) error {
+	wf := s.c.WireFormat
+	changed := s.changed[decl.GetName()]
+	switch v := decl.(type) {
+	case *Struct:
+		for i := range v.Members {
+			ok, err := s.typ(&v.Members[i].Type)
+			if err != nil {
+				return err
+			}
+			changed = changed || ok
+		}
+		if !changed {
+			return nil
+		}
+		var shapes []TypeShape
+		for _, m := range v.Members {
+			shapes = append(shapes, m.Type.Shape(wf))
+		}
+		shape, fields := s.c.Struct(shapes)
+		v.SetShape(wf, shape)
+		for i := range v.Members {
+			v.Members[i].SetFieldShape(wf, fields[i])
+			v.Members[i].MaxHandles = shapes[i].MaxHandles
+		}
+	case *Table:
+		maxOrdinal := 0
+		var shapes []TypeShape
+		for i, m := range v.Members {
+			if m.Reserved {
+				continue
+			}
+			ok, err := s.typ(&v.Members[i].Type)
+			if err != nil {
+				return err
+			}
+			changed = changed || ok
+			maxOrdinal = maxInt(maxOrdinal, m.Ordinal)
+			shapes = append(shapes, v.Members[i].Type.Shape(wf))
+		}
+		if !changed {
+			return nil
+		}
+		v.SetShape(wf, s.c.Table(maxOrdinal, shapes))
+	case *Union:
+		// As in TypeShapeCalculator.UnionDecl, the result unions of flexible
+		// methods have a flexible envelope.
+		flexible := v.IsFlexible()
+		var shapes []TypeShape
+		for i, m := range v.Members {
+			if m.Reserved {
+				continue
+			}
+			ok, err := s.typ(&v.Members[i].Type)
+			if err != nil {
+				return err
+			}
+			changed = changed || ok
+			flexible = flexible || (m.Type.Kind == InternalType && m.Type.InternalSubtype == FrameworkErr)
+			shapes = append(shapes, v.Members[i].Type.Shape(wf))
+		}
+		if !changed {
+			return nil
+		}
+		v.SetShape(wf, s.c.Union(flexible, shapes))
+	default:
+		return nil
+	}
+	s.changed[decl.GetName()] = true
+	return nil
+}
+
+// forEachAttributes calls a callback with the attributes of each declaration
+// of the library and of each of their members.
+func (r *Root) forEachAttributes(cb func(Attributes)) {
+	r.ForEachDecl(func(decl Declaration) {
+		cb(decl.GetAttributes())
+		switch v := decl.(type) {
+		case *Bits:
+			for _, m := range v.Members {
+				cb(m.Attributes)
+			}
+		case *Enum:
+			for _, m := range v.Members {
+				cb(m.Attributes)
+			}
+		case *Protocol:
+			for _, m := range v.Methods {
+				cb(m.Attributes)
+			}
+			for _, c := range v.Composed {
+				cb(c.Attributes)
+			}
+		case *Service:
+			for _, m := range v.Members {
+				cb(m.Attributes)
+			}
+		case *Struct:
+			for _, m := range v.Members {
+				cb(m.Attributes)
+			}
+		case *Table:
+			for _, m := range v.Members {
+				cb(m.Attributes)
+			}
+		case *Union:
+			for _, m := range v.Members {
+				cb(m.Attributes)
+			}
+		}
+	})
+}
+
+// Versions returns, in increasing order, the versions at which @available
+// marks an element of the library as added, deprecated or removed, i.e. the
+// versions at which the library changes. LEGACY is included if a removed
+// element is kept at LEGACY.
+func (r *Root) Versions() ([]Version, error) {
+	set := make(map[Version]struct{})
+	var err error
+	r.forEachAttributes(func(attrs Attributes) {
+		a, aerr := attrs.Availability()
+		if aerr != nil {
+			if err == nil {
+				err = aerr
+			}
+			return
+		}
+		for _, v := range []Version{a.Added, a.Deprecated, a.Removed} {
+			if v != 0 {
+				set[v] = struct{}{}
+			}
+		}
+		if a.Legacy {
+			set[LegacyVersion] = struct{}{}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]Version, 0, len(set))
+	for v := range set {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions, nil
+}
+
+// DecomposeByVersion decomposes the library into what it is at each of its
+// Versions, so that tooling can compare adjacent versions or generate
+// per-version artifacts from a single IR. The entry for a version also
+// describes the library at all later versions up to the next entry. A library
+// without any @available is returned as is, at HEAD.
+func (r *Root) DecomposeByVersion() (map[Version]Root, error) {
+	versions, err := r.Versions()
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return map[Version]Root{HeadVersion: *r}, nil
+	}
+	res := make(map[Version]Root, len(versions))
+	for _, v := range versions {
+		root, err := r.AtVersion(v)
+		if err != nil {
+			return nil, err
+		}
+		res[v] = root
+	}
+	return res, nil
+}
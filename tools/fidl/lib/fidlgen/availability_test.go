@@ -0,0 +1,288 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// available returns the attributes holding an @available with the given
+// arguments, given as name/value pairs.
+func available(args ...string) fidlgen.Attributes {
+	attr := fidlgen.Attribute{Name: "available"}
+	for i := 0; i < len(args); i += 2 {
+		attr.Args = append(attr.Args, fidlgen.AttributeArg{
+			Name:  fidlgen.Identifier(args[i]),
+			Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: args[i+1]},
+		})
+	}
+	return fidlgen.Attributes{Attributes: []fidlgen.Attribute{attr}}
+}
+
+func TestAvailability(t *testing.T) {
+	a, err := available("platform", "fuchsia", "added", "1", "deprecated", "2", "removed", "HEAD", "note", "use Bar").Availability()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fidlgen.Availability{
+		Platform:   "fuchsia",
+		Added:      1,
+		Deprecated: 2,
+		Removed:    fidlgen.HeadVersion,
+		Note:       "use Bar",
+	}
+	if diff := cmp.Diff(expected, a); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		version             fidlgen.Version
+		present, deprecated bool
+	}{
+		{1, true, false},
+		{2, true, true},
+		{3, true, true},
+		{fidlgen.HeadVersion, false, false},
+		{fidlgen.LegacyVersion, false, false},
+	} {
+		if a.IsPresentAt(tc.version) != tc.present || a.IsDeprecatedAt(tc.version) != tc.deprecated {
+			t.Errorf("at %s: got present=%t deprecated=%t, want present=%t deprecated=%t", tc.version,
+				a.IsPresentAt(tc.version), a.IsDeprecatedAt(tc.version), tc.present, tc.deprecated)
+		}
+	}
+
+	legacy, err := available("removed", "2", "legacy", "true").Availability()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !legacy.IsPresentAt(fidlgen.LegacyVersion) || legacy.IsPresentAt(2) {
+		t.Errorf("legacy elements should only be present at LEGACY after removal")
+	}
+
+	member, err := available("added", "3").Availability()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(fidlgen.Availability{Platform: "fuchsia", Added: 3, Deprecated: 2, Removed: fidlgen.HeadVersion}, member.Inherit(a)); diff != "" {
+		t.Errorf("Inherit: unexpected diff (-want +got):\n%s", diff)
+	}
+
+	for _, args := range [][]string{{"added", "0"}, {"added", "NEXT"}, {"colour", "red"}} {
+		if _, err := available(args...).Availability(); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestAtVersion(t *testing.T) {
+	s := structDecl("example/S", "old", "new")
+	s.Members[0].Attributes = available("removed", "2")
+	s.Members[1].Attributes = available("added", "2")
+	removed := structDecl("example/Removed")
+	removed.Attributes = available("added", "1", "removed", "2")
+	table := fidlgen.Table{
+		ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+			LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/T"}},
+		},
+		Members: []fidlgen.TableMember{
+			{Name: "kept", Ordinal: 1},
+			{Name: "dropped", Ordinal: 2, Attributes: available("removed", "2")},
+		},
+	}
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{s, removed},
+		Tables:  []fidlgen.Table{table},
+		Decls: fidlgen.DeclMap{
+			"example/S":       fidlgen.StructDeclType,
+			"example/Removed": fidlgen.StructDeclType,
+			"example/T":       fidlgen.TableDeclType,
+		},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{"example/Removed", "example/S", "example/T"},
+	}
+
+	v1, err := root.AtVersion(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v1.Structs) != 2 || len(v1.Structs[0].Members) != 1 || v1.Structs[0].Members[0].Name != "old" {
+		t.Errorf("at 1: got structs %+v", v1.Structs)
+	}
+
+	v2, err := root.AtVersion(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v2.Structs) != 1 || len(v2.Structs[0].Members) != 1 || v2.Structs[0].Members[0].Name != "new" {
+		t.Errorf("at 2: got structs %+v", v2.Structs)
+	}
+	if members := v2.Tables[0].Members; len(members) != 2 || members[0].Reserved || !members[1].Reserved {
+		t.Errorf("at 2: got table members %+v, want the removed one reserved", members)
+	}
+	if diff := cmp.Diff([]fidlgen.EncodedCompoundIdentifier{"example/S", "example/T"}, v2.DeclOrder); diff != "" {
+		t.Errorf("at 2: DeclOrder: unexpected diff (-want +got):\n%s", diff)
+	}
+	if _, ok := v2.Decls["example/Removed"]; ok {
+		t.Errorf("at 2: example/Removed should not be in Decls")
+	}
+
+	root.Structs[0].Attributes = available("added", "bogus")
+	if _, err := root.AtVersion(1); err == nil {
+		t.Errorf("expected an error for a malformed @available")
+	}
+}
+
+// shapeLibrary computes the shapes of the structs and method payloads of a
+// library, given in dependency order, as fidlc would.
+func shapeLibrary(t *testing.T, root *fidlgen.Root) {
+	t.Helper()
+	for _, wf := range fidlgen.WireFormatVersions {
+		c := fidlgen.TypeShapeCalculator{WireFormat: wf, Decls: root}
+		setShape := func(typ *fidlgen.Type) {
+			shape, err := c.Type(*typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			typ.SetShape(wf, shape)
+		}
+		for i := range root.Structs {
+			s := &root.Structs[i]
+			for j := range s.Members {
+				setShape(&s.Members[j].Type)
+			}
+			shape, fields, err := c.StructDecl(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.SetShape(wf, shape)
+			for j := range s.Members {
+				s.Members[j].SetFieldShape(wf, fields[j])
+			}
+		}
+		for i := range root.Protocols {
+			for _, m := range root.Protocols[i].Methods {
+				setShape(m.RequestPayload)
+			}
+		}
+	}
+}
+
+func TestAtVersionRecomputesShapesAndMasks(t *testing.T) {
+	inner := structDecl("example/Inner", "removed", "kept")
+	inner.Members[0].Type = fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint64}
+	inner.Members[0].Attributes = available("removed", "2")
+	inner.Members[1].Type = fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}
+	innerType := fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/Inner"}
+	outer := structDecl("example/Outer", "inner", "flag")
+	outer.Members[0].Type = innerType
+	outer.Members[1].Type = fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Bool}
+	payload := innerType
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{inner, outer},
+		Protocols: []fidlgen.Protocol{{
+			Decl:    fidlgen.Decl{Name: "example/P"},
+			Methods: []fidlgen.Method{{Name: "M", HasRequest: true, RequestPayload: &payload}},
+		}},
+		Bits: []fidlgen.Bits{{
+			LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/B"}},
+			Type:       fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint8},
+			Mask:       "3",
+			Members: []fidlgen.BitsMember{
+				{Name: "ONE", Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: "1"}},
+				{Name: "TWO", Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: "2"}, Attributes: available("removed", "2")},
+			},
+		}},
+		Decls: fidlgen.DeclMap{
+			"example/Inner": fidlgen.StructDeclType,
+			"example/Outer": fidlgen.StructDeclType,
+			"example/P":     fidlgen.ProtocolDeclType,
+			"example/B":     fidlgen.BitsDeclType,
+		},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{"example/B", "example/Inner", "example/Outer", "example/P"},
+	}
+	shapeLibrary(t, &root)
+	original := root.Structs[1].Shape(fidlgen.WireFormatVersionV2)
+
+	v2, err := root.AtVersion(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v2.Bits[0].Mask != "1" {
+		t.Errorf("got mask %s, want 1", v2.Bits[0].Mask)
+	}
+	expected := v2
+	expected.Structs = append([]fidlgen.Struct(nil), v2.Structs...)
+	for i := range expected.Structs {
+		expected.Structs[i].Members = append([]fidlgen.StructMember(nil), v2.Structs[i].Members...)
+	}
+	expectedPayload := *v2.Protocols[0].Methods[0].RequestPayload
+	expected.Protocols = []fidlgen.Protocol{v2.Protocols[0]}
+	expected.Protocols[0].Methods = []fidlgen.Method{v2.Protocols[0].Methods[0]}
+	expected.Protocols[0].Methods[0].RequestPayload = &expectedPayload
+	shapeLibrary(t, &expected)
+	if diff := cmp.Diff(expected, v2); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	if v2.Structs[1].Shape(fidlgen.WireFormatVersionV2) == original {
+		t.Errorf("the shape of example/Outer was not recomputed")
+	}
+	if root.Protocols[0].Methods[0].RequestPayload.Shape(fidlgen.WireFormatVersionV2).InlineSize != 16 {
+		t.Errorf("the shapes of the original library were modified")
+	}
+}
+
+func TestDecomposeByVersion(t *testing.T) {
+	s := structDecl("example/S", "old", "new")
+	s.Attributes = available("added", "1")
+	s.Members[0].Attributes = available("deprecated", "2", "removed", "3", "legacy", "true")
+	s.Members[1].Attributes = available("added", "3")
+	root := fidlgen.Root{
+		Name:      "example",
+		Structs:   []fidlgen.Struct{s},
+		Decls:     fidlgen.DeclMap{"example/S": fidlgen.StructDeclType},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{"example/S"},
+	}
+
+	versions, err := root.Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]fidlgen.Version{1, 2, 3, fidlgen.LegacyVersion}, versions); diff != "" {
+		t.Errorf("Versions: unexpected diff (-want +got):\n%s", diff)
+	}
+
+	roots, err := root.DecomposeByVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := make(map[fidlgen.Version][]fidlgen.Identifier)
+	for v, r := range roots {
+		for _, m := range r.Structs[0].Members {
+			members[v] = append(members[v], m.Name)
+		}
+	}
+	expected := map[fidlgen.Version][]fidlgen.Identifier{
+		1:                     {"old"},
+		2:                     {"old"},
+		3:                     {"new"},
+		fidlgen.LegacyVersion: {"old", "new"},
+	}
+	if diff := cmp.Diff(expected, members); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	unversioned := fidlgen.Root{Name: "example", Structs: []fidlgen.Struct{structDecl("example/S")}}
+	roots, err = unversioned.DecomposeByVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := roots[fidlgen.HeadVersion]; len(roots) != 1 || !ok {
+		t.Errorf("expected an unversioned library to be returned at HEAD, got %v", roots)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import "fmt"
+
+// BindingsFilter describes the exact configuration of a backend, i.e. what
+// part of a library it generates bindings for. Unset fields filter nothing.
+type BindingsFilter struct {
+	// Language is the name of the bindings, as listed by @bindings_denylist.
+	Language string
+	// Version is the API level targeted by the bindings.
+	Version Version
+	// Transport is the transport the bindings support.
+	Transport Transport
+}
+
+// Filter returns the library as seen by bindings of the given configuration,
+// combining AtVersion, ForTransport and ForBindings. It returns a new Root and
+// does not modify r. An error is returned if declarations kept for the
+// transport refer to the protocols it filters out.
+func (r *Root) Filter(f BindingsFilter) (Root, error) {
+	res := *r
+	if f.Version != 0 {
+		var err error
+		if res, err = res.AtVersion(f.Version); err != nil {
+			return Root{}, err
+		}
+	}
+	if f.Transport != "" {
+		if !f.Transport.IsValid() {
+			return Root{}, fmt.Errorf("unknown transport %q", f.Transport)
+		}
+		res = res.ForTransport(f.Transport)
+		if err := res.ValidateEndpoints(); err != nil {
+			return Root{}, fmt.Errorf("%s transport: %w", f.Transport, err)
+		}
+	}
+	if f.Language != "" {
+		res = res.ForBindings(f.Language)
+	}
+	return res, nil
+}
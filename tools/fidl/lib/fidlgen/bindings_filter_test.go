@@ -0,0 +1,87 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestFilter(t *testing.T) {
+	s := structDecl("example/S", "old", "not_go", "new")
+	s.Members[0].Attributes = available("removed", "2")
+	s.Members[1].Attributes = fidlgen.Attributes{Attributes: []fidlgen.Attribute{{
+		Name: "bindings_denylist",
+		Args: []fidlgen.AttributeArg{{
+			Name:  "value",
+			Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: "go"},
+		}},
+	}}}
+	s.Members[2].Attributes = available("added", "2")
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{s},
+		Protocols: []fidlgen.Protocol{
+			{Decl: fidlgen.Decl{Name: "example/Device", Attributes: transportAttributes("Driver")}},
+			{Decl: fidlgen.Decl{Name: "example/Node"}},
+		},
+		Decls: fidlgen.DeclMap{
+			"example/S":      fidlgen.StructDeclType,
+			"example/Device": fidlgen.ProtocolDeclType,
+			"example/Node":   fidlgen.ProtocolDeclType,
+		},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{"example/Device", "example/Node", "example/S"},
+	}
+
+	names := func(r fidlgen.Root) []string {
+		var names []string
+		for _, m := range r.Structs[0].Members {
+			names = append(names, string(m.Name))
+		}
+		for _, p := range r.Protocols {
+			names = append(names, string(p.Name))
+		}
+		return names
+	}
+	for _, tc := range []struct {
+		filter   fidlgen.BindingsFilter
+		expected []string
+	}{
+		{
+			filter:   fidlgen.BindingsFilter{},
+			expected: []string{"old", "not_go", "new", "example/Device", "example/Node"},
+		},
+		{
+			filter:   fidlgen.BindingsFilter{Language: "go"},
+			expected: []string{"old", "new", "example/Device", "example/Node"},
+		},
+		{
+			filter:   fidlgen.BindingsFilter{Language: "go", Version: 2, Transport: fidlgen.ChannelTransport},
+			expected: []string{"new", "example/Node"},
+		},
+		{
+			filter:   fidlgen.BindingsFilter{Language: "rust", Version: 1, Transport: fidlgen.DriverTransport},
+			expected: []string{"old", "not_go", "example/Device"},
+		},
+	} {
+		filtered, err := root.Filter(tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(tc.expected, names(filtered)); diff != "" {
+			t.Errorf("%+v: unexpected diff (-want +got):\n%s", tc.filter, diff)
+		}
+	}
+
+	if _, err := root.Filter(fidlgen.BindingsFilter{Transport: "Carrier pigeon"}); err == nil {
+		t.Errorf("expected an error for an unknown transport")
+	}
+	root.Structs[0].Attributes = available("added", "bogus")
+	if _, err := root.Filter(fidlgen.BindingsFilter{Version: 1}); err == nil {
+		t.Errorf("expected an error for a malformed @available")
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+)
+
+// BitsLayout describes how the members of a bits declaration occupy its
+// underlying integer type.
+type BitsLayout struct {
+	// Width is the width of the underlying type, in bits.
+	Width int
+	// Covered has the bits of all members set.
+	Covered uint64
+	// Free has the bits of the underlying type which no member uses set, i.e.
+	// those available to future members.
+	Free uint64
+}
+
+// Exhausted returns whether every bit of the underlying type is used by a
+// member, so that no member can be added without changing the type.
+func (l BitsLayout) Exhausted() bool {
+	return l.Free == 0
+}
+
+// CoveredCount returns the number of bits used by members.
+func (l BitsLayout) CoveredCount() int {
+	return bits.OnesCount64(l.Covered)
+}
+
+// FreeCount returns the number of bits available to future members.
+func (l BitsLayout) FreeCount() int {
+	return bits.OnesCount64(l.Free)
+}
+
+// FreeBits returns the positions of the free bits, lowest first, where 0 is
+// the least significant bit.
+func (l BitsLayout) FreeBits() []int {
+	var positions []int
+	for free := l.Free; free != 0; free &= free - 1 {
+		positions = append(positions, bits.TrailingZeros64(free))
+	}
+	return positions
+}
+
+// Layout describes how the members of the bits declaration occupy its
+// underlying type. It fails if the underlying type is not an unsigned integer
+// or a member value does not fit in it.
+func (b *Bits) Layout() (BitsLayout, error) {
+	subtype := b.Type.PrimitiveSubtype
+	if b.Type.Kind != PrimitiveType || !subtype.IsUnsigned() {
+		return BitsLayout{}, fmt.Errorf("%s: underlying type %s is not an unsigned integer", b.Name, subtype)
+	}
+	layout := BitsLayout{Width: integerSubtypeBitWidths[subtype]}
+	for _, m := range b.Members {
+		v, err := m.Value.AsUint64(subtype)
+		if err != nil {
+			return BitsLayout{}, fmt.Errorf("%s.%s: %w", b.Name, m.Name, err)
+		}
+		layout.Covered |= v
+	}
+	all := uint64(1)<<layout.Width - 1
+	if layout.Width == 64 {
+		all = ^uint64(0)
+	}
+	layout.Free = all &^ layout.Covered
+	return layout, nil
+}
+
+// Mask64 parses the mask of the bits declaration, i.e. the union of the values
+// of its members.
+func (b *Bits) Mask64() (uint64, error) {
+	mask, err := strconv.ParseUint(b.Mask, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid mask %q: %w", b.Name, b.Mask, err)
+	}
+	return mask, nil
+}
+
+// UnsetBits returns the positions of the bits of the underlying type which
+// are not set in the mask, lowest first, where 0 is the least significant
+// bit. Unlike Layout, it relies on the mask computed by fidlc rather than on
+// the member values.
+func (b *Bits) UnsetBits() ([]int, error) {
+	mask, err := b.Mask64()
+	if err != nil {
+		return nil, err
+	}
+	width, ok := integerSubtypeBitWidths[b.Type.PrimitiveSubtype]
+	if b.Type.Kind != PrimitiveType || !ok {
+		return nil, fmt.Errorf("%s: underlying type %s is not an integer", b.Name, b.Type.PrimitiveSubtype)
+	}
+	var positions []int
+	for i := 0; i < width; i++ {
+		if mask&(1<<i) == 0 {
+			positions = append(positions, i)
+		}
+	}
+	return positions, nil
+}
+
+// Value64 parses the value of the member.
+func (m *BitsMember) Value64() (uint64, error) {
+	v, err := m.Value.AsUint64(Uint64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", m.Name, err)
+	}
+	return v, nil
+}
+
+// Index returns the position of the single bit the member sets, where 0 is
+// the least significant bit. It fails if the member sets no bit, or several.
+func (m *BitsMember) Index() (int, error) {
+	v, err := m.Value64()
+	if err != nil {
+		return 0, err
+	}
+	if bits.OnesCount64(v) != 1 {
+		return 0, fmt.Errorf("%s: value %d is not a power of two", m.Name, v)
+	}
+	return bits.TrailingZeros64(v), nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func bitsDecl(subtype fidlgen.PrimitiveSubtype, members ...fidlgen.BitsMember) fidlgen.Bits {
+	return fidlgen.Bits{
+		LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Flags"}},
+		Type:       fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: subtype},
+		Members:    members,
+	}
+}
+
+func TestBitsLayout(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		bits      fidlgen.Bits
+		expected  fidlgen.BitsLayout
+		freeBits  []int
+		exhausted bool
+	}{
+		{
+			name:     "empty",
+			bits:     bitsDecl(fidlgen.Uint8),
+			expected: fidlgen.BitsLayout{Width: 8, Free: 0xff},
+			freeBits: []int{0, 1, 2, 3, 4, 5, 6, 7},
+		},
+		{
+			name:     "sparse",
+			bits:     bitsDecl(fidlgen.Uint8, bitsMember("A", "1"), bitsMember("B", "4"), bitsMember("C", "128")),
+			expected: fidlgen.BitsLayout{Width: 8, Covered: 0x85, Free: 0x7a},
+			freeBits: []int{1, 3, 4, 5, 6},
+		},
+		{
+			name:      "exhausted",
+			bits:      bitsDecl(fidlgen.Uint16, bitsMember("LOW", "255"), bitsMember("HIGH", "65280")),
+			expected:  fidlgen.BitsLayout{Width: 16, Covered: 0xffff},
+			exhausted: true,
+		},
+		{
+			name:     "uint64",
+			bits:     bitsDecl(fidlgen.Uint64, bitsMember("TOP", "9223372036854775808")),
+			expected: fidlgen.BitsLayout{Width: 64, Covered: 1 << 63, Free: 1<<63 - 1},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			layout, err := tc.bits.Layout()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expected, layout); diff != "" {
+				t.Errorf("layout (-want +got):\n%s", diff)
+			}
+			if got := layout.Exhausted(); got != tc.exhausted {
+				t.Errorf("got Exhausted() = %t, want %t", got, tc.exhausted)
+			}
+			if got, want := layout.CoveredCount()+layout.FreeCount(), layout.Width; got != want {
+				t.Errorf("got %d covered and free bits, want %d", got, want)
+			}
+			if tc.freeBits != nil {
+				if diff := cmp.Diff(tc.freeBits, layout.FreeBits()); diff != "" {
+					t.Errorf("free bits (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestBitsLayoutErrors(t *testing.T) {
+	for name, bits := range map[string]fidlgen.Bits{
+		"signed":   bitsDecl(fidlgen.Int8, bitsMember("A", "1")),
+		"overflow": bitsDecl(fidlgen.Uint8, bitsMember("A", "256")),
+	} {
+		if _, err := bits.Layout(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestBitsMaskAndIndices(t *testing.T) {
+	b := bitsDecl(fidlgen.Uint8, bitsMember("A", "1"), bitsMember("B", "16"), bitsMember("AB", "17"))
+	b.Mask = "17"
+	mask, err := b.Mask64()
+	if err != nil || mask != 17 {
+		t.Errorf("got Mask64() = %d, %v", mask, err)
+	}
+	unset, err := b.UnsetBits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]int{1, 2, 3, 5, 6, 7}, unset); diff != "" {
+		t.Errorf("unset bits: unexpected diff (-want +got):\n%s", diff)
+	}
+
+	for i, expected := range []int{0, 4} {
+		if index, err := b.Members[i].Index(); err != nil || index != expected {
+			t.Errorf("%s: got Index() = %d, %v, want %d", b.Members[i].Name, index, err, expected)
+		}
+	}
+	if v, err := b.Members[2].Value64(); err != nil || v != 17 {
+		t.Errorf("got Value64() = %d, %v", v, err)
+	}
+	if _, err := b.Members[2].Index(); err == nil {
+		t.Errorf("expected an error for a multi-bit member")
+	}
+
+	b.Mask = "-1"
+	if _, err := b.Mask64(); err == nil {
+		t.Errorf("expected an error for an invalid mask")
+	}
+	wide := bitsDecl(fidlgen.Uint64)
+	wide.Mask = "18446744073709551615"
+	if unset, err := wide.UnsetBits(); err != nil || len(unset) != 0 {
+		t.Errorf("got unset bits %v, %v for a full mask", unset, err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// fidlc accepts any value that fits in the underlying type for a bits member,
+// including values setting several bits, which name combinations of flags,
+// e.g. `RW = 3` next to `R = 1` and `W = 2`. Backends generating one match arm
+// per bit, and linters, need to tell those apart from mistakes.
+
+// BitsIssueKind classifies the issues found by Bits.Validate.
+type BitsIssueKind string
+
+const (
+	// BitsOutOfRange means the value of the member does not fit in the
+	// underlying type of the bits. Other checks are skipped for the member.
+	BitsOutOfRange BitsIssueKind = "out_of_range"
+	// BitsZero means the member sets no bit.
+	BitsZero BitsIssueKind = "zero"
+	// BitsDuplicate means the member has the same value as Other, declared
+	// earlier.
+	BitsDuplicate BitsIssueKind = "duplicate"
+	// BitsCombination means the member sets several bits, each of which is
+	// set by a single-bit member. This documents a combination of flags
+	// rather than a mistake: backends may generate it as a constant rather
+	// than as a match arm.
+	BitsCombination BitsIssueKind = "combination"
+	// BitsMultiBit means the member sets several bits, some of which no
+	// single-bit member sets.
+	BitsMultiBit BitsIssueKind = "multi_bit"
+	// BitsOverlap means the member and Other, declared earlier, both set
+	// several bits and share some but not all of them.
+	BitsOverlap BitsIssueKind = "overlap"
+)
+
+// BitsIssue is an issue with a member of a bits declaration.
+type BitsIssue struct {
+	Kind BitsIssueKind
+	// Member is the name of the member.
+	Member Identifier
+	// Other is the name of the other member involved, for duplicates and
+	// overlaps.
+	Other Identifier
+}
+
+func (i BitsIssue) String() string {
+	if i.Other != "" {
+		return fmt.Sprintf("%s: %s with %s", i.Member, i.Kind, i.Other)
+	}
+	return fmt.Sprintf("%s: %s", i.Member, i.Kind)
+}
+
+// Validate checks that each member of the bits declaration sets a single bit
+// of the underlying type, distinct from those of the other members, and
+// reports the members which do not, in declaration order. It returns nil if
+// all members are distinct single bits.
+func (b *Bits) Validate() []BitsIssue {
+	values := make([]uint64, len(b.Members))
+	inRange := make([]bool, len(b.Members))
+	singleBits := uint64(0)
+	for i, m := range b.Members {
+		v, err := m.Value.AsUint64(b.Type.PrimitiveSubtype)
+		values[i], inRange[i] = v, err == nil
+		if inRange[i] && bits.OnesCount64(v) == 1 {
+			singleBits |= v
+		}
+	}
+
+	var issues []BitsIssue
+	for i, m := range b.Members {
+		v := values[i]
+		switch {
+		case !inRange[i]:
+			issues = append(issues, BitsIssue{Kind: BitsOutOfRange, Member: m.Name})
+			continue
+		case v == 0:
+			issues = append(issues, BitsIssue{Kind: BitsZero, Member: m.Name})
+			continue
+		}
+		duplicate := false
+		for j := 0; j < i && !duplicate; j++ {
+			if inRange[j] && values[j] == v {
+				issues = append(issues, BitsIssue{Kind: BitsDuplicate, Member: m.Name, Other: b.Members[j].Name})
+				duplicate = true
+			}
+		}
+		if duplicate || bits.OnesCount64(v) == 1 {
+			continue
+		}
+		if v&^singleBits == 0 {
+			issues = append(issues, BitsIssue{Kind: BitsCombination, Member: m.Name})
+		} else {
+			issues = append(issues, BitsIssue{Kind: BitsMultiBit, Member: m.Name})
+		}
+		for j := 0; j < i; j++ {
+			shared := values[j] & v
+			if inRange[j] && bits.OnesCount64(values[j]) > 1 && shared != 0 && shared != values[j] && shared != v {
+				issues = append(issues, BitsIssue{Kind: BitsOverlap, Member: m.Name, Other: b.Members[j].Name})
+			}
+		}
+	}
+	return issues
+}
@@ -0,0 +1,46 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestBitsValidate(t *testing.T) {
+	valid := bitsDecl(fidlgen.Uint8, bitsMember("A", "1"), bitsMember("B", "2"), bitsMember("H", "128"))
+	if issues := valid.Validate(); issues != nil {
+		t.Errorf("got issues %v for distinct single bits", issues)
+	}
+
+	b := bitsDecl(fidlgen.Uint8,
+		bitsMember("R", "1"),
+		bitsMember("W", "2"),
+		bitsMember("X", "4"),
+		bitsMember("RW", "3"),
+		bitsMember("WX", "6"),
+		bitsMember("ALSO_R", "1"),
+		bitsMember("NONE", "0"),
+		bitsMember("HIGH", "48"),
+		bitsMember("HUGE", "256"),
+	)
+	expected := []fidlgen.BitsIssue{
+		{Kind: fidlgen.BitsCombination, Member: "RW"},
+		{Kind: fidlgen.BitsCombination, Member: "WX"},
+		{Kind: fidlgen.BitsOverlap, Member: "WX", Other: "RW"},
+		{Kind: fidlgen.BitsDuplicate, Member: "ALSO_R", Other: "R"},
+		{Kind: fidlgen.BitsZero, Member: "NONE"},
+		{Kind: fidlgen.BitsMultiBit, Member: "HIGH"},
+		{Kind: fidlgen.BitsOutOfRange, Member: "HUGE"},
+	}
+	if diff := cmp.Diff(expected, b.Validate()); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	if got, want := expected[2].String(), "WX: overlap with RW"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
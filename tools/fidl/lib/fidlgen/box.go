@@ -0,0 +1,133 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// An optional struct is stored out-of-line, behind a presence marker, whereas
+// other optional types are stored inline (e.g. optional unions and strings).
+// The IR represents both the same way, as nullable types, so backends would
+// need to look up the declaration to tell whether they should generate a
+// box/pointer type or an inline optional. Type.Boxed saves them the trouble.
+//
+// FIDL is migrating the syntax of optional structs from `T:optional` to
+// `box<T>`; under the no_optional_structs experiment, only the latter is
+// allowed. Both have the same wire format, and so are both marked Boxed.
+
+// forEachTopLevelType calls a provided callback on each type appearing
+// directly in a declaration of the library (i.e., not nested within another
+// type).
+func (r *Root) forEachTopLevelType(cb func(*Type)) {
+	r.forEachDeclType(func(_ Declaration, typ *Type) {
+		cb(typ)
+	})
+}
+
+// forEachDeclType is like forEachTopLevelType, but also passes the
+// declaration in which each type appears.
+func (r *Root) forEachDeclType(cb func(Declaration, *Type)) {
+	r.ForEachDecl(func(d Declaration) {
+		visit := func(typ *Type) { cb(d, typ) }
+		switch decl := d.(type) {
+		case *Const:
+			visit(&decl.Type)
+		case *Bits:
+			visit(&decl.Type)
+		case *Resource:
+			visit(&decl.Type)
+			for i := range decl.Properties {
+				visit(&decl.Properties[i].Type)
+			}
+		case *Protocol:
+			for i := range decl.Methods {
+				m := &decl.Methods[i]
+				for _, typ := range []*Type{m.RequestPayload, m.ResponsePayload, m.ResultType, m.ValueType, m.ErrorType} {
+					if typ != nil {
+						visit(typ)
+					}
+				}
+			}
+		case *Service:
+			for i := range decl.Members {
+				visit(&decl.Members[i].Type)
+			}
+		case *Struct:
+			for i := range decl.Members {
+				visit(&decl.Members[i].Type)
+			}
+		case *Table:
+			for i := range decl.Members {
+				visit(&decl.Members[i].Type)
+			}
+		case *Union:
+			for i := range decl.Members {
+				visit(&decl.Members[i].Type)
+			}
+		case *NewType:
+			visit(&decl.Type)
+		}
+	})
+}
+
+// MarkBoxedTypes sets Type.Boxed on the optional struct types of the library.
+// DecodeJSONIr already does this: it only needs to be called on IR that is
+// constructed or modified by other means.
+func (r *Root) MarkBoxedTypes() {
+	decls := r.DeclInfo()
+	r.forEachTopLevelType(func(typ *Type) {
+		WalkType(typ, func(typ *Type) bool {
+			typ.Boxed = typ.Kind == IdentifierType && typ.Nullable &&
+				decls[typ.Identifier].Type == StructDeclType
+			return true
+		})
+	})
+}
+
+// BoxSyntaxRequired returns whether optional structs of the library can only
+// be written as `box<T>`, as opposed to `T:optional`, which is the case under
+// the no_optional_structs experiment. Backends migrating their generated
+// types for optional structs can key off of this.
+func (r *Root) BoxSyntaxRequired() bool {
+	return r.Experiments.Contains(ExperimentNoOptionalStructs)
+}
+
+// Optionality is how a type is made optional on the wire. Bindings generally
+// represent optional types of different optionality differently, e.g. an
+// optional struct as a pointer or box, but an optional union inline.
+type Optionality string
+
+const (
+	// RequiredOptionality is that of types which are not optional.
+	RequiredOptionality Optionality = "required"
+	// BoxedOptionality is that of optional structs, i.e. `box<T>`, which are
+	// stored out-of-line behind a presence marker.
+	BoxedOptionality Optionality = "boxed"
+	// OutOfLineOptionality is that of optional vectors and strings, whose
+	// absence is given by that of their out-of-line data.
+	OutOfLineOptionality Optionality = "out_of_line"
+	// HandleOptionality is that of optional handles and protocol endpoints,
+	// which are absent handles.
+	HandleOptionality Optionality = "handle"
+	// UnionOptionality is that of optional unions, which are stored inline as
+	// an empty envelope.
+	UnionOptionality Optionality = "union"
+)
+
+// Optionality returns how the type is made optional. It relies on Boxed and
+// Endpoint, and so on IR from DecodeJSONIr or on which MarkBoxedTypes and
+// MarkEndpointTypes have been called.
+func (t *Type) Optionality() Optionality {
+	if !t.Nullable {
+		return RequiredOptionality
+	}
+	switch {
+	case t.Boxed:
+		return BoxedOptionality
+	case t.Kind == VectorType || t.Kind == StringType:
+		return OutOfLineOptionality
+	case t.Kind == HandleType || t.Kind == RequestType || t.Endpoint != nil:
+		return HandleOptionality
+	default:
+		return UnionOptionality
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestMarkBoxedTypes(t *testing.T) {
+	optional := func(name fidlgen.EncodedCompoundIdentifier) fidlgen.Type {
+		typ := *identifierType(name)
+		typ.Nullable = true
+		return typ
+	}
+	boxedElement := optional("example/Point")
+
+	holder := structDecl("example/Holder", "boxed", "required", "union", "vector", "external")
+	holder.Members[0].Type = optional("example/Point")
+	holder.Members[1].Type = *identifierType("example/Point")
+	holder.Members[2].Type = optional("example/Shape")
+	holder.Members[3].Type = fidlgen.Type{Kind: fidlgen.VectorType, ElementType: &boxedElement}
+	holder.Members[4].Type = optional("dep/External")
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{structDecl("example/Point"), holder},
+		Unions: []fidlgen.Union{
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Shape"}},
+				},
+			},
+		},
+		Libraries: []fidlgen.Library{
+			{
+				Name:  "dep",
+				Decls: fidlgen.DeclInfoMap{"dep/External": {Type: fidlgen.StructDeclType}},
+			},
+		},
+	}
+	root.MarkBoxedTypes()
+
+	members := root.Structs[1].Members
+	for i, expected := range []bool{true, false, false, false, true} {
+		if members[i].Type.Boxed != expected {
+			t.Errorf("%s: got Boxed = %t, want %t", members[i].Name, members[i].Type.Boxed, expected)
+		}
+	}
+	if !members[3].Type.ElementType.Boxed {
+		t.Errorf("vector element: got Boxed = false, want true")
+	}
+}
+
+func TestBoxSyntaxRequired(t *testing.T) {
+	root := fidlgen.Root{}
+	if root.BoxSyntaxRequired() {
+		t.Errorf("got true without the no_optional_structs experiment")
+	}
+	root.Experiments = fidlgen.Experiments{fidlgen.ExperimentNoOptionalStructs}
+	if !root.BoxSyntaxRequired() {
+		t.Errorf("got false with the no_optional_structs experiment")
+	}
+}
+
+func TestOptionality(t *testing.T) {
+	optional := func(typ fidlgen.Type) fidlgen.Type {
+		typ.Nullable = true
+		return typ
+	}
+	holder := structDecl("example/Holder", "required", "box", "vector", "string", "handle", "client", "server", "union")
+	holder.Members[0].Type = *identifierType("example/Point")
+	holder.Members[1].Type = optional(*identifierType("example/Point"))
+	holder.Members[2].Type = optional(fidlgen.Type{Kind: fidlgen.VectorType, ElementType: &fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint8}})
+	holder.Members[3].Type = optional(fidlgen.Type{Kind: fidlgen.StringType})
+	holder.Members[4].Type = optional(fidlgen.Type{Kind: fidlgen.HandleType, HandleSubtype: fidlgen.HandleSubtypeChannel})
+	holder.Members[5].Type = optional(*identifierType("example/Node"))
+	holder.Members[6].Type = optional(fidlgen.Type{Kind: fidlgen.RequestType, RequestSubtype: "example/Node"})
+	holder.Members[7].Type = optional(*identifierType("example/Shape"))
+	root := fidlgen.Root{
+		Name:      "example",
+		Structs:   []fidlgen.Struct{structDecl("example/Point"), holder},
+		Protocols: []fidlgen.Protocol{{Decl: fidlgen.Decl{Name: "example/Node"}}},
+		Unions: []fidlgen.Union{
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Shape"}},
+				},
+			},
+		},
+	}
+	root.MarkBoxedTypes()
+	root.MarkEndpointTypes()
+
+	members := root.Structs[1].Members
+	for i, expected := range []fidlgen.Optionality{
+		fidlgen.RequiredOptionality,
+		fidlgen.BoxedOptionality,
+		fidlgen.OutOfLineOptionality,
+		fidlgen.OutOfLineOptionality,
+		fidlgen.HandleOptionality,
+		fidlgen.HandleOptionality,
+		fidlgen.HandleOptionality,
+		fidlgen.UnionOptionality,
+	} {
+		if actual := members[i].Type.Optionality(); actual != expected {
+			t.Errorf("%s: got %q, want %q", members[i].Name, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package builders constructs well-formed IR types and declarations, as fidlc
+// would emit them: with type and field shapes computed and naming contexts
+// set. It serves tests needing IR beyond what a fixture provides, and
+// backends synthesizing declarations absent from the IR, e.g. result unions.
+package builders
+
+import (
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// withShape sets the shape of a type in all wire formats, for types whose
+// shape does not depend on the wire format.
+func withShape(typ fidlgen.Type, shape fidlgen.TypeShape) fidlgen.Type {
+	for _, wf := range fidlgen.WireFormatVersions {
+		typ.SetShape(wf, shape)
+	}
+	return typ
+}
+
+// withShapes sets the shape of a type in each wire format, as computed by f
+// from the shape of another type in the same wire format.
+func withShapes(typ fidlgen.Type, from fidlgen.Type, f func(fidlgen.TypeShapeCalculator, fidlgen.TypeShape) fidlgen.TypeShape) fidlgen.Type {
+	for _, wf := range fidlgen.WireFormatVersions {
+		typ.SetShape(wf, f(fidlgen.TypeShapeCalculator{WireFormat: wf}, from.Shape(wf)))
+	}
+	return typ
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package builders_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen/builders"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+// The built declarations are compared with those fidlc emitted for the
+// fixtures, shapes included.
+var opts = cmp.Options{fidlgentest.AllowUnexported(), fidlgentest.IgnoreLocations()}
+
+func TestBuildUnion(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.ReservedUnion)
+	u := builders.NewUnion("test.reservedunion/U").
+		Flexible().
+		Reserved(1).
+		Member(2, "a", builders.Primitive(fidlgen.Int32)).
+		Reserved(3).
+		Member(4, "b", builders.String(nil)).
+		Build()
+	if diff := cmp.Diff(root.Unions[0], u, opts); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestBuildMethod(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	var want fidlgen.Method
+	for _, m := range root.Protocols[0].Methods {
+		if m.Name == "FlexibleTwoWay" {
+			want = m
+		}
+	}
+
+	m := builders.NewMethod("test.openprotocol/P", "FlexibleTwoWay", want.Ordinal).
+		Flexible().
+		Response(nil).
+		Build()
+	if diff := cmp.Diff(want, m.Method, opts); diff != "" {
+		t.Errorf("method: unexpected diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(root.Unions[0], *m.Result, opts); diff != "" {
+		t.Errorf("result: unexpected diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(root.Structs[0], *m.Response, opts); diff != "" {
+		t.Errorf("response: unexpected diff (-want +got):\n%s", diff)
+	}
+
+	strict := builders.NewMethod("test.openprotocol/P", "TwoWay", 1).Response(nil).Build()
+	if strict.Result != nil || strict.Response != nil || strict.ResponsePayload != nil {
+		t.Errorf("expected nothing to be synthesized for a strict method, got %+v", strict)
+	}
+}
+
+func TestBuildStruct(t *testing.T) {
+	count := 2
+	s := builders.NewStruct("example/S").
+		Member("a", builders.Primitive(fidlgen.Uint8)).
+		Member("b", builders.Primitive(fidlgen.Uint32)).
+		Member("c", builders.Vector(builders.Handle(fidlgen.HandleSubtypeVmo, fidlgen.HandleRightsSameRights), &count)).
+		Member("d", builders.Array(builders.Primitive(fidlgen.Uint16), 3)).
+		Resource().
+		Build()
+
+	shape := fidlgen.TypeShape{
+		InlineSize:   32,
+		Alignment:    8,
+		Depth:        1,
+		MaxHandles:   2,
+		MaxOutOfLine: 8,
+		HasPadding:   true,
+	}
+	if diff := cmp.Diff(shape, s.TypeShapeV2); diff != "" {
+		t.Errorf("shape: unexpected diff (-want +got):\n%s", diff)
+	}
+	fields := []fidlgen.FieldShape{{Offset: 0, Padding: 3}, {Offset: 4, Padding: 0}, {Offset: 8, Padding: 0}, {Offset: 24, Padding: 2}}
+	for i, m := range s.Members {
+		if diff := cmp.Diff(fields[i], m.FieldShapeV2); diff != "" {
+			t.Errorf("%s: unexpected diff (-want +got):\n%s", m.Name, diff)
+		}
+	}
+	if diff := cmp.Diff(fidlgen.NamingContext{"S"}, s.NamingContext); diff != "" {
+		t.Errorf("naming context: unexpected diff (-want +got):\n%s", diff)
+	}
+
+	empty := builders.NewStruct("example/Empty").Build()
+	if empty.TypeShapeV1.InlineSize != 1 || empty.TypeShapeV2.InlineSize != 1 {
+		t.Errorf("expected the empty struct to have size 1, got %+v", empty.TypeShapeV2)
+	}
+
+	boxed := builders.Box(builders.StructType(s))
+	if boxed.TypeShapeV2.InlineSize != 8 || boxed.TypeShapeV2.MaxOutOfLine != 40 || boxed.TypeShapeV2.Depth != 2 {
+		t.Errorf("unexpected boxed shape: %+v", boxed.TypeShapeV2)
+	}
+}
+
+func TestBuildHandle(t *testing.T) {
+	vmo := builders.Handle(fidlgen.HandleSubtypeVmo, fidlgen.HandleRightsSameRights)
+	if vmo.ObjType != uint32(fidlgen.ObjectTypeVmo) {
+		t.Errorf("vmo: got object type %d, want %d", vmo.ObjType, fidlgen.ObjectTypeVmo)
+	}
+	objTypes := fidlgen.HandleObjectTypes{"counter": 42}
+	counter := builders.HandleOf(objTypes, "counter", fidlgen.HandleRightsSameRights)
+	if counter.ObjType != 42 {
+		t.Errorf("counter: got object type %d, want 42", counter.ObjType)
+	}
+	if vmo := builders.HandleOf(objTypes, fidlgen.HandleSubtypeVmo, fidlgen.HandleRightsSameRights); vmo.ObjType != uint32(fidlgen.ObjectTypeVmo) {
+		t.Errorf("vmo: got object type %d, want the fallback %d", vmo.ObjType, fidlgen.ObjectTypeVmo)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package builders
+
+import (
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// layoutDecl returns the LayoutDecl of a declaration with the given name and
+// naming context. An empty naming context defaults to that of a top-level
+// declaration.
+func layoutDecl(name fidlgen.EncodedCompoundIdentifier, namingContext []string) fidlgen.LayoutDecl {
+	if len(namingContext) == 0 {
+		namingContext = []string{string(name.Parse().Name)}
+	}
+	return fidlgen.LayoutDecl{
+		Decl:          fidlgen.Decl{Name: name},
+		NamingContext: namingContext,
+	}
+}
+
+// StructBuilder builds a struct, laying its members out as fidlc does.
+type StructBuilder struct {
+	s fidlgen.Struct
+}
+
+// NewStruct starts building a struct. An anonymous struct is given its naming
+// context, e.g. "P", "Method", "Response" for a method's response payload.
+func NewStruct(name fidlgen.EncodedCompoundIdentifier, namingContext ...string) *StructBuilder {
+	return &StructBuilder{s: fidlgen.Struct{
+		ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+			LayoutDecl: layoutDecl(name, namingContext),
+		},
+	}}
+}
+
+// Member appends a member to the struct.
+func (b *StructBuilder) Member(name fidlgen.Identifier, typ fidlgen.Type) *StructBuilder {
+	b.s.Members = append(b.s.Members, fidlgen.StructMember{
+		Type:       typ,
+		Name:       name,
+		MaxHandles: typ.Shape(fidlgen.WireFormatVersionV2).MaxHandles,
+	})
+	return b
+}
+
+// Resource marks the struct as a resource type.
+func (b *StructBuilder) Resource() *StructBuilder {
+	b.s.Resourceness = fidlgen.IsResourceType
+	return b
+}
+
+// Build returns the struct.
+func (b *StructBuilder) Build() fidlgen.Struct {
+	s := b.s
+	s.Members = append([]fidlgen.StructMember{}, b.s.Members...)
+	for _, wf := range fidlgen.WireFormatVersions {
+		var shapes []fidlgen.TypeShape
+		for _, m := range s.Members {
+			shapes = append(shapes, m.Type.Shape(wf))
+		}
+		shape, fields := fidlgen.TypeShapeCalculator{WireFormat: wf}.Struct(shapes)
+		s.SetShape(wf, shape)
+		for i := range s.Members {
+			s.Members[i].SetFieldShape(wf, fields[i])
+		}
+	}
+	return s
+}
+
+// UnionBuilder builds a union.
+type UnionBuilder struct {
+	u fidlgen.Union
+	// flexibleEnvelope forces the union to report a flexible envelope, as
+	// fidlc does for the result unions of flexible methods.
+	flexibleEnvelope bool
+}
+
+// NewUnion starts building a strict union. An anonymous union is given its
+// naming context, as for NewStruct.
+func NewUnion(name fidlgen.EncodedCompoundIdentifier, namingContext ...string) *UnionBuilder {
+	return &UnionBuilder{u: fidlgen.Union{
+		ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+			LayoutDecl: layoutDecl(name, namingContext),
+		},
+		Strictness: fidlgen.IsStrict,
+	}}
+}
+
+// Member appends a member with the given ordinal to the union.
+func (b *UnionBuilder) Member(ordinal int, name fidlgen.Identifier, typ fidlgen.Type) *UnionBuilder {
+	b.u.Members = append(b.u.Members, fidlgen.UnionMember{
+		Ordinal:      ordinal,
+		Type:         typ,
+		Name:         name,
+		MaxOutOfLine: typ.Shape(fidlgen.WireFormatVersionV2).MaxOutOfLine,
+	})
+	return b
+}
+
+// Reserved appends a reserved member with the given ordinal to the union.
+func (b *UnionBuilder) Reserved(ordinal int) *UnionBuilder {
+	b.u.Members = append(b.u.Members, fidlgen.UnionMember{
+		Ordinal:  ordinal,
+		Reserved: true,
+	})
+	return b
+}
+
+// Flexible makes the union flexible.
+func (b *UnionBuilder) Flexible() *UnionBuilder {
+	b.u.Strictness = fidlgen.IsFlexible
+	return b
+}
+
+// Resource marks the union as a resource type.
+func (b *UnionBuilder) Resource() *UnionBuilder {
+	b.u.Resourceness = fidlgen.IsResourceType
+	return b
+}
+
+// Build returns the union.
+func (b *UnionBuilder) Build() fidlgen.Union {
+	u := b.u
+	u.Members = append([]fidlgen.UnionMember{}, b.u.Members...)
+	flexible := u.IsFlexible() || b.flexibleEnvelope
+	for _, wf := range fidlgen.WireFormatVersions {
+		var shapes []fidlgen.TypeShape
+		for _, m := range u.Members {
+			if !m.Reserved {
+				shapes = append(shapes, m.Type.Shape(wf))
+			}
+		}
+		u.SetShape(wf, fidlgen.TypeShapeCalculator{WireFormat: wf}.Union(flexible, shapes))
+	}
+	return u
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package builders
+
+import (
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// MethodBuilder builds a protocol method, along with the declarations fidlc
+// synthesizes for the responses of flexible two-way methods and of methods
+// using error syntax.
+type MethodBuilder struct {
+	protocol fidlgen.EncodedCompoundIdentifier
+	method   fidlgen.Method
+	response *fidlgen.Type
+}
+
+// NewMethod starts building a strict one-way method of a protocol, without
+// a request payload.
+func NewMethod(protocol fidlgen.EncodedCompoundIdentifier, name fidlgen.Identifier, ordinal uint64) *MethodBuilder {
+	strict := true
+	return &MethodBuilder{
+		protocol: protocol,
+		method: fidlgen.Method{
+			Ordinal:     ordinal,
+			Name:        name,
+			MaybeStrict: &strict,
+			HasRequest:  true,
+		},
+	}
+}
+
+// Flexible makes the method flexible.
+func (b *MethodBuilder) Flexible() *MethodBuilder {
+	strict := false
+	b.method.MaybeStrict = &strict
+	return b
+}
+
+// Request sets the request payload of the method.
+func (b *MethodBuilder) Request(payload fidlgen.Type) *MethodBuilder {
+	b.method.RequestPayload = &payload
+	return b
+}
+
+// Response makes the method two-way, with the given response payload, or
+// none if it is nil.
+func (b *MethodBuilder) Response(payload *fidlgen.Type) *MethodBuilder {
+	b.method.HasResponse = true
+	b.response = payload
+	return b
+}
+
+// Event makes the method an event, with the given payload, or none if it is
+// nil.
+func (b *MethodBuilder) Event(payload *fidlgen.Type) *MethodBuilder {
+	b.method.HasRequest = false
+	b.method.RequestPayload = nil
+	return b.Response(payload)
+}
+
+// Error makes the method use error syntax, with the given error type.
+func (b *MethodBuilder) Error(typ fidlgen.Type) *MethodBuilder {
+	b.method.HasError = true
+	b.method.ErrorType = &typ
+	return b
+}
+
+// BuiltMethod is a method along with the declarations synthesized for it.
+type BuiltMethod struct {
+	fidlgen.Method
+	// Response is the empty struct synthesized as the success value of the
+	// result union, if the method has a result union but no response payload.
+	Response *fidlgen.Struct
+	// Result is the result union synthesized as the response payload of
+	// flexible two-way methods and of methods using error syntax.
+	Result *fidlgen.Union
+}
+
+// Build returns the method and the declarations synthesized for it.
+func (b *MethodBuilder) Build() BuiltMethod {
+	built := BuiltMethod{Method: b.method}
+	m := &built.Method
+	twoWay := m.HasRequest && m.HasResponse
+	if !twoWay || (m.IsStrict() && !m.HasError) {
+		m.ResponsePayload = b.response
+		return built
+	}
+
+	library := b.protocol.LibraryName()
+	protocolName := string(b.protocol.Parse().Name)
+	layoutName := func(suffix string) fidlgen.EncodedCompoundIdentifier {
+		return fidlgen.EncodedCompoundIdentifier(string(library) + "/" + protocolName + "_" + string(m.Name) + "_" + suffix)
+	}
+
+	value := b.response
+	if value == nil {
+		response := NewStruct(layoutName("Response"), protocolName, string(m.Name), "Response").Build()
+		built.Response = &response
+		typ := StructType(response)
+		value = &typ
+	}
+	result := NewUnion(layoutName("Result"), protocolName, string(m.Name), "Result").Member(1, "response", *value)
+	if m.HasError {
+		result.Member(2, "err", *m.ErrorType)
+	} else {
+		result.Reserved(2)
+	}
+	if m.IsFlexible() {
+		// As in the IR of irtestdata, whose fidlc still names the variant
+		// transport_err. Its transport_error subtype decodes as FrameworkErr.
+		result.Member(3, "transport_err", withShape(fidlgen.Type{
+			Kind:            fidlgen.InternalType,
+			InternalSubtype: fidlgen.FrameworkErr,
+		}, fidlgen.PrimitiveTypeShape(fidlgen.Int32)))
+		result.flexibleEnvelope = true
+	}
+	union := result.Build()
+	built.Result = &union
+
+	payload, resultType := UnionType(union), UnionType(union)
+	m.ResponsePayload = &payload
+	m.ResultType = &resultType
+	m.ValueType = value
+	return built
+}
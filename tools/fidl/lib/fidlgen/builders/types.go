@@ -0,0 +1,130 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package builders
+
+import (
+	"fmt"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// Primitive returns a primitive type.
+func Primitive(subtype fidlgen.PrimitiveSubtype) fidlgen.Type {
+	return withShape(fidlgen.Type{
+		Kind:             fidlgen.PrimitiveType,
+		PrimitiveSubtype: subtype,
+	}, fidlgen.PrimitiveTypeShape(subtype))
+}
+
+// String returns a string type, bounded to max bytes if max is not nil.
+func String(max *int) fidlgen.Type {
+	return withShape(fidlgen.Type{
+		Kind:         fidlgen.StringType,
+		ElementCount: max,
+	}, fidlgen.TypeShapeCalculator{WireFormat: fidlgen.WireFormatVersionV2}.String(max))
+}
+
+// Vector returns a vector type, bounded to max elements if max is not nil.
+func Vector(elem fidlgen.Type, max *int) fidlgen.Type {
+	return withShapes(fidlgen.Type{
+		Kind:         fidlgen.VectorType,
+		ElementType:  &elem,
+		ElementCount: max,
+	}, elem, func(c fidlgen.TypeShapeCalculator, s fidlgen.TypeShape) fidlgen.TypeShape {
+		return c.Vector(s, max)
+	})
+}
+
+// Array returns an array type.
+func Array(elem fidlgen.Type, count int) fidlgen.Type {
+	return withShapes(fidlgen.Type{
+		Kind:         fidlgen.ArrayType,
+		ElementType:  &elem,
+		ElementCount: &count,
+	}, elem, func(c fidlgen.TypeShapeCalculator, s fidlgen.TypeShape) fidlgen.TypeShape {
+		return c.Array(s, count)
+	})
+}
+
+// Handle returns a handle type of the zx library, of a subtype fidlgen knows
+// the object type of.
+func Handle(subtype fidlgen.HandleSubtype, rights fidlgen.HandleRights) fidlgen.Type {
+	return HandleOf(nil, subtype, rights)
+}
+
+// HandleOf returns a handle type of the zx library, whose object type is
+// looked up in objTypes, e.g. the Root.HandleObjectTypes of the library the
+// type is built for. This supports subtypes newer than fidlgen.
+func HandleOf(objTypes fidlgen.HandleObjectTypes, subtype fidlgen.HandleSubtype, rights fidlgen.HandleRights) fidlgen.Type {
+	return withShape(fidlgen.Type{
+		Kind:               fidlgen.HandleType,
+		HandleSubtype:      subtype,
+		HandleRights:       rights,
+		ObjType:            uint32(objTypes.ObjectType(subtype)),
+		ResourceIdentifier: "zx/Handle",
+	}, fidlgen.HandleTypeShape)
+}
+
+// ClientEnd returns the type of the client end of a protocol over channels.
+func ClientEnd(protocol fidlgen.EncodedCompoundIdentifier) fidlgen.Type {
+	return withShape(fidlgen.Type{
+		Kind:              fidlgen.IdentifierType,
+		Identifier:        protocol,
+		ProtocolTransport: fidlgen.ChannelTransport,
+		Endpoint:          &fidlgen.Endpoint{Role: fidlgen.ClientEndpoint, Protocol: protocol, Transport: fidlgen.ChannelTransport},
+	}, fidlgen.HandleTypeShape)
+}
+
+// ServerEnd returns the type of the server end of a protocol over channels.
+func ServerEnd(protocol fidlgen.EncodedCompoundIdentifier) fidlgen.Type {
+	return withShape(fidlgen.Type{
+		Kind:              fidlgen.RequestType,
+		RequestSubtype:    protocol,
+		ProtocolTransport: fidlgen.ChannelTransport,
+		Endpoint:          &fidlgen.Endpoint{Role: fidlgen.ServerEndpoint, Protocol: protocol, Transport: fidlgen.ChannelTransport},
+	}, fidlgen.HandleTypeShape)
+}
+
+// StructType returns the type referring to a struct.
+func StructType(s fidlgen.Struct) fidlgen.Type {
+	typ := fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: s.Name}
+	for _, wf := range fidlgen.WireFormatVersions {
+		typ.SetShape(wf, s.Shape(wf))
+	}
+	return typ
+}
+
+// UnionType returns the type referring to a union.
+func UnionType(u fidlgen.Union) fidlgen.Type {
+	typ := fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: u.Name}
+	for _, wf := range fidlgen.WireFormatVersions {
+		typ.SetShape(wf, u.Shape(wf))
+	}
+	return typ
+}
+
+// Box makes a struct type optional, i.e. `box<T>`, moving it out-of-line.
+func Box(typ fidlgen.Type) fidlgen.Type {
+	if typ.Kind != fidlgen.IdentifierType {
+		panic(fmt.Sprintf("only structs can be boxed, not %s", typ))
+	}
+	typ.Nullable = true
+	typ.Boxed = true
+	return withShapes(typ, typ, func(c fidlgen.TypeShapeCalculator, s fidlgen.TypeShape) fidlgen.TypeShape {
+		return c.Box(s)
+	})
+}
+
+// Optional makes a type optional in place, which does not change its shape:
+// it applies to strings, vectors, handles, protocol endpoints and unions. See
+// Box for structs.
+func Optional(typ fidlgen.Type) fidlgen.Type {
+	switch typ.Kind {
+	case fidlgen.PrimitiveType, fidlgen.ArrayType:
+		panic(fmt.Sprintf("%s cannot be optional", typ))
+	}
+	typ.Nullable = true
+	return typ
+}
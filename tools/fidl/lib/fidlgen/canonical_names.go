@@ -0,0 +1,176 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"sort"
+	"strings"
+)
+
+// RFC-0040 requires the names of the declarations of a library, and those of
+// the members of a declaration, to have distinct canonical forms, so that
+// bindings converting them to the case of their language do not produce
+// clashing identifiers. fidlc enforces it, but hand-built IR may not honor
+// it, and the case conversions of some languages are lossier than the
+// canonical form, e.g. `foo_1` and `foo1` are both `Foo1` in UpperCamelCase.
+// NameCollisions reports the names which would clash, per language.
+
+// CanonicalName returns the RFC-0040 canonical form of a name: its parts, as
+// split by ToSnakeCase, in lower case and joined by underscores.
+func CanonicalName(name string) string {
+	return ToSnakeCase(name)
+}
+
+// nameCollisionLanguages lists the name transformers of the languages
+// NameCollisions checks, by the names @bindings_denylist gives them.
+var nameCollisionLanguages = map[string]NameTransformer{
+	"cpp":  CppNames,
+	"dart": DartNames,
+	"go":   GoNames,
+	"rust": RustNames,
+}
+
+// NameCollision is a set of names which clash in the bindings of some
+// languages.
+type NameCollision struct {
+	// Scope is the library, for declarations, or the declaration, for its
+	// members and methods.
+	Scope string
+	// Names are the clashing names: members and methods in declaration
+	// order, and declarations in the order of Root.ForEachDecl.
+	Names []Identifier
+	// Locations are the locations of the names. Those of members, which the
+	// IR does not give, are that of their declaration.
+	Locations []Location
+	// Canonical is the canonical name of the names if they share one, which
+	// breaks RFC-0040, and is empty otherwise.
+	Canonical string
+	// Languages are the languages whose bindings the names clash in, sorted.
+	Languages []string
+}
+
+// scopedName is a name of a scope, along with its location and the way
+// NameTransformer converts it.
+type scopedName struct {
+	name      Identifier
+	location  Location
+	transform func(NameTransformer, Identifier) string
+}
+
+// NameCollisions reports the names of the declarations of the library, and
+// those of the members and methods of each declaration, which clash once
+// converted by CppNames, DartNames, GoNames or RustNames. Collisions are
+// sorted by scope, then by the order of their first name.
+func (r *Root) NameCollisions() []NameCollision {
+	languages := make([]string, 0, len(nameCollisionLanguages))
+	for language := range nameCollisionLanguages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	var collisions []NameCollision
+	check := func(scope string, names []scopedName) {
+		byNames := make(map[string]*NameCollision)
+		var found []*NameCollision
+		for _, language := range languages {
+			t := nameCollisionLanguages[language]
+			groups := make(map[string][]int)
+			var order []string
+			for i, n := range names {
+				converted := n.transform(t, n.name)
+				if _, ok := groups[converted]; !ok {
+					order = append(order, converted)
+				}
+				groups[converted] = append(groups[converted], i)
+			}
+			for _, converted := range order {
+				group := groups[converted]
+				if len(group) < 2 {
+					continue
+				}
+				var clashing []string
+				for _, i := range group {
+					clashing = append(clashing, string(names[i].name))
+				}
+				key := strings.Join(clashing, "\x00")
+				c, ok := byNames[key]
+				if !ok {
+					c = &NameCollision{Scope: scope, Canonical: CanonicalName(clashing[0])}
+					for _, i := range group {
+						c.Names = append(c.Names, names[i].name)
+						c.Locations = append(c.Locations, names[i].location)
+						if CanonicalName(string(names[i].name)) != c.Canonical {
+							c.Canonical = ""
+						}
+					}
+					byNames[key] = c
+					found = append(found, c)
+				}
+				c.Languages = append(c.Languages, language)
+			}
+		}
+		// Report collisions in the order of their first name.
+		first := make(map[Identifier]int, len(names))
+		for i := len(names) - 1; i >= 0; i-- {
+			first[names[i].name] = i
+		}
+		sort.SliceStable(found, func(i, j int) bool {
+			return first[found[i].Names[0]] < first[found[j].Names[0]]
+		})
+		for _, c := range found {
+			collisions = append(collisions, *c)
+		}
+	}
+
+	var decls []scopedName
+	var scopes []Declaration
+	r.ForEachDecl(func(decl Declaration) {
+		if decl.GetName().LibraryName() != r.Name {
+			return
+		}
+		transform := NameTransformer.DeclName
+		if _, ok := decl.(*Const); ok {
+			transform = NameTransformer.ConstName
+		}
+		decls = append(decls, scopedName{decl.GetName().Parse().Name, decl.GetLocation(), transform})
+		scopes = append(scopes, decl)
+	})
+	check(string(r.Name), decls)
+
+	sort.SliceStable(scopes, func(i, j int) bool {
+		return scopes[i].GetName() < scopes[j].GetName()
+	})
+	for _, decl := range scopes {
+		scope := string(decl.GetName())
+		switch decl := decl.(type) {
+		case DeclarationWithMembers:
+			transform := NameTransformer.MemberName
+			switch decl.(type) {
+			case *Enum:
+				transform = NameTransformer.EnumMemberName
+			case *Bits:
+				transform = NameTransformer.BitsMemberName
+			}
+			var members []scopedName
+			decl.ForEachMember(func(m MemberDeclaration) {
+				members = append(members, scopedName{m.GetName(), decl.GetLocation(), transform})
+			})
+			check(scope, members)
+		case *Protocol:
+			var methods []scopedName
+			for _, m := range decl.Methods {
+				methods = append(methods, scopedName{m.Name, m.Location, NameTransformer.MethodName})
+			}
+			check(scope, methods)
+		case *Service:
+			var members []scopedName
+			for _, m := range decl.Members {
+				members = append(members, scopedName{m.Name, decl.Location, NameTransformer.MemberName})
+			}
+			check(scope, members)
+		}
+	}
+	return collisions
+}
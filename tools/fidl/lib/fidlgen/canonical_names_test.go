@@ -0,0 +1,101 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestCanonicalName(t *testing.T) {
+	for _, name := range []string{"FooBar", "foo_bar", "FOO_BAR", "fooBar"} {
+		if actual := fidlgen.CanonicalName(name); actual != "foo_bar" {
+			t.Errorf("%s: got %s, want foo_bar", name, actual)
+		}
+	}
+}
+
+func TestNameCollisions(t *testing.T) {
+	at := func(line int) fidlgen.Location {
+		return fidlgen.Location{Filename: "example.fidl", Line: line}
+	}
+	fooBar := structDecl("example/FooBar", "member_1", "member1", "other")
+	fooBar.Location = at(1)
+	fooBarSnake := structDecl("example/foo_bar")
+	fooBarSnake.Location = at(2)
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{fooBar, fooBarSnake},
+		Protocols: []fidlgen.Protocol{{
+			Decl: fidlgen.Decl{Name: "example/P", Location: at(3)},
+			Methods: []fidlgen.Method{
+				{Name: "GetValue", Location: at(4)},
+				{Name: "get_value", Location: at(5)},
+			},
+		}},
+	}
+
+	expected := []fidlgen.NameCollision{
+		{
+			Scope:     "example",
+			Names:     []fidlgen.Identifier{"FooBar", "foo_bar"},
+			Locations: []fidlgen.Location{at(1), at(2)},
+			Canonical: "foo_bar",
+			Languages: []string{"cpp", "dart", "go", "rust"},
+		},
+		{
+			Scope:     "example/FooBar",
+			Names:     []fidlgen.Identifier{"member_1", "member1"},
+			Locations: []fidlgen.Location{at(1), at(1)},
+			Languages: []string{"dart", "go"},
+		},
+		{
+			Scope:     "example/P",
+			Names:     []fidlgen.Identifier{"GetValue", "get_value"},
+			Locations: []fidlgen.Location{at(4), at(5)},
+			Canonical: "get_value",
+			Languages: []string{"cpp", "dart", "go", "rust"},
+		},
+	}
+	if diff := cmp.Diff(expected, root.NameCollisions()); diff != "" {
+		t.Errorf("unexpected collisions (-want +got):\n%s", diff)
+	}
+
+	if collisions := (&fidlgen.Root{Name: "example", Structs: []fidlgen.Struct{structDecl("example/S", "a", "b")}}).NameCollisions(); len(collisions) != 0 {
+		t.Errorf("unexpected collisions: %+v", collisions)
+	}
+}
+
+func TestNameCollisionsOfEnumAndBitsMembers(t *testing.T) {
+	at := fidlgen.Location{Filename: "example.fidl", Line: 1}
+	enum := enumDecl(fidlgen.Uint8, enumMember("MEMBER_1", "1"), enumMember("MEMBER1", "2"))
+	enum.Location = at
+	bits := bitsDecl(fidlgen.Uint8, bitsMember("MEMBER_1", "1"), bitsMember("MEMBER1", "2"))
+	bits.Location = at
+	root := fidlgen.Root{Name: "example", Enums: []fidlgen.Enum{enum}, Bits: []fidlgen.Bits{bits}}
+
+	// Members are converted as enum and bits members, e.g. to UpperCamelCase
+	// enum variants and SCREAMING_SNAKE_CASE bits constants in Rust, rather
+	// than as struct members.
+	expected := []fidlgen.NameCollision{
+		{
+			Scope:     string(enum.Name),
+			Names:     []fidlgen.Identifier{"MEMBER_1", "MEMBER1"},
+			Locations: []fidlgen.Location{at, at},
+			Languages: []string{"cpp", "dart", "go", "rust"},
+		},
+		{
+			Scope:     string(bits.Name),
+			Names:     []fidlgen.Identifier{"MEMBER_1", "MEMBER1"},
+			Locations: []fidlgen.Location{at, at},
+			Languages: []string{"cpp", "dart", "go"},
+		},
+	}
+	if diff := cmp.Diff(expected, root.NameCollisions()); diff != "" {
+		t.Errorf("unexpected collisions (-want +got):\n%s", diff)
+	}
+}
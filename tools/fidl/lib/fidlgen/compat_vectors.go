@@ -0,0 +1,119 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// CompatExpectation is the expected outcome of sending a message to bindings
+// generated from the newer version of a protocol.
+type CompatExpectation string
+
+const (
+	// CompatAccepted means the message should be dispatched to the method.
+	CompatAccepted CompatExpectation = "accepted"
+	// CompatRejected means the message should be treated as an unknown
+	// interaction: never dispatched to a method handler.
+	CompatRejected CompatExpectation = "rejected"
+)
+
+// CompatTestVector is a message header that a peer built against the older
+// version of a protocol may send, along with how bindings of the newer version
+// are expected to handle it.
+type CompatTestVector struct {
+	Protocol EncodedCompoundIdentifier `json:"protocol"`
+	Method   Identifier                `json:"method"`
+	// IsEvent is true if the message is an event, i.e. sent by the server.
+	IsEvent bool   `json:"is_event"`
+	Ordinal uint64 `json:"ordinal"`
+	// Header is the hex-encoded transactional message header, as sent for the
+	// method over the V2 wire format.
+	Header string            `json:"header"`
+	Expect CompatExpectation `json:"expect"`
+}
+
+const (
+	// The at-rest flag indicating the V2 wire format.
+	messageHeaderWireFormatV2 = 0x02
+	// The dynamic flag indicating a flexible method.
+	messageHeaderFlexible    = 0x80
+	messageHeaderMagicNumber = 0x01
+)
+
+// encodeMessageHeader encodes the transactional message header sent for a
+// method, using the given transaction ID.
+func encodeMessageHeader(txid uint32, ordinal uint64, flexible bool) []byte {
+	header := make([]byte, MessageHeaderSize)
+	binary.LittleEndian.PutUint32(header[0:4], txid)
+	header[4] = messageHeaderWireFormatV2
+	if flexible {
+		header[6] = messageHeaderFlexible
+	}
+	header[7] = messageHeaderMagicNumber
+	binary.LittleEndian.PutUint64(header[8:16], ordinal)
+	return header
+}
+
+// CompatTestVectors generates test vectors checking that bindings generated
+// from a newer version of a set of protocols handle the messages of the older
+// version: methods that are still present must be dispatched under the same
+// ordinal, and removed ones must be rejected. Composed methods are covered
+// under each composing protocol, so that removals from a composed protocol are
+// checked everywhere they have an effect.
+//
+// Protocols that are absent from the newer version are skipped, as there are
+// no bindings to test. A method whose ordinal changed between versions, e.g.
+// through its @selector, is a breaking change and reported as an error.
+func CompatTestVectors(before, after *Program) ([]CompatTestVector, error) {
+	var vectors []CompatTestVector
+	var errs []string
+	before.ForEachLibrary(func(root *Root) {
+		for _, protocol := range root.Protocols {
+			decl, ok := after.LookupDecl(protocol.Name)
+			if !ok {
+				continue
+			}
+			newer, ok := decl.(*Protocol)
+			if !ok {
+				continue
+			}
+			newMethods := make(map[Identifier]*Method)
+			for i := range newer.Methods {
+				newMethods[newer.Methods[i].Name] = &newer.Methods[i]
+			}
+			for _, m := range protocol.Methods {
+				v := CompatTestVector{
+					Protocol: protocol.Name,
+					Method:   m.Name,
+					IsEvent:  m.IsEvent(),
+					Ordinal:  m.Ordinal,
+					Expect:   CompatRejected,
+				}
+				var txid uint32
+				if m.IsTwoWay() {
+					txid = 1
+				}
+				v.Header = hex.EncodeToString(encodeMessageHeader(txid, m.Ordinal, m.IsFlexible()))
+				if newMethod, ok := newMethods[m.Name]; ok {
+					if newMethod.Ordinal != m.Ordinal {
+						errs = append(errs, fmt.Sprintf("%s.%s: ordinal changed from %#x to %#x",
+							protocol.Name, m.Name, m.Ordinal, newMethod.Ordinal))
+						continue
+					}
+					v.Expect = CompatAccepted
+				}
+				vectors = append(vectors, v)
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("incompatible protocol changes:\n%s", strings.Join(errs, "\n"))
+	}
+	return vectors, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func compatProgram(t *testing.T, methods ...fidlgen.Method) *fidlgen.Program {
+	t.Helper()
+	p, err := fidlgen.NewProgram([]fidlgen.Root{
+		{
+			Name: "example",
+			Protocols: []fidlgen.Protocol{
+				{Decl: fidlgen.Decl{Name: "example/Node"}, Methods: methods},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestCompatTestVectors(t *testing.T) {
+	flexible := false
+	closeMethod := fidlgen.Method{Name: "Close", Ordinal: 0x1122334455667788, HasRequest: true, HasResponse: true}
+	sync := fidlgen.Method{Name: "Sync", Ordinal: 2, HasRequest: true, MaybeStrict: &flexible}
+	onOpen := fidlgen.Method{Name: "OnOpen", Ordinal: 3, HasResponse: true}
+
+	vectors, err := fidlgen.CompatTestVectors(
+		compatProgram(t, closeMethod, sync, onOpen),
+		compatProgram(t, closeMethod, fidlgen.Method{Name: "Added", Ordinal: 4, HasRequest: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []fidlgen.CompatTestVector{
+		{
+			Protocol: "example/Node",
+			Method:   "Close",
+			Ordinal:  0x1122334455667788,
+			Header:   "01000000020000018877665544332211",
+			Expect:   fidlgen.CompatAccepted,
+		},
+		{
+			Protocol: "example/Node",
+			Method:   "Sync",
+			Ordinal:  2,
+			Header:   "00000000020080010200000000000000",
+			Expect:   fidlgen.CompatRejected,
+		},
+		{
+			Protocol: "example/Node",
+			Method:   "OnOpen",
+			IsEvent:  true,
+			Ordinal:  3,
+			Header:   "00000000020000010300000000000000",
+			Expect:   fidlgen.CompatRejected,
+		},
+	}
+	if diff := cmp.Diff(expected, vectors); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompatTestVectorsOrdinalChange(t *testing.T) {
+	before := compatProgram(t, fidlgen.Method{Name: "Close", Ordinal: 1, HasRequest: true})
+	after := compatProgram(t, fidlgen.Method{Name: "Close", Ordinal: 2, HasRequest: true})
+	if _, err := fidlgen.CompatTestVectors(before, after); err == nil {
+		t.Errorf("expected an error for a changed ordinal")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Compilation represents a FIDL library together with the IR of the libraries
+// it depends on. Whereas a Root alone only knows the DeclInfo of its
+// dependencies' declarations, a Compilation can look up the full Declaration
+// of any declaration it transitively references, e.g. to inspect the members
+// of an external struct.
+type Compilation struct {
+	// Root is the IR of the target library.
+	Root Root
+
+	// Dependencies holds the IR of the dependency libraries, keyed by library
+	// name.
+	Dependencies map[EncodedLibraryIdentifier]*Root
+
+	decls map[EncodedCompoundIdentifier]Declaration
+}
+
+// NewCompilation creates a Compilation from the IR of a target library and
+// that of its dependencies. Dependencies that are not referenced by the target
+// are allowed (e.g., when a whole transitive closure is passed in), but each
+// library may only be provided once.
+func NewCompilation(root Root, deps []Root) (*Compilation, error) {
+	c := &Compilation{
+		Root:         root,
+		Dependencies: make(map[EncodedLibraryIdentifier]*Root, len(deps)),
+		decls:        make(map[EncodedCompoundIdentifier]Declaration),
+	}
+	for i := range deps {
+		dep := &deps[i]
+		if dep.Name == root.Name {
+			return nil, fmt.Errorf("library %s cannot be a dependency of itself", dep.Name)
+		}
+		if _, ok := c.Dependencies[dep.Name]; ok {
+			return nil, fmt.Errorf("dependency %s provided more than once", dep.Name)
+		}
+		c.Dependencies[dep.Name] = dep
+		c.addDecls(dep)
+	}
+	c.addDecls(&c.Root)
+	return c, nil
+}
+
+// addDecls records the declarations of a library. A declaration is recorded
+// from the library declaring it in preference to the copies other libraries
+// hold, e.g. in ExternalStructs, regardless of the order libraries are added
+// in. Copies are only recorded for declarations not seen yet.
+func (c *Compilation) addDecls(lib *Root) {
+	lib.ForEachDecl(func(decl Declaration) {
+		name := decl.GetName()
+		if name.LibraryName() == lib.Name {
+			c.decls[name] = decl
+		} else if _, ok := c.decls[name]; !ok {
+			c.decls[name] = decl
+		}
+	})
+}
+
+// ReadCompilation reads the JSON IR of a target library and of its
+// dependencies from the given files, and creates a Compilation out of them.
+func ReadCompilation(filename string, depFilenames []string) (*Compilation, error) {
+	root, err := ReadJSONIr(filename)
+	if err != nil {
+		return nil, err
+	}
+	var deps []Root
+	for _, depFilename := range depFilenames {
+		dep, err := ReadJSONIr(depFilename)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+	return NewCompilation(root, deps)
+}
+
+// LookupDecl returns the declaration with the given name, which may belong to
+// the target library or to any of the provided dependencies. If the name
+// refers to a member, the enclosing declaration is returned.
+func (c *Compilation) LookupDecl(name EncodedCompoundIdentifier) (Declaration, bool) {
+	decl, ok := c.decls[name.DeclName()]
+	return decl, ok
+}
+
+// LookupLibrary returns the IR of the library with the given name, which may
+// either be the target library or one of the provided dependencies.
+func (c *Compilation) LookupLibrary(name EncodedLibraryIdentifier) (*Root, bool) {
+	if name == c.Root.Name {
+		return &c.Root, true
+	}
+	root, ok := c.Dependencies[name]
+	return root, ok
+}
+
+// MissingDependencies returns the sorted names of the libraries the target
+// library depends on but for which no IR was provided. Declarations from these
+// libraries can still be looked up in DeclInfo, but not in LookupDecl.
+func (c *Compilation) MissingDependencies() []EncodedLibraryIdentifier {
+	var missing []EncodedLibraryIdentifier
+	for _, lib := range c.Root.Libraries {
+		if _, ok := c.Dependencies[lib.Name]; !ok {
+			missing = append(missing, lib.Name)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i] < missing[j]
+	})
+	return missing
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func structDecl(name fidlgen.EncodedCompoundIdentifier, members ...fidlgen.Identifier) fidlgen.Struct {
+	s := fidlgen.Struct{
+		ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+			LayoutDecl: fidlgen.LayoutDecl{
+				Decl:          fidlgen.Decl{Name: name},
+				NamingContext: []string{string(name.Parse().Name)},
+			},
+		},
+	}
+	for _, m := range members {
+		s.Members = append(s.Members, fidlgen.StructMember{Name: m})
+	}
+	return s
+}
+
+func TestCompilationLookupDecl(t *testing.T) {
+	dep := fidlgen.Root{
+		Name:    "dep",
+		Structs: []fidlgen.Struct{structDecl("dep/Point", "x", "y")},
+		Enums: []fidlgen.Enum{
+			{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "dep/Color"}}},
+		},
+	}
+	root := fidlgen.Root{
+		Name:            "example",
+		Structs:         []fidlgen.Struct{structDecl("example/Line", "start", "end")},
+		ExternalStructs: []fidlgen.Struct{structDecl("dep/Point")},
+		Libraries: []fidlgen.Library{
+			{Name: "dep"},
+			{Name: "other"},
+		},
+	}
+	c, err := fidlgen.NewCompilation(root, []fidlgen.Root{dep})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decl, ok := c.LookupDecl("dep/Point")
+	if !ok {
+		t.Fatal("dep/Point not found")
+	}
+	point, ok := decl.(*fidlgen.Struct)
+	if !ok {
+		t.Fatalf("dep/Point: got %T, want *fidlgen.Struct", decl)
+	}
+	// The full declaration from the dependency wins over the ExternalStructs
+	// copy.
+	if len(point.Members) != 2 {
+		t.Errorf("dep/Point: got %d members, want 2", len(point.Members))
+	}
+
+	if _, ok := c.LookupDecl("dep/Color.RED"); !ok {
+		t.Errorf("member lookup of dep/Color.RED did not resolve to dep/Color")
+	}
+	if _, ok := c.LookupDecl("example/Line"); !ok {
+		t.Errorf("example/Line not found")
+	}
+	if _, ok := c.LookupDecl("other/Missing"); ok {
+		t.Errorf("other/Missing unexpectedly found")
+	}
+
+	if lib, ok := c.LookupLibrary("dep"); !ok || lib.Name != "dep" {
+		t.Errorf("LookupLibrary(dep): got (%v, %t)", lib, ok)
+	}
+	if diff := cmp.Diff([]fidlgen.EncodedLibraryIdentifier{"other"}, c.MissingDependencies()); diff != "" {
+		t.Errorf("MissingDependencies: unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewCompilationRejectsDuplicateDependencies(t *testing.T) {
+	root := fidlgen.Root{Name: "example"}
+	if _, err := fidlgen.NewCompilation(root, []fidlgen.Root{{Name: "dep"}, {Name: "dep"}}); err == nil {
+		t.Errorf("expected an error for a duplicated dependency")
+	}
+	if _, err := fidlgen.NewCompilation(root, []fidlgen.Root{{Name: "example"}}); err == nil {
+		t.Errorf("expected an error for a self-dependency")
+	}
+}
+
+func TestCompilationPrefersDeclaringDependency(t *testing.T) {
+	// Library a holds a copy of b/S, e.g. in ExternalStructs, which must not
+	// replace the declaration of library b, whatever the order of the
+	// dependencies.
+	a := fidlgen.Root{
+		Name:            "a",
+		ExternalStructs: []fidlgen.Struct{structDecl("b/S")},
+	}
+	b := fidlgen.Root{
+		Name:    "b",
+		Structs: []fidlgen.Struct{structDecl("b/S", "x", "y")},
+	}
+	for _, deps := range [][]fidlgen.Root{{a, b}, {b, a}} {
+		c, err := fidlgen.NewCompilation(fidlgen.Root{Name: "example"}, deps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decl, ok := c.LookupDecl("b/S")
+		if !ok {
+			t.Fatalf("deps %s, %s: b/S not found", deps[0].Name, deps[1].Name)
+		}
+		if s := decl.(*fidlgen.Struct); len(s.Members) != 2 {
+			t.Errorf("deps %s, %s: got %d members for b/S, want those of library b", deps[0].Name, deps[1].Name, len(s.Members))
+		}
+	}
+}
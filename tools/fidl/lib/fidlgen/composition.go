@@ -0,0 +1,113 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// The IR only marks composed methods with IsComposed: the protocol declaring
+// them has to be found by walking the composed protocols. Backends need it to
+// document where a method is inherited from, to reuse the types generated for
+// the declaring protocol, and to apply denylists on that protocol.
+
+// MethodComposition describes where a composed method comes from.
+type MethodComposition struct {
+	// Origin is the protocol declaring the method.
+	Origin EncodedCompoundIdentifier
+	// Chain lists the protocols through which the method is composed, from
+	// one composed by the composing protocol directly down to Origin, which
+	// is last.
+	Chain []EncodedCompoundIdentifier
+}
+
+// MethodComposition describes where a method of the protocol comes from. It
+// returns false if the method is not composed. When a method is composed
+// through several paths, the first in declaration order is described. The
+// composed protocols are resolved through decls: for protocols composing
+// protocols of other libraries, this should be a Compilation or a Program.
+// An error is returned if a composed protocol cannot be resolved, or if none
+// declares the method.
+func (p *Protocol) MethodComposition(m *Method, decls DeclResolver) (MethodComposition, bool, error) {
+	if !m.IsComposed {
+		return MethodComposition{}, false, nil
+	}
+	visited := make(map[EncodedCompoundIdentifier]struct{})
+	var find func(protocol *Protocol) ([]EncodedCompoundIdentifier, error)
+	find = func(protocol *Protocol) ([]EncodedCompoundIdentifier, error) {
+		for _, composed := range protocol.Composed {
+			if _, ok := visited[composed.Name]; ok {
+				continue
+			}
+			visited[composed.Name] = struct{}{}
+			decl, ok := decls.LookupDecl(composed.Name)
+			if !ok {
+				return nil, fmt.Errorf("%s composes %s, which cannot be resolved", protocol.Name, composed.Name)
+			}
+			parent, ok := decl.(*Protocol)
+			if !ok {
+				return nil, fmt.Errorf("%s composes %s, which is not a protocol", protocol.Name, composed.Name)
+			}
+			for _, pm := range parent.Methods {
+				if pm.Name == m.Name && !pm.IsComposed {
+					return []EncodedCompoundIdentifier{parent.Name}, nil
+				}
+			}
+			chain, err := find(parent)
+			if err != nil {
+				return nil, err
+			}
+			if chain != nil {
+				return append([]EncodedCompoundIdentifier{parent.Name}, chain...), nil
+			}
+		}
+		return nil, nil
+	}
+	chain, err := find(p)
+	if err != nil {
+		return MethodComposition{}, false, err
+	}
+	if chain == nil {
+		return MethodComposition{}, false, fmt.Errorf("%s.%s is composed, but no composed protocol declares it", p.Name, m.Name)
+	}
+	return MethodComposition{Origin: chain[len(chain)-1], Chain: chain}, true, nil
+}
+
+// ComposedProtocol describes a compose clause of a protocol, e.g.
+//
+//	protocol Node {
+//	    @available(added=2)
+//	    compose fuchsia.unknown.Closeable;
+//	};
+type ComposedProtocol struct {
+	// Attributes are those of the compose clause, e.g. @available, rather
+	// than those of the composed protocol.
+	Attributes
+	// Name is the name of the composed protocol.
+	Name EncodedCompoundIdentifier
+	// Location is the location of the compose clause.
+	Location Location
+	// Protocol is the composed protocol, or nil if it could not be resolved.
+	Protocol *Protocol
+}
+
+// ComposedProtocols describes the compose clauses of the protocol, in
+// declaration order. The composed protocols are resolved through decls, as
+// for MethodComposition; those which cannot be resolved are left nil.
+func (p *Protocol) ComposedProtocols(decls DeclResolver) []ComposedProtocol {
+	var composed []ComposedProtocol
+	for _, c := range p.Composed {
+		cp := ComposedProtocol{
+			Attributes: c.Attributes,
+			Name:       c.Name,
+			Location:   c.Location,
+		}
+		if decl, ok := decls.LookupDecl(c.Name); ok {
+			cp.Protocol, _ = decl.(*Protocol)
+		}
+		composed = append(composed, cp)
+	}
+	return composed
+}
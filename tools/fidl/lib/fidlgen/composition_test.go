@@ -0,0 +1,192 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestMethodComposition(t *testing.T) {
+	withMethods := func(p fidlgen.Protocol, methods ...fidlgen.Method) fidlgen.Protocol {
+		p.Methods = methods
+		return p
+	}
+	declared := func(name fidlgen.Identifier) fidlgen.Method {
+		return fidlgen.Method{Name: name}
+	}
+	composed := func(name fidlgen.Identifier) fidlgen.Method {
+		return fidlgen.Method{Name: name, IsComposed: true}
+	}
+	unknown := fidlgen.Root{
+		Name: "fuchsia.unknown",
+		Protocols: []fidlgen.Protocol{
+			withMethods(protocolDecl("fuchsia.unknown/Closeable"), declared("Close")),
+			withMethods(composing("fuchsia.unknown/Cloneable", "fuchsia.unknown/Closeable"), declared("Clone"), composed("Close")),
+		},
+	}
+	io := fidlgen.Root{
+		Name: "fuchsia.io",
+		Protocols: []fidlgen.Protocol{
+			withMethods(composing("fuchsia.io/Node", "fuchsia.unknown/Cloneable", "fuchsia.unknown/Closeable"), declared("Sync"), composed("Clone"), composed("Close"), composed("Missing")),
+			withMethods(composing("fuchsia.io/Broken", "fuchsia.other/Gone"), composed("Close")),
+		},
+	}
+	program, err := fidlgen.NewProgram([]fidlgen.Root{unknown, io})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &io.Protocols[0]
+	for _, tc := range []struct {
+		method   int
+		ok       bool
+		expected fidlgen.MethodComposition
+	}{
+		{method: 0},
+		{
+			method:   1,
+			ok:       true,
+			expected: fidlgen.MethodComposition{Origin: "fuchsia.unknown/Cloneable", Chain: []fidlgen.EncodedCompoundIdentifier{"fuchsia.unknown/Cloneable"}},
+		},
+		{
+			// Close is composed both directly and through Cloneable; the first
+			// path in declaration order is described.
+			method: 2,
+			ok:     true,
+			expected: fidlgen.MethodComposition{
+				Origin: "fuchsia.unknown/Closeable",
+				Chain:  []fidlgen.EncodedCompoundIdentifier{"fuchsia.unknown/Cloneable", "fuchsia.unknown/Closeable"},
+			},
+		},
+	} {
+		m := &node.Methods[tc.method]
+		actual, ok, err := node.MethodComposition(m, program)
+		if err != nil {
+			t.Errorf("%s: %s", m.Name, err)
+			continue
+		}
+		if ok != tc.ok {
+			t.Errorf("%s: got ok = %t, want %t", m.Name, ok, tc.ok)
+		}
+		if diff := cmp.Diff(tc.expected, actual); diff != "" {
+			t.Errorf("%s: unexpected diff (-want +got):\n%s", m.Name, diff)
+		}
+	}
+
+	if _, _, err := node.MethodComposition(&node.Methods[3], program); err == nil {
+		t.Errorf("expected an error for a method no composed protocol declares")
+	}
+	broken := &io.Protocols[1]
+	if _, _, err := broken.MethodComposition(&broken.Methods[0], program); err == nil {
+		t.Errorf("expected an error for an unresolved composed protocol")
+	}
+}
+
+func TestComposedProtocols(t *testing.T) {
+	closeable := protocolDecl("example/Closeable")
+	node := composing("example/Node", "example/Closeable", "other/Missing")
+	node.Composed[0].Attributes = available("added", "2")
+	node.Composed[0].Location = fidlgen.Location{Filename: "node.fidl", Line: 3, Column: 5, Length: 26}
+	root := fidlgen.Root{
+		Name:      "example",
+		Protocols: []fidlgen.Protocol{closeable, node},
+		Decls: fidlgen.DeclMap{
+			"example/Closeable": fidlgen.ProtocolDeclType,
+			"example/Node":      fidlgen.ProtocolDeclType,
+		},
+	}
+
+	composed := root.Protocols[1].ComposedProtocols(&root)
+	if len(composed) != 2 {
+		t.Fatalf("got %d compose clauses, want 2", len(composed))
+	}
+	if composed[0].Name != "example/Closeable" || composed[0].Protocol == nil || composed[0].Protocol.Name != "example/Closeable" {
+		t.Errorf("got %+v, want a resolved example/Closeable", composed[0])
+	}
+	if composed[0].Location.Line != 3 {
+		t.Errorf("got location %+v", composed[0].Location)
+	}
+	if a, err := composed[0].Availability(); err != nil || a.Added != 2 {
+		t.Errorf("got availability %+v, %v", a, err)
+	}
+	if composed[1].Name != "other/Missing" || composed[1].Protocol != nil {
+		t.Errorf("got %+v, want an unresolved other/Missing", composed[1])
+	}
+
+	versions, err := root.Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]fidlgen.Version{2}, versions); diff != "" {
+		t.Errorf("versions: unexpected diff (-want +got):\n%s", diff)
+	}
+	v1, err := root.AtVersion(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v1.Protocols[1].Composed; len(got) != 1 || got[0].Name != "other/Missing" {
+		t.Errorf("at 1: got compose clauses %+v", got)
+	}
+	v2, err := root.AtVersion(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v2.Protocols[1].Composed; len(got) != 2 {
+		t.Errorf("at 2: got compose clauses %+v", got)
+	}
+}
+
+func TestAtVersionDropsMethodsOfRemovedComposeClauses(t *testing.T) {
+	closeable := protocolDecl("example/Closeable")
+	closeable.Methods = []fidlgen.Method{{Name: "Close"}}
+	node := composing("example/Node", "example/Closeable")
+	node.Composed[0].Attributes = available("added", "2")
+	node.Methods = []fidlgen.Method{{Name: "Sync"}, {Name: "Close", IsComposed: true}}
+	// Remote may compose anything, so the methods it composes are kept.
+	remote := composing("example/Remote", "example/Closeable", "other/Missing")
+	remote.Composed[0].Attributes = available("added", "2")
+	remote.Methods = []fidlgen.Method{{Name: "Close", IsComposed: true}}
+	root := fidlgen.Root{
+		Name:      "example",
+		Protocols: []fidlgen.Protocol{closeable, node, remote},
+		Decls: fidlgen.DeclMap{
+			"example/Closeable": fidlgen.ProtocolDeclType,
+			"example/Node":      fidlgen.ProtocolDeclType,
+			"example/Remote":    fidlgen.ProtocolDeclType,
+		},
+	}
+
+	for _, tc := range []struct {
+		version      fidlgen.Version
+		node, remote []fidlgen.Identifier
+	}{
+		{1, []fidlgen.Identifier{"Sync"}, []fidlgen.Identifier{"Close"}},
+		{2, []fidlgen.Identifier{"Sync", "Close"}, []fidlgen.Identifier{"Close"}},
+	} {
+		atVersion, err := root.AtVersion(tc.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, expected := range [][]fidlgen.Identifier{tc.node, tc.remote} {
+			p := &atVersion.Protocols[i+1]
+			var names []fidlgen.Identifier
+			for _, m := range p.Methods {
+				names = append(names, m.Name)
+			}
+			if diff := cmp.Diff(expected, names); diff != "" {
+				t.Errorf("at %s: %s: unexpected diff (-want +got):\n%s", tc.version, p.Name, diff)
+			}
+		}
+		node := &atVersion.Protocols[1]
+		for i := range node.Methods {
+			if _, _, err := node.MethodComposition(&node.Methods[i], &atVersion); err != nil {
+				t.Errorf("at %s: %s", tc.version, err)
+			}
+		}
+	}
+}
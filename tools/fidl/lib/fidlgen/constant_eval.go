@@ -0,0 +1,244 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The IR only gives the source text of a binary_operator constant, along with
+// its value as resolved by fidlc. The utilities below recover the operand tree
+// from that text and evaluate it, so that backends can either re-render the
+// expression in their own syntax or double-check its value for a given type.
+//
+// The only binary operator FIDL defines is `|`, which is the bitwise OR of
+// unsigned integers or of members of the same bits type.
+
+// BitwiseOr is the operator of a bitwise OR expression.
+const BitwiseOr = "|"
+
+// ConstantExpression is the operand tree of a constant expression.
+type ConstantExpression struct {
+	// Operator is the binary operator applied to Left and Right, or empty if
+	// the expression is a single operand.
+	Operator    string
+	Left, Right *ConstantExpression
+
+	// Operand is the source text of a literal or identifier operand, e.g.
+	// `0x10`, `MAX` or `Flags.READABLE`. It is only set if Operator is empty.
+	Operand string
+}
+
+// IsOperand returns whether the expression is a single literal or identifier.
+func (e ConstantExpression) IsOperand() bool {
+	return e.Operator == ""
+}
+
+// String renders the expression back in FIDL syntax.
+func (e ConstantExpression) String() string {
+	if e.IsOperand() {
+		return e.Operand
+	}
+	return fmt.Sprintf("%s %s %s", e.Left, e.Operator, e.Right)
+}
+
+// ParseConstantExpression parses the source text of a constant, as given by
+// Constant.Expression. Binary operators associate to the left.
+func ParseConstantExpression(expr string) (ConstantExpression, error) {
+	var tree *ConstantExpression
+	for _, text := range strings.Split(expr, BitwiseOr) {
+		text = strings.TrimSpace(text)
+		if text == "" || strings.ContainsAny(text, " \t\n()") {
+			return ConstantExpression{}, fmt.Errorf("malformed constant expression: %q", expr)
+		}
+		operand := &ConstantExpression{Operand: text}
+		if tree == nil {
+			tree = operand
+		} else {
+			tree = &ConstantExpression{Operator: BitwiseOr, Left: tree, Right: operand}
+		}
+	}
+	return *tree, nil
+}
+
+// ConstantValue is the result of evaluating an integral constant.
+type ConstantValue struct {
+	// Subtype is the primitive subtype of the value: either the type of the
+	// constant itself or, for bits, its underlying type.
+	Subtype PrimitiveSubtype
+	// Bits is the name of the bits type of the constant, if any.
+	Bits  EncodedCompoundIdentifier
+	Value uint64
+}
+
+// EvaluateConstant computes the value of a constant of a given type, which is
+// expected to be an unsigned integer or bits type: these are the only types
+// for which binary operators are defined. Identifiers within the expression
+// are looked up through decls, relative to the given library; library
+// aliases introduced by `using ... as` are not supported.
+func EvaluateConstant(decls DeclResolver, library EncodedLibraryIdentifier, c Constant, typ Type) (ConstantValue, error) {
+	e := constantEvaluator{decls: decls, library: library}
+	if err := e.setType(typ); err != nil {
+		return ConstantValue{}, err
+	}
+
+	var value uint64
+	var err error
+	switch c.Kind {
+	case LiteralConstant:
+		value, err = e.evaluateLiteral(c.Literal.Value)
+	case IdentifierConstant:
+		value, err = e.evaluateIdentifier(c.Identifier)
+	case BinaryOperator:
+		var expr ConstantExpression
+		if expr, err = ParseConstantExpression(c.Expression); err == nil {
+			value, err = e.evaluate(expr)
+		}
+	default:
+		err = fmt.Errorf("unknown constant kind: %s", c.Kind)
+	}
+	if err != nil {
+		return ConstantValue{}, err
+	}
+	return ConstantValue{Subtype: e.subtype, Bits: e.bits, Value: value}, nil
+}
+
+// EvaluateConstant computes the value of a constant appearing in this
+// library. Identifiers referring to dependency libraries can only be
+// evaluated through a Compilation.
+func (r *Root) EvaluateConstant(c Constant, typ Type) (ConstantValue, error) {
+	return EvaluateConstant(r, r.Name, c, typ)
+}
+
+// EvaluateConstant computes the value of a constant appearing in the target
+// library.
+func (c *Compilation) EvaluateConstant(constant Constant, typ Type) (ConstantValue, error) {
+	return EvaluateConstant(c, c.Root.Name, constant, typ)
+}
+
+type constantEvaluator struct {
+	decls   DeclResolver
+	library EncodedLibraryIdentifier
+	subtype PrimitiveSubtype
+	bits    EncodedCompoundIdentifier
+}
+
+func (e *constantEvaluator) setType(typ Type) error {
+	switch typ.Kind {
+	case PrimitiveType:
+		e.subtype = typ.PrimitiveSubtype
+	case IdentifierType:
+		decl, ok := e.decls.LookupDecl(typ.Identifier)
+		if !ok {
+			return fmt.Errorf("%s not found", typ.Identifier)
+		}
+		bits, ok := decl.(*Bits)
+		if !ok {
+			return fmt.Errorf("cannot evaluate constant of type %s", typ.Identifier)
+		}
+		e.subtype = bits.Type.PrimitiveSubtype
+		e.bits = bits.Name
+	default:
+		return fmt.Errorf("cannot evaluate constant of %s type", typ.Kind)
+	}
+	if !e.subtype.IsUnsigned() {
+		return fmt.Errorf("cannot evaluate constant of type %s", e.subtype)
+	}
+	return nil
+}
+
+func (e *constantEvaluator) evaluate(expr ConstantExpression) (uint64, error) {
+	if !expr.IsOperand() {
+		if expr.Operator != BitwiseOr {
+			return 0, fmt.Errorf("unsupported binary operator: %s", expr.Operator)
+		}
+		left, err := e.evaluate(*expr.Left)
+		if err != nil {
+			return 0, err
+		}
+		right, err := e.evaluate(*expr.Right)
+		if err != nil {
+			return 0, err
+		}
+		return left | right, nil
+	}
+
+	if c := expr.Operand[0]; '0' <= c && c <= '9' {
+		return e.evaluateLiteral(expr.Operand)
+	}
+	name, err := e.resolveName(expr.Operand)
+	if err != nil {
+		return 0, err
+	}
+	return e.evaluateIdentifier(name)
+}
+
+// evaluateLiteral parses a numeric literal, checking that it fits in the
+// type of the constant.
+func (e *constantEvaluator) evaluateLiteral(literal string) (uint64, error) {
+	return Constant{Value: literal}.AsUint64(e.subtype)
+}
+
+// evaluateIdentifier returns the value of a constant or of a bits or enum
+// member, as resolved by fidlc.
+func (e *constantEvaluator) evaluateIdentifier(name EncodedCompoundIdentifier) (uint64, error) {
+	decl, ok := e.decls.LookupDecl(name)
+	if !ok {
+		return 0, fmt.Errorf("%s not found", name)
+	}
+	member := name.Parse().Member
+
+	var value *Constant
+	switch decl := decl.(type) {
+	case *Const:
+		value = &decl.Value
+	case *Bits:
+		if e.bits != "" && decl.Name != e.bits {
+			return 0, fmt.Errorf("%s is not a member of %s", name, e.bits)
+		}
+		for i := range decl.Members {
+			if decl.Members[i].Name == member {
+				value = &decl.Members[i].Value
+			}
+		}
+	case *Enum:
+		for i := range decl.Members {
+			if decl.Members[i].Name == member {
+				value = &decl.Members[i].Value
+			}
+		}
+	default:
+		return 0, fmt.Errorf("%s does not name a constant value", name)
+	}
+	if value == nil {
+		return 0, fmt.Errorf("%s not found", name)
+	}
+	return e.evaluateLiteral(value.Value)
+}
+
+// resolveName maps an identifier as written in FIDL source (e.g., `MAX`,
+// `Flags.READABLE` or `fuchsia.io.MAX_NAME_LENGTH`) to the declaration or
+// member it refers to.
+func (e *constantEvaluator) resolveName(text string) (EncodedCompoundIdentifier, error) {
+	parts := strings.Split(text, ".")
+	for libraryParts := 0; libraryParts < len(parts); libraryParts++ {
+		library := e.library
+		if libraryParts > 0 {
+			library = EncodedLibraryIdentifier(strings.Join(parts[:libraryParts], "."))
+		}
+		// What follows the library is either a declaration or a member of
+		// one.
+		rest := parts[libraryParts:]
+		if len(rest) > 2 {
+			continue
+		}
+		declName := EncodedCompoundIdentifier(fmt.Sprintf("%s/%s", library, rest[0]))
+		if decl, ok := e.decls.LookupDecl(declName); ok && decl.GetName() == declName {
+			return EncodedCompoundIdentifier(fmt.Sprintf("%s/%s", library, strings.Join(rest, "."))), nil
+		}
+	}
+	return "", fmt.Errorf("unable to resolve %s", text)
+}
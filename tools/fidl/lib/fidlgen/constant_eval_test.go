@@ -0,0 +1,150 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestParseConstantExpression(t *testing.T) {
+	expr, err := fidlgen.ParseConstantExpression("Flags.A|0x10 | dep.MASK")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fidlgen.ConstantExpression{
+		Operator: fidlgen.BitwiseOr,
+		Left: &fidlgen.ConstantExpression{
+			Operator: fidlgen.BitwiseOr,
+			Left:     &fidlgen.ConstantExpression{Operand: "Flags.A"},
+			Right:    &fidlgen.ConstantExpression{Operand: "0x10"},
+		},
+		Right: &fidlgen.ConstantExpression{Operand: "dep.MASK"},
+	}
+	if diff := cmp.Diff(expected, expr); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	if s := expr.String(); s != "Flags.A | 0x10 | dep.MASK" {
+		t.Errorf("got %q", s)
+	}
+
+	for _, malformed := range []string{"", "A |", "| B", "A | (B)", "A B"} {
+		if _, err := fidlgen.ParseConstantExpression(malformed); err == nil {
+			t.Errorf("%q: expected an error", malformed)
+		}
+	}
+}
+
+func bitsMember(name fidlgen.Identifier, value string) fidlgen.BitsMember {
+	return fidlgen.BitsMember{Name: name, Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: value}}
+}
+
+func TestEvaluateConstant(t *testing.T) {
+	uint8Type := fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint8}
+	flags := fidlgen.Bits{
+		LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Flags"}},
+		Type:       uint8Type,
+		Members:    []fidlgen.BitsMember{bitsMember("A", "1"), bitsMember("B", "2")},
+	}
+	other := fidlgen.Bits{
+		LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Other"}},
+		Type:       uint8Type,
+		Members:    []fidlgen.BitsMember{bitsMember("C", "4")},
+	}
+	dep := fidlgen.Root{
+		Name: "dep.lib",
+		Consts: []fidlgen.Const{
+			{
+				Decl:  fidlgen.Decl{Name: "dep.lib/MASK"},
+				Type:  uint8Type,
+				Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: "128"},
+			},
+		},
+	}
+	compilation, err := fidlgen.NewCompilation(fidlgen.Root{
+		Name: "example",
+		Bits: []fidlgen.Bits{flags, other},
+	}, []fidlgen.Root{dep})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary := func(expr string) fidlgen.Constant {
+		return fidlgen.Constant{Kind: fidlgen.BinaryOperator, Expression: expr}
+	}
+	flagsType := fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/Flags"}
+
+	for _, tc := range []struct {
+		name     string
+		constant fidlgen.Constant
+		typ      fidlgen.Type
+		expected fidlgen.ConstantValue
+	}{
+		{
+			name:     "bits members",
+			constant: binary("Flags.A | Flags.B"),
+			typ:      flagsType,
+			expected: fidlgen.ConstantValue{Subtype: fidlgen.Uint8, Bits: "example/Flags", Value: 3},
+		},
+		{
+			name:     "literals and dependency constant",
+			constant: binary("0x10 | 0b100 | dep.lib.MASK"),
+			typ:      uint8Type,
+			expected: fidlgen.ConstantValue{Subtype: fidlgen.Uint8, Value: 0x94},
+		},
+		{
+			name:     "decimal literal with leading zero",
+			constant: binary("010 | 1"),
+			typ:      uint8Type,
+			expected: fidlgen.ConstantValue{Subtype: fidlgen.Uint8, Value: 11},
+		},
+		{
+			name:     "identifier",
+			constant: fidlgen.Constant{Kind: fidlgen.IdentifierConstant, Identifier: "example/Flags.B"},
+			typ:      flagsType,
+			expected: fidlgen.ConstantValue{Subtype: fidlgen.Uint8, Bits: "example/Flags", Value: 2},
+		},
+		{
+			name: "literal",
+			constant: fidlgen.Constant{
+				Kind:    fidlgen.LiteralConstant,
+				Literal: fidlgen.Literal{Kind: fidlgen.NumericLiteral, Value: "255"},
+			},
+			typ:      uint8Type,
+			expected: fidlgen.ConstantValue{Subtype: fidlgen.Uint8, Value: 255},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := compilation.EvaluateConstant(tc.constant, tc.typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name     string
+		constant fidlgen.Constant
+		typ      fidlgen.Type
+	}{
+		{"overflow", binary("Flags.A | 0x100"), flagsType},
+		{"underscores", binary("1_000 | 1"), fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}},
+		{"mismatched bits", binary("Flags.A | Other.C"), flagsType},
+		{"unresolved", binary("Flags.A | Missing.D"), flagsType},
+		{"signed", binary("1 | 2"), fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Int32}},
+		{"string", binary("1 | 2"), fidlgen.Type{Kind: fidlgen.StringType}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := compilation.EvaluateConstant(tc.constant, tc.typ); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
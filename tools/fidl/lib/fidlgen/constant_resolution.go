@@ -0,0 +1,122 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// A constant may be defined as another constant, e.g. `const MAX uint32 =
+// dep.MAX;`, which may itself be defined as an enum or bits member, and so on.
+// Backends either emit a reference to what the identifier names, which needs
+// the defining library to be generated and imported, or the folded value. The
+// utilities below follow the chain to its end, so that they can choose.
+
+// ResolvedConstant is the end of a chain of identifier constants.
+type ResolvedConstant struct {
+	// Chain lists the identifiers followed, in order, starting with the one
+	// of the resolved constant. It is empty if that constant is not an
+	// identifier.
+	Chain []EncodedCompoundIdentifier
+	// Decl is the declaration whose value ends the chain: a *Const, or the
+	// *Enum or *Bits declaring Member. It is nil if Chain is empty.
+	Decl Declaration
+	// Member is the name of the enum or bits member ending the chain, if any.
+	Member Identifier
+	// Type is the type of the value ending the chain: the type of the
+	// constant, or the enum or bits type of the member. It is the zero Type
+	// if Chain is empty.
+	Type Type
+	// Value is the value ending the chain: either a literal or a binary
+	// operator.
+	Value Constant
+}
+
+// IsReference returns whether the resolved constant is an identifier.
+func (r ResolvedConstant) IsReference() bool {
+	return len(r.Chain) > 0
+}
+
+// Folded returns the value ending the chain as a literal constant. Binary
+// operators are folded into the numeric literal of their value, as resolved
+// by fidlc.
+func (r ResolvedConstant) Folded() Constant {
+	if r.Value.Kind != BinaryOperator {
+		return r.Value
+	}
+	return Constant{
+		Kind:       LiteralConstant,
+		Literal:    Literal{Kind: NumericLiteral, Value: r.Value.Value},
+		Value:      r.Value.Value,
+		Expression: r.Value.Value,
+	}
+}
+
+// ResolveConstant follows a chain of identifier constants to the constant,
+// enum member or bits member defining its value. Identifiers are looked up
+// through decls: for chains going through other libraries, this should be a
+// Compilation or a Program. An error is returned if an identifier cannot be
+// resolved, or if the chain loops.
+func ResolveConstant(decls DeclResolver, c Constant) (ResolvedConstant, error) {
+	var r ResolvedConstant
+	seen := make(map[EncodedCompoundIdentifier]struct{})
+	for c.Kind == IdentifierConstant {
+		name := c.Identifier
+		if _, ok := seen[name]; ok {
+			return ResolvedConstant{}, fmt.Errorf("constant %s is defined in terms of itself", name)
+		}
+		seen[name] = struct{}{}
+		r.Chain = append(r.Chain, name)
+
+		decl, ok := decls.LookupDecl(name)
+		if !ok {
+			return ResolvedConstant{}, fmt.Errorf("%s not found", name)
+		}
+		member := name.Parse().Member
+		var value *Constant
+		switch decl := decl.(type) {
+		case *Const:
+			value = &decl.Value
+			r.Type = decl.Type
+		case *Enum:
+			for i := range decl.Members {
+				if decl.Members[i].Name == member {
+					value = &decl.Members[i].Value
+				}
+			}
+			r.Type = Type{Kind: IdentifierType, Identifier: decl.Name}
+		case *Bits:
+			for i := range decl.Members {
+				if decl.Members[i].Name == member {
+					value = &decl.Members[i].Value
+				}
+			}
+			r.Type = Type{Kind: IdentifierType, Identifier: decl.Name}
+		default:
+			return ResolvedConstant{}, fmt.Errorf("%s does not name a constant value", name)
+		}
+		if value == nil {
+			return ResolvedConstant{}, fmt.Errorf("%s not found", name)
+		}
+		r.Decl = decl
+		r.Member = member
+		c = *value
+	}
+	r.Value = c
+	return r, nil
+}
+
+// ResolveConstant resolves a constant appearing in this library. Identifiers
+// referring to dependency libraries can only be resolved through a
+// Compilation.
+func (r *Root) ResolveConstant(c Constant) (ResolvedConstant, error) {
+	return ResolveConstant(r, c)
+}
+
+// ResolveConstant resolves a constant appearing in the target library, or in
+// one of its dependencies.
+func (c *Compilation) ResolveConstant(constant Constant) (ResolvedConstant, error) {
+	return ResolveConstant(c, constant)
+}
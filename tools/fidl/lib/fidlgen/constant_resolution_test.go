@@ -0,0 +1,102 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func identifierConstant(name fidlgen.EncodedCompoundIdentifier) fidlgen.Constant {
+	return fidlgen.Constant{Kind: fidlgen.IdentifierConstant, Identifier: name}
+}
+
+func TestResolveConstant(t *testing.T) {
+	uint32Type := fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}
+	flagsType := fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "dep.lib/Flags"}
+	hex := fidlgen.Constant{
+		Kind:       fidlgen.LiteralConstant,
+		Literal:    fidlgen.Literal{Kind: fidlgen.NumericLiteral, Value: "0x10"},
+		Value:      "16",
+		Expression: "0x10",
+	}
+	dep := fidlgen.Root{
+		Name: "dep.lib",
+		Consts: []fidlgen.Const{
+			{Decl: fidlgen.Decl{Name: "dep.lib/MAX"}, Type: uint32Type, Value: hex},
+		},
+		Bits: []fidlgen.Bits{
+			{
+				LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "dep.lib/Flags"}},
+				Type:       uint32Type,
+				Members: []fidlgen.BitsMember{
+					{Name: "A", Value: identifierConstant("dep.lib/MAX")},
+				},
+			},
+		},
+	}
+	compilation, err := fidlgen.NewCompilation(fidlgen.Root{
+		Name: "example",
+		Consts: []fidlgen.Const{
+			{Decl: fidlgen.Decl{Name: "example/LIMIT"}, Type: flagsType, Value: identifierConstant("dep.lib/Flags.A")},
+			{Decl: fidlgen.Decl{Name: "example/LOOP"}, Type: uint32Type, Value: identifierConstant("example/LOOP")},
+		},
+	}, []fidlgen.Root{dep})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := compilation.ResolveConstant(identifierConstant("example/LIMIT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedChain := []fidlgen.EncodedCompoundIdentifier{"example/LIMIT", "dep.lib/Flags.A", "dep.lib/MAX"}
+	if diff := cmp.Diff(expectedChain, r.Chain); diff != "" {
+		t.Errorf("unexpected chain (-want +got):\n%s", diff)
+	}
+	if !r.IsReference() || r.Decl.GetName() != "dep.lib/MAX" || r.Member != "" {
+		t.Errorf("unexpected end of chain: %s, member %q", r.Decl.GetName(), r.Member)
+	}
+	if diff := cmp.Diff(uint32Type, r.Type); diff != "" {
+		t.Errorf("unexpected type (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(hex, r.Folded()); diff != "" {
+		t.Errorf("unexpected folded value (-want +got):\n%s", diff)
+	}
+
+	r, err = compilation.ResolveConstant(identifierConstant("dep.lib/Flags.A"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Decl.GetName() != "dep.lib/MAX" || len(r.Chain) != 2 {
+		t.Errorf("unexpected resolution of a bits member: %+v", r)
+	}
+
+	binary := fidlgen.Constant{Kind: fidlgen.BinaryOperator, Value: "3", Expression: "0x1 | 0x2"}
+	r, err = compilation.ResolveConstant(binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.IsReference() || r.Decl != nil {
+		t.Errorf("expected no reference, got %+v", r)
+	}
+	folded := r.Folded()
+	if folded.Kind != fidlgen.LiteralConstant || folded.Literal.Kind != fidlgen.NumericLiteral || folded.Literal.Value != "3" {
+		t.Errorf("unexpected folded value: %+v", folded)
+	}
+
+	for _, name := range []fidlgen.EncodedCompoundIdentifier{"example/LOOP", "example/MISSING", "dep.lib/Flags.B"} {
+		if r, err := compilation.ResolveConstant(identifierConstant(name)); err == nil {
+			t.Errorf("%s: expected an error, got %+v", name, r)
+		}
+	}
+
+	root := compilation.Root
+	if _, err := root.ResolveConstant(identifierConstant("example/LIMIT")); err == nil {
+		t.Errorf("expected an error resolving a dependency through a Root")
+	}
+}
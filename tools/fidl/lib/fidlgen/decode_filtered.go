@@ -0,0 +1,147 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// declListField is a field of Root holding a list of declarations.
+type declListField struct {
+	index int
+	key   string
+}
+
+// declListFields returns the fields of Root holding lists of declarations,
+// e.g. Structs, keyed by their JSON IR names.
+func declListFields() []declListField {
+	declarationType := reflect.TypeOf((*Declaration)(nil)).Elem()
+	rootType := reflect.TypeOf(Root{})
+	var fields []declListField
+	for i := 0; i < rootType.NumField(); i++ {
+		f := rootType.Field(i)
+		if f.Type.Kind() != reflect.Slice || !reflect.PtrTo(f.Type.Elem()).Implements(declarationType) {
+			continue
+		}
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		fields = append(fields, declListField{index: i, key: key})
+	}
+	return fields
+}
+
+// rawDecl is a declaration whose decoding is deferred until it is known to be
+// needed.
+type rawDecl struct {
+	field   declListField
+	raw     json.RawMessage
+	decoded reflect.Value
+}
+
+// DecodeJSONIrFiltered decodes the JSON IR of a library like DecodeJSONIr,
+// but only keeps the declarations with the given names along with the
+// declarations they transitively reference. The JSON of other declarations is
+// never fully decoded, which saves tools needing a single protocol of a large
+// library most of the cost of decoding it. An error is returned if a name is
+// not that of a declaration of the library.
+func DecodeJSONIrFiltered(r io.Reader, names []EncodedCompoundIdentifier) (Root, error) {
+	var obj map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&obj); err != nil {
+		return Root{}, fmt.Errorf("Error parsing JSON IR: %w", err)
+	}
+
+	// Index the declarations by name, only decoding their names.
+	fields := declListFields()
+	decls := make(map[EncodedCompoundIdentifier]*rawDecl)
+	var order []*rawDecl
+	for _, field := range fields {
+		raw, ok := obj[field.key]
+		if !ok {
+			continue
+		}
+		delete(obj, field.key)
+		var elems []json.RawMessage
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return Root{}, fmt.Errorf("Error parsing JSON IR: %s: %w", field.key, err)
+		}
+		for _, elem := range elems {
+			var named struct {
+				Name EncodedCompoundIdentifier `json:"name"`
+			}
+			if err := json.Unmarshal(elem, &named); err != nil {
+				return Root{}, fmt.Errorf("Error parsing JSON IR: %s: %w", field.key, err)
+			}
+			d := &rawDecl{field: field, raw: elem}
+			decls[named.Name] = d
+			order = append(order, d)
+		}
+	}
+
+	// Everything but the declarations is decoded as usual.
+	rest, err := json.Marshal(obj)
+	if err != nil {
+		return Root{}, err
+	}
+	var root Root
+	if err := json.Unmarshal(rest, &root); err != nil {
+		return Root{}, fmt.Errorf("Error parsing JSON IR: %w", err)
+	}
+
+	// Decode the requested declarations and those they reference.
+	pending := append([]EncodedCompoundIdentifier(nil), names...)
+	for _, name := range names {
+		if _, ok := decls[name]; !ok {
+			return Root{}, fmt.Errorf("declaration %s not found in library %s", name, root.Name)
+		}
+	}
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		d, ok := decls[name]
+		if !ok || d.decoded.IsValid() {
+			continue
+		}
+		elemType := reflect.TypeOf(root).Field(d.field.index).Type.Elem()
+		d.decoded = reflect.New(elemType)
+		if err := json.Unmarshal(d.raw, d.decoded.Interface()); err != nil {
+			return Root{}, fmt.Errorf("Error parsing JSON IR: %s: %w", name, err)
+		}
+		var refs declReferences
+		refs.addDecl(d.decoded.Interface().(Declaration))
+		pending = append(pending, refs...)
+	}
+
+	// Keep the decoded declarations, in their original order.
+	rootValue := reflect.ValueOf(&root).Elem()
+	kept := make(map[EncodedCompoundIdentifier]struct{})
+	for _, d := range order {
+		if !d.decoded.IsValid() {
+			continue
+		}
+		list := rootValue.Field(d.field.index)
+		list.Set(reflect.Append(list, d.decoded.Elem()))
+		kept[d.decoded.Interface().(Declaration).GetName()] = struct{}{}
+	}
+	var declOrder []EncodedCompoundIdentifier
+	for _, name := range root.DeclOrder {
+		if _, ok := kept[name]; ok {
+			declOrder = append(declOrder, name)
+		}
+	}
+	root.DeclOrder = declOrder
+	for name := range root.Decls {
+		if _, ok := kept[name]; !ok {
+			delete(root.Decls, name)
+		}
+	}
+
+	root.MarkBoxedTypes()
+	root.MarkEndpointTypes()
+	root.MarkHandleObjectTypes()
+	return root, nil
+}
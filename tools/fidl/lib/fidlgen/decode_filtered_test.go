@@ -0,0 +1,75 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestDecodeJSONIrFiltered(t *testing.T) {
+	b, err := fs.ReadFile(irtestdata.FS(), irtestdata.OpenProtocol+".fidl.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := fidlgen.ReadJSONIrContent(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		protocol = "test.openprotocol/P"
+		result   = "test.openprotocol/P_FlexibleTwoWay_Result"
+		response = "test.openprotocol/P_FlexibleTwoWay_Response"
+	)
+	for _, tc := range []struct {
+		names    []fidlgen.EncodedCompoundIdentifier
+		expected []fidlgen.EncodedCompoundIdentifier
+	}{
+		{
+			names:    []fidlgen.EncodedCompoundIdentifier{response},
+			expected: []fidlgen.EncodedCompoundIdentifier{response},
+		},
+		{
+			names:    []fidlgen.EncodedCompoundIdentifier{result},
+			expected: []fidlgen.EncodedCompoundIdentifier{response, result},
+		},
+		{
+			names:    []fidlgen.EncodedCompoundIdentifier{protocol},
+			expected: []fidlgen.EncodedCompoundIdentifier{response, result, protocol},
+		},
+	} {
+		root, err := fidlgen.DecodeJSONIrFiltered(bytes.NewReader(b), tc.names)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(tc.expected, root.DeclOrder); diff != "" {
+			t.Errorf("%v: DeclOrder: unexpected diff (-want +got):\n%s", tc.names, diff)
+		}
+		if root.Name != full.Name || len(root.Decls) != len(tc.expected) {
+			t.Errorf("%v: got name %s and decls %v", tc.names, root.Name, root.Decls)
+		}
+		for _, name := range tc.expected {
+			want, _ := full.LookupDecl(name)
+			got, ok := root.LookupDecl(name)
+			if !ok {
+				t.Errorf("%v: %s not decoded", tc.names, name)
+				continue
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("%v: %s: unexpected diff (-want +got):\n%s", tc.names, name, diff)
+			}
+		}
+	}
+
+	if _, err := fidlgen.DecodeJSONIrFiltered(bytes.NewReader(b), []fidlgen.EncodedCompoundIdentifier{"test.openprotocol/Missing"}); err == nil {
+		t.Errorf("expected an error for a missing declaration")
+	}
+}
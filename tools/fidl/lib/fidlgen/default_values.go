@@ -0,0 +1,270 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Struct and table members may have a default value, e.g. `flags Flags =
+// Flags.READ;` or `mask uint32 = 0xff00;`. Each backend supporting defaults
+// renders them as a literal or a reference in its own syntax; a
+// DefaultValueRenderer captures that syntax, so that RenderDefaultValue
+// breaks values down the same way for all of them. Implementations are
+// provided for C++, Go and Rust.
+
+// DefaultValueRenderer renders the parts of default values in the syntax of a
+// language.
+type DefaultValueRenderer interface {
+	// Bool renders a boolean literal.
+	Bool(v bool) string
+	// Numeric renders a numeric literal of the given subtype, preferably in
+	// the radix it was spelled in.
+	Numeric(n NumericSpelling, subtype PrimitiveSubtype) string
+	// String renders a string literal, given its value.
+	String(s string) string
+	// Const renders a reference to a constant.
+	Const(name EncodedCompoundIdentifier) string
+	// Member renders a reference to a member of an enum or bits declaration.
+	Member(decl EncodedCompoundIdentifier, member Identifier) string
+}
+
+// RenderDefaultValue renders a default value of the given type with r.
+// Identifiers are rendered as references, and binary operators as the numeric
+// literal of their value, as resolved by fidlc.
+func RenderDefaultValue(r DefaultValueRenderer, c Constant, typ Type) (string, error) {
+	switch c.Kind {
+	case IdentifierConstant:
+		ci := c.Identifier.Parse()
+		if ci.Member != "" {
+			return r.Member(c.Identifier.DeclName(), ci.Member), nil
+		}
+		return r.Const(c.Identifier), nil
+	case BinaryOperator:
+		n, err := ParseNumericSpelling(c.Value)
+		if err != nil {
+			return "", err
+		}
+		return r.Numeric(n, typ.PrimitiveSubtype), nil
+	case LiteralConstant:
+		switch c.Literal.Kind {
+		case BoolLiteral:
+			v, err := strconv.ParseBool(c.Literal.Value)
+			if err != nil {
+				return "", fmt.Errorf("invalid bool literal %q", c.Literal.Value)
+			}
+			return r.Bool(v), nil
+		case NumericLiteral:
+			n, ok := c.NumericSpelling()
+			if !ok {
+				return "", fmt.Errorf("invalid numeric literal %q", c.Literal.Value)
+			}
+			return r.Numeric(n, typ.PrimitiveSubtype), nil
+		case StringLiteral:
+			return r.String(c.Literal.Value), nil
+		default:
+			return "", fmt.Errorf("literal of kind %q has no default value", c.Literal.Kind)
+		}
+	default:
+		return "", fmt.Errorf("unknown constant kind %q", c.Kind)
+	}
+}
+
+// DefaultValue renders the default value of the member with r. It returns ""
+// if the member has no default value.
+func (m *StructMember) DefaultValue(r DefaultValueRenderer) (string, error) {
+	if m.MaybeDefaultValue == nil {
+		return "", nil
+	}
+	s, err := RenderDefaultValue(r, *m.MaybeDefaultValue, m.Type)
+	if err != nil {
+		return "", fmt.Errorf("default value of %s: %w", m.Name, err)
+	}
+	return s, nil
+}
+
+// DefaultValue renders the default value of the member with r. It returns ""
+// if the member has no default value.
+func (m *TableMember) DefaultValue(r DefaultValueRenderer) (string, error) {
+	if m.MaybeDefaultValue == nil {
+		return "", nil
+	}
+	s, err := RenderDefaultValue(r, *m.MaybeDefaultValue, m.Type)
+	if err != nil {
+		return "", fmt.Errorf("default value of %s: %w", m.Name, err)
+	}
+	return s, nil
+}
+
+// DefaultValueNames names the declarations default values refer to, the way
+// a backend does. Functions left nil name declarations as in FIDL, e.g.
+// `example.Flags.READ`.
+type DefaultValueNames struct {
+	ConstName  func(name EncodedCompoundIdentifier) string
+	MemberName func(decl EncodedCompoundIdentifier, member Identifier) string
+}
+
+// Const renders a reference to a constant.
+func (n DefaultValueNames) Const(name EncodedCompoundIdentifier) string {
+	if n.ConstName == nil {
+		return fidlSyntaxName(name)
+	}
+	return n.ConstName(name)
+}
+
+// Member renders a reference to a member of an enum or bits declaration.
+func (n DefaultValueNames) Member(decl EncodedCompoundIdentifier, member Identifier) string {
+	if n.MemberName == nil {
+		return fmt.Sprintf("%s.%s", fidlSyntaxName(decl), member)
+	}
+	return n.MemberName(decl, member)
+}
+
+// CppDefaultValues renders default values in C++.
+type CppDefaultValues struct {
+	DefaultValueNames
+}
+
+// GoDefaultValues renders default values in Go.
+type GoDefaultValues struct {
+	DefaultValueNames
+}
+
+// RustDefaultValues renders default values in Rust.
+type RustDefaultValues struct {
+	DefaultValueNames
+}
+
+var _ = []DefaultValueRenderer{CppDefaultValues{}, GoDefaultValues{}, RustDefaultValues{}}
+
+func (CppDefaultValues) Bool(v bool) string {
+	return strconv.FormatBool(v)
+}
+
+// floatLiteral renders a literal of a floating-point type in decimal, with a
+// fraction unless it has an exponent, e.g. `1.0` for `1` and `16.0` for
+// `0x10`. C++ and Rust would otherwise read the float suffix following integer
+// digits as part of a hexadecimal literal, or reject it.
+func floatLiteral(n NumericSpelling) string {
+	if n.Base == DecimalBase {
+		if n.IsInteger() {
+			return n.String() + ".0"
+		}
+		return n.String()
+	}
+	v, err := strconv.ParseUint(n.Digits, int(n.Base), 64)
+	if err != nil {
+		return n.String()
+	}
+	return NumericSpelling{Negative: n.Negative, Base: DecimalBase, Digits: strconv.FormatUint(v, 10) + ".0"}.String()
+}
+
+// Numeric renders unsigned literals with a `u` suffix and float32 literals
+// with an `f` suffix. Float literals are rendered in decimal, see
+// floatLiteral. The minimum int64 is rendered as an expression, since
+// C++ has no negative literals and its magnitude does not fit in an int64.
+func (CppDefaultValues) Numeric(n NumericSpelling, subtype PrimitiveSubtype) string {
+	switch {
+	case subtype == Int64 && n.Negative && n.IsInteger():
+		if v, err := strconv.ParseUint(n.Digits, int(n.Base), 64); err == nil && v == 1<<63 {
+			return "(-9223372036854775807ll-1)"
+		}
+	case subtype.IsUnsigned():
+		return n.String() + "u"
+	case subtype == Float32:
+		return floatLiteral(n) + "f"
+	case subtype == Float64:
+		return floatLiteral(n)
+	}
+	return n.String()
+}
+
+// String renders a C++ string literal. Other characters than printable ASCII
+// ones are escaped as octal bytes, since hexadecimal escapes would absorb
+// the hexadecimal digits following them.
+func (CppDefaultValues) String(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case PrintableASCIIRune(rune(c)):
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "\\%03o", c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func (GoDefaultValues) Bool(v bool) string {
+	return strconv.FormatBool(v)
+}
+
+// Numeric renders literals as spelled, since Go shares the FIDL prefixes and
+// default values are rendered in a typed context.
+func (GoDefaultValues) Numeric(n NumericSpelling, subtype PrimitiveSubtype) string {
+	return n.String()
+}
+
+// String renders a Go string literal.
+func (GoDefaultValues) String(s string) string {
+	return strconv.Quote(s)
+}
+
+func (RustDefaultValues) Bool(v bool) string {
+	return strconv.FormatBool(v)
+}
+
+var rustNumericSuffixes = map[PrimitiveSubtype]string{
+	Int8:    "i8",
+	Int16:   "i16",
+	Int32:   "i32",
+	Int64:   "i64",
+	Uint8:   "u8",
+	Uint16:  "u16",
+	Uint32:  "u32",
+	Uint64:  "u64",
+	Float32: "f32",
+	Float64: "f64",
+}
+
+// Numeric renders literals with the suffix of their type, e.g. `0xffu32`.
+// Float literals are rendered in decimal, see floatLiteral.
+func (RustDefaultValues) Numeric(n NumericSpelling, subtype PrimitiveSubtype) string {
+	if subtype.IsFloat() {
+		return floatLiteral(n) + rustNumericSuffixes[subtype]
+	}
+	return n.String() + rustNumericSuffixes[subtype]
+}
+
+// String renders a Rust string literal. Other characters than printable ASCII
+// ones are escaped as Unicode code points.
+func (RustDefaultValues) String(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case PrintableASCIIRune(r):
+			b.WriteRune(r)
+		default:
+			fmt.Fprintf(&b, `\u{%x}`, r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
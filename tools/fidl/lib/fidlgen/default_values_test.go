@@ -0,0 +1,102 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"fmt"
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestRenderDefaultValue(t *testing.T) {
+	literal := func(kind fidlgen.LiteralKind, value string) fidlgen.Constant {
+		return fidlgen.Constant{Kind: fidlgen.LiteralConstant, Literal: fidlgen.Literal{Kind: kind, Value: value}, Value: value}
+	}
+	primitive := func(subtype fidlgen.PrimitiveSubtype) fidlgen.Type {
+		return fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: subtype}
+	}
+	str := fidlgen.Type{Kind: fidlgen.StringType}
+	rustNames := fidlgen.DefaultValueNames{
+		MemberName: func(decl fidlgen.EncodedCompoundIdentifier, member fidlgen.Identifier) string {
+			return fmt.Sprintf("%s::%s", decl.Parse().Name, member)
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		value    fidlgen.Constant
+		typ      fidlgen.Type
+		cpp      string
+		golang   string
+		rust     string
+		rustWith fidlgen.DefaultValueNames
+	}{
+		{"bool", literal(fidlgen.BoolLiteral, "true"), primitive(fidlgen.Bool), "true", "true", "true", fidlgen.DefaultValueNames{}},
+		{"hex", literal(fidlgen.NumericLiteral, "0xff00"), primitive(fidlgen.Uint32), "0xff00u", "0xff00", "0xff00u32", fidlgen.DefaultValueNames{}},
+		{"negative", literal(fidlgen.NumericLiteral, "-5"), primitive(fidlgen.Int8), "-5", "-5", "-5i8", fidlgen.DefaultValueNames{}},
+		{"int64 min", literal(fidlgen.NumericLiteral, "-9223372036854775808"), primitive(fidlgen.Int64), "(-9223372036854775807ll-1)", "-9223372036854775808", "-9223372036854775808i64", fidlgen.DefaultValueNames{}},
+		{"float", literal(fidlgen.NumericLiteral, "1.5"), primitive(fidlgen.Float32), "1.5f", "1.5", "1.5f32", fidlgen.DefaultValueNames{}},
+		{"integral float", literal(fidlgen.NumericLiteral, "1"), primitive(fidlgen.Float32), "1.0f", "1", "1.0f32", fidlgen.DefaultValueNames{}},
+		{"hex float", literal(fidlgen.NumericLiteral, "0x10"), primitive(fidlgen.Float32), "16.0f", "0x10", "16.0f32", fidlgen.DefaultValueNames{}},
+		{"negative binary double", literal(fidlgen.NumericLiteral, "-0b11"), primitive(fidlgen.Float64), "-3.0", "-0b11", "-3.0f64", fidlgen.DefaultValueNames{}},
+		{"float exponent", literal(fidlgen.NumericLiteral, "1e5"), primitive(fidlgen.Float32), "1e5f", "1e5", "1e5f32", fidlgen.DefaultValueNames{}},
+		{"string", literal(fidlgen.StringLiteral, "a\"b\né"), str, `"a\"b\012\303\251"`, `"a\"b\né"`, `"a\"b\n\u{e9}"`, fidlgen.DefaultValueNames{}},
+		{
+			"binary operator",
+			fidlgen.Constant{Kind: fidlgen.BinaryOperator, Value: "3", Expression: "A | B"},
+			primitive(fidlgen.Uint8), "3u", "3", "3u8", fidlgen.DefaultValueNames{},
+		},
+		{
+			"member",
+			fidlgen.Constant{Kind: fidlgen.IdentifierConstant, Identifier: "example/Flags.READ"},
+			fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/Flags"},
+			"example.Flags.READ", "example.Flags.READ", "Flags::READ", rustNames,
+		},
+		{
+			"constant",
+			fidlgen.Constant{Kind: fidlgen.IdentifierConstant, Identifier: "example/MAX"},
+			primitive(fidlgen.Uint32), "example.MAX", "example.MAX", "example.MAX", rustNames,
+		},
+	} {
+		for _, r := range []struct {
+			renderer fidlgen.DefaultValueRenderer
+			expected string
+		}{
+			{fidlgen.CppDefaultValues{}, tc.cpp},
+			{fidlgen.GoDefaultValues{}, tc.golang},
+			{fidlgen.RustDefaultValues{DefaultValueNames: tc.rustWith}, tc.rust},
+		} {
+			actual, err := fidlgen.RenderDefaultValue(r.renderer, tc.value, tc.typ)
+			if err != nil {
+				t.Errorf("%s (%T): %s", tc.name, r.renderer, err)
+				continue
+			}
+			if actual != r.expected {
+				t.Errorf("%s (%T): got %s, want %s", tc.name, r.renderer, actual, r.expected)
+			}
+		}
+	}
+
+	if _, err := fidlgen.RenderDefaultValue(fidlgen.GoDefaultValues{}, literal(fidlgen.DefaultLiteral, ""), str); err == nil {
+		t.Errorf("expected an error for a default literal")
+	}
+}
+
+func TestMemberDefaultValue(t *testing.T) {
+	value := fidlgen.Constant{Kind: fidlgen.LiteralConstant, Literal: fidlgen.Literal{Kind: fidlgen.NumericLiteral, Value: "0b101"}, Value: "5"}
+	member := fidlgen.StructMember{
+		Name:              "mask",
+		Type:              fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint16},
+		MaybeDefaultValue: &value,
+	}
+	if actual, err := member.DefaultValue(fidlgen.RustDefaultValues{}); err != nil || actual != "0b101u16" {
+		t.Errorf("got %q (%v), want 0b101u16", actual, err)
+	}
+	member.MaybeDefaultValue = nil
+	if actual, err := member.DefaultValue(fidlgen.RustDefaultValues{}); err != nil || actual != "" {
+		t.Errorf("got %q (%v) without a default value", actual, err)
+	}
+}
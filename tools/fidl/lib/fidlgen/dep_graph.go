@@ -2,14 +2,12 @@
 // Use of this source code is governed by a BSD-style license that can be
 // found in the LICENSE file.
 
-package fidlgen_cpp
+package fidlgen
 
 import (
 	"fmt"
 	"sort"
 	"strings"
-
-	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
 )
 
 // DeclDepGraph represents the definitional dependency graph between
@@ -24,12 +22,12 @@ import (
 // methods.
 type DeclDepGraph struct {
 	nodes declDepNodeMap
-	decls map[fidlgen.EncodedCompoundIdentifier]fidlgen.Declaration
+	decls map[EncodedCompoundIdentifier]Declaration
 }
 
 type declDepNode struct {
 	// The name of the associated declaration.
-	name fidlgen.EncodedCompoundIdentifier
+	name EncodedCompoundIdentifier
 
 	// The reverse dependencies (i.e., dependents) of the associated
 	// declaration. This is tracked to facilitate a topological sort with
@@ -37,65 +35,147 @@ type declDepNode struct {
 	revDeps declDepNodeMap
 }
 
-type declDepNodeMap map[fidlgen.EncodedCompoundIdentifier]*declDepNode
+type declDepNodeMap map[EncodedCompoundIdentifier]*declDepNode
 
 // NewDeclDepGraph computes the dependency graph for a given library.
-func NewDeclDepGraph(r fidlgen.Root) DeclDepGraph {
+func NewDeclDepGraph(r Root) DeclDepGraph {
 	g := DeclDepGraph{
 		nodes: make(declDepNodeMap),
-		decls: make(map[fidlgen.EncodedCompoundIdentifier]fidlgen.Declaration),
+		decls: make(map[EncodedCompoundIdentifier]Declaration),
 	}
-	r.ForEachDecl(func(decl fidlgen.Declaration) { g.addDecl(decl) })
+	r.ForEachDecl(func(decl Declaration) { g.addDecl(decl) })
 	return g
 }
 
-// SortedDecls returns a deterministic, topologically sorted list of the
+// CycleError is returned when declarations depend on each other cyclically.
+type CycleError struct {
+	// Path gives the declarations forming the cycle, each depending on the
+	// next, and starting and ending with the same declaration.
+	Path []EncodedCompoundIdentifier
+}
+
+func (e *CycleError) Error() string {
+	var names []string
+	for _, name := range e.Path {
+		names = append(names, string(name))
+	}
+	return fmt.Sprintf("cyclic dependency: %s", strings.Join(names, " -> "))
+}
+
+// TopologicalSort returns a deterministic, topologically sorted list of the
 // associated declarations, so that a declaration always appears before those
 // that depend on it. Beyond preserving this relation, the list attempts to
-// prioritize declarations in terms of their original ordering in source.
-func (g *DeclDepGraph) SortedDecls() []fidlgen.Declaration {
+// prioritize declarations in terms of their original ordering in source. If
+// the declarations depend on each other cyclically, a *CycleError is returned.
+func (g *DeclDepGraph) TopologicalSort() ([]Declaration, error) {
 	const (
 		nodeProccesing int = iota
 		nodeProcessed
 	)
 
-	var decls []fidlgen.Declaration
-	nodeState := make(map[fidlgen.EncodedCompoundIdentifier]int)
-	var visit func(*declDepNode, []string)
-	visit = func(node *declDepNode, depChain []string) {
-		depChain = append(depChain, string(node.name))
+	var decls []Declaration
+	nodeState := make(map[EncodedCompoundIdentifier]int)
+	// depChain records the path of dependents from the node at which the
+	// current traversal started.
+	var depChain []EncodedCompoundIdentifier
+	var visit func(*declDepNode) error
+	visit = func(node *declDepNode) error {
 		if state, ok := nodeState[node.name]; ok {
 			switch state {
 			case nodeProccesing:
-				panic(fmt.Sprintf("cyclic dependency: %s", strings.Join(depChain, " <- ")))
+				return newCycleError(depChain, node.name)
 			case nodeProcessed:
-				return
+				return nil
 			}
 		}
 
 		nodeState[node.name] = nodeProccesing
+		depChain = append(depChain, node.name)
 		for _, dep := range g.normalizeNodes(node.revDeps) {
-			visit(dep, depChain)
+			if err := visit(dep); err != nil {
+				return err
+			}
 		}
 		if decl, ok := g.decls[node.name]; ok {
-			decls = append([]fidlgen.Declaration{decl}, decls...)
+			decls = append([]Declaration{decl}, decls...)
 		}
 		nodeState[node.name] = nodeProcessed
 		depChain = depChain[:len(depChain)-1]
+		return nil
 	}
 
 	for _, node := range g.normalizeNodes(g.nodes) {
-		visit(node, nil)
+		if err := visit(node); err != nil {
+			return nil, err
+		}
+	}
+	return decls, nil
+}
+
+// newCycleError returns the error for a chain of dependents that loops back
+// to the given name.
+func newCycleError(depChain []EncodedCompoundIdentifier, name EncodedCompoundIdentifier) *CycleError {
+	start := 0
+	for i, n := range depChain {
+		if n == name {
+			start = i
+			break
+		}
+	}
+	// The chain lists each declaration before its dependent; the path lists it
+	// after.
+	path := []EncodedCompoundIdentifier{name}
+	for i := len(depChain) - 1; i >= start; i-- {
+		path = append(path, depChain[i])
+	}
+	return &CycleError{Path: path}
+}
+
+// SortedDecls is as TopologicalSort, but panics if the declarations depend on
+// each other cyclically.
+func (g *DeclDepGraph) SortedDecls() []Declaration {
+	decls, err := g.TopologicalSort()
+	if err != nil {
+		panic(err.Error())
 	}
 	return decls
 }
 
+// FindCycle returns the path of a dependency cycle among the declarations,
+// each depending on the next, or nil if there are none.
+func (g *DeclDepGraph) FindCycle() []EncodedCompoundIdentifier {
+	if _, err := g.TopologicalSort(); err != nil {
+		return err.(*CycleError).Path
+	}
+	return nil
+}
+
+// GetDirectDependencies returns the names of the declarations that a given
+// one, referenced by name, directly depends on, sorted. These may include
+// declarations from other libraries. A boolean is also returned indicating
+// whether the provided declaration is contained in the graph.
+func (g DeclDepGraph) GetDirectDependencies(name EncodedCompoundIdentifier) ([]EncodedCompoundIdentifier, bool) {
+	if _, ok := g.decls[name]; !ok {
+		return nil, false
+	}
+	var deps []EncodedCompoundIdentifier
+	for _, node := range g.nodes {
+		if _, ok := node.revDeps[name]; ok {
+			deps = append(deps, node.name)
+		}
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		return deps[i] < deps[j]
+	})
+	return deps, true
+}
+
 // GetDirectDependents returns the declarations that are directly dependent on
 // a given one, referenced by name. The returned declarations are given in
 // source order (lexicographically first on filename). A boolean is also
 // returned indicating whether the provided declaration is contained in the
 // graph.
-func (g DeclDepGraph) GetDirectDependents(name fidlgen.EncodedCompoundIdentifier) ([]fidlgen.Declaration, bool) {
+func (g DeclDepGraph) GetDirectDependents(name EncodedCompoundIdentifier) ([]Declaration, bool) {
 	// First check whether the provided name represents a local declaration.
 	if _, ok := g.decls[name]; !ok {
 		return nil, false
@@ -104,13 +184,13 @@ func (g DeclDepGraph) GetDirectDependents(name fidlgen.EncodedCompoundIdentifier
 	if !ok {
 		return nil, false
 	}
-	var decls []fidlgen.Declaration
+	var decls []Declaration
 	for _, revDep := range node.revDeps {
 		// All direct dependents on a local declaration should be local declarations themselves.
 		decls = append(decls, g.decls[revDep.name])
 	}
 	sort.Slice(decls, func(i, j int) bool {
-		return fidlgen.LocationCmp(decls[i].GetLocation(), decls[j].GetLocation())
+		return LocationCmp(decls[i].GetLocation(), decls[j].GetLocation())
 	})
 	return decls, true
 }
@@ -128,8 +208,9 @@ func (g DeclDepGraph) GetDirectDependents(name fidlgen.EncodedCompoundIdentifier
 // ordering is preserved (falling back to a lexicographic comparison on
 // filenames if the two declarations came from different files).
 //
-// * If both nodes represent imported declarations, the one with the
-// lexicographically smaller name is prioritized. (This is an arbitrary choice.)
+// * If both nodes represent imported declarations (or local declarations at the
+// same location), the one with the lexicographically smaller name is
+// prioritized. (This is an arbitrary choice.)
 //
 // * If only one node of the two represents a local declaration, the local one
 // is prioritized. (This is also an arbitrary choice.)
@@ -144,9 +225,13 @@ func (g DeclDepGraph) normalizeNodes(m declDepNodeMap) []*declDepNode {
 		iDecl, iLocal := g.decls[iName]
 		jDecl, jLocal := g.decls[jName]
 		if iLocal && jLocal {
-			return !fidlgen.LocationCmp(iDecl.GetLocation(), jDecl.GetLocation())
-		}
-		if iLocal != jLocal {
+			iLoc, jLoc := iDecl.GetLocation(), jDecl.GetLocation()
+			// Declarations may share a location in hand-written IR; fall back
+			// to comparing names below to stay deterministic.
+			if LocationCmp(iLoc, jLoc) || LocationCmp(jLoc, iLoc) {
+				return !LocationCmp(iLoc, jLoc)
+			}
+		} else if iLocal != jLocal {
 			return iLocal
 		}
 		return strings.Compare(string(iName), string(jName)) > 0
@@ -154,28 +239,28 @@ func (g DeclDepGraph) normalizeNodes(m declDepNodeMap) []*declDepNode {
 	return nodes
 }
 
-func (g *DeclDepGraph) addDecl(decl fidlgen.Declaration) {
+func (g *DeclDepGraph) addDecl(decl Declaration) {
 	node := g.getNode(decl.GetName())
 	g.decls[node.name] = decl
 
 	switch decl := decl.(type) {
-	case *fidlgen.Const:
+	case *Const:
 		g.addDepsFromType(node, decl.Type)
 		g.addDepsFromConstant(node, decl.Value)
-	case *fidlgen.Bits:
+	case *Bits:
 		g.addDepsFromType(node, decl.Type)
 		for _, m := range decl.Members {
 			g.addDepsFromConstant(node, m.Value)
 		}
-	case *fidlgen.Enum:
+	case *Enum:
 		for _, m := range decl.Members {
 			g.addDepsFromConstant(node, m.Value)
 		}
-	case *fidlgen.Resource:
+	case *Resource:
 		for _, prop := range decl.Properties {
 			g.addDepsFromType(node, prop.Type)
 		}
-	case *fidlgen.Protocol:
+	case *Protocol:
 		for _, comp := range decl.Composed {
 			g.addDep(node, comp.Name)
 		}
@@ -187,11 +272,11 @@ func (g *DeclDepGraph) addDecl(decl fidlgen.Declaration) {
 				g.addDep(node, resp)
 			}
 		}
-	case *fidlgen.Service:
+	case *Service:
 		for _, m := range decl.Members {
 			g.addDepsFromType(node, m.Type)
 		}
-	case *fidlgen.Struct:
+	case *Struct:
 		for _, m := range decl.Members {
 			g.addDepsFromType(node, m.Type)
 			if m.MaybeDefaultValue != nil {
@@ -201,7 +286,7 @@ func (g *DeclDepGraph) addDecl(decl fidlgen.Declaration) {
 				g.addDepsFromTypeCtor(node, *m.MaybeTypeAlias)
 			}
 		}
-	case *fidlgen.Table:
+	case *Table:
 		for _, m := range decl.Members {
 			g.addDepsFromType(node, m.Type)
 			if m.MaybeDefaultValue != nil {
@@ -211,16 +296,16 @@ func (g *DeclDepGraph) addDecl(decl fidlgen.Declaration) {
 				g.addDepsFromTypeCtor(node, *m.MaybeTypeAlias)
 			}
 		}
-	case *fidlgen.Union:
+	case *Union:
 		for _, m := range decl.Members {
 			g.addDepsFromType(node, m.Type)
 			if m.MaybeTypeAlias != nil {
 				g.addDepsFromTypeCtor(node, *m.MaybeTypeAlias)
 			}
 		}
-	case *fidlgen.TypeAlias:
+	case *TypeAlias:
 		g.addDepsFromTypeCtor(node, decl.PartialTypeConstructor)
-	case *fidlgen.NewType:
+	case *NewType:
 		if decl.Alias != nil {
 			g.addDepsFromTypeCtor(node, *decl.Alias)
 		} else {
@@ -229,7 +314,7 @@ func (g *DeclDepGraph) addDecl(decl fidlgen.Declaration) {
 	}
 }
 
-func (g *DeclDepGraph) getNode(decl fidlgen.EncodedCompoundIdentifier) *declDepNode {
+func (g *DeclDepGraph) getNode(decl EncodedCompoundIdentifier) *declDepNode {
 	node, ok := g.nodes[decl]
 	if !ok {
 		node = &declDepNode{
@@ -241,17 +326,17 @@ func (g *DeclDepGraph) getNode(decl fidlgen.EncodedCompoundIdentifier) *declDepN
 	return node
 }
 
-func (g *DeclDepGraph) addDep(node *declDepNode, dep fidlgen.EncodedCompoundIdentifier) {
+func (g *DeclDepGraph) addDep(node *declDepNode, dep EncodedCompoundIdentifier) {
 	g.getNode(dep).revDeps[node.name] = node
 }
 
-func (g *DeclDepGraph) addDepsFromConstant(node *declDepNode, c fidlgen.Constant) {
-	if c.Kind == fidlgen.IdentifierConstant {
+func (g *DeclDepGraph) addDepsFromConstant(node *declDepNode, c Constant) {
+	if c.Kind == IdentifierConstant {
 		g.addDep(node, c.Identifier)
 	}
 }
 
-func (g *DeclDepGraph) addDepsFromType(node *declDepNode, typ fidlgen.Type) {
+func (g *DeclDepGraph) addDepsFromType(node *declDepNode, typ Type) {
 	// As above, we do not create edges to nullable types or protocols via
 	// endpoint dependencies.
 	if typ.Nullable || typ.ProtocolTransport != "" {
@@ -259,18 +344,18 @@ func (g *DeclDepGraph) addDepsFromType(node *declDepNode, typ fidlgen.Type) {
 	}
 
 	switch typ.Kind {
-	case fidlgen.ArrayType, fidlgen.VectorType:
+	case ArrayType, VectorType:
 		g.addDepsFromType(node, *typ.ElementType)
-	case fidlgen.HandleType:
+	case HandleType:
 		// TODO(fxbug.dev/7660): ResourceIdentifier should be an
-		// `fidlgen.EncodedCompoundIdentifier`.
-		g.addDep(node, fidlgen.EncodedCompoundIdentifier(typ.ResourceIdentifier))
-	case fidlgen.IdentifierType:
+		// `EncodedCompoundIdentifier`.
+		g.addDep(node, EncodedCompoundIdentifier(typ.ResourceIdentifier))
+	case IdentifierType:
 		g.addDep(node, typ.Identifier)
 	}
 }
 
-func (g *DeclDepGraph) addDepsFromTypeCtor(node *declDepNode, ctor fidlgen.PartialTypeConstructor) {
+func (g *DeclDepGraph) addDepsFromTypeCtor(node *declDepNode, ctor PartialTypeConstructor) {
 	// As above, we do not create edges to nullable types.
 	if ctor.Nullable {
 		return
@@ -2,11 +2,13 @@
 // Use of this source code is governed by a BSD-style license that can be
 // found in the LICENSE file.
 
-package fidlgen_cpp
+package fidlgen_test
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
 )
@@ -55,7 +57,7 @@ func TestIndependentDeclsAreSourceOrdered(t *testing.T) {
 		alias F = bool;
 	`,
 	})
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/A",
 		"example/B",
@@ -99,7 +101,7 @@ func TestInterdependentDeclsAreTopologicallySorted(t *testing.T) {
 		alias G = uint64;
 	`,
 	})
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/B",
 		"example/A",
@@ -140,7 +142,7 @@ func TestDirectDependency(t *testing.T) {
 		};
 	`,
 	})
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 
 	directDependents := func(t *testing.T, name string) []fidlgen.Declaration {
 		dependents, ok := g.GetDirectDependents(fidlgen.EncodedCompoundIdentifier(name))
@@ -172,7 +174,7 @@ func TestConstDeps(t *testing.T) {
 
 	`)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/C",
 		"example/B",
@@ -191,7 +193,7 @@ func TestBitsDeps(t *testing.T) {
 		const B uint8 = 1;
 	`)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/B",
 		"example/A",
@@ -209,7 +211,7 @@ func TestEnumDeps(t *testing.T) {
 		const B uint8 = 0;
 	`)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/B",
 		"example/A",
@@ -235,7 +237,7 @@ func TestResourceDeps(t *testing.T) {
 		};
 	`)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/B",
 		"example/C",
@@ -268,7 +270,7 @@ func TestProtocolDeps(t *testing.T) {
 		protocol E {};
 	`)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/C",
 		"example/D",
@@ -292,7 +294,7 @@ func TestServiceDeps(t *testing.T) {
 		protocol C {};
 	`)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/A",
 		"example/B", // No edges drawn to protocols specified via endpoints.
@@ -321,7 +323,7 @@ func TestStructDeps(t *testing.T) {
 	};
 `)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/B",
 		"example/D",
@@ -351,7 +353,7 @@ func TestTableDeps(t *testing.T) {
 	};
 `)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/B",
 		"example/D",
@@ -381,7 +383,7 @@ func TestUnionDeps(t *testing.T) {
 	};
 `)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/B",
 		"example/D",
@@ -402,7 +404,7 @@ func TestTypeAliasDeps(t *testing.T) {
 	};
 `)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/B",
 		"example/A",
@@ -426,7 +428,7 @@ func TestNewTypeDeps(t *testing.T) {
 	};
 `)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/B",
 		"example/A",
@@ -454,7 +456,7 @@ func TestNoEdgesToNullableTypes(t *testing.T) {
 	protocol D {};
 `)
 
-	g := NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	expectNames(t, g.SortedDecls(), []string{
 		"example/A",
 		"example/B",
@@ -462,3 +464,68 @@ func TestNoEdgesToNullableTypes(t *testing.T) {
 		"example/D",
 	})
 }
+
+// fidlc rejects cyclic declarations, so the following IR is hand-written.
+func cyclicLibrary() fidlgen.Root {
+	a := structDecl("example/A", "b")
+	a.Members[0].Type = *identifierType("example/B")
+	b := structDecl("example/B", "c")
+	b.Members[0].Type = *identifierType("example/C")
+	c := structDecl("example/C", "a")
+	c.Members[0].Type = *identifierType("example/A")
+	return fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{a, b, c, structDecl("example/D")},
+	}
+}
+
+func TestTopologicalSortReportsCycles(t *testing.T) {
+	g := fidlgen.NewDeclDepGraph(cyclicLibrary())
+	_, err := g.TopologicalSort()
+	var cycleErr *fidlgen.CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expected a *CycleError; got %v", err)
+	}
+
+	// The cycle may be reported from any of its declarations, but each must
+	// depend on the next.
+	path := cycleErr.Path
+	if len(path) != 4 || path[0] != path[3] {
+		t.Fatalf("malformed cycle path: %v", path)
+	}
+	for i := 0; i < 3; i++ {
+		deps, ok := g.GetDirectDependencies(path[i])
+		if !ok || len(deps) != 1 || deps[0] != path[i+1] {
+			t.Errorf("%s does not directly depend on %s (dependencies: %v)", path[i], path[i+1], deps)
+		}
+	}
+	if diff := cmp.Diff(path, g.FindCycle()); diff != "" {
+		t.Errorf("FindCycle: unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetDirectDependencies(t *testing.T) {
+	ir := cyclicLibrary()
+	ir.Structs[3].Members = []fidlgen.StructMember{
+		{Name: "c", Type: *identifierType("example/C")},
+		{Name: "a", Type: fidlgen.Type{Kind: fidlgen.ArrayType, ElementType: identifierType("example/A")}},
+		{Name: "b", Type: fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/B", Nullable: true}},
+	}
+	g := fidlgen.NewDeclDepGraph(ir)
+
+	deps, ok := g.GetDirectDependencies("example/D")
+	if !ok {
+		t.Fatal("example/D not found")
+	}
+	// Nullable types do not give edges.
+	if diff := cmp.Diff([]fidlgen.EncodedCompoundIdentifier{"example/A", "example/C"}, deps); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	if _, ok := g.GetDirectDependencies("example/Missing"); ok {
+		t.Errorf("example/Missing unexpectedly found")
+	}
+	acyclic := fidlgen.NewDeclDepGraph(fidlgen.Root{Structs: ir.Structs[3:]})
+	if cycle := acyclic.FindCycle(); cycle != nil {
+		t.Errorf("unexpected cycle: %v", cycle)
+	}
+}
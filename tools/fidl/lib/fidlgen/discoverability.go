@@ -0,0 +1,112 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"sort"
+)
+
+// Components route protocols by name: a @discoverable protocol is served and
+// connected to under its discovery name. Platform routing audits need to know
+// which protocols are discoverable, whether any two of them claim the same
+// name, and whether components serve protocols which were never marked
+// @discoverable, in which case the name they are routed under is not tied to
+// the protocol by the IR.
+
+// DiscoverableProtocol describes a @discoverable protocol.
+type DiscoverableProtocol struct {
+	// Protocol is the name of the protocol declaration.
+	Protocol EncodedCompoundIdentifier
+	// DiscoveryName is the unquoted name under which the protocol is
+	// discovered, e.g. "fuchsia.io.Directory".
+	DiscoveryName string
+}
+
+// DiscoveryNameCollision describes a discovery name claimed by more than one
+// protocol.
+type DiscoveryNameCollision struct {
+	// DiscoveryName is the contested discovery name.
+	DiscoveryName string
+	// Protocols lists the protocols claiming the name, sorted.
+	Protocols []EncodedCompoundIdentifier
+}
+
+// DiscoverabilityAudit reports on the discoverable protocols of a Program,
+// against the protocols served by a set of components.
+type DiscoverabilityAudit struct {
+	// Discoverable lists the @discoverable protocols of all libraries, sorted
+	// by protocol name.
+	Discoverable []DiscoverableProtocol
+	// Collisions lists the discovery names claimed by more than one protocol,
+	// possibly from different libraries, sorted by discovery name.
+	Collisions []DiscoveryNameCollision
+	// Undiscoverable lists the served protocols which are not @discoverable,
+	// matched by their qualified name, sorted.
+	Undiscoverable []EncodedCompoundIdentifier
+	// Unknown lists the served protocol names which match no protocol of the
+	// program, sorted and deduplicated.
+	Unknown []string
+}
+
+// AuditDiscoverability reports on the discoverable protocols of the program.
+// The served argument gives the names of the protocol capabilities declared by
+// a list of component manifests, e.g. "fuchsia.logger.LogSink"; each is
+// matched against discovery names first, then against the qualified names of
+// protocols which are not @discoverable. It may be nil to only report on the
+// declarations.
+func (p *Program) AuditDiscoverability(served []string) DiscoverabilityAudit {
+	var audit DiscoverabilityAudit
+	byDiscoveryName := make(map[string][]EncodedCompoundIdentifier)
+	byQualifiedName := make(map[string]EncodedCompoundIdentifier)
+	p.ForEachLibrary(func(root *Root) {
+		for i := range root.Protocols {
+			protocol := &root.Protocols[i]
+			name, ok := protocol.DiscoveryName()
+			if !ok {
+				byQualifiedName[protocol.QualifiedName()] = protocol.Name
+				continue
+			}
+			audit.Discoverable = append(audit.Discoverable, DiscoverableProtocol{
+				Protocol:      protocol.Name,
+				DiscoveryName: name,
+			})
+			byDiscoveryName[name] = append(byDiscoveryName[name], protocol.Name)
+		}
+	})
+	sort.Slice(audit.Discoverable, func(i, j int) bool {
+		return audit.Discoverable[i].Protocol < audit.Discoverable[j].Protocol
+	})
+
+	for name, protocols := range byDiscoveryName {
+		if len(protocols) > 1 {
+			audit.Collisions = append(audit.Collisions, DiscoveryNameCollision{
+				DiscoveryName: name,
+				Protocols:     sortNames(protocols),
+			})
+		}
+	}
+	sort.Slice(audit.Collisions, func(i, j int) bool {
+		return audit.Collisions[i].DiscoveryName < audit.Collisions[j].DiscoveryName
+	})
+
+	seen := make(map[string]struct{})
+	for _, name := range served {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		if _, ok := byDiscoveryName[name]; ok {
+			continue
+		}
+		if protocol, ok := byQualifiedName[name]; ok {
+			audit.Undiscoverable = append(audit.Undiscoverable, protocol)
+		} else {
+			audit.Unknown = append(audit.Unknown, name)
+		}
+	}
+	sortNames(audit.Undiscoverable)
+	sort.Strings(audit.Unknown)
+	return audit
+}
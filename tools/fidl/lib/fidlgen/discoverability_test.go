@@ -0,0 +1,74 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func protocolDecl(name fidlgen.EncodedCompoundIdentifier, attrs ...fidlgen.Attribute) fidlgen.Protocol {
+	return fidlgen.Protocol{Decl: fidlgen.Decl{
+		Name:       name,
+		Attributes: fidlgen.Attributes{Attributes: attrs},
+	}}
+}
+
+func discoverable(name string) fidlgen.Attribute {
+	attr := fidlgen.Attribute{Name: "discoverable"}
+	if name != "" {
+		attr.Args = []fidlgen.AttributeArg{{
+			Name:  "value",
+			Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: name},
+		}}
+	}
+	return attr
+}
+
+func TestAuditDiscoverability(t *testing.T) {
+	a := fidlgen.Root{
+		Name: "fuchsia.a",
+		Protocols: []fidlgen.Protocol{
+			protocolDecl("fuchsia.a/Logger", discoverable("")),
+			protocolDecl("fuchsia.a/Internal"),
+		},
+	}
+	b := fidlgen.Root{
+		Name: "fuchsia.b",
+		Protocols: []fidlgen.Protocol{
+			protocolDecl("fuchsia.b/LoggerV2", discoverable("fuchsia.a.Logger")),
+			protocolDecl("fuchsia.b/Renamed", discoverable("fuchsia.Renamed")),
+		},
+	}
+	p, err := fidlgen.NewProgram([]fidlgen.Root{b, a})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	audit := p.AuditDiscoverability([]string{
+		"fuchsia.a.Logger", "fuchsia.a.Internal", "fuchsia.b.Renamed", "fuchsia.Missing", "fuchsia.a.Internal",
+	})
+	expected := fidlgen.DiscoverabilityAudit{
+		Discoverable: []fidlgen.DiscoverableProtocol{
+			{Protocol: "fuchsia.a/Logger", DiscoveryName: "fuchsia.a.Logger"},
+			{Protocol: "fuchsia.b/LoggerV2", DiscoveryName: "fuchsia.a.Logger"},
+			{Protocol: "fuchsia.b/Renamed", DiscoveryName: "fuchsia.Renamed"},
+		},
+		Collisions: []fidlgen.DiscoveryNameCollision{
+			{DiscoveryName: "fuchsia.a.Logger", Protocols: []fidlgen.EncodedCompoundIdentifier{"fuchsia.a/Logger", "fuchsia.b/LoggerV2"}},
+		},
+		Undiscoverable: []fidlgen.EncodedCompoundIdentifier{"fuchsia.a/Internal"},
+		Unknown:        []string{"fuchsia.Missing", "fuchsia.b.Renamed"},
+	}
+	if diff := cmp.Diff(expected, audit); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	if name := p.Libraries["fuchsia.b"].Protocols[1].GetProtocolName(); name != `"fuchsia.Renamed"` {
+		t.Errorf("got protocol name %s", name)
+	}
+}
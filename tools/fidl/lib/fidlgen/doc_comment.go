@@ -0,0 +1,412 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DocComment is the parsed content of a doc comment, following the
+// conventions of the FIDL API rubric: markdown-like paragraphs, list items and
+// fenced code blocks, references to other FIDL elements in brackets (e.g. [`Node.Close`]),
+// and trailing annotations describing the request, response and errors of a
+// method:
+//
+//	/// Opens the node at `path`. See [`fuchsia.io/Node`].
+//	///
+//	/// + request `path` the path of the node to open.
+//	/// - response `node` the opened node.
+//	/// * error a zx.status value indicating failure.
+type DocComment struct {
+	Blocks      []DocBlock
+	Annotations []DocAnnotation
+}
+
+// DocBlockKind is the kind of a block of a doc comment.
+type DocBlockKind string
+
+const (
+	DocParagraph DocBlockKind = "paragraph"
+	DocListItem  DocBlockKind = "list_item"
+	DocCodeBlock DocBlockKind = "code"
+)
+
+// DocBlock is a paragraph, an item of a bulleted list or a fenced code block of
+// a doc comment.
+type DocBlock struct {
+	Kind DocBlockKind
+	// Text is the content of a paragraph or list item, with its lines joined
+	// so that it can be wrapped anew.
+	Text []DocSpan
+	// Marker is the bullet of a list item, `-` or `*`.
+	Marker string
+	// Info is the info string of a code block, e.g. "fidl" for ```fidl.
+	Info string
+	// Code holds the lines of a code block, verbatim.
+	Code []string
+}
+
+// DocSpan is a piece of text in a doc comment. It is either plain text or, if
+// Reference is set, a reference to a FIDL declaration or member, in which case
+// Text is the name as written, e.g. "Node.Close".
+type DocSpan struct {
+	Text      string
+	Reference *DocReference
+}
+
+// DocReference is a reference to a FIDL declaration or member.
+type DocReference struct {
+	// Name is the fully qualified name of the declaration or member, e.g.
+	// "fuchsia.io/Node.Close". Names written unqualified are resolved
+	// against the library of the doc comment.
+	Name EncodedCompoundIdentifier
+	// Local is true if the element belongs to the library of the doc comment.
+	Local bool
+}
+
+// DocAnnotationKind is the kind of a trailing doc comment annotation.
+type DocAnnotationKind string
+
+const (
+	// DocRequestAnnotation, written `+ request`, describes a request
+	// parameter.
+	DocRequestAnnotation DocAnnotationKind = "request"
+	// DocResponseAnnotation, written `- response`, describes a response
+	// parameter.
+	DocResponseAnnotation DocAnnotationKind = "response"
+	// DocErrorAnnotation, written `* error`, describes the errors of a method.
+	DocErrorAnnotation DocAnnotationKind = "error"
+)
+
+var docAnnotationMarkers = map[DocAnnotationKind]string{
+	DocRequestAnnotation:  "+",
+	DocResponseAnnotation: "-",
+	DocErrorAnnotation:    "*",
+}
+
+// DocAnnotation is a trailing annotation of a doc comment.
+type DocAnnotation struct {
+	Kind DocAnnotationKind
+	// Param is the name of the parameter described, if any.
+	Param string
+	Text  []DocSpan
+}
+
+var (
+	docAnnotationRegexp = regexp.MustCompile("^([+*-]) (request|response|error)\\b\\s*(?:`([A-Za-z0-9_]+)`)?\\s*(.*)$")
+	docListItemRegexp   = regexp.MustCompile(`^([*-]) +(.*)$`)
+	docReferenceRegexp  = regexp.MustCompile(
+		"\\[(`?)((?:[A-Za-z][A-Za-z0-9_]*(?:\\.[A-Za-z][A-Za-z0-9_]*)*/)?[A-Za-z][A-Za-z0-9_]*(?:\\.[A-Za-z][A-Za-z0-9_]*)?)(`?)\\]")
+)
+
+// ParsedDocComment parses the doc comment of an element of the given library.
+func (el Attributes) ParsedDocComment(library EncodedLibraryIdentifier) DocComment {
+	return ParseDocComment(el.DocComments(), library)
+}
+
+// ParseDocComment parses the lines of a doc comment, as returned by
+// Attributes.DocComments, of an element of the given library.
+func ParseDocComment(lines []string, library EncodedLibraryIdentifier) DocComment {
+	// Doc comments are conventionally written with a space after `///`, which
+	// is not part of the content.
+	indented := true
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" && !strings.HasPrefix(line, " ") {
+			indented = false
+		}
+	}
+
+	var d DocComment
+	var paragraph, listItem []string
+	var listMarker string
+	var code *DocBlock
+	var annotation *DocAnnotation
+	flush := func() {
+		if len(paragraph) > 0 {
+			d.Blocks = append(d.Blocks, DocBlock{
+				Kind: DocParagraph,
+				Text: parseDocSpans(strings.Join(paragraph, " "), library),
+			})
+			paragraph = nil
+		}
+		if len(listItem) > 0 {
+			d.Blocks = append(d.Blocks, DocBlock{
+				Kind:   DocListItem,
+				Text:   parseDocSpans(strings.Join(listItem, " "), library),
+				Marker: listMarker,
+			})
+			listItem = nil
+		}
+		annotation = nil
+	}
+	for _, line := range lines {
+		if indented && line != "" {
+			line = line[1:]
+		}
+		trimmed := strings.TrimSpace(line)
+		if code != nil {
+			if strings.HasPrefix(trimmed, "```") {
+				d.Blocks = append(d.Blocks, *code)
+				code = nil
+			} else {
+				code.Code = append(code.Code, line)
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			code = &DocBlock{Kind: DocCodeBlock, Info: strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))}
+			continue
+		}
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		if m := docAnnotationRegexp.FindStringSubmatch(trimmed); m != nil && docAnnotationMarkers[DocAnnotationKind(m[2])] == m[1] {
+			flush()
+			d.Annotations = append(d.Annotations, DocAnnotation{
+				Kind:  DocAnnotationKind(m[2]),
+				Param: m[3],
+				Text:  parseDocSpans(m[4], library),
+			})
+			annotation = &d.Annotations[len(d.Annotations)-1]
+			continue
+		}
+		if annotation != nil {
+			// A continuation line of the annotation.
+			annotation.Text = appendDocSpans(annotation.Text, parseDocSpans(" "+trimmed, library))
+			continue
+		}
+		if m := docListItemRegexp.FindStringSubmatch(trimmed); m != nil {
+			flush()
+			listMarker, listItem = m[1], []string{m[2]}
+			continue
+		}
+		if listItem != nil {
+			// A continuation line of the list item.
+			listItem = append(listItem, trimmed)
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+	// Unterminated code blocks extend to the end of the comment.
+	if code != nil {
+		d.Blocks = append(d.Blocks, *code)
+	}
+	flush()
+	return d
+}
+
+// docCodeSpans returns the ranges of the code spans of text, i.e. of text
+// between runs of backticks of the same length, backticks included.
+func docCodeSpans(text string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(text); {
+		if text[i] != '`' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(text) && text[i] == '`' {
+			i++
+		}
+		fence := text[start:i]
+		end := strings.Index(text[i:], fence)
+		if end < 0 {
+			// An unmatched run of backticks is plain text.
+			continue
+		}
+		i += end + len(fence)
+		spans = append(spans, [2]int{start, i})
+	}
+	return spans
+}
+
+// parseDocSpans splits text into plain text and references. Brackets within
+// code spans, e.g. `buf[i]`, are not references.
+func parseDocSpans(text string, library EncodedLibraryIdentifier) []DocSpan {
+	codeSpans := docCodeSpans(text)
+	inCode := func(pos int) bool {
+		for _, c := range codeSpans {
+			if c[0] <= pos && pos < c[1] {
+				return true
+			}
+		}
+		return false
+	}
+	var spans []DocSpan
+	last := 0
+	for _, m := range docReferenceRegexp.FindAllStringSubmatchIndex(text, -1) {
+		start, end := m[0], m[1]
+		openTick, name, closeTick := text[m[2]:m[3]], text[m[4]:m[5]], text[m[6]:m[7]]
+		// Skip mismatched backticks, markdown links, e.g. [Foo](url), and
+		// brackets within code.
+		if openTick != closeTick || (end < len(text) && text[end] == '(') || inCode(start) {
+			continue
+		}
+		ref := DocReference{Name: EncodedCompoundIdentifier(name), Local: true}
+		if strings.Contains(name, "/") {
+			ref.Local = ref.Name.LibraryName() == library
+		} else {
+			ref.Name = EncodedCompoundIdentifier(fmt.Sprintf("%s/%s", library, name))
+		}
+		spans = appendDocSpans(spans, []DocSpan{{Text: text[last:start]}})
+		spans = append(spans, DocSpan{Text: name, Reference: &ref})
+		last = end
+	}
+	return appendDocSpans(spans, []DocSpan{{Text: text[last:]}})
+}
+
+// appendDocSpans appends spans, merging adjacent plain text and dropping empty
+// text.
+func appendDocSpans(spans []DocSpan, more []DocSpan) []DocSpan {
+	for _, s := range more {
+		if s.Reference == nil {
+			if s.Text == "" {
+				continue
+			}
+			if n := len(spans); n > 0 && spans[n-1].Reference == nil {
+				spans[n-1].Text += s.Text
+				continue
+			}
+		}
+		spans = append(spans, s)
+	}
+	return spans
+}
+
+// DocCommentRenderer renders parsed doc comments as comments of a target
+// language.
+type DocCommentRenderer struct {
+	// Prefix starts each line of the comment, e.g. "///".
+	Prefix string
+	// Width is the length at which lines of text are wrapped, prefix
+	// included. Code blocks are never wrapped. Zero disables wrapping.
+	Width int
+	// CodeIndent, if set, indents the lines of code blocks instead of fencing
+	// them, for languages where doc comments are not markdown.
+	CodeIndent string
+	// Reference renders a reference to a FIDL declaration or member, given
+	// the name as written.
+	Reference func(text string, ref DocReference) string
+}
+
+// Render renders a doc comment as lines of comments. The first line starts
+// with the prefix; no newlines are included.
+func (r DocCommentRenderer) Render(d DocComment) []string {
+	var lines []string
+	separate := func() {
+		if len(lines) > 0 {
+			lines = append(lines, r.Prefix)
+		}
+	}
+	for i, b := range d.Blocks {
+		// Items of a list are not separated.
+		if b.Kind != DocListItem || i == 0 || d.Blocks[i-1].Kind != DocListItem {
+			separate()
+		}
+		switch b.Kind {
+		case DocParagraph:
+			lines = append(lines, r.wrap(r.renderSpans(b.Text), "")...)
+		case DocListItem:
+			lines = append(lines, r.wrap(b.Marker+" "+r.renderSpans(b.Text), "  ")...)
+		case DocCodeBlock:
+			if r.CodeIndent != "" {
+				for _, c := range b.Code {
+					lines = append(lines, strings.TrimRight(r.Prefix+r.CodeIndent+c, " \t"))
+				}
+				continue
+			}
+			lines = append(lines, r.line("```"+b.Info))
+			for _, c := range b.Code {
+				lines = append(lines, r.line(c))
+			}
+			lines = append(lines, r.line("```"))
+		}
+	}
+	for i, a := range d.Annotations {
+		if i == 0 {
+			separate()
+		}
+		text := fmt.Sprintf("%s %s", docAnnotationMarkers[a.Kind], a.Kind)
+		if a.Param != "" {
+			text += fmt.Sprintf(" `%s`", a.Param)
+		}
+		if spans := r.renderSpans(a.Text); spans != "" {
+			text += " " + spans
+		}
+		lines = append(lines, r.wrap(text, "  ")...)
+	}
+	return lines
+}
+
+func (r DocCommentRenderer) line(text string) string {
+	if text == "" {
+		return r.Prefix
+	}
+	return r.Prefix + " " + text
+}
+
+func (r DocCommentRenderer) renderSpans(spans []DocSpan) string {
+	var b strings.Builder
+	for _, s := range spans {
+		if s.Reference != nil && r.Reference != nil {
+			b.WriteString(r.Reference(s.Text, *s.Reference))
+		} else if s.Reference != nil {
+			fmt.Fprintf(&b, "`%s`", s.Text)
+		} else {
+			b.WriteString(s.Text)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// wrap wraps text into comment lines, indenting continuation lines.
+func (r DocCommentRenderer) wrap(text string, indent string) []string {
+	var lines []string
+	current := ""
+	for _, word := range strings.Fields(text) {
+		if current == "" {
+			current = word
+			if len(lines) > 0 {
+				current = indent + word
+			}
+			continue
+		}
+		if r.Width > 0 && len(r.line(current+" "+word)) > r.Width {
+			lines = append(lines, r.line(current))
+			current = indent + word
+			continue
+		}
+		current += " " + word
+	}
+	if current != "" {
+		lines = append(lines, r.line(current))
+	}
+	return lines
+}
+
+// CppDocComments renders Doxygen-style C++ doc comments.
+var CppDocComments = DocCommentRenderer{Prefix: "///", Width: 80}
+
+// DartDocComments returns a renderer of Dart doc comments, linking the
+// declarations of decls, e.g. Root.Decls.
+func DartDocComments(decls DeclMap) DocCommentRenderer {
+	return DocCommentRenderer{Prefix: "///", Width: 80, Reference: LinkLocalDecls("[%s]", DartNames, decls)}
+}
+
+// GoDocComments returns a renderer of Go doc comments, linking the
+// declarations of decls, e.g. Root.Decls.
+func GoDocComments(decls DeclMap) DocCommentRenderer {
+	return DocCommentRenderer{Prefix: "//", Width: 80, CodeIndent: "\t", Reference: LinkLocalDecls("[%s]", GoNames, decls)}
+}
+
+// RustDocComments returns a renderer of rustdoc comments, linking the
+// declarations of decls, e.g. Root.Decls, as intra-doc links.
+func RustDocComments(decls DeclMap) DocCommentRenderer {
+	return DocCommentRenderer{Prefix: "///", Width: 100, Reference: LinkLocalDecls("[`%s`]", RustNames, decls)}
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+var docCommentLines = strings.Split(` Opens the node at `+"`path`"+`, which may
+ be a [`+"`Directory`"+`] or a [fuchsia.io/Node]. See
+ [the docs](https://fuchsia.dev) and [Directory.Open].
+
+ `+"```fidl"+`
+ protocol P {};
+ `+"```"+`
+
+ + request `+"`path`"+` the path of the node,
+   relative to this directory.
+ * error a zx.status value.`, "\n")
+
+func TestParseDocComment(t *testing.T) {
+	text := func(s string) fidlgen.DocSpan { return fidlgen.DocSpan{Text: s} }
+	ref := func(s string, name fidlgen.EncodedCompoundIdentifier, local bool) fidlgen.DocSpan {
+		return fidlgen.DocSpan{Text: s, Reference: &fidlgen.DocReference{Name: name, Local: local}}
+	}
+	expected := fidlgen.DocComment{
+		Blocks: []fidlgen.DocBlock{
+			{
+				Kind: fidlgen.DocParagraph,
+				Text: []fidlgen.DocSpan{
+					text("Opens the node at `path`, which may be a "),
+					ref("Directory", "fuchsia.io/Directory", true),
+					text(" or a "),
+					ref("fuchsia.io/Node", "fuchsia.io/Node", true),
+					text(". See [the docs](https://fuchsia.dev) and "),
+					ref("Directory.Open", "fuchsia.io/Directory.Open", true),
+					text("."),
+				},
+			},
+			{Kind: fidlgen.DocCodeBlock, Info: "fidl", Code: []string{"protocol P {};"}},
+		},
+		Annotations: []fidlgen.DocAnnotation{
+			{
+				Kind:  fidlgen.DocRequestAnnotation,
+				Param: "path",
+				Text:  []fidlgen.DocSpan{text("the path of the node, relative to this directory.")},
+			},
+			{
+				Kind: fidlgen.DocErrorAnnotation,
+				Text: []fidlgen.DocSpan{text("a zx.status value.")},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, fidlgen.ParseDocComment(docCommentLines, "fuchsia.io")); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	external := fidlgen.ParseDocComment([]string{" See [fuchsia.io/Node]."}, "example")
+	if r := external.Blocks[0].Text[1].Reference; r == nil || r.Local {
+		t.Errorf("expected a reference to another library, got %+v", external.Blocks[0].Text)
+	}
+}
+
+func TestRenderDocComment(t *testing.T) {
+	d := fidlgen.ParseDocComment(docCommentLines, "fuchsia.io")
+	for _, tc := range []struct {
+		name     string
+		renderer fidlgen.DocCommentRenderer
+		expected []string
+	}{
+		{
+			name:     "cpp",
+			renderer: fidlgen.CppDocComments,
+			expected: []string{
+				"/// Opens the node at `path`, which may be a `Directory` or a `fuchsia.io/Node`.",
+				"/// See [the docs](https://fuchsia.dev) and `Directory.Open`.",
+				"///",
+				"/// ```fidl",
+				"/// protocol P {};",
+				"/// ```",
+				"///",
+				"/// + request `path` the path of the node, relative to this directory.",
+				"/// * error a zx.status value.",
+			},
+		},
+		{
+			name: "go",
+			renderer: fidlgen.GoDocComments(fidlgen.DeclMap{
+				"fuchsia.io/Directory": fidlgen.ProtocolDeclType,
+				"fuchsia.io/Node":      fidlgen.ProtocolDeclType,
+			}),
+			expected: []string{
+				"// Opens the node at `path`, which may be a [Directory] or a [Node]. See [the",
+				"// docs](https://fuchsia.dev) and `Directory.Open`.",
+				"//",
+				"//\tprotocol P {};",
+				"//",
+				"// + request `path` the path of the node, relative to this directory.",
+				"// * error a zx.status value.",
+			},
+		},
+		{
+			name:     "narrow",
+			renderer: fidlgen.DocCommentRenderer{Prefix: "///", Width: 40},
+			expected: []string{
+				"/// Opens the node at `path`, which may",
+				"/// be a `Directory` or a",
+				"/// `fuchsia.io/Node`. See [the",
+				"/// docs](https://fuchsia.dev) and",
+				"/// `Directory.Open`.",
+				"///",
+				"/// ```fidl",
+				"/// protocol P {};",
+				"/// ```",
+				"///",
+				"/// + request `path` the path of the",
+				"///   node, relative to this directory.",
+				"/// * error a zx.status value.",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, tc.renderer.Render(d)); diff != "" {
+				t.Errorf("unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDocCommentCodeSpansAndLists(t *testing.T) {
+	lines := []string{
+		" Reads `buf[i]` and ``a[`b`]``, unlike [Node].",
+		" - the first item, which",
+		"   goes on.",
+		" * the second item.",
+		"",
+		" After the list.",
+	}
+	text := func(s string) fidlgen.DocSpan { return fidlgen.DocSpan{Text: s} }
+	expected := []fidlgen.DocBlock{
+		{
+			Kind: fidlgen.DocParagraph,
+			Text: []fidlgen.DocSpan{
+				text("Reads `buf[i]` and ``a[`b`]``, unlike "),
+				{Text: "Node", Reference: &fidlgen.DocReference{Name: "example/Node", Local: true}},
+				text("."),
+			},
+		},
+		{Kind: fidlgen.DocListItem, Marker: "-", Text: []fidlgen.DocSpan{text("the first item, which goes on.")}},
+		{Kind: fidlgen.DocListItem, Marker: "*", Text: []fidlgen.DocSpan{text("the second item.")}},
+		{Kind: fidlgen.DocParagraph, Text: []fidlgen.DocSpan{text("After the list.")}},
+	}
+	d := fidlgen.ParseDocComment(lines, "example")
+	if diff := cmp.Diff(expected, d.Blocks); diff != "" {
+		t.Errorf("unexpected blocks (-want +got):\n%s", diff)
+	}
+
+	renderer := fidlgen.DocCommentRenderer{
+		Prefix: "///",
+		Width:  30,
+		Reference: func(text string, ref fidlgen.DocReference) string {
+			return "[`" + text + "`]"
+		},
+	}
+	expectedLines := []string{
+		"/// Reads `buf[i]` and",
+		"/// ``a[`b`]``, unlike",
+		"/// [`Node`].",
+		"///",
+		"/// - the first item, which",
+		"///   goes on.",
+		"/// * the second item.",
+		"///",
+		"/// After the list.",
+	}
+	if diff := cmp.Diff(expectedLines, renderer.Render(d)); diff != "" {
+		t.Errorf("unexpected rendering (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// The driver transport carries driver runtime handles, i.e. `fdf.handle`, as
+// well as Zircon handles, whereas other transports only carry the latter. The
+// endpoints of protocols spoken over the driver transport are driver
+// handles, which can then only be sent over the driver transport themselves.
+// Backends generating driver bindings need to tell the two classes of handles
+// apart, both to name their types and to reject layouts which cannot be sent.
+
+// DriverHandleResource is the resource of driver runtime handles.
+const DriverHandleResource EncodedCompoundIdentifier = "fdf/handle"
+
+// HandleClass is the class of handles a type is carried as.
+type HandleClass string
+
+const (
+	// NoHandle is the class of types which are not handles.
+	NoHandle HandleClass = ""
+	// ZirconHandle is the class of Zircon handles, including the endpoints
+	// of protocols spoken over Zircon channels.
+	ZirconHandle HandleClass = "zircon"
+	// DriverHandle is the class of driver runtime handles, including the
+	// endpoints of protocols spoken over the driver transport.
+	DriverHandle HandleClass = "driver"
+)
+
+// HandleClass returns the class of handles the type is carried as. Nested
+// types, e.g. the elements of vectors, are not considered. Client ends are
+// only told apart from other identifier types through Type.Endpoint, which
+// DecodeJSONIr sets.
+func (t *Type) HandleClass() HandleClass {
+	switch {
+	case t.Kind == HandleType:
+		if t.HandleResource() == DriverHandleResource {
+			return DriverHandle
+		}
+		return ZirconHandle
+	case t.Endpoint != nil:
+		if t.Endpoint.Transport == DriverTransport {
+			return DriverHandle
+		}
+		return ZirconHandle
+	case t.Kind == RequestType:
+		if t.ProtocolTransport == DriverTransport {
+			return DriverHandle
+		}
+		return ZirconHandle
+	default:
+		return NoHandle
+	}
+}
+
+// IsDriverHandle returns whether the type is a driver runtime handle, or an
+// endpoint of a protocol spoken over the driver transport.
+func (t *Type) IsDriverHandle() bool {
+	return t.HandleClass() == DriverHandle
+}
+
+// CarriesHandleClass returns whether the transport can carry handles of the
+// given class.
+func (t Transport) CarriesHandleClass(class HandleClass) bool {
+	switch class {
+	case NoHandle, ZirconHandle:
+		return true
+	case DriverHandle:
+		return t == DriverTransport
+	default:
+		return false
+	}
+}
+
+// CanCarry returns whether values of the type may be sent over the transport:
+// that is, whether the transport carries all of the handles they may hold,
+// be it directly or within the members of their layouts. Layouts are resolved
+// through decls, which should be a Compilation or a Program for types
+// referring to other libraries. An error is returned if a layout cannot be
+// resolved.
+func (t Transport) CanCarry(typ Type, decls DeclResolver) (bool, error) {
+	visited := make(map[EncodedCompoundIdentifier]struct{})
+	var canCarry func(typ *Type) (bool, error)
+	canCarryMembers := func(types []*Type) (bool, error) {
+		for _, typ := range types {
+			if ok, err := canCarry(typ); !ok || err != nil {
+				return ok, err
+			}
+		}
+		return true, nil
+	}
+	canCarry = func(typ *Type) (bool, error) {
+		if !t.CarriesHandleClass(typ.HandleClass()) {
+			return false, nil
+		}
+		switch typ.Kind {
+		case ArrayType, VectorType:
+			return canCarry(typ.ElementType)
+		case IdentifierType:
+			if _, ok := visited[typ.Identifier]; ok {
+				return true, nil
+			}
+			visited[typ.Identifier] = struct{}{}
+			decl, ok := decls.LookupDecl(typ.Identifier)
+			if !ok {
+				return false, fmt.Errorf("%s not found", typ.Identifier)
+			}
+			var types []*Type
+			switch decl := decl.(type) {
+			case *Protocol:
+				// Type.Endpoint is not set on IR constructed by other means
+				// than DecodeJSONIr.
+				if typ.Endpoint == nil && typ.ProtocolTransport == DriverTransport {
+					return t == DriverTransport, nil
+				}
+			case *Struct:
+				for i := range decl.Members {
+					types = append(types, &decl.Members[i].Type)
+				}
+			case *Table:
+				for i := range decl.Members {
+					if !decl.Members[i].Reserved {
+						types = append(types, &decl.Members[i].Type)
+					}
+				}
+			case *Union:
+				for i := range decl.Members {
+					if !decl.Members[i].Reserved {
+						types = append(types, &decl.Members[i].Type)
+					}
+				}
+			}
+			return canCarryMembers(types)
+		}
+		return true, nil
+	}
+	return canCarry(&typ)
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestHandleClass(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		typ      fidlgen.Type
+		expected fidlgen.HandleClass
+	}{
+		{"string", fidlgen.Type{Kind: fidlgen.StringType}, fidlgen.NoHandle},
+		{"zx handle", fidlgen.Type{Kind: fidlgen.HandleType, HandleSubtype: fidlgen.HandleSubtypeVmo, ResourceIdentifier: "zx/Handle"}, fidlgen.ZirconHandle},
+		{"fdf handle", fidlgen.Type{Kind: fidlgen.HandleType, HandleSubtype: fidlgen.HandleSubtypeChannel, ResourceIdentifier: "fdf/handle"}, fidlgen.DriverHandle},
+		{"channel server end", fidlgen.Type{Kind: fidlgen.RequestType, RequestSubtype: "example/P"}, fidlgen.ZirconHandle},
+		{"driver server end", fidlgen.Type{Kind: fidlgen.RequestType, RequestSubtype: "example/P", ProtocolTransport: fidlgen.DriverTransport}, fidlgen.DriverHandle},
+		{
+			"driver client end",
+			fidlgen.Type{
+				Kind:       fidlgen.IdentifierType,
+				Identifier: "example/P",
+				Endpoint:   &fidlgen.Endpoint{Role: fidlgen.ClientEndpoint, Protocol: "example/P", Transport: fidlgen.DriverTransport},
+			},
+			fidlgen.DriverHandle,
+		},
+	} {
+		if actual := tc.typ.HandleClass(); actual != tc.expected {
+			t.Errorf("%s: got class %q, want %q", tc.name, actual, tc.expected)
+		}
+		if tc.typ.IsDriverHandle() != (tc.expected == fidlgen.DriverHandle) {
+			t.Errorf("%s: unexpected IsDriverHandle()", tc.name)
+		}
+	}
+}
+
+func TestTransportCanCarry(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.DriverTransport)
+	ends := fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "test.drivertransport/Ends"}
+	fdfHandle := fidlgen.Type{Kind: fidlgen.HandleType, HandleSubtype: fidlgen.HandleSubtypeChannel, ResourceIdentifier: "fdf/handle"}
+	zxHandles := fidlgen.Type{
+		Kind:        fidlgen.VectorType,
+		ElementType: &fidlgen.Type{Kind: fidlgen.HandleType, HandleSubtype: fidlgen.HandleSubtypeEvent, ResourceIdentifier: "zx/Handle"},
+	}
+	fdfHandles := fidlgen.Type{Kind: fidlgen.ArrayType, ElementType: &fdfHandle}
+
+	for _, tc := range []struct {
+		name      string
+		typ       fidlgen.Type
+		transport fidlgen.Transport
+		expected  bool
+	}{
+		{"driver ends over channel", ends, fidlgen.ChannelTransport, false},
+		{"driver ends over driver", ends, fidlgen.DriverTransport, true},
+		{"zx handles over channel", zxHandles, fidlgen.ChannelTransport, true},
+		{"zx handles over driver", zxHandles, fidlgen.DriverTransport, true},
+		{"fdf handles over channel", fdfHandles, fidlgen.ChannelTransport, false},
+		{"fdf handles over driver", fdfHandles, fidlgen.DriverTransport, true},
+	} {
+		actual, err := tc.transport.CanCarry(tc.typ, &root)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %t, want %t", tc.name, actual, tc.expected)
+		}
+	}
+
+	missing := fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "test.drivertransport/Missing"}
+	if _, err := fidlgen.ChannelTransport.CanCarry(missing, &root); err == nil {
+		t.Errorf("expected an error for an unresolved layout")
+	}
+}
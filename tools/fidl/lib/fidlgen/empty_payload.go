@@ -0,0 +1,70 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// A method may have no payload, e.g. `Ping()`, or an empty struct payload,
+// e.g. the success type of `Ping() -> () error uint32` or the payload of
+// `Ping(struct {})`. Bindings generate all of those with zero parameters, and
+// may also see empty structs through the placeholder member standing in for
+// their single byte. The helpers below give a single answer for all cases.
+
+// IsPlaceholder returns whether the member is the placeholder the
+// representation gives empty structs, as returned by Placeholder.
+func (r EmptyStructRepresentation) IsPlaceholder(m StructMember) bool {
+	return r.PlaceholderName != "" && string(m.Name) == r.PlaceholderName &&
+		m.Type.Kind == PrimitiveType && m.Type.PrimitiveSubtype == Uint8
+}
+
+// IsEmpty returns whether the struct is empty, or only has the placeholder of
+// the representation as a member.
+func (r EmptyStructRepresentation) IsEmpty(s Struct) bool {
+	return s.IsEmpty() || len(s.Members) == 1 && r.IsPlaceholder(s.Members[0])
+}
+
+// IsEmptyPayload returns whether a payload carries no value: that is, whether
+// there is none, or it is an empty struct. The payload is resolved through
+// decls, and an error is returned if it cannot be. Empty tables are not empty
+// payloads, since members may be added to them compatibly.
+func IsEmptyPayload(payload *Type, decls DeclResolver) (bool, error) {
+	if payload == nil {
+		return true, nil
+	}
+	decl, ok := decls.LookupDecl(payload.Identifier)
+	if !ok {
+		return false, fmt.Errorf("payload %s is not declared", payload.Identifier)
+	}
+	s, ok := decl.(*Struct)
+	return ok && s.IsEmpty(), nil
+}
+
+// HasZeroParameters returns whether the given message of the method should be
+// generated with zero parameters: its payload is missing or an empty struct,
+// or, if the response is wrapped in a result union, its success type is an
+// empty struct. Methods without a request, or without a response, have zero
+// parameters in that direction. Payloads are resolved as for
+// RequestParameters.
+func (m *Method) HasZeroParameters(direction MessageDirection, decls DeclResolver) (bool, error) {
+	var payload *Type
+	switch direction {
+	case RequestMessage:
+		payload = m.RequestPayload
+	case ResponseMessage, EventMessage:
+		payload = m.ResponsePayload
+		if m.ResultType != nil {
+			payload = m.ValueType
+		}
+	default:
+		return false, fmt.Errorf("unknown message direction %q", direction)
+	}
+	empty, err := IsEmptyPayload(payload, decls)
+	if err != nil {
+		return false, fmt.Errorf("%s %s: %w", m.Name, direction, err)
+	}
+	return empty, nil
+}
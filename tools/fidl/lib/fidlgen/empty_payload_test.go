@@ -0,0 +1,86 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestEmptyStructPlaceholder(t *testing.T) {
+	withPlaceholder := fidlgen.EmptyStructRepresentation{PlaceholderName: "__reserved"}
+	placeholder := fidlgen.EmptyStructMember("__reserved")
+	padded := structDecl("example/Padded")
+	padded.Members = []fidlgen.StructMember{placeholder}
+
+	if !withPlaceholder.IsPlaceholder(placeholder) || !withPlaceholder.IsEmpty(padded) {
+		t.Errorf("placeholder not recognized")
+	}
+	if fidlgen.ZeroSizedEmptyStruct.IsPlaceholder(placeholder) || fidlgen.ZeroSizedEmptyStruct.IsEmpty(padded) {
+		t.Errorf("placeholder recognized without a placeholder name")
+	}
+	if !fidlgen.ZeroSizedEmptyStruct.IsEmpty(structDecl("example/Empty")) {
+		t.Errorf("empty struct not recognized")
+	}
+	if withPlaceholder.IsEmpty(structDecl("example/S", "__reserved", "other")) {
+		t.Errorf("struct with a member named like the placeholder deemed empty")
+	}
+}
+
+func TestMethodHasZeroParameters(t *testing.T) {
+	empty := structDecl("example/Empty")
+	args := structDecl("example/Args", "a")
+	options := fidlgen.Table{ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+		LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Options"}},
+	}}
+	root := fidlgen.Root{
+		Structs: []fidlgen.Struct{empty, args},
+		Tables:  []fidlgen.Table{options},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		method    fidlgen.Method
+		direction fidlgen.MessageDirection
+		expected  bool
+	}{
+		{"no payload", fidlgen.Method{HasRequest: true}, fidlgen.RequestMessage, true},
+		{"empty struct", fidlgen.Method{HasRequest: true, RequestPayload: identifierType("example/Empty")}, fidlgen.RequestMessage, true},
+		{"struct", fidlgen.Method{HasRequest: true, RequestPayload: identifierType("example/Args")}, fidlgen.RequestMessage, false},
+		{"empty table", fidlgen.Method{HasRequest: true, RequestPayload: identifierType("example/Options")}, fidlgen.RequestMessage, false},
+		{"event", fidlgen.Method{HasResponse: true, ResponsePayload: identifierType("example/Args")}, fidlgen.EventMessage, false},
+	} {
+		actual, err := tc.method.HasZeroParameters(tc.direction, &root)
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: got %t, want %t", tc.name, actual, tc.expected)
+		}
+	}
+
+	missing := fidlgen.Method{Name: "Missing", RequestPayload: identifierType("example/Missing")}
+	if _, err := missing.HasZeroParameters(fidlgen.RequestMessage, &root); err == nil {
+		t.Errorf("expected an error for an undeclared payload")
+	}
+
+	// Responses wrapped in a result union are judged by their success type.
+	open := irtestdata.Load(t, irtestdata.OpenProtocol)
+	for _, m := range open.Protocols[0].Methods {
+		if !m.HasResponse {
+			continue
+		}
+		direction := fidlgen.ResponseMessage
+		if m.IsEvent() {
+			direction = fidlgen.EventMessage
+		}
+		if actual, err := m.HasZeroParameters(direction, &open); err != nil || !actual {
+			t.Errorf("%s: got %t (%v), want true", m.Name, actual, err)
+		}
+	}
+}
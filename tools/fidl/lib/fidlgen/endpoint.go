@@ -0,0 +1,54 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// The IR encodes the two ends of a protocol differently: a client end
+// (`client_end:P`, or plain `P` in older syntax) is an IdentifierType naming
+// the protocol, whereas a server end (`server_end:P`, or `request<P>`) is a
+// RequestType. Telling a client end apart from other identifier types further
+// requires looking the identifier up. Type.Endpoint describes both the same
+// way, so that backends need not pattern-match the two encodings.
+
+// EndpointRole is the end of a protocol an endpoint type stands for.
+type EndpointRole string
+
+const (
+	ClientEndpoint EndpointRole = "client_end"
+	ServerEndpoint EndpointRole = "server_end"
+)
+
+// Endpoint describes a protocol endpoint type.
+type Endpoint struct {
+	Role EndpointRole
+	// Protocol is the name of the protocol spoken over the endpoint.
+	Protocol EncodedCompoundIdentifier
+	// Transport is the transport of the endpoint, Channel unless specified
+	// otherwise in the IR.
+	Transport Transport
+}
+
+// MarkEndpointTypes sets Type.Endpoint on the protocol endpoint types of the
+// library. DecodeJSONIr already does this: it only needs to be called on IR
+// that is constructed or modified by other means.
+func (r *Root) MarkEndpointTypes() {
+	decls := r.DeclInfo()
+	mark := func(typ *Type) bool {
+		transport := typ.ProtocolTransport
+		if transport == "" {
+			transport = ChannelTransport
+		}
+		typ.Endpoint = nil
+		switch {
+		case typ.Kind == RequestType:
+			typ.Endpoint = &Endpoint{Role: ServerEndpoint, Protocol: typ.RequestSubtype, Transport: transport}
+		case typ.Kind == IdentifierType && decls[typ.Identifier].Type == ProtocolDeclType:
+			typ.Endpoint = &Endpoint{Role: ClientEndpoint, Protocol: typ.Identifier, Transport: transport}
+		}
+		return true
+	}
+	r.forEachTopLevelType(func(typ *Type) {
+		WalkType(typ, mark)
+	})
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestMarkEndpointTypes(t *testing.T) {
+	serverEnd := fidlgen.Type{Kind: fidlgen.RequestType, RequestSubtype: "example/Node", ProtocolTransport: fidlgen.ChannelTransport}
+	holder := structDecl("example/Holder", "client", "server", "driver", "vector", "external", "struct")
+	holder.Members[0].Type = *identifierType("example/Node")
+	holder.Members[1].Type = serverEnd
+	holder.Members[2].Type = fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/Device", ProtocolTransport: fidlgen.DriverTransport}
+	holder.Members[3].Type = fidlgen.Type{Kind: fidlgen.VectorType, ElementType: &serverEnd}
+	holder.Members[4].Type = *identifierType("dep/External")
+	holder.Members[5].Type = *identifierType("example/Holder")
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{holder},
+		Protocols: []fidlgen.Protocol{
+			{Decl: fidlgen.Decl{Name: "example/Node"}},
+			{Decl: fidlgen.Decl{Name: "example/Device"}},
+		},
+		Libraries: []fidlgen.Library{
+			{
+				Name:  "dep",
+				Decls: fidlgen.DeclInfoMap{"dep/External": {Type: fidlgen.ProtocolDeclType}},
+			},
+		},
+	}
+	root.MarkEndpointTypes()
+
+	members := root.Structs[0].Members
+	expected := []*fidlgen.Endpoint{
+		{Role: fidlgen.ClientEndpoint, Protocol: "example/Node", Transport: fidlgen.ChannelTransport},
+		{Role: fidlgen.ServerEndpoint, Protocol: "example/Node", Transport: fidlgen.ChannelTransport},
+		{Role: fidlgen.ClientEndpoint, Protocol: "example/Device", Transport: fidlgen.DriverTransport},
+		nil,
+		{Role: fidlgen.ClientEndpoint, Protocol: "dep/External", Transport: fidlgen.ChannelTransport},
+		nil,
+	}
+	for i := range expected {
+		if diff := cmp.Diff(expected[i], members[i].Type.Endpoint); diff != "" {
+			t.Errorf("%s: unexpected diff (-want +got):\n%s", members[i].Name, diff)
+		}
+	}
+	if diff := cmp.Diff(expected[1], members[3].Type.ElementType.Endpoint); diff != "" {
+		t.Errorf("vector element: unexpected diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// EnumValue is the numeric value of an enum member. Whether it is read as an
+// int64 or as a uint64 depends on the signedness of the underlying type of
+// the enum, so that all values of all integer types are represented.
+type EnumValue struct {
+	// Signed indicates whether the underlying type is signed, in which case
+	// the value is Int. Otherwise, it is Uint.
+	Signed bool
+	Int    int64
+	Uint   uint64
+}
+
+// Less returns whether the value is less than another value of the same enum.
+func (v EnumValue) Less(other EnumValue) bool {
+	if v.Signed {
+		return v.Int < other.Int
+	}
+	return v.Uint < other.Uint
+}
+
+func (v EnumValue) String() string {
+	if v.Signed {
+		return strconv.FormatInt(v.Int, 10)
+	}
+	return strconv.FormatUint(v.Uint, 10)
+}
+
+// IntValue parses the value of the member, given the underlying type of its
+// enum. It fails if the value does not fit in the type.
+func (member *EnumMember) IntValue(subtype PrimitiveSubtype) (EnumValue, error) {
+	if subtype.IsSigned() {
+		i, err := member.Value.AsInt64(subtype)
+		if err != nil {
+			return EnumValue{}, fmt.Errorf("%s: %w", member.Name, err)
+		}
+		return EnumValue{Signed: true, Int: i}, nil
+	}
+	u, err := member.Value.AsUint64(subtype)
+	if err != nil {
+		return EnumValue{}, fmt.Errorf("%s: %w", member.Name, err)
+	}
+	return EnumValue{Uint: u}, nil
+}
+
+// EnumMemberValue is an enum member along with its parsed value.
+type EnumMemberValue struct {
+	EnumMember
+	// Number is the parsed value of the member.
+	Number EnumValue
+}
+
+// MemberValues returns the members of the enum along with their values, in
+// declaration order. It fails if a value does not fit in the underlying type.
+func (enum *Enum) MemberValues() ([]EnumMemberValue, error) {
+	var members []EnumMemberValue
+	for i := range enum.Members {
+		v, err := enum.Members[i].IntValue(enum.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%w", enum.Name, err)
+		}
+		members = append(members, EnumMemberValue{EnumMember: enum.Members[i], Number: v})
+	}
+	return members, nil
+}
+
+// SortedMembersByValue returns the members of the enum along with their
+// values, sorted by value. Members sharing a value are kept in declaration
+// order.
+func (enum *Enum) SortedMembersByValue() ([]EnumMemberValue, error) {
+	members, err := enum.MemberValues()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Number.Less(members[j].Number)
+	})
+	return members, nil
+}
+
+// DuplicateValues returns the groups of members of the enum which share a
+// value, e.g. aliases kept for source compatibility, sorted by value. Each
+// group lists member names in declaration order. Backends for languages
+// whose enums cannot have duplicate values need to generate aliases for all
+// but the first member of each group.
+func (enum *Enum) DuplicateValues() ([][]Identifier, error) {
+	members, err := enum.SortedMembersByValue()
+	if err != nil {
+		return nil, err
+	}
+	var groups [][]Identifier
+	for i := 0; i < len(members); {
+		j := i + 1
+		for j < len(members) && members[j].Number == members[i].Number {
+			j++
+		}
+		if j-i > 1 {
+			var group []Identifier
+			for _, m := range members[i:j] {
+				group = append(group, m.Name)
+			}
+			groups = append(groups, group)
+		}
+		i = j
+	}
+	return groups, nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func enumMember(name fidlgen.Identifier, value string) fidlgen.EnumMember {
+	return fidlgen.EnumMember{Name: name, Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: value}}
+}
+
+func enumDecl(subtype fidlgen.PrimitiveSubtype, members ...fidlgen.EnumMember) fidlgen.Enum {
+	return fidlgen.Enum{
+		LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/E"}},
+		Type:       subtype,
+		Members:    members,
+	}
+}
+
+func TestEnumMemberIntValue(t *testing.T) {
+	for _, tc := range []struct {
+		subtype  fidlgen.PrimitiveSubtype
+		value    string
+		expected fidlgen.EnumValue
+	}{
+		{fidlgen.Int8, "-128", fidlgen.EnumValue{Signed: true, Int: -128}},
+		{fidlgen.Int64, "-9223372036854775808", fidlgen.EnumValue{Signed: true, Int: -9223372036854775808}},
+		{fidlgen.Uint64, "18446744073709551615", fidlgen.EnumValue{Uint: 18446744073709551615}},
+		{fidlgen.Uint32, "0x10", fidlgen.EnumValue{Uint: 16}},
+	} {
+		m := enumMember("M", tc.value)
+		actual, err := m.IntValue(tc.subtype)
+		if err != nil {
+			t.Errorf("%s %s: %s", tc.subtype, tc.value, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("%s %s: got %+v, want %+v", tc.subtype, tc.value, actual, tc.expected)
+		}
+		if actual.String() != tc.expected.String() {
+			t.Errorf("%s %s: got %s", tc.subtype, tc.value, actual)
+		}
+	}
+
+	for _, tc := range []struct {
+		subtype fidlgen.PrimitiveSubtype
+		value   string
+	}{
+		{fidlgen.Uint8, "-1"},
+		{fidlgen.Uint8, "256"},
+		{fidlgen.Int8, "128"},
+		{fidlgen.Float32, "1"},
+	} {
+		m := enumMember("M", tc.value)
+		if _, err := m.IntValue(tc.subtype); err == nil {
+			t.Errorf("%s %s: expected an error", tc.subtype, tc.value)
+		}
+	}
+}
+
+func TestEnumSortedMembersByValue(t *testing.T) {
+	e := enumDecl(fidlgen.Int16, enumMember("B", "2"), enumMember("NEG", "-1"), enumMember("A", "1"), enumMember("TWO", "2"))
+	members, err := e.SortedMembersByValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []fidlgen.Identifier
+	for _, m := range members {
+		names = append(names, m.Name)
+	}
+	if diff := cmp.Diff([]fidlgen.Identifier{"NEG", "A", "B", "TWO"}, names); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	duplicates, err := e.DuplicateValues()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]fidlgen.Identifier{{"B", "TWO"}}, duplicates); diff != "" {
+		t.Errorf("duplicates: unexpected diff (-want +got):\n%s", diff)
+	}
+
+	unique := enumDecl(fidlgen.Uint64, enumMember("MAX", "18446744073709551615"), enumMember("ZERO", "0"))
+	if duplicates, err := unique.DuplicateValues(); err != nil || len(duplicates) != 0 {
+		t.Errorf("got duplicates %v, %v", duplicates, err)
+	}
+
+	overflow := enumDecl(fidlgen.Uint8, enumMember("BIG", "300"))
+	if _, err := overflow.SortedMembersByValue(); err == nil {
+		t.Errorf("expected an error for an out of range value")
+	}
+}
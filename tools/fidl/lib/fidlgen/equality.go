@@ -0,0 +1,89 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// typeKey encodes the parts of a type compared by Type.EqualIgnoringShape.
+func typeKey(t *Type) string {
+	var b strings.Builder
+	for ; t != nil; t = t.ElementType {
+		fmt.Fprintf(&b, "%s|%s|%s|%s|%s|%t|%s|%d|%d|%s|%s",
+			t.Kind, t.PrimitiveSubtype, t.Identifier, t.RequestSubtype,
+			t.InternalSubtype, t.Nullable, t.HandleSubtype, t.HandleRights,
+			t.ObjType, t.ResourceIdentifier, t.ProtocolTransport)
+		if t.ElementCount != nil {
+			fmt.Fprintf(&b, "|%d", *t.ElementCount)
+		}
+		b.WriteString(";")
+	}
+	return b.String()
+}
+
+// EqualIgnoringShape returns whether two types are the same FIDL type. The type
+// shapes are not compared, nor are Boxed and Endpoint, as they are all derived
+// from the rest of the type and the library it is part of. It is deliberately
+// not named Equal, which go-cmp would use to compare *Type values.
+func (t *Type) EqualIgnoringShape(other *Type) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	return typeKey(t) == typeKey(other)
+}
+
+// Hash returns a hash of the type that is stable across runs and consistent
+// with EqualIgnoringShape: equal types have equal hashes.
+func (t *Type) Hash() uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(typeKey(t)))
+	return h.Sum64()
+}
+
+// HashDecl returns a hash of the content of a declaration that is stable
+// across runs. It ignores the name of the declaration, its naming context and
+// source locations, so that declarations with the same layout hash the same,
+// e.g. identical anonymous payload layouts of different methods. The
+// declarations must still be compared to tell them apart from collisions.
+func HashDecl(decl Declaration) uint64 {
+	b, err := json.Marshal(decl)
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode %s: %s", decl.GetName(), err))
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		panic(fmt.Sprintf("failed to encode %s: %s", decl.GetName(), err))
+	}
+	delete(obj, "name")
+	delete(obj, "naming_context")
+	stripLocations(obj)
+	// Encoding sorts the keys of maps, making the result canonical.
+	b, err = json.Marshal(obj)
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode %s: %s", decl.GetName(), err))
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+// stripLocations removes the source locations from a decoded JSON value.
+func stripLocations(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		delete(v, "location")
+		for _, elem := range v {
+			stripLocations(elem)
+		}
+	case []interface{}:
+		for _, elem := range v {
+			stripLocations(elem)
+		}
+	}
+}
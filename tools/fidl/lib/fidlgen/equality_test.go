@@ -0,0 +1,86 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestTypeEqual(t *testing.T) {
+	count := func(n int) *int { return &n }
+	vector := func(n int, shape int) *fidlgen.Type {
+		return &fidlgen.Type{
+			Kind:         fidlgen.VectorType,
+			ElementType:  identifierType("example/S"),
+			ElementCount: count(n),
+			TypeShapeV2:  fidlgen.TypeShape{InlineSize: shape},
+		}
+	}
+	boxed := identifierType("example/S")
+	boxed.Nullable, boxed.Boxed = true, true
+	optional := identifierType("example/S")
+	optional.Nullable = true
+
+	for _, tc := range []struct {
+		name  string
+		a, b  *fidlgen.Type
+		equal bool
+	}{
+		{"nil", nil, nil, true},
+		{"nil and non-nil", nil, vector(1, 16), false},
+		{"same", vector(1, 16), vector(1, 16), true},
+		{"different shapes", vector(1, 16), vector(1, 0), true},
+		{"different counts", vector(1, 16), vector(2, 16), false},
+		{"different elements", vector(1, 16), &fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifierType("example/T"), ElementCount: count(1)}, false},
+		{"unbounded", vector(1, 16), &fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifierType("example/S")}, false},
+		{"derived fields", boxed, optional, true},
+		{"nullability", identifierType("example/S"), optional, false},
+	} {
+		if got := tc.a.EqualIgnoringShape(tc.b); got != tc.equal {
+			t.Errorf("%s: EqualIgnoringShape got %t, want %t", tc.name, got, tc.equal)
+		}
+		if got := tc.b.EqualIgnoringShape(tc.a); got != tc.equal {
+			t.Errorf("%s: EqualIgnoringShape (reversed) got %t, want %t", tc.name, got, tc.equal)
+		}
+		if tc.a != nil && tc.b != nil && (tc.a.Hash() == tc.b.Hash()) != tc.equal {
+			t.Errorf("%s: got hashes %x and %x", tc.name, tc.a.Hash(), tc.b.Hash())
+		}
+	}
+}
+
+func TestCmpComparesTypeShapes(t *testing.T) {
+	// go-cmp compares *Type fields in full, shapes included.
+	a := fidlgen.Method{RequestPayload: identifierType("example/S")}
+	b := fidlgen.Method{RequestPayload: identifierType("example/S")}
+	b.RequestPayload.TypeShapeV2.InlineSize = 8
+	if cmp.Diff(a, b) == "" {
+		t.Errorf("expected a diff between payload types of different shapes")
+	}
+}
+
+func TestHashDecl(t *testing.T) {
+	payload := func(name fidlgen.EncodedCompoundIdentifier, line int, member fidlgen.Identifier) *fidlgen.Struct {
+		s := structDecl(name, member)
+		s.Location = fidlgen.Location{Filename: "example.fidl", Line: line}
+		s.Members[0].Type = *identifierType("example/S")
+		return &s
+	}
+	foo := payload("example/ProtocolFooRequest", 10, "s")
+	bar := payload("example/ProtocolBarRequest", 20, "s")
+	baz := payload("example/ProtocolBazRequest", 30, "t")
+
+	if fidlgen.HashDecl(foo) != fidlgen.HashDecl(bar) {
+		t.Errorf("expected identical layouts to hash the same")
+	}
+	if fidlgen.HashDecl(foo) == fidlgen.HashDecl(baz) {
+		t.Errorf("expected different layouts to hash differently")
+	}
+	if fidlgen.HashDecl(foo) != fidlgen.HashDecl(payload("example/ProtocolFooRequest", 10, "s")) {
+		t.Errorf("expected hashes to be stable")
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// WithoutExperiments returns the library as it would be represented without
+// the IR features gated behind experiments, so that generators only
+// supporting stable features may consume IR produced with experiments enabled
+// deterministically. It does not modify r. Specifically:
+//
+//   - Under allow_new_types, references to new types are replaced with their
+//     underlying types, which share their wire format, and the new types
+//     themselves are dropped.
+//   - Under unknown_interactions, the openness of protocols is cleared, strict
+//     methods lose their explicit strictness, and flexible methods, which
+//     have no stable equivalent, are dropped. Their payloads are kept, but not
+//     the result unions wrapping their responses (nor the structs wrapping
+//     those), whose framework_err member only exists under the experiment.
+//
+// The list of experiments is cleared. Type aliases are left as is, even if
+// they name a new type. Only the new types of this library are resolved; use
+// Compilation.WithoutExperiments to also resolve those of its dependencies.
+func (r *Root) WithoutExperiments() Root {
+	return withoutExperiments(r, r)
+}
+
+// WithoutExperiments returns the target library as Root.WithoutExperiments
+// does, also replacing references to the new types of the dependency
+// libraries with their underlying types.
+func (c *Compilation) WithoutExperiments() Root {
+	return withoutExperiments(&c.Root, c)
+}
+
+func withoutExperiments(r *Root, decls DeclResolver) Root {
+	unwrap := func(typ Type) Type {
+		return unwrapNewTypes(typ, decls)
+	}
+
+	// The result unions of flexible methods, and the structs wrapping them as
+	// response payloads, are dropped along with the methods.
+	droppedResults := make(map[EncodedCompoundIdentifier]struct{})
+	for _, p := range r.Protocols {
+		for _, m := range p.Methods {
+			if !m.IsFlexible() || m.ResultType == nil || m.SyntheticResult {
+				continue
+			}
+			droppedResults[m.ResultType.Identifier] = struct{}{}
+			if m.ResponsePayload != nil {
+				droppedResults[m.ResponsePayload.Identifier] = struct{}{}
+			}
+		}
+	}
+
+	res := Root{
+		Name:              r.Name,
+		Libraries:         r.Libraries,
+		Decls:             make(DeclMap, len(r.Decls)),
+		HandleObjectTypes: r.HandleObjectTypes,
+	}
+
+	r.ForEachDecl(func(decl Declaration) {
+		switch v := decl.(type) {
+		case *Const:
+			newV := *v
+			newV.Type = unwrap(v.Type)
+			res.Consts = append(res.Consts, newV)
+		case *Bits:
+			newV := *v
+			newV.Type = unwrap(v.Type)
+			res.Bits = append(res.Bits, newV)
+		case *Enum:
+			res.Enums = append(res.Enums, *v)
+		case *Resource:
+			newV := *v
+			newV.Type = unwrap(v.Type)
+			newV.Properties = nil
+			for _, p := range v.Properties {
+				p.Type = unwrap(p.Type)
+				newV.Properties = append(newV.Properties, p)
+			}
+			res.Resources = append(res.Resources, newV)
+		case *Protocol:
+			newV := *v
+			newV.Openness = ""
+			newV.Methods = nil
+			for _, m := range v.Methods {
+				if m.IsFlexible() {
+					continue
+				}
+				m.MaybeStrict = nil
+				newV.Methods = append(newV.Methods, m)
+			}
+			res.Protocols = append(res.Protocols, newV)
+		case *Service:
+			res.Services = append(res.Services, *v)
+		case *Struct:
+			if _, ok := droppedResults[v.Name]; ok {
+				return
+			}
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				m.Type = unwrap(m.Type)
+				newV.Members = append(newV.Members, m)
+			}
+			if v.Name.LibraryName() == r.Name {
+				res.Structs = append(res.Structs, newV)
+			} else {
+				res.ExternalStructs = append(res.ExternalStructs, newV)
+			}
+		case *Table:
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				m.Type = unwrap(m.Type)
+				newV.Members = append(newV.Members, m)
+			}
+			res.Tables = append(res.Tables, newV)
+		case *Union:
+			if _, ok := droppedResults[v.Name]; ok {
+				return
+			}
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				m.Type = unwrap(m.Type)
+				newV.Members = append(newV.Members, m)
+			}
+			res.Unions = append(res.Unions, newV)
+		case *TypeAlias:
+			res.TypeAliases = append(res.TypeAliases, *v)
+		case *NewType:
+			return
+		}
+		if declType, ok := r.Decls[decl.GetName()]; ok {
+			res.Decls[decl.GetName()] = declType
+		}
+	})
+
+	for _, d := range r.DeclOrder {
+		if _, ok := res.Decls[d]; ok {
+			res.DeclOrder = append(res.DeclOrder, d)
+		}
+	}
+
+	return res
+}
+
+// unwrapNewTypes returns a copy of the type where references to new types,
+// including those of element types, are replaced with their underlying types,
+// looking declarations up through decls. The original type is left untouched.
+func unwrapNewTypes(typ Type, decls DeclResolver) Type {
+	for typ.Kind == IdentifierType {
+		decl, ok := decls.LookupDecl(typ.Identifier)
+		if !ok {
+			break
+		}
+		newType, ok := decl.(*NewType)
+		if !ok || newType.Name != typ.Identifier {
+			break
+		}
+		typ = newType.Type
+	}
+	if typ.ElementType != nil {
+		elem := unwrapNewTypes(*typ.ElementType, decls)
+		typ.ElementType = &elem
+	}
+	return typ
+}
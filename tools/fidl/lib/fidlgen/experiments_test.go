@@ -0,0 +1,208 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestWithoutExperiments(t *testing.T) {
+	strict, flexible := true, false
+	u32 := fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}
+	holder := structDecl("example/Holder", "id", "ids", "other")
+	holder.Members[0].Type = *identifierType("example/Id")
+	holder.Members[1].Type = fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifierType("example/WrappedId")}
+	holder.Members[2].Type = u32
+	root := fidlgen.Root{
+		Name:        "example",
+		Experiments: fidlgen.Experiments{fidlgen.ExperimentAllowNewTypes, fidlgen.ExperimentUnknownInteractions},
+		Structs:     []fidlgen.Struct{holder},
+		NewTypes: []fidlgen.NewType{
+			{Decl: fidlgen.Decl{Name: "example/Id"}, Type: u32},
+			{Decl: fidlgen.Decl{Name: "example/WrappedId"}, Type: *identifierType("example/Id")},
+		},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl:     fidlgen.Decl{Name: "example/P"},
+				Openness: fidlgen.Open,
+				Methods: []fidlgen.Method{
+					{Name: "Strict", MaybeStrict: &strict},
+					{Name: "Flexible", MaybeStrict: &flexible},
+					{Name: "Unspecified"},
+				},
+			},
+		},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{"example/Id", "example/WrappedId", "example/Holder", "example/P"},
+		Decls: fidlgen.DeclMap{
+			"example/Id":        fidlgen.NewTypeDeclType,
+			"example/WrappedId": fidlgen.NewTypeDeclType,
+			"example/Holder":    fidlgen.StructDeclType,
+			"example/P":         fidlgen.ProtocolDeclType,
+		},
+	}
+
+	expectedHolder := structDecl("example/Holder", "id", "ids", "other")
+	expectedHolder.Members[0].Type = u32
+	expectedHolder.Members[1].Type = fidlgen.Type{Kind: fidlgen.VectorType, ElementType: &u32}
+	expectedHolder.Members[2].Type = u32
+	expected := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{expectedHolder},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl: fidlgen.Decl{Name: "example/P"},
+				Methods: []fidlgen.Method{
+					{Name: "Strict"},
+					{Name: "Unspecified"},
+				},
+			},
+		},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{"example/Holder", "example/P"},
+		Decls: fidlgen.DeclMap{
+			"example/Holder": fidlgen.StructDeclType,
+			"example/P":      fidlgen.ProtocolDeclType,
+		},
+	}
+
+	before := root.Structs[0].Members[1].Type.String()
+	if diff := cmp.Diff(expected, root.WithoutExperiments()); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	if after := root.Structs[0].Members[1].Type.String(); after != before {
+		t.Errorf("original modified: got %s, want %s", after, before)
+	}
+}
+
+func TestWithoutExperimentsDropsFlexibleResults(t *testing.T) {
+	strict, flexible := true, false
+	unionDecl := func(name fidlgen.EncodedCompoundIdentifier) fidlgen.Union {
+		return fidlgen.Union{
+			ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+				LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: name}},
+			},
+		}
+	}
+	strictResultPayload := structDecl("example/P_Strict_Response", "result")
+	strictResultPayload.Members[0].Type = *identifierType("example/P_Strict_Result")
+	flexibleResultPayload := structDecl("example/P_Flexible_Response", "result")
+	flexibleResultPayload.Members[0].Type = *identifierType("example/P_Flexible_Result")
+	strictMethod := fidlgen.Method{
+		Name:            "Strict",
+		MaybeStrict:     &strict,
+		HasError:        true,
+		ResponsePayload: identifierType("example/P_Strict_Response"),
+		ResultType:      identifierType("example/P_Strict_Result"),
+		ValueType:       identifierType("example/Value"),
+	}
+	root := fidlgen.Root{
+		Name: "example",
+		Structs: []fidlgen.Struct{
+			structDecl("example/Value", "v"),
+			strictResultPayload,
+			flexibleResultPayload,
+		},
+		Unions: []fidlgen.Union{
+			unionDecl("example/P_Strict_Result"),
+			unionDecl("example/P_Flexible_Result"),
+		},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl: fidlgen.Decl{Name: "example/P"},
+				Methods: []fidlgen.Method{
+					strictMethod,
+					{
+						Name:            "Flexible",
+						MaybeStrict:     &flexible,
+						ResponsePayload: identifierType("example/P_Flexible_Response"),
+						ResultType:      identifierType("example/P_Flexible_Result"),
+						ValueType:       identifierType("example/Value"),
+					},
+				},
+			},
+		},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{
+			"example/Value",
+			"example/P_Strict_Result",
+			"example/P_Strict_Response",
+			"example/P_Flexible_Result",
+			"example/P_Flexible_Response",
+			"example/P",
+		},
+		Decls: fidlgen.DeclMap{
+			"example/Value":               fidlgen.StructDeclType,
+			"example/P_Strict_Result":     fidlgen.UnionDeclType,
+			"example/P_Strict_Response":   fidlgen.StructDeclType,
+			"example/P_Flexible_Result":   fidlgen.UnionDeclType,
+			"example/P_Flexible_Response": fidlgen.StructDeclType,
+			"example/P":                   fidlgen.ProtocolDeclType,
+		},
+	}
+
+	strictMethod.MaybeStrict = nil
+	expected := fidlgen.Root{
+		Name: "example",
+		Structs: []fidlgen.Struct{
+			structDecl("example/Value", "v"),
+			strictResultPayload,
+		},
+		Unions: []fidlgen.Union{unionDecl("example/P_Strict_Result")},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl:    fidlgen.Decl{Name: "example/P"},
+				Methods: []fidlgen.Method{strictMethod},
+			},
+		},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{
+			"example/Value",
+			"example/P_Strict_Result",
+			"example/P_Strict_Response",
+			"example/P",
+		},
+		Decls: fidlgen.DeclMap{
+			"example/Value":             fidlgen.StructDeclType,
+			"example/P_Strict_Result":   fidlgen.UnionDeclType,
+			"example/P_Strict_Response": fidlgen.StructDeclType,
+			"example/P":                 fidlgen.ProtocolDeclType,
+		},
+	}
+	if diff := cmp.Diff(expected, root.WithoutExperiments()); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestCompilationWithoutExperiments(t *testing.T) {
+	u32 := fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}
+	dep := fidlgen.Root{
+		Name: "dep",
+		NewTypes: []fidlgen.NewType{
+			{Decl: fidlgen.Decl{Name: "dep/Id"}, Type: u32},
+		},
+		Decls: fidlgen.DeclMap{"dep/Id": fidlgen.NewTypeDeclType},
+	}
+	holder := structDecl("example/Holder", "id")
+	holder.Members[0].Type = *identifierType("dep/Id")
+	root := fidlgen.Root{
+		Name:      "example",
+		Structs:   []fidlgen.Struct{holder},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{"example/Holder"},
+		Decls:     fidlgen.DeclMap{"example/Holder": fidlgen.StructDeclType},
+	}
+
+	// A Root alone cannot see the new types of its dependencies.
+	if got := root.WithoutExperiments().Structs[0].Members[0].Type; got.Identifier != "dep/Id" {
+		t.Errorf("Root: got %s, want dep/Id left as is", got)
+	}
+
+	c, err := fidlgen.NewCompilation(root, []fidlgen.Root{dep})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(u32, c.WithoutExperiments().Structs[0].Members[0].Type); diff != "" {
+		t.Errorf("Compilation: unexpected diff (-want +got):\n%s", diff)
+	}
+}
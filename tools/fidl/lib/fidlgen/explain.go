@@ -0,0 +1,384 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Explanation gathers everything fidlgen knows about a declaration, as a
+// debugging aid for backends whose templates misbehave.
+type Explanation struct {
+	Name     EncodedCompoundIdentifier
+	Kind     DeclType
+	Location Location
+	// Attributes are the attributes of the declaration, doc comments
+	// included.
+	Attributes []Attribute
+	// Availability is the parsed @available attribute, if any.
+	Availability Availability
+	// NamingContext is set for layouts.
+	NamingContext NamingContext
+	// TypeShapeV1 and TypeShapeV2 are the shapes of structs, tables and
+	// unions in the V1 and V2 wire formats.
+	TypeShapeV1, TypeShapeV2 *TypeShape
+	// Type is the underlying type of constants, bits, resources and new
+	// types.
+	Type *TypeNode
+	// Members are the members of layouts and services, and the methods of
+	// protocols.
+	Members []ExplainedMember
+	// Dependencies are the declarations the declaration directly references,
+	// including through nullable types and protocol endpoints, sorted.
+	Dependencies []EncodedCompoundIdentifier
+	// Dependents are the declarations of the program that directly reference
+	// the declaration, sorted.
+	Dependents []EncodedCompoundIdentifier
+	// MethodTypeUsage is set if the declaration is referenced by the methods
+	// of its library.
+	MethodTypeUsage MethodTypeUsage
+	// TypeClass is set for the bits, enums, structs, tables and unions.
+	TypeClass TypeClass
+}
+
+// ExplainedMember is a member of an explained declaration.
+type ExplainedMember struct {
+	Name       Identifier
+	Attributes []Attribute
+	// Ordinal is set for table and union members, and methods.
+	Ordinal uint64
+	// Value is the value of bits and enum members.
+	Value string
+	// Type is the type of struct, table, union and service members.
+	Type *TypeNode
+	// FieldShapeV1 and FieldShapeV2 are set for struct members.
+	FieldShapeV1, FieldShapeV2 *FieldShape
+	// Request and Response are the payloads of methods, if any.
+	Request, Response *TypeNode
+}
+
+// TypeNode is a node of a resolved type tree, which is a type along with its
+// element types.
+type TypeNode struct {
+	Kind TypeKind
+	// Name is the primitive, handle or internal subtype, or the name of the
+	// referenced declaration or protocol.
+	Name string
+	// DeclType is the kind of declaration referenced by an identifier type,
+	// if known.
+	DeclType     DeclType
+	Nullable     bool
+	ElementCount *int
+	TypeShapeV1  TypeShape
+	TypeShapeV2  TypeShape
+	Element      *TypeNode
+}
+
+// Explain explains a declaration of the program. The type class is given for
+// the bindings of the given language, which may be empty to disregard
+// @bindings_denylist.
+func (p *Program) Explain(name EncodedCompoundIdentifier, language string) (Explanation, error) {
+	if name.DeclName() != name {
+		return Explanation{}, fmt.Errorf("%s is a member, not a declaration", name)
+	}
+	decl, ok := p.LookupDecl(name)
+	if !ok {
+		return Explanation{}, fmt.Errorf("declaration %s not found", name)
+	}
+	root, ok := p.Libraries[name.LibraryName()]
+	if !ok {
+		return Explanation{}, fmt.Errorf("library %s not found", name.LibraryName())
+	}
+	availability, err := decl.GetAttributes().Availability()
+	if err != nil {
+		return Explanation{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	e := Explanation{
+		Name:            name,
+		Kind:            GetDeclType(decl),
+		Location:        decl.GetLocation(),
+		Attributes:      decl.GetAttributes().Attributes,
+		Availability:    availability,
+		MethodTypeUsage: root.MethodTypeUsageMap()[name],
+		TypeClass:       root.TypeClasses(language)[name],
+	}
+	if layout, ok := decl.(LayoutDeclaration); ok {
+		e.NamingContext = layout.GetNamingContext()
+	}
+	node := func(typ *Type) *TypeNode {
+		return p.typeNode(root, typ)
+	}
+
+	switch decl := decl.(type) {
+	case *Const:
+		e.Type = node(&decl.Type)
+	case *Bits:
+		e.Type = node(&decl.Type)
+	case *Resource:
+		e.Type = node(&decl.Type)
+	case *NewType:
+		e.Type = node(&decl.Type)
+	case *Struct:
+		e.TypeShapeV1, e.TypeShapeV2 = &decl.TypeShapeV1, &decl.TypeShapeV2
+	case *Table:
+		e.TypeShapeV1, e.TypeShapeV2 = &decl.TypeShapeV1, &decl.TypeShapeV2
+	case *Union:
+		e.TypeShapeV1, e.TypeShapeV2 = &decl.TypeShapeV1, &decl.TypeShapeV2
+	case *Protocol:
+		for _, m := range decl.Methods {
+			e.Members = append(e.Members, ExplainedMember{
+				Name:       m.Name,
+				Attributes: m.Attributes.Attributes,
+				Ordinal:    m.Ordinal,
+				Request:    node(m.RequestPayload),
+				Response:   node(m.ResponsePayload),
+			})
+		}
+	case *Service:
+		for i, m := range decl.Members {
+			e.Members = append(e.Members, ExplainedMember{
+				Name:       m.Name,
+				Attributes: m.Attributes.Attributes,
+				Type:       node(&decl.Members[i].Type),
+			})
+		}
+	}
+	if d, ok := decl.(DeclarationWithMembers); ok {
+		d.ForEachMember(func(m MemberDeclaration) {
+			member := ExplainedMember{
+				Name:       m.GetName(),
+				Attributes: m.GetAttributes().Attributes,
+				Type:       node(m.GetType()),
+			}
+			if ordinal, ok := m.GetOrdinal(); ok {
+				member.Ordinal = uint64(ordinal)
+			}
+			switch m := m.(type) {
+			case *StructMember:
+				member.FieldShapeV1, member.FieldShapeV2 = &m.FieldShapeV1, &m.FieldShapeV2
+			case *BitsMember:
+				member.Value = m.Value.Value
+			case *EnumMember:
+				member.Value = m.Value.Value
+			}
+			e.Members = append(e.Members, member)
+		})
+	}
+
+	var refs declReferences
+	refs.addDecl(decl)
+	e.Dependencies = sortedUniqueNames(refs, name)
+	var dependents []EncodedCompoundIdentifier
+	p.ForEachLibrary(func(r *Root) {
+		r.ForEachDecl(func(other Declaration) {
+			if other.GetName().LibraryName() != r.Name {
+				return
+			}
+			var refs declReferences
+			refs.addDecl(other)
+			for _, ref := range refs {
+				if ref == name {
+					dependents = append(dependents, other.GetName())
+					return
+				}
+			}
+		})
+	})
+	e.Dependents = sortedUniqueNames(dependents, name)
+	return e, nil
+}
+
+// sortedUniqueNames sorts names and removes duplicates along with the given
+// name.
+func sortedUniqueNames(names []EncodedCompoundIdentifier, exclude EncodedCompoundIdentifier) []EncodedCompoundIdentifier {
+	set := make(map[EncodedCompoundIdentifier]struct{})
+	for _, n := range names {
+		if n != exclude {
+			set[n] = struct{}{}
+		}
+	}
+	var res []EncodedCompoundIdentifier
+	for n := range set {
+		res = append(res, n)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+// typeNode resolves a type into a tree, looking up the kinds of referenced
+// declarations in the program, or else in the DeclInfo of the library.
+func (p *Program) typeNode(root *Root, typ *Type) *TypeNode {
+	if typ == nil {
+		return nil
+	}
+	n := &TypeNode{
+		Kind:         typ.Kind,
+		Nullable:     typ.Nullable,
+		ElementCount: typ.ElementCount,
+		TypeShapeV1:  typ.TypeShapeV1,
+		TypeShapeV2:  typ.TypeShapeV2,
+		Element:      p.typeNode(root, typ.ElementType),
+	}
+	switch typ.Kind {
+	case PrimitiveType:
+		n.Name = string(typ.PrimitiveSubtype)
+	case HandleType:
+		n.Name = string(typ.HandleSubtype)
+	case InternalType:
+		n.Name = string(typ.InternalSubtype)
+	case RequestType:
+		n.Name = string(typ.RequestSubtype)
+	case IdentifierType:
+		n.Name = string(typ.Identifier)
+		if decl, ok := p.LookupDecl(typ.Identifier); ok {
+			n.DeclType = GetDeclType(decl)
+		} else {
+			n.DeclType = root.Decls[typ.Identifier]
+		}
+	}
+	return n
+}
+
+// String pretty-prints the explanation.
+func (e Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", e.Kind, e.Name)
+	fmt.Fprintf(&b, "  location: %s:%d:%d\n", e.Location.Filename, e.Location.Line, e.Location.Column)
+	if len(e.NamingContext) > 0 {
+		fmt.Fprintf(&b, "  naming context: %s\n", strings.Join(e.NamingContext, "."))
+	}
+	if a := formatAvailability(e.Availability); a != "" {
+		fmt.Fprintf(&b, "  availability: %s\n", a)
+	}
+	writeAttributes(&b, "  ", e.Attributes)
+	if e.TypeShapeV1 != nil {
+		fmt.Fprintf(&b, "  shape (v1): %s\n", formatTypeShape(*e.TypeShapeV1))
+	}
+	if e.TypeShapeV2 != nil {
+		fmt.Fprintf(&b, "  shape (v2): %s\n", formatTypeShape(*e.TypeShapeV2))
+	}
+	if e.Type != nil {
+		b.WriteString("  type:\n")
+		e.Type.write(&b, "    ")
+	}
+	if len(e.Members) > 0 {
+		b.WriteString("  members:\n")
+		for _, m := range e.Members {
+			m.write(&b, "    ")
+		}
+	}
+	writeNames(&b, "depends on", e.Dependencies)
+	writeNames(&b, "depended on by", e.Dependents)
+	if e.MethodTypeUsage != "" {
+		fmt.Fprintf(&b, "  method type usage: %s\n", e.MethodTypeUsage)
+	}
+	if e.TypeClass != "" {
+		fmt.Fprintf(&b, "  type class: %s\n", e.TypeClass)
+	}
+	return b.String()
+}
+
+func (m ExplainedMember) write(b *strings.Builder, indent string) {
+	b.WriteString(indent + string(m.Name))
+	if m.Ordinal != 0 {
+		fmt.Fprintf(b, " (ordinal %d)", m.Ordinal)
+	}
+	if m.Value != "" {
+		fmt.Fprintf(b, " = %s", m.Value)
+	}
+	b.WriteString("\n")
+	writeAttributes(b, indent+"  ", m.Attributes)
+	if m.FieldShapeV1 != nil && m.FieldShapeV2 != nil {
+		fmt.Fprintf(b, "%s  field shape: v1 offset=%d padding=%d, v2 offset=%d padding=%d\n", indent,
+			m.FieldShapeV1.Offset, m.FieldShapeV1.Padding, m.FieldShapeV2.Offset, m.FieldShapeV2.Padding)
+	}
+	if m.Type != nil {
+		m.Type.write(b, indent+"  ")
+	}
+	if m.Request != nil {
+		b.WriteString(indent + "  request:\n")
+		m.Request.write(b, indent+"    ")
+	}
+	if m.Response != nil {
+		b.WriteString(indent + "  response:\n")
+		m.Response.write(b, indent+"    ")
+	}
+}
+
+func (n *TypeNode) write(b *strings.Builder, indent string) {
+	b.WriteString(indent + string(n.Kind))
+	if n.Name != "" {
+		b.WriteString(" " + n.Name)
+	}
+	var qualifiers []string
+	if n.DeclType != "" {
+		qualifiers = append(qualifiers, string(n.DeclType))
+	}
+	if n.ElementCount != nil {
+		qualifiers = append(qualifiers, fmt.Sprintf("count=%d", *n.ElementCount))
+	}
+	if n.Nullable {
+		qualifiers = append(qualifiers, "nullable")
+	}
+	if len(qualifiers) > 0 {
+		fmt.Fprintf(b, " (%s)", strings.Join(qualifiers, ", "))
+	}
+	b.WriteString("\n")
+	fmt.Fprintf(b, "%s  shape (v1): %s\n", indent, formatTypeShape(n.TypeShapeV1))
+	fmt.Fprintf(b, "%s  shape (v2): %s\n", indent, formatTypeShape(n.TypeShapeV2))
+	if n.Element != nil {
+		n.Element.write(b, indent+"  ")
+	}
+}
+
+func writeAttributes(b *strings.Builder, indent string, attrs []Attribute) {
+	for _, attr := range attrs {
+		var args []string
+		for _, arg := range attr.Args {
+			args = append(args, fmt.Sprintf("%s=%q", arg.Name, arg.ValueString()))
+		}
+		fmt.Fprintf(b, "%s@%s(%s)\n", indent, attr.Name, strings.Join(args, ", "))
+	}
+}
+
+func writeNames(b *strings.Builder, title string, names []EncodedCompoundIdentifier) {
+	if len(names) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "  %s:\n", title)
+	for _, name := range names {
+		fmt.Fprintf(b, "    %s\n", name)
+	}
+}
+
+func formatTypeShape(s TypeShape) string {
+	return fmt.Sprintf("inline_size=%d alignment=%d depth=%d max_handles=%d max_out_of_line=%d has_padding=%t has_envelope=%t has_flexible_envelope=%t",
+		s.InlineSize, s.Alignment, s.Depth, s.MaxHandles, s.MaxOutOfLine, s.HasPadding, s.HasEnvelope, s.HasFlexibleEnvelope)
+}
+
+func formatAvailability(a Availability) string {
+	var parts []string
+	if a.Platform != "" {
+		parts = append(parts, "platform="+a.Platform)
+	}
+	for _, v := range []struct {
+		name    string
+		version Version
+	}{{"added", a.Added}, {"deprecated", a.Deprecated}, {"removed", a.Removed}} {
+		if v.version != 0 {
+			parts = append(parts, fmt.Sprintf("%s=%s", v.name, v.version))
+		}
+	}
+	if a.Legacy {
+		parts = append(parts, "legacy=true")
+	}
+	if a.Note != "" {
+		parts = append(parts, fmt.Sprintf("note=%q", a.Note))
+	}
+	return strings.Join(parts, " ")
+}
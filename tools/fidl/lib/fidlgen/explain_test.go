@@ -0,0 +1,91 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestExplain(t *testing.T) {
+	pixel := structDecl("a/Pixel", "color")
+	pixel.Attributes = available("added", "2")
+	pixel.TypeShapeV2 = fidlgen.TypeShape{InlineSize: 4, Alignment: 4}
+	pixel.Members[0].Type = *identifierType("a/Color")
+	pixel.Members[0].FieldShapeV2 = fidlgen.FieldShape{Offset: 0, Padding: 3}
+	paintRequest := structDecl("a/PainterPaintRequest", "pixels")
+	paintRequest.Members[0].Type = fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifierType("a/Pixel")}
+	a := fidlgen.Root{
+		Name: "a",
+		Enums: []fidlgen.Enum{
+			{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "a/Color"}}},
+		},
+		Structs: []fidlgen.Struct{pixel, paintRequest},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl: fidlgen.Decl{Name: "a/Painter"},
+				Methods: []fidlgen.Method{
+					{Name: "Paint", Ordinal: 1, HasRequest: true, RequestPayload: identifierType("a/PainterPaintRequest")},
+				},
+			},
+		},
+	}
+	b := fidlgen.Root{
+		Name:            "b",
+		Structs:         []fidlgen.Struct{structDecl("b/Canvas", "pixel")},
+		ExternalStructs: []fidlgen.Struct{pixel},
+	}
+	b.Structs[0].Members[0].Type = *identifierType("a/Pixel")
+	p, err := fidlgen.NewProgram([]fidlgen.Root{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := p.Explain("a/Pixel", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]fidlgen.EncodedCompoundIdentifier{"a/Color"}, e.Dependencies); diff != "" {
+		t.Errorf("Dependencies: unexpected diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]fidlgen.EncodedCompoundIdentifier{"a/PainterPaintRequest", "b/Canvas"}, e.Dependents); diff != "" {
+		t.Errorf("Dependents: unexpected diff (-want +got):\n%s", diff)
+	}
+	if e.Availability.Added != 2 || e.TypeClass != fidlgen.ValueTypeClass || e.TypeShapeV2.InlineSize != 4 {
+		t.Errorf("got availability %+v, type class %q and shape %+v", e.Availability, e.TypeClass, e.TypeShapeV2)
+	}
+	if len(e.Members) != 1 || e.Members[0].Type.DeclType != fidlgen.EnumDeclType || e.Members[0].FieldShapeV2.Padding != 3 {
+		t.Errorf("got members %+v", e.Members)
+	}
+	for _, line := range []string{
+		"struct a/Pixel\n",
+		"  naming context: Pixel\n",
+		"  availability: added=2\n",
+		"  shape (v2): inline_size=4 alignment=4 ",
+		"    color\n      field shape: v1 offset=0 padding=0, v2 offset=0 padding=3\n      identifier a/Color (enum)\n",
+		"  depended on by:\n    a/PainterPaintRequest\n    b/Canvas\n",
+	} {
+		if !strings.Contains(e.String(), line) {
+			t.Errorf("expected %q in:\n%s", line, e.String())
+		}
+	}
+
+	e, err = p.Explain("a/Painter", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Members) != 1 || e.Members[0].Request.Name != "a/PainterPaintRequest" || e.Members[0].Response != nil {
+		t.Errorf("got methods %+v", e.Members)
+	}
+
+	for _, name := range []fidlgen.EncodedCompoundIdentifier{"a/Missing", "a/Pixel.color"} {
+		if _, err := p.Explain(name, ""); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
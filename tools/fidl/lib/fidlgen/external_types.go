@@ -0,0 +1,107 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// An external type mapping substitutes a pre-existing, hand-written type of
+// the target language for the bindings of a FIDL declaration, e.g. an
+// existing geometry struct for fuchsia.math/Vec. Backends honoring mappings
+// emit conversions between the wire representation and the external type
+// instead of a definition. Mappings are given to a backend as a JSON file of
+// the form
+//
+//	[
+//	  {
+//	    "declaration": "fuchsia.math/Vec",
+//	    "type": "::geometry::Vec",
+//	    "includes": ["lib/geometry/vec.h"]
+//	  }
+//	]
+
+// ExternalTypeMapping maps a FIDL declaration to a type of the target
+// language.
+type ExternalTypeMapping struct {
+	// Decl is the name of the mapped declaration.
+	Decl EncodedCompoundIdentifier `json:"declaration"`
+	// Type is the fully-qualified name of the external type, in the syntax of
+	// the target language.
+	Type string `json:"type"`
+	// Includes lists what the generated code needs to include or import to
+	// use the external type (e.g. headers, modules, or packages).
+	Includes []string `json:"includes,omitempty"`
+}
+
+// ReadExternalTypeMappings reads external type mappings from a JSON file.
+func ReadExternalTypeMappings(filename string) ([]ExternalTypeMapping, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading from %s: %w", filename, err)
+	}
+	defer f.Close()
+	return DecodeExternalTypeMappings(f)
+}
+
+// DecodeExternalTypeMappings reads external type mappings from a reader.
+func DecodeExternalTypeMappings(r io.Reader) ([]ExternalTypeMapping, error) {
+	var mappings []ExternalTypeMapping
+	if err := json.NewDecoder(r).Decode(&mappings); err != nil {
+		return nil, fmt.Errorf("Error parsing external type mappings: %w", err)
+	}
+	return mappings, nil
+}
+
+// ExternalTypes holds a validated set of external type mappings.
+type ExternalTypes struct {
+	mappings map[EncodedCompoundIdentifier]ExternalTypeMapping
+}
+
+// ResolveExternalTypes validates external type mappings against the
+// declarations that decls can look up: each mapping must name an existing
+// bits, enum, struct, table, union, or new type declaration, at most once,
+// and must give an external type.
+func ResolveExternalTypes(mappings []ExternalTypeMapping, decls DeclResolver) (*ExternalTypes, error) {
+	e := &ExternalTypes{mappings: make(map[EncodedCompoundIdentifier]ExternalTypeMapping)}
+	for _, m := range mappings {
+		if m.Type == "" {
+			return nil, fmt.Errorf("external type mapping for %s gives no type", m.Decl)
+		}
+		if _, ok := e.mappings[m.Decl]; ok {
+			return nil, fmt.Errorf("%s is mapped to an external type more than once", m.Decl)
+		}
+		decl, ok := decls.LookupDecl(m.Decl)
+		if !ok || decl.GetName() != m.Decl {
+			return nil, fmt.Errorf("external type mapping for unknown declaration %s", m.Decl)
+		}
+		switch decl.(type) {
+		case *Bits, *Enum, *Struct, *Table, *Union, *NewType:
+		default:
+			return nil, fmt.Errorf("%s is a %s, which cannot be mapped to an external type", m.Decl, GetDeclType(decl))
+		}
+		e.mappings[m.Decl] = m
+	}
+	return e, nil
+}
+
+// Lookup returns the external type mapping for a declaration, if any. It may
+// be called on a nil *ExternalTypes, which holds no mappings.
+func (e *ExternalTypes) Lookup(name EncodedCompoundIdentifier) (ExternalTypeMapping, bool) {
+	if e == nil {
+		return ExternalTypeMapping{}, false
+	}
+	m, ok := e.mappings[name]
+	return m, ok
+}
+
+// IsExternal returns whether a declaration is mapped to an external type.
+func (e *ExternalTypes) IsExternal(name EncodedCompoundIdentifier) bool {
+	_, ok := e.Lookup(name)
+	return ok
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+var externalTypesLibrary = fidlgen.Root{
+	Name:      "fuchsia.math",
+	Consts:    []fidlgen.Const{{Decl: fidlgen.Decl{Name: "fuchsia.math/MAX"}}},
+	Structs:   []fidlgen.Struct{structDecl("fuchsia.math/Vec", "x", "y")},
+	Protocols: []fidlgen.Protocol{{Decl: fidlgen.Decl{Name: "fuchsia.math/Calculator"}}},
+}
+
+func TestResolveExternalTypes(t *testing.T) {
+	mappings, err := fidlgen.DecodeExternalTypeMappings(strings.NewReader(`[
+		{
+			"declaration": "fuchsia.math/Vec",
+			"type": "::geometry::Vec",
+			"includes": ["lib/geometry/vec.h"]
+		}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	external, err := fidlgen.ResolveExternalTypes(mappings, &externalTypesLibrary)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := external.Lookup("fuchsia.math/Vec")
+	if !ok {
+		t.Fatal("fuchsia.math/Vec is not mapped")
+	}
+	expected := fidlgen.ExternalTypeMapping{
+		Decl:     "fuchsia.math/Vec",
+		Type:     "::geometry::Vec",
+		Includes: []string{"lib/geometry/vec.h"},
+	}
+	if diff := cmp.Diff(expected, m); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	if external.IsExternal("fuchsia.math/MAX") {
+		t.Errorf("fuchsia.math/MAX is unexpectedly mapped")
+	}
+
+	var none *fidlgen.ExternalTypes
+	if none.IsExternal("fuchsia.math/Vec") {
+		t.Errorf("nil ExternalTypes unexpectedly maps fuchsia.math/Vec")
+	}
+}
+
+func TestResolveExternalTypesErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		mappings []fidlgen.ExternalTypeMapping
+	}{
+		{
+			name:     "unknown declaration",
+			mappings: []fidlgen.ExternalTypeMapping{{Decl: "fuchsia.math/Missing", Type: "Missing"}},
+		},
+		{
+			name:     "member",
+			mappings: []fidlgen.ExternalTypeMapping{{Decl: "fuchsia.math/Vec.x", Type: "X"}},
+		},
+		{
+			name:     "constant",
+			mappings: []fidlgen.ExternalTypeMapping{{Decl: "fuchsia.math/MAX", Type: "Max"}},
+		},
+		{
+			name:     "protocol",
+			mappings: []fidlgen.ExternalTypeMapping{{Decl: "fuchsia.math/Calculator", Type: "Calculator"}},
+		},
+		{
+			name:     "no type",
+			mappings: []fidlgen.ExternalTypeMapping{{Decl: "fuchsia.math/Vec"}},
+		},
+		{
+			name: "duplicate",
+			mappings: []fidlgen.ExternalTypeMapping{
+				{Decl: "fuchsia.math/Vec", Type: "A"},
+				{Decl: "fuchsia.math/Vec", Type: "B"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := fidlgen.ResolveExternalTypes(tc.mappings, &externalTypesLibrary); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// fidlSyntaxName renders a name as it is referred to in FIDL source, e.g.
+// `fuchsia.mem.Buffer` for `fuchsia.mem/Buffer`.
+func fidlSyntaxName(name EncodedCompoundIdentifier) string {
+	return strings.Replace(string(name), "/", ".", 1)
+}
+
+// fidlSyntaxRights names the rights of the zx library making up the given
+// handle rights, e.g. `zx.Rights.READ | zx.Rights.WRITE`.
+func fidlSyntaxRights(rights HandleRights) string {
+	var names []string
+	for right := HandleRights(1); right != 0; right <<= 1 {
+		if rights&right == 0 {
+			continue
+		}
+		if name, ok := handleRightsNames[right]; ok {
+			names = append(names, "zx.Rights."+name)
+		} else {
+			names = append(names, fmt.Sprintf("%#x", uint32(right)))
+		}
+	}
+	if len(names) == 0 {
+		return "zx.Rights.NONE"
+	}
+	return strings.Join(names, " | ")
+}
+
+// String renders the type in FIDL source syntax, e.g. `vector<uint8>:16`,
+// `box<fuchsia.mem.Buffer>` or `client_end:<fuchsia.io.Node, optional>`,
+// formatted the way `fidl-format` would, for use in error messages,
+// generated comments and the like. Names are fully qualified.
+//
+// Client ends are only told apart from other identifier types through
+// Type.Endpoint, which DecodeJSONIr sets; on IR constructed by other means,
+// see Root.MarkEndpointTypes.
+func (t Type) String() string {
+	var layout string
+	var params, constraints []string
+	switch {
+	case t.Endpoint != nil:
+		layout = string(t.Endpoint.Role)
+		constraints = append(constraints, fidlSyntaxName(t.Endpoint.Protocol))
+	case t.Kind == PrimitiveType:
+		layout = string(t.PrimitiveSubtype)
+	case t.Kind == StringType:
+		layout = "string"
+	case t.Kind == ArrayType, t.Kind == VectorType:
+		layout = string(t.Kind)
+		if t.ElementType != nil {
+			params = append(params, t.ElementType.String())
+		}
+	case t.Kind == HandleType:
+		layout = "zx.Handle"
+		if t.ResourceIdentifier != "" {
+			layout = fidlSyntaxName(EncodedCompoundIdentifier(t.ResourceIdentifier))
+		}
+		hasRights := t.HandleRights != HandleRightsSameRights
+		if t.HandleSubtype != HandleSubtypeNone {
+			constraints = append(constraints, strings.ToUpper(string(t.HandleSubtype)))
+		} else if hasRights {
+			// Rights can only be given along with an object type.
+			constraints = append(constraints, "NONE")
+		}
+		if hasRights {
+			constraints = append(constraints, fidlSyntaxRights(t.HandleRights))
+		}
+	case t.Kind == RequestType:
+		layout = string(ServerEndpoint)
+		constraints = append(constraints, fidlSyntaxName(t.RequestSubtype))
+	case t.Kind == IdentifierType:
+		layout = fidlSyntaxName(t.Identifier)
+	case t.Kind == InternalType:
+		layout = string(t.InternalSubtype)
+	default:
+		layout = string(t.Kind)
+	}
+
+	if t.ElementCount != nil {
+		if t.Kind == ArrayType {
+			params = append(params, fmt.Sprint(*t.ElementCount))
+		} else {
+			constraints = append(constraints, fmt.Sprint(*t.ElementCount))
+		}
+	}
+	if t.Nullable {
+		if t.Boxed {
+			params = []string{layout}
+			layout = "box"
+		} else {
+			constraints = append(constraints, "optional")
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(layout)
+	if len(params) > 0 {
+		fmt.Fprintf(&b, "<%s>", strings.Join(params, ", "))
+	}
+	switch len(constraints) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&b, ":%s", constraints[0])
+	default:
+		fmt.Fprintf(&b, ":<%s>", strings.Join(constraints, ", "))
+	}
+	return b.String()
+}
+
+// String renders the constant in FIDL source syntax, i.e. as the expression
+// it was declared with, e.g. `Flags.READ | Flags.WRITE` or `"hello"`, falling
+// back to its resolved value.
+func (c Constant) String() string {
+	switch {
+	case c.Expression != "":
+		return c.Expression
+	case c.Kind == IdentifierConstant:
+		return fidlSyntaxName(c.Identifier)
+	case c.Kind == LiteralConstant && c.Literal.Value != "":
+		return c.Literal.Value
+	}
+	return c.Value
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"fmt"
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestTypeString(t *testing.T) {
+	count := func(n int) *int { return &n }
+	u8 := &fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint8}
+	for _, tc := range []struct {
+		typ      fidlgen.Type
+		expected string
+	}{
+		{
+			typ:      *u8,
+			expected: "uint8",
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.StringType, ElementCount: count(32), Nullable: true},
+			expected: "string:<32, optional>",
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.VectorType, ElementType: u8, ElementCount: count(16)},
+			expected: "vector<uint8>:16",
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.VectorType, ElementType: u8, Nullable: true},
+			expected: "vector<uint8>:optional",
+		},
+		{
+			typ: fidlgen.Type{
+				Kind:         fidlgen.ArrayType,
+				ElementType:  &fidlgen.Type{Kind: fidlgen.VectorType, ElementType: u8},
+				ElementCount: count(4),
+			},
+			expected: "array<vector<uint8>, 4>",
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.HandleType, HandleSubtype: fidlgen.HandleSubtypeNone, HandleRights: fidlgen.HandleRightsSameRights},
+			expected: "zx.Handle",
+		},
+		{
+			typ: fidlgen.Type{
+				Kind:               fidlgen.HandleType,
+				HandleSubtype:      fidlgen.HandleSubtypeVmo,
+				HandleRights:       fidlgen.HandleRightsRead | fidlgen.HandleRightsMap,
+				ResourceIdentifier: "zx/Handle",
+				Nullable:           true,
+			},
+			expected: "zx.Handle:<VMO, zx.Rights.READ | zx.Rights.MAP, optional>",
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "fuchsia.mem/Buffer", Nullable: true, Boxed: true},
+			expected: "box<fuchsia.mem.Buffer>",
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/Union", Nullable: true},
+			expected: "example.Union:optional",
+		},
+		{
+			typ: fidlgen.Type{
+				Kind:       fidlgen.IdentifierType,
+				Identifier: "fuchsia.io/Node",
+				Nullable:   true,
+				Endpoint:   &fidlgen.Endpoint{Role: fidlgen.ClientEndpoint, Protocol: "fuchsia.io/Node"},
+			},
+			expected: "client_end:<fuchsia.io.Node, optional>",
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.RequestType, RequestSubtype: "fuchsia.io/Node"},
+			expected: "server_end:fuchsia.io.Node",
+		},
+	} {
+		if got := tc.typ.String(); got != tc.expected {
+			t.Errorf("got %s, want %s", got, tc.expected)
+		}
+		// Types are also printed as such by fmt.
+		if got := fmt.Sprintf("%v", &tc.typ); got != tc.expected {
+			t.Errorf("fmt: got %s, want %s", got, tc.expected)
+		}
+	}
+}
+
+func TestConstantString(t *testing.T) {
+	for _, tc := range []struct {
+		constant fidlgen.Constant
+		expected string
+	}{
+		{
+			constant: fidlgen.Constant{Kind: fidlgen.BinaryOperator, Expression: "Flags.READ | Flags.WRITE", Value: "3"},
+			expected: "Flags.READ | Flags.WRITE",
+		},
+		{
+			constant: fidlgen.Constant{Kind: fidlgen.IdentifierConstant, Identifier: "example/Flags.READ", Value: "1"},
+			expected: "example.Flags.READ",
+		},
+		{
+			constant: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Literal: fidlgen.Literal{Kind: fidlgen.StringLiteral, Value: `"hello"`}, Value: "hello"},
+			expected: `"hello"`,
+		},
+		{
+			constant: fidlgen.Constant{Value: "42"},
+			expected: "42",
+		},
+	} {
+		if got := tc.constant.String(); got != tc.expected {
+			t.Errorf("got %s, want %s", got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Flexible enums and bits accept values their members do not name. Backends
+// represent unknown enum values with the member annotated @unknown if there is
+// one, or else with the value fidlc reserved for them, i.e. the maximum of the
+// underlying type; they represent unknown bits by masking out the known ones.
+// The helpers below compute those representations, and check that they do not
+// collide with declared members: fidlc rules this out, but hand-built IR may
+// not.
+
+// EnumUnknown is the representation of the unknown values of a flexible enum.
+type EnumUnknown struct {
+	// Member is the member annotated @unknown, or nil if there is none.
+	Member *EnumMember
+	// Value is the value unknown values are represented with: that of
+	// Member, or else the value reserved by fidlc. Its signedness is that of
+	// the underlying type of the enum.
+	Value EnumValue
+}
+
+// UnknownRepresentation returns the representation of the unknown values of
+// the enum. It fails if the enum is strict, if several members are annotated
+// @unknown, or if a member not annotated @unknown has the unknown value.
+func (enum *Enum) UnknownRepresentation() (EnumUnknown, error) {
+	if enum.IsStrict() {
+		return EnumUnknown{}, fmt.Errorf("%s: strict enums have no unknown values", enum.Name)
+	}
+	var res EnumUnknown
+	for i := range enum.Members {
+		m := &enum.Members[i]
+		if !m.IsUnknown() {
+			continue
+		}
+		if res.Member != nil {
+			return EnumUnknown{}, fmt.Errorf("%s: both %s and %s are annotated @unknown", enum.Name, res.Member.Name, m.Name)
+		}
+		v, err := m.IntValue(enum.Type)
+		if err != nil {
+			return EnumUnknown{}, fmt.Errorf("%s.%w", enum.Name, err)
+		}
+		res.Member, res.Value = m, v
+	}
+	if res.Member == nil {
+		v, err := enum.reservedUnknownValue()
+		if err != nil {
+			return EnumUnknown{}, err
+		}
+		res.Value = v
+	}
+	members, err := enum.MemberValues()
+	if err != nil {
+		return EnumUnknown{}, err
+	}
+	for _, m := range members {
+		if m.Number == res.Value && !m.IsUnknown() {
+			return EnumUnknown{}, fmt.Errorf("%s.%s: value %s is reserved for unknown values", enum.Name, m.Name, m.Number)
+		}
+	}
+	return res, nil
+}
+
+// reservedUnknownValue returns the value fidlc reserves for unknown values of
+// a flexible enum without an @unknown member, as given by the IR, or else the
+// maximum of the underlying type.
+func (enum *Enum) reservedUnknownValue() (EnumValue, error) {
+	width, ok := integerSubtypeBitWidths[enum.Type]
+	if !ok {
+		return EnumValue{}, fmt.Errorf("%s: underlying type %s is not an integer", enum.Name, enum.Type)
+	}
+	if enum.Type.IsSigned() {
+		if v := enum.RawUnknownValue.readInt64(); v != 0 {
+			return EnumValue{Signed: true, Int: v}, nil
+		}
+		return EnumValue{Signed: true, Int: int64(uint64(1)<<(width-1) - 1)}, nil
+	}
+	if v := enum.RawUnknownValue.readUint64(); v != 0 {
+		return EnumValue{Uint: v}, nil
+	}
+	return EnumValue{Uint: ^uint64(0) >> (64 - width)}, nil
+}
+
+// UnknownMask returns the bits of the underlying type which are unknown to a
+// flexible bits declaration, i.e. those not set in its mask. Backends mask
+// values with it to extract their unknown bits. It fails if the bits
+// declaration is strict, or if a member sets bits outside of the mask.
+func (b *Bits) UnknownMask() (uint64, error) {
+	if b.IsStrict() {
+		return 0, fmt.Errorf("%s: strict bits have no unknown values", b.Name)
+	}
+	mask, err := b.Mask64()
+	if err != nil {
+		return 0, err
+	}
+	layout, err := b.Layout()
+	if err != nil {
+		return 0, err
+	}
+	if outside := layout.Covered &^ mask; outside != 0 {
+		return 0, fmt.Errorf("%s: members set bit %d, which is not in the mask", b.Name, bits.TrailingZeros64(outside))
+	}
+	return (layout.Free | layout.Covered) &^ mask, nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func unknownEnumMember(name fidlgen.Identifier, value string) fidlgen.EnumMember {
+	m := enumMember(name, value)
+	m.Attributes = fidlgen.Attributes{Attributes: []fidlgen.Attribute{{Name: "unknown"}}}
+	return m
+}
+
+func TestEnumUnknownRepresentation(t *testing.T) {
+	custom := enumDecl(fidlgen.Int8, enumMember("A", "1"), unknownEnumMember("OTHER", "-1"))
+	strict := enumDecl(fidlgen.Uint8, enumMember("A", "1"))
+	strict.Strictness = fidlgen.IsStrict
+
+	for _, tc := range []struct {
+		name     string
+		enum     fidlgen.Enum
+		member   fidlgen.Identifier
+		expected fidlgen.EnumValue
+	}{
+		{"custom member", custom, "OTHER", fidlgen.EnumValue{Signed: true, Int: -1}},
+		{"signed", enumDecl(fidlgen.Int16, enumMember("A", "1")), "", fidlgen.EnumValue{Signed: true, Int: 32767}},
+		{"unsigned", enumDecl(fidlgen.Uint32, enumMember("A", "1")), "", fidlgen.EnumValue{Uint: 4294967295}},
+		{"uint64", enumDecl(fidlgen.Uint64, enumMember("A", "1")), "", fidlgen.EnumValue{Uint: 18446744073709551615}},
+	} {
+		actual, err := tc.enum.UnknownRepresentation()
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+			continue
+		}
+		var member fidlgen.Identifier
+		if actual.Member != nil {
+			member = actual.Member.Name
+		}
+		if member != tc.member || actual.Value != tc.expected {
+			t.Errorf("%s: got %s = %s, want %s = %s", tc.name, member, actual.Value, tc.member, tc.expected)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		enum fidlgen.Enum
+	}{
+		{"strict", strict},
+		{"two unknown members", enumDecl(fidlgen.Uint8, unknownEnumMember("A", "1"), unknownEnumMember("B", "2"))},
+		{"collision", enumDecl(fidlgen.Uint8, enumMember("MAX", "255"))},
+	} {
+		if _, err := tc.enum.UnknownRepresentation(); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func TestBitsUnknownMask(t *testing.T) {
+	flags := bitsDecl(fidlgen.Uint8, bitsMember("A", "1"), bitsMember("B", "4"))
+	flags.Mask = "5"
+	mask, err := flags.UnknownMask()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mask != 0xfa {
+		t.Errorf("got unknown mask %#x, want 0xfa", mask)
+	}
+
+	flags.Mask = "1"
+	if _, err := flags.UnknownMask(); err == nil {
+		t.Errorf("expected an error for a member outside of the mask")
+	}
+
+	flags.Mask = "5"
+	flags.Strictness = fidlgen.IsStrict
+	if _, err := flags.UnknownMask(); err == nil {
+		t.Errorf("expected an error for strict bits")
+	}
+}
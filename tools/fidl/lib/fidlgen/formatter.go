@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"go/format"
 	"os/exec"
 	"time"
 )
@@ -18,6 +19,16 @@ type Formatter interface {
 	Format(source []byte) ([]byte, error)
 }
 
+// FormatterFunc adapts a function to a Formatter.
+type FormatterFunc func(source []byte) ([]byte, error)
+
+func (f FormatterFunc) Format(source []byte) ([]byte, error) {
+	return f(source)
+}
+
+// GoFormatter formats Go source code as gofmt does, without running it.
+var GoFormatter Formatter = FormatterFunc(format.Source)
+
 // identifyFormatter returns the input unmodified
 type identityFormatter struct{}
 
@@ -32,7 +43,7 @@ type externalFormatter struct {
 	limit int
 }
 
-var _ = []Formatter{identityFormatter{}, externalFormatter{}}
+var _ = []Formatter{FormatterFunc(nil), identityFormatter{}, externalFormatter{}}
 
 const timeout = 2 * time.Minute
 
@@ -0,0 +1,115 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// FileBanner describes the comment generated files start with: a license
+// notice, if Year is set, and a notice that the file is generated, which
+// tools recognize, e.g.
+//
+//	// Copyright 2022 The Fuchsia Authors. All rights reserved.
+//	// Use of this source code is governed by a BSD-style license that can be
+//	// found in the LICENSE file.
+//	//
+//	// Code generated by fidlgen_go; DO NOT EDIT.
+type FileBanner struct {
+	// CommentPrefix starts each line of the banner, e.g. "//" or "#". If
+	// empty, no banner is written.
+	CommentPrefix string
+	// Year is the year of the copyright notice. If zero, the license notice
+	// is omitted.
+	Year int
+	// Generator is the name of the tool generating the file. If empty, it is
+	// "fidlgen".
+	Generator string
+}
+
+// Lines returns the lines of the banner, without newlines.
+func (b FileBanner) Lines() []string {
+	if b.CommentPrefix == "" {
+		return nil
+	}
+	generator := b.Generator
+	if generator == "" {
+		generator = "fidlgen"
+	}
+	var lines []string
+	if b.Year != 0 {
+		lines = append(lines,
+			fmt.Sprintf("%s Copyright %d The Fuchsia Authors. All rights reserved.", b.CommentPrefix, b.Year),
+			b.CommentPrefix+" Use of this source code is governed by a BSD-style license that can be",
+			b.CommentPrefix+" found in the LICENSE file.",
+			b.CommentPrefix,
+		)
+	}
+	return append(lines, fmt.Sprintf("%s Code generated by %s; DO NOT EDIT.", b.CommentPrefix, generator))
+}
+
+// GeneratedFile accumulates the source of a generated file, e.g. as the
+// io.Writer templates are executed into, then writes it with its banner,
+// once formatted.
+type GeneratedFile struct {
+	// Banner is the banner the file starts with.
+	Banner FileBanner
+	// Formatters format the source in turn. The banner is added afterwards,
+	// so that formatters never rewrap it.
+	Formatters []Formatter
+
+	source bytes.Buffer
+}
+
+// NewGeneratedFile returns an empty generated file, with the given banner and
+// formatters.
+func NewGeneratedFile(banner FileBanner, formatters ...Formatter) *GeneratedFile {
+	return &GeneratedFile{Banner: banner, Formatters: formatters}
+}
+
+// Write appends p to the source of the file.
+func (f *GeneratedFile) Write(p []byte) (int, error) {
+	return f.source.Write(p)
+}
+
+// WriteString appends s to the source of the file.
+func (f *GeneratedFile) WriteString(s string) (int, error) {
+	return f.source.WriteString(s)
+}
+
+// Contents returns the contents of the file: its banner, followed by a blank
+// line and its formatted source.
+func (f *GeneratedFile) Contents() ([]byte, error) {
+	source := f.source.Bytes()
+	for _, formatter := range f.Formatters {
+		formatted, err := formatter.Format(source)
+		if err != nil {
+			return nil, fmt.Errorf("Error formatting source: %w", err)
+		}
+		source = formatted
+	}
+	banner := f.Banner.Lines()
+	if len(banner) == 0 {
+		return source, nil
+	}
+	var contents bytes.Buffer
+	contents.WriteString(strings.Join(banner, "\n"))
+	contents.WriteString("\n\n")
+	contents.Write(source)
+	return contents.Bytes(), nil
+}
+
+// WriteFile writes the contents of the file to filename, as
+// WriteFileIfChanged does: the file is replaced atomically, and left untouched
+// if it already has these contents.
+func (f *GeneratedFile) WriteFile(filename string) error {
+	contents, err := f.Contents()
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return WriteFileIfChanged(filename, contents)
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestFileBanner(t *testing.T) {
+	expected := []string{
+		"# Copyright 2022 The Fuchsia Authors. All rights reserved.",
+		"# Use of this source code is governed by a BSD-style license that can be",
+		"# found in the LICENSE file.",
+		"#",
+		"# Code generated by fidlgen_python; DO NOT EDIT.",
+	}
+	banner := fidlgen.FileBanner{CommentPrefix: "#", Year: 2022, Generator: "fidlgen_python"}
+	if diff := cmp.Diff(expected, banner.Lines()); diff != "" {
+		t.Errorf("unexpected banner (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"// Code generated by fidlgen; DO NOT EDIT."}, fidlgen.FileBanner{CommentPrefix: "//"}.Lines()); diff != "" {
+		t.Errorf("unexpected banner (-want +got):\n%s", diff)
+	}
+	if lines := (fidlgen.FileBanner{Year: 2022}).Lines(); lines != nil {
+		t.Errorf("expected no banner without a comment prefix, got %q", lines)
+	}
+}
+
+func TestGeneratedFile(t *testing.T) {
+	appendLine := func(line string) fidlgen.Formatter {
+		return fidlgen.FormatterFunc(func(source []byte) ([]byte, error) {
+			return append(source, line+"\n"...), nil
+		})
+	}
+	f := fidlgen.NewGeneratedFile(fidlgen.FileBanner{CommentPrefix: "//"},
+		fidlgen.GoFormatter, appendLine("// first"), appendLine("// second"))
+	fmt.Fprintf(f, "package   example\n")
+	f.WriteString("const  X = 1\n")
+
+	expected := "// Code generated by fidlgen; DO NOT EDIT.\n\npackage example\n\nconst X = 1\n// first\n// second\n"
+	contents, err := f.Contents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expected, string(contents)); diff != "" {
+		t.Errorf("unexpected contents (-want +got):\n%s", diff)
+	}
+
+	filename := filepath.Join(t.TempDir(), "out", "example.go")
+	if err := f.WriteFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	written, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, contents) {
+		t.Errorf("got %q, want %q", written, contents)
+	}
+
+	// Unchanged files are left untouched.
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filename, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.WriteFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	if stat, err := os.Stat(filename); err != nil {
+		t.Fatal(err)
+	} else if !stat.ModTime().Equal(past) {
+		t.Errorf("unchanged file was rewritten")
+	}
+
+	// No temporary file is left behind.
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d files, want only %s", len(entries), filename)
+	}
+}
+
+func TestGeneratedFileFormatterError(t *testing.T) {
+	failing := fidlgen.FormatterFunc(func([]byte) ([]byte, error) {
+		return nil, errors.New("bad source")
+	})
+	f := fidlgen.NewGeneratedFile(fidlgen.FileBanner{}, failing)
+	f.WriteString("source")
+	filename := filepath.Join(t.TempDir(), "out.txt")
+	if err := f.WriteFile(filename); err == nil {
+		t.Errorf("expected a formatting error")
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("expected no file to be written, got %v", err)
+	}
+}
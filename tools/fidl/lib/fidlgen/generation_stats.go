@@ -0,0 +1,80 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// StatsFileEnvVar is the environment variable naming the file generators
+// append their stats to, unless overridden with Generator.SetStatsFile. Stats
+// are not recorded when it is unset or empty.
+const StatsFileEnvVar = "FIDLGEN_STATS_FILE"
+
+// GenerationStats are the stats of the generation of a single file. A stats
+// file holds one JSON object per line for each generated file, so that the
+// stats of all generator invocations of a build may be appended to the same
+// file and aggregated afterwards.
+type GenerationStats struct {
+	// Generator is the name the generator was created with.
+	Generator string `json:"generator"`
+	// Template is the name of the template the file was generated from.
+	Template string `json:"template"`
+	// File is the path of the generated file.
+	File string `json:"file"`
+	// Library is the name of the library generated for, if known.
+	Library EncodedLibraryIdentifier `json:"library,omitempty"`
+	// Decls counts the declarations of the library by kind, if known.
+	Decls map[DeclType]int `json:"decls,omitempty"`
+	// ExecuteNanos is the time spent executing the template.
+	ExecuteNanos int64 `json:"execute_ns"`
+	// FormatNanos is the time spent formatting the generated source.
+	FormatNanos int64 `json:"format_ns"`
+	// OutputBytes is the size of the generated file, once formatted.
+	OutputBytes int `json:"output_bytes"`
+}
+
+// SetStatsFile sets the file that stats are appended to, overriding
+// StatsFileEnvVar. An empty path disables stats.
+func (gen *Generator) SetStatsFile(path string) {
+	gen.statsFile = path
+}
+
+// SetStatsLibrary records the library being generated for, whose declarations
+// are counted in stats.
+func (gen *Generator) SetStatsLibrary(r *Root) {
+	gen.statsLibrary = r.Name
+	gen.statsDecls = make(map[DeclType]int)
+	for _, declType := range r.Decls {
+		gen.statsDecls[declType]++
+	}
+}
+
+// recordStats appends stats to the stats file, if any.
+func (gen *Generator) recordStats(stats GenerationStats) error {
+	if gen.statsFile == "" {
+		return nil
+	}
+	stats.Generator = gen.tmpls.Name()
+	stats.Library = gen.statsLibrary
+	stats.Decls = gen.statsDecls
+	line, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(gen.statsFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return fmt.Errorf("Error opening stats file: %w", err)
+	}
+	// Lines are written with a single call so that concurrent generators
+	// appending to the same file do not interleave them.
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("Error writing stats file: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,94 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestGenerationStats(t *testing.T) {
+	templates := fstest.MapFS{
+		"file.tmpl": {Data: []byte(`{{- define "File" -}}library {{ .Name }};{{- end -}}`)},
+	}
+	dir := t.TempDir()
+	statsFile := filepath.Join(dir, "stats.json")
+	root := fidlgen.Root{
+		Name: "example",
+		Decls: fidlgen.DeclMap{
+			"example/S": fidlgen.StructDeclType,
+			"example/T": fidlgen.StructDeclType,
+			"example/P": fidlgen.ProtocolDeclType,
+		},
+	}
+
+	// Stats are only recorded once a stats file is set.
+	t.Setenv(fidlgen.StatsFileEnvVar, "")
+	gen := fidlgen.NewGenerator("Test", templates, fidlgen.NewFormatter(""), template.FuncMap{})
+	if err := gen.GenerateFile(filepath.Join(dir, "ignored.txt"), "File", root); err != nil {
+		t.Fatal(err)
+	}
+	gen.SetStatsFile(statsFile)
+	if err := gen.GenerateFile(filepath.Join(dir, "a.txt"), "File", root); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(fidlgen.StatsFileEnvVar, statsFile)
+	gen = fidlgen.NewGenerator("Test", templates, fidlgen.NewFormatter(""), template.FuncMap{})
+	gen.SetStatsLibrary(&root)
+	if err := gen.GenerateFile(filepath.Join(dir, "b.txt"), "File", root); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(statsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var stats []fidlgen.GenerationStats
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s fidlgen.GenerationStats
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatal(err)
+		}
+		stats = append(stats, s)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []fidlgen.GenerationStats{
+		{
+			Generator:   "Test",
+			Template:    "File",
+			File:        filepath.Join(dir, "a.txt"),
+			OutputBytes: len("library example;"),
+		},
+		{
+			Generator: "Test",
+			Template:  "File",
+			File:      filepath.Join(dir, "b.txt"),
+			Library:   "example",
+			Decls: map[fidlgen.DeclType]int{
+				fidlgen.StructDeclType:   2,
+				fidlgen.ProtocolDeclType: 1,
+			},
+			OutputBytes: len("library example;"),
+		},
+	}
+	opt := cmpopts.IgnoreFields(fidlgen.GenerationStats{}, "ExecuteNanos", "FormatNanos")
+	if diff := cmp.Diff(expected, stats, opt); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
@@ -10,22 +10,39 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"text/template"
+	"time"
 )
 
 type Generator struct {
 	tmpls     *template.Template
 	formatter Formatter
+
+	// statsFile is the file GenerationStats are appended to, if any.
+	statsFile    string
+	statsLibrary EncodedLibraryIdentifier
+	statsDecls   map[DeclType]int
+
+	// id tells the outputs of the generator stored in renderCache, if any,
+	// from those of other generators.
+	id          uint64
+	renderCache *RenderCache
 }
 
 // NewGenerator creates a new fidlgen Generator, given a name, a system of Go
 // .tmpl files dictating the generation (likely deriving from a go:embed
 // directive), a formatter for the generated source, and a template function map.
+// Besides those of the map, templates may call ExecuteCachedTemplate, see
+// RenderCache.
 func NewGenerator(name string, tmplFS fs.FS, formatter Formatter, funcs template.FuncMap) *Generator {
 	gen := &Generator{
-		template.New(name),
-		formatter,
+		tmpls:     template.New(name),
+		formatter: formatter,
+		statsFile: os.Getenv(StatsFileEnvVar),
+		id:        atomic.AddUint64(&lastGeneratorID, 1),
 	}
+	gen.tmpls.Funcs(gen.renderCacheFuncs())
 	gen.tmpls.Funcs(funcs)
 
 	// The text/template package does not make it easy for us to populate the
@@ -73,15 +90,24 @@ func (gen *Generator) GenerateFile(filename string, tmpl string, data interface{
 		return err
 	}
 
-	generated, err := gen.ExecuteTemplate(tmpl, data)
-	if err != nil {
+	stats := GenerationStats{Template: tmpl, File: filename}
+	file := NewGeneratedFile(FileBanner{}, gen.formatter)
+	start := time.Now()
+	if err := gen.tmpls.ExecuteTemplate(file, tmpl, data); err != nil {
 		return fmt.Errorf("Error generating content: %w", err)
 	}
+	stats.ExecuteNanos = time.Since(start).Nanoseconds()
 
-	formatted, err := gen.formatter.Format(generated)
+	start = time.Now()
+	contents, err := file.Contents()
 	if err != nil {
-		return fmt.Errorf("Error formatting source: %w", err)
+		return err
 	}
+	stats.FormatNanos = time.Since(start).Nanoseconds()
+	stats.OutputBytes = len(contents)
 
-	return WriteFileIfChanged(filename, formatted)
+	if err := WriteFileIfChanged(filename, contents); err != nil {
+		return err
+	}
+	return gen.recordStats(stats)
 }
@@ -0,0 +1,114 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// handleRightsNames names the rights of the zx library, as they are spelled
+// in FIDL source, e.g. `zx.Rights.READ`.
+var handleRightsNames = map[HandleRights]string{
+	HandleRightsDuplicate:     "DUPLICATE",
+	HandleRightsTransfer:      "TRANSFER",
+	HandleRightsRead:          "READ",
+	HandleRightsWrite:         "WRITE",
+	HandleRightsExecute:       "EXECUTE",
+	HandleRightsMap:           "MAP",
+	HandleRightsGetProperty:   "GET_PROPERTY",
+	HandleRightsSetProperty:   "SET_PROPERTY",
+	HandleRightsEnumerate:     "ENUMERATE",
+	HandleRightsDestroy:       "DESTROY",
+	HandleRightsSetPolicy:     "SET_POLICY",
+	HandleRightsGetPolicy:     "GET_POLICY",
+	HandleRightsSignal:        "SIGNAL",
+	HandleRightsSignalPeer:    "SIGNAL_PEER",
+	HandleRightsWait:          "WAIT",
+	HandleRightsInspect:       "INSPECT",
+	HandleRightsManageJob:     "MANAGE_JOB",
+	HandleRightsManageProcess: "MANAGE_PROCESS",
+	HandleRightsManageThread:  "MANAGE_THREAD",
+	HandleRightsApplyProfile:  "APPLY_PROFILE",
+	HandleRightsSameRights:    "SAME_RIGHTS",
+}
+
+// handleRightsAliases names the combinations of rights which the zx library
+// defines, e.g. `ZX_RIGHTS_BASIC`, along with the absence of rights.
+var handleRightsAliases = map[string]HandleRights{
+	"NONE":  HandleRightsNone,
+	"BASIC": HandleRightsBasic,
+	"IO":    HandleRightsRead | HandleRightsWrite,
+}
+
+// Names returns the names of the rights making up the handle rights, in bit
+// order, e.g. `["DUPLICATE", "TRANSFER"]`. Bits which have no name are given
+// in hexadecimal, e.g. `0x100000`.
+func (r HandleRights) Names() []string {
+	var names []string
+	for right := HandleRights(1); right != 0; right <<= 1 {
+		if r&right == 0 {
+			continue
+		}
+		if name, ok := handleRightsNames[right]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, fmt.Sprintf("%#x", uint32(right)))
+		}
+	}
+	return names
+}
+
+// Symbolic renders the handle rights as the names of their rights, joined by
+// `|`, e.g. `DUPLICATE | TRANSFER`, or `NONE` if there are none. HandleRights
+// deliberately does not implement fmt.Stringer: generators format rights as
+// numbers, e.g. with `%#x` or `%d`.
+func (r HandleRights) Symbolic() string {
+	if r == HandleRightsNone {
+		return "NONE"
+	}
+	return strings.Join(r.Names(), " | ")
+}
+
+// ParseHandleRights parses names of rights joined by `|` or `+`, e.g.
+// `DUPLICATE | TRANSFER` or `read+write`. Names are case-insensitive, and may
+// be given with the `zx.Rights.` or `ZX_RIGHT_` prefixes of FIDL and C, or
+// in hexadecimal. The combinations `BASIC` and `IO` of the zx library are
+// understood, as is `NONE`.
+func ParseHandleRights(s string) (HandleRights, error) {
+	var rights HandleRights
+	for _, part := range strings.Split(strings.ReplaceAll(s, "+", "|"), "|") {
+		name := strings.ToUpper(strings.TrimSpace(part))
+		for _, prefix := range []string{"ZX.RIGHTS.", "ZX_RIGHTS_", "ZX_RIGHT_"} {
+			name = strings.TrimPrefix(name, prefix)
+		}
+		right, ok := parseHandleRight(name)
+		if !ok {
+			return 0, fmt.Errorf("unknown handle right %q in %q", strings.TrimSpace(part), s)
+		}
+		rights |= right
+	}
+	return rights, nil
+}
+
+// parseHandleRight parses a single upper-case name of a right, or of a
+// combination of rights.
+func parseHandleRight(name string) (HandleRights, bool) {
+	if rights, ok := handleRightsAliases[name]; ok {
+		return rights, true
+	}
+	for right, rightName := range handleRightsNames {
+		if rightName == name {
+			return right, true
+		}
+	}
+	if strings.HasPrefix(name, "0X") {
+		if value, err := strconv.ParseUint(name[2:], 16, 32); err == nil {
+			return HandleRights(value), true
+		}
+	}
+	return 0, false
+}
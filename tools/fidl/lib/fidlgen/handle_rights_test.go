@@ -0,0 +1,67 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestHandleRightsNames(t *testing.T) {
+	for _, tc := range []struct {
+		rights   fidlgen.HandleRights
+		names    []string
+		rendered string
+	}{
+		{fidlgen.HandleRightsNone, nil, "NONE"},
+		{fidlgen.HandleRightsTransfer | fidlgen.HandleRightsDuplicate, []string{"DUPLICATE", "TRANSFER"}, "DUPLICATE | TRANSFER"},
+		{fidlgen.HandleRightsSameRights, []string{"SAME_RIGHTS"}, "SAME_RIGHTS"},
+		{fidlgen.HandleRightsRead | 1<<20, []string{"READ", "0x100000"}, "READ | 0x100000"},
+	} {
+		if diff := cmp.Diff(tc.names, tc.rights.Names()); diff != "" {
+			t.Errorf("%#x: unexpected names (-want +got):\n%s", uint32(tc.rights), diff)
+		}
+		if actual := tc.rights.Symbolic(); actual != tc.rendered {
+			t.Errorf("%#x: got %q, want %q", uint32(tc.rights), actual, tc.rendered)
+		}
+		parsed, err := fidlgen.ParseHandleRights(tc.rendered)
+		if err != nil {
+			t.Errorf("%q: %s", tc.rendered, err)
+		} else if parsed != tc.rights {
+			t.Errorf("%q: parsed as %#x, want %#x", tc.rendered, uint32(parsed), uint32(tc.rights))
+		}
+	}
+}
+
+func TestParseHandleRights(t *testing.T) {
+	for _, tc := range []struct {
+		text     string
+		expected fidlgen.HandleRights
+	}{
+		{"read+write", fidlgen.HandleRightsRead | fidlgen.HandleRightsWrite},
+		{"io", fidlgen.HandleRightsRead | fidlgen.HandleRightsWrite},
+		{"zx.Rights.MAP | zx.Rights.READ", fidlgen.HandleRightsMap | fidlgen.HandleRightsRead},
+		{"ZX_RIGHTS_BASIC", fidlgen.HandleRightsBasic},
+		{"ZX_RIGHT_SIGNAL_PEER", fidlgen.HandleRightsSignalPeer},
+		{"none", fidlgen.HandleRightsNone},
+	} {
+		actual, err := fidlgen.ParseHandleRights(tc.text)
+		if err != nil {
+			t.Errorf("%q: %s", tc.text, err)
+			continue
+		}
+		if actual != tc.expected {
+			t.Errorf("%q: got %s, want %s", tc.text, actual.Symbolic(), tc.expected.Symbolic())
+		}
+	}
+
+	for _, text := range []string{"", "read+", "read | bogus", "0xZZ"} {
+		if rights, err := fidlgen.ParseHandleRights(text); err == nil {
+			t.Errorf("%q: expected an error, got %s", text, rights.Symbolic())
+		}
+	}
+}
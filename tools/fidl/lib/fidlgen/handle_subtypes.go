@@ -0,0 +1,102 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"sort"
+)
+
+// New Zircon object types come with new handle subtypes, which the IR of
+// newer libraries may use before fidlgen knows about them. Unknown subtypes are
+// still carried through the IR: their object type is given by the IR itself,
+// see Type.ObjType and Root.ObjectType, and backends render it numerically
+// since they cannot name it.
+
+// HandleSubtypeInfo describes a handle subtype.
+type HandleSubtypeInfo struct {
+	// Subtype is the subtype, as spelled in the IR, e.g. `suspendtoken`.
+	Subtype HandleSubtype
+	// ObjectType is the object type of handles of the subtype.
+	ObjectType ObjectType
+	// ConstName is the name of the object type in library zx, e.g.
+	// `SUSPEND_TOKEN`, which is also the suffix of its ZX_OBJ_TYPE_ constant.
+	ConstName string
+}
+
+var handleSubtypes = knownHandleSubtypes()
+
+// knownHandleSubtypes returns the handle subtypes fidlgen knows about.
+func knownHandleSubtypes() map[HandleSubtype]HandleSubtypeInfo {
+	m := make(map[HandleSubtype]HandleSubtypeInfo)
+	for _, info := range []HandleSubtypeInfo{
+		{HandleSubtypeNone, ObjectTypeNone, "NONE"},
+		{HandleSubtypeBti, ObjectTypeBti, "BTI"},
+		{HandleSubtypeChannel, ObjectTypeChannel, "CHANNEL"},
+		{HandleSubtypeClock, ObjectTypeClock, "CLOCK"},
+		{HandleSubtypeDebugLog, ObjectTypeDebugLog, "DEBUGLOG"},
+		{HandleSubtypeEvent, ObjectTypeEvent, "EVENT"},
+		{HandleSubtypeEventpair, ObjectTypeEventPair, "EVENTPAIR"},
+		{HandleSubtypeException, ObjectTypeException, "EXCEPTION"},
+		{HandleSubtypeFifo, ObjectTypeFifo, "FIFO"},
+		{HandleSubtypeGuest, ObjectTypeGuest, "GUEST"},
+		{HandleSubtypeInterrupt, ObjectTypeInterrupt, "INTERRUPT"},
+		{HandleSubtypeIommu, ObjectTypeIommu, "IOMMU"},
+		{HandleSubtypeJob, ObjectTypeJob, "JOB"},
+		{HandleSubtypeMsi, ObjectTypeMsi, "MSI"},
+		{HandleSubtypePager, ObjectTypePager, "PAGER"},
+		{HandleSubtypePciDevice, ObjectTypePciDevice, "PCI_DEVICE"},
+		{HandleSubtypePmt, ObjectTypePmt, "PMT"},
+		{HandleSubtypePort, ObjectTypePort, "PORT"},
+		{HandleSubtypeProcess, ObjectTypeProcess, "PROCESS"},
+		{HandleSubtypeProfile, ObjectTypeProfile, "PROFILE"},
+		{HandleSubtypeResource, ObjectTypeResource, "RESOURCE"},
+		{HandleSubtypeSocket, ObjectTypeSocket, "SOCKET"},
+		{HandleSubtypeStream, ObjectTypeStream, "STREAM"},
+		{HandleSubtypeSuspendToken, ObjectTypeSuspendToken, "SUSPEND_TOKEN"},
+		{HandleSubtypeThread, ObjectTypeThread, "THREAD"},
+		{HandleSubtypeTime, ObjectTypeTimer, "TIMER"},
+		{HandleSubtypeVcpu, ObjectTypeVcpu, "VCPU"},
+		{HandleSubtypeVmar, ObjectTypeVmar, "VMAR"},
+		{HandleSubtypeVmo, ObjectTypeVmo, "VMO"},
+	} {
+		m[info.Subtype] = info
+	}
+	return m
+}
+
+// LookupHandleSubtype returns the information fidlgen has about a handle
+// subtype, and whether it has any.
+func LookupHandleSubtype(subtype HandleSubtype) (HandleSubtypeInfo, bool) {
+	info, ok := handleSubtypes[subtype]
+	return info, ok
+}
+
+// HandleSubtypes returns the handle subtypes fidlgen knows about, sorted.
+func HandleSubtypes() []HandleSubtypeInfo {
+	infos := make([]HandleSubtypeInfo, 0, len(handleSubtypes))
+	for _, info := range handleSubtypes {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Subtype < infos[j].Subtype
+	})
+	return infos
+}
+
+// IsKnown returns whether fidlgen knows about the handle subtype.
+func (s HandleSubtype) IsKnown() bool {
+	_, ok := LookupHandleSubtype(s)
+	return ok
+}
+
+// ConstName returns the name of the object type of the handle subtype in
+// library zx, e.g. `SUSPEND_TOKEN`, and whether the subtype is known. Unknown
+// subtypes cannot be named, e.g. upper-casing them need not give the name of
+// an existing constant: their object type should be rendered from
+// Type.ObjType instead.
+func (s HandleSubtype) ConstName() (string, bool) {
+	info, ok := LookupHandleSubtype(s)
+	return info.ConstName, ok
+}
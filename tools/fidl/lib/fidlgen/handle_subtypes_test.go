@@ -0,0 +1,57 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"testing"
+)
+
+func TestBuiltinHandleSubtypes(t *testing.T) {
+	for _, tc := range []struct {
+		subtype    HandleSubtype
+		objectType ObjectType
+		constName  string
+	}{
+		{HandleSubtypeNone, ObjectTypeNone, "NONE"},
+		{HandleSubtypeChannel, ObjectTypeChannel, "CHANNEL"},
+		{HandleSubtypeSuspendToken, ObjectTypeSuspendToken, "SUSPEND_TOKEN"},
+		{HandleSubtypeTime, ObjectTypeTimer, "TIMER"},
+	} {
+		if !tc.subtype.IsKnown() {
+			t.Errorf("%s: expected a known subtype", tc.subtype)
+		}
+		if actual := ObjectTypeFromHandleSubtype(tc.subtype); actual != tc.objectType {
+			t.Errorf("%s: got object type %d, want %d", tc.subtype, actual, tc.objectType)
+		}
+		if actual, ok := tc.subtype.ConstName(); !ok || actual != tc.constName {
+			t.Errorf("%s: got %s, %t, want %s", tc.subtype, actual, ok, tc.constName)
+		}
+	}
+}
+
+func TestUnknownHandleSubtype(t *testing.T) {
+	const iob HandleSubtype = "iob"
+	if iob.IsKnown() {
+		t.Fatalf("%s: expected an unknown subtype", iob)
+	}
+	if actual := ObjectTypeFromHandleSubtype(iob); actual != ObjectTypeNone {
+		t.Errorf("got object type %d for an unknown subtype", actual)
+	}
+	if actual, ok := iob.ConstName(); ok {
+		t.Errorf("got %s for an unknown subtype, want no name", actual)
+	}
+
+	subtypes := HandleSubtypes()
+	for i := 1; i < len(subtypes); i++ {
+		if subtypes[i-1].Subtype >= subtypes[i].Subtype {
+			t.Errorf("subtypes are not sorted: %s before %s", subtypes[i-1].Subtype, subtypes[i].Subtype)
+		}
+	}
+	for _, info := range subtypes {
+		if info.Subtype == iob {
+			t.Errorf("%s is listed among the known subtypes", iob)
+		}
+	}
+}
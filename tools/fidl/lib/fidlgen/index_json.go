@@ -0,0 +1,165 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The types in this file mirror the index JSON that fidlc produces when the
+// ExperimentOutputIndexJSON experiment is enabled (see
+// //tools/fidl/fidlc/src/index_json_generator.cc). Whereas the JSON IR is
+// meant for code generation, the index is meant for tooling (e.g., code
+// search and IDEs): it maps each identifier, including references to
+// identifiers from other libraries, to its exact span in the source.
+
+// IndexLocation gives a span of FIDL source text.
+type IndexLocation struct {
+	// IsVirtual is true for locations that do not correspond to an actual
+	// source file, e.g. those of compiler-generated declarations.
+	IsVirtual bool `json:"is_virtual"`
+	// Path is the path to the source file.
+	Path string `json:"path"`
+	// Data is the spanned text.
+	Data string `json:"data"`
+	// StartOffset is the byte offset of the start of the span.
+	StartOffset int `json:"start_offset"`
+	// EndOffset is the byte offset of the end of the span (exclusive).
+	EndOffset int `json:"end_offset"`
+}
+
+// IndexUsingDeclaration represents a `using` declaration.
+type IndexUsingDeclaration struct {
+	LibraryName  EncodedLibraryIdentifier `json:"library_name"`
+	ReferencedAt IndexLocation            `json:"referenced_at"`
+}
+
+// IndexDependency represents a library dependency, as located by its own
+// library declaration.
+type IndexDependency struct {
+	LibraryName     EncodedLibraryIdentifier `json:"library_name"`
+	LibraryLocation IndexLocation            `json:"library_location"`
+}
+
+// IndexDependencyIdentifier represents a declaration of a dependency library
+// that is referenced by the indexed library.
+type IndexDependencyIdentifier struct {
+	Identifier EncodedCompoundIdentifier `json:"identifier"`
+	Location   IndexLocation             `json:"location"`
+}
+
+// IndexReference represents a reference to a declaration, e.g. from the type
+// of a member or from a constant.
+type IndexReference struct {
+	Identifier   EncodedCompoundIdentifier `json:"identifier"`
+	ReferencedAt IndexLocation             `json:"referenced_at"`
+}
+
+// IndexMember represents a member of a declaration: a field of a layout or a
+// method of a protocol. References lists the declarations that the member's
+// type or value refers to.
+type IndexMember struct {
+	Name       Identifier       `json:"name"`
+	Location   IndexLocation    `json:"location"`
+	References []IndexReference `json:"references,omitempty"`
+}
+
+// IndexDecl represents a declaration along with its members.
+type IndexDecl struct {
+	Identifier  EncodedCompoundIdentifier `json:"identifier"`
+	Location    IndexLocation             `json:"location"`
+	IsAnonymous bool                      `json:"is_anonymous,omitempty"`
+	Members     []IndexMember             `json:"members,omitempty"`
+	References  []IndexReference          `json:"references,omitempty"`
+}
+
+// IndexJSON is the top-level object of the index of a FIDL library.
+type IndexJSON struct {
+	Name                  EncodedLibraryIdentifier    `json:"name"`
+	LibDeclarations       []IndexLocation             `json:"lib_declarations"`
+	UsingDeclarations     []IndexUsingDeclaration     `json:"using_declarations"`
+	Dependencies          []IndexDependency           `json:"dependencies"`
+	DependencyIdentifiers []IndexDependencyIdentifier `json:"dependency_identifiers"`
+	Consts                []IndexDecl                 `json:"consts"`
+	Bits                  []IndexDecl                 `json:"bits"`
+	Enums                 []IndexDecl                 `json:"enums"`
+	Structs               []IndexDecl                 `json:"structs"`
+	Tables                []IndexDecl                 `json:"tables"`
+	Unions                []IndexDecl                 `json:"unions"`
+	Protocols             []IndexDecl                 `json:"protocols"`
+}
+
+// ReadIndexJSON reads an index JSON file.
+func ReadIndexJSON(filename string) (IndexJSON, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return IndexJSON{}, fmt.Errorf("Error reading from %s: %w", filename, err)
+	}
+	defer f.Close()
+	return DecodeIndexJSON(f)
+}
+
+// DecodeIndexJSON reads index JSON content from a reader.
+func DecodeIndexJSON(r io.Reader) (IndexJSON, error) {
+	var index IndexJSON
+	if err := json.NewDecoder(r).Decode(&index); err != nil {
+		return IndexJSON{}, fmt.Errorf("Error parsing index JSON: %w", err)
+	}
+	return index, nil
+}
+
+// forEachDecl calls a provided callback on each declaration of the index.
+func (idx *IndexJSON) forEachDecl(cb func(*IndexDecl)) {
+	for _, decls := range [][]IndexDecl{
+		idx.Consts, idx.Bits, idx.Enums, idx.Structs, idx.Tables, idx.Unions, idx.Protocols,
+	} {
+		for i := range decls {
+			cb(&decls[i])
+		}
+	}
+}
+
+// Definitions maps the identifier of every declaration and member of the
+// indexed library to the location at which it is defined. Members are keyed
+// by their member-qualified identifier, e.g. "my.library/MyStruct.field". The
+// map is iterated in random order, so its keys should be sorted for
+// deterministic output.
+func (idx *IndexJSON) Definitions() map[EncodedCompoundIdentifier]IndexLocation {
+	defs := make(map[EncodedCompoundIdentifier]IndexLocation)
+	idx.forEachDecl(func(decl *IndexDecl) {
+		defs[decl.Identifier] = decl.Location
+		for _, m := range decl.Members {
+			defs[EncodedCompoundIdentifier(fmt.Sprintf("%s.%s", decl.Identifier, m.Name))] = m.Location
+		}
+	})
+	for _, dep := range idx.DependencyIdentifiers {
+		if _, ok := defs[dep.Identifier]; !ok {
+			defs[dep.Identifier] = dep.Location
+		}
+	}
+	return defs
+}
+
+// References maps the identifier of every declaration referenced in the
+// indexed library to the locations at which it is referenced, in the order in
+// which they appear in the index. As with Definitions, the keys of the map
+// should be sorted for deterministic output.
+func (idx *IndexJSON) References() map[EncodedCompoundIdentifier][]IndexLocation {
+	refs := make(map[EncodedCompoundIdentifier][]IndexLocation)
+	idx.forEachDecl(func(decl *IndexDecl) {
+		for _, ref := range decl.References {
+			refs[ref.Identifier] = append(refs[ref.Identifier], ref.ReferencedAt)
+		}
+		for _, m := range decl.Members {
+			for _, ref := range m.References {
+				refs[ref.Identifier] = append(refs[ref.Identifier], ref.ReferencedAt)
+			}
+		}
+	})
+	return refs
+}
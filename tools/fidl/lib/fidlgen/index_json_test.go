@@ -0,0 +1,80 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestDecodeIndexJSON(t *testing.T) {
+	input := `{
+		"name": "example",
+		"lib_declarations": [
+			{"is_virtual": false, "path": "example.fidl", "data": "example", "start_offset": 8, "end_offset": 15}
+		],
+		"using_declarations": [
+			{"library_name": "dep", "referenced_at": {"is_virtual": false, "path": "example.fidl", "data": "dep", "start_offset": 23, "end_offset": 26}}
+		],
+		"dependencies": [
+			{"library_name": "dep", "library_location": {"is_virtual": false, "path": "dep.fidl", "data": "dep", "start_offset": 8, "end_offset": 11}}
+		],
+		"dependency_identifiers": [
+			{"identifier": "dep/Point", "location": {"is_virtual": false, "path": "dep.fidl", "data": "Point", "start_offset": 18, "end_offset": 23}}
+		],
+		"structs": [
+			{
+				"identifier": "example/Line",
+				"location": {"is_virtual": false, "path": "example.fidl", "data": "Line", "start_offset": 33, "end_offset": 37},
+				"members": [
+					{
+						"name": "start",
+						"location": {"is_virtual": false, "path": "example.fidl", "data": "start", "start_offset": 52, "end_offset": 57},
+						"references": [
+							{"identifier": "dep/Point", "referenced_at": {"is_virtual": false, "path": "example.fidl", "data": "dep.Point", "start_offset": 58, "end_offset": 67}}
+						]
+					}
+				]
+			}
+		]
+	}`
+
+	index, err := fidlgen.DecodeIndexJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Name != "example" {
+		t.Errorf("got library name %q, want %q", index.Name, "example")
+	}
+
+	defs := index.Definitions()
+	wantDefs := map[fidlgen.EncodedCompoundIdentifier]int{
+		"example/Line":       33,
+		"example/Line.start": 52,
+		"dep/Point":          18,
+	}
+	for name, offset := range wantDefs {
+		loc, ok := defs[name]
+		if !ok {
+			t.Errorf("no definition found for %s", name)
+			continue
+		}
+		if loc.StartOffset != offset {
+			t.Errorf("%s: got start offset %d, want %d", name, loc.StartOffset, offset)
+		}
+	}
+
+	wantRefs := map[fidlgen.EncodedCompoundIdentifier][]fidlgen.IndexLocation{
+		"dep/Point": {
+			{Path: "example.fidl", Data: "dep.Point", StartOffset: 58, EndOffset: 67},
+		},
+	}
+	if diff := cmp.Diff(wantRefs, index.References()); diff != "" {
+		t.Errorf("References: unexpected diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,438 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// These tests audit the JSON mapping of the IR structs reachable from Root,
+// so that a field added to types.go without a JSON tag, or with one that
+// does not match the IR, is caught rather than silently dropped on decode.
+
+var (
+	typeType = reflect.TypeOf(fidlgen.Type{})
+	// int64OrUint64 is unexported, so it is found through a field using it.
+	int64OrUint64Type = reflect.TypeOf(fidlgen.Enum{}.RawUnknownValue)
+
+	// JSON IR names are snake_case.
+	jsonNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+)
+
+// irStructTypes returns the fidlgen struct types reachable from Root, sorted
+// by name. The types decoded by hand are not walked into.
+func irStructTypes() []reflect.Type {
+	seen := make(map[reflect.Type]struct{})
+	var walk func(reflect.Type)
+	walk = func(t reflect.Type) {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			walk(t.Elem())
+		case reflect.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		case reflect.Struct:
+			if _, ok := seen[t]; ok || t.PkgPath() != typeType.PkgPath() {
+				return
+			}
+			seen[t] = struct{}{}
+			if t == typeType || t == int64OrUint64Type {
+				return
+			}
+			for i := 0; i < t.NumField(); i++ {
+				walk(t.Field(i).Type)
+			}
+		}
+	}
+	walk(reflect.TypeOf(fidlgen.Root{}))
+	// Of the types making up Type, only its shapes are part of the IR.
+	walk(reflect.TypeOf(fidlgen.TypeShape{}))
+
+	var types []reflect.Type
+	for t := range seen {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Name() < types[j].Name() })
+	return types
+}
+
+// jsonName returns the JSON name of a struct field, and whether the field is
+// an untagged embedded struct whose fields are promoted instead.
+func jsonName(f reflect.StructField) (string, bool) {
+	tag, ok := f.Tag.Lookup("json")
+	if !ok && f.Anonymous {
+		return "", true
+	}
+	return strings.Split(tag, ",")[0], false
+}
+
+func TestJSONTags(t *testing.T) {
+	for _, typ := range irStructTypes() {
+		// Type and int64OrUint64 are decoded by hand.
+		if typ == typeType || typ == int64OrUint64Type {
+			continue
+		}
+		names := make(map[string]string)
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, promoted := jsonName(f)
+			if promoted {
+				continue
+			}
+			if name == "-" {
+				continue
+			}
+			if !jsonNameRegexp.MatchString(name) {
+				t.Errorf("%s.%s: JSON name %q is not snake_case; is the field missing a json tag?", typ.Name(), f.Name, name)
+				continue
+			}
+			if other, ok := names[name]; ok {
+				t.Errorf("%s.%s: JSON name %q is already used by %s", typ.Name(), f.Name, name, other)
+			}
+			names[name] = f.Name
+		}
+	}
+}
+
+// The fixed JSON of the decode tests is written from the IR schema rather
+// than from the struct tags, and sets every field, so that a field whose tag
+// does not match the IR decodes to its zero value. It need not be valid IR.
+
+const (
+	locationJSON   = `{"filename": "test.fidl", "line": 2, "column": 6, "length": 3}`
+	literalJSON    = `{"kind": "numeric", "value": "7"}`
+	constantJSON   = `{"kind": "literal", "identifier": "test.json/SEVEN", "literal": ` + literalJSON + `, "value": "7", "expression": "SEVEN"}`
+	attributeJSON  = `{"name": "doc", "arguments": [{"name": "value", "value": ` + constantJSON + `}]}`
+	typeShapeJSON  = `{"inline_size": 8, "alignment": 4, "depth": 1, "max_handles": 1, "max_out_of_line": 16, "has_padding": true, "has_envelope": true, "has_flexible_envelope": true}`
+	fieldShapeJSON = `{"offset": 4, "padding": 2}`
+	typeJSON       = `{"kind": "primitive", "subtype": "uint32", "type_shape_v1": ` + typeShapeJSON + `, "type_shape_v2": ` + typeShapeJSON + `}`
+	typeCtorJSON   = `{"name": "vector", "args": [{"name": "uint8", "args": [], "nullable": false}], "nullable": true, "maybe_size": ` + constantJSON + `}`
+
+	// The members of the objects embedding Attributes, Decl, LayoutDecl and
+	// ResourceableLayoutDecl.
+	attributesMembersJSON   = `"maybe_attributes": [` + attributeJSON + `]`
+	declMembersJSON         = attributesMembersJSON + `, "name": "test.json/Foo", "location": ` + locationJSON
+	layoutDeclMembersJSON   = declMembersJSON + `, "naming_context": ["Foo"]`
+	resourceableMembersJSON = layoutDeclMembersJSON + `, "resource": true`
+)
+
+var (
+	sampleLocation  = fidlgen.Location{Filename: "test.fidl", Line: 2, Column: 6, Length: 3}
+	sampleLiteral   = fidlgen.Literal{Kind: fidlgen.NumericLiteral, Value: "7"}
+	sampleConstant  = fidlgen.Constant{Kind: fidlgen.LiteralConstant, Identifier: "test.json/SEVEN", Literal: sampleLiteral, Value: "7", Expression: "SEVEN"}
+	sampleAttribute = fidlgen.Attribute{Name: "doc", Args: []fidlgen.AttributeArg{{Name: "value", Value: sampleConstant}}}
+	sampleTypeShape = fidlgen.TypeShape{
+		InlineSize:          8,
+		Alignment:           4,
+		Depth:               1,
+		MaxHandles:          1,
+		MaxOutOfLine:        16,
+		HasPadding:          true,
+		HasEnvelope:         true,
+		HasFlexibleEnvelope: true,
+	}
+	sampleFieldShape = fidlgen.FieldShape{Offset: 4, Padding: 2}
+	sampleType       = fidlgen.Type{
+		Kind:             fidlgen.PrimitiveType,
+		PrimitiveSubtype: fidlgen.Uint32,
+		TypeShapeV1:      sampleTypeShape,
+		TypeShapeV2:      sampleTypeShape,
+	}
+	sampleTypeCtor = fidlgen.PartialTypeConstructor{
+		Name:      "vector",
+		Args:      []fidlgen.PartialTypeConstructor{{Name: "uint8", Args: []fidlgen.PartialTypeConstructor{}}},
+		Nullable:  true,
+		MaybeSize: &sampleConstant,
+	}
+
+	sampleAttributes             = fidlgen.Attributes{Attributes: []fidlgen.Attribute{sampleAttribute}}
+	sampleDecl                   = fidlgen.Decl{Attributes: sampleAttributes, Name: "test.json/Foo", Location: sampleLocation}
+	sampleLayoutDecl             = fidlgen.LayoutDecl{Decl: sampleDecl, NamingContext: fidlgen.NamingContext{"Foo"}}
+	sampleResourceableLayoutDecl = fidlgen.ResourceableLayoutDecl{LayoutDecl: sampleLayoutDecl, Resourceness: fidlgen.IsResourceType}
+
+	strict       = true
+	resourceness = fidlgen.IsResourceType
+)
+
+var jsonDecodeCases = []struct {
+	json string
+	want interface{}
+}{
+	{locationJSON, sampleLocation},
+	{literalJSON, sampleLiteral},
+	{constantJSON, sampleConstant},
+	{attributeJSON, sampleAttribute},
+	{`{"name": "value", "value": ` + constantJSON + `}`, fidlgen.AttributeArg{Name: "value", Value: sampleConstant}},
+	{`{` + attributesMembersJSON + `}`, sampleAttributes},
+	{typeShapeJSON, sampleTypeShape},
+	{fieldShapeJSON, sampleFieldShape},
+	{typeCtorJSON, sampleTypeCtor},
+	{`{` + declMembersJSON + `}`, sampleDecl},
+	{`{` + layoutDeclMembersJSON + `}`, sampleLayoutDecl},
+	{`{` + resourceableMembersJSON + `}`, sampleResourceableLayoutDecl},
+	{`{"kind": "struct", "resource": true}`, fidlgen.DeclInfo{Type: fidlgen.StructDeclType, Resourceness: &resourceness}},
+	{
+		`{` + declMembersJSON + `, "partial_type_ctor": ` + typeCtorJSON + `}`,
+		fidlgen.TypeAlias{Decl: sampleDecl, PartialTypeConstructor: sampleTypeCtor},
+	},
+	{
+		`{` + declMembersJSON + `, "type": ` + typeJSON + `, "experimental_maybe_from_type_alias": ` + typeCtorJSON + `}`,
+		fidlgen.NewType{Decl: sampleDecl, Type: sampleType, Alias: &sampleTypeCtor},
+	},
+	{
+		`{` + declMembersJSON + `, "type": ` + typeJSON + `, "value": ` + constantJSON + `}`,
+		fidlgen.Const{Decl: sampleDecl, Type: sampleType, Value: sampleConstant},
+	},
+	{
+		`{` + attributesMembersJSON + `, "name": "A", "value": ` + constantJSON + `}`,
+		fidlgen.BitsMember{Attributes: sampleAttributes, Name: "A", Value: sampleConstant},
+	},
+	{
+		`{` + layoutDeclMembersJSON + `, "type": ` + typeJSON + `, "mask": "1", "members": [{"name": "A"}], "strict": true}`,
+		fidlgen.Bits{
+			LayoutDecl: sampleLayoutDecl,
+			Type:       sampleType,
+			Mask:       "1",
+			Members:    []fidlgen.BitsMember{{Name: "A"}},
+			Strictness: fidlgen.IsStrict,
+		},
+	},
+	{
+		`{` + attributesMembersJSON + `, "name": "A", "value": ` + constantJSON + `}`,
+		fidlgen.EnumMember{Attributes: sampleAttributes, Name: "A", Value: sampleConstant},
+	},
+	{
+		`{` + layoutDeclMembersJSON + `, "type": "uint8", "members": [{"name": "A"}], "strict": true, "maybe_unknown_value": 255}`,
+		fidlgen.Enum{
+			LayoutDecl:      sampleLayoutDecl,
+			Type:            fidlgen.Uint8,
+			Members:         []fidlgen.EnumMember{{Name: "A"}},
+			Strictness:      fidlgen.IsStrict,
+			RawUnknownValue: fidlgen.Int64OrUint64FromUint64ForTesting(255),
+		},
+	},
+	{
+		`{` + declMembersJSON + `, "type": ` + typeJSON + `}`,
+		fidlgen.ResourceProperty{Decl: sampleDecl, Type: sampleType},
+	},
+	{
+		`{` + declMembersJSON + `, "type": ` + typeJSON + `, "properties": [{"name": "test.json/rights"}]}`,
+		fidlgen.Resource{Decl: sampleDecl, Type: sampleType, Properties: []fidlgen.ResourceProperty{{Decl: fidlgen.Decl{Name: "test.json/rights"}}}},
+	},
+	{
+		`{` + attributesMembersJSON + `, "ordinal": 42, "name": "Foo", "location": ` + locationJSON + `, "strict": true, "is_composed": true, ` +
+			`"has_request": true, "maybe_request_payload": ` + typeJSON + `, "has_response": true, "maybe_response_payload": ` + typeJSON + `, ` +
+			`"has_error": true, "maybe_response_result_type": ` + typeJSON + `, "maybe_response_success_type": ` + typeJSON + `, ` +
+			`"maybe_response_err_type": ` + typeJSON + `}`,
+		fidlgen.Method{
+			Attributes:      sampleAttributes,
+			Ordinal:         42,
+			Name:            "Foo",
+			Location:        sampleLocation,
+			MaybeStrict:     &strict,
+			IsComposed:      true,
+			HasRequest:      true,
+			RequestPayload:  &sampleType,
+			HasResponse:     true,
+			ResponsePayload: &sampleType,
+			HasError:        true,
+			ResultType:      &sampleType,
+			ValueType:       &sampleType,
+			ErrorType:       &sampleType,
+		},
+	},
+	{
+		`{` + declMembersJSON + `, "openness": "open", "methods": [{"name": "Foo"}], "composed_protocols": [{"name": "test.json/Bar"}]}`,
+		fidlgen.Protocol{
+			Decl:     sampleDecl,
+			Openness: fidlgen.Open,
+			Methods:  []fidlgen.Method{{Name: "Foo"}},
+			Composed: []fidlgen.Decl{{Name: "test.json/Bar"}},
+		},
+	},
+	{
+		`{` + attributesMembersJSON + `, "name": "foo", "type": ` + typeJSON + `}`,
+		fidlgen.ServiceMember{Attributes: sampleAttributes, Name: "foo", Type: sampleType},
+	},
+	{
+		`{` + declMembersJSON + `, "members": [{"name": "foo"}]}`,
+		fidlgen.Service{Decl: sampleDecl, Members: []fidlgen.ServiceMember{{Name: "foo"}}},
+	},
+	{
+		`{` + attributesMembersJSON + `, "type": ` + typeJSON + `, "name": "foo", "maybe_default_value": ` + constantJSON + `, ` +
+			`"experimental_maybe_from_type_alias": ` + typeCtorJSON + `, "max_handles": 1, "field_shape_v1": ` + fieldShapeJSON + `, ` +
+			`"field_shape_v2": ` + fieldShapeJSON + `}`,
+		fidlgen.StructMember{
+			Attributes:        sampleAttributes,
+			Type:              sampleType,
+			Name:              "foo",
+			MaybeDefaultValue: &sampleConstant,
+			MaybeTypeAlias:    &sampleTypeCtor,
+			MaxHandles:        1,
+			FieldShapeV1:      sampleFieldShape,
+			FieldShapeV2:      sampleFieldShape,
+		},
+	},
+	{
+		`{` + resourceableMembersJSON + `, "members": [{"name": "foo"}], "type_shape_v1": ` + typeShapeJSON + `, "type_shape_v2": ` + typeShapeJSON + `}`,
+		fidlgen.Struct{
+			ResourceableLayoutDecl: sampleResourceableLayoutDecl,
+			Members:                []fidlgen.StructMember{{Name: "foo"}},
+			TypeShapeV1:            sampleTypeShape,
+			TypeShapeV2:            sampleTypeShape,
+		},
+	},
+	{
+		`{` + attributesMembersJSON + `, "reserved": true, "type": ` + typeJSON + `, "name": "foo", "ordinal": 1, ` +
+			`"maybe_default_value": ` + constantJSON + `, "experimental_maybe_from_type_alias": ` + typeCtorJSON + `, "max_out_of_line": 16}`,
+		fidlgen.TableMember{
+			Attributes:        sampleAttributes,
+			Reserved:          true,
+			Type:              sampleType,
+			Name:              "foo",
+			Ordinal:           1,
+			MaybeDefaultValue: &sampleConstant,
+			MaybeTypeAlias:    &sampleTypeCtor,
+			MaxOutOfLine:      16,
+		},
+	},
+	{
+		`{` + resourceableMembersJSON + `, "members": [{"name": "foo"}], "type_shape_v1": ` + typeShapeJSON + `, "type_shape_v2": ` + typeShapeJSON + `}`,
+		fidlgen.Table{
+			ResourceableLayoutDecl: sampleResourceableLayoutDecl,
+			Members:                []fidlgen.TableMember{{Name: "foo"}},
+			TypeShapeV1:            sampleTypeShape,
+			TypeShapeV2:            sampleTypeShape,
+		},
+	},
+	{
+		`{` + attributesMembersJSON + `, "reserved": true, "ordinal": 1, "type": ` + typeJSON + `, "name": "foo", "offset": 8, ` +
+			`"max_out_of_line": 16, "experimental_maybe_from_type_alias": ` + typeCtorJSON + `}`,
+		fidlgen.UnionMember{
+			Attributes:     sampleAttributes,
+			Reserved:       true,
+			Ordinal:        1,
+			Type:           sampleType,
+			Name:           "foo",
+			Offset:         8,
+			MaxOutOfLine:   16,
+			MaybeTypeAlias: &sampleTypeCtor,
+		},
+	},
+	{
+		`{` + resourceableMembersJSON + `, "members": [{"name": "foo"}], "strict": true, "type_shape_v1": ` + typeShapeJSON + `, ` +
+			`"type_shape_v2": ` + typeShapeJSON + `}`,
+		fidlgen.Union{
+			ResourceableLayoutDecl: sampleResourceableLayoutDecl,
+			Members:                []fidlgen.UnionMember{{Name: "foo"}},
+			Strictness:             fidlgen.IsStrict,
+			TypeShapeV1:            sampleTypeShape,
+			TypeShapeV2:            sampleTypeShape,
+		},
+	},
+	{
+		`{"name": "test.dep", "declarations": {"test.dep/Foo": {"kind": "struct"}}}`,
+		fidlgen.Library{
+			Name:  "test.dep",
+			Decls: fidlgen.DeclInfoMap{"test.dep/Foo": {Type: fidlgen.StructDeclType}},
+		},
+	},
+	{
+		`{
+			"name": "test.json",
+			"experiments": ["output_index_json"],
+			"const_declarations": [{"name": "test.json/SEVEN"}],
+			"bits_declarations": [{"name": "test.json/Bits"}],
+			"enum_declarations": [{"name": "test.json/Enum"}],
+			"experimental_resource_declarations": [{"name": "test.json/handle"}],
+			"protocol_declarations": [{"name": "test.json/Protocol"}],
+			"service_declarations": [{"name": "test.json/Service"}],
+			"struct_declarations": [{"name": "test.json/Struct"}],
+			"external_struct_declarations": [{"name": "test.dep/Foo"}],
+			"table_declarations": [{"name": "test.json/Table"}],
+			"union_declarations": [{"name": "test.json/Union"}],
+			"type_alias_declarations": [{"name": "test.json/Alias"}],
+			"new_type_declarations": [{"name": "test.json/NewType"}],
+			"declaration_order": ["test.json/Struct"],
+			"declarations": {"test.json/Struct": "struct"},
+			"library_dependencies": [{"name": "test.dep"}]
+		}`,
+		fidlgen.Root{
+			Name:            "test.json",
+			Experiments:     fidlgen.Experiments{"output_index_json"},
+			Consts:          []fidlgen.Const{{Decl: fidlgen.Decl{Name: "test.json/SEVEN"}}},
+			Bits:            []fidlgen.Bits{{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "test.json/Bits"}}}},
+			Enums:           []fidlgen.Enum{{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "test.json/Enum"}}}},
+			Resources:       []fidlgen.Resource{{Decl: fidlgen.Decl{Name: "test.json/handle"}}},
+			Protocols:       []fidlgen.Protocol{{Decl: fidlgen.Decl{Name: "test.json/Protocol"}}},
+			Services:        []fidlgen.Service{{Decl: fidlgen.Decl{Name: "test.json/Service"}}},
+			Structs:         []fidlgen.Struct{{ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "test.json/Struct"}}}}},
+			ExternalStructs: []fidlgen.Struct{{ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "test.dep/Foo"}}}}},
+			Tables:          []fidlgen.Table{{ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "test.json/Table"}}}}},
+			Unions:          []fidlgen.Union{{ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "test.json/Union"}}}}},
+			TypeAliases:     []fidlgen.TypeAlias{{Decl: fidlgen.Decl{Name: "test.json/Alias"}}},
+			NewTypes:        []fidlgen.NewType{{Decl: fidlgen.Decl{Name: "test.json/NewType"}}},
+			DeclOrder:       []fidlgen.EncodedCompoundIdentifier{"test.json/Struct"},
+			Decls:           fidlgen.DeclMap{"test.json/Struct": fidlgen.StructDeclType},
+			Libraries:       []fidlgen.Library{{Name: "test.dep"}},
+		},
+	},
+}
+
+// unsetFields returns the names of the IR fields of v left at their zero
+// value, looking into the structs whose fields are promoted.
+func unsetFields(v reflect.Value) []string {
+	var unset []string
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, promoted := jsonName(f)
+		if promoted {
+			unset = append(unset, unsetFields(v.Field(i))...)
+		} else if name != "-" && v.Field(i).IsZero() {
+			unset = append(unset, f.Name)
+		}
+	}
+	return unset
+}
+
+func TestJSONDecode(t *testing.T) {
+	tested := make(map[reflect.Type]struct{})
+	for _, ex := range jsonDecodeCases {
+		typ := reflect.TypeOf(ex.want)
+		tested[typ] = struct{}{}
+		t.Run(typ.Name(), func(t *testing.T) {
+			got := reflect.New(typ)
+			if err := json.Unmarshal([]byte(ex.json), got.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			opt := cmp.Exporter(func(reflect.Type) bool { return true })
+			if diff := cmp.Diff(ex.want, got.Elem().Interface(), opt); diff != "" {
+				t.Errorf("unexpected decoding (-want +got):\n%s", diff)
+			}
+			if unset := unsetFields(reflect.ValueOf(ex.want)); len(unset) > 0 {
+				t.Errorf("fields not set by the test case: %s", strings.Join(unset, ", "))
+			}
+		})
+	}
+	for _, typ := range irStructTypes() {
+		if _, ok := tested[typ]; !ok && typ != typeType && typ != int64OrUint64Type {
+			t.Errorf("%s: no decode test case", typ.Name())
+		}
+	}
+}
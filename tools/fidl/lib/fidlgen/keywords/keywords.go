@@ -0,0 +1,106 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package keywords lists the reserved words of the languages FIDL bindings
+// are generated in, along with the way backends escape identifiers which
+// clash with them, so that all backends for a language agree.
+//
+// The lists only hold the words the language itself reserves. Backends add
+// the names their bindings reserve, e.g. those of runtime types, to their own
+// fidlgen.NameContext.
+package keywords
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Language is a language FIDL bindings are generated in, named as in
+// @bindings_denylist.
+type Language string
+
+const (
+	C      Language = "c"
+	Cpp    Language = "cpp"
+	Dart   Language = "dart"
+	Go     Language = "go"
+	Python Language = "python"
+	Rust   Language = "rust"
+)
+
+// language is the reserved words of a language and its escaping convention.
+type language struct {
+	words map[string]struct{}
+	// suffix is appended to reserved words to escape them.
+	suffix string
+}
+
+func newLanguage(suffix string, lists ...[]string) language {
+	l := language{words: make(map[string]struct{}), suffix: suffix}
+	for _, list := range lists {
+		for _, w := range list {
+			l.words[w] = struct{}{}
+		}
+	}
+	return l
+}
+
+var languages = map[Language]language{
+	C:      newLanguage("_", cKeywords),
+	Cpp:    newLanguage("_", cppKeywords),
+	Dart:   newLanguage("$", dartKeywords),
+	Go:     newLanguage("_", goKeywords, goPredeclared),
+	Python: newLanguage("_", pythonKeywords),
+	Rust:   newLanguage("_", rustKeywords),
+}
+
+// Languages returns the languages whose reserved words are known, sorted.
+func Languages() []Language {
+	var res []Language
+	for lang := range languages {
+		res = append(res, lang)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i] < res[j]
+	})
+	return res
+}
+
+func lookup(lang Language) language {
+	l, ok := languages[lang]
+	if !ok {
+		panic(fmt.Sprintf("unknown language %q", lang))
+	}
+	return l
+}
+
+// Reserved returns the reserved words of the language, sorted. It panics if
+// the language is unknown.
+func Reserved(lang Language) []string {
+	l := lookup(lang)
+	words := make([]string, 0, len(l.words))
+	for w := range l.words {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// IsReserved returns whether the name is reserved in the language. Names are
+// case sensitive. It panics if the language is unknown.
+func IsReserved(lang Language, name string) bool {
+	_, ok := lookup(lang).words[name]
+	return ok
+}
+
+// EscapeIdentifier returns the name, escaped the way backends for the
+// language do if it is reserved: with a trailing `$` in Dart, and a trailing
+// underscore in other languages. It panics if the language is unknown.
+func EscapeIdentifier(lang Language, name string) string {
+	l := lookup(lang)
+	if _, ok := l.words[name]; ok {
+		return name + l.suffix
+	}
+	return name
+}
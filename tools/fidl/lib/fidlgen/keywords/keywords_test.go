@@ -0,0 +1,68 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package keywords_test
+
+import (
+	"sort"
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen/keywords"
+)
+
+func TestEscapeIdentifier(t *testing.T) {
+	cases := []struct {
+		lang     keywords.Language
+		name     string
+		expected string
+	}{
+		{keywords.C, "restrict", "restrict_"},
+		{keywords.C, "class", "class"},
+		{keywords.Cpp, "class", "class_"},
+		{keywords.Cpp, "co_await", "co_await_"},
+		{keywords.Dart, "with", "with$"},
+		{keywords.Dart, "String", "String"},
+		{keywords.Go, "chan", "chan_"},
+		{keywords.Go, "uint32", "uint32_"},
+		{keywords.Python, "lambda", "lambda_"},
+		{keywords.Python, "none", "none"},
+		{keywords.Rust, "Self", "Self_"},
+		{keywords.Rust, "dyn", "dyn_"},
+		{keywords.Rust, "union", "union"},
+	}
+	for _, c := range cases {
+		if actual := keywords.EscapeIdentifier(c.lang, c.name); actual != c.expected {
+			t.Errorf("%s: got %q, want %q", c.lang, actual, c.expected)
+		}
+		if reserved := keywords.IsReserved(c.lang, c.name); reserved != (c.name != c.expected) {
+			t.Errorf("%s: got reserved %t for %q", c.lang, reserved, c.name)
+		}
+	}
+}
+
+func TestReserved(t *testing.T) {
+	for _, lang := range keywords.Languages() {
+		words := keywords.Reserved(lang)
+		if len(words) == 0 {
+			t.Errorf("%s: no reserved words", lang)
+		}
+		if !sort.StringsAreSorted(words) {
+			t.Errorf("%s: reserved words are not sorted", lang)
+		}
+		for _, w := range words {
+			if !keywords.IsReserved(lang, w) {
+				t.Errorf("%s: %q is listed but not reserved", lang, w)
+			}
+		}
+	}
+}
+
+func TestUnknownLanguage(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	keywords.IsReserved("cobol", "PERFORM")
+}
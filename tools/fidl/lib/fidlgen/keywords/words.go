@@ -0,0 +1,382 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package keywords
+
+// C keywords, as of C23, from: https://en.cppreference.com/w/c/keyword.
+var cKeywords = []string{
+	"_Alignas",
+	"_Alignof",
+	"_Atomic",
+	"_BitInt",
+	"_Bool",
+	"_Complex",
+	"_Decimal128",
+	"_Decimal32",
+	"_Decimal64",
+	"_Generic",
+	"_Imaginary",
+	"_Noreturn",
+	"_Static_assert",
+	"_Thread_local",
+	"alignas",
+	"alignof",
+	"auto",
+	"bool",
+	"break",
+	"case",
+	"char",
+	"const",
+	"constexpr",
+	"continue",
+	"default",
+	"do",
+	"double",
+	"else",
+	"enum",
+	"extern",
+	"false",
+	"float",
+	"for",
+	"goto",
+	"if",
+	"inline",
+	"int",
+	"long",
+	"nullptr",
+	"register",
+	"restrict",
+	"return",
+	"short",
+	"signed",
+	"sizeof",
+	"static",
+	"static_assert",
+	"struct",
+	"switch",
+	"thread_local",
+	"true",
+	"typedef",
+	"typeof",
+	"typeof_unqual",
+	"union",
+	"unsigned",
+	"void",
+	"volatile",
+	"while",
+}
+
+// C++ keywords from: https://en.cppreference.com/w/cpp/keyword.
+var cppKeywords = []string{
+	"alignas",
+	"alignof",
+	"and_eq",
+	"and",
+	"asm",
+	"atomic_cancel",
+	"atomic_commit",
+	"atomic_noexcept",
+	"auto",
+	"bitand",
+	"bitor",
+	"bool",
+	"break",
+	"case",
+	"catch",
+	"char",
+	"char16_t",
+	"char32_t",
+	"class",
+	"co_await",
+	"co_return",
+	"co_yield",
+	"compl",
+	"concept",
+	"const_cast",
+	"const",
+	"consteval",
+	"constexpr",
+	"constinit",
+	"continue",
+	"decltype",
+	"default",
+	"delete",
+	"do",
+	"double",
+	"dynamic_cast",
+	"else",
+	"enum",
+	"explicit",
+	"export",
+	"extern",
+	"false",
+	"float",
+	"for",
+	"friend",
+	"goto",
+	"if",
+	"inline",
+	"int",
+	"long",
+	"module",
+	"mutable",
+	"namespace",
+	"new",
+	"noexcept",
+	"not_eq",
+	"not",
+	"nullptr",
+	"operator",
+	"or_eq",
+	"or",
+	"private",
+	"protected",
+	"public",
+	"reflexpr",
+	"register",
+	"reinterpret_cast",
+	"requires",
+	"return",
+	"short",
+	"signed",
+	"sizeof",
+	"static_assert",
+	"static_cast",
+	"static",
+	"struct",
+	"switch",
+	"synchronized",
+	"template",
+	"this",
+	"thread_local",
+	"throw",
+	"true",
+	"try",
+	"typedef",
+	"typeid",
+	"typename",
+	"union",
+	"unsigned",
+	"using",
+	"virtual",
+	"void",
+	"volatile",
+	"wchar_t",
+	"while",
+	"xor_eq",
+	"xor",
+}
+
+// Dart reserved words, from: https://dart.dev/guides/language/language-tour#keywords,
+// along with the words which may not be used as identifiers in asynchronous
+// or generator functions.
+var dartKeywords = []string{
+	"assert",
+	"async",
+	"await",
+	"break",
+	"case",
+	"catch",
+	"class",
+	"const",
+	"continue",
+	"default",
+	"do",
+	"else",
+	"enum",
+	"extends",
+	"false",
+	"final",
+	"finally",
+	"for",
+	"if",
+	"in",
+	"is",
+	"new",
+	"null",
+	"rethrow",
+	"return",
+	"super",
+	"switch",
+	"this",
+	"throw",
+	"true",
+	"try",
+	"var",
+	"void",
+	"while",
+	"with",
+	"yield",
+}
+
+// Go keywords, from: https://go.dev/ref/spec#Keywords.
+var goKeywords = []string{
+	"break",
+	"case",
+	"chan",
+	"const",
+	"continue",
+	"default",
+	"defer",
+	"else",
+	"fallthrough",
+	"for",
+	"func",
+	"go",
+	"goto",
+	"if",
+	"import",
+	"interface",
+	"map",
+	"package",
+	"range",
+	"return",
+	"select",
+	"struct",
+	"switch",
+	"type",
+	"var",
+}
+
+// Go predeclared identifiers, from: https://go.dev/ref/spec#Predeclared_identifiers.
+// They may be shadowed, but generated code relies on them.
+var goPredeclared = []string{
+	"any",
+	"append",
+	"bool",
+	"byte",
+	"cap",
+	"close",
+	"comparable",
+	"complex",
+	"complex128",
+	"complex64",
+	"copy",
+	"delete",
+	"error",
+	"false",
+	"float32",
+	"float64",
+	"imag",
+	"int",
+	"int16",
+	"int32",
+	"int64",
+	"int8",
+	"iota",
+	"len",
+	"make",
+	"new",
+	"nil",
+	"panic",
+	"print",
+	"println",
+	"real",
+	"recover",
+	"rune",
+	"string",
+	"true",
+	"uint",
+	"uint16",
+	"uint32",
+	"uint64",
+	"uint8",
+	"uintptr",
+}
+
+// Python keywords, from: https://docs.python.org/3/reference/lexical_analysis.html#keywords.
+var pythonKeywords = []string{
+	"False",
+	"None",
+	"True",
+	"and",
+	"as",
+	"assert",
+	"async",
+	"await",
+	"break",
+	"class",
+	"continue",
+	"def",
+	"del",
+	"elif",
+	"else",
+	"except",
+	"finally",
+	"for",
+	"from",
+	"global",
+	"if",
+	"import",
+	"in",
+	"is",
+	"lambda",
+	"nonlocal",
+	"not",
+	"or",
+	"pass",
+	"raise",
+	"return",
+	"try",
+	"while",
+	"with",
+	"yield",
+}
+
+// Rust strict and reserved keywords, as of the 2018 edition, from:
+// https://doc.rust-lang.org/reference/keywords.html. Weak keywords, e.g.
+// `union`, are only keywords in contexts FIDL names do not appear in.
+var rustKeywords = []string{
+	"Self",
+	"abstract",
+	"as",
+	"async",
+	"await",
+	"become",
+	"box",
+	"break",
+	"const",
+	"continue",
+	"crate",
+	"do",
+	"dyn",
+	"else",
+	"enum",
+	"extern",
+	"false",
+	"final",
+	"fn",
+	"for",
+	"if",
+	"impl",
+	"in",
+	"let",
+	"loop",
+	"macro",
+	"match",
+	"mod",
+	"move",
+	"mut",
+	"override",
+	"priv",
+	"pub",
+	"ref",
+	"return",
+	"self",
+	"static",
+	"struct",
+	"super",
+	"trait",
+	"true",
+	"try",
+	"type",
+	"typeof",
+	"unsafe",
+	"unsized",
+	"use",
+	"virtual",
+	"where",
+	"while",
+	"yield",
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// LibraryRenames maps old library names to new ones. During a multi-release
+// migration from one library name to another, loading historical IR with the
+// renames applied lets analysis tools treat both names as the same library,
+// e.g. in diffs and statistics.
+type LibraryRenames map[EncodedLibraryIdentifier]EncodedLibraryIdentifier
+
+// ReadLibraryRenames reads renames from a JSON file holding an object that
+// maps old library names to new ones, e.g. `{"fuchsia.old": "fuchsia.new"}`.
+func ReadLibraryRenames(filename string) (LibraryRenames, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var renames LibraryRenames
+	if err := json.Unmarshal(b, &renames); err != nil {
+		return nil, fmt.Errorf("failed to parse library renames %s: %w", filename, err)
+	}
+	for from, to := range renames {
+		if _, ok := renames[to]; ok {
+			return nil, fmt.Errorf("library renames %s: %s is renamed to %s, which is itself renamed", filename, from, to)
+		}
+	}
+	return renames, nil
+}
+
+// RenameLibrary returns the new name of a library.
+func (m LibraryRenames) RenameLibrary(name EncodedLibraryIdentifier) EncodedLibraryIdentifier {
+	if renamed, ok := m[name]; ok {
+		return renamed
+	}
+	return name
+}
+
+// RenameDecl returns the new name of a declaration or member.
+func (m LibraryRenames) RenameDecl(name EncodedCompoundIdentifier) EncodedCompoundIdentifier {
+	if name.IsBuiltIn() {
+		return name
+	}
+	parts := name.Parts()
+	return EncodedCompoundIdentifier(fmt.Sprintf("%s/%s", m.RenameLibrary(EncodedLibraryIdentifier(parts[0])), parts[1]))
+}
+
+// Apply returns a copy of the IR of a library in which all library names
+// have been renamed, both in the library's own name and in every reference to
+// a declaration. The input is left untouched.
+func (m LibraryRenames) Apply(root Root) Root {
+	return m.rename(reflect.ValueOf(root)).Interface().(Root)
+}
+
+var (
+	encodedCompoundIdentifierType = reflect.TypeOf(EncodedCompoundIdentifier(""))
+	encodedLibraryIdentifierType  = reflect.TypeOf(EncodedLibraryIdentifier(""))
+)
+
+// rename returns a deep copy of v in which all values of type
+// EncodedCompoundIdentifier and EncodedLibraryIdentifier, including map keys,
+// have been renamed.
+func (m LibraryRenames) rename(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		switch v.Type() {
+		case encodedCompoundIdentifierType:
+			return reflect.ValueOf(m.RenameDecl(EncodedCompoundIdentifier(v.String())))
+		case encodedLibraryIdentifierType:
+			return reflect.ValueOf(m.RenameLibrary(EncodedLibraryIdentifier(v.String())))
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(m.rename(v.Elem()))
+		return p
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(m.rename(v.Index(i)))
+		}
+		return s
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(m.rename(iter.Key()), m.rename(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		// Unexported fields, which cannot be set individually, hold no
+		// identifiers and are copied as is.
+		s.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := s.Field(i); f.CanSet() {
+				f.Set(m.rename(v.Field(i)))
+			}
+		}
+		return s
+	}
+	return v
+}
+
+// NewProgramWithRenames creates a Program out of the IR of a set of
+// libraries, after applying library renames to each. Renaming must not result
+// in the same library being provided more than once.
+func NewProgramWithRenames(roots []Root, renames LibraryRenames) (*Program, error) {
+	renamed := make([]Root, 0, len(roots))
+	var names []string
+	for _, root := range roots {
+		if renames.RenameLibrary(root.Name) != root.Name {
+			names = append(names, string(root.Name))
+		}
+		renamed = append(renamed, renames.Apply(root))
+	}
+	p, err := NewProgram(renamed)
+	if err != nil && len(names) > 0 {
+		return nil, fmt.Errorf("%w (after renaming %s)", err, strings.Join(names, ", "))
+	}
+	return p, err
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestLibraryRenames(t *testing.T) {
+	renames := fidlgen.LibraryRenames{"fuchsia.old": "fuchsia.new"}
+
+	holder := structDecl("fuchsia.old/Holder", "point", "dep")
+	holder.Members[0].Type = *identifierType("fuchsia.old/Point")
+	holder.Members[1].Type = *identifierType("other/Dep")
+	old := fidlgen.Root{
+		Name:      "fuchsia.old",
+		Structs:   []fidlgen.Struct{holder, structDecl("fuchsia.old/Point")},
+		Decls:     fidlgen.DeclMap{"fuchsia.old/Holder": fidlgen.StructDeclType, "fuchsia.old/Point": fidlgen.StructDeclType},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{"fuchsia.old/Point", "fuchsia.old/Holder"},
+		Libraries: []fidlgen.Library{
+			{Name: "other", Decls: fidlgen.DeclInfoMap{"other/Dep": {Type: fidlgen.StructDeclType}}},
+		},
+	}
+
+	renamed := renames.Apply(old)
+	if renamed.Name != "fuchsia.new" {
+		t.Errorf("got library name %s", renamed.Name)
+	}
+	if diff := cmp.Diff([]fidlgen.EncodedCompoundIdentifier{"fuchsia.new/Point", "fuchsia.new/Holder"}, renamed.DeclOrder); diff != "" {
+		t.Errorf("DeclOrder: unexpected diff (-want +got):\n%s", diff)
+	}
+	if _, ok := renamed.Decls["fuchsia.new/Holder"]; !ok {
+		t.Errorf("Decls was not renamed: %v", renamed.Decls)
+	}
+	members := renamed.Structs[0].Members
+	if members[0].Type.Identifier != "fuchsia.new/Point" || members[1].Type.Identifier != "other/Dep" {
+		t.Errorf("got member types %s and %s", members[0].Type.Identifier, members[1].Type.Identifier)
+	}
+	if old.Structs[0].Members[0].Type.Identifier != "fuchsia.old/Point" || old.DeclOrder[0] != "fuchsia.old/Point" {
+		t.Errorf("Apply modified its input")
+	}
+
+	p, err := fidlgen.NewProgramWithRenames([]fidlgen.Root{old}, renames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.LookupDecl("fuchsia.new/Point"); !ok {
+		t.Errorf("fuchsia.new/Point not found in the program")
+	}
+	if _, err := fidlgen.NewProgramWithRenames([]fidlgen.Root{old, {Name: "fuchsia.new"}}, renames); err == nil {
+		t.Errorf("expected an error for a library provided twice after renaming")
+	}
+}
+
+func TestReadLibraryRenames(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	renames, err := fidlgen.ReadLibraryRenames(write("renames.json", `{"fuchsia.old": "fuchsia.new"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(fidlgen.LibraryRenames{"fuchsia.old": "fuchsia.new"}, renames); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	if _, err := fidlgen.ReadLibraryRenames(write("chained.json", `{"a": "b", "b": "c"}`)); err == nil {
+		t.Errorf("expected an error for chained renames")
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// MemberDeclaration is implemented by the members of the layout declarations:
+// StructMember, TableMember, UnionMember, EnumMember, and BitsMember. It lets
+// tools that treat members uniformly (e.g. linters and doc generators) avoid
+// handling each kind of member separately.
+type MemberDeclaration interface {
+	GetName() Identifier
+	GetAttributes() Attributes
+	// GetType returns the type of the member, or nil for the members of enums
+	// and bits, whose type is that of the enclosing declaration.
+	GetType() *Type
+	// GetOrdinal returns the ordinal of the member, for table and union
+	// members.
+	GetOrdinal() (int, bool)
+}
+
+// DeclarationWithMembers is implemented by the layout declarations which have
+// members.
+type DeclarationWithMembers interface {
+	Declaration
+	// ForEachMember calls a provided callback on each member, in declaration
+	// order. Reserved members are skipped.
+	ForEachMember(cb func(MemberDeclaration))
+}
+
+func (m *StructMember) GetName() Identifier       { return m.Name }
+func (m *StructMember) GetAttributes() Attributes { return m.Attributes }
+func (m *StructMember) GetType() *Type            { return &m.Type }
+func (m *StructMember) GetOrdinal() (int, bool)   { return 0, false }
+
+func (m *TableMember) GetName() Identifier       { return m.Name }
+func (m *TableMember) GetAttributes() Attributes { return m.Attributes }
+func (m *TableMember) GetType() *Type            { return &m.Type }
+func (m *TableMember) GetOrdinal() (int, bool)   { return m.Ordinal, true }
+
+func (m *UnionMember) GetName() Identifier       { return m.Name }
+func (m *UnionMember) GetAttributes() Attributes { return m.Attributes }
+func (m *UnionMember) GetType() *Type            { return &m.Type }
+func (m *UnionMember) GetOrdinal() (int, bool)   { return m.Ordinal, true }
+
+func (m *EnumMember) GetName() Identifier       { return m.Name }
+func (m *EnumMember) GetAttributes() Attributes { return m.Attributes }
+func (m *EnumMember) GetType() *Type            { return nil }
+func (m *EnumMember) GetOrdinal() (int, bool)   { return 0, false }
+
+func (m *BitsMember) GetName() Identifier       { return m.Name }
+func (m *BitsMember) GetAttributes() Attributes { return m.Attributes }
+func (m *BitsMember) GetType() *Type            { return nil }
+func (m *BitsMember) GetOrdinal() (int, bool)   { return 0, false }
+
+func (s *Struct) ForEachMember(cb func(MemberDeclaration)) {
+	for i := range s.Members {
+		cb(&s.Members[i])
+	}
+}
+
+func (t *Table) ForEachMember(cb func(MemberDeclaration)) {
+	for i := range t.Members {
+		if !t.Members[i].Reserved {
+			cb(&t.Members[i])
+		}
+	}
+}
+
+func (u *Union) ForEachMember(cb func(MemberDeclaration)) {
+	for i := range u.Members {
+		if !u.Members[i].Reserved {
+			cb(&u.Members[i])
+		}
+	}
+}
+
+func (e *Enum) ForEachMember(cb func(MemberDeclaration)) {
+	for i := range e.Members {
+		cb(&e.Members[i])
+	}
+}
+
+func (b *Bits) ForEachMember(cb func(MemberDeclaration)) {
+	for i := range b.Members {
+		cb(&b.Members[i])
+	}
+}
+
+var (
+	_ DeclarationWithMembers = (*Struct)(nil)
+	_ DeclarationWithMembers = (*Table)(nil)
+	_ DeclarationWithMembers = (*Union)(nil)
+	_ DeclarationWithMembers = (*Enum)(nil)
+	_ DeclarationWithMembers = (*Bits)(nil)
+)
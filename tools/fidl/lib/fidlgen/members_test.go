@@ -0,0 +1,104 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+type memberSummary struct {
+	Name    fidlgen.Identifier
+	HasType bool
+	Ordinal int
+}
+
+func summarizeMembers(decl fidlgen.DeclarationWithMembers) []memberSummary {
+	var members []memberSummary
+	decl.ForEachMember(func(m fidlgen.MemberDeclaration) {
+		ordinal, _ := m.GetOrdinal()
+		members = append(members, memberSummary{
+			Name:    m.GetName(),
+			HasType: m.GetType() != nil,
+			Ordinal: ordinal,
+		})
+	})
+	return members
+}
+
+func TestForEachMember(t *testing.T) {
+	testCases := []struct {
+		name     string
+		decl     fidlgen.DeclarationWithMembers
+		expected []memberSummary
+	}{
+		{
+			name: "struct",
+			decl: func() *fidlgen.Struct { s := structDecl("example/S", "a", "b"); return &s }(),
+			expected: []memberSummary{
+				{Name: "a", HasType: true},
+				{Name: "b", HasType: true},
+			},
+		},
+		{
+			name: "table skips reserved",
+			decl: &fidlgen.Table{
+				Members: []fidlgen.TableMember{
+					{Name: "a", Ordinal: 1},
+					{Ordinal: 2, Reserved: true},
+					{Name: "c", Ordinal: 3},
+				},
+			},
+			expected: []memberSummary{
+				{Name: "a", HasType: true, Ordinal: 1},
+				{Name: "c", HasType: true, Ordinal: 3},
+			},
+		},
+		{
+			name: "union",
+			decl: &fidlgen.Union{
+				Members: []fidlgen.UnionMember{{Name: "a", Ordinal: 1}},
+			},
+			expected: []memberSummary{
+				{Name: "a", HasType: true, Ordinal: 1},
+			},
+		},
+		{
+			name: "enum",
+			decl: &fidlgen.Enum{
+				Members: []fidlgen.EnumMember{{Name: "RED"}, {Name: "BLUE"}},
+			},
+			expected: []memberSummary{{Name: "RED"}, {Name: "BLUE"}},
+		},
+		{
+			name: "bits",
+			decl: &fidlgen.Bits{
+				Members: []fidlgen.BitsMember{{Name: "READ"}},
+			},
+			expected: []memberSummary{{Name: "READ"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, summarizeMembers(tc.decl)); diff != "" {
+				t.Errorf("unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestForEachMemberGetTypeAliasesMember(t *testing.T) {
+	s := structDecl("example/S", "a", "b")
+	s.ForEachMember(func(m fidlgen.MemberDeclaration) {
+		m.GetType().Nullable = true
+	})
+	for _, m := range s.Members {
+		if !m.Type.Nullable {
+			t.Errorf("%s: mutation through GetType was not applied to the member", m.Name)
+		}
+	}
+}
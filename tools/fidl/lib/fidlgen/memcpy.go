@@ -0,0 +1,46 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// IsMemcpyCompatible returns whether the wire representation of a type in the
+// given wire format is a plain run of bytes: it has no padding, no handles, no
+// envelopes and no out-of-line data. Vectors and arrays of such types can be
+// encoded with a bulk copy rather than element by element. The declarations the
+// type refers to are looked up in declInfo: only structs, enums and bits are
+// compatible, and only when not nullable.
+//
+// Compatibility only concerns the layout. Decoders must still validate the
+// values of bools, and of strict enums and bits, before exposing them.
+func IsMemcpyCompatible(typ Type, wireFormat WireFormatVersion, declInfo DeclInfoMap) bool {
+	shape := typ.Shape(wireFormat)
+	if shape.HasPadding || shape.MaxHandles != 0 || shape.HasEnvelope || shape.MaxOutOfLine != 0 || shape.Depth != 0 {
+		return false
+	}
+	return hasMemcpyCompatibleKind(typ, declInfo)
+}
+
+// hasMemcpyCompatibleKind returns whether a type is of a kind which can be
+// memcpy compatible, regardless of its shape.
+func hasMemcpyCompatibleKind(typ Type, declInfo DeclInfoMap) bool {
+	switch typ.Kind {
+	case PrimitiveType:
+		return true
+	case ArrayType:
+		return hasMemcpyCompatibleKind(*typ.ElementType, declInfo)
+	case IdentifierType:
+		if typ.Nullable {
+			return false
+		}
+		info, ok := declInfo[typ.Identifier]
+		if !ok {
+			return false
+		}
+		switch info.Type {
+		case StructDeclType, EnumDeclType, BitsDeclType:
+			return true
+		}
+	}
+	return false
+}
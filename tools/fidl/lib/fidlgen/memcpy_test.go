@@ -0,0 +1,59 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestIsMemcpyCompatible(t *testing.T) {
+	const wf = fidlgen.WireFormatVersionV2
+	calc := fidlgen.TypeShapeCalculator{WireFormat: wf}
+	withShape := func(typ fidlgen.Type, shape fidlgen.TypeShape) fidlgen.Type {
+		typ.SetShape(wf, shape)
+		return typ
+	}
+	primitive := func(subtype fidlgen.PrimitiveSubtype) fidlgen.Type {
+		return withShape(fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: subtype}, fidlgen.PrimitiveTypeShape(subtype))
+	}
+	identifier := func(name fidlgen.EncodedCompoundIdentifier, shape fidlgen.TypeShape) fidlgen.Type {
+		return withShape(fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: name}, shape)
+	}
+	u16, u32 := primitive(fidlgen.Uint16), primitive(fidlgen.Uint32)
+	packed, _ := calc.Struct([]fidlgen.TypeShape{u32.Shape(wf), u32.Shape(wf)})
+	padded, _ := calc.Struct([]fidlgen.TypeShape{u16.Shape(wf), u32.Shape(wf)})
+	declInfo := fidlgen.DeclInfoMap{
+		"example/Packed": {Type: fidlgen.StructDeclType},
+		"example/Padded": {Type: fidlgen.StructDeclType},
+		"example/Color":  {Type: fidlgen.EnumDeclType},
+		"example/Table":  {Type: fidlgen.TableDeclType},
+	}
+	count := 4
+	nullablePacked := identifier("example/Packed", calc.Box(packed))
+	nullablePacked.Nullable = true
+
+	for _, tc := range []struct {
+		name     string
+		typ      fidlgen.Type
+		expected bool
+	}{
+		{"primitive", u32, true},
+		{"array", withShape(fidlgen.Type{Kind: fidlgen.ArrayType, ElementType: &u16, ElementCount: &count}, calc.Array(u16.Shape(wf), count)), true},
+		{"packed struct", identifier("example/Packed", packed), true},
+		{"enum", identifier("example/Color", fidlgen.PrimitiveTypeShape(fidlgen.Uint32)), true},
+		{"padded struct", identifier("example/Padded", padded), false},
+		{"boxed struct", nullablePacked, false},
+		{"string", withShape(fidlgen.Type{Kind: fidlgen.StringType}, calc.String(nil)), false},
+		{"handle", withShape(fidlgen.Type{Kind: fidlgen.HandleType}, fidlgen.HandleTypeShape), false},
+		{"table", identifier("example/Table", calc.Table(0, nil)), false},
+		{"unknown identifier", identifier("example/Unknown", packed), false},
+	} {
+		if got := fidlgen.IsMemcpyCompatible(tc.typ, wf, declInfo); got != tc.expected {
+			t.Errorf("%s: got %t, want %t", tc.name, got, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// MethodResult describes the result union wrapping the response of a two-way
+// method which is flexible or uses error syntax, e.g. for
+//
+//	flexible Get() -> (struct { value int32; }) error uint32;
+//
+// the union "P_Get_Result" has the success type as its "response" member, the
+// error type as its "err" member, and a "framework_err" member for unknown
+// interactions.
+type MethodResult struct {
+	// ResultType is the name of the result union.
+	ResultType EncodedCompoundIdentifier
+	// SuccessType is the type of the "response" member, i.e. the return value
+	// of the method.
+	SuccessType Type
+	// ErrorType is the type of the "err" member, or nil if the method does not
+	// use error syntax.
+	ErrorType *Type
+	// HasFrameworkError is whether the union has a "framework_err" member,
+	// i.e. the method is flexible.
+	HasFrameworkError bool
+	// Synthetic is whether the union was synthesized by
+	// Root.WithSyntheticResults, and is not part of the wire format.
+	Synthetic bool
+}
+
+// HasError returns whether the method uses error syntax.
+func (r MethodResult) HasError() bool {
+	return r.ErrorType != nil
+}
+
+// HasEmptySuccess returns whether the success type is an empty struct, as for
+// `Method() -> () error uint32`. Bindings usually return nothing but the error
+// in that case. The success type is resolved through decls, and is deemed not
+// empty if it cannot be resolved.
+func (r MethodResult) HasEmptySuccess(decls DeclResolver) bool {
+	decl, ok := decls.LookupDecl(r.SuccessType.Identifier)
+	if !ok {
+		return false
+	}
+	s, ok := decl.(*Struct)
+	return ok && s.IsEmpty()
+}
+
+// Result describes the result union of the method. It returns false if the
+// method has none, i.e. it is a one-way method, an event, or a strict two-way
+// method without error syntax, and Root.WithSyntheticResults was not used.
+func (m *Method) Result() (MethodResult, bool) {
+	if m.ResultType == nil || m.ValueType == nil {
+		return MethodResult{}, false
+	}
+	return MethodResult{
+		ResultType:        m.ResultType.Identifier,
+		SuccessType:       *m.ValueType,
+		ErrorType:         m.ErrorType,
+		HasFrameworkError: m.HasFrameworkError(),
+		Synthetic:         m.SyntheticResult,
+	}, true
+}
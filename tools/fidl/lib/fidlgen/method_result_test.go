@@ -0,0 +1,88 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestMethodResult(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	methods := make(map[fidlgen.Identifier]fidlgen.Method)
+	for _, m := range root.Protocols[0].Methods {
+		methods[m.Name] = m
+	}
+	for _, name := range []fidlgen.Identifier{"OneWay", "TwoWay", "OnEvent"} {
+		m := methods[name]
+		if _, ok := m.Result(); ok {
+			t.Errorf("%s has a result", name)
+		}
+	}
+
+	flexible := methods["FlexibleTwoWay"]
+	result, ok := flexible.Result()
+	if !ok {
+		t.Fatal("FlexibleTwoWay has no result")
+	}
+	if result.ResultType != "test.openprotocol/P_FlexibleTwoWay_Result" {
+		t.Errorf("got result type %s", result.ResultType)
+	}
+	if result.SuccessType.Identifier != "test.openprotocol/P_FlexibleTwoWay_Response" {
+		t.Errorf("got success type %s", result.SuccessType.Identifier)
+	}
+	if result.HasError() || !result.HasFrameworkError || result.Synthetic {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !result.HasEmptySuccess(&root) {
+		t.Errorf("FlexibleTwoWay does not have an empty success")
+	}
+
+	synthetic, err := root.WithSyntheticResults(fidlgen.SyntheticResultsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range synthetic.Protocols[0].Methods {
+		if m.Name != "TwoWay" {
+			continue
+		}
+		result, ok := m.Result()
+		if !ok || !result.Synthetic || result.HasFrameworkError || !result.HasEmptySuccess(&synthetic) {
+			t.Errorf("unexpected synthetic result %+v, %t", result, ok)
+		}
+	}
+}
+
+func TestMethodResultError(t *testing.T) {
+	value := structDecl("example/P_Get_Response", "value")
+	root := fidlgen.Root{Structs: []fidlgen.Struct{value}}
+	m := fidlgen.Method{
+		Name:        "Get",
+		HasRequest:  true,
+		HasResponse: true,
+		HasError:    true,
+		ResultType:  identifierType("example/P_Get_Result"),
+		ValueType:   identifierType("example/P_Get_Response"),
+		ErrorType:   &fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32},
+	}
+	result, ok := m.Result()
+	if !ok {
+		t.Fatal("Get has no result")
+	}
+	if !result.HasError() || result.ErrorType.PrimitiveSubtype != fidlgen.Uint32 {
+		t.Errorf("got error type %+v", result.ErrorType)
+	}
+	if result.HasFrameworkError {
+		t.Errorf("strict method has a framework error")
+	}
+	if result.HasEmptySuccess(&root) {
+		t.Errorf("success type with a member is deemed empty")
+	}
+	if result.HasEmptySuccess(&fidlgen.Root{}) {
+		t.Errorf("unresolved success type is deemed empty")
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateMethodStrictness checks that all methods of the library carry an
+// explicit strictness. fidlc only emits it under the unknown_interactions
+// experiment for now, which is why Method.IsStrict treats a missing
+// strictness as strict; backends which only accept IR with explicit
+// strictness can check it here, or through
+// DecodeOptions.RequireMethodStrictness, instead. The error lists the
+// offending methods, with their locations when the IR gives them, in
+// declaration order.
+func (r *Root) ValidateMethodStrictness() error {
+	var errs []string
+	for _, p := range r.Protocols {
+		for _, m := range p.Methods {
+			if m.MaybeStrict != nil {
+				continue
+			}
+			where := fmt.Sprintf("%s.%s", p.Name, m.Name)
+			if m.Location.Filename != "" {
+				where = fmt.Sprintf("%s:%d:%d: %s", m.Location.Filename, m.Location.Line, m.Location.Column, where)
+			}
+			errs = append(errs, where)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("methods without an explicit strictness:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
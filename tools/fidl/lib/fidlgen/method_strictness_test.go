@@ -0,0 +1,50 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestValidateMethodStrictness(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	if err := root.ValidateMethodStrictness(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	root.Protocols[0].Methods[0].MaybeStrict = nil
+	err := root.ValidateMethodStrictness()
+	if err == nil {
+		t.Fatal("expected an error for a method without strictness")
+	}
+	if expected := "open_protocol.test.fidl:4:14: test.openprotocol/P.OneWay"; !strings.Contains(err.Error(), expected) {
+		t.Errorf("got error %q, want it to mention %q", err, expected)
+	}
+}
+
+func TestDecodeRequiringMethodStrictness(t *testing.T) {
+	b, err := fs.ReadFile(irtestdata.FS(), irtestdata.OpenProtocol+".fidl.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := fidlgen.DecodeOptions{RequireMethodStrictness: true}
+	if _, err := fidlgen.DecodeJSONIrWithOptions(bytes.NewReader(b), opts); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	withoutStrictness := bytes.Replace(b, []byte(`"strict": false,`), nil, 1)
+	if _, err := fidlgen.DecodeJSONIrWithOptions(bytes.NewReader(withoutStrictness), fidlgen.DecodeOptions{}); err != nil {
+		t.Errorf("unexpected error without the option: %s", err)
+	}
+	if _, err := fidlgen.DecodeJSONIrWithOptions(bytes.NewReader(withoutStrictness), opts); err == nil {
+		t.Errorf("expected an error for a method without strictness")
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Migrating users off a deprecated type takes a number of releases. During
+// the migration, teams want to measure how many declarations still use the
+// deprecated types, and backends may generate shim aliases from a library in
+// which uses have already been moved to the replacements.
+
+// TypeReplacements maps deprecated declarations to the declarations replacing
+// them, possibly in other libraries.
+type TypeReplacements map[EncodedCompoundIdentifier]EncodedCompoundIdentifier
+
+// ReadTypeReplacements reads replacements from a JSON file holding an object
+// that maps deprecated declarations to their replacements, e.g.
+// `{"fuchsia.old/Thing": "fuchsia.new/Thing"}`.
+func ReadTypeReplacements(filename string) (TypeReplacements, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var replacements TypeReplacements
+	if err := json.Unmarshal(b, &replacements); err != nil {
+		return nil, fmt.Errorf("failed to parse type replacements %s: %w", filename, err)
+	}
+	for from, to := range replacements {
+		if _, ok := replacements[to]; ok {
+			return nil, fmt.Errorf("type replacements %s: %s is replaced by %s, which is itself replaced", filename, from, to)
+		}
+	}
+	return replacements, nil
+}
+
+// DeprecatedUsage reports on the uses of a deprecated declaration.
+type DeprecatedUsage struct {
+	// Deprecated is the name of the deprecated declaration.
+	Deprecated EncodedCompoundIdentifier
+	// Replacement is the name of the declaration replacing it, if known.
+	Replacement EncodedCompoundIdentifier
+	// Note is the deprecation note of the declaration, if it is declared in
+	// the library and has one.
+	Note string
+	// Users lists the declarations of the library referring to the deprecated
+	// declaration, sorted. It is empty once the migration is complete.
+	Users []EncodedCompoundIdentifier
+}
+
+// MigrationReport reports on the uses of deprecated declarations in a library,
+// sorted by deprecated declaration.
+type MigrationReport []DeprecatedUsage
+
+// RemainingUses returns the number of references to deprecated declarations
+// left, counting each user of each deprecated declaration once.
+func (report MigrationReport) RemainingUses() int {
+	n := 0
+	for _, usage := range report {
+		n += len(usage.Users)
+	}
+	return n
+}
+
+// Report reports on the uses of deprecated declarations in a library: those
+// which are replaced, and those of the library which are marked deprecated,
+// with @deprecated or @available.
+func (m TypeReplacements) Report(r *Root) MigrationReport {
+	usages := make(map[EncodedCompoundIdentifier]*DeprecatedUsage)
+	for deprecated, replacement := range m {
+		usages[deprecated] = &DeprecatedUsage{Deprecated: deprecated, Replacement: replacement}
+	}
+	r.ForEachDecl(func(decl Declaration) {
+		if !decl.IsDeprecated() || decl.GetName().LibraryName() != r.Name {
+			return
+		}
+		usage, ok := usages[decl.GetName()]
+		if !ok {
+			usage = &DeprecatedUsage{Deprecated: decl.GetName()}
+			usages[decl.GetName()] = usage
+		}
+		usage.Note = decl.DeprecationNote()
+	})
+
+	r.ForEachDecl(func(decl Declaration) {
+		var refs declReferences
+		refs.addDecl(decl)
+		seen := make(map[EncodedCompoundIdentifier]struct{})
+		for _, name := range refs {
+			if _, ok := seen[name]; ok || name == decl.GetName() {
+				continue
+			}
+			seen[name] = struct{}{}
+			if usage, ok := usages[name]; ok {
+				usage.Users = append(usage.Users, decl.GetName())
+			}
+		}
+	})
+
+	var report MigrationReport
+	for _, usage := range usages {
+		sortNames(usage.Users)
+		report = append(report, *usage)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Deprecated < report[j].Deprecated
+	})
+	return report
+}
+
+// Apply returns a copy of the IR of a library in which the types and type
+// constructors referring to replaced declarations refer to their replacements
+// instead. The replaced declarations themselves are kept, so that backends may
+// generate shims for them. Type shapes are left as is: replacements are
+// expected to share the layout of the declarations they replace. The input is
+// left untouched.
+func (m TypeReplacements) Apply(root Root) Root {
+	// Renaming no library deep-copies the IR.
+	res := LibraryRenames(nil).Apply(root)
+	replace := func(name *EncodedCompoundIdentifier) {
+		if replacement, ok := m[*name]; ok {
+			*name = replacement
+		}
+	}
+	res.forEachTopLevelType(func(typ *Type) {
+		WalkType(typ, func(typ *Type) bool {
+			switch typ.Kind {
+			case IdentifierType:
+				replace(&typ.Identifier)
+			case RequestType:
+				replace(&typ.RequestSubtype)
+			}
+			if typ.Endpoint != nil {
+				replace(&typ.Endpoint.Protocol)
+			}
+			return true
+		})
+	})
+	var replaceCtor func(ctor *PartialTypeConstructor)
+	replaceCtor = func(ctor *PartialTypeConstructor) {
+		if ctor == nil {
+			return
+		}
+		replace(&ctor.Name)
+		for i := range ctor.Args {
+			replaceCtor(&ctor.Args[i])
+		}
+	}
+	res.ForEachDecl(func(decl Declaration) {
+		switch decl := decl.(type) {
+		case *Struct:
+			for i := range decl.Members {
+				replaceCtor(decl.Members[i].MaybeTypeAlias)
+			}
+		case *Table:
+			for i := range decl.Members {
+				replaceCtor(decl.Members[i].MaybeTypeAlias)
+			}
+		case *Union:
+			for i := range decl.Members {
+				replaceCtor(decl.Members[i].MaybeTypeAlias)
+			}
+		case *TypeAlias:
+			replaceCtor(&decl.PartialTypeConstructor)
+		case *NewType:
+			replaceCtor(decl.Alias)
+		}
+	})
+	return res
+}
@@ -0,0 +1,104 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func deprecated(note string) fidlgen.Attributes {
+	return fidlgen.Attributes{Attributes: []fidlgen.Attribute{{
+		Name: "deprecated",
+		Args: []fidlgen.AttributeArg{{
+			Name:  "value",
+			Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: note},
+		}},
+	}}}
+}
+
+func TestTypeReplacements(t *testing.T) {
+	replacements := fidlgen.TypeReplacements{
+		"example/Old":  "example/New",
+		"other/Legacy": "other/Modern",
+	}
+	old := structDecl("example/Old")
+	old.Attributes = deprecated("use New")
+	unused := structDecl("example/Unused")
+	unused.Attributes = deprecated("")
+	holder := structDecl("example/Holder", "old", "legacy")
+	holder.Members[0].Type = *identifierType("example/Old")
+	holder.Members[1].Type = fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifierType("other/Legacy")}
+	other := structDecl("example/Other", "old")
+	other.Members[0].Type = *identifierType("example/Old")
+	other.Members[0].Type.Nullable = true
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{old, structDecl("example/New"), unused, holder, other},
+	}
+
+	expected := fidlgen.MigrationReport{
+		{
+			Deprecated:  "example/Old",
+			Replacement: "example/New",
+			Note:        "use New",
+			Users:       []fidlgen.EncodedCompoundIdentifier{"example/Holder", "example/Other"},
+		},
+		{Deprecated: "example/Unused"},
+		{
+			Deprecated:  "other/Legacy",
+			Replacement: "other/Modern",
+			Users:       []fidlgen.EncodedCompoundIdentifier{"example/Holder"},
+		},
+	}
+	report := replacements.Report(&root)
+	if diff := cmp.Diff(expected, report); diff != "" {
+		t.Errorf("report (-want +got):\n%s", diff)
+	}
+	if got := report.RemainingUses(); got != 3 {
+		t.Errorf("got %d remaining uses, want 3", got)
+	}
+
+	migrated := replacements.Apply(root)
+	if got := len(migrated.Structs); got != len(root.Structs) {
+		t.Errorf("got %d structs, want %d", got, len(root.Structs))
+	}
+	members := migrated.Structs[3].Members
+	if members[0].Type.Identifier != "example/New" || members[1].Type.ElementType.Identifier != "other/Modern" {
+		t.Errorf("got member types %s and vector<%s>", members[0].Type.Identifier, members[1].Type.ElementType.Identifier)
+	}
+	if root.Structs[3].Members[1].Type.ElementType.Identifier != "other/Legacy" {
+		t.Errorf("Apply modified its input")
+	}
+	if got := replacements.Report(&migrated).RemainingUses(); got != 0 {
+		t.Errorf("got %d remaining uses after migrating, want 0", got)
+	}
+}
+
+func TestReadTypeReplacements(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	replacements, err := fidlgen.ReadTypeReplacements(write("replacements.json", `{"a/Old": "a/New"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(fidlgen.TypeReplacements{"a/Old": "a/New"}, replacements); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	if _, err := fidlgen.ReadTypeReplacements(write("chained.json", `{"a/A": "a/B", "a/B": "a/C"}`)); err == nil {
+		t.Errorf("expected an error for chained replacements")
+	}
+}
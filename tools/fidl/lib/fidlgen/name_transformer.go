@@ -0,0 +1,184 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"strings"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen/keywords"
+)
+
+// Each backend converts the names of FIDL elements to the case its language's
+// style guide requires, and escapes those clashing with reserved words. A
+// NameTransformer captures those conversions, so that the utilities of this
+// package which produce names, e.g. of anonymous layouts, method parameters or
+// doc comment links, follow the conventions of a language.
+//
+// The transformers of this package only apply the case conversions of a
+// language and escape the keywords of package keywords. They approximate the
+// names the bindings generate, which have rules of their own: fidlgen_rust
+// also reserves prelude names such as `Result` and `Vec` and names ending in
+// suffixes of generated types such as `Proxy`, and escapes names before
+// converting their case; fidlgen_go reserves the names of builtin types.
+// Backends needing the exact names implement NameTransformer themselves, as
+// fidlgen_rust does with codegen.Names.
+
+// NameTransformer converts the names of FIDL elements to identifiers of a
+// language. Names are unqualified: qualifying them, e.g. with a namespace or a
+// package, is left to backends.
+type NameTransformer interface {
+	// DeclName converts the name of a type, protocol or service declaration.
+	DeclName(name Identifier) string
+	// MemberName converts the name of a member of a struct, table, union or
+	// service.
+	MemberName(name Identifier) string
+	// EnumMemberName converts the name of a member of an enum.
+	EnumMemberName(name Identifier) string
+	// BitsMemberName converts the name of a member of a bits.
+	BitsMemberName(name Identifier) string
+	// ConstName converts the name of a constant declaration.
+	ConstName(name Identifier) string
+	// MethodName converts the name of a method.
+	MethodName(name Identifier) string
+}
+
+// CaseNameTransformer is a NameTransformer converting names with the case
+// conversions of this package, then escaping reserved words.
+type CaseNameTransformer struct {
+	// Decl, Member, EnumMember, BitsMember, Const and Method convert the
+	// names of each kind of element. Nil functions leave names unchanged.
+	Decl, Member, EnumMember, BitsMember, Const, Method func(string) string
+	// Language, if set, is the language whose reserved words are escaped,
+	// as by keywords.EscapeIdentifier.
+	Language keywords.Language
+}
+
+var (
+	// FIDLNames leaves names as written in FIDL.
+	FIDLNames = CaseNameTransformer{}
+	// CppNames converts names to the case of the unified and wire C++
+	// bindings, escaping C++ keywords.
+	CppNames = CaseNameTransformer{
+		Decl:       ToUpperCamelCase,
+		Member:     ToSnakeCase,
+		EnumMember: ConstNameToKCamelCase,
+		BitsMember: ConstNameToKCamelCase,
+		Const:      ConstNameToKCamelCase,
+		Method:     ToUpperCamelCase,
+		Language:   keywords.Cpp,
+	}
+	// DartNames converts names to the case of the Dart bindings, escaping
+	// Dart keywords.
+	DartNames = CaseNameTransformer{
+		Decl:       ToUpperCamelCase,
+		Member:     ToLowerCamelCase,
+		EnumMember: ToLowerCamelCase,
+		BitsMember: ToLowerCamelCase,
+		Const:      ToLowerCamelCase,
+		Method:     ToLowerCamelCase,
+		Language:   keywords.Dart,
+	}
+	// GoNames converts names to the case of the Go bindings, escaping Go
+	// keywords. The Go bindings prefix the names of enum and bits members with
+	// that of their type, which is left to backends.
+	GoNames = CaseNameTransformer{
+		Decl:       ToUpperCamelCase,
+		Member:     ToUpperCamelCase,
+		EnumMember: ToUpperCamelCase,
+		BitsMember: ToUpperCamelCase,
+		Const:      ToUpperCamelCase,
+		Method:     ToUpperCamelCase,
+		Language:   keywords.Go,
+	}
+	// RustNames converts names to the case of the Rust bindings, escaping
+	// Rust keywords.
+	RustNames = CaseNameTransformer{
+		Decl:       ToUpperCamelCase,
+		Member:     ToSnakeCase,
+		EnumMember: ToUpperCamelCase,
+		BitsMember: ConstNameToAllCapsSnake,
+		Const:      ConstNameToAllCapsSnake,
+		Method:     ToSnakeCase,
+		Language:   keywords.Rust,
+	}
+)
+
+var _ NameTransformer = CaseNameTransformer{}
+
+func (t CaseNameTransformer) transform(convert func(string) string, name Identifier) string {
+	s := string(name)
+	if convert != nil {
+		s = convert(s)
+	}
+	if t.Language != "" {
+		s = keywords.EscapeIdentifier(t.Language, s)
+	}
+	return s
+}
+
+func (t CaseNameTransformer) DeclName(name Identifier) string {
+	return t.transform(t.Decl, name)
+}
+
+func (t CaseNameTransformer) MemberName(name Identifier) string {
+	return t.transform(t.Member, name)
+}
+
+func (t CaseNameTransformer) EnumMemberName(name Identifier) string {
+	return t.transform(t.EnumMember, name)
+}
+
+func (t CaseNameTransformer) BitsMemberName(name Identifier) string {
+	return t.transform(t.BitsMember, name)
+}
+
+func (t CaseNameTransformer) ConstName(name Identifier) string {
+	return t.transform(t.Const, name)
+}
+
+func (t CaseNameTransformer) MethodName(name Identifier) string {
+	return t.transform(t.Method, name)
+}
+
+// FlattenNamingContext returns a function flattening naming contexts into
+// declaration names with t, for AnonymousLayoutNames, e.g. `FooBar` for
+// ["Foo", "bar"] with CppNames. Parts are joined with underscores before
+// being converted, so that case conversions see the boundaries between them.
+func FlattenNamingContext(t NameTransformer) func(NamingContext) string {
+	return func(nc NamingContext) string {
+		return t.DeclName(Identifier(strings.Join(nc, "_")))
+	}
+}
+
+// ParameterNames returns the names of the parameters of a flattened payload,
+// converted with t.
+func (p MethodParameters) ParameterNames(t NameTransformer) []string {
+	var names []string
+	for _, param := range p.Parameters {
+		names = append(names, t.MemberName(param.Name))
+	}
+	return names
+}
+
+// LinkLocalDecls returns a doc comment reference renderer that links
+// references to declarations of the same library using the given format,
+// given the declaration name converted with t, and renders other references
+// as code. decls gives the declarations of the library, e.g. Root.Decls, so
+// that constants are converted with ConstName; references to names missing
+// from it are rendered as code, as there is nothing to link them to.
+func LinkLocalDecls(format string, t NameTransformer, decls DeclMap) func(string, DocReference) string {
+	return func(text string, ref DocReference) string {
+		if ci := ref.Name.Parse(); ref.Local && ci.Member == "" {
+			if declType, ok := decls[ref.Name]; ok {
+				if declType == ConstDeclType {
+					return fmt.Sprintf(format, t.ConstName(ci.Name))
+				}
+				return fmt.Sprintf(format, t.DeclName(ci.Name))
+			}
+		}
+		return fmt.Sprintf("`%s`", text)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestNameTransformers(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		transformer fidlgen.NameTransformer
+		decl        string
+		member      string
+		enumMember  string
+		bitsMember  string
+		constant    string
+		method      string
+	}{
+		{"fidl", fidlgen.FIDLNames, "my_decl", "class", "BLUE_GREEN", "BLUE_GREEN", "MAX_SIZE", "do_it"},
+		{"cpp", fidlgen.CppNames, "MyDecl", "class_", "kBlueGreen", "kBlueGreen", "kMaxSize", "DoIt"},
+		{"dart", fidlgen.DartNames, "MyDecl", "class$", "blueGreen", "blueGreen", "maxSize", "doIt"},
+		{"go", fidlgen.GoNames, "MyDecl", "Class", "BlueGreen", "BlueGreen", "MaxSize", "DoIt"},
+		{"rust", fidlgen.RustNames, "MyDecl", "class", "BlueGreen", "BLUE_GREEN", "MAX_SIZE", "do_it"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.transformer.DeclName("my_decl"); actual != tc.decl {
+				t.Errorf("decl: got %q, want %q", actual, tc.decl)
+			}
+			if actual := tc.transformer.MemberName("class"); actual != tc.member {
+				t.Errorf("member: got %q, want %q", actual, tc.member)
+			}
+			if actual := tc.transformer.EnumMemberName("BLUE_GREEN"); actual != tc.enumMember {
+				t.Errorf("enum member: got %q, want %q", actual, tc.enumMember)
+			}
+			if actual := tc.transformer.BitsMemberName("BLUE_GREEN"); actual != tc.bitsMember {
+				t.Errorf("bits member: got %q, want %q", actual, tc.bitsMember)
+			}
+			if actual := tc.transformer.ConstName("MAX_SIZE"); actual != tc.constant {
+				t.Errorf("const: got %q, want %q", actual, tc.constant)
+			}
+			if actual := tc.transformer.MethodName("do_it"); actual != tc.method {
+				t.Errorf("method: got %q, want %q", actual, tc.method)
+			}
+		})
+	}
+
+	if actual := fidlgen.RustNames.MemberName("type"); actual != "type_" {
+		t.Errorf("got %q, want a reserved Rust member name to be escaped", actual)
+	}
+}
+
+func TestFlattenNamingContext(t *testing.T) {
+	root := fidlgen.Root{
+		Name: "example",
+		Structs: []fidlgen.Struct{
+			anonymousStruct("example/A", "Protocol", "do_it", "Request"),
+			anonymousStruct("example/B", "ProtocolDo", "itRequest"),
+		},
+	}
+	expected := map[fidlgen.EncodedCompoundIdentifier]string{
+		"example/A": "ProtocolDoItRequest",
+		"example/B": "ProtocolDoItRequest2",
+	}
+	if diff := cmp.Diff(expected, root.AnonymousLayoutNames(fidlgen.FlattenNamingContext(fidlgen.CppNames))); diff != "" {
+		t.Errorf("unexpected names (-want +got):\n%s", diff)
+	}
+}
+
+func TestParameterNames(t *testing.T) {
+	root := fidlgen.Root{Structs: []fidlgen.Struct{structDecl("example/Args", "first_value", "type")}}
+	m := fidlgen.Method{Name: "Add", RequestPayload: identifierType("example/Args")}
+	params, err := m.RequestParameters(&root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"firstValue", "type"}, params.ParameterNames(fidlgen.DartNames)); diff != "" {
+		t.Errorf("unexpected Dart names (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"first_value", "type_"}, params.ParameterNames(fidlgen.RustNames)); diff != "" {
+		t.Errorf("unexpected Rust names (-want +got):\n%s", diff)
+	}
+}
+
+func TestLinkLocalDecls(t *testing.T) {
+	d := fidlgen.ParseDocComment([]string{" See [my_decl], [MAX_SIZE], [my_decl.member], [missing] and [other/Decl]."}, "example")
+	decls := fidlgen.DeclMap{
+		"example/my_decl":  fidlgen.StructDeclType,
+		"example/MAX_SIZE": fidlgen.ConstDeclType,
+	}
+	for _, tc := range []struct {
+		name      string
+		reference func(string, fidlgen.DocReference) string
+		expected  string
+	}{
+		{
+			name:      "go",
+			reference: fidlgen.LinkLocalDecls("[%s]", fidlgen.GoNames, decls),
+			expected:  "// See [MyDecl], [MaxSize], `my_decl.member`, `missing` and `other/Decl`.",
+		},
+		{
+			name:      "rust",
+			reference: fidlgen.LinkLocalDecls("[`%s`]", fidlgen.RustNames, decls),
+			expected:  "// See [`MyDecl`], [`MAX_SIZE`], `my_decl.member`, `missing` and `other/Decl`.",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := fidlgen.DocCommentRenderer{Prefix: "//", Reference: tc.reference}
+			if diff := cmp.Diff([]string{tc.expected}, r.Render(d)); diff != "" {
+				t.Errorf("unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
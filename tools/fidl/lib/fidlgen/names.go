@@ -11,10 +11,11 @@ import (
 )
 
 // The functions in this file convert names between snake_case, ALL_CAPS_SNAKE,
-// UpperCamelCase, lowerCamelCase, kCamelCase, and "friendly case". They accept
-// inputs in any style, split into parts, and recombine with the desired style.
-// See "RFC-0040: Identifier Uniqueness" for details on how name-part boundaries
-// are calculated.
+// UpperCamelCase, lowerCamelCase, kCamelCase, kebab-case, and "friendly case".
+// They accept inputs in any style, split into parts, and recombine with the
+// desired style. See "RFC-0040: Identifier Uniqueness" for details on how
+// name-part boundaries are calculated. Acronyms converts names the same way,
+// spelling acronyms as a language's style guide requires.
 //
 // TODO(fxbug.dev/95218): Fix any discrepancies with RFC-0040. In particular,
 // ensure that all these functions are idempotent.
@@ -134,3 +135,150 @@ func RemoveLeadingK(name string) string {
 	}
 	return name
 }
+
+// ToScreamingSnakeCase converts an identifier to SCREAMING_SNAKE_CASE style.
+// Works independent of which case the identifier is originally in. Unlike
+// ConstNameToAllCapsSnake, a leading 'k' is kept.
+func ToScreamingSnakeCase(name string) string {
+	parts := nameParts(name)
+	for i := range parts {
+		parts[i] = strings.ToUpper(parts[i])
+	}
+	return strings.Join(parts, "_")
+}
+
+// ToKebabCase converts an identifier to kebab-case style. Works independent of
+// which case the identifier is originally in.
+func ToKebabCase(name string) string {
+	parts := nameParts(name)
+	for i := range parts {
+		parts[i] = strings.ToLower(parts[i])
+	}
+	return strings.Join(parts, "-")
+}
+
+// Acronyms is a dictionary of acronyms, such as "VMO" or "IPv6", which keep
+// their spelling in camel case rather than being title cased, as style guides
+// of some languages require. Name parts spelling an acronym, in any case, are
+// recognized as one part, e.g. "IPv6Address", "ipv6_address" and
+// "Ipv6Address" all have the parts "IPv6" and "Address". The zero value has
+// no acronyms, and converts names as the functions above do.
+type Acronyms struct {
+	// spellings maps acronyms in lower case to their spelling.
+	spellings map[string]string
+}
+
+// NewAcronyms returns a dictionary of the given acronyms, as spelled in camel
+// case.
+func NewAcronyms(spellings ...string) Acronyms {
+	a := Acronyms{spellings: make(map[string]string, len(spellings))}
+	for _, s := range spellings {
+		a.spellings[strings.ToLower(s)] = s
+	}
+	return a
+}
+
+// acronymPart is a name part, which may be an acronym.
+type acronymPart struct {
+	text    string
+	acronym bool
+}
+
+// nameParts breaks an identifier into parts like nameParts, joining runs of
+// parts within an underscore-separated segment which spell an acronym. The
+// longest run is taken, so "IPv6" is preferred to "IP" if both are acronyms.
+func (a Acronyms) nameParts(name string) []acronymPart {
+	var parts []acronymPart
+	for _, segment := range strings.Split(name, "_") {
+		segmentParts := nameParts(segment)
+		for i := 0; i < len(segmentParts); {
+			j := len(segmentParts)
+			for ; j > i; j-- {
+				if spelling, ok := a.spellings[strings.ToLower(strings.Join(segmentParts[i:j], ""))]; ok {
+					parts = append(parts, acronymPart{text: spelling, acronym: true})
+					break
+				}
+			}
+			if j == i {
+				parts = append(parts, acronymPart{text: segmentParts[i]})
+				j = i + 1
+			}
+			i = j
+		}
+	}
+	return parts
+}
+
+// join converts the parts of a name with convert and joins them with sep.
+func (a Acronyms) join(name string, sep string, convert func(i int, p acronymPart) string) string {
+	parts := a.nameParts(name)
+	converted := make([]string, len(parts))
+	for i, p := range parts {
+		converted[i] = convert(i, p)
+	}
+	return strings.Join(converted, sep)
+}
+
+// ToSnakeCase converts an identifier to snake_case style, like ToSnakeCase,
+// keeping acronyms in one part.
+func (a Acronyms) ToSnakeCase(name string) string {
+	return a.join(name, "_", func(_ int, p acronymPart) string {
+		return strings.ToLower(p.text)
+	})
+}
+
+// ToScreamingSnakeCase converts an identifier to SCREAMING_SNAKE_CASE style,
+// like ToScreamingSnakeCase, keeping acronyms in one part.
+func (a Acronyms) ToScreamingSnakeCase(name string) string {
+	return a.join(name, "_", func(_ int, p acronymPart) string {
+		return strings.ToUpper(p.text)
+	})
+}
+
+// ToKebabCase converts an identifier to kebab-case style, like ToKebabCase,
+// keeping acronyms in one part.
+func (a Acronyms) ToKebabCase(name string) string {
+	return a.join(name, "-", func(_ int, p acronymPart) string {
+		return strings.ToLower(p.text)
+	})
+}
+
+// ToUpperCamelCase converts an identifier to UpperCamelCase style, like
+// ToUpperCamelCase, spelling acronyms as in the dictionary, e.g.
+// "ipv6_address" becomes "IPv6Address".
+func (a Acronyms) ToUpperCamelCase(name string) string {
+	return a.join(name, "", func(_ int, p acronymPart) string {
+		if p.acronym {
+			return p.text
+		}
+		if p.text == "" {
+			return "_"
+		}
+		return strings.Title(strings.ToLower(p.text))
+	})
+}
+
+// ToLowerCamelCase converts an identifier to lowerCamelCase style, like
+// ToLowerCamelCase, spelling acronyms as in the dictionary but for a leading
+// one, which is in lower case, e.g. "vmo_ipv6" becomes "vmoIPv6".
+func (a Acronyms) ToLowerCamelCase(name string) string {
+	return a.join(name, "", func(i int, p acronymPart) string {
+		switch {
+		case i == 0:
+			p.text = strings.ToLower(p.text)
+		case p.acronym:
+		default:
+			p.text = strings.Title(strings.ToLower(p.text))
+		}
+		if p.text == "" {
+			return "_"
+		}
+		return p.text
+	})
+}
+
+// ToConstCase converts a const name to kCamelCase style, like
+// ConstNameToKCamelCase, spelling acronyms as in the dictionary.
+func (a Acronyms) ToConstCase(name string) string {
+	return "k" + a.ToUpperCamelCase(RemoveLeadingK(name))
+}
@@ -350,3 +350,155 @@ func TestRemoveLeadingK(t *testing.T) {
 		}
 	}
 }
+
+func TestToScreamingSnakeCase(t *testing.T) {
+	type testCase struct {
+		input  string
+		output string
+	}
+	tests := []testCase{
+		{
+			input:  "kCamelCase",
+			output: "K_CAMEL_CASE",
+		},
+		{
+			input:  "HTTPExample",
+			output: "HTTP_EXAMPLE",
+		},
+		{
+			input:  "snake_case1",
+			output: "SNAKE_CASE1",
+		},
+	}
+	for _, test := range tests {
+		output := ToScreamingSnakeCase(test.input)
+		if output != test.output {
+			t.Errorf("input %q produced unexpected output. got %q, want %q", test.input, output, test.output)
+		}
+	}
+}
+
+func TestToKebabCase(t *testing.T) {
+	type testCase struct {
+		input  string
+		output string
+	}
+	tests := []testCase{
+		{
+			input:  "UpperCamelCase",
+			output: "upper-camel-case",
+		},
+		{
+			input:  "HTTPExample",
+			output: "http-example",
+		},
+		{
+			input:  "snake_case1",
+			output: "snake-case1",
+		},
+	}
+	for _, test := range tests {
+		output := ToKebabCase(test.input)
+		if output != test.output {
+			t.Errorf("input %q produced unexpected output. got %q, want %q", test.input, output, test.output)
+		}
+	}
+}
+
+func TestAcronyms(t *testing.T) {
+	acronyms := NewAcronyms("VMO", "IP", "IPv6")
+	type testCase struct {
+		input          string
+		upperCamelCase string
+		lowerCamelCase string
+		snakeCase      string
+		screamingSnake string
+		kebabCase      string
+		constCase      string
+	}
+	tests := []testCase{
+		{
+			input:          "ipv6_address",
+			upperCamelCase: "IPv6Address",
+			lowerCamelCase: "ipv6Address",
+			snakeCase:      "ipv6_address",
+			screamingSnake: "IPV6_ADDRESS",
+			kebabCase:      "ipv6-address",
+			constCase:      "kIPv6Address",
+		},
+		{
+			input:          "IPv6Address",
+			upperCamelCase: "IPv6Address",
+			lowerCamelCase: "ipv6Address",
+			snakeCase:      "ipv6_address",
+			screamingSnake: "IPV6_ADDRESS",
+			kebabCase:      "ipv6-address",
+			constCase:      "kIPv6Address",
+		},
+		{
+			input:          "get_vmo_ip",
+			upperCamelCase: "GetVMOIP",
+			lowerCamelCase: "getVMOIP",
+			snakeCase:      "get_vmo_ip",
+			screamingSnake: "GET_VMO_IP",
+			kebabCase:      "get-vmo-ip",
+			constCase:      "kGetVMOIP",
+		},
+		{
+			input:          "kVmoSize",
+			upperCamelCase: "KVMOSize",
+			lowerCamelCase: "kVMOSize",
+			snakeCase:      "k_vmo_size",
+			screamingSnake: "K_VMO_SIZE",
+			kebabCase:      "k-vmo-size",
+			constCase:      "kVMOSize",
+		},
+		{
+			input:          "vmos_ip_",
+			upperCamelCase: "VmosIP_",
+			lowerCamelCase: "vmosIP_",
+			snakeCase:      "vmos_ip_",
+			screamingSnake: "VMOS_IP_",
+			kebabCase:      "vmos-ip-",
+			constCase:      "kVmosIP_",
+		},
+	}
+	for _, test := range tests {
+		if output := acronyms.ToUpperCamelCase(test.input); output != test.upperCamelCase {
+			t.Errorf("ToUpperCamelCase(%q): got %q, want %q", test.input, output, test.upperCamelCase)
+		}
+		if output := acronyms.ToLowerCamelCase(test.input); output != test.lowerCamelCase {
+			t.Errorf("ToLowerCamelCase(%q): got %q, want %q", test.input, output, test.lowerCamelCase)
+		}
+		if output := acronyms.ToSnakeCase(test.input); output != test.snakeCase {
+			t.Errorf("ToSnakeCase(%q): got %q, want %q", test.input, output, test.snakeCase)
+		}
+		if output := acronyms.ToScreamingSnakeCase(test.input); output != test.screamingSnake {
+			t.Errorf("ToScreamingSnakeCase(%q): got %q, want %q", test.input, output, test.screamingSnake)
+		}
+		if output := acronyms.ToKebabCase(test.input); output != test.kebabCase {
+			t.Errorf("ToKebabCase(%q): got %q, want %q", test.input, output, test.kebabCase)
+		}
+		if output := acronyms.ToConstCase(test.input); output != test.constCase {
+			t.Errorf("ToConstCase(%q): got %q, want %q", test.input, output, test.constCase)
+		}
+	}
+}
+
+func TestAcronymsZeroValue(t *testing.T) {
+	var acronyms Acronyms
+	for _, input := range []string{"", "_", "a__b", "HTTPExample", "snake_case1", "kCamelCase", "x_"} {
+		if output, expected := acronyms.ToUpperCamelCase(input), ToUpperCamelCase(input); output != expected {
+			t.Errorf("ToUpperCamelCase(%q): got %q, want %q", input, output, expected)
+		}
+		if output, expected := acronyms.ToLowerCamelCase(input), ToLowerCamelCase(input); output != expected {
+			t.Errorf("ToLowerCamelCase(%q): got %q, want %q", input, output, expected)
+		}
+		if output, expected := acronyms.ToSnakeCase(input), ToSnakeCase(input); output != expected {
+			t.Errorf("ToSnakeCase(%q): got %q, want %q", input, output, expected)
+		}
+		if output, expected := acronyms.ToConstCase(input), ConstNameToKCamelCase(input); output != expected {
+			t.Errorf("ToConstCase(%q): got %q, want %q", input, output, expected)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// Under the allow_new_types experiment, `type Id = uint64;` declares a new
+// type, which shares the wire format of its underlying type but is a distinct
+// type in the bindings. Backends supporting the experiment generate a wrapper
+// around the underlying type; others see references to new types replaced by
+// their underlying types through Root.WithoutExperiments, or through
+// Compilation.WithoutExperiments for the new types of dependency libraries.
+
+// UnderlyingType returns the type the new type wraps, looking through new
+// types wrapping other new types, so that the result is never itself a new
+// type. Declarations are looked up through decls: for new types wrapping
+// those of other libraries, this should be a Compilation or a Program. An
+// error is returned if a wrapped new type cannot be resolved, or if new types
+// wrap each other in a loop.
+func (n *NewType) UnderlyingType(decls DeclResolver) (Type, error) {
+	seen := map[EncodedCompoundIdentifier]struct{}{n.Name: {}}
+	typ := n.Type
+	for typ.Kind == IdentifierType {
+		decl, ok := decls.LookupDecl(typ.Identifier)
+		if !ok {
+			return Type{}, fmt.Errorf("new type %s: %s not found", n.Name, typ.Identifier)
+		}
+		wrapped, ok := decl.(*NewType)
+		if !ok {
+			break
+		}
+		if _, ok := seen[wrapped.Name]; ok {
+			return Type{}, fmt.Errorf("new type %s: loop through %s", n.Name, wrapped.Name)
+		}
+		seen[wrapped.Name] = struct{}{}
+		typ = wrapped.Type
+	}
+	return typ, nil
+}
+
+// FromAlias returns the name of the alias the underlying type of the new type
+// was spelled with, e.g. `example/Bytes` for `type Blob = Bytes;` where
+// `alias Bytes = vector<uint8>;`, and whether there is one.
+func (n *NewType) FromAlias() (EncodedCompoundIdentifier, bool) {
+	if n.Alias == nil {
+		return "", false
+	}
+	return n.Alias.Name, true
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestNewTypeUnderlyingType(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.NewType)
+	if len(root.NewTypes) != 1 {
+		t.Fatalf("got %d new types, want 1", len(root.NewTypes))
+	}
+	id := &root.NewTypes[0]
+	u64 := fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint64}
+	actual, err := id.UnderlyingType(&root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.String() != u64.String() {
+		t.Errorf("got underlying type %s, want %s", actual, u64)
+	}
+	if _, ok := id.FromAlias(); ok {
+		t.Errorf("%s: unexpected alias", id.Name)
+	}
+
+	wrapped := fidlgen.NewType{
+		Decl:  fidlgen.Decl{Name: "test.newtype/WrappedId"},
+		Type:  *identifierType(id.Name),
+		Alias: &fidlgen.PartialTypeConstructor{Name: "test.newtype/IdAlias"},
+	}
+	root.NewTypes = append(root.NewTypes, wrapped)
+	if actual, err := wrapped.UnderlyingType(&root); err != nil || actual.String() != u64.String() {
+		t.Errorf("got underlying type %s (%v), want %s", actual, err, u64)
+	}
+	if alias, ok := wrapped.FromAlias(); !ok || alias != "test.newtype/IdAlias" {
+		t.Errorf("got alias %s, want test.newtype/IdAlias", alias)
+	}
+
+	loop := fidlgen.NewType{Decl: fidlgen.Decl{Name: "test.newtype/Loop"}, Type: *identifierType("test.newtype/Loop")}
+	missing := fidlgen.NewType{Decl: fidlgen.Decl{Name: "test.newtype/Dangling"}, Type: *identifierType("test.newtype/Missing")}
+	root.NewTypes = append(root.NewTypes, loop, missing)
+	for _, n := range []fidlgen.NewType{loop, missing} {
+		if _, err := n.UnderlyingType(&root); err == nil {
+			t.Errorf("%s: expected an error", n.Name)
+		}
+	}
+}
+
+func TestForBindingsNewTypes(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.NewType)
+	root.NewTypes[0].Attributes = bindingsDenylist("rust")
+
+	var names []fidlgen.EncodedCompoundIdentifier
+	for _, n := range root.ForBindings("go").NewTypes {
+		names = append(names, n.Name)
+	}
+	if diff := cmp.Diff([]fidlgen.EncodedCompoundIdentifier{"test.newtype/Id"}, names); diff != "" {
+		t.Errorf("unexpected new types (-want +got):\n%s", diff)
+	}
+	if res := root.ForBindings("rust"); len(res.NewTypes) != 0 {
+		t.Errorf("got new types %v for a denied language", res.NewTypes)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The resolved Value of a constant is always in decimal, so that backends
+// rendering it lose the radix the author chose, e.g. `0x8000` for a mask or
+// `0b101` for a set of flags. The IR keeps the original spelling of numeric
+// literals; the utilities below break it down, so that every backend can
+// render the value in the same radix, with its own prefixes if need be.
+
+// NumericBase is the radix in which a numeric literal is spelled.
+type NumericBase int
+
+const (
+	BinaryBase      NumericBase = 2
+	DecimalBase     NumericBase = 10
+	HexadecimalBase NumericBase = 16
+)
+
+// Prefix returns the FIDL prefix of literals in the base, which is also the
+// one of C, C++, Go, Rust and Dart: `0b`, `0x`, or none for decimal.
+func (b NumericBase) Prefix() string {
+	switch b {
+	case BinaryBase:
+		return "0b"
+	case HexadecimalBase:
+		return "0x"
+	default:
+		return ""
+	}
+}
+
+// NumericSpelling is the original spelling of a numeric literal.
+type NumericSpelling struct {
+	// Negative is whether the literal is preceded by a minus sign.
+	Negative bool
+	// Base is the radix of the literal.
+	Base NumericBase
+	// Digits are the digits of the literal, without sign or prefix, as
+	// spelled, e.g. `ABCD` for `0xABCD`. Decimal literals of floating-point
+	// constants may include a fraction and an exponent.
+	Digits string
+}
+
+// ParseNumericSpelling breaks down the spelling of a FIDL numeric literal,
+// e.g. `0xABCD`, `-0b101` or `1.5e3`.
+func ParseNumericSpelling(text string) (NumericSpelling, error) {
+	var s NumericSpelling
+	rest := text
+	if strings.HasPrefix(rest, "-") {
+		s.Negative = true
+		rest = rest[1:]
+	}
+	s.Base = DecimalBase
+	if len(rest) > 2 && rest[0] == '0' {
+		switch rest[1] {
+		case 'x', 'X':
+			s.Base = HexadecimalBase
+			rest = rest[2:]
+		case 'b', 'B':
+			s.Base = BinaryBase
+			rest = rest[2:]
+		}
+	}
+	if rest == "" || !validNumericDigits(rest, s.Base) {
+		return NumericSpelling{}, fmt.Errorf("malformed numeric literal: %q", text)
+	}
+	s.Digits = rest
+	return s, nil
+}
+
+// validNumericDigits returns whether digits are well-formed in the given base.
+// Binary and hexadecimal literals are integers, while decimal ones may have a
+// fraction and an exponent, e.g. `1.5e-3`.
+func validNumericDigits(digits string, base NumericBase) bool {
+	isDigit := func(c byte) bool {
+		switch base {
+		case BinaryBase:
+			return c == '0' || c == '1'
+		case HexadecimalBase:
+			return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+		default:
+			return c >= '0' && c <= '9'
+		}
+	}
+	// skipDigits returns the index of the first non-digit at or after i, and
+	// whether there was at least one digit.
+	skipDigits := func(i int) (int, bool) {
+		start := i
+		for i < len(digits) && isDigit(digits[i]) {
+			i++
+		}
+		return i, i > start
+	}
+
+	i, ok := skipDigits(0)
+	if !ok {
+		return false
+	}
+	if base != DecimalBase {
+		return i == len(digits)
+	}
+	if i < len(digits) && digits[i] == '.' {
+		if i, ok = skipDigits(i + 1); !ok {
+			return false
+		}
+	}
+	if i < len(digits) && (digits[i] == 'e' || digits[i] == 'E') {
+		i++
+		if i < len(digits) && (digits[i] == '+' || digits[i] == '-') {
+			i++
+		}
+		if i, ok = skipDigits(i); !ok {
+			return false
+		}
+	}
+	return i == len(digits)
+}
+
+// IsInteger returns whether the literal has neither a fraction nor an
+// exponent.
+func (s NumericSpelling) IsInteger() bool {
+	return s.Base != DecimalBase || !strings.ContainsAny(s.Digits, ".eE")
+}
+
+// String renders the literal in FIDL syntax, in its original radix.
+func (s NumericSpelling) String() string {
+	return s.Format(s.Base.Prefix())
+}
+
+// Format renders the literal with the given prefix in place of the FIDL one,
+// for backends whose syntax differs, e.g. `16#` for hexadecimal.
+func (s NumericSpelling) Format(prefix string) string {
+	sign := ""
+	if s.Negative {
+		sign = "-"
+	}
+	return sign + prefix + s.Digits
+}
+
+// NumericSpelling returns the original spelling of a numeric literal
+// constant. It returns false if the constant is not a numeric literal, e.g.
+// an identifier or a binary operator, whose operands may be broken down with
+// ParseConstantExpression instead.
+func (c Constant) NumericSpelling() (NumericSpelling, bool) {
+	if c.Kind != LiteralConstant || c.Literal.Kind != NumericLiteral {
+		return NumericSpelling{}, false
+	}
+	text := c.Literal.Value
+	if text == "" {
+		text = c.Expression
+	}
+	s, err := ParseNumericSpelling(text)
+	if err != nil {
+		return NumericSpelling{}, false
+	}
+	return s, true
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestParseNumericSpelling(t *testing.T) {
+	for _, tc := range []struct {
+		text      string
+		expected  fidlgen.NumericSpelling
+		isInteger bool
+	}{
+		{"0", fidlgen.NumericSpelling{Base: fidlgen.DecimalBase, Digits: "0"}, true},
+		{"-42", fidlgen.NumericSpelling{Negative: true, Base: fidlgen.DecimalBase, Digits: "42"}, true},
+		{"0xABCD", fidlgen.NumericSpelling{Base: fidlgen.HexadecimalBase, Digits: "ABCD"}, true},
+		{"0b101", fidlgen.NumericSpelling{Base: fidlgen.BinaryBase, Digits: "101"}, true},
+		{"-0x8000000000000000", fidlgen.NumericSpelling{Negative: true, Base: fidlgen.HexadecimalBase, Digits: "8000000000000000"}, true},
+		{"1.5e-3", fidlgen.NumericSpelling{Base: fidlgen.DecimalBase, Digits: "1.5e-3"}, false},
+		{"2E+10", fidlgen.NumericSpelling{Base: fidlgen.DecimalBase, Digits: "2E+10"}, false},
+		{"0.25", fidlgen.NumericSpelling{Base: fidlgen.DecimalBase, Digits: "0.25"}, false},
+	} {
+		actual, err := fidlgen.ParseNumericSpelling(tc.text)
+		if err != nil {
+			t.Errorf("%s: %s", tc.text, err)
+			continue
+		}
+		if diff := cmp.Diff(tc.expected, actual); diff != "" {
+			t.Errorf("%s: unexpected spelling (-want +got):\n%s", tc.text, diff)
+		}
+		if actual.IsInteger() != tc.isInteger {
+			t.Errorf("%s: expected IsInteger() = %t", tc.text, tc.isInteger)
+		}
+		if actual.String() != tc.text {
+			t.Errorf("%s: rendered back as %s", tc.text, actual)
+		}
+	}
+}
+
+func TestParseNumericSpellingErrors(t *testing.T) {
+	for _, text := range []string{"", "-", "0b102", "0xG", "e10", "1x2", "FOO", "1-2", "1+2", "1e5e5", "1.2.3", "1.", ".5", "1e", "1e+", "0x1.5", "0b1e1", "--1"} {
+		if s, err := fidlgen.ParseNumericSpelling(text); err == nil {
+			t.Errorf("%q: expected an error, got %+v", text, s)
+		}
+	}
+}
+
+func TestNumericSpellingFormat(t *testing.T) {
+	s, err := fidlgen.ParseNumericSpelling("-0xff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := s.Format("16#"); actual != "-16#ff" {
+		t.Errorf("got %s, want -16#ff", actual)
+	}
+}
+
+func TestConstantNumericSpelling(t *testing.T) {
+	literal := fidlgen.Constant{
+		Kind:       fidlgen.LiteralConstant,
+		Literal:    fidlgen.Literal{Kind: fidlgen.NumericLiteral, Value: "0b101"},
+		Value:      "5",
+		Expression: "0b101",
+	}
+	s, ok := literal.NumericSpelling()
+	if !ok {
+		t.Fatal("expected a numeric spelling")
+	}
+	if s.Base != fidlgen.BinaryBase || s.String() != "0b101" {
+		t.Errorf("unexpected spelling: %+v", s)
+	}
+
+	for _, c := range []fidlgen.Constant{
+		{Kind: fidlgen.LiteralConstant, Literal: fidlgen.Literal{Kind: fidlgen.StringLiteral, Value: "0x1"}},
+		{Kind: fidlgen.IdentifierConstant, Identifier: "example/MAX", Value: "16", Expression: "MAX"},
+		{Kind: fidlgen.BinaryOperator, Value: "3", Expression: "0x1 | 0x2"},
+	} {
+		if s, ok := c.NumericSpelling(); ok {
+			t.Errorf("%+v: expected no numeric spelling, got %+v", c, s)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// The object types of handles are defined by library zx: the subtype property
+// of its handle resource_definition is an enum, whose members are the object
+// types. ObjectTypeFromHandleSubtype duplicates that enum, so that each new
+// object type requires a change here too. Root.HandleObjectTypes is computed
+// from the object types fidlc gives the handle types of the IR instead, and
+// only falls back to ObjectTypeFromHandleSubtype for subtypes the IR says
+// nothing about.
+//
+// The enum itself is not consulted: only the IR of library zx has it, and the
+// names of its members do not always match the handle subtypes (e.g. `LOG`
+// for `debuglog`).
+
+// HandleObjectTypes maps handle subtypes to the object types of library zx.
+type HandleObjectTypes map[HandleSubtype]ObjectType
+
+// ObjectType returns the object type of a handle subtype. Subtypes missing
+// from the mapping are looked up with ObjectTypeFromHandleSubtype.
+func (m HandleObjectTypes) ObjectType(subtype HandleSubtype) ObjectType {
+	if objType, ok := m[subtype]; ok {
+		return objType
+	}
+	return ObjectTypeFromHandleSubtype(subtype)
+}
+
+// MarkHandleObjectTypes sets Root.HandleObjectTypes from the object types
+// fidlc gives the handle types of the library. DecodeJSONIr already does
+// this: it only needs to be called on IR that is constructed or modified by
+// other means.
+func (r *Root) MarkHandleObjectTypes() {
+	objTypes := make(HandleObjectTypes)
+	r.forEachTopLevelType(func(typ *Type) {
+		WalkType(typ, func(typ *Type) bool {
+			if typ.Kind == HandleType && typ.HandleSubtype != HandleSubtypeNone && typ.ObjType != uint32(ObjectTypeNone) {
+				objTypes[typ.HandleSubtype] = ObjectType(typ.ObjType)
+			}
+			return true
+		})
+	})
+	r.HandleObjectTypes = nil
+	if len(objTypes) > 0 {
+		r.HandleObjectTypes = objTypes
+	}
+}
+
+// ObjectType returns the object type of a handle subtype, as given by the IR
+// of the library if it can tell, or by ObjectTypeFromHandleSubtype otherwise.
+func (r *Root) ObjectType(subtype HandleSubtype) ObjectType {
+	return r.HandleObjectTypes.ObjectType(subtype)
+}
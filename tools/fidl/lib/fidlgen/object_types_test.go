@@ -0,0 +1,121 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestMarkHandleObjectTypesIgnoresResourceDefinition(t *testing.T) {
+	uint32Type := fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}
+	objType := enumDecl(fidlgen.Uint32,
+		enumMember("NONE", "0"),
+		// The handle subtype of this object type is `debuglog`.
+		enumMember("LOG", "12"),
+		enumMember("COUNTER", "42"),
+	)
+	objType.Name = "zx/ObjType"
+	zx := fidlgen.Root{
+		Name:  "zx",
+		Enums: []fidlgen.Enum{objType},
+		Resources: []fidlgen.Resource{
+			{
+				Decl: fidlgen.Decl{Name: "zx/Handle"},
+				Type: uint32Type,
+				Properties: []fidlgen.ResourceProperty{
+					{Decl: fidlgen.Decl{Name: "subtype"}, Type: fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "zx/ObjType"}},
+				},
+			},
+		},
+	}
+	zx.MarkHandleObjectTypes()
+
+	if zx.HandleObjectTypes != nil {
+		t.Errorf("expected no object types, got %v", zx.HandleObjectTypes)
+	}
+	if actual := zx.ObjectType(fidlgen.HandleSubtypeDebugLog); actual != fidlgen.ObjectTypeDebugLog {
+		t.Errorf("got object type %d for debuglog, want %d", actual, fidlgen.ObjectTypeDebugLog)
+	}
+	if actual := zx.ObjectType("log"); actual != fidlgen.ObjectTypeNone {
+		t.Errorf("got object type %d for log, want none", actual)
+	}
+}
+
+func TestMarkHandleObjectTypesFromHandleTypes(t *testing.T) {
+	root := fidlgen.Root{
+		Structs: []fidlgen.Struct{
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/S"}},
+				},
+				Members: []fidlgen.StructMember{
+					{Name: "h", Type: fidlgen.Type{Kind: fidlgen.HandleType, HandleSubtype: "counter", ObjType: 42}},
+					{Name: "any", Type: fidlgen.Type{Kind: fidlgen.HandleType, HandleSubtype: fidlgen.HandleSubtypeNone}},
+				},
+			},
+		},
+	}
+	root.MarkHandleObjectTypes()
+	if diff := cmp.Diff(fidlgen.HandleObjectTypes{"counter": 42}, root.HandleObjectTypes); diff != "" {
+		t.Errorf("unexpected object types (-want +got):\n%s", diff)
+	}
+
+	var empty fidlgen.Root
+	empty.MarkHandleObjectTypes()
+	if empty.HandleObjectTypes != nil {
+		t.Errorf("expected no object types, got %v", empty.HandleObjectTypes)
+	}
+	if actual := empty.ObjectType(fidlgen.HandleSubtypeChannel); actual != fidlgen.ObjectTypeChannel {
+		t.Errorf("got object type %d for channel, want the fallback %d", actual, fidlgen.ObjectTypeChannel)
+	}
+}
+
+func TestHandleObjectTypesOfDerivedRoots(t *testing.T) {
+	root := fidlgen.Root{
+		Name: "example",
+		Structs: []fidlgen.Struct{
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/S"}},
+				},
+				Members: []fidlgen.StructMember{
+					{Name: "h", Type: fidlgen.Type{Kind: fidlgen.HandleType, HandleSubtype: "counter", ObjType: 42}},
+				},
+			},
+		},
+		Decls: fidlgen.DeclMap{"example/S": fidlgen.StructDeclType},
+	}
+	root.MarkHandleObjectTypes()
+
+	atHead, err := root.AtVersion(fidlgen.HeadVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forBindings := root.ForBindings("go")
+	forBindingsAtHead, err := forBindings.AtVersion(fidlgen.HeadVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		desc    string
+		derived fidlgen.Root
+	}{
+		{"ForBindings", forBindings},
+		{"ForTransport", root.ForTransport(fidlgen.ChannelTransport)},
+		{"AtVersion", atHead},
+		{"WithoutExperiments", root.WithoutExperiments()},
+		{"ForBindings then AtVersion", forBindingsAtHead},
+	} {
+		if diff := cmp.Diff(root.HandleObjectTypes, tc.derived.HandleObjectTypes); diff != "" {
+			t.Errorf("%s: unexpected object types (-want +got):\n%s", tc.desc, diff)
+		}
+		if actual := tc.derived.ObjectType("counter"); actual != 42 {
+			t.Errorf("%s: got object type %d for counter, want 42", tc.desc, actual)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+)
+
+// fidlc derives the ordinal of a method from its selector, i.e. its fully
+// qualified name, e.g. "fuchsia.io/Node.Close", unless renamed with @selector.
+// Computing ordinals the same way lets tools verify the ordinals of the IR at
+// hand, or find those of methods for which there is no IR, e.g. in GIDL or
+// compatibility tests.
+
+// ordinalMask clears the most significant bit of ordinals, which is reserved.
+const ordinalMask = 0x7fffffffffffffff
+
+// DefaultSelector returns the selector of a method of the given protocol
+// which is not renamed with @selector, e.g. "fuchsia.io/Node.Close".
+func DefaultSelector(protocol EncodedCompoundIdentifier, method Identifier) string {
+	return fmt.Sprintf("%s.%s", protocol, method)
+}
+
+// ComputeOrdinal computes the ordinal of a method from its fully qualified
+// selector, as fidlc does: the first 8 bytes of the SHA-256 hash of the
+// selector, read as a little-endian integer, without the most significant
+// bit.
+func ComputeOrdinal(selector string) uint64 {
+	hash := sha256.Sum256([]byte(selector))
+	return binary.LittleEndian.Uint64(hash[:8]) & ordinalMask
+}
+
+// VerifyOrdinal checks that an ordinal is the one fidlc computes for a
+// selector.
+func VerifyOrdinal(selector string, ordinal uint64) error {
+	if want := ComputeOrdinal(selector); ordinal != want {
+		return fmt.Errorf("ordinal %#x does not match selector %q, whose ordinal is %#x", ordinal, selector, want)
+	}
+	return nil
+}
+
+var (
+	selectorIdentifierRegexp = regexp.MustCompile(`^[A-Za-z]([A-Za-z0-9_]*[A-Za-z0-9])?$`)
+	fullSelectorRegexp       = regexp.MustCompile(`^[a-z][a-z0-9]*(\.[a-z][a-z0-9]*)*/[A-Za-z]([A-Za-z0-9_]*[A-Za-z0-9])?\.[A-Za-z]([A-Za-z0-9_]*[A-Za-z0-9])?$`)
+)
+
+// Selector returns the effective selector of a method of the given protocol.
+// The @selector attribute either renames the method, e.g. @selector("Old"),
+// or gives its full selector, e.g. @selector("fuchsia.old/Protocol.Method"),
+// so that the method keeps its ordinal after being renamed or moved. Without
+// it, the selector is the DefaultSelector. An error is returned if the
+// attribute is not in one of these forms.
+//
+// Composed methods keep the selector they have in the protocol declaring
+// them, which must then be given instead of the composing protocol.
+func (m *Method) Selector(protocol EncodedCompoundIdentifier) (string, error) {
+	attr, ok := m.LookupAttribute("selector")
+	if !ok {
+		return DefaultSelector(protocol, m.Name), nil
+	}
+	arg, ok := attr.LookupArgStandalone()
+	if !ok {
+		return "", fmt.Errorf("%s.%s: @selector has no value", protocol, m.Name)
+	}
+	value := arg.ValueString()
+	switch {
+	case selectorIdentifierRegexp.MatchString(value):
+		return DefaultSelector(protocol, Identifier(value)), nil
+	case fullSelectorRegexp.MatchString(value):
+		return value, nil
+	default:
+		return "", fmt.Errorf("%s.%s: invalid selector %q", protocol, m.Name, value)
+	}
+}
+
+// VerifyOrdinals checks that the ordinals of the methods of the protocol are
+// those computed from their selectors. Composed methods are not checked, as
+// the protocol declaring them is not known.
+func (p *Protocol) VerifyOrdinals() error {
+	for i := range p.Methods {
+		m := &p.Methods[i]
+		if m.IsComposed {
+			continue
+		}
+		selector, err := m.Selector(p.Name)
+		if err != nil {
+			return err
+		}
+		if err := VerifyOrdinal(selector, m.Ordinal); err != nil {
+			return fmt.Errorf("%s.%s: %w", p.Name, m.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestComputeOrdinal(t *testing.T) {
+	for _, tc := range []struct {
+		protocol fidlgen.EncodedCompoundIdentifier
+		method   fidlgen.Identifier
+		selector string
+		ordinal  uint64
+	}{
+		{"fuchsia.io/Node", "Close", "fuchsia.io/Node.Close", 0x5ac5d459ad7f657e},
+		{"fuchsia.unknown/Queryable", "Query", "fuchsia.unknown/Queryable.Query", 0x2658edee9decfc06},
+	} {
+		selector := fidlgen.DefaultSelector(tc.protocol, tc.method)
+		if selector != tc.selector {
+			t.Errorf("got selector %q, want %q", selector, tc.selector)
+		}
+		if got := fidlgen.ComputeOrdinal(selector); got != tc.ordinal {
+			t.Errorf("%s: got ordinal %#x, want %#x", selector, got, tc.ordinal)
+		}
+		if err := fidlgen.VerifyOrdinal(selector, tc.ordinal); err != nil {
+			t.Error(err)
+		}
+		if err := fidlgen.VerifyOrdinal(selector, tc.ordinal+1); err == nil {
+			t.Errorf("%s: expected an error for a wrong ordinal", selector)
+		}
+	}
+}
+
+func selectorMethod(name fidlgen.Identifier, selector string) fidlgen.Method {
+	m := fidlgen.Method{Name: name}
+	if selector != "" {
+		m.Attributes = fidlgen.Attributes{Attributes: []fidlgen.Attribute{{
+			Name: "selector",
+			Args: []fidlgen.AttributeArg{{
+				Name:  "value",
+				Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: selector},
+			}},
+		}}}
+	}
+	return m
+}
+
+func TestMethodSelector(t *testing.T) {
+	for _, tc := range []struct {
+		selector string
+		expected string
+	}{
+		{"", "fuchsia.io/Node.Close"},
+		{"OldClose", "fuchsia.io/Node.OldClose"},
+		{"fuchsia.unknown/Closeable.Close", "fuchsia.unknown/Closeable.Close"},
+	} {
+		m := selectorMethod("Close", tc.selector)
+		got, err := m.Selector("fuchsia.io/Node")
+		if err != nil {
+			t.Errorf("%q: %s", tc.selector, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("%q: got selector %q, want %q", tc.selector, got, tc.expected)
+		}
+	}
+	for _, selector := range []string{"Bad Name", "_Close", "fuchsia.unknown/Closeable", "Fuchsia.Unknown/Closeable.Close", "a/B.C.D"} {
+		m := selectorMethod("Close", selector)
+		if _, err := m.Selector("fuchsia.io/Node"); err == nil {
+			t.Errorf("%q: expected an error", selector)
+		}
+	}
+}
+
+func TestVerifyOrdinals(t *testing.T) {
+	closeMethod := selectorMethod("Close", "fuchsia.unknown/Closeable.Close")
+	closeMethod.Ordinal = 0x74f5d243849cb458
+	composed := fidlgen.Method{Name: "Query", Ordinal: 0x2658edee9decfc06, IsComposed: true}
+	p := fidlgen.Protocol{
+		Decl:    fidlgen.Decl{Name: "fuchsia.io/Node"},
+		Methods: []fidlgen.Method{closeMethod, composed},
+	}
+	if err := p.VerifyOrdinals(); err != nil {
+		t.Error(err)
+	}
+	p.Methods[0].Ordinal = 0x5ac5d459ad7f657e
+	if err := p.VerifyOrdinals(); err == nil {
+		t.Errorf("expected an error for an ordinal ignoring @selector")
+	}
+}
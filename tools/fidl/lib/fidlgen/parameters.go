@@ -0,0 +1,103 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// Most bindings flatten struct payloads into method parameters, e.g.
+// `Add(struct { a int32; b int32; })` becomes `Add(a, b)`, but pass table and
+// union payloads as a single value, since their members are optional or
+// exclusive. RequestParameters and ResponseParameters make that decision once.
+
+// Parameter is a member of a struct payload, seen as a method parameter.
+type Parameter struct {
+	// Name is the name of the struct member.
+	Name Identifier
+	// Type is the type of the struct member.
+	Type Type
+	// Attributes are the attributes of the struct member.
+	Attributes Attributes
+}
+
+// MethodParameters describes the parameters of a request or response.
+type MethodParameters struct {
+	// Payload is the type of the payload, or nil if there is none. For
+	// responses wrapped in a result union, this is the success type.
+	Payload *Type
+	// Parameters lists the members of a struct payload, in declaration order.
+	// It is empty if the payload is not a struct, or an empty one.
+	Parameters []Parameter
+	// Table is the payload, if it is a table. It is passed as a whole.
+	Table *Table
+	// Union is the payload, if it is a union. It is passed as a whole.
+	Union *Union
+}
+
+// IsFlattened returns whether the payload is a struct, whose members are then
+// passed as separate parameters.
+func (p MethodParameters) IsFlattened() bool {
+	return p.Payload != nil && p.Table == nil && p.Union == nil
+}
+
+// methodParameters resolves a payload through decls and describes its
+// parameters.
+func methodParameters(payload *Type, decls DeclResolver) (MethodParameters, error) {
+	if payload == nil {
+		return MethodParameters{}, nil
+	}
+	params := MethodParameters{Payload: payload}
+	decl, ok := decls.LookupDecl(payload.Identifier)
+	if !ok {
+		return MethodParameters{}, fmt.Errorf("payload %s is not declared", payload.Identifier)
+	}
+	switch decl := decl.(type) {
+	case *Struct:
+		for _, member := range decl.Members {
+			params.Parameters = append(params.Parameters, Parameter{
+				Name:       member.Name,
+				Type:       member.Type,
+				Attributes: member.Attributes,
+			})
+		}
+	case *Table:
+		params.Table = decl
+	case *Union:
+		params.Union = decl
+	default:
+		return MethodParameters{}, fmt.Errorf("payload %s is a %T, not a struct, table or union", payload.Identifier, decl)
+	}
+	return params, nil
+}
+
+// RequestParameters describes the parameters of the method's request. The
+// payload is resolved through decls: for composed methods whose payloads are
+// declared in another library, this should be a Compilation. An error is
+// returned if the payload cannot be resolved.
+func (m *Method) RequestParameters(decls DeclResolver) (MethodParameters, error) {
+	params, err := methodParameters(m.RequestPayload, decls)
+	if err != nil {
+		return MethodParameters{}, fmt.Errorf("%s request: %w", m.Name, err)
+	}
+	return params, nil
+}
+
+// ResponseParameters describes the parameters of the method's response, or of
+// its event. If the response is wrapped in a result union, i.e. the method is
+// flexible or uses error syntax, these are the parameters of the success type,
+// as bindings return errors separately. The payload is resolved as for
+// RequestParameters.
+func (m *Method) ResponseParameters(decls DeclResolver) (MethodParameters, error) {
+	payload := m.ResponsePayload
+	if m.ResultType != nil {
+		payload = m.ValueType
+	}
+	params, err := methodParameters(payload, decls)
+	if err != nil {
+		return MethodParameters{}, fmt.Errorf("%s response: %w", m.Name, err)
+	}
+	return params, nil
+}
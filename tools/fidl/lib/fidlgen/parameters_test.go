@@ -0,0 +1,87 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestMethodParameters(t *testing.T) {
+	args := structDecl("example/Args", "a", "b")
+	args.Members[1].Attributes = deprecated("use a")
+	options := fidlgen.Table{ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+		LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Options"}},
+	}}
+	root := fidlgen.Root{
+		Structs: []fidlgen.Struct{args},
+		Tables:  []fidlgen.Table{options},
+		Enums: []fidlgen.Enum{{
+			LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Color"}},
+		}},
+	}
+	m := fidlgen.Method{
+		Name:            "Add",
+		RequestPayload:  identifierType("example/Args"),
+		ResponsePayload: identifierType("example/Options"),
+	}
+
+	req, err := m.RequestParameters(&root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []fidlgen.Parameter{
+		{Name: "a"},
+		{Name: "b", Attributes: deprecated("use a")},
+	}
+	if diff := cmp.Diff(expected, req.Parameters); diff != "" {
+		t.Errorf("request parameters (-want +got):\n%s", diff)
+	}
+	if !req.IsFlattened() || req.Table != nil || req.Union != nil {
+		t.Errorf("struct request is not flattened: %+v", req)
+	}
+
+	resp, err := m.ResponseParameters(&root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.IsFlattened() || resp.Table == nil || resp.Table.Name != "example/Options" || len(resp.Parameters) != 0 {
+		t.Errorf("table response is flattened: %+v", resp)
+	}
+
+	var none fidlgen.Method
+	if params, err := none.RequestParameters(&root); err != nil || params.Payload != nil || params.IsFlattened() {
+		t.Errorf("got %+v, %v for a method without payloads", params, err)
+	}
+
+	for _, payload := range []fidlgen.EncodedCompoundIdentifier{"example/Missing", "example/Color"} {
+		bad := fidlgen.Method{Name: "Bad", RequestPayload: identifierType(payload)}
+		if _, err := bad.RequestParameters(&root); err == nil {
+			t.Errorf("expected an error for payload %s", payload)
+		}
+	}
+}
+
+func TestMethodResponseParametersResult(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	for _, m := range root.Protocols[0].Methods {
+		if m.Name != "FlexibleTwoWay" {
+			continue
+		}
+		params, err := m.ResponseParameters(&root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if params.Payload == nil || params.Payload.Identifier != "test.openprotocol/P_FlexibleTwoWay_Response" {
+			t.Errorf("got payload %+v, want the success type", params.Payload)
+		}
+		if !params.IsFlattened() || len(params.Parameters) != 0 {
+			t.Errorf("got %+v, want an empty flattened struct", params)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// A pipelined method is a method that takes the server end of a protocol as a
+// request parameter, e.g.
+//
+//	protocol Directory {
+//	    Open(resource struct { path string; object server_end:Node; });
+//	};
+//
+// Such "constructor-style" methods let a client start using the client end of
+// the new connection right away, without waiting on a reply: messages are
+// "pipelined" behind the request that creates the connection. Bindings can
+// take advantage of this to generate connect-and-call convenience wrappers, and
+// documentation tools to call these methods out.
+
+// PipelinedParameter describes a request parameter carrying a server end.
+type PipelinedParameter struct {
+	// Name is the name of the parameter (i.e., of the payload member).
+	Name Identifier
+	// Protocol is the protocol whose server end is carried.
+	Protocol EncodedCompoundIdentifier
+	// Transport is the transport of the protocol, e.g. "Channel".
+	Transport Transport
+	// Nullable indicates whether the server end is optional.
+	Nullable bool
+}
+
+// PipelinedMethod describes a method of a protocol which takes at least one
+// server end as a request parameter.
+type PipelinedMethod struct {
+	// Method is the pipelined method.
+	Method Method
+	// Parameters lists the request parameters carrying a server end, in
+	// declaration order.
+	Parameters []PipelinedParameter
+}
+
+// asPipelinedParameter returns the PipelinedParameter corresponding to a
+// request parameter of the given name and type, if that type is a server end.
+func asPipelinedParameter(name Identifier, typ Type) (PipelinedParameter, bool) {
+	if typ.Kind != RequestType {
+		return PipelinedParameter{}, false
+	}
+	return PipelinedParameter{
+		Name:      name,
+		Protocol:  typ.RequestSubtype,
+		Transport: typ.ProtocolTransport,
+		Nullable:  typ.Nullable,
+	}, true
+}
+
+// pipelinedParameters returns the server end parameters of a method's request
+// payload, which may be a struct, table, or union.
+func pipelinedParameters(m Method, decls DeclResolver) []PipelinedParameter {
+	name, ok := m.GetRequestPayloadIdentifier()
+	if !ok {
+		return nil
+	}
+	decl, ok := decls.LookupDecl(name)
+	if !ok {
+		return nil
+	}
+
+	var params []PipelinedParameter
+	add := func(name Identifier, typ Type) {
+		if param, ok := asPipelinedParameter(name, typ); ok {
+			params = append(params, param)
+		}
+	}
+	switch payload := decl.(type) {
+	case *Struct:
+		for _, member := range payload.Members {
+			add(member.Name, member.Type)
+		}
+	case *Table:
+		for _, member := range payload.Members {
+			if !member.Reserved {
+				add(member.Name, member.Type)
+			}
+		}
+	case *Union:
+		for _, member := range payload.Members {
+			if !member.Reserved {
+				add(member.Name, member.Type)
+			}
+		}
+	}
+	return params
+}
+
+// PipelinedMethods returns the methods of a protocol which take a server end
+// as a request parameter, in declaration order. Request payloads are resolved
+// through decls: for composed methods whose payloads are declared in another
+// library, this should be a Compilation, or the method will be skipped.
+func PipelinedMethods(p *Protocol, decls DeclResolver) []PipelinedMethod {
+	var methods []PipelinedMethod
+	for _, m := range p.Methods {
+		if params := pipelinedParameters(m, decls); len(params) > 0 {
+			methods = append(methods, PipelinedMethod{
+				Method:     m,
+				Parameters: params,
+			})
+		}
+	}
+	return methods
+}
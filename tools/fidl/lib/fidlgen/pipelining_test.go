@@ -0,0 +1,64 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
+)
+
+func TestPipelinedMethods(t *testing.T) {
+	root := fidlgentest.EndToEndTest{T: t}.Single(`
+		library example;
+
+		protocol Node {};
+
+		protocol Directory {
+			Open(resource struct { path string; object server_end:Node; });
+			Clone(resource struct { object server_end:<Node, optional>; }) -> ();
+			Describe() -> (struct { path string; });
+			Watch(resource struct { watcher client_end:Node; });
+		};
+	`)
+
+	var dir *fidlgen.Protocol
+	for i := range root.Protocols {
+		if root.Protocols[i].Name == "example/Directory" {
+			dir = &root.Protocols[i]
+		}
+	}
+	if dir == nil {
+		t.Fatal("example/Directory not found")
+	}
+
+	type result struct {
+		Method     fidlgen.Identifier
+		Parameters []fidlgen.PipelinedParameter
+	}
+	var actual []result
+	for _, m := range fidlgen.PipelinedMethods(dir, &root) {
+		actual = append(actual, result{m.Method.Name, m.Parameters})
+	}
+	expected := []result{
+		{
+			Method: "Open",
+			Parameters: []fidlgen.PipelinedParameter{
+				{Name: "object", Protocol: "example/Node", Transport: "Channel"},
+			},
+		},
+		{
+			Method: "Clone",
+			Parameters: []fidlgen.PipelinedParameter{
+				{Name: "object", Protocol: "example/Node", Transport: "Channel", Nullable: true},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Program is a set of FIDL libraries loaded together, e.g. all the libraries
+// of a build. Unlike a Compilation, which is centered on a single target
+// library, a Program gives all its libraries equal standing and is meant for
+// platform-wide analyses.
+type Program struct {
+	// Libraries holds the IR of each library, keyed by library name.
+	Libraries map[EncodedLibraryIdentifier]*Root
+
+	decls map[EncodedCompoundIdentifier]Declaration
+	// composers maps each protocol to the protocols composing it directly.
+	composers map[EncodedCompoundIdentifier][]EncodedCompoundIdentifier
+}
+
+// NewProgram creates a Program out of the IR of a set of libraries. Each
+// library may only be provided once.
+func NewProgram(roots []Root) (*Program, error) {
+	p := &Program{
+		Libraries: make(map[EncodedLibraryIdentifier]*Root, len(roots)),
+		decls:     make(map[EncodedCompoundIdentifier]Declaration),
+		composers: make(map[EncodedCompoundIdentifier][]EncodedCompoundIdentifier),
+	}
+	for i := range roots {
+		root := &roots[i]
+		if _, ok := p.Libraries[root.Name]; ok {
+			return nil, fmt.Errorf("library %s provided more than once", root.Name)
+		}
+		p.Libraries[root.Name] = root
+	}
+	for _, root := range p.Libraries {
+		root.ForEachDecl(func(decl Declaration) {
+			// ExternalStructs are copies of declarations owned by another
+			// library; prefer the owner's when it is present.
+			if _, ok := p.decls[decl.GetName()]; !ok || decl.GetName().LibraryName() == root.Name {
+				p.decls[decl.GetName()] = decl
+			}
+		})
+		for _, protocol := range root.Protocols {
+			for _, composed := range protocol.Composed {
+				p.composers[composed.Name] = append(p.composers[composed.Name], protocol.Name)
+			}
+		}
+	}
+	return p, nil
+}
+
+// ReadProgram reads the JSON IR of a set of libraries from the given files, and
+// creates a Program out of them.
+func ReadProgram(filenames []string) (*Program, error) {
+	var roots []Root
+	for _, filename := range filenames {
+		root, err := ReadJSONIr(filename)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, root)
+	}
+	return NewProgram(roots)
+}
+
+// LibraryNames returns the names of the libraries of the program, sorted.
+func (p *Program) LibraryNames() []EncodedLibraryIdentifier {
+	var names []EncodedLibraryIdentifier
+	for name := range p.Libraries {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// ForEachLibrary calls a provided callback on each library of the program, in
+// order of library name.
+func (p *Program) ForEachLibrary(cb func(*Root)) {
+	for _, name := range p.LibraryNames() {
+		cb(p.Libraries[name])
+	}
+}
+
+// LookupDecl returns the declaration with the given name from any library of
+// the program. If the name refers to a member, the enclosing declaration is
+// returned.
+func (p *Program) LookupDecl(name EncodedCompoundIdentifier) (Declaration, bool) {
+	decl, ok := p.decls[name.DeclName()]
+	return decl, ok
+}
+
+var _ DeclResolver = (*Program)(nil)
+
+// DirectComposersOf returns the protocols of the program which compose the
+// given protocol directly, sorted.
+func (p *Program) DirectComposersOf(protocol EncodedCompoundIdentifier) []EncodedCompoundIdentifier {
+	return sortNames(append([]EncodedCompoundIdentifier(nil), p.composers[protocol]...))
+}
+
+// ComposersOf returns the protocols of the program which compose the given
+// protocol, directly or through other protocols, sorted. All of them gain the
+// methods added to the given protocol, e.g. every protocol composing
+// fuchsia.unknown/Closeable gains the methods added to it.
+func (p *Program) ComposersOf(protocol EncodedCompoundIdentifier) []EncodedCompoundIdentifier {
+	var composers []EncodedCompoundIdentifier
+	seen := map[EncodedCompoundIdentifier]struct{}{protocol: {}}
+	queue := []EncodedCompoundIdentifier{protocol}
+	for len(queue) > 0 {
+		for _, composer := range p.composers[queue[0]] {
+			if _, ok := seen[composer]; !ok {
+				seen[composer] = struct{}{}
+				composers = append(composers, composer)
+				queue = append(queue, composer)
+			}
+		}
+		queue = queue[1:]
+	}
+	return sortNames(composers)
+}
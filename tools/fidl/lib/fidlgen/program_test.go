@@ -0,0 +1,51 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func composing(name fidlgen.EncodedCompoundIdentifier, composed ...fidlgen.EncodedCompoundIdentifier) fidlgen.Protocol {
+	p := protocolDecl(name)
+	for _, c := range composed {
+		p.Composed = append(p.Composed, fidlgen.Decl{Name: c})
+	}
+	return p
+}
+
+func TestComposersOf(t *testing.T) {
+	unknown := fidlgen.Root{
+		Name:      "fuchsia.unknown",
+		Protocols: []fidlgen.Protocol{composing("fuchsia.unknown/Closeable")},
+	}
+	io := fidlgen.Root{
+		Name: "fuchsia.io",
+		Protocols: []fidlgen.Protocol{
+			composing("fuchsia.io/Node", "fuchsia.unknown/Closeable"),
+			composing("fuchsia.io/File", "fuchsia.io/Node"),
+			composing("fuchsia.io/Directory", "fuchsia.io/Node", "fuchsia.unknown/Closeable"),
+		},
+	}
+	p, err := fidlgen.NewProgram([]fidlgen.Root{unknown, io})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []fidlgen.EncodedCompoundIdentifier{"fuchsia.io/Directory", "fuchsia.io/Node"}
+	if diff := cmp.Diff(expected, p.DirectComposersOf("fuchsia.unknown/Closeable")); diff != "" {
+		t.Errorf("DirectComposersOf: unexpected diff (-want +got):\n%s", diff)
+	}
+	expected = []fidlgen.EncodedCompoundIdentifier{"fuchsia.io/Directory", "fuchsia.io/File", "fuchsia.io/Node"}
+	if diff := cmp.Diff(expected, p.ComposersOf("fuchsia.unknown/Closeable")); diff != "" {
+		t.Errorf("ComposersOf: unexpected diff (-want +got):\n%s", diff)
+	}
+	if composers := p.ComposersOf("fuchsia.io/File"); len(composers) != 0 {
+		t.Errorf("expected fuchsia.io/File to have no composers, got %v", composers)
+	}
+}
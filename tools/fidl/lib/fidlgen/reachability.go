@@ -0,0 +1,238 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// ReachableDecls gives the declarations that a set of protocols transitively
+// depend on. Each list is sorted by name.
+type ReachableDecls struct {
+	// Types lists the reachable type declarations: bits, enums, structs,
+	// tables, unions, aliases, new types, and resources.
+	Types []EncodedCompoundIdentifier
+	// Constants lists the reachable constants, e.g. those giving vector
+	// bounds or default values.
+	Constants []EncodedCompoundIdentifier
+	// Protocols lists the reachable protocols other than the selected ones,
+	// whether composed or referenced through client or server ends. Selected
+	// protocols are included only if reachable from another selected
+	// protocol.
+	Protocols []EncodedCompoundIdentifier
+	// Unresolved lists the reachable declarations which could not be looked
+	// up, e.g. those from dependency libraries when resolving against a single
+	// Root. Their own dependencies are not accounted for.
+	Unresolved []EncodedCompoundIdentifier
+}
+
+// declReferences accumulates the names of the declarations referenced by
+// other declarations. Unlike the edges of DeclDepGraph, references through
+// nullable types and protocol endpoints are included.
+type declReferences []EncodedCompoundIdentifier
+
+func (refs *declReferences) add(name EncodedCompoundIdentifier) {
+	*refs = append(*refs, name.DeclName())
+}
+
+func (refs *declReferences) addConstant(c *Constant) {
+	if c != nil && c.Kind == IdentifierConstant {
+		refs.add(c.Identifier)
+	}
+}
+
+func (refs *declReferences) addType(typ *Type) {
+	WalkType(typ, func(typ *Type) bool {
+		switch typ.Kind {
+		case IdentifierType:
+			refs.add(typ.Identifier)
+		case RequestType:
+			refs.add(typ.RequestSubtype)
+		case HandleType:
+			if typ.ResourceIdentifier != "" {
+				refs.add(EncodedCompoundIdentifier(typ.ResourceIdentifier))
+			}
+		}
+		return true
+	})
+}
+
+func (refs *declReferences) addTypeCtor(ctor *PartialTypeConstructor) {
+	if ctor == nil {
+		return
+	}
+	if !ctor.Name.IsBuiltIn() {
+		refs.add(ctor.Name)
+	}
+	refs.addConstant(ctor.MaybeSize)
+	for i := range ctor.Args {
+		refs.addTypeCtor(&ctor.Args[i])
+	}
+}
+
+func (refs *declReferences) addDecl(decl Declaration) {
+	switch decl := decl.(type) {
+	case *Const:
+		refs.addType(&decl.Type)
+		refs.addConstant(&decl.Value)
+	case *Bits:
+		refs.addType(&decl.Type)
+		for i := range decl.Members {
+			refs.addConstant(&decl.Members[i].Value)
+		}
+	case *Enum:
+		for i := range decl.Members {
+			refs.addConstant(&decl.Members[i].Value)
+		}
+	case *Resource:
+		refs.addType(&decl.Type)
+		for i := range decl.Properties {
+			refs.addType(&decl.Properties[i].Type)
+		}
+	case *Protocol:
+		for _, composed := range decl.Composed {
+			refs.add(composed.Name)
+		}
+		for i := range decl.Methods {
+			m := &decl.Methods[i]
+			for _, typ := range []*Type{m.RequestPayload, m.ResponsePayload, m.ResultType, m.ValueType, m.ErrorType} {
+				refs.addType(typ)
+			}
+		}
+	case *Service:
+		for i := range decl.Members {
+			refs.addType(&decl.Members[i].Type)
+		}
+	case *Struct:
+		for i := range decl.Members {
+			m := &decl.Members[i]
+			refs.addType(&m.Type)
+			refs.addConstant(m.MaybeDefaultValue)
+			refs.addTypeCtor(m.MaybeTypeAlias)
+		}
+	case *Table:
+		for i := range decl.Members {
+			m := &decl.Members[i]
+			if !m.Reserved {
+				refs.addType(&m.Type)
+				refs.addConstant(m.MaybeDefaultValue)
+				refs.addTypeCtor(m.MaybeTypeAlias)
+			}
+		}
+	case *Union:
+		for i := range decl.Members {
+			m := &decl.Members[i]
+			if !m.Reserved {
+				refs.addType(&m.Type)
+				refs.addTypeCtor(m.MaybeTypeAlias)
+			}
+		}
+	case *TypeAlias:
+		refs.addTypeCtor(&decl.PartialTypeConstructor)
+	case *NewType:
+		refs.addType(&decl.Type)
+		refs.addTypeCtor(decl.Alias)
+	}
+}
+
+// ReachableFrom computes the declarations that the given protocols
+// transitively depend on, looking declarations up through decls. To account
+// for the declarations of dependency libraries, decls should be a Compilation
+// or a Program. An error is returned if a given name does not refer to a
+// protocol.
+func ReachableFrom(decls DeclResolver, protocols []EncodedCompoundIdentifier) (ReachableDecls, error) {
+	selected := make(map[EncodedCompoundIdentifier]struct{})
+	var queue []Declaration
+	for _, name := range protocols {
+		decl, ok := decls.LookupDecl(name)
+		if !ok {
+			return ReachableDecls{}, fmt.Errorf("protocol %s not found", name)
+		}
+		if _, ok := decl.(*Protocol); !ok || decl.GetName() != name {
+			return ReachableDecls{}, fmt.Errorf("%s is not a protocol", name)
+		}
+		selected[name] = struct{}{}
+		queue = append(queue, decl)
+	}
+
+	var reachable ReachableDecls
+	seen := make(map[EncodedCompoundIdentifier]struct{})
+	for len(queue) > 0 {
+		var refs declReferences
+		refs.addDecl(queue[0])
+		queue = queue[1:]
+		for _, name := range refs {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+
+			decl, ok := decls.LookupDecl(name)
+			if !ok {
+				reachable.Unresolved = append(reachable.Unresolved, name)
+				continue
+			}
+			switch decl.(type) {
+			case *Const:
+				reachable.Constants = append(reachable.Constants, name)
+			case *Protocol:
+				reachable.Protocols = append(reachable.Protocols, name)
+			case *Service:
+				// Services cannot be referenced by other declarations.
+			default:
+				reachable.Types = append(reachable.Types, name)
+			}
+			if _, ok := selected[name]; !ok {
+				queue = append(queue, decl)
+			}
+		}
+	}
+
+	for _, names := range [][]EncodedCompoundIdentifier{
+		reachable.Types, reachable.Constants, reachable.Protocols, reachable.Unresolved,
+	} {
+		sortNames(names)
+	}
+	return reachable, nil
+}
+
+// ExternalDependencies returns the names of the declarations of other
+// libraries that a declaration depends on, sorted: those it references
+// directly, and those referenced by the declarations of its own library that
+// it transitively depends on. References through nullable types and protocol
+// endpoints are included, as they too require the other library to be
+// imported. The declarations of other libraries are not traversed, and local
+// ones are looked up through decls.
+func ExternalDependencies(decls DeclResolver, decl Declaration) []EncodedCompoundIdentifier {
+	library := decl.GetName().LibraryName()
+	var external []EncodedCompoundIdentifier
+	seen := map[EncodedCompoundIdentifier]struct{}{decl.GetName(): {}}
+	queue := []Declaration{decl}
+	for len(queue) > 0 {
+		var refs declReferences
+		refs.addDecl(queue[0])
+		queue = queue[1:]
+		for _, name := range refs {
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			if name.LibraryName() != library {
+				external = append(external, name)
+			} else if local, ok := decls.LookupDecl(name); ok {
+				queue = append(queue, local)
+			}
+		}
+	}
+	return sortNames(external)
+}
+
+// HasExternalDependencies returns whether a declaration depends on any
+// declaration of another library, as per ExternalDependencies. Backends can
+// use this to decide between emitting a self-contained definition and
+// importing the bindings of dependency libraries.
+func HasExternalDependencies(decls DeclResolver, decl Declaration) bool {
+	return len(ExternalDependencies(decls, decl)) > 0
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestReachableFrom(t *testing.T) {
+	request := structDecl("example/PFooRequest", "points", "other", "maybe", "ext")
+	request.Members[0].Type = fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifierType("example/Point")}
+	request.Members[0].MaybeTypeAlias = &fidlgen.PartialTypeConstructor{
+		Name:      "example/Points",
+		MaybeSize: &fidlgen.Constant{Kind: fidlgen.IdentifierConstant, Identifier: "example/MAX"},
+	}
+	request.Members[1].Type = fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/Other", ProtocolTransport: "Channel"}
+	request.Members[2].Type = fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/Maybe", Nullable: true}
+	request.Members[3].Type = *identifierType("dep/External")
+
+	root := fidlgen.Root{
+		Name:        "example",
+		Consts:      []fidlgen.Const{{Decl: fidlgen.Decl{Name: "example/MAX"}}},
+		Structs:     []fidlgen.Struct{request, structDecl("example/Point"), structDecl("example/Maybe"), structDecl("example/Unused")},
+		TypeAliases: []fidlgen.TypeAlias{{Decl: fidlgen.Decl{Name: "example/Points"}}},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl:     fidlgen.Decl{Name: "example/P"},
+				Composed: []fidlgen.Decl{{Name: "example/Base"}},
+				Methods: []fidlgen.Method{
+					{Name: "Foo", HasRequest: true, RequestPayload: identifierType("example/PFooRequest")},
+				},
+			},
+			{Decl: fidlgen.Decl{Name: "example/Base"}},
+			{Decl: fidlgen.Decl{Name: "example/Other"}},
+			{Decl: fidlgen.Decl{Name: "example/Unreferenced"}},
+		},
+	}
+
+	reachable, err := fidlgen.ReachableFrom(&root, []fidlgen.EncodedCompoundIdentifier{"example/P"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fidlgen.ReachableDecls{
+		Types: []fidlgen.EncodedCompoundIdentifier{
+			"example/Maybe", "example/PFooRequest", "example/Point", "example/Points",
+		},
+		Constants:  []fidlgen.EncodedCompoundIdentifier{"example/MAX"},
+		Protocols:  []fidlgen.EncodedCompoundIdentifier{"example/Base", "example/Other"},
+		Unresolved: []fidlgen.EncodedCompoundIdentifier{"dep/External"},
+	}
+	if diff := cmp.Diff(expected, reachable); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := fidlgen.ReachableFrom(&root, []fidlgen.EncodedCompoundIdentifier{"example/Point"}); err == nil {
+		t.Errorf("expected an error for a non-protocol")
+	}
+	if _, err := fidlgen.ReachableFrom(&root, []fidlgen.EncodedCompoundIdentifier{"example/Missing"}); err == nil {
+		t.Errorf("expected an error for a missing protocol")
+	}
+}
+
+func TestExternalDependencies(t *testing.T) {
+	outer := structDecl("example/Outer", "inner", "local")
+	outer.Members[0].Type = *identifierType("example/Inner")
+	outer.Members[1].Type = *identifierType("example/Local")
+	inner := structDecl("example/Inner", "client", "handle", "maybe")
+	inner.Members[0].Type = fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "dep/P", ProtocolTransport: "Channel"}
+	inner.Members[1].Type = fidlgen.Type{Kind: fidlgen.HandleType, ResourceIdentifier: "zx/Handle"}
+	inner.Members[2].Type = fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "dep/S", Nullable: true}
+	bits := fidlgen.Bits{
+		LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Flags"}},
+		Type:       fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32},
+		Members: []fidlgen.BitsMember{
+			{Name: "A", Value: fidlgen.Constant{Kind: fidlgen.IdentifierConstant, Identifier: "example/Flags.B"}},
+		},
+	}
+	example := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{outer, inner, structDecl("example/Local")},
+		Bits:    []fidlgen.Bits{bits},
+	}
+	p, err := fidlgen.NewProgram([]fidlgen.Root{example})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decl, _ := p.LookupDecl("example/Outer")
+	expected := []fidlgen.EncodedCompoundIdentifier{"dep/P", "dep/S", "zx/Handle"}
+	if diff := cmp.Diff(expected, fidlgen.ExternalDependencies(p, decl)); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	if !fidlgen.HasExternalDependencies(p, decl) {
+		t.Errorf("expected example/Outer to have external dependencies")
+	}
+
+	for _, name := range []fidlgen.EncodedCompoundIdentifier{"example/Local", "example/Flags"} {
+		decl, _ := p.LookupDecl(name)
+		if fidlgen.HasExternalDependencies(p, decl) {
+			t.Errorf("expected %s to have no external dependencies, got %v", name, fidlgen.ExternalDependencies(p, decl))
+		}
+	}
+}
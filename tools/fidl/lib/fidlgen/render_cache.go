@@ -0,0 +1,158 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"text/template"
+)
+
+// Generators running in a long-lived process, e.g. in watch mode, render the
+// same declarations over and over while only a few of them change. A
+// RenderCache keeps the outputs of templates, keyed by the generator, the
+// template and the full content of the data the template was executed with,
+// so that unchanged declarations are not rendered again.
+
+// RenderCacheStats counts the lookups of a RenderCache.
+type RenderCacheStats struct {
+	// Hits counts the renderings served from the cache.
+	Hits int
+	// Misses counts the renderings which executed a template.
+	Misses int
+}
+
+type renderCacheKey struct {
+	generator uint64
+	template  string
+	data      string
+}
+
+type renderCacheEntry struct {
+	key renderCacheKey
+	out []byte
+}
+
+// RenderCache caches rendered outputs, evicting the least recently used ones
+// beyond a maximum number of entries. It may be shared by generators, also
+// across goroutines: outputs are keyed by generator, so generators do not
+// collide.
+type RenderCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	// lru holds the entries, most recently used first.
+	lru     *list.List
+	entries map[renderCacheKey]*list.Element
+	stats   RenderCacheStats
+}
+
+// NewRenderCache creates an empty RenderCache holding at most maxEntries
+// outputs, which must be positive.
+func NewRenderCache(maxEntries int) *RenderCache {
+	if maxEntries <= 0 {
+		panic("a RenderCache must hold at least one entry")
+	}
+	return &RenderCache{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[renderCacheKey]*list.Element),
+	}
+}
+
+// Stats returns the number of hits and misses of the cache so far.
+func (c *RenderCache) Stats() RenderCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Len returns the number of cached outputs.
+func (c *RenderCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *RenderCache) get(key renderCacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(e)
+	return e.Value.(*renderCacheEntry).out, true
+}
+
+func (c *RenderCache) put(key renderCacheKey, out []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*renderCacheEntry).out = out
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&renderCacheEntry{key, out})
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		delete(c.entries, oldest.Value.(*renderCacheEntry).key)
+		c.lru.Remove(oldest)
+	}
+}
+
+// SetRenderCache sets the cache used by ExecuteCachedTemplate. A nil cache
+// disables caching, which is the default.
+func (gen *Generator) SetRenderCache(c *RenderCache) {
+	gen.renderCache = c
+}
+
+// ExecuteCachedTemplate executes a template with the given data, typically
+// that of a single declaration. If the generator has a RenderCache, the output
+// is cached: executing the same template again with data of the same content
+// returns the cached output.
+//
+// The content of the data is its JSON encoding, so the template must only
+// read its exported fields, and the methods it calls and the template
+// functions must only depend on those. Data which cannot be encoded is
+// rendered without the cache.
+func (gen *Generator) ExecuteCachedTemplate(tmpl string, data interface{}) ([]byte, error) {
+	if gen.renderCache == nil {
+		return gen.ExecuteTemplate(tmpl, data)
+	}
+	content, err := json.Marshal(data)
+	if err != nil {
+		return gen.ExecuteTemplate(tmpl, data)
+	}
+	key := renderCacheKey{generator: gen.id, template: tmpl, data: string(content)}
+	if out, ok := gen.renderCache.get(key); ok {
+		return out, nil
+	}
+	out, err := gen.ExecuteTemplate(tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+	gen.renderCache.put(key, out)
+	return out, nil
+}
+
+// lastGeneratorID is the id of the last generator created.
+var lastGeneratorID uint64
+
+// renderCacheFuncs returns the template functions of the generator which
+// use its cache:
+//
+//   - ExecuteCachedTemplate executes a template with the given data, like the
+//     template action, through ExecuteCachedTemplate.
+func (gen *Generator) renderCacheFuncs() template.FuncMap {
+	return template.FuncMap{
+		"ExecuteCachedTemplate": func(tmpl string, data interface{}) (string, error) {
+			out, err := gen.ExecuteCachedTemplate(tmpl, data)
+			return string(out), err
+		},
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"text/template"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestRenderCache(t *testing.T) {
+	renders := 0
+	funcs := template.FuncMap{
+		"count": func() string {
+			renders++
+			return ""
+		},
+	}
+	templates := fstest.MapFS{
+		"decl.tmpl": {Data: []byte(`
+{{- define "Decl" -}}{{ count }}struct {{ .Name }} {{ len .Members }} line {{ .Location.Line }}{{- end -}}
+{{- define "File" -}}{{ range . }}{{ ExecuteCachedTemplate "Decl" . }};{{ end }}{{- end -}}
+`)},
+	}
+	newGenerator := func() *fidlgen.Generator {
+		return fidlgen.NewGenerator("Test", templates, fidlgen.NewFormatter(""), funcs)
+	}
+	cache := fidlgen.NewRenderCache(3)
+	gen := newGenerator()
+	gen.SetRenderCache(cache)
+
+	render := func(gen *fidlgen.Generator, s fidlgen.Struct, want string) {
+		t.Helper()
+		out, err := gen.ExecuteCachedTemplate("Decl", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != want {
+			t.Errorf("got %q, want %q", out, want)
+		}
+	}
+	s := structDecl("example/S", "a")
+	render(gen, s, "struct example/S 1 line 0")
+	render(gen, s, "struct example/S 1 line 0")
+	if renders != 1 {
+		t.Errorf("got %d renders of an unchanged declaration, want 1", renders)
+	}
+
+	// Data are told apart by their full content, locations included.
+	moved := s
+	moved.Location.Line = 7
+	render(gen, moved, "struct example/S 1 line 7")
+	render(gen, structDecl("example/S", "a", "b"), "struct example/S 2 line 0")
+	// Generators do not share outputs, even with the same templates.
+	other := newGenerator()
+	other.SetRenderCache(cache)
+	render(other, s, "struct example/S 1 line 0")
+	if renders != 4 {
+		t.Errorf("got %d renders, want 4", renders)
+	}
+
+	// The cache holds 3 outputs: that of s for gen, the least recently used,
+	// was evicted.
+	if got := cache.Len(); got != 3 {
+		t.Errorf("got %d cached outputs, want 3", got)
+	}
+	render(gen, s, "struct example/S 1 line 0")
+	if renders != 5 {
+		t.Errorf("got %d renders, want 5", renders)
+	}
+	if got, want := cache.Stats(), (fidlgen.RenderCacheStats{Hits: 1, Misses: 5}); got != want {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
+
+	// Templates render through the cache with ExecuteCachedTemplate.
+	out, err := gen.ExecuteTemplate("File", []fidlgen.Struct{s, s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "struct example/S 1 line 0;struct example/S 1 line 0;"; string(out) != want {
+		t.Errorf("got %q, want %q", out, want)
+	}
+	if renders != 5 {
+		t.Errorf("got %d renders, want 5", renders)
+	}
+
+	// Without a cache, templates are always executed.
+	gen.SetRenderCache(nil)
+	render(gen, s, "struct example/S 1 line 0")
+	if renders != 6 {
+		t.Errorf("got %d renders, want 6", renders)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// Handle types are instances of a resource_definition, e.g. `zx.Handle` or
+// `fdf.handle`, whose properties they constrain:
+//
+//	resource_definition Handle : uint32 {
+//	    properties {
+//	        subtype ObjType;
+//	        rights Rights;
+//	    };
+//	};
+//
+// The IR flattens the values of the properties into the subtype, obj_type and
+// rights of the type, as if all handles were zx handles. The utilities below
+// relate them back to the resource declaring them, so that backends can
+// handle resources of other libraries the same way.
+//
+// Type does not carry property values generically: fidlc only emits those of
+// the subtype and rights properties, which are the only ones it supports, so
+// a resource declaring any other property cannot be represented yet.
+
+// Resource properties whose values the IR carries.
+const (
+	ResourceSubtypeProperty = "subtype"
+	ResourceRightsProperty  = "rights"
+)
+
+// Property returns the property of the resource with the given name.
+func (r *Resource) Property(name string) (*ResourceProperty, bool) {
+	for i := range r.Properties {
+		if string(r.Properties[i].Name) == name {
+			return &r.Properties[i], true
+		}
+	}
+	return nil, false
+}
+
+// HandleResource returns the name of the resource the type is an instance
+// of, e.g. `zx/Handle`, or "" if it is not a handle type.
+func (t *Type) HandleResource() EncodedCompoundIdentifier {
+	if t.Kind != HandleType {
+		return ""
+	}
+	return EncodedCompoundIdentifier(t.ResourceIdentifier)
+}
+
+// ResourcePropertyValue is the value a handle type gives to a property of its
+// resource.
+type ResourcePropertyValue struct {
+	// Property is the property, as declared by the resource.
+	Property *ResourceProperty
+	// Value is the value of the property, as resolved by fidlc.
+	Value uint64
+	// Member is the member of the enum type of the property with the value,
+	// e.g. `CHANNEL` for the subtype of `zx.Handle:CHANNEL`. It is empty if
+	// the property is not of an enum type, or if no member has the value.
+	Member Identifier
+}
+
+// ResourcePropertyValues resolves the resource of a handle type through
+// decls, and returns the values the type gives to its properties, in
+// declaration order. The resource is resolved as for other declarations: for
+// resources of dependency libraries, decls should be a Compilation or a
+// Program. An error is returned if the resource cannot be resolved, or if it
+// declares a property other than subtype and rights, whose values the IR
+// does not carry.
+func (t *Type) ResourcePropertyValues(decls DeclResolver) (*Resource, []ResourcePropertyValue, error) {
+	name := t.HandleResource()
+	if name == "" {
+		return nil, nil, fmt.Errorf("%s is not a handle type", t.Kind)
+	}
+	decl, ok := decls.LookupDecl(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("resource %s not found", name)
+	}
+	resource, ok := decl.(*Resource)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not a resource", name)
+	}
+	var values []ResourcePropertyValue
+	for i := range resource.Properties {
+		property := &resource.Properties[i]
+		v := ResourcePropertyValue{Property: property}
+		switch string(property.Name) {
+		case ResourceSubtypeProperty:
+			v.Value = uint64(t.ObjType)
+		case ResourceRightsProperty:
+			v.Value = uint64(t.HandleRights)
+		default:
+			return nil, nil, fmt.Errorf("%s has property %s, whose values the IR does not carry", name, property.Name)
+		}
+		if property.Type.Kind == IdentifierType {
+			if decl, ok := decls.LookupDecl(property.Type.Identifier); ok {
+				if enum, ok := decl.(*Enum); ok {
+					v.Member = enumMemberWithValue(enum, v.Value)
+				}
+			}
+		}
+		values = append(values, v)
+	}
+	return resource, values, nil
+}
+
+// enumMemberWithValue returns the name of the first member of an enum with
+// the given value, or "" if there is none.
+func enumMemberWithValue(enum *Enum, value uint64) Identifier {
+	for _, member := range enum.Members {
+		if v, err := member.Value.AsUint64(enum.Type); err == nil && v == value {
+			return member.Name
+		}
+	}
+	return ""
+}
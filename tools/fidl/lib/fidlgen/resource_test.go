@@ -0,0 +1,85 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func handleResource(name fidlgen.EncodedCompoundIdentifier, properties ...fidlgen.ResourceProperty) fidlgen.Resource {
+	return fidlgen.Resource{
+		Decl:       fidlgen.Decl{Name: name},
+		Type:       fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32},
+		Properties: properties,
+	}
+}
+
+func resourceProperty(name string, typ fidlgen.Type) fidlgen.ResourceProperty {
+	return fidlgen.ResourceProperty{Decl: fidlgen.Decl{Name: fidlgen.EncodedCompoundIdentifier(name)}, Type: typ}
+}
+
+func TestResourcePropertyValues(t *testing.T) {
+	objType := enumDecl(fidlgen.Uint32, enumMember("NONE", "0"), enumMember("CHANNEL", "4"))
+	objType.Name = "zx/ObjType"
+	rightsType := fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "zx/Rights"}
+	zx := fidlgen.Root{
+		Name:  "zx",
+		Enums: []fidlgen.Enum{objType},
+		Resources: []fidlgen.Resource{
+			handleResource("zx/Handle",
+				resourceProperty("subtype", fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "zx/ObjType"}),
+				resourceProperty("rights", rightsType),
+			),
+			handleResource("zx/Odd", resourceProperty("color", rightsType)),
+		},
+	}
+	compilation, err := fidlgen.NewCompilation(fidlgen.Root{Name: "example"}, []fidlgen.Root{zx})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channel := fidlgen.Type{
+		Kind:               fidlgen.HandleType,
+		HandleSubtype:      fidlgen.HandleSubtypeChannel,
+		HandleRights:       fidlgen.HandleRightsRead | fidlgen.HandleRightsWrite,
+		ObjType:            uint32(fidlgen.ObjectTypeChannel),
+		ResourceIdentifier: "zx/Handle",
+	}
+	if actual := channel.HandleResource(); actual != "zx/Handle" {
+		t.Errorf("got resource %s, want zx/Handle", actual)
+	}
+	resource, values, err := channel.ResourcePropertyValues(compilation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resource.Name != "zx/Handle" || len(values) != 2 {
+		t.Fatalf("unexpected resource %s with values %+v", resource.Name, values)
+	}
+	if v := values[0]; v.Property.Name != "subtype" || v.Value != uint64(fidlgen.ObjectTypeChannel) || v.Member != "CHANNEL" {
+		t.Errorf("unexpected subtype value: %+v", v)
+	}
+	if v := values[1]; v.Property.Name != "rights" || v.Value != uint64(channel.HandleRights) || v.Member != "" {
+		t.Errorf("unexpected rights value: %+v", v)
+	}
+	if p, ok := resource.Property("rights"); !ok || p != values[1].Property {
+		t.Errorf("rights property not found")
+	}
+
+	odd := channel
+	odd.ResourceIdentifier = "zx/Odd"
+	missing := channel
+	missing.ResourceIdentifier = "zx/Missing"
+	notHandle := fidlgen.Type{Kind: fidlgen.StringType}
+	for _, typ := range []fidlgen.Type{odd, missing, notHandle} {
+		if _, _, err := typ.ResourcePropertyValues(compilation); err == nil {
+			t.Errorf("%s: expected an error", typ.ResourceIdentifier)
+		}
+	}
+	if actual := notHandle.HandleResource(); actual != "" {
+		t.Errorf("got resource %s for a string type", actual)
+	}
+}
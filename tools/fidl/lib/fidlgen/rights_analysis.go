@@ -0,0 +1,155 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A handle sent over a protocol keeps the rights of the sender unless its type
+// reduces them, e.g. `zx.Handle:<VMO, zx.Rights.READ>`. Security reviews look
+// for the handles of a protocol which are sent with all of their rights, i.e.
+// HandleRightsSameRights. MethodHandleRights lists the handles of each message
+// of the protocols of a library, so that tools need not grep FIDL sources.
+
+// MessageDirection tells which message of a method a payload is carried by.
+type MessageDirection string
+
+const (
+	RequestMessage  MessageDirection = "request"
+	ResponseMessage MessageDirection = "response"
+	EventMessage    MessageDirection = "event"
+)
+
+// HandleFieldRights describes a handle held by the payload of a message.
+type HandleFieldRights struct {
+	// Protocol is the name of the protocol, in which the method may be
+	// composed.
+	Protocol EncodedCompoundIdentifier
+	// Method is the name of the method.
+	Method Identifier
+	// Direction tells which message of the method holds the handle.
+	Direction MessageDirection
+	// Path locates the handle in the payload, as the names of the members
+	// leading to it, with `[]` standing for the elements of vectors and
+	// arrays, e.g. `["buffers", "[]", "vmo"]`.
+	Path []string
+	// Type is the type of the handle.
+	Type Type
+}
+
+// Unconstrained returns whether the handle is sent with all of the rights of
+// the sender, i.e. whether its type does not reduce them.
+func (f HandleFieldRights) Unconstrained() bool {
+	return f.Type.HandleRights == HandleRightsSameRights
+}
+
+// String describes the handle, e.g.
+// `example.P.Send request buffers.[].vmo: zx.Handle:<VMO, zx.Rights.READ>`.
+func (f HandleFieldRights) String() string {
+	return fmt.Sprintf("%s.%s %s %s: %s", fidlSyntaxName(f.Protocol), f.Method, f.Direction, strings.Join(f.Path, "."), f.Type)
+}
+
+// MethodHandleRights lists the handles held by the messages of the methods of
+// the protocols of the library, composed ones included, in declaration order.
+// Layouts are resolved through decls: for payloads referring to layouts of
+// other libraries, this should be a Compilation or a Program. Protocol
+// endpoints are not listed, since their rights are set by the bindings rather
+// than by their types. An error is returned if a layout cannot be resolved.
+func (r *Root) MethodHandleRights(decls DeclResolver) ([]HandleFieldRights, error) {
+	var fields []HandleFieldRights
+	for _, p := range r.Protocols {
+		for i := range p.Methods {
+			m := &p.Methods[i]
+			field := HandleFieldRights{Protocol: p.Name, Method: m.Name}
+			if m.HasRequest && m.RequestPayload != nil {
+				field.Direction = RequestMessage
+				found, err := payloadHandles(field, m.RequestPayload, decls)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, found...)
+			}
+			if m.HasResponse && m.ResponsePayload != nil {
+				field.Direction = ResponseMessage
+				if !m.HasRequest {
+					field.Direction = EventMessage
+				}
+				found, err := payloadHandles(field, m.ResponsePayload, decls)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, found...)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// payloadHandles lists the handles held by a payload, filling in the given
+// field description with their paths and types.
+func payloadHandles(field HandleFieldRights, payload *Type, decls DeclResolver) ([]HandleFieldRights, error) {
+	var fields []HandleFieldRights
+	// Layouts on the current path are not walked again, so that recursive
+	// layouts terminate.
+	onPath := make(map[EncodedCompoundIdentifier]struct{})
+	var walk func(path []string, typ *Type) error
+	walkMember := func(path []string, name Identifier, typ *Type) error {
+		return walk(append(path[:len(path):len(path)], string(name)), typ)
+	}
+	walk = func(path []string, typ *Type) error {
+		switch typ.Kind {
+		case HandleType:
+			f := field
+			f.Path = path
+			f.Type = *typ
+			fields = append(fields, f)
+		case ArrayType, VectorType:
+			return walk(append(path[:len(path):len(path)], "[]"), typ.ElementType)
+		case IdentifierType:
+			if _, ok := onPath[typ.Identifier]; ok {
+				return nil
+			}
+			decl, ok := decls.LookupDecl(typ.Identifier)
+			if !ok {
+				return fmt.Errorf("%s.%s %s: %s not found", field.Protocol, field.Method, field.Direction, typ.Identifier)
+			}
+			onPath[typ.Identifier] = struct{}{}
+			defer delete(onPath, typ.Identifier)
+			switch decl := decl.(type) {
+			case *Struct:
+				for i := range decl.Members {
+					if err := walkMember(path, decl.Members[i].Name, &decl.Members[i].Type); err != nil {
+						return err
+					}
+				}
+			case *Table:
+				for i := range decl.Members {
+					if decl.Members[i].Reserved {
+						continue
+					}
+					if err := walkMember(path, decl.Members[i].Name, &decl.Members[i].Type); err != nil {
+						return err
+					}
+				}
+			case *Union:
+				for i := range decl.Members {
+					if decl.Members[i].Reserved {
+						continue
+					}
+					if err := walkMember(path, decl.Members[i].Name, &decl.Members[i].Type); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(nil, payload); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestMethodHandleRights(t *testing.T) {
+	vmo := fidlgen.Type{
+		Kind:               fidlgen.HandleType,
+		HandleSubtype:      fidlgen.HandleSubtypeVmo,
+		HandleRights:       fidlgen.HandleRightsRead,
+		ResourceIdentifier: "zx/Handle",
+	}
+	event := fidlgen.Type{
+		Kind:               fidlgen.HandleType,
+		HandleSubtype:      fidlgen.HandleSubtypeEvent,
+		HandleRights:       fidlgen.HandleRightsSameRights,
+		ResourceIdentifier: "zx/Handle",
+	}
+	identifier := func(name fidlgen.EncodedCompoundIdentifier) *fidlgen.Type {
+		return &fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: name}
+	}
+	buffer := structDecl("example/Buffer")
+	buffer.Members = []fidlgen.StructMember{
+		{Name: "vmo", Type: vmo},
+		{Name: "size", Type: fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint64}},
+	}
+	sendRequest := structDecl("example/SendRequest")
+	sendRequest.Members = []fidlgen.StructMember{
+		{Name: "buffers", Type: fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifier("example/Buffer")}},
+		{Name: "node", Type: *identifier("example/Node")},
+	}
+	onSignal := structDecl("example/OnSignalRequest")
+	onSignal.Members = []fidlgen.StructMember{{Name: "event", Type: event}}
+	// A recursive layout, holding a handle itself.
+	node := fidlgen.Table{
+		ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+			LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Node"}},
+		},
+		Members: []fidlgen.TableMember{
+			{Name: "signal", Type: event},
+			{Reserved: true},
+			{Name: "next", Type: *identifier("example/Node")},
+		},
+	}
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{buffer, sendRequest, onSignal},
+		Tables:  []fidlgen.Table{node},
+		Protocols: []fidlgen.Protocol{{
+			Decl: fidlgen.Decl{Name: "example/P"},
+			Methods: []fidlgen.Method{
+				{Name: "Send", HasRequest: true, RequestPayload: identifier("example/SendRequest"), HasResponse: true},
+				{Name: "OnSignal", HasResponse: true, ResponsePayload: identifier("example/OnSignalRequest")},
+			},
+		}},
+	}
+
+	fields, err := root.MethodHandleRights(&root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		str           string
+		unconstrained bool
+	}{
+		{"example.P.Send request buffers.[].vmo: zx.Handle:<VMO, zx.Rights.READ>", false},
+		{"example.P.Send request node.signal: zx.Handle:EVENT", true},
+		{"example.P.OnSignal event event: zx.Handle:EVENT", true},
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("got %d handles, want %d: %v", len(fields), len(expected), fields)
+	}
+	for i, field := range fields {
+		if actual := field.String(); actual != expected[i].str {
+			t.Errorf("got %q, want %q", actual, expected[i].str)
+		}
+		if field.Unconstrained() != expected[i].unconstrained {
+			t.Errorf("%s: got unconstrained %t", field, field.Unconstrained())
+		}
+	}
+
+	root.Protocols[0].Methods[0].RequestPayload = identifier("example/Missing")
+	if _, err := root.MethodHandleRights(&root); err == nil {
+		t.Errorf("expected an error for an unresolved layout")
+	}
+}
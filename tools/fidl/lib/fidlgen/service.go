@@ -0,0 +1,92 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// The members of a service are client ends, e.g. `foo client_end:P;`, possibly
+// optional or over another transport than channels. The helpers below resolve
+// those constraints, so that backends do not inspect the member types
+// themselves.
+
+// ClientEnd returns the client end that the member stands for, or false if its
+// type is not a client end, which fidlc rejects. The member type is expected to
+// be marked, as DecodeJSONIr does; otherwise, as service members can only be
+// client ends, an identifier type is taken to name a protocol.
+func (m *ServiceMember) ClientEnd() (Endpoint, bool) {
+	if e := m.Type.Endpoint; e != nil {
+		return *e, e.Role == ClientEndpoint
+	}
+	if m.Type.Kind != IdentifierType {
+		return Endpoint{}, false
+	}
+	transport := m.Type.ProtocolTransport
+	if transport == "" {
+		transport = ChannelTransport
+	}
+	return Endpoint{Role: ClientEndpoint, Protocol: m.Type.Identifier, Transport: transport}, true
+}
+
+// MemberProtocol returns the protocol of the member's client end, or false if
+// the member is not a client end.
+func (m *ServiceMember) MemberProtocol() (EncodedCompoundIdentifier, bool) {
+	e, ok := m.ClientEnd()
+	return e.Protocol, ok
+}
+
+// MemberTransport returns the transport of the member's client end, Channel
+// unless specified otherwise, or false if the member is not a client end.
+func (m *ServiceMember) MemberTransport() (Transport, bool) {
+	e, ok := m.ClientEnd()
+	return e.Transport, ok
+}
+
+// ResolveProtocol returns the declaration of the protocol of the member's
+// client end, resolved through decls: for services whose members are
+// protocols of other libraries, this should be a Compilation. An error is
+// returned if the member is not a client end, or if its protocol cannot be
+// resolved.
+func (m *ServiceMember) ResolveProtocol(decls DeclResolver) (*Protocol, error) {
+	name, ok := m.MemberProtocol()
+	if !ok {
+		return nil, fmt.Errorf("service member %s is not a client end, found %s", m.Name, m.Type.Kind)
+	}
+	decl, ok := decls.LookupDecl(name)
+	if !ok {
+		return nil, fmt.Errorf("service member %s: protocol %s cannot be resolved", m.Name, name)
+	}
+	p, ok := decl.(*Protocol)
+	if !ok {
+		return nil, fmt.Errorf("service member %s: %s is not a protocol", m.Name, name)
+	}
+	return p, nil
+}
+
+// IsOptional returns whether the member's client end is optional, i.e.
+// `client_end:<P, optional>`.
+func (m *ServiceMember) IsOptional() bool {
+	return m.Type.Nullable
+}
+
+// Validate checks that the members of the service are client ends, all over
+// the same transport.
+func (s *Service) Validate() error {
+	var transport Transport
+	for i := range s.Members {
+		m := &s.Members[i]
+		e, ok := m.ClientEnd()
+		if !ok {
+			return fmt.Errorf("%s.%s: service members must be client ends, found %s", s.Name, m.Name, m.Type.Kind)
+		}
+		if transport == "" {
+			transport = e.Transport
+		} else if e.Transport != transport {
+			return fmt.Errorf("%s.%s: service members must share a transport, found %s and %s", s.Name, m.Name, transport, e.Transport)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestServiceMembers(t *testing.T) {
+	root := fidlgen.Root{
+		Name: "example",
+		Protocols: []fidlgen.Protocol{
+			{Decl: fidlgen.Decl{Name: "example/P"}},
+		},
+		Services: []fidlgen.Service{
+			{
+				Decl: fidlgen.Decl{Name: "example/S"},
+				Members: []fidlgen.ServiceMember{
+					{Name: "required", Type: fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/P", ProtocolTransport: fidlgen.DriverTransport}},
+					{Name: "optional", Type: fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/P", ProtocolTransport: fidlgen.DriverTransport, Nullable: true}},
+				},
+			},
+		},
+		Decls: fidlgen.DeclMap{
+			"example/P": fidlgen.ProtocolDeclType,
+			"example/S": fidlgen.ServiceDeclType,
+		},
+	}
+	// The helpers resolve member types whether or not they are marked.
+	marked := root
+	marked.Services = []fidlgen.Service{root.Services[0]}
+	marked.Services[0].Members = append([]fidlgen.ServiceMember{}, root.Services[0].Members...)
+	marked.MarkEndpointTypes()
+
+	for _, r := range []fidlgen.Root{root, marked} {
+		s := &r.Services[0]
+		if err := s.Validate(); err != nil {
+			t.Errorf("Validate() = %s", err)
+		}
+		for i, optional := range []bool{false, true} {
+			m := &s.Members[i]
+			if protocol, ok := m.MemberProtocol(); !ok || protocol != "example/P" {
+				t.Errorf("%s: got protocol %s, %t", m.Name, protocol, ok)
+			}
+			if transport, ok := m.MemberTransport(); !ok || transport != fidlgen.DriverTransport {
+				t.Errorf("%s: got transport %s, %t", m.Name, transport, ok)
+			}
+			if got := m.IsOptional(); got != optional {
+				t.Errorf("%s: got IsOptional() = %t, want %t", m.Name, got, optional)
+			}
+			if p, err := m.ResolveProtocol(&r); err != nil || p.Name != "example/P" {
+				t.Errorf("%s: got protocol %+v, %v", m.Name, p, err)
+			}
+		}
+	}
+
+	// Transports default to Channel.
+	channel := fidlgen.ServiceMember{Name: "m", Type: fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/P"}}
+	if transport, ok := channel.MemberTransport(); !ok || transport != fidlgen.ChannelTransport {
+		t.Errorf("got transport %s, %t", transport, ok)
+	}
+}
+
+func TestServiceValidate(t *testing.T) {
+	clientEnd := func(name fidlgen.Identifier, transport fidlgen.Transport) fidlgen.ServiceMember {
+		return fidlgen.ServiceMember{Name: name, Type: fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/P", ProtocolTransport: transport}}
+	}
+	serverEnd := fidlgen.ServiceMember{Name: "server", Type: fidlgen.Type{Kind: fidlgen.RequestType, RequestSubtype: "example/P"}}
+	serverEnd.Type.Endpoint = &fidlgen.Endpoint{Role: fidlgen.ServerEndpoint, Protocol: "example/P", Transport: fidlgen.ChannelTransport}
+	for name, members := range map[string][]fidlgen.ServiceMember{
+		"server end": {serverEnd},
+		"primitive":  {{Name: "p", Type: fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}}},
+		"transports": {clientEnd("a", fidlgen.ChannelTransport), clientEnd("b", fidlgen.DriverTransport)},
+	} {
+		s := fidlgen.Service{Decl: fidlgen.Decl{Name: "example/S"}, Members: members}
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestServiceMemberResolveProtocol(t *testing.T) {
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{structDecl("example/NotAProtocol")},
+	}
+	for _, m := range []fidlgen.ServiceMember{
+		{Name: "missing", Type: *identifierType("other/Missing")},
+		{Name: "struct", Type: *identifierType("example/NotAProtocol")},
+		{Name: "primitive", Type: fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}},
+	} {
+		if _, err := m.ResolveProtocol(&root); err == nil {
+			t.Errorf("%s: expected an error", m.Name)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Flipping the strictness of a bits, enum, or union changes how it is
+// validated on the wire: unknown members are rejected by a strict type and
+// accepted by a flexible one. Any type that embeds it, directly or through
+// other types, and any method whose payload reaches it inherits the change.
+// Soft transitions therefore need to account for all of those, including the
+// ones declared in downstream libraries.
+
+// StrictnessImpact lists what would change wire behavior if the strictness of
+// a declaration were flipped.
+type StrictnessImpact struct {
+	// Decl is the declaration whose strictness would be flipped.
+	Decl EncodedCompoundIdentifier
+	// Types lists the declarations which transitively contain Decl, sorted.
+	// Decl itself is not included.
+	Types []EncodedCompoundIdentifier
+	// Methods lists the methods whose request or response payload transitively
+	// contains Decl, as member-qualified identifiers (e.g.,
+	// "my.library/MyProtocol.MyMethod"), sorted. Methods composed into other
+	// protocols are listed once per composing protocol.
+	Methods []EncodedCompoundIdentifier
+	// Libraries lists the libraries declaring Decl or any of the affected
+	// types or methods, sorted.
+	Libraries []EncodedLibraryIdentifier
+}
+
+// collectTypeIdentifiers appends the declarations referenced by a type,
+// looking through the element types of arrays and vectors.
+func collectTypeIdentifiers(typ *Type, ids []EncodedCompoundIdentifier) []EncodedCompoundIdentifier {
+	for ; typ != nil; typ = typ.ElementType {
+		if typ.Kind == IdentifierType {
+			ids = append(ids, typ.Identifier)
+		}
+	}
+	return ids
+}
+
+// containedTypes returns the declarations referenced by the members of a
+// declaration, i.e. the declarations it embeds on the wire.
+func containedTypes(decl Declaration) []EncodedCompoundIdentifier {
+	var ids []EncodedCompoundIdentifier
+	switch decl := decl.(type) {
+	case *Struct:
+		for i := range decl.Members {
+			ids = collectTypeIdentifiers(&decl.Members[i].Type, ids)
+		}
+	case *Table:
+		for i := range decl.Members {
+			if !decl.Members[i].Reserved {
+				ids = collectTypeIdentifiers(&decl.Members[i].Type, ids)
+			}
+		}
+	case *Union:
+		for i := range decl.Members {
+			if !decl.Members[i].Reserved {
+				ids = collectTypeIdentifiers(&decl.Members[i].Type, ids)
+			}
+		}
+	case *NewType:
+		ids = collectTypeIdentifiers(&decl.Type, ids)
+	}
+	return ids
+}
+
+// methodTypes returns the declarations referenced by the payloads of a method.
+func methodTypes(m *Method) []EncodedCompoundIdentifier {
+	var ids []EncodedCompoundIdentifier
+	for _, typ := range []*Type{m.RequestPayload, m.ResponsePayload, m.ResultType, m.ValueType, m.ErrorType} {
+		ids = collectTypeIdentifiers(typ, ids)
+	}
+	return ids
+}
+
+// StrictnessImpact computes what would change wire behavior if the strictness
+// of the named bits, enum, or union were flipped, across all libraries of the
+// program.
+func (p *Program) StrictnessImpact(name EncodedCompoundIdentifier) (StrictnessImpact, error) {
+	decl, ok := p.LookupDecl(name)
+	if !ok || decl.GetName() != name {
+		return StrictnessImpact{}, fmt.Errorf("declaration %s not found", name)
+	}
+	switch decl.(type) {
+	case *Bits, *Enum, *Union:
+	default:
+		return StrictnessImpact{}, fmt.Errorf("declaration %s does not have a strictness", name)
+	}
+
+	// Map each declaration to the declarations embedding it.
+	containers := make(map[EncodedCompoundIdentifier][]EncodedCompoundIdentifier)
+	for _, decl := range p.decls {
+		for _, id := range containedTypes(decl) {
+			containers[id] = append(containers[id], decl.GetName())
+		}
+	}
+
+	affected := map[EncodedCompoundIdentifier]struct{}{name: {}}
+	queue := []EncodedCompoundIdentifier{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, container := range containers[current] {
+			if _, ok := affected[container]; !ok {
+				affected[container] = struct{}{}
+				queue = append(queue, container)
+			}
+		}
+	}
+
+	impact := StrictnessImpact{Decl: name}
+	libraries := map[EncodedLibraryIdentifier]struct{}{name.LibraryName(): {}}
+	for id := range affected {
+		if id != name {
+			impact.Types = append(impact.Types, id)
+			libraries[id.LibraryName()] = struct{}{}
+		}
+	}
+	p.ForEachLibrary(func(root *Root) {
+		for i := range root.Protocols {
+			protocol := &root.Protocols[i]
+			for j := range protocol.Methods {
+				method := &protocol.Methods[j]
+				for _, id := range methodTypes(method) {
+					if _, ok := affected[id]; ok {
+						impact.Methods = append(impact.Methods, EncodedCompoundIdentifier(
+							fmt.Sprintf("%s.%s", protocol.Name, method.Name)))
+						libraries[root.Name] = struct{}{}
+						break
+					}
+				}
+			}
+		}
+	})
+	for library := range libraries {
+		impact.Libraries = append(impact.Libraries, library)
+	}
+
+	sort.Slice(impact.Types, func(i, j int) bool {
+		return impact.Types[i] < impact.Types[j]
+	})
+	sort.Slice(impact.Methods, func(i, j int) bool {
+		return impact.Methods[i] < impact.Methods[j]
+	})
+	sort.Slice(impact.Libraries, func(i, j int) bool {
+		return impact.Libraries[i] < impact.Libraries[j]
+	})
+	return impact, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func identifierType(name fidlgen.EncodedCompoundIdentifier) *fidlgen.Type {
+	return &fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: name}
+}
+
+func TestStrictnessImpact(t *testing.T) {
+	pixel := structDecl("a/Pixel", "color")
+	pixel.Members[0].Type = *identifierType("a/Color")
+	paintRequest := structDecl("a/PainterPaintRequest", "pixels")
+	paintRequest.Members[0].Type = fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifierType("a/Pixel")}
+	a := fidlgen.Root{
+		Name: "a",
+		Enums: []fidlgen.Enum{
+			{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "a/Color"}}},
+		},
+		Structs: []fidlgen.Struct{pixel, paintRequest, structDecl("a/Unrelated", "x")},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl: fidlgen.Decl{Name: "a/Painter"},
+				Methods: []fidlgen.Method{
+					{Name: "Paint", HasRequest: true, RequestPayload: identifierType("a/PainterPaintRequest")},
+					{Name: "Clear", HasRequest: true},
+				},
+			},
+		},
+	}
+	b := fidlgen.Root{
+		Name: "b",
+		Unions: []fidlgen.Union{
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "b/Thing"}},
+				},
+				Members: []fidlgen.UnionMember{
+					{Name: "pixel", Ordinal: 1, Type: *identifierType("a/Pixel")},
+				},
+			},
+		},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl: fidlgen.Decl{Name: "b/Viewer"},
+				Methods: []fidlgen.Method{
+					{Name: "OnThing", HasResponse: true, ResponsePayload: identifierType("b/Thing")},
+				},
+			},
+		},
+	}
+	c := fidlgen.Root{Name: "c", Structs: []fidlgen.Struct{structDecl("c/Empty")}}
+	p, err := fidlgen.NewProgram([]fidlgen.Root{a, b, c})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	impact, err := p.StrictnessImpact("a/Color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := fidlgen.StrictnessImpact{
+		Decl:      "a/Color",
+		Types:     []fidlgen.EncodedCompoundIdentifier{"a/PainterPaintRequest", "a/Pixel", "b/Thing"},
+		Methods:   []fidlgen.EncodedCompoundIdentifier{"a/Painter.Paint", "b/Viewer.OnThing"},
+		Libraries: []fidlgen.EncodedLibraryIdentifier{"a", "b"},
+	}
+	if diff := cmp.Diff(expected, impact); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := p.StrictnessImpact("a/Pixel"); err == nil {
+		t.Errorf("expected an error for a struct")
+	}
+	if _, err := p.StrictnessImpact("a/Missing"); err == nil {
+		t.Errorf("expected an error for a missing declaration")
+	}
+}
+
+func TestNewProgramDuplicateLibrary(t *testing.T) {
+	if _, err := fidlgen.NewProgram([]fidlgen.Root{{Name: "a"}, {Name: "a"}}); err == nil {
+		t.Errorf("expected an error for a duplicate library")
+	}
+}
@@ -120,41 +120,15 @@ func (s Struct) buildPaddingMarkers(flatten bool, getTypeShape func(Struct) Type
 	return paddingMarkers
 }
 
-type WireFormatVersion int
-
-const (
-	_ = iota
-	WireFormatVersionV1
-	WireFormatVersionV2
-)
-
 func getTypeShapeFunc(wireFormatVersion WireFormatVersion) func(Struct) TypeShape {
-	switch wireFormatVersion {
-	case WireFormatVersionV1:
-		return func(s Struct) TypeShape {
-			return s.TypeShapeV1
-		}
-	case WireFormatVersionV2:
-		return func(s Struct) TypeShape {
-			return s.TypeShapeV2
-		}
-	default:
-		panic("unknown wire format version")
+	return func(s Struct) TypeShape {
+		return s.Shape(wireFormatVersion)
 	}
 }
 
 func getFieldShapeFunc(wireFormatVersion WireFormatVersion) func(StructMember) FieldShape {
-	switch wireFormatVersion {
-	case WireFormatVersionV1:
-		return func(s StructMember) FieldShape {
-			return s.FieldShapeV1
-		}
-	case WireFormatVersionV2:
-		return func(s StructMember) FieldShape {
-			return s.FieldShapeV2
-		}
-	default:
-		panic("unknown wire format version")
+	return func(m StructMember) FieldShape {
+		return m.FieldShape(wireFormatVersion)
 	}
 }
 
@@ -165,3 +139,46 @@ func (s Struct) BuildPaddingMarkers(wireFormatVersion WireFormatVersion) []Paddi
 func (s Struct) BuildFlattenedPaddingMarkers(wireFormatVersion WireFormatVersion, resolveStruct func(identifier EncodedCompoundIdentifier) *Struct) []PaddingMarker {
 	return s.buildPaddingMarkers(true, getTypeShapeFunc(wireFormatVersion), getFieldShapeFunc(wireFormatVersion), resolveStruct)
 }
+
+// PaddingRange is a run of consecutive padding bytes in a struct.
+type PaddingRange struct {
+	// Offset of the first padding byte (0 is the start of the struct).
+	Offset int
+	// Length is the number of padding bytes.
+	Length int
+}
+
+// paddingRanges returns the runs of 0xff bytes of a padding mask.
+func paddingRanges(mask []byte) []PaddingRange {
+	var ranges []PaddingRange
+	for i := 0; i < len(mask); i++ {
+		if mask[i] != 0xff {
+			continue
+		}
+		start := i
+		for i < len(mask) && mask[i] == 0xff {
+			i++
+		}
+		ranges = append(ranges, PaddingRange{Offset: start, Length: i - start})
+	}
+	return ranges
+}
+
+// PaddingRanges returns the padding bytes of the struct in the given wire
+// format, as ranges sorted by offset. This is the padding recorded in the
+// FieldShape of each member: that of nested structs is not included.
+func (s Struct) PaddingRanges(wireFormatVersion WireFormatVersion) []PaddingRange {
+	mask := make([]byte, s.Shape(wireFormatVersion).InlineSize)
+	s.populateFullStructMaskForStruct(mask, false, getTypeShapeFunc(wireFormatVersion), getFieldShapeFunc(wireFormatVersion), nil)
+	return paddingRanges(mask)
+}
+
+// FlattenedPaddingRanges is like PaddingRanges, but includes the padding of the
+// non-nullable structs nested in the struct, inline or in arrays. Adjacent
+// padding, e.g. the trailing padding of a nested struct followed by that of
+// the member holding it, is merged into a single range.
+func (s Struct) FlattenedPaddingRanges(wireFormatVersion WireFormatVersion, resolveStruct func(identifier EncodedCompoundIdentifier) *Struct) []PaddingRange {
+	mask := make([]byte, s.Shape(wireFormatVersion).InlineSize)
+	s.populateFullStructMaskForStruct(mask, true, getTypeShapeFunc(wireFormatVersion), getFieldShapeFunc(wireFormatVersion), resolveStruct)
+	return paddingRanges(mask)
+}
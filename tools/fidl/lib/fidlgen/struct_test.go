@@ -470,3 +470,113 @@ func TestBuildPaddingMarkersFlatteningArray(t *testing.T) {
 		t.Errorf("expected != actual (-want +got)\n%s", diff)
 	}
 }
+
+func TestEmptyStructRepresentation(t *testing.T) {
+	empty := Struct{}
+	nonEmpty := Struct{Members: []StructMember{{Name: "x"}}}
+
+	withPlaceholder := EmptyStructRepresentation{PlaceholderName: "__reserved"}
+	members := withPlaceholder.Members(empty)
+	if len(members) != 1 || members[0].Name != "__reserved" || members[0].Type.PrimitiveSubtype != Uint8 {
+		t.Errorf("got members %+v, want a single uint8 placeholder", members)
+	}
+	if _, ok := withPlaceholder.Placeholder(nonEmpty); ok {
+		t.Errorf("got a placeholder for a non-empty struct")
+	}
+	if members := withPlaceholder.Members(nonEmpty); len(members) != 1 || members[0].Name != "x" {
+		t.Errorf("got members %+v, want the struct's own", members)
+	}
+
+	if _, ok := ZeroSizedEmptyStruct.Placeholder(empty); ok {
+		t.Errorf("got a placeholder for a zero-sized empty struct")
+	}
+	if members := ZeroSizedEmptyStruct.Members(empty); len(members) != 0 {
+		t.Errorf("got members %+v, want none", members)
+	}
+}
+
+func TestPaddingRanges(t *testing.T) {
+	u8, u16, u32 := PrimitiveTypeShape(Uint8), PrimitiveTypeShape(Uint16), PrimitiveTypeShape(Uint32)
+	input := Struct{Members: make([]StructMember, 3)}
+	for _, wf := range WireFormatVersions {
+		shape, fields := TypeShapeCalculator{WireFormat: wf}.Struct([]TypeShape{u8, u32, u16})
+		input.SetShape(wf, shape)
+		for i := range fields {
+			input.Members[i].SetFieldShape(wf, fields[i])
+		}
+	}
+	expected := []PaddingRange{
+		{Offset: 1, Length: 3},
+		{Offset: 10, Length: 2},
+	}
+	for _, wf := range WireFormatVersions {
+		if diff := cmp.Diff(expected, input.PaddingRanges(wf)); diff != "" {
+			t.Errorf("%s: expected != actual (-want +got)\n%s", wf, diff)
+		}
+	}
+	if out := (Struct{}).PaddingRanges(WireFormatVersionV2); out != nil {
+		t.Errorf("got padding %v for the empty struct", out)
+	}
+}
+
+func TestFlattenedPaddingRanges(t *testing.T) {
+	var innerStructIdentifier EncodedCompoundIdentifier = "abcd"
+	innerStruct := Struct{
+		TypeShapeV2: TypeShape{
+			InlineSize: 4,
+			Alignment:  4,
+		},
+		Members: []StructMember{
+			{
+				FieldShapeV2: FieldShape{
+					Offset:  0,
+					Padding: 3,
+				},
+			},
+		},
+	}
+	input := Struct{
+		TypeShapeV2: TypeShape{
+			InlineSize: 12,
+			Alignment:  4,
+		},
+		Members: []StructMember{
+			{
+				FieldShapeV2: FieldShape{
+					Offset:  0,
+					Padding: 4,
+				},
+				Type: Type{
+					Kind:       IdentifierType,
+					Identifier: innerStructIdentifier,
+				},
+			},
+			{
+				FieldShapeV2: FieldShape{
+					Offset:  8,
+					Padding: 2,
+				},
+			},
+		},
+	}
+	resolveStruct := func(identifier EncodedCompoundIdentifier) *Struct {
+		if identifier == innerStructIdentifier {
+			return &innerStruct
+		}
+		return nil
+	}
+	expected := []PaddingRange{
+		{Offset: 1, Length: 7},
+		{Offset: 10, Length: 2},
+	}
+	if diff := cmp.Diff(expected, input.FlattenedPaddingRanges(WireFormatVersionV2, resolveStruct)); diff != "" {
+		t.Errorf("expected != actual (-want +got)\n%s", diff)
+	}
+	expected = []PaddingRange{
+		{Offset: 4, Length: 4},
+		{Offset: 10, Length: 2},
+	}
+	if diff := cmp.Diff(expected, input.PaddingRanges(WireFormatVersionV2)); diff != "" {
+		t.Errorf("expected != actual (-want +got)\n%s", diff)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// fidlc only wraps the response of a two-way method in a result union when
+// the method is flexible or uses error syntax. Templates which want to handle
+// all two-way methods through one code path otherwise need a second one for
+// plain responses. WithSyntheticResults gives those methods a result union
+// too, as a view: it is marked synthetic, and the wire format is unchanged.
+
+// SyntheticResultsOptions configures Root.WithSyntheticResults.
+type SyntheticResultsOptions struct {
+	// Disable leaves the library unchanged, so that tools can turn the
+	// transformation off with a flag without a separate code path.
+	Disable bool
+}
+
+// syntheticLayoutName returns the name fidlc gives the layouts it synthesizes
+// for a method, e.g. "example/P_Method_Result".
+func syntheticLayoutName(protocol EncodedCompoundIdentifier, method Identifier, suffix string) EncodedCompoundIdentifier {
+	return EncodedCompoundIdentifier(fmt.Sprintf("%s/%s_%s_%s", protocol.LibraryName(), protocol.Parse().Name, method, suffix))
+}
+
+// WithSyntheticResults returns a copy of the library in which every strict
+// two-way method without error syntax has a result union, as flexible methods
+// and methods using error syntax do. For each such method:
+//
+//   - a strict union named "<Protocol>_<Method>_Result" is added to the
+//     library, with a single "response" member of ordinal 1;
+//   - if the method has no response payload, an empty struct named
+//     "<Protocol>_<Method>_Response" is added as the type of that member;
+//   - ResultType and ValueType are set, and SyntheticResult is set.
+//
+// The added layouts are marked Synthetic. ResponsePayload is left as is, since
+// it describes the wire format. Composed methods are given a result union
+// named after the composing protocol. The receiver is not modified. An error
+// is returned if a synthesized name is already taken.
+func (r *Root) WithSyntheticResults(opts SyntheticResultsOptions) (Root, error) {
+	res := *r
+	if opts.Disable {
+		return res, nil
+	}
+	res.Decls = make(DeclMap, len(r.Decls))
+	for name, declType := range r.Decls {
+		res.Decls[name] = declType
+	}
+	res.Protocols = make([]Protocol, len(r.Protocols))
+	res.Structs = append([]Struct{}, r.Structs...)
+	res.Unions = append([]Union{}, r.Unions...)
+
+	declare := func(name EncodedCompoundIdentifier, declType DeclType) error {
+		if _, ok := res.Decls[name]; ok {
+			return fmt.Errorf("cannot synthesize %s: the name is already declared", name)
+		}
+		res.Decls[name] = declType
+		return nil
+	}
+
+	for i, p := range r.Protocols {
+		p.Methods = append([]Method{}, p.Methods...)
+		for j := range p.Methods {
+			m := &p.Methods[j]
+			if !m.IsTwoWay() || m.ResultType != nil {
+				continue
+			}
+
+			var value Type
+			resourceness := IsValueType
+			if m.ResponsePayload != nil {
+				value = *m.ResponsePayload
+				resourceness = r.payloadResourceness(value)
+			} else {
+				name := syntheticLayoutName(p.Name, m.Name, "Response")
+				if err := declare(name, StructDeclType); err != nil {
+					return Root{}, err
+				}
+				s := Struct{ResourceableLayoutDecl: ResourceableLayoutDecl{
+					LayoutDecl: LayoutDecl{
+						Decl:          Decl{Name: name},
+						NamingContext: NamingContext{string(p.Name.Parse().Name), string(m.Name), "Response"},
+						Synthetic:     true,
+					},
+				}}
+				value = Type{Kind: IdentifierType, Identifier: name}
+				for _, wf := range WireFormatVersions {
+					shape, _ := TypeShapeCalculator{WireFormat: wf}.Struct(nil)
+					s.SetShape(wf, shape)
+					value.SetShape(wf, shape)
+				}
+				res.Structs = append(res.Structs, s)
+			}
+
+			name := syntheticLayoutName(p.Name, m.Name, "Result")
+			if err := declare(name, UnionDeclType); err != nil {
+				return Root{}, err
+			}
+			u := Union{
+				ResourceableLayoutDecl: ResourceableLayoutDecl{
+					LayoutDecl: LayoutDecl{
+						Decl:          Decl{Name: name},
+						NamingContext: NamingContext{string(p.Name.Parse().Name), string(m.Name), "Result"},
+						Synthetic:     true,
+					},
+					Resourceness: resourceness,
+				},
+				Members: []UnionMember{{
+					Ordinal:      1,
+					Type:         value,
+					Name:         "response",
+					MaxOutOfLine: value.Shape(WireFormatVersionV2).MaxOutOfLine,
+				}},
+				Strictness: IsStrict,
+			}
+			result := Type{Kind: IdentifierType, Identifier: name}
+			for _, wf := range WireFormatVersions {
+				shape := TypeShapeCalculator{WireFormat: wf}.Union(false, []TypeShape{value.Shape(wf)})
+				u.SetShape(wf, shape)
+				result.SetShape(wf, shape)
+			}
+			res.Unions = append(res.Unions, u)
+
+			valueType := value
+			m.ResultType = &result
+			m.ValueType = &valueType
+			m.SyntheticResult = true
+		}
+		res.Protocols[i] = p
+	}
+	return res, nil
+}
+
+// payloadResourceness returns the resourceness of a response payload. Payloads
+// declared in other libraries cannot be looked up unless they are structs, and
+// are then deemed resources if they may carry handles.
+func (r *Root) payloadResourceness(payload Type) Resourceness {
+	if decl, ok := r.LookupDecl(payload.Identifier); ok {
+		if decl, ok := decl.(ResourceableLayoutDeclaration); ok {
+			return decl.GetResourceness()
+		}
+	}
+	return Resourceness(payload.Shape(WireFormatVersionV2).MaxHandles > 0)
+}
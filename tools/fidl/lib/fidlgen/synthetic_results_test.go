@@ -0,0 +1,99 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestWithSyntheticResults(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	before := irtestdata.Load(t, irtestdata.OpenProtocol)
+	res, err := root.WithSyntheticResults(fidlgen.SyntheticResultsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(before, root, fidlgentest.AllowUnexported()); diff != "" {
+		t.Errorf("receiver was modified (-before +after):\n%s", diff)
+	}
+
+	methods := make(map[fidlgen.Identifier]fidlgen.Method)
+	for _, m := range res.Protocols[0].Methods {
+		methods[m.Name] = m
+	}
+	twoWay := methods["TwoWay"]
+	if !twoWay.SyntheticResult || twoWay.ResultType == nil || twoWay.ValueType == nil {
+		t.Fatalf("TwoWay was not given a synthetic result: %+v", twoWay)
+	}
+	if twoWay.ResponsePayload != nil {
+		t.Errorf("TwoWay response payload changed to %+v", twoWay.ResponsePayload)
+	}
+	for _, name := range []fidlgen.Identifier{"OneWay", "FlexibleTwoWay", "OnEvent"} {
+		if m := methods[name]; m.SyntheticResult {
+			t.Errorf("%s was given a synthetic result", name)
+		}
+	}
+
+	if got, want := twoWay.ResultType.Identifier, fidlgen.EncodedCompoundIdentifier("test.openprotocol/P_TwoWay_Result"); got != want {
+		t.Errorf("got result type %s, want %s", got, want)
+	}
+	if got, want := twoWay.ValueType.Identifier, fidlgen.EncodedCompoundIdentifier("test.openprotocol/P_TwoWay_Response"); got != want {
+		t.Errorf("got value type %s, want %s", got, want)
+	}
+	for _, name := range []fidlgen.EncodedCompoundIdentifier{twoWay.ResultType.Identifier, twoWay.ValueType.Identifier} {
+		decl, ok := res.LookupDecl(name)
+		if !ok {
+			t.Errorf("%s is not declared", name)
+			continue
+		}
+		if _, ok := res.Decls[name]; !ok {
+			t.Errorf("%s is missing from Decls", name)
+		}
+		switch decl := decl.(type) {
+		case *fidlgen.Struct:
+			if !decl.Synthetic {
+				t.Errorf("%s is not marked synthetic", name)
+			}
+		case *fidlgen.Union:
+			if !decl.Synthetic {
+				t.Errorf("%s is not marked synthetic", name)
+			}
+		}
+	}
+
+	// The synthetic result union is laid out as that of FlexibleTwoWay, but
+	// for its strict envelope.
+	for _, wf := range fidlgen.WireFormatVersions {
+		want := methods["FlexibleTwoWay"].ResultType.Shape(wf)
+		want.HasFlexibleEnvelope = false
+		if diff := cmp.Diff(want, twoWay.ResultType.Shape(wf)); diff != "" {
+			t.Errorf("%s: result shape (-want +got):\n%s", wf, diff)
+		}
+	}
+}
+
+func TestWithSyntheticResultsDisabled(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	res, err := root.WithSyntheticResults(fidlgen.SyntheticResultsOptions{Disable: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(root, res, fidlgentest.AllowUnexported()); diff != "" {
+		t.Errorf("library changed (-want +got):\n%s", diff)
+	}
+}
+
+func TestWithSyntheticResultsNameCollision(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	root.Decls["test.openprotocol/P_TwoWay_Result"] = fidlgen.UnionDeclType
+	if _, err := root.WithSyntheticResults(fidlgen.SyntheticResultsOptions{}); err == nil {
+		t.Errorf("expected an error for a taken name")
+	}
+}
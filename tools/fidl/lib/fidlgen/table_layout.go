@@ -0,0 +1,95 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// From the V2 wire format on, values of at most 4 bytes with no out-of-line
+// data, e.g. scalars and handles, are stored in their envelope itself. A
+// table whose members are all such values has no out-of-line data beyond its
+// envelopes, and bindings can then represent it compactly, e.g. as a presence
+// bitmap next to the values. The helpers below make that decision once for
+// all bindings.
+
+// IsInlinedInEnvelope returns whether values of the given shape are stored in
+// their envelope itself, rather than out-of-line, in the given wire format.
+func IsInlinedInEnvelope(shape TypeShape, wireFormat WireFormatVersion) bool {
+	return shape.InlineSize <= wireFormat.maxInlineEnvelopeSize() && shape.MaxOutOfLine == 0 && shape.Depth == 0
+}
+
+// TableMemberLayout describes how a table member is stored.
+type TableMemberLayout struct {
+	// Ordinal is the ordinal of the member.
+	Ordinal int
+	// Name is the name of the member.
+	Name Identifier
+	// Inline is whether the member is stored in its envelope, as per
+	// IsInlinedInEnvelope, rather than out-of-line.
+	Inline bool
+}
+
+// MemberLayouts describes how the members of the table are stored in the
+// given wire format, in ordinal order. Reserved members are skipped.
+func (t *Table) MemberLayouts(wireFormat WireFormatVersion) []TableMemberLayout {
+	var layouts []TableMemberLayout
+	for _, m := range t.SortedMembersNoReserved() {
+		layouts = append(layouts, TableMemberLayout{
+			Ordinal: m.Ordinal,
+			Name:    m.Name,
+			Inline:  IsInlinedInEnvelope(m.Type.Shape(wireFormat), wireFormat),
+		})
+	}
+	return layouts
+}
+
+// IsPackedViewEligible returns whether all members of the table are stored in
+// their envelopes in the V2 wire format, so that bindings may represent the
+// table compactly. Tables without members are trivially eligible.
+func (t *Table) IsPackedViewEligible() bool {
+	for _, m := range t.MemberLayouts(WireFormatVersionV2) {
+		if !m.Inline {
+			return false
+		}
+	}
+	return true
+}
+
+// MaxOrdinal returns the largest ordinal of the table's members, excluding
+// reserved ones, or 0 if there are none.
+func (t *Table) MaxOrdinal() int {
+	max := 0
+	for _, m := range t.Members {
+		if !m.Reserved && m.Ordinal > max {
+			max = m.Ordinal
+		}
+	}
+	return max
+}
+
+// DenseMembers returns the members of the table indexed by ordinal: the
+// element at ordinal-1 is the member with that ordinal, or nil if there is no
+// such member or it is reserved. The slice has MaxOrdinal elements, and points
+// into Members.
+func (t *Table) DenseMembers() []*TableMember {
+	members := make([]*TableMember, t.MaxOrdinal())
+	for i := range t.Members {
+		if m := &t.Members[i]; !m.Reserved {
+			members[m.Ordinal-1] = m
+		}
+	}
+	return members
+}
+
+// MaxEnvelopeCount returns the largest number of envelopes a table value can
+// be encoded with: encoders omit the envelopes past the last member set, so
+// this is reached when the member of ordinal MaxOrdinal is set.
+func (t *Table) MaxEnvelopeCount() int {
+	return t.MaxOrdinal()
+}
+
+// MaxEnvelopesSize returns the size, in bytes, taken out-of-line by the
+// envelopes of a table value with MaxEnvelopeCount envelopes in the given wire
+// format, excluding the member values.
+func (t *Table) MaxEnvelopesSize(wireFormat WireFormatVersion) int {
+	return t.MaxEnvelopeCount() * wireFormat.envelopeSize()
+}
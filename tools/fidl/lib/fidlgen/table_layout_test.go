@@ -0,0 +1,107 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestTableMemberLayouts(t *testing.T) {
+	withShapes := func(typ fidlgen.Type, shape func(fidlgen.TypeShapeCalculator) fidlgen.TypeShape) fidlgen.Type {
+		for _, wf := range fidlgen.WireFormatVersions {
+			typ.SetShape(wf, shape(fidlgen.TypeShapeCalculator{WireFormat: wf}))
+		}
+		return typ
+	}
+	primitive := func(subtype fidlgen.PrimitiveSubtype) fidlgen.Type {
+		return withShapes(fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: subtype}, func(fidlgen.TypeShapeCalculator) fidlgen.TypeShape {
+			return fidlgen.PrimitiveTypeShape(subtype)
+		})
+	}
+	handle := withShapes(fidlgen.Type{Kind: fidlgen.HandleType}, func(fidlgen.TypeShapeCalculator) fidlgen.TypeShape {
+		return fidlgen.HandleTypeShape
+	})
+	str := withShapes(fidlgen.Type{Kind: fidlgen.StringType}, func(c fidlgen.TypeShapeCalculator) fidlgen.TypeShape {
+		return c.String(nil)
+	})
+
+	packed := fidlgen.Table{Members: []fidlgen.TableMember{
+		{Ordinal: 3, Name: "handle", Type: handle},
+		{Ordinal: 1, Name: "flag", Type: primitive(fidlgen.Bool)},
+		{Ordinal: 2, Reserved: true},
+		{Ordinal: 4, Name: "count", Type: primitive(fidlgen.Uint32)},
+	}}
+	expected := []fidlgen.TableMemberLayout{
+		{Ordinal: 1, Name: "flag", Inline: true},
+		{Ordinal: 3, Name: "handle", Inline: true},
+		{Ordinal: 4, Name: "count", Inline: true},
+	}
+	if diff := cmp.Diff(expected, packed.MemberLayouts(fidlgen.WireFormatVersionV2)); diff != "" {
+		t.Errorf("V2 layouts (-want +got):\n%s", diff)
+	}
+	for _, m := range packed.MemberLayouts(fidlgen.WireFormatVersionV1) {
+		if m.Inline {
+			t.Errorf("%s is inlined in V1", m.Name)
+		}
+	}
+	if !packed.IsPackedViewEligible() {
+		t.Errorf("table of small scalars is not eligible for a packed view")
+	}
+
+	for _, m := range []fidlgen.TableMember{
+		{Ordinal: 5, Name: "wide", Type: primitive(fidlgen.Uint64)},
+		{Ordinal: 5, Name: "name", Type: str},
+	} {
+		mixed := packed
+		mixed.Members = append(append([]fidlgen.TableMember{}, packed.Members...), m)
+		layouts := mixed.MemberLayouts(fidlgen.WireFormatVersionV2)
+		if last := layouts[len(layouts)-1]; last.Inline {
+			t.Errorf("%s is inlined", last.Name)
+		}
+		if mixed.IsPackedViewEligible() {
+			t.Errorf("table with %s is eligible for a packed view", m.Name)
+		}
+	}
+
+	var empty fidlgen.Table
+	if !empty.IsPackedViewEligible() {
+		t.Errorf("empty table is not eligible for a packed view")
+	}
+}
+
+func TestTableOrdinals(t *testing.T) {
+	table := fidlgen.Table{Members: []fidlgen.TableMember{
+		{Name: "c", Ordinal: 4},
+		{Name: "a", Ordinal: 1},
+		{Ordinal: 2, Reserved: true},
+		{Ordinal: 5, Reserved: true},
+	}}
+	if got := table.MaxOrdinal(); got != 4 {
+		t.Errorf("got MaxOrdinal() = %d, want 4", got)
+	}
+	dense := table.DenseMembers()
+	if len(dense) != 4 || dense[0] != &table.Members[1] || dense[1] != nil || dense[2] != nil || dense[3] != &table.Members[0] {
+		t.Errorf("got dense members %v", dense)
+	}
+	if got := table.MaxEnvelopeCount(); got != 4 {
+		t.Errorf("got MaxEnvelopeCount() = %d, want 4", got)
+	}
+	for wf, expected := range map[fidlgen.WireFormatVersion]int{
+		fidlgen.WireFormatVersionV1: 4 * 16,
+		fidlgen.WireFormatVersionV2: 4 * 8,
+	} {
+		if got := table.MaxEnvelopesSize(wf); got != expected {
+			t.Errorf("%s: got MaxEnvelopesSize() = %d, want %d", wf, got, expected)
+		}
+	}
+
+	var empty fidlgen.Table
+	if empty.MaxOrdinal() != 0 || len(empty.DenseMembers()) != 0 || empty.MaxEnvelopesSize(fidlgen.WireFormatVersionV2) != 0 {
+		t.Errorf("unexpected ordinals for an empty table")
+	}
+}
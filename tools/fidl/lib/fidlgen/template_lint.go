@@ -0,0 +1,350 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// Templates are only checked against the data they are given when executed,
+// and only along the branches taken, so that a misspelled field or a missing
+// case may go unnoticed until a library exercising it comes along, and then
+// panic in the generator. LintTemplates catches common mistakes statically, so
+// that backends may run it from a test.
+
+// TemplateLintIssue is a likely mistake found in a template.
+type TemplateLintIssue struct {
+	// File is the path of the template file, in the linted filesystem.
+	File string
+	// Line is the 1-based line of the mistake in the file.
+	Line int
+	// Message describes the mistake.
+	Message string
+}
+
+func (i TemplateLintIssue) String() string {
+	return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+}
+
+// TemplateLintOptions configures LintTemplates.
+type TemplateLintOptions struct {
+	// Models are values of the types the templates are executed with, e.g. the
+	// IR of a backend wrapping the one of this package. The fields and methods
+	// of their types, and of the types reachable from them, are known to the
+	// linter, as are those of the types reachable from Root.
+	//
+	// Reserved members are only checked for if the Members of a type reachable
+	// from the models have a Reserved field, or, without models, those of a
+	// type reachable from Root.
+	Models []interface{}
+	// MembersExcludeReserved disables the check for reserved members, for
+	// backends whose IR already leaves them out but keeps the Reserved field.
+	MembersExcludeReserved bool
+}
+
+// strictnessLayoutTemplate matches the names of the templates which generate
+// code for unions, enums, or bits, whose handling depends on their strictness.
+var strictnessLayoutTemplate = regexp.MustCompile(`(?i)union|enum|bits`)
+
+// LintTemplates parses the .tmpl files of a filesystem, as NewGenerator does,
+// and reports likely mistakes in them, sorted by file and line:
+//
+//   - references to fields or methods which no known model type has;
+//   - ranges over members which refer to their ordinals, i.e. the members of
+//     tables or unions, without ever checking whether they are reserved,
+//     unless MembersExcludeReserved is set;
+//   - files defining templates for unions, enums, or bits which never check
+//     IsStrict, IsFlexible or Strictness.
+//
+// Functions are not checked, so that the backend's function map is not
+// needed. An error is returned if the templates cannot be read or parsed.
+func LintTemplates(tmplFS fs.FS, opts TemplateLintOptions) ([]TemplateLintIssue, error) {
+	known := make(map[string]struct{})
+	seen := make(map[reflect.Type]struct{})
+	collectTemplateFields(reflect.TypeOf(Root{}), known, seen)
+	for _, model := range opts.Models {
+		collectTemplateFields(reflect.TypeOf(model), known, seen)
+	}
+	models := []reflect.Type{reflect.TypeOf(Root{})}
+	if len(opts.Models) > 0 {
+		models = nil
+		for _, model := range opts.Models {
+			models = append(models, reflect.TypeOf(model))
+		}
+	}
+	checkReserved := false
+	if !opts.MembersExcludeReserved {
+		seen := make(map[reflect.Type]struct{})
+		for _, model := range models {
+			checkReserved = checkReserved || hasReservableMembers(model, seen)
+		}
+	}
+
+	files, err := listTemplateFiles(tmplFS)
+	if err != nil {
+		return nil, err
+	}
+	var issues []TemplateLintIssue
+	for _, file := range files {
+		b, err := fs.ReadFile(tmplFS, file)
+		if err != nil {
+			return nil, err
+		}
+		text := string(b)
+		tree := parse.New(file)
+		tree.Mode = parse.SkipFuncCheck
+		trees := make(map[string]*parse.Tree)
+		if _, err := tree.Parse(text, "", "", trees); err != nil {
+			return nil, err
+		}
+		l := templateLinter{file: file, text: text, known: known, checkReserved: checkReserved}
+		var names []string
+		for name := range trees {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		strictnessChecked := false
+		var strictnessLayouts []*parse.Tree
+		for _, name := range names {
+			t := trees[name]
+			if t.Root == nil {
+				continue
+			}
+			refs := l.walk(t.Root)
+			if _, ok := refs["IsStrict"]; ok {
+				strictnessChecked = true
+			} else if _, ok := refs["IsFlexible"]; ok {
+				strictnessChecked = true
+			} else if _, ok := refs["Strictness"]; ok {
+				strictnessChecked = true
+			}
+			if name != file && strictnessLayoutTemplate.MatchString(name) {
+				strictnessLayouts = append(strictnessLayouts, t)
+			}
+		}
+		if !strictnessChecked && len(strictnessLayouts) > 0 {
+			t := strictnessLayouts[0]
+			l.report(t.Root, "template %q generates code for a union, enum, or bits declaration, but the file never checks IsStrict, IsFlexible or Strictness", t.Name)
+		}
+		issues = append(issues, l.issues...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues, nil
+}
+
+// collectTemplateFields adds the names of the exported fields and methods of a
+// type, and of the types reachable from it, to known.
+func collectTemplateFields(typ reflect.Type, known map[string]struct{}, seen map[reflect.Type]struct{}) {
+	if typ == nil {
+		return
+	}
+	if _, ok := seen[typ]; ok {
+		return
+	}
+	seen[typ] = struct{}{}
+	for i := 0; i < typ.NumMethod(); i++ {
+		known[typ.Method(i).Name] = struct{}{}
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		ptr := reflect.PtrTo(typ)
+		for i := 0; i < ptr.NumMethod(); i++ {
+			known[ptr.Method(i).Name] = struct{}{}
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.IsExported() {
+				known[f.Name] = struct{}{}
+			}
+			// Fields of unexported embedded structs are promoted too.
+			if f.IsExported() || f.Anonymous {
+				collectTemplateFields(f.Type, known, seen)
+			}
+		}
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		collectTemplateFields(typ.Elem(), known, seen)
+	case reflect.Map:
+		collectTemplateFields(typ.Key(), known, seen)
+		collectTemplateFields(typ.Elem(), known, seen)
+	}
+}
+
+// hasReservableMembers returns whether a type, or one reachable from it, has
+// Members with a Reserved field, like those of tables and unions in Root.
+// Members are looked up as templates do, so that those of an embedded struct
+// do not count if the embedding one has its own.
+func hasReservableMembers(typ reflect.Type, seen map[reflect.Type]struct{}) bool {
+	if typ == nil {
+		return false
+	}
+	if _, ok := seen[typ]; ok {
+		return false
+	}
+	seen[typ] = struct{}{}
+	switch typ.Kind() {
+	case reflect.Struct:
+		if f, ok := typ.FieldByName("Members"); ok && f.Type.Kind() == reflect.Slice {
+			if elem := f.Type.Elem(); elem.Kind() == reflect.Struct {
+				if _, ok := elem.FieldByName("Reserved"); ok {
+					return true
+				}
+			}
+		}
+		return fieldsHaveReservableMembers(typ, seen)
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return hasReservableMembers(typ.Elem(), seen)
+	case reflect.Map:
+		return hasReservableMembers(typ.Key(), seen) || hasReservableMembers(typ.Elem(), seen)
+	}
+	return false
+}
+
+// fieldsHaveReservableMembers calls hasReservableMembers on the types of the
+// fields of a struct, and on the fields of the structs it embeds.
+func fieldsHaveReservableMembers(typ reflect.Type, seen map[reflect.Type]struct{}) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if fieldsHaveReservableMembers(f.Type, seen) {
+				return true
+			}
+		} else if f.IsExported() && hasReservableMembers(f.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+type templateLinter struct {
+	file          string
+	text          string
+	known         map[string]struct{}
+	checkReserved bool
+	issues        []TemplateLintIssue
+}
+
+func (l *templateLinter) report(node parse.Node, format string, a ...interface{}) {
+	l.issues = append(l.issues, TemplateLintIssue{
+		File:    l.file,
+		Line:    strings.Count(l.text[:node.Position()], "\n") + 1,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// fields checks the field names of a field chain, and adds them to refs.
+func (l *templateLinter) fields(node parse.Node, idents []string, refs map[string]struct{}) {
+	for _, ident := range idents {
+		refs[ident] = struct{}{}
+		if _, ok := l.known[ident]; !ok {
+			l.report(node, "no model type has a field or method named %s", ident)
+		}
+	}
+}
+
+// walk checks a node and those below it, and returns the names of the fields
+// and methods they refer to.
+func (l *templateLinter) walk(node parse.Node) map[string]struct{} {
+	refs := make(map[string]struct{})
+	var visit func(node parse.Node)
+	visitBranch := func(b *parse.BranchNode) {
+		visit(b.Pipe)
+		visit(b.List)
+		visit(b.ElseList)
+	}
+	visit = func(node parse.Node) {
+		switch node := node.(type) {
+		case *parse.ListNode:
+			if node == nil {
+				return
+			}
+			for _, n := range node.Nodes {
+				visit(n)
+			}
+		case *parse.ActionNode:
+			visit(node.Pipe)
+		case *parse.PipeNode:
+			if node == nil {
+				return
+			}
+			for _, cmd := range node.Cmds {
+				visit(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range node.Args {
+				visit(arg)
+			}
+		case *parse.FieldNode:
+			l.fields(node, node.Ident, refs)
+		case *parse.VariableNode:
+			l.fields(node, node.Ident[1:], refs)
+		case *parse.ChainNode:
+			visit(node.Node)
+			l.fields(node, node.Field, refs)
+		case *parse.IfNode:
+			visitBranch(&node.BranchNode)
+		case *parse.WithNode:
+			visitBranch(&node.BranchNode)
+		case *parse.RangeNode:
+			visitBranch(&node.BranchNode)
+			l.checkRange(node)
+		case *parse.TemplateNode:
+			visit(node.Pipe)
+		}
+	}
+	visit(node)
+	return refs
+}
+
+// checkRange reports ranges over the members of tables or unions, which may be
+// reserved, that never check whether they are.
+func (l *templateLinter) checkRange(node *parse.RangeNode) {
+	if !l.checkReserved || lastFieldName(node.Pipe) != "Members" {
+		return
+	}
+	body := (&templateLinter{text: l.text, known: l.known}).walk(node.List)
+	if _, ok := body["Ordinal"]; !ok {
+		return
+	}
+	if _, ok := body["Reserved"]; ok {
+		return
+	}
+	l.report(node, "range over Members refers to Ordinal but never checks Reserved; reserved table and union members have no type or name")
+}
+
+// lastFieldName returns the last field name of the last command of a pipeline,
+// if it ends in a field chain.
+func lastFieldName(pipe *parse.PipeNode) string {
+	if pipe == nil || len(pipe.Cmds) == 0 {
+		return ""
+	}
+	args := pipe.Cmds[len(pipe.Cmds)-1].Args
+	if len(args) != 1 {
+		return ""
+	}
+	var idents []string
+	switch arg := args[0].(type) {
+	case *parse.FieldNode:
+		idents = arg.Ident
+	case *parse.VariableNode:
+		idents = arg.Ident[1:]
+	case *parse.ChainNode:
+		idents = arg.Field
+	}
+	if len(idents) == 0 {
+		return ""
+	}
+	return idents[len(idents)-1]
+}
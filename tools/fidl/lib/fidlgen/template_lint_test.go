@@ -0,0 +1,119 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestLintTemplates(t *testing.T) {
+	templates := fstest.MapFS{
+		"struct.tmpl": {Data: []byte(`{{- define "StructDeclaration" }}
+struct {{ .Name }} {
+{{- range .Members }}
+  {{ .Name }}: {{ .Type.Kind }};
+{{- end }}
+};
+{{- end }}
+`)},
+		"table.tmpl": {Data: []byte(`{{- define "TableDeclaration" }}
+{{- range $m := .Members }}
+  {{ $m.Ordinal }}: {{ $m.Nmae }};
+{{- end }}
+{{- range .Members }}
+  {{- if not .Reserved }}{{ .Ordinal }}{{ end }}
+{{- end }}
+{{- end }}
+`)},
+		"union.tmpl": {Data: []byte(`{{- define "UnionDeclaration" }}
+{{- range .SortedMembersNoReserved }}
+  {{ .Ordinal }}: {{ .Name }};
+{{- end }}
+{{- end }}
+`)},
+		"enum.tmpl": {Data: []byte(`{{- define "EnumDeclaration" }}
+{{- if .IsFlexible }}{{ .UnknownValueForTmpl }}{{ end }}
+{{- end }}
+`)},
+		"helpers/funcs.tmpl": {Data: []byte(`{{- define "Helper" }}{{ myFunc .Name | otherFunc }}{{ (index .Decls 0).Nmae }}{{ end }}`)},
+	}
+	issues, err := fidlgen.LintTemplates(templates, fidlgen.TemplateLintOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.String())
+	}
+	expected := []string{
+		"helpers/funcs.tmpl:1: no model type has a field or method named Nmae",
+		"table.tmpl:2: range over Members refers to Ordinal but never checks Reserved; reserved table and union members have no type or name",
+		"table.tmpl:3: no model type has a field or method named Nmae",
+		`union.tmpl:2: template "UnionDeclaration" generates code for a union, enum, or bits declaration, but the file never checks IsStrict, IsFlexible or Strictness`,
+	}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("unexpected issues (-want +got):\n%s", diff)
+	}
+}
+
+func TestLintTemplatesOptions(t *testing.T) {
+	type member struct {
+		Ordinal  int
+		Nickname string
+	}
+	type model struct {
+		Members []member
+	}
+	templates := fstest.MapFS{
+		"table.tmpl": {Data: []byte(`{{ range .Members }}{{ .Ordinal }}: {{ .Nickname }}{{ end }}`)},
+	}
+	issues, err := fidlgen.LintTemplates(templates, fidlgen.TemplateLintOptions{
+		Models:                 []interface{}{model{}},
+		MembersExcludeReserved: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+}
+
+func TestLintTemplatesModelWithoutReserved(t *testing.T) {
+	type member struct {
+		Ordinal int
+		Name    string
+	}
+	type union struct {
+		// The embedded members, which have a Reserved field, are shadowed.
+		fidlgen.Union
+		Members []member
+	}
+	templates := fstest.MapFS{
+		"union.tmpl": {Data: []byte(`{{ if .IsStrict }}{{ range .Members }}{{ .Ordinal }}: {{ .Name }}{{ end }}{{ end }}`)},
+	}
+	issues, err := fidlgen.LintTemplates(templates, fidlgen.TemplateLintOptions{
+		Models: []interface{}{union{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("unexpected issues: %v", issues)
+	}
+}
+
+func TestLintTemplatesParseError(t *testing.T) {
+	templates := fstest.MapFS{
+		"broken.tmpl": {Data: []byte(`{{ if .Name }}`)},
+	}
+	if _, err := fidlgen.LintTemplates(templates, fidlgen.TemplateLintOptions{}); err == nil {
+		t.Errorf("expected an error for an unterminated if")
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package templates provides the template functions most fidlgen backends
+// need, so that new backends do not reimplement them. Backends merge the
+// functions of Funcs with their own:
+//
+//	fidlgen.NewGenerator("MyTemplates", tmplFS, formatter, templates.Merge(
+//		templates.Funcs(templates.Options{Names: fidlgen.GoNames}),
+//		template.FuncMap{"MyFunc": myFunc},
+//	))
+package templates
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// Options configures the functions of Funcs for the language of a backend.
+type Options struct {
+	// Names converts names for DeclName, MemberName, EnumMemberName,
+	// BitsMemberName, ConstName and MethodName. If nil, names are left as
+	// written in FIDL.
+	Names fidlgen.NameTransformer
+	// DocComments renders doc comments for DocComment. The zero value
+	// renders `///` comments without wrapping.
+	DocComments fidlgen.DocCommentRenderer
+	// Values renders constants for Constant. If nil, constants are rendered
+	// in FIDL syntax.
+	Values fidlgen.DefaultValueRenderer
+}
+
+// Funcs returns the common template functions, configured by opts:
+//
+//   - ToSnakeCase, ToUpperCamelCase, ToLowerCamelCase, ToScreamingSnakeCase,
+//     ToKebabCase, ToFriendlyCase, ConstNameToKCamelCase and
+//     ConstNameToAllCapsSnake convert names, as the functions of fidlgen do.
+//   - DeclName, MemberName, EnumMemberName, BitsMemberName, ConstName and
+//     MethodName convert names with opts.Names.
+//   - DocComment renders the doc comment of an element with attributes, given
+//     its library, as lines ending with a newline.
+//   - Join joins the elements of a slice with a separator, like strings.Join.
+//   - TrimPrefix, TrimSuffix and RemoveLeadingK strip prefixes and suffixes.
+//   - Constant renders a constant of a given type with opts.Values.
+//
+// Functions converting names accept any string type, e.g. fidlgen.Identifier.
+// A new map is returned, which backends may modify.
+func Funcs(opts Options) template.FuncMap {
+	names := opts.Names
+	if names == nil {
+		names = fidlgen.FIDLNames
+	}
+	docComments := opts.DocComments
+	if docComments.Prefix == "" {
+		docComments.Prefix = "///"
+	}
+	return template.FuncMap{
+		"ToSnakeCase":             stringFunc(fidlgen.ToSnakeCase),
+		"ToUpperCamelCase":        stringFunc(fidlgen.ToUpperCamelCase),
+		"ToLowerCamelCase":        stringFunc(fidlgen.ToLowerCamelCase),
+		"ToScreamingSnakeCase":    stringFunc(fidlgen.ToScreamingSnakeCase),
+		"ToKebabCase":             stringFunc(fidlgen.ToKebabCase),
+		"ToFriendlyCase":          stringFunc(fidlgen.ToFriendlyCase),
+		"ConstNameToKCamelCase":   stringFunc(fidlgen.ConstNameToKCamelCase),
+		"ConstNameToAllCapsSnake": stringFunc(fidlgen.ConstNameToAllCapsSnake),
+
+		"DeclName":       identifierFunc(names.DeclName),
+		"MemberName":     identifierFunc(names.MemberName),
+		"EnumMemberName": identifierFunc(names.EnumMemberName),
+		"BitsMemberName": identifierFunc(names.BitsMemberName),
+		"ConstName":      identifierFunc(names.ConstName),
+		"MethodName":     identifierFunc(names.MethodName),
+
+		"DocComment": func(el documented, library fidlgen.EncodedLibraryIdentifier) string {
+			lines := docComments.Render(fidlgen.ParseDocComment(el.DocComments(), library))
+			if len(lines) == 0 {
+				return ""
+			}
+			return strings.Join(lines, "\n") + "\n"
+		},
+
+		"Join": func(elems interface{}, sep string) (string, error) {
+			v := reflect.ValueOf(elems)
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+				return "", fmt.Errorf("Join: %T is not a slice", elems)
+			}
+			strs := make([]string, v.Len())
+			for i := range strs {
+				strs[i] = toString(v.Index(i).Interface())
+			}
+			return strings.Join(strs, sep), nil
+		},
+
+		"TrimPrefix": func(s interface{}, prefix string) string {
+			return strings.TrimPrefix(toString(s), prefix)
+		},
+		"TrimSuffix": func(s interface{}, suffix string) string {
+			return strings.TrimSuffix(toString(s), suffix)
+		},
+		"RemoveLeadingK": stringFunc(fidlgen.RemoveLeadingK),
+
+		"Constant": func(c fidlgen.Constant, typ fidlgen.Type) (string, error) {
+			if opts.Values == nil {
+				return c.String(), nil
+			}
+			return fidlgen.RenderDefaultValue(opts.Values, c, typ)
+		},
+	}
+}
+
+// Merge merges function maps. Functions of later maps replace those of
+// earlier ones with the same name.
+func Merge(all ...template.FuncMap) template.FuncMap {
+	merged := template.FuncMap{}
+	for _, funcs := range all {
+		for name, fn := range funcs {
+			merged[name] = fn
+		}
+	}
+	return merged
+}
+
+// documented is an element which may have a doc comment, e.g.
+// fidlgen.Attributes or any declaration or member embedding it.
+type documented interface {
+	DocComments() []string
+}
+
+// toString returns the value of a string type, e.g. fidlgen.Identifier, or
+// formats other values as with fmt.Sprint.
+func toString(v interface{}) string {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+		return rv.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// stringFunc adapts a string function so that templates can call it with any
+// string type.
+func stringFunc(fn func(string) string) func(interface{}) string {
+	return func(v interface{}) string {
+		return fn(toString(v))
+	}
+}
+
+// identifierFunc adapts a function of an identifier so that templates can
+// call it with any string type.
+func identifierFunc(fn func(fidlgen.Identifier) string) func(interface{}) string {
+	return func(v interface{}) string {
+		return fn(fidlgen.Identifier(toString(v)))
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package templates_test
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen/templates"
+)
+
+func execute(t *testing.T, funcs template.FuncMap, text string, data interface{}) string {
+	t.Helper()
+	tmpl, err := template.New("test").Funcs(funcs).Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestFuncs(t *testing.T) {
+	doc := fidlgen.Attributes{Attributes: []fidlgen.Attribute{{
+		Name: "doc",
+		Args: []fidlgen.AttributeArg{{
+			Name:  "value",
+			Value: fidlgen.Constant{Value: " Refers to [Thing_Id].\n"},
+		}},
+	}}}
+	member := fidlgen.StructMember{
+		Name:       "max_count",
+		Attributes: doc,
+		MaybeDefaultValue: &fidlgen.Constant{
+			Kind:    fidlgen.LiteralConstant,
+			Literal: fidlgen.Literal{Kind: fidlgen.NumericLiteral, Value: "0x10"},
+		},
+		Type: fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		opts     templates.Options
+		data     interface{}
+		text     string
+		expected string
+	}{
+		{
+			name:     "cases",
+			text:     `{{ ToUpperCamelCase .Name }} {{ ToScreamingSnakeCase .Name }} {{ ToKebabCase .Name }} {{ ConstNameToKCamelCase .Name }}`,
+			expected: "MaxCount MAX_COUNT max-count kMaxCount",
+		},
+		{
+			name:     "fidl names",
+			text:     `{{ MemberName .Name }}`,
+			expected: "max_count",
+		},
+		{
+			name:     "go names",
+			opts:     templates.Options{Names: fidlgen.GoNames},
+			text:     `{{ MemberName .Name }} {{ DeclName "thing" }} {{ MethodName "do_it" }} {{ ConstName "MAX" }}`,
+			expected: "MaxCount Thing DoIt Max",
+		},
+		{
+			name:     "doc comment",
+			text:     `{{ DocComment . "example" }}`,
+			expected: "/// Refers to `Thing_Id`.\n",
+		},
+		{
+			name:     "go doc comment",
+			opts:     templates.Options{DocComments: fidlgen.GoDocComments(fidlgen.DeclMap{"example/Thing_Id": fidlgen.StructDeclType})},
+			text:     `{{ DocComment .Attributes "example" }}`,
+			expected: "// Refers to [ThingId].\n",
+		},
+		{
+			name:     "no doc comment",
+			data:     fidlgen.StructMember{},
+			text:     `{{ DocComment . "example" }}`,
+			expected: "",
+		},
+		{
+			name:     "join",
+			data:     []fidlgen.Identifier{"a", "b"},
+			text:     `{{ Join . ", " }}`,
+			expected: "a, b",
+		},
+		{
+			name:     "trim",
+			text:     `{{ TrimPrefix .Name "max_" }} {{ TrimSuffix .Name "_count" }} {{ RemoveLeadingK "kMax" }}`,
+			expected: "count max Max",
+		},
+		{
+			name:     "fidl constant",
+			text:     `{{ Constant .MaybeDefaultValue .Type }}`,
+			expected: "0x10",
+		},
+		{
+			name:     "rust constant",
+			opts:     templates.Options{Values: fidlgen.RustDefaultValues{}},
+			text:     `{{ Constant .MaybeDefaultValue .Type }}`,
+			expected: "0x10u32",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			funcs := templates.Funcs(tc.opts)
+			data := tc.data
+			if data == nil {
+				data = member
+			}
+			if diff := cmp.Diff(tc.expected, execute(t, funcs, tc.text, data)); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	funcs := templates.Merge(templates.Funcs(templates.Options{}), template.FuncMap{
+		"ToSnakeCase": func(interface{}) string { return "overridden" },
+	})
+	if actual := execute(t, funcs, `{{ ToSnakeCase "FooBar" }} {{ ToLowerCamelCase "FooBar" }}`, nil); actual != "overridden fooBar" {
+		t.Errorf("got %q", actual)
+	}
+}
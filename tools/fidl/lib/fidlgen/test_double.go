@@ -0,0 +1,95 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// A test double of a protocol (e.g. a mock or a fake) stands in for one of the
+// ends of a connection in tests. The TestDouble* types compute what such a
+// double needs to provide, so that backends generating test scaffolding agree
+// on its shape rather than each deriving it separately.
+
+// TestDoubleMethod describes a method of a protocol as seen by a test double.
+type TestDoubleMethod struct {
+	// Name is the name of the method.
+	Name Identifier
+	// Ordinal is the ordinal of the method.
+	Ordinal uint64
+	// TwoWay indicates whether a fake server must reply to the method. For
+	// events, this is always false.
+	TwoWay bool
+	// Flexible indicates whether the method is flexible.
+	Flexible bool
+	// HasError indicates whether the method uses the error syntax, in which
+	// case a fake server may reply with an error.
+	HasError bool
+	// Transitional indicates whether the method is @transitional, in which
+	// case test doubles should not require it to be handled.
+	Transitional bool
+	// Composed indicates whether the method was composed from another
+	// protocol.
+	Composed bool
+}
+
+// TestDoubleSurface describes what a test double of a protocol needs to
+// provide.
+type TestDoubleSurface struct {
+	// Protocol is the name of the protocol.
+	Protocol EncodedCompoundIdentifier
+	// Methods lists the client-initiated methods, which a fake server must
+	// handle, in declaration order.
+	Methods []TestDoubleMethod
+	// Events lists the events which a fake server may send, in declaration
+	// order.
+	Events []TestDoubleMethod
+	// UnknownOneWayHook indicates whether a fake server must provide a hook
+	// for unknown one-way methods, i.e. whether the protocol is open or ajar.
+	UnknownOneWayHook bool
+	// UnknownTwoWayHook indicates whether a fake server must provide a hook
+	// for unknown two-way methods, i.e. whether the protocol is open.
+	UnknownTwoWayHook bool
+	// UnknownEventHook indicates whether a fake client must provide a hook for
+	// unknown events, i.e. whether the protocol is open or ajar.
+	UnknownEventHook bool
+}
+
+func newTestDoubleMethod(m *Method) TestDoubleMethod {
+	return TestDoubleMethod{
+		Name:         m.Name,
+		Ordinal:      m.Ordinal,
+		TwoWay:       m.IsTwoWay(),
+		Flexible:     m.IsFlexible(),
+		HasError:     m.HasError,
+		Transitional: m.IsTransitional(),
+		Composed:     m.IsComposed,
+	}
+}
+
+// NewTestDoubleSurface computes the test double surface of a protocol.
+func NewTestDoubleSurface(p *Protocol) TestDoubleSurface {
+	s := TestDoubleSurface{
+		Protocol:          p.Name,
+		UnknownOneWayHook: p.OneWayUnknownInteractions(),
+		UnknownTwoWayHook: p.TwoWayUnknownInteractions(),
+		UnknownEventHook:  p.OneWayUnknownInteractions(),
+	}
+	for i := range p.Methods {
+		m := &p.Methods[i]
+		if m.HasRequest {
+			s.Methods = append(s.Methods, newTestDoubleMethod(m))
+		} else {
+			s.Events = append(s.Events, newTestDoubleMethod(m))
+		}
+	}
+	return s
+}
+
+// TestDoubleSurfaces computes the test double surfaces of all the protocols of
+// the library, in declaration order.
+func (r *Root) TestDoubleSurfaces() []TestDoubleSurface {
+	var surfaces []TestDoubleSurface
+	for i := range r.Protocols {
+		surfaces = append(surfaces, NewTestDoubleSurface(&r.Protocols[i]))
+	}
+	return surfaces
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestNewTestDoubleSurface(t *testing.T) {
+	flexible := false
+	protocol := fidlgen.Protocol{
+		Decl:     fidlgen.Decl{Name: "example/Calculator"},
+		Openness: fidlgen.Ajar,
+		Methods: []fidlgen.Method{
+			{Name: "Add", Ordinal: 1, HasRequest: true, HasResponse: true, HasError: true},
+			{Name: "Clear", Ordinal: 2, HasRequest: true, MaybeStrict: &flexible},
+			{Name: "OnOverflow", Ordinal: 3, HasResponse: true},
+			{
+				Name:       "Reset",
+				Ordinal:    4,
+				HasRequest: true,
+				IsComposed: true,
+				Attributes: fidlgen.Attributes{
+					Attributes: []fidlgen.Attribute{{Name: "transitional"}},
+				},
+			},
+		},
+	}
+
+	expected := fidlgen.TestDoubleSurface{
+		Protocol: "example/Calculator",
+		Methods: []fidlgen.TestDoubleMethod{
+			{Name: "Add", Ordinal: 1, TwoWay: true, HasError: true},
+			{Name: "Clear", Ordinal: 2, Flexible: true},
+			{Name: "Reset", Ordinal: 4, Transitional: true, Composed: true},
+		},
+		Events: []fidlgen.TestDoubleMethod{
+			{Name: "OnOverflow", Ordinal: 3},
+		},
+		UnknownOneWayHook: true,
+		UnknownEventHook:  true,
+	}
+	if diff := cmp.Diff(expected, fidlgen.NewTestDoubleSurface(&protocol)); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
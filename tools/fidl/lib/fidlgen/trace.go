@@ -0,0 +1,64 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MethodTrace is the content of a method's @trace attribute, which asks
+// bindings to instrument calls to the method with a trace duration event, e.g.
+// TRACE_DURATION in C++:
+//
+//	@trace(category="fidl:example", name="Foo.Bar")
+//	Bar();
+//
+// The category may also be given as the sole argument, in which case the name
+// defaults to that of the method.
+type MethodTrace struct {
+	Category string
+	Name     string
+}
+
+// traceCategoryRegexp matches valid trace categories, e.g. "kernel:ipc".
+var traceCategoryRegexp = regexp.MustCompile(`^[A-Za-z0-9_.:-]+$`)
+
+// Trace parses and validates the method's @trace attribute. It returns nil if
+// the method has none.
+func (m *Method) Trace() (*MethodTrace, error) {
+	attr, ok := m.LookupAttribute("trace")
+	if !ok {
+		return nil, nil
+	}
+	trace := MethodTrace{Name: string(m.Name)}
+	for _, arg := range attr.Args {
+		switch arg.Name {
+		case "value", "category":
+			trace.Category = arg.ValueString()
+		case "name":
+			trace.Name = arg.ValueString()
+		default:
+			return nil, fmt.Errorf("method %s: @trace: unknown argument %s", m.Name, arg.Name)
+		}
+	}
+	if !traceCategoryRegexp.MatchString(trace.Category) {
+		return nil, fmt.Errorf("method %s: @trace: invalid category %q", m.Name, trace.Category)
+	}
+	// Names are emitted as string literals in generated code, so anything that
+	// would need escaping is rejected.
+	if trace.Name == "" || strings.ContainsAny(trace.Name, "\"\\") || strings.IndexFunc(trace.Name, func(r rune) bool {
+		return r < ' ' || r == 0x7f
+	}) >= 0 {
+		return nil, fmt.Errorf("method %s: @trace: invalid name %q", m.Name, trace.Name)
+	}
+	return &trace, nil
+}
+
+// String formats the trace as the @trace attribute it was parsed from.
+func (t MethodTrace) String() string {
+	return fmt.Sprintf("@trace(category=%q, name=%q)", t.Category, t.Name)
+}
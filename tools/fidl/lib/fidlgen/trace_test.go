@@ -0,0 +1,78 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func traceMethod(args ...fidlgen.AttributeArg) fidlgen.Method {
+	return fidlgen.Method{
+		Name: "Bar",
+		Attributes: fidlgen.Attributes{Attributes: []fidlgen.Attribute{
+			{Name: "trace", Args: args},
+		}},
+	}
+}
+
+func traceArg(name, value string) fidlgen.AttributeArg {
+	return fidlgen.AttributeArg{
+		Name:  fidlgen.Identifier(name),
+		Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: value},
+	}
+}
+
+func TestMethodTrace(t *testing.T) {
+	var untraced fidlgen.Method
+	if trace, err := untraced.Trace(); trace != nil || err != nil {
+		t.Errorf("untraced method: got (%v, %v), want (nil, nil)", trace, err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		method   fidlgen.Method
+		expected fidlgen.MethodTrace
+	}{
+		{
+			name:     "standalone",
+			method:   traceMethod(traceArg("value", "fidl:example")),
+			expected: fidlgen.MethodTrace{Category: "fidl:example", Name: "Bar"},
+		},
+		{
+			name:     "named",
+			method:   traceMethod(traceArg("category", "kernel:ipc"), traceArg("name", "Foo.Bar")),
+			expected: fidlgen.MethodTrace{Category: "kernel:ipc", Name: "Foo.Bar"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			trace, err := tc.method.Trace()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(&tc.expected, trace); diff != "" {
+				t.Errorf("unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name   string
+		method fidlgen.Method
+	}{
+		{"no category", traceMethod(traceArg("name", "Foo.Bar"))},
+		{"bad category", traceMethod(traceArg("category", "fidl example"))},
+		{"bad name", traceMethod(traceArg("category", "fidl"), traceArg("name", `Foo"Bar`))},
+		{"unknown argument", traceMethod(traceArg("category", "fidl"), traceArg("level", "1"))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.method.Trace(); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
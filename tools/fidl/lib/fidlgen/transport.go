@@ -0,0 +1,215 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Transport is a transport over which protocols are spoken, as given by the
+// @transport attribute of a protocol and by the endpoint types referring to
+// it.
+type Transport string
+
+const (
+	ChannelTransport Transport = "Channel"
+	DriverTransport  Transport = "Driver"
+	SyscallTransport Transport = "Syscall"
+	BanjoTransport   Transport = "Banjo"
+)
+
+var knownTransports = map[Transport]struct{}{
+	ChannelTransport: {},
+	DriverTransport:  {},
+	SyscallTransport: {},
+	BanjoTransport:   {},
+}
+
+// ParseTransport parses the name of a transport, as written in @transport.
+func ParseTransport(s string) (Transport, error) {
+	t := Transport(s)
+	if !t.IsValid() {
+		return "", fmt.Errorf("unknown transport %q", s)
+	}
+	return t, nil
+}
+
+// IsValid returns whether the transport is a known one.
+func (t Transport) IsValid() bool {
+	_, ok := knownTransports[t]
+	return ok
+}
+
+// ValidateTransports checks that the protocols and protocol endpoints of the
+// library only use known transports.
+func (r *Root) ValidateTransports() error {
+	var errs []string
+	check := func(where string, t Transport) {
+		if !t.IsValid() {
+			errs = append(errs, fmt.Sprintf("%s: unknown transport %q", where, t))
+		}
+	}
+	checkType := func(where string, typ Type) {
+		for ; ; typ = *typ.ElementType {
+			if typ.ProtocolTransport != "" {
+				check(where, typ.ProtocolTransport)
+			}
+			if typ.ElementType == nil {
+				return
+			}
+		}
+	}
+	for _, p := range r.Protocols {
+		for t := range p.Transports() {
+			check(string(p.Name), t)
+		}
+	}
+	for _, s := range r.Services {
+		for _, m := range s.Members {
+			checkType(fmt.Sprintf("%s.%s", s.Name, m.Name), m.Type)
+		}
+	}
+	for _, s := range r.Structs {
+		for _, m := range s.Members {
+			checkType(fmt.Sprintf("%s.%s", s.Name, m.Name), m.Type)
+		}
+	}
+	for _, t := range r.Tables {
+		for _, m := range t.Members {
+			if !m.Reserved {
+				checkType(fmt.Sprintf("%s.%s", t.Name, m.Name), m.Type)
+			}
+		}
+	}
+	for _, u := range r.Unions {
+		for _, m := range u.Members {
+			if !m.Reserved {
+				checkType(fmt.Sprintf("%s.%s", u.Name, m.Name), m.Type)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("invalid transports:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// ValidateEndpoints checks that the protocol endpoints of the library refer
+// to protocols it declares, or to protocols of other libraries, which cannot
+// be checked. It reports the endpoints of protocols filtered out by
+// ForTransport, which the bindings of the transport cannot name.
+func (r *Root) ValidateEndpoints() error {
+	var errs []string
+	r.forEachDeclType(func(decl Declaration, typ *Type) {
+		WalkType(typ, func(typ *Type) bool {
+			var protocol EncodedCompoundIdentifier
+			switch {
+			case typ.Kind == RequestType:
+				protocol = typ.RequestSubtype
+			case typ.Kind == IdentifierType && typ.ProtocolTransport != "":
+				protocol = typ.Identifier
+			default:
+				return true
+			}
+			if protocol.LibraryName() != r.Name {
+				return true
+			}
+			if declType, ok := r.Decls[protocol]; !ok || declType != ProtocolDeclType {
+				errs = append(errs, fmt.Sprintf("%s: endpoint of undeclared protocol %s", decl.GetName(), protocol))
+			}
+			return true
+		})
+	})
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return fmt.Errorf("dangling endpoints:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// ForTransport filters out the protocols that are not spoken over the given
+// transport, along with the anonymous layouts nested within them, e.g. the
+// payloads of their methods, and the service members for other transports.
+// It is analogous to ForBindings, returns a new Root and does not modify r.
+// Endpoints of the filtered out protocols which remain in other declarations
+// are left dangling: ValidateEndpoints reports them.
+func (r *Root) ForTransport(transport Transport) Root {
+	var dropped []scopedNamingContext
+	for _, p := range r.Protocols {
+		if _, ok := p.Transports()[transport]; !ok {
+			dropped = append(dropped, scopedNamingContext{r.Name, []string{string(p.Name.Parse().Name)}})
+		}
+	}
+
+	res := Root{
+		Name:              r.Name,
+		Experiments:       r.Experiments,
+		Libraries:         r.Libraries,
+		Decls:             make(DeclMap, len(r.Decls)),
+		HandleObjectTypes: r.HandleObjectTypes,
+	}
+	r.ForEachDecl(func(decl Declaration) {
+		if layout, ok := decl.(LayoutDeclaration); ok {
+			if (scopedNamingContext{decl.GetName().LibraryName(), layout.GetNamingContext()}).isDenied(dropped) {
+				return
+			}
+		}
+
+		switch v := decl.(type) {
+		case *Const:
+			res.Consts = append(res.Consts, *v)
+		case *Bits:
+			res.Bits = append(res.Bits, *v)
+		case *Enum:
+			res.Enums = append(res.Enums, *v)
+		case *Resource:
+			res.Resources = append(res.Resources, *v)
+		case *Protocol:
+			if _, ok := v.Transports()[transport]; !ok {
+				return
+			}
+			res.Protocols = append(res.Protocols, *v)
+		case *Service:
+			newV := *v
+			newV.Members = nil
+			for _, m := range v.Members {
+				if m.Type.ProtocolTransport == transport {
+					newV.Members = append(newV.Members, m)
+				}
+			}
+			if len(newV.Members) == 0 && len(v.Members) > 0 {
+				return
+			}
+			res.Services = append(res.Services, newV)
+		case *Struct:
+			if v.Name.LibraryName() == r.Name {
+				res.Structs = append(res.Structs, *v)
+			} else {
+				res.ExternalStructs = append(res.ExternalStructs, *v)
+			}
+		case *Table:
+			res.Tables = append(res.Tables, *v)
+		case *Union:
+			res.Unions = append(res.Unions, *v)
+		case *TypeAlias:
+			res.TypeAliases = append(res.TypeAliases, *v)
+		case *NewType:
+			res.NewTypes = append(res.NewTypes, *v)
+		}
+		if info, ok := r.Decls[decl.GetName()]; ok {
+			res.Decls[decl.GetName()] = info
+		}
+	})
+
+	for _, d := range r.DeclOrder {
+		if _, ok := res.Decls[d]; ok {
+			res.DeclOrder = append(res.DeclOrder, d)
+		}
+	}
+	return res
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+const (
+	// MessageHeaderSize is the size in bytes of the header of transactional
+	// messages, which precedes their payload.
+	MessageHeaderSize = 16
+
+	// ChannelMaxMessageBytes is the maximum size in bytes of a channel
+	// message, i.e. ZX_CHANNEL_MAX_MSG_BYTES.
+	ChannelMaxMessageBytes = 65536
+
+	// ChannelMaxMessageHandles is the maximum number of handles of a channel
+	// message, i.e. ZX_CHANNEL_MAX_MSG_HANDLES.
+	ChannelMaxMessageHandles = 64
+)
+
+// align8 rounds a size up to the 8-byte alignment of FIDL objects.
+func align8(size int) int {
+	return (size + 7) &^ 7
+}
+
+// MaxMessageBytes returns the maximum size in bytes of a transactional
+// message whose payload has the given shape, header included.
+func MaxMessageBytes(shape TypeShape) int {
+	return MessageHeaderSize + align8(shape.InlineSize) + align8(shape.MaxOutOfLine)
+}
+
+// FitsInChannel returns whether every transactional message whose payload has
+// the given shape fits within the byte and handle limits of a channel
+// message. The unknown data that flexible envelopes may hold on decode is not
+// accounted for.
+func FitsInChannel(shape TypeShape) bool {
+	return MaxMessageBytes(shape) <= ChannelMaxMessageBytes && shape.MaxHandles <= ChannelMaxMessageHandles
+}
+
+// MessageLimits bounds the transactional messages sent in one direction of a
+// method.
+type MessageLimits struct {
+	// MaxBytes is the maximum size in bytes of a message, header included.
+	// It is only meaningful if UnboundedBytes is false.
+	MaxBytes int
+	// UnboundedBytes is set if messages may be arbitrarily large, e.g. if the
+	// payload holds a vector without a maximum size.
+	UnboundedBytes bool
+	// MaxHandles is the maximum number of handles of a message. It is only
+	// meaningful if UnboundedHandles is false.
+	MaxHandles int
+	// UnboundedHandles is set if messages may hold any number of handles.
+	UnboundedHandles bool
+}
+
+// Fits returns whether every message fits within the given byte and handle
+// limits, e.g. ChannelMaxMessageBytes and ChannelMaxMessageHandles.
+func (l MessageLimits) Fits(maxBytes, maxHandles int) bool {
+	return !l.UnboundedBytes && l.MaxBytes <= maxBytes && !l.UnboundedHandles && l.MaxHandles <= maxHandles
+}
+
+// messageLimits returns the limits of messages with the given payload, or
+// none.
+func messageLimits(payload *Type, wireFormat WireFormatVersion) MessageLimits {
+	if payload == nil {
+		return MessageLimits{MaxBytes: MessageHeaderSize}
+	}
+	shape := payload.Shape(wireFormat)
+	return MessageLimits{
+		MaxBytes:         MaxMessageBytes(shape),
+		UnboundedBytes:   shape.MaxOutOfLine >= UnboundedTypeShapeSize,
+		MaxHandles:       shape.MaxHandles,
+		UnboundedHandles: shape.MaxHandles >= UnboundedTypeShapeSize,
+	}
+}
+
+// RequestLimits returns the limits of the request messages of the method in
+// the given wire format, or false if it has no request, i.e. is an event. As
+// for FitsInChannel, the unknown data that flexible envelopes may hold on
+// decode is not accounted for.
+func (m *Method) RequestLimits(wireFormat WireFormatVersion) (MessageLimits, bool) {
+	if !m.HasRequest {
+		return MessageLimits{}, false
+	}
+	return messageLimits(m.RequestPayload, wireFormat), true
+}
+
+// ResponseLimits returns the limits of the response messages of the method,
+// or of the event messages for an event, in the given wire format, or false if
+// it has no response, i.e. is one-way. See RequestLimits.
+func (m *Method) ResponseLimits(wireFormat WireFormatVersion) (MessageLimits, bool) {
+	if !m.HasResponse {
+		return MessageLimits{}, false
+	}
+	return messageLimits(m.ResponsePayload, wireFormat), true
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestFitsInChannel(t *testing.T) {
+	for _, tc := range []struct {
+		shape fidlgen.TypeShape
+		bytes int
+		fits  bool
+	}{
+		{
+			shape: fidlgen.TypeShape{},
+			bytes: 16,
+			fits:  true,
+		},
+		{
+			shape: fidlgen.TypeShape{InlineSize: 4, MaxOutOfLine: 12},
+			bytes: 40,
+			fits:  true,
+		},
+		{
+			shape: fidlgen.TypeShape{InlineSize: 16, MaxOutOfLine: fidlgen.ChannelMaxMessageBytes - 32},
+			bytes: fidlgen.ChannelMaxMessageBytes,
+			fits:  true,
+		},
+		{
+			shape: fidlgen.TypeShape{InlineSize: 16, MaxOutOfLine: fidlgen.ChannelMaxMessageBytes - 31},
+			bytes: fidlgen.ChannelMaxMessageBytes + 8,
+			fits:  false,
+		},
+		{
+			shape: fidlgen.TypeShape{InlineSize: 16, MaxOutOfLine: 0xffffffff},
+			bytes: 16 + 16 + 0x100000000,
+			fits:  false,
+		},
+		{
+			shape: fidlgen.TypeShape{InlineSize: 8, MaxHandles: fidlgen.ChannelMaxMessageHandles},
+			bytes: 24,
+			fits:  true,
+		},
+		{
+			shape: fidlgen.TypeShape{InlineSize: 8, MaxHandles: fidlgen.ChannelMaxMessageHandles + 1},
+			bytes: 24,
+			fits:  false,
+		},
+	} {
+		if got := fidlgen.MaxMessageBytes(tc.shape); got != tc.bytes {
+			t.Errorf("%+v: MaxMessageBytes got %d, want %d", tc.shape, got, tc.bytes)
+		}
+		if got := fidlgen.FitsInChannel(tc.shape); got != tc.fits {
+			t.Errorf("%+v: FitsInChannel got %t, want %t", tc.shape, got, tc.fits)
+		}
+	}
+}
+
+func TestMethodLimits(t *testing.T) {
+	const wf = fidlgen.WireFormatVersionV2
+	calc := fidlgen.TypeShapeCalculator{WireFormat: wf}
+	payload := func(shape fidlgen.TypeShape) *fidlgen.Type {
+		typ := &fidlgen.Type{Kind: fidlgen.IdentifierType}
+		typ.SetShape(wf, shape)
+		return typ
+	}
+	maxSize := 10
+	bounded, _ := calc.Struct([]fidlgen.TypeShape{calc.String(&maxSize), fidlgen.HandleTypeShape})
+	unbounded, _ := calc.Struct([]fidlgen.TypeShape{calc.Vector(fidlgen.HandleTypeShape, nil)})
+	method := fidlgen.Method{
+		HasRequest:      true,
+		RequestPayload:  payload(bounded),
+		HasResponse:     true,
+		ResponsePayload: payload(unbounded),
+	}
+
+	request, ok := method.RequestLimits(wf)
+	if !ok {
+		t.Fatal("no request limits")
+	}
+	// The header, the string header and handle padded to 24 bytes, and 10
+	// bytes of string data padded to 16.
+	if want := (fidlgen.MessageLimits{MaxBytes: 56, MaxHandles: 1}); request != want {
+		t.Errorf("got request limits %+v, want %+v", request, want)
+	}
+	if !request.Fits(fidlgen.ChannelMaxMessageBytes, fidlgen.ChannelMaxMessageHandles) {
+		t.Errorf("request does not fit in a channel")
+	}
+
+	response, ok := method.ResponseLimits(wf)
+	if !ok {
+		t.Fatal("no response limits")
+	}
+	if !response.UnboundedBytes || !response.UnboundedHandles {
+		t.Errorf("got response limits %+v, want unbounded", response)
+	}
+	if response.Fits(fidlgen.ChannelMaxMessageBytes, fidlgen.ChannelMaxMessageHandles) {
+		t.Errorf("unbounded response fits in a channel")
+	}
+
+	oneWay := fidlgen.Method{HasRequest: true}
+	if limits, ok := oneWay.RequestLimits(wf); !ok || limits != (fidlgen.MessageLimits{MaxBytes: fidlgen.MessageHeaderSize}) {
+		t.Errorf("got request limits %+v, %t for an empty request", limits, ok)
+	}
+	if _, ok := oneWay.ResponseLimits(wf); ok {
+		t.Errorf("got response limits for a one-way method")
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func transportAttributes(transport string) fidlgen.Attributes {
+	return fidlgen.Attributes{Attributes: []fidlgen.Attribute{{
+		Name: "transport",
+		Args: []fidlgen.AttributeArg{{
+			Name:  "value",
+			Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: transport},
+		}},
+	}}}
+}
+
+func TestParseTransport(t *testing.T) {
+	for _, s := range []string{"Channel", "Driver", "Syscall", "Banjo"} {
+		if transport, err := fidlgen.ParseTransport(s); err != nil || string(transport) != s {
+			t.Errorf("ParseTransport(%q): got (%q, %v)", s, transport, err)
+		}
+	}
+	if _, err := fidlgen.ParseTransport("channel"); err == nil {
+		t.Errorf("expected an error for an unknown transport")
+	}
+}
+
+func TestForTransport(t *testing.T) {
+	request := structDecl("example/DeviceDoRequest")
+	request.NamingContext = []string{"Device", "Do", "Request"}
+	ends := structDecl("example/Ends", "channel", "driver")
+	ends.Members[0].Type = fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/Node", ProtocolTransport: fidlgen.ChannelTransport}
+	ends.Members[1].Type = fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/Device", ProtocolTransport: fidlgen.DriverTransport}
+	device := fidlgen.Protocol{
+		Decl: fidlgen.Decl{Name: "example/Device", Attributes: transportAttributes("Driver")},
+		Methods: []fidlgen.Method{
+			{Name: "Do", HasRequest: true, RequestPayload: identifierType("example/DeviceDoRequest")},
+		},
+	}
+	root := fidlgen.Root{
+		Name:      "example",
+		Structs:   []fidlgen.Struct{request, ends},
+		Protocols: []fidlgen.Protocol{device, {Decl: fidlgen.Decl{Name: "example/Node"}}},
+		Services: []fidlgen.Service{
+			{
+				Decl: fidlgen.Decl{Name: "example/DriverService"},
+				Members: []fidlgen.ServiceMember{
+					{Name: "device", Type: ends.Members[1].Type},
+				},
+			},
+		},
+		Decls: fidlgen.DeclMap{
+			"example/DeviceDoRequest": fidlgen.StructDeclType,
+			"example/Ends":            fidlgen.StructDeclType,
+			"example/Device":          fidlgen.ProtocolDeclType,
+			"example/Node":            fidlgen.ProtocolDeclType,
+			"example/DriverService":   fidlgen.ServiceDeclType,
+		},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{
+			"example/DeviceDoRequest", "example/Device", "example/Ends", "example/Node", "example/DriverService",
+		},
+	}
+	if err := root.ValidateTransports(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := root.ValidateEndpoints(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		transport fidlgen.Transport
+		expected  []fidlgen.EncodedCompoundIdentifier
+		dangling  string
+	}{
+		{
+			fidlgen.ChannelTransport,
+			[]fidlgen.EncodedCompoundIdentifier{"example/Ends", "example/Node"},
+			"example/Ends: endpoint of undeclared protocol example/Device",
+		},
+		{
+			fidlgen.DriverTransport,
+			[]fidlgen.EncodedCompoundIdentifier{
+				"example/DeviceDoRequest", "example/Device", "example/Ends", "example/DriverService",
+			},
+			"example/Ends: endpoint of undeclared protocol example/Node",
+		},
+	} {
+		filtered := root.ForTransport(tc.transport)
+		if diff := cmp.Diff(tc.expected, filtered.DeclOrder); diff != "" {
+			t.Errorf("%s: unexpected diff (-want +got):\n%s", tc.transport, diff)
+		}
+		if len(filtered.Decls) != len(tc.expected) {
+			t.Errorf("%s: got declarations %v", tc.transport, filtered.Decls)
+		}
+		if err := filtered.ValidateEndpoints(); err == nil || !strings.Contains(err.Error(), tc.dangling) {
+			t.Errorf("%s: got %v, want an error reporting %q", tc.transport, err, tc.dangling)
+		}
+		if _, err := root.Filter(fidlgen.BindingsFilter{Transport: tc.transport}); err == nil {
+			t.Errorf("%s: expected Filter to report the dangling endpoint", tc.transport)
+		}
+	}
+
+	root.Protocols[1].Attributes = transportAttributes("Carrier Pigeon")
+	if err := root.ValidateTransports(); err == nil {
+		t.Errorf("expected an error for an unknown transport")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// TypeClass consolidates what backends generating both natural (domain
+// object) and wire types, in the style of the C++ bindings, need to know to
+// decide what to generate for a type declaration: whether it is exposed to
+// users, whether it is a resource, and whether it is excluded from the
+// bindings altogether.
+type TypeClass string
+
+const (
+	// DeniedTypeClass is for types excluded from the bindings by
+	// @bindings_denylist, directly or through an enclosing declaration.
+	DeniedTypeClass TypeClass = "denied"
+	// MessageBodyOnlyTypeClass is for types that only describe the shape of a
+	// message body and are never exposed to users, e.g. the struct wrapping
+	// the result union of a method using error syntax. Only a wire type is
+	// needed.
+	MessageBodyOnlyTypeClass TypeClass = "message_body_only"
+	// ValueTypeClass is for value types used as domain objects, which may
+	// also be used as payloads. Natural and wire types are needed, and may be
+	// copied.
+	ValueTypeClass TypeClass = "value"
+	// ResourceTypeClass is for resource types used as domain objects, which
+	// may also be used as payloads. Natural and wire types are needed, and are
+	// move-only.
+	ResourceTypeClass TypeClass = "resource"
+)
+
+// NeedsNaturalType returns whether a natural type should be generated.
+func (c TypeClass) NeedsNaturalType() bool {
+	return c == ValueTypeClass || c == ResourceTypeClass
+}
+
+// NeedsWireType returns whether a wire type should be generated.
+func (c TypeClass) NeedsWireType() bool {
+	return c != DeniedTypeClass
+}
+
+// IsMoveOnly returns whether the generated types may only be moved, as
+// opposed to copied.
+func (c TypeClass) IsMoveOnly() bool {
+	return c == ResourceTypeClass
+}
+
+// TypeClasses classifies the type declarations of the library (bits, enums,
+// structs, tables and unions) for the bindings of the given language. The map
+// is iterated in random order: iterate over the sorted names of the library's
+// Decls for deterministic output.
+func (r *Root) TypeClasses(language string) map[EncodedCompoundIdentifier]TypeClass {
+	denied := deniedContexts(r, language)
+	usage := r.MethodTypeUsageMap()
+	classes := make(map[EncodedCompoundIdentifier]TypeClass)
+	r.ForEachDecl(func(decl Declaration) {
+		switch decl.(type) {
+		case *Bits, *Enum, *Struct, *Table, *Union:
+		default:
+			return
+		}
+		name := decl.GetName()
+		// ExternalStructs are classified by their own library.
+		if name.LibraryName() != r.Name {
+			return
+		}
+
+		class := ValueTypeClass
+		if isDeclDenied(denied, decl, language) {
+			class = DeniedTypeClass
+		} else if usage[name] == UsedOnlyAsMessageBody {
+			class = MessageBodyOnlyTypeClass
+		} else if layout, ok := decl.(ResourceableLayoutDeclaration); ok && layout.GetResourceness().IsResourceType() {
+			class = ResourceTypeClass
+		}
+		classes[name] = class
+	})
+	return classes
+}
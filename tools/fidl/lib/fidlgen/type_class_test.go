@@ -0,0 +1,88 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestTypeClasses(t *testing.T) {
+	// Resembles the IR for:
+	//
+	//	type Point = struct { ... };
+	//	type Channel = resource struct { ... };
+	//	@bindings_denylist("cpp")
+	//	type Hidden = struct { ... };
+	//	protocol Geometry {
+	//	    Locate(Point) -> (Point) error uint32;
+	//	};
+	point := structDecl("example/Point", "x")
+	channel := structDecl("example/Channel", "handle")
+	channel.Resourceness = fidlgen.IsResourceType
+	hidden := structDecl("example/Hidden", "x")
+	hidden.Attributes = fidlgen.Attributes{
+		Attributes: []fidlgen.Attribute{
+			{
+				Name: "bindings_denylist",
+				Args: []fidlgen.AttributeArg{
+					{Name: "value", Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: "cpp"}},
+				},
+			},
+		},
+	}
+	root := fidlgen.Root{
+		Name: "example",
+		Structs: []fidlgen.Struct{
+			point, channel, hidden, structDecl("example/GeometryLocateResponse", "result"),
+		},
+		ExternalStructs: []fidlgen.Struct{structDecl("dep/External", "x")},
+		Unions: []fidlgen.Union{
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/GeometryLocateResult"}},
+				},
+			},
+		},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl: fidlgen.Decl{Name: "example/Geometry"},
+				Methods: []fidlgen.Method{
+					{
+						Name:            "Locate",
+						HasRequest:      true,
+						RequestPayload:  identifierType("example/Point"),
+						HasResponse:     true,
+						ResponsePayload: identifierType("example/GeometryLocateResponse"),
+						HasError:        true,
+						ResultType:      identifierType("example/GeometryLocateResult"),
+						ValueType:       identifierType("example/Point"),
+						ErrorType:       &fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32},
+					},
+				},
+			},
+		},
+	}
+
+	expected := map[fidlgen.EncodedCompoundIdentifier]fidlgen.TypeClass{
+		"example/Point":                  fidlgen.ValueTypeClass,
+		"example/Channel":                fidlgen.ResourceTypeClass,
+		"example/Hidden":                 fidlgen.DeniedTypeClass,
+		"example/GeometryLocateResponse": fidlgen.MessageBodyOnlyTypeClass,
+		"example/GeometryLocateResult":   fidlgen.ValueTypeClass,
+	}
+	if diff := cmp.Diff(expected, root.TypeClasses("cpp")); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	if fidlgen.MessageBodyOnlyTypeClass.NeedsNaturalType() || !fidlgen.MessageBodyOnlyTypeClass.NeedsWireType() {
+		t.Errorf("message body only types need a wire type but no natural type")
+	}
+	if !fidlgen.ResourceTypeClass.IsMoveOnly() || fidlgen.ValueTypeClass.IsMoveOnly() {
+		t.Errorf("only resource types are move-only")
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"sort"
+)
+
+// FIDL types may be recursive, as long as each cycle goes through an
+// indirection, e.g. `type Node = struct { next box<Node>; }`. Backends whose
+// natural types are stored inline need to know which types are recursive, and
+// through which members, to decide where to allocate on the heap: a Rust union
+// holding a struct which holds the union must box one of the two, unless the
+// recursion already goes through a vector or an optional.
+
+// TypeEdge is a reference from a struct, table or union to another, through
+// one of its members.
+type TypeEdge struct {
+	// From is the layout declaring the member.
+	From EncodedCompoundIdentifier
+	// Member is the name of the member.
+	Member Identifier
+	// To is the layout referred to by the type of the member, possibly as the
+	// element type of a vector or array.
+	To EncodedCompoundIdentifier
+	// Indirect is whether the member refers to To through an indirection, i.e.
+	// a box or optional, or a vector. Arrays are stored inline, and so are
+	// envelopes in most natural representations: the members of tables and
+	// unions are not indirect on that account alone.
+	Indirect bool
+}
+
+// TypeCycle is a group of recursive layouts: each of them refers to every
+// other, and to itself, through a chain of members. A single layout forms a
+// cycle if it refers to itself.
+type TypeCycle struct {
+	// Layouts lists the layouts of the group, sorted.
+	Layouts []EncodedCompoundIdentifier
+	// Edges lists the references between the layouts of the group, sorted by
+	// layout, then by member. Each of them is part of a cycle.
+	Edges []TypeEdge
+}
+
+// Breakers returns the edges of the cycle which go through an indirection,
+// and so break it. Backends storing envelopes inline must add an indirection of
+// their own, e.g. a heap allocation, on any chain of members through the group
+// which loops back without going through one of these edges.
+func (c TypeCycle) Breakers() []TypeEdge {
+	var edges []TypeEdge
+	for _, e := range c.Edges {
+		if e.Indirect {
+			edges = append(edges, e)
+		}
+	}
+	return edges
+}
+
+// typeEdges returns the references from a member of the given type to layouts
+// of the library.
+func typeEdges(from EncodedCompoundIdentifier, member Identifier, typ *Type, layouts map[EncodedCompoundIdentifier]struct{}) []TypeEdge {
+	var edges []TypeEdge
+	indirect := false
+	WalkType(typ, func(typ *Type) bool {
+		switch typ.Kind {
+		case VectorType:
+			indirect = true
+		case IdentifierType:
+			if _, ok := layouts[typ.Identifier]; ok {
+				edges = append(edges, TypeEdge{
+					From:     from,
+					Member:   member,
+					To:       typ.Identifier,
+					Indirect: indirect || typ.Nullable,
+				})
+			}
+		}
+		return true
+	})
+	return edges
+}
+
+// TypeCycles returns the groups of recursive structs, tables and unions of the
+// library, sorted by their first layout. Layouts of other libraries cannot
+// refer back to those of this library, so they are not part of any cycle.
+func (r *Root) TypeCycles() []TypeCycle {
+	layouts := make(map[EncodedCompoundIdentifier]struct{})
+	r.ForEachDecl(func(decl Declaration) {
+		switch decl.(type) {
+		case *Struct, *Table, *Union:
+			if decl.GetName().LibraryName() == r.Name {
+				layouts[decl.GetName()] = struct{}{}
+			}
+		}
+	})
+	edges := make(map[EncodedCompoundIdentifier][]TypeEdge)
+	r.ForEachDecl(func(decl Declaration) {
+		from := decl.GetName()
+		if _, ok := layouts[from]; !ok {
+			return
+		}
+		switch decl := decl.(type) {
+		case *Struct:
+			for i := range decl.Members {
+				edges[from] = append(edges[from], typeEdges(from, decl.Members[i].Name, &decl.Members[i].Type, layouts)...)
+			}
+		case *Table:
+			for i := range decl.Members {
+				if !decl.Members[i].Reserved {
+					edges[from] = append(edges[from], typeEdges(from, decl.Members[i].Name, &decl.Members[i].Type, layouts)...)
+				}
+			}
+		case *Union:
+			for i := range decl.Members {
+				if !decl.Members[i].Reserved {
+					edges[from] = append(edges[from], typeEdges(from, decl.Members[i].Name, &decl.Members[i].Type, layouts)...)
+				}
+			}
+		}
+	})
+
+	var names []EncodedCompoundIdentifier
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sortNames(names)
+
+	// Tarjan's algorithm finds the strongly connected components of the graph
+	// of references, which are the groups of recursive layouts.
+	var (
+		cycles  []TypeCycle
+		index   = make(map[EncodedCompoundIdentifier]int)
+		lowLink = make(map[EncodedCompoundIdentifier]int)
+		onStack = make(map[EncodedCompoundIdentifier]bool)
+		stack   []EncodedCompoundIdentifier
+	)
+	var visit func(EncodedCompoundIdentifier)
+	visit = func(name EncodedCompoundIdentifier) {
+		index[name] = len(index)
+		lowLink[name] = index[name]
+		stack = append(stack, name)
+		onStack[name] = true
+		for _, e := range edges[name] {
+			if _, ok := index[e.To]; !ok {
+				visit(e.To)
+				if lowLink[e.To] < lowLink[name] {
+					lowLink[name] = lowLink[e.To]
+				}
+			} else if onStack[e.To] && index[e.To] < lowLink[name] {
+				lowLink[name] = index[e.To]
+			}
+		}
+		if lowLink[name] != index[name] {
+			return
+		}
+
+		group := make(map[EncodedCompoundIdentifier]struct{})
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			group[top] = struct{}{}
+			if top == name {
+				break
+			}
+		}
+		var cycle TypeCycle
+		for member := range group {
+			cycle.Layouts = append(cycle.Layouts, member)
+			for _, e := range edges[member] {
+				if _, ok := group[e.To]; ok {
+					cycle.Edges = append(cycle.Edges, e)
+				}
+			}
+		}
+		// A single layout only forms a cycle if it refers to itself.
+		if len(cycle.Edges) == 0 {
+			return
+		}
+		sortNames(cycle.Layouts)
+		sort.SliceStable(cycle.Edges, func(i, j int) bool {
+			a, b := cycle.Edges[i], cycle.Edges[j]
+			if a.From != b.From {
+				return a.From < b.From
+			}
+			return a.Member < b.Member
+		})
+		cycles = append(cycles, cycle)
+	}
+	for _, name := range names {
+		if _, ok := index[name]; !ok {
+			visit(name)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i].Layouts[0] < cycles[j].Layouts[0]
+	})
+	return cycles
+}
@@ -0,0 +1,79 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestTypeCycles(t *testing.T) {
+	// type Node = struct { next box<Node>; };
+	node := structDecl("example/Node", "next")
+	node.Members[0].Type = *identifierType("example/Node")
+	node.Members[0].Type.Nullable = true
+	// type Holder = struct { node Node; };
+	holder := structDecl("example/Holder", "node")
+	holder.Members[0].Type = *identifierType("example/Node")
+	// type Tree = struct { value Value; };
+	// type Value = union { 1: leaf uint32; 2: trees vector<Tree>; };
+	tree := structDecl("example/Tree", "value")
+	tree.Members[0].Type = *identifierType("example/Value")
+	value := fidlgen.Union{
+		ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+			LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Value"}},
+		},
+		Members: []fidlgen.UnionMember{
+			{Ordinal: 1, Name: "leaf", Type: fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}},
+			{Ordinal: 2, Name: "trees", Type: fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifierType("example/Tree")}},
+			{Ordinal: 3, Reserved: true},
+		},
+	}
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{node, holder, tree},
+		Unions:  []fidlgen.Union{value},
+	}
+
+	expected := []fidlgen.TypeCycle{
+		{
+			Layouts: []fidlgen.EncodedCompoundIdentifier{"example/Node"},
+			Edges: []fidlgen.TypeEdge{
+				{From: "example/Node", Member: "next", To: "example/Node", Indirect: true},
+			},
+		},
+		{
+			Layouts: []fidlgen.EncodedCompoundIdentifier{"example/Tree", "example/Value"},
+			Edges: []fidlgen.TypeEdge{
+				{From: "example/Tree", Member: "value", To: "example/Value"},
+				{From: "example/Value", Member: "trees", To: "example/Tree", Indirect: true},
+			},
+		},
+	}
+	cycles := root.TypeCycles()
+	if diff := cmp.Diff(expected, cycles); diff != "" {
+		t.Fatalf("cycles (-want +got):\n%s", diff)
+	}
+	breakers := []fidlgen.TypeEdge{
+		{From: "example/Value", Member: "trees", To: "example/Tree", Indirect: true},
+	}
+	if diff := cmp.Diff(breakers, cycles[1].Breakers()); diff != "" {
+		t.Errorf("breakers (-want +got):\n%s", diff)
+	}
+}
+
+func TestTypeCyclesNone(t *testing.T) {
+	holder := structDecl("example/Holder", "inner")
+	holder.Members[0].Type = *identifierType("example/Inner")
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{holder, structDecl("example/Inner")},
+	}
+	if cycles := root.TypeCycles(); cycles != nil {
+		t.Errorf("got cycles %+v", cycles)
+	}
+}
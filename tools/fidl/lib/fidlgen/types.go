@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"reflect"
 	"sort"
@@ -41,11 +42,38 @@ func ReadJSONIr(filename string) (Root, error) {
 
 // DecodeJSONIr reads the JSON content from a reader.
 func DecodeJSONIr(r io.Reader) (Root, error) {
+	return DecodeJSONIrWithOptions(r, DecodeOptions{})
+}
+
+// DecodeOptions configures DecodeJSONIrWithOptions.
+type DecodeOptions struct {
+	// OmitTypeShapeV1 leaves the V1 type and field shapes zero, for consumers
+	// which only target the V2 wire format. The IR may then lack them too.
+	OmitTypeShapeV1 bool
+	// RequireMethodStrictness rejects IR in which a method has no explicit
+	// strictness, see Root.ValidateMethodStrictness.
+	RequireMethodStrictness bool
+}
+
+// DecodeJSONIrWithOptions reads the JSON content from a reader, as configured
+// by opts.
+func DecodeJSONIrWithOptions(r io.Reader, opts DecodeOptions) (Root, error) {
 	d := json.NewDecoder(r)
 	var root Root
 	if err := d.Decode(&root); err != nil {
 		return Root{}, fmt.Errorf("Error parsing JSON IR: %w", err)
 	}
+	if opts.OmitTypeShapeV1 {
+		root.OmitTypeShapeV1()
+	}
+	if opts.RequireMethodStrictness {
+		if err := root.ValidateMethodStrictness(); err != nil {
+			return Root{}, err
+		}
+	}
+	root.MarkBoxedTypes()
+	root.MarkEndpointTypes()
+	root.MarkHandleObjectTypes()
 	return root, nil
 }
 
@@ -268,7 +296,20 @@ func (typ PrimitiveSubtype) IsFloat() bool {
 type InternalSubtype string
 
 const (
-	TransportErr InternalSubtype = "transport_error"
+	// FrameworkErr is the type of the framework error variant of the result
+	// union of flexible two-way methods, which carries errors raised by the
+	// bindings rather than by the method implementation, e.g. for unknown
+	// methods.
+	FrameworkErr InternalSubtype = "framework_error"
+
+	// TransportErr is the former name of FrameworkErr.
+	//
+	// Deprecated: Use FrameworkErr.
+	TransportErr = FrameworkErr
+
+	// legacyTransportErr is how older versions of fidlc spell FrameworkErr.
+	// It is decoded as FrameworkErr.
+	legacyTransportErr InternalSubtype = "transport_error"
 )
 
 type HandleSubtype string
@@ -305,7 +346,8 @@ const (
 	HandleSubtypeVmo          HandleSubtype = "vmo"
 )
 
-// TODO(fxb/64629): Remove, source of truth is library zx.
+// TODO(fxb/64629): Remove, source of truth is library zx. Prefer
+// Root.ObjectType, which only falls back to this mapping.
 //
 // One complication is that GIDL parses nice handle subtypes in its grammar,
 // e.g. `#0 = event(rights: execute + write )`. And some GIDL backends care
@@ -350,67 +392,14 @@ const (
 	ObjectTypeMsi
 )
 
+// ObjectTypeFromHandleSubtype returns the object type of a handle subtype
+// fidlgen knows about, or ObjectTypeNone if it is unknown. The IR also gives
+// the object types of newer subtypes, see Root.ObjectType.
 func ObjectTypeFromHandleSubtype(val HandleSubtype) ObjectType {
-	switch val {
-	case HandleSubtypeBti:
-		return ObjectTypeBti
-	case HandleSubtypeChannel:
-		return ObjectTypeChannel
-	case HandleSubtypeClock:
-		return ObjectTypeClock
-	case HandleSubtypeDebugLog:
-		return ObjectTypeDebugLog
-	case HandleSubtypeEvent:
-		return ObjectTypeEvent
-	case HandleSubtypeEventpair:
-		return ObjectTypeEventPair
-	case HandleSubtypeException:
-		return ObjectTypeException
-	case HandleSubtypeFifo:
-		return ObjectTypeFifo
-	case HandleSubtypeGuest:
-		return ObjectTypeGuest
-	case HandleSubtypeInterrupt:
-		return ObjectTypeInterrupt
-	case HandleSubtypeIommu:
-		return ObjectTypeIommu
-	case HandleSubtypeJob:
-		return ObjectTypeJob
-	case HandleSubtypeMsi:
-		return ObjectTypeMsi
-	case HandleSubtypePager:
-		return ObjectTypePager
-	case HandleSubtypePciDevice:
-		return ObjectTypePciDevice
-	case HandleSubtypePmt:
-		return ObjectTypePmt
-	case HandleSubtypePort:
-		return ObjectTypePort
-	case HandleSubtypeProcess:
-		return ObjectTypeProcess
-	case HandleSubtypeProfile:
-		return ObjectTypeProfile
-	case HandleSubtypeResource:
-		return ObjectTypeResource
-	case HandleSubtypeSocket:
-		return ObjectTypeSocket
-	case HandleSubtypeStream:
-		return ObjectTypeStream
-	case HandleSubtypeSuspendToken:
-		return ObjectTypeSuspendToken
-	case HandleSubtypeThread:
-		return ObjectTypeThread
-	case HandleSubtypeTime:
-		return ObjectTypeTimer
-	case HandleSubtypeVcpu:
-		return ObjectTypeVcpu
-	case HandleSubtypeVmar:
-		return ObjectTypeVmar
-	case HandleSubtypeVmo:
-		return ObjectTypeVmo
-	default:
-		return ObjectTypeNone
+	if info, ok := LookupHandleSubtype(val); ok {
+		return info.ObjectType
 	}
+	return ObjectTypeNone
 }
 
 type HandleRights uint32
@@ -474,6 +463,125 @@ type Constant struct {
 	Expression string                    `json:"expression"`
 }
 
+var integerSubtypeBitWidths = map[PrimitiveSubtype]int{
+	Int8:   8,
+	Int16:  16,
+	Int32:  32,
+	Int64:  64,
+	Uint8:  8,
+	Uint16: 16,
+	Uint32: 32,
+	Uint64: 64,
+}
+
+// AsUint64 parses the resolved value of an integral constant of the given
+// subtype, failing if it is negative or out of the range of the subtype.
+func (c Constant) AsUint64(subtype PrimitiveSubtype) (uint64, error) {
+	width, ok := integerSubtypeBitWidths[subtype]
+	if !ok {
+		return 0, fmt.Errorf("%s is not an integral subtype", subtype)
+	}
+	negative, digits, base := integerDigits(c.Value)
+	if negative {
+		return 0, fmt.Errorf("constant value %s is negative", c.Value)
+	}
+	if subtype.IsSigned() {
+		width--
+	}
+	u, err := strconv.ParseUint(digits, base, width)
+	if err != nil {
+		return 0, fmt.Errorf("constant value %s is not a valid %s: %w", c.Value, subtype, err)
+	}
+	return u, nil
+}
+
+// AsInt64 parses the resolved value of an integral constant of the given
+// subtype, failing if it is out of the range of the subtype or of int64.
+func (c Constant) AsInt64(subtype PrimitiveSubtype) (int64, error) {
+	width, ok := integerSubtypeBitWidths[subtype]
+	if !ok {
+		return 0, fmt.Errorf("%s is not an integral subtype", subtype)
+	}
+	if subtype.IsUnsigned() {
+		u, err := c.AsUint64(subtype)
+		if err != nil {
+			return 0, err
+		}
+		if u > math.MaxInt64 {
+			return 0, fmt.Errorf("constant value %s overflows int64", c.Value)
+		}
+		return int64(u), nil
+	}
+	negative, digits, base := integerDigits(c.Value)
+	u, err := strconv.ParseUint(digits, base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("constant value %s is not a valid %s: %w", c.Value, subtype, err)
+	}
+	// The magnitude of the minimum of the subtype, one more than its maximum.
+	limit := uint64(1) << (width - 1)
+	switch {
+	case negative && u <= limit:
+		return -int64(u), nil
+	case !negative && u < limit:
+		return int64(u), nil
+	default:
+		return 0, fmt.Errorf("constant value %s is out of the range of %s", c.Value, subtype)
+	}
+}
+
+// integerDigits splits the value of an integral constant into its sign and
+// its digits, which are in base 10 unless prefixed with 0x or 0b, as in FIDL.
+// strconv would otherwise read `010` as octal and accept `1_000`, given base 0.
+func integerDigits(value string) (negative bool, digits string, base int) {
+	if strings.HasPrefix(value, "-") {
+		negative, value = true, value[1:]
+	}
+	if len(value) > 2 && value[0] == '0' {
+		switch value[1] {
+		case 'x', 'X':
+			return negative, value[2:], 16
+		case 'b', 'B':
+			return negative, value[2:], 2
+		}
+	}
+	return negative, value, 10
+}
+
+// AsFloat parses the resolved value of a floating-point constant of the given
+// subtype, failing if it is out of the range of the subtype.
+func (c Constant) AsFloat(subtype PrimitiveSubtype) (float64, error) {
+	if !subtype.IsFloat() {
+		return 0, fmt.Errorf("%s is not a floating-point subtype", subtype)
+	}
+	bitSize := 64
+	if subtype == Float32 {
+		bitSize = 32
+	}
+	f, err := strconv.ParseFloat(c.Value, bitSize)
+	if err != nil {
+		return 0, fmt.Errorf("constant value %s is not a valid %s: %w", c.Value, subtype, err)
+	}
+	return f, nil
+}
+
+// AsBool parses the resolved value of a boolean constant.
+func (c Constant) AsBool() (bool, error) {
+	switch c.Value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("constant value %s is not a valid bool", c.Value)
+}
+
+// AsString returns the resolved value of a string constant. Unlike the
+// literal and expression, which are given as written in FIDL, the value is
+// neither quoted nor escaped.
+func (c Constant) AsString() string {
+	return c.Value
+}
+
 // Location gives the location of the FIDL declaration in its source `.fidl`
 // file.
 type Location struct {
@@ -522,11 +630,19 @@ type Type struct {
 	Identifier         EncodedCompoundIdentifier
 	InternalSubtype    InternalSubtype
 	Nullable           bool
-	ProtocolTransport  string
+	ProtocolTransport  Transport
 	ObjType            uint32
 	ResourceIdentifier string
 	TypeShapeV1        TypeShape
 	TypeShapeV2        TypeShape
+	// Boxed is set on optional struct types, i.e. `box<T>`, which are stored
+	// out-of-line as opposed to the inline optionality of other types. It is
+	// not part of the JSON IR, but computed by DecodeJSONIr.
+	Boxed bool
+	// Endpoint is set on protocol endpoint types, i.e. client and server
+	// ends, whichever way the IR encodes them. Like Boxed, it is computed by
+	// DecodeJSONIr.
+	Endpoint *Endpoint
 }
 
 // UnmarshalJSON customizes the JSON unmarshalling for Type.
@@ -541,9 +657,12 @@ func (t *Type) UnmarshalJSON(b []byte) error {
 	if err != nil {
 		return err
 	}
-	err = json.Unmarshal(*obj["type_shape_v1"], &t.TypeShapeV1)
-	if err != nil {
-		return err
+	// The V1 shape may be omitted, see DecodeOptions.OmitTypeShapeV1.
+	if shape, ok := obj["type_shape_v1"]; ok {
+		err = json.Unmarshal(*shape, &t.TypeShapeV1)
+		if err != nil {
+			return err
+		}
 	}
 	err = json.Unmarshal(*obj["type_shape_v2"], &t.TypeShapeV2)
 	if err != nil {
@@ -647,6 +766,9 @@ func (t *Type) UnmarshalJSON(b []byte) error {
 		if err != nil {
 			return err
 		}
+		if t.InternalSubtype == legacyTransportErr {
+			t.InternalSubtype = FrameworkErr
+		}
 	default:
 		return fmt.Errorf("Unknown type kind: %s", t.Kind)
 	}
@@ -702,13 +824,91 @@ type Attributes struct {
 
 func (el Attributes) LookupAttribute(name Identifier) (Attribute, bool) {
 	for _, a := range el.Attributes {
-		if ToSnakeCase(string(a.Name)) == ToSnakeCase(string(name)) {
+		if a.CanonicalName() == canonicalAttributeName(name) {
 			return a, true
 		}
 	}
 	return Attribute{}, false
 }
 
+func canonicalAttributeName(name Identifier) Identifier {
+	return Identifier(ToSnakeCase(string(name)))
+}
+
+// CanonicalName returns the name of the attribute in canonical snake_case
+// form, under which attribute names are compared: e.g. both @Transitional and
+// @transitional have the canonical name "transitional".
+func (el Attribute) CanonicalName() Identifier {
+	return canonicalAttributeName(el.Name)
+}
+
+// AttributeOrder specifies the order in which attributes (or attribute
+// arguments) are listed.
+type AttributeOrder int
+
+const (
+	// SourceAttributeOrder lists attributes in the order in which they
+	// appear in source, as preserved by the IR.
+	SourceAttributeOrder AttributeOrder = iota
+
+	// CanonicalAttributeOrder lists attributes sorted by canonical name,
+	// falling back to source order among attributes with the same name. This
+	// yields a listing that is stable across reorderings of the source.
+	CanonicalAttributeOrder
+)
+
+// Ordered returns a copy of the list of attributes in the given order.
+func (el Attributes) Ordered(order AttributeOrder) []Attribute {
+	attrs := append([]Attribute(nil), el.Attributes...)
+	switch order {
+	case SourceAttributeOrder:
+	case CanonicalAttributeOrder:
+		sort.SliceStable(attrs, func(i, j int) bool {
+			return attrs[i].CanonicalName() < attrs[j].CanonicalName()
+		})
+	default:
+		panic(fmt.Sprintf("unknown attribute order: %d", order))
+	}
+	return attrs
+}
+
+// OrderedArgs returns a copy of the list of the attribute's arguments in the
+// given order.
+func (el Attribute) OrderedArgs(order AttributeOrder) []AttributeArg {
+	args := append([]AttributeArg(nil), el.Args...)
+	switch order {
+	case SourceAttributeOrder:
+	case CanonicalAttributeOrder:
+		sort.SliceStable(args, func(i, j int) bool {
+			return canonicalAttributeName(args[i].Name) < canonicalAttributeName(args[j].Name)
+		})
+	default:
+		panic(fmt.Sprintf("unknown attribute order: %d", order))
+	}
+	return args
+}
+
+// DuplicateAttributes returns the sorted canonical names of the attributes
+// that appear more than once in the list. fidlc rejects such duplicates, so
+// this is only expected to be non-empty for IR that did not come from fidlc
+// (e.g., synthesized or hand-written IR).
+func (el Attributes) DuplicateAttributes() []Identifier {
+	counts := make(map[Identifier]int)
+	for _, a := range el.Attributes {
+		counts[a.CanonicalName()]++
+	}
+	var dups []Identifier
+	for name, count := range counts {
+		if count > 1 {
+			dups = append(dups, name)
+		}
+	}
+	sort.Slice(dups, func(i, j int) bool {
+		return dups[i] < dups[j]
+	})
+	return dups
+}
+
 func (el Attributes) HasAttribute(name Identifier) bool {
 	_, ok := el.LookupAttribute(name)
 	return ok
@@ -727,20 +927,20 @@ func (el Attributes) DocComments() []string {
 	return strings.Split(docVal[0:len(docVal)-1], "\n")
 }
 
-func (el Attributes) Transports() map[string]struct{} {
-	transports := make(map[string]struct{})
+func (el Attributes) Transports() map[Transport]struct{} {
+	transports := make(map[Transport]struct{})
 	attr, ok := el.LookupAttribute("transport")
 	if ok {
 		raw, ok := attr.LookupArgStandalone()
 		if ok && raw.ValueString() != "" {
 			for _, transport := range strings.Split(raw.ValueString(), ",") {
-				transports[strings.TrimSpace(transport)] = struct{}{}
+				transports[Transport(strings.TrimSpace(transport))] = struct{}{}
 			}
 		}
 	}
 	// No transport attribute => just Channel
 	if !ok {
-		transports["Channel"] = struct{}{}
+		transports[ChannelTransport] = struct{}{}
 	}
 	return transports
 }
@@ -764,6 +964,40 @@ func (el Attributes) BindingsDenylistIncludes(targetLanguage string) bool {
 	return false
 }
 
+// IsDeprecated returns true if the element is deprecated, either by the
+// `deprecated` argument of @available or by @deprecated. The IR does not
+// record the version it was compiled at, so an element deprecated at any
+// version is considered deprecated.
+func (el Attributes) IsDeprecated() bool {
+	if el.HasAttribute("deprecated") {
+		return true
+	}
+	if attr, ok := el.LookupAttribute("available"); ok {
+		return attr.HasArg("deprecated")
+	}
+	return false
+}
+
+// DeprecationNote returns the explanation attached to a deprecation, i.e. the
+// argument of @deprecated or else the `note` argument of @available, if any.
+// It is empty for elements that are not deprecated.
+func (el Attributes) DeprecationNote() string {
+	if !el.IsDeprecated() {
+		return ""
+	}
+	if attr, ok := el.LookupAttribute("deprecated"); ok {
+		if arg, ok := attr.LookupArgStandalone(); ok && arg.ValueString() != "" {
+			return arg.ValueString()
+		}
+	}
+	if attr, ok := el.LookupAttribute("available"); ok {
+		if arg, ok := attr.LookupArg("note"); ok {
+			return arg.ValueString()
+		}
+	}
+	return ""
+}
+
 // TypeShape represents the shape of the type on the wire.
 // See JSON IR schema, e.g. fidlc --json-schema
 type TypeShape struct {
@@ -788,6 +1022,8 @@ type Declaration interface {
 	GetAttributes() Attributes
 	GetName() EncodedCompoundIdentifier
 	GetLocation() Location
+	IsDeprecated() bool
+	DeprecationNote() string
 }
 
 type Decl struct {
@@ -879,6 +1115,10 @@ type LayoutDeclaration interface {
 type LayoutDecl struct {
 	Decl
 	NamingContext NamingContext `json:"naming_context"`
+	// Synthetic is set on layouts which are not part of the IR, but were
+	// synthesized by a transformation such as Root.WithSyntheticResults.
+	// They do not appear on the wire.
+	Synthetic bool `json:"-"`
 }
 
 func (l LayoutDecl) GetNamingContext() NamingContext {
@@ -1050,6 +1290,44 @@ type StructMember struct {
 	FieldShapeV2      FieldShape              `json:"field_shape_v2"`
 }
 
+// IsEmpty returns whether the struct is the canonical empty struct, i.e. it
+// has no members. On the wire, the empty struct still has a size of 1: a single
+// zero byte.
+func (s Struct) IsEmpty() bool {
+	return len(s.Members) == 0
+}
+
+// EmptyStructRepresentation describes how a backend represents the empty
+// struct in generated code.
+type EmptyStructRepresentation struct {
+	// PlaceholderName is the name of the uint8 member standing in for the
+	// single byte of the empty struct. If empty, no placeholder is emitted,
+	// which suits languages with zero-sized types (e.g. Rust).
+	PlaceholderName string
+}
+
+// ZeroSizedEmptyStruct represents the empty struct as a zero-sized type,
+// without a placeholder member.
+var ZeroSizedEmptyStruct = EmptyStructRepresentation{}
+
+// Placeholder returns the placeholder member to emit for a struct, which is
+// only needed if the struct is empty and the representation has one.
+func (r EmptyStructRepresentation) Placeholder(s Struct) (StructMember, bool) {
+	if !s.IsEmpty() || r.PlaceholderName == "" {
+		return StructMember{}, false
+	}
+	return EmptyStructMember(r.PlaceholderName), true
+}
+
+// Members returns the members to emit for a struct: its own or, if it is
+// empty, the placeholder if there is one.
+func (r EmptyStructRepresentation) Members(s Struct) []StructMember {
+	if placeholder, ok := r.Placeholder(s); ok {
+		return []StructMember{placeholder}
+	}
+	return s.Members
+}
+
 // EmptyStructMember returns a StructMember that's suitable as the sole member
 // of an empty struct.
 func EmptyStructMember(name string) StructMember {
@@ -1110,24 +1388,39 @@ type Protocol struct {
 // library name and protocol declaration name separated by dots and enclosed in quotes. For example,
 // "\"my.library.MyProtocol\"". This part of legacy service discovery (pre-RFC-0041).
 func (d *Protocol) GetProtocolName() string {
-	attr, ok := d.LookupAttribute("discoverable")
+	name, ok := d.DiscoveryName()
 	if !ok {
 		return ""
 	}
-	var name string
+	return strconv.Quote(name)
+}
+
+// DiscoveryName returns the unquoted discovery name of the protocol, and
+// whether the protocol is @discoverable at all. The name defaults to the
+// protocol's qualified name, e.g. "my.library.MyProtocol", unless given
+// explicitly as the argument of @discoverable.
+func (d *Protocol) DiscoveryName() (string, bool) {
+	attr, ok := d.LookupAttribute("discoverable")
+	if !ok {
+		return "", false
+	}
 	if arg, ok := attr.LookupArgStandalone(); ok {
-		name = arg.ValueString()
-	} else {
-		// TODO(fxbug.dev/102803): Construct this string in fidlc, not here.
-		ci := d.Name.Parse()
-		var parts []string
-		for _, i := range ci.Library {
-			parts = append(parts, string(i))
-		}
-		parts = append(parts, string(ci.Name))
-		name = strings.Join(parts, ".")
+		return arg.ValueString(), true
 	}
-	return strconv.Quote(name)
+	return d.QualifiedName(), true
+}
+
+// QualifiedName returns the name of the protocol qualified by its library,
+// with all components separated by dots, e.g. "my.library.MyProtocol".
+func (d *Protocol) QualifiedName() string {
+	// TODO(fxbug.dev/102803): Construct this string in fidlc, not here.
+	ci := d.Name.Parse()
+	var parts []string
+	for _, i := range ci.Library {
+		parts = append(parts, string(i))
+	}
+	parts = append(parts, string(ci.Name))
+	return strings.Join(parts, ".")
 }
 
 // Returns true if this protocol must handle one-way unknown interactions.
@@ -1140,6 +1433,35 @@ func (p *Protocol) TwoWayUnknownInteractions() bool {
 	return p.Openness == Open
 }
 
+// methodsWhere returns the methods of the protocol satisfying a predicate, in
+// declaration order.
+func (p *Protocol) methodsWhere(pred func(m *Method) bool) []Method {
+	var methods []Method
+	for i := range p.Methods {
+		if pred(&p.Methods[i]) {
+			methods = append(methods, p.Methods[i])
+		}
+	}
+	return methods
+}
+
+// Events returns the events of the protocol, in declaration order.
+func (p *Protocol) Events() []Method {
+	return p.methodsWhere((*Method).IsEvent)
+}
+
+// OneWayMethods returns the one-way methods of the protocol, in declaration
+// order.
+func (p *Protocol) OneWayMethods() []Method {
+	return p.methodsWhere((*Method).IsOneWay)
+}
+
+// TwoWayMethods returns the two-way methods of the protocol, in declaration
+// order.
+func (p *Protocol) TwoWayMethods() []Method {
+	return p.methodsWhere((*Method).IsTwoWay)
+}
+
 // Service represents the declaration of a FIDL service.
 type Service struct {
 	Decl
@@ -1170,6 +1492,8 @@ type Method struct {
 	Ordinal uint64 `json:"ordinal"`
 	// Unqualified name of the method.
 	Name Identifier `json:"name"`
+	// Location of the method in its source `.fidl` file.
+	Location Location `json:"location"`
 	// Whether the method is marked as strict (other wise flexible).
 	//
 	// While unknown interactions are experimental, a not-set strictness should
@@ -1215,6 +1539,11 @@ type Method struct {
 	// If error syntax is used, this is the type of the error variant of the
 	// ResultType union.
 	ErrorType *Type `json:"maybe_response_err_type,omitempty"`
+	// True if ResultType and ValueType were synthesized by
+	// Root.WithSyntheticResults. The ResponsePayload is then still the return
+	// value of the method: the result union is a view, not part of the wire
+	// format.
+	SyntheticResult bool `json:"-"`
 }
 
 // GetRequestPayloadIdentifier retrieves the identifier that points to the
@@ -1239,7 +1568,7 @@ func (m *Method) GetResponsePayloadIdentifier() (EncodedCompoundIdentifier, bool
 // unknown interactions are experimental, and if strict is missing it should be
 // treated as true.
 // TODO(fxbug.dev/88366): replace this method with direct access to the Strict
-// field, once it is required.
+// field, once it is required. Root.ValidateMethodStrictness checks that it is.
 func (m *Method) IsStrict() bool {
 	return m.MaybeStrict == nil || *m.MaybeStrict
 }
@@ -1263,10 +1592,35 @@ func (m *Method) HasResponsePayload() bool {
 	return m.ResponsePayload != nil
 }
 
-// HasTransportError returns true if the method uses a result union with
-// transport_err variant. This is true if it is a flexible two-way method.
+// HasFrameworkError returns true if the method uses a result union with a
+// framework_err variant. This is true if it is a flexible two-way method.
+func (m *Method) HasFrameworkError() bool {
+	return m.IsTwoWay() && m.IsFlexible()
+}
+
+// HasTransportError is the former name of HasFrameworkError.
+//
+// Deprecated: Use HasFrameworkError.
 func (m *Method) HasTransportError() bool {
-	return m.HasRequest && m.HasResponse && m.IsFlexible()
+	return m.HasFrameworkError()
+}
+
+// IsEvent returns whether the method is an event, i.e. it is sent by the
+// server, and has a response but no request.
+func (m *Method) IsEvent() bool {
+	return !m.HasRequest && m.HasResponse
+}
+
+// IsOneWay returns whether the method is a one-way method, i.e. it is sent by
+// the client and has no response. Events are not one-way methods.
+func (m *Method) IsOneWay() bool {
+	return m.HasRequest && !m.HasResponse
+}
+
+// IsTwoWay returns whether the method is a two-way method, i.e. it is sent by
+// the client and has a response.
+func (m *Method) IsTwoWay() bool {
+	return m.HasRequest && m.HasResponse
 }
 
 // Enum represents a FIDL declaration of an enum.
@@ -1461,9 +1815,37 @@ type DeclInfo struct {
 	*Resourceness `json:"resource,omitempty"`
 }
 
+// DeclMap and DeclInfoMap, like the other maps of this package, are iterated
+// in random order: code whose output depends on the iteration order, such as
+// generators, should iterate over SortedNames instead.
 type DeclMap map[EncodedCompoundIdentifier]DeclType
 type DeclInfoMap map[EncodedCompoundIdentifier]DeclInfo
 
+// SortedNames returns the names of the declarations of the map, sorted.
+func (m DeclMap) SortedNames() []EncodedCompoundIdentifier {
+	names := make([]EncodedCompoundIdentifier, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return sortNames(names)
+}
+
+// SortedNames returns the names of the declarations of the map, sorted.
+func (m DeclInfoMap) SortedNames() []EncodedCompoundIdentifier {
+	names := make([]EncodedCompoundIdentifier, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return sortNames(names)
+}
+
+func sortNames(names []EncodedCompoundIdentifier) []EncodedCompoundIdentifier {
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
+
 func (dt DeclType) IsPrimitive() bool {
 	switch dt {
 	case BitsDeclType, EnumDeclType:
@@ -1499,6 +1881,10 @@ type Root struct {
 	DeclOrder       []EncodedCompoundIdentifier `json:"declaration_order,omitempty"`
 	Decls           DeclMap                     `json:"declarations,omitempty"`
 	Libraries       []Library                   `json:"library_dependencies,omitempty"`
+	// HandleObjectTypes maps the handle subtypes known to the IR to their
+	// object types. It is not part of the JSON IR, but computed by
+	// DecodeJSONIr.
+	HandleObjectTypes HandleObjectTypes `json:"-"`
 }
 
 // ForEachDecl calls a provided callback on each associated declaration. Logic
@@ -1543,8 +1929,134 @@ func (r *Root) ForEachDecl(cb func(Declaration)) {
 	}
 }
 
+// LookupDecl returns the declaration of this library with the given name. If
+// the name refers to a member, the enclosing declaration is returned. Only the
+// declarations carried by the IR of this library can be found: use a
+// Compilation to look up those of its dependencies.
+func (r *Root) LookupDecl(name EncodedCompoundIdentifier) (Declaration, bool) {
+	name = name.DeclName()
+	// The declarations map gives the type of the declaration, so that only
+	// the declarations of that type are searched. Names missing from it (e.g.
+	// external structs) fall back to searching every type.
+	if declType, ok := r.Decls[name]; ok {
+		if decl := r.lookupDeclOfType(declType, name); decl != nil {
+			return decl, true
+		}
+	}
+	for _, declType := range declTypesInOrder {
+		if decl := r.lookupDeclOfType(declType, name); decl != nil {
+			return decl, true
+		}
+	}
+	return nil, false
+}
+
+// declTypesInOrder lists the declaration types in the order in which
+// ForEachDecl visits them.
+var declTypesInOrder = []DeclType{
+	ConstDeclType,
+	BitsDeclType,
+	EnumDeclType,
+	ResourceDeclType,
+	ProtocolDeclType,
+	ServiceDeclType,
+	StructDeclType,
+	TableDeclType,
+	UnionDeclType,
+	TypeAliasDeclType,
+	NewTypeDeclType,
+}
+
+// lookupDeclOfType returns the declaration of the given type and name, or nil
+// if there is none. Structs include external structs.
+func (r *Root) lookupDeclOfType(declType DeclType, name EncodedCompoundIdentifier) Declaration {
+	switch declType {
+	case ConstDeclType:
+		for i := range r.Consts {
+			if r.Consts[i].Name == name {
+				return &r.Consts[i]
+			}
+		}
+	case BitsDeclType:
+		for i := range r.Bits {
+			if r.Bits[i].Name == name {
+				return &r.Bits[i]
+			}
+		}
+	case EnumDeclType:
+		for i := range r.Enums {
+			if r.Enums[i].Name == name {
+				return &r.Enums[i]
+			}
+		}
+	case ResourceDeclType:
+		for i := range r.Resources {
+			if r.Resources[i].Name == name {
+				return &r.Resources[i]
+			}
+		}
+	case ProtocolDeclType:
+		for i := range r.Protocols {
+			if r.Protocols[i].Name == name {
+				return &r.Protocols[i]
+			}
+		}
+	case ServiceDeclType:
+		for i := range r.Services {
+			if r.Services[i].Name == name {
+				return &r.Services[i]
+			}
+		}
+	case StructDeclType:
+		for i := range r.Structs {
+			if r.Structs[i].Name == name {
+				return &r.Structs[i]
+			}
+		}
+		for i := range r.ExternalStructs {
+			if r.ExternalStructs[i].Name == name {
+				return &r.ExternalStructs[i]
+			}
+		}
+	case TableDeclType:
+		for i := range r.Tables {
+			if r.Tables[i].Name == name {
+				return &r.Tables[i]
+			}
+		}
+	case UnionDeclType:
+		for i := range r.Unions {
+			if r.Unions[i].Name == name {
+				return &r.Unions[i]
+			}
+		}
+	case TypeAliasDeclType:
+		for i := range r.TypeAliases {
+			if r.TypeAliases[i].Name == name {
+				return &r.TypeAliases[i]
+			}
+		}
+	case NewTypeDeclType:
+		for i := range r.NewTypes {
+			if r.NewTypes[i].Name == name {
+				return &r.NewTypes[i]
+			}
+		}
+	}
+	return nil
+}
+
+// DeclResolver resolves declaration names to declarations. It is implemented
+// by both Root and Compilation, so that analyses can be run on either a single
+// library or on a library together with its dependencies.
+type DeclResolver interface {
+	LookupDecl(name EncodedCompoundIdentifier) (Declaration, bool)
+}
+
+var _ = []DeclResolver{(*Root)(nil), (*Compilation)(nil)}
+
 // DeclInfo returns information on the FIDL library's local and imported
-// declarations.
+// declarations. See DeclInfoMap.SortedNames to iterate over them in order.
 func (r *Root) DeclInfo() DeclInfoMap {
 	m := DeclInfoMap{}
 	r.ForEachDecl(func(decl Declaration) {
@@ -1567,8 +2079,18 @@ func (r *Root) DeclInfo() DeclInfoMap {
 
 type EncodedCompoundIdentifierSet map[EncodedCompoundIdentifier]struct{}
 
+// SortedNames returns the names in the set, sorted.
+func (s EncodedCompoundIdentifierSet) SortedNames() []EncodedCompoundIdentifier {
+	names := make([]EncodedCompoundIdentifier, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	return sortNames(names)
+}
+
 // GetMessageBodyTypeNames calculates set of ECIs that refer to types used as
-// message bodies by this library.
+// message bodies by this library. See EncodedCompoundIdentifierSet.SortedNames
+// to iterate over them in order.
 func (r *Root) GetMessageBodyTypeNames() EncodedCompoundIdentifierSet {
 	mbtn := EncodedCompoundIdentifierSet{}
 	for _, protocol := range r.Protocols {
@@ -1604,7 +2126,7 @@ func (r *Root) payloadTypeNames() EncodedCompoundIdentifierSet {
 				ptn[method.RequestPayload.Identifier] = struct{}{}
 			}
 			if method.ResponsePayload != nil {
-				if method.HasError || method.HasTransportError() {
+				if method.HasError || method.HasFrameworkError() {
 					ptn[method.ValueType.Identifier] = struct{}{}
 				} else {
 					ptn[method.ResponsePayload.Identifier] = struct{}{}
@@ -1694,28 +2216,84 @@ func deniedContexts(r *Root, language string) []scopedNamingContext {
 	return denied
 }
 
+// isDeclDenied reports whether a declaration should be omitted from the given
+// language bindings, either because it bears a matching @bindings_denylist or
+// because it is an anonymous layout nested within a denied declaration or
+// method.
+func isDeclDenied(denied []scopedNamingContext, decl Declaration, language string) bool {
+	if decl.GetAttributes().BindingsDenylistIncludes(language) {
+		return true
+	}
+	if layout, ok := decl.(LayoutDeclaration); ok {
+		scoped := scopedNamingContext{decl.GetName().LibraryName(), layout.GetNamingContext()}
+		return scoped.isDenied(denied)
+	}
+	return false
+}
+
+// memberAttributes returns the attributes of the member of decl with the given
+// name: a field of a layout, a method of a protocol, or a member of a service.
+func memberAttributes(decl Declaration, member Identifier) (Attributes, bool) {
+	switch v := decl.(type) {
+	case DeclarationWithMembers:
+		var attrs Attributes
+		found := false
+		v.ForEachMember(func(m MemberDeclaration) {
+			if !found && m.GetName() == member {
+				attrs, found = m.GetAttributes(), true
+			}
+		})
+		return attrs, found
+	case *Protocol:
+		for _, m := range v.Methods {
+			if m.Name == member {
+				return m.Attributes, true
+			}
+		}
+	case *Service:
+		for _, m := range v.Members {
+			if m.Name == member {
+				return m.Attributes, true
+			}
+		}
+	}
+	return Attributes{}, false
+}
+
+// IsMemberDenied reports whether the named member of decl should be omitted in
+// the given language bindings. This applies the same criteria as ForBindings:
+// a member is denied if it bears a matching @bindings_denylist itself, or if
+// it inherits the denial from its enclosing declaration, which happens when
+// that declaration is denylisted or is an anonymous layout declared within a
+// denylisted declaration or method. Backends that process members outside of
+// ForBindings should use this to remain consistent with it.
+//
+// Members that cannot be found in decl are never considered denied.
+func (r *Root) IsMemberDenied(decl Declaration, member Identifier, language string) bool {
+	if isDeclDenied(deniedContexts(r, language), decl, language) {
+		return true
+	}
+	attrs, ok := memberAttributes(decl, member)
+	return ok && attrs.BindingsDenylistIncludes(language)
+}
+
 // ForBindings filters out declarations that should be omitted in the given
 // language bindings based on BindingsDenylist attributes. It returns a new Root
-// and does not modify r.
+// and does not modify r. See Filter to also filter by API level and transport.
 func (r *Root) ForBindings(language string) Root {
 	denied := deniedContexts(r, language)
 	res := Root{
-		Name:        r.Name,
-		Experiments: r.Experiments,
-		Libraries:   r.Libraries,
-		Decls:       make(DeclMap, len(r.Decls)),
+		Name:              r.Name,
+		Experiments:       r.Experiments,
+		Libraries:         r.Libraries,
+		Decls:             make(DeclMap, len(r.Decls)),
+		HandleObjectTypes: r.HandleObjectTypes,
 	}
 
 	r.ForEachDecl(func(decl Declaration) {
-		if decl.GetAttributes().BindingsDenylistIncludes(language) {
+		if isDeclDenied(denied, decl, language) {
 			return
 		}
-		if layout, ok := decl.(LayoutDeclaration); ok {
-			scoped := scopedNamingContext{r.Name, layout.GetNamingContext()}
-			if scoped.isDenied(denied) {
-				return
-			}
-		}
 
 		switch v := decl.(type) {
 		case *Const:
@@ -1810,9 +2388,15 @@ func (r *Root) ForBindings(language string) Root {
 			}
 			res.Unions = append(res.Unions, newV)
 			res.Decls[v.Name] = r.Decls[v.Name]
+		case *Resource:
+			res.Resources = append(res.Resources, *v)
+			res.Decls[v.Name] = r.Decls[v.Name]
 		case *TypeAlias:
 			res.TypeAliases = append(res.TypeAliases, *v)
 			res.Decls[v.Name] = r.Decls[v.Name]
+		case *NewType:
+			res.NewTypes = append(res.NewTypes, *v)
+			res.Decls[v.Name] = r.Decls[v.Name]
 		}
 	})
 
@@ -15,6 +15,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
 )
 
 // toDocComment formats doc comments in a by adding a leading space and a
@@ -715,3 +716,457 @@ func compoundIdentifier(library []string, name, member string) fidlgen.CompoundI
 		Member:  fidlgen.Identifier(member),
 	}
 }
+
+func bindingsDenylist(languages string) fidlgen.Attributes {
+	return fidlgen.Attributes{
+		Attributes: []fidlgen.Attribute{
+			{
+				Name: "bindings_denylist",
+				Args: []fidlgen.AttributeArg{
+					{
+						Name:  "value",
+						Value: fidlgen.Constant{Value: languages},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestIsMemberDenied(t *testing.T) {
+	root := fidlgen.Root{
+		Name: "example",
+		Structs: []fidlgen.Struct{
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{
+						Decl:          fidlgen.Decl{Name: "example/Plain"},
+						NamingContext: []string{"Plain"},
+					},
+				},
+				Members: []fidlgen.StructMember{
+					{Name: "kept"},
+					{Name: "dropped", Attributes: bindingsDenylist("rust, go")},
+				},
+			},
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{
+						Decl: fidlgen.Decl{
+							Name:       "example/Denied",
+							Attributes: bindingsDenylist("rust"),
+						},
+						NamingContext: []string{"Denied"},
+					},
+				},
+				Members: []fidlgen.StructMember{{Name: "member"}},
+			},
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{
+						Decl:          fidlgen.Decl{Name: "example/DeniedInner"},
+						NamingContext: []string{"Denied", "inner"},
+					},
+				},
+				Members: []fidlgen.StructMember{{Name: "member"}},
+			},
+		},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl: fidlgen.Decl{Name: "example/Protocol"},
+				Methods: []fidlgen.Method{
+					{Name: "Kept"},
+					{Name: "Dropped", Attributes: bindingsDenylist("rust")},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		decl   fidlgen.Declaration
+		member fidlgen.Identifier
+		want   bool
+	}{
+		{&root.Structs[0], "kept", false},
+		{&root.Structs[0], "dropped", true},
+		{&root.Structs[0], "missing", false},
+		{&root.Structs[1], "member", true},
+		{&root.Structs[2], "member", true},
+		{&root.Protocols[0], "Kept", false},
+		{&root.Protocols[0], "Dropped", true},
+	}
+	for _, c := range cases {
+		if got := root.IsMemberDenied(c.decl, c.member, "rust"); got != c.want {
+			t.Errorf("IsMemberDenied(%s, %s, rust): got %t, want %t", c.decl.GetName(), c.member, got, c.want)
+		}
+		if got := root.IsMemberDenied(c.decl, c.member, "dart"); got {
+			t.Errorf("IsMemberDenied(%s, %s, dart): got true, want false", c.decl.GetName(), c.member)
+		}
+	}
+}
+
+func TestForBindingsResources(t *testing.T) {
+	kept := handleResource("example/Kept")
+	denied := handleResource("example/Denied")
+	denied.Attributes = bindingsDenylist("rust")
+	root := fidlgen.Root{
+		Name:      "example",
+		Resources: []fidlgen.Resource{kept, denied},
+		Decls: fidlgen.DeclMap{
+			"example/Kept":   fidlgen.ResourceDeclType,
+			"example/Denied": fidlgen.ResourceDeclType,
+		},
+		DeclOrder: []fidlgen.EncodedCompoundIdentifier{"example/Kept", "example/Denied"},
+	}
+
+	for _, tc := range []struct {
+		language string
+		expected []fidlgen.EncodedCompoundIdentifier
+	}{
+		{"rust", []fidlgen.EncodedCompoundIdentifier{"example/Kept"}},
+		{"go", []fidlgen.EncodedCompoundIdentifier{"example/Kept", "example/Denied"}},
+	} {
+		res := root.ForBindings(tc.language)
+		var names []fidlgen.EncodedCompoundIdentifier
+		for _, r := range res.Resources {
+			names = append(names, r.Name)
+		}
+		if diff := cmp.Diff(tc.expected, names); diff != "" {
+			t.Errorf("%s: unexpected resources (-want +got):\n%s", tc.language, diff)
+		}
+		if diff := cmp.Diff(tc.expected, res.DeclOrder); diff != "" {
+			t.Errorf("%s: unexpected decl order (-want +got):\n%s", tc.language, diff)
+		}
+	}
+}
+
+func TestAttributeOrdering(t *testing.T) {
+	attrs := fidlgen.Attributes{
+		Attributes: []fidlgen.Attribute{
+			{
+				Name: "transport",
+				Args: []fidlgen.AttributeArg{{Name: "value"}},
+			},
+			{
+				Name: "Doc",
+				Args: []fidlgen.AttributeArg{{Name: "value"}},
+			},
+			{
+				Name: "available",
+				Args: []fidlgen.AttributeArg{{Name: "removed"}, {Name: "added"}},
+			},
+			{
+				Name: "doc",
+			},
+		},
+	}
+
+	names := func(attrs []fidlgen.Attribute) []fidlgen.Identifier {
+		var names []fidlgen.Identifier
+		for _, a := range attrs {
+			names = append(names, a.Name)
+		}
+		return names
+	}
+	if diff := cmp.Diff(
+		[]fidlgen.Identifier{"transport", "Doc", "available", "doc"},
+		names(attrs.Ordered(fidlgen.SourceAttributeOrder)),
+	); diff != "" {
+		t.Errorf("source order: unexpected diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(
+		[]fidlgen.Identifier{"available", "Doc", "doc", "transport"},
+		names(attrs.Ordered(fidlgen.CanonicalAttributeOrder)),
+	); diff != "" {
+		t.Errorf("canonical order: unexpected diff (-want +got):\n%s", diff)
+	}
+
+	available := attrs.Attributes[2]
+	var argNames []fidlgen.Identifier
+	for _, arg := range available.OrderedArgs(fidlgen.CanonicalAttributeOrder) {
+		argNames = append(argNames, arg.Name)
+	}
+	if diff := cmp.Diff([]fidlgen.Identifier{"added", "removed"}, argNames); diff != "" {
+		t.Errorf("canonical arg order: unexpected diff (-want +got):\n%s", diff)
+	}
+	if available.Args[0].Name != "removed" {
+		t.Errorf("OrderedArgs modified the original argument list")
+	}
+
+	if diff := cmp.Diff([]fidlgen.Identifier{"doc"}, attrs.DuplicateAttributes()); diff != "" {
+		t.Errorf("DuplicateAttributes: unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestConstantAccessors(t *testing.T) {
+	constant := func(value string) fidlgen.Constant {
+		return fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: value}
+	}
+
+	for _, tc := range []struct {
+		value    string
+		subtype  fidlgen.PrimitiveSubtype
+		expected uint64
+	}{
+		{"255", fidlgen.Uint8, 255},
+		{"0xff", fidlgen.Uint8, 255},
+		{"0b101", fidlgen.Uint8, 5},
+		{"010", fidlgen.Uint8, 10},
+		{"18446744073709551615", fidlgen.Uint64, math.MaxUint64},
+		{"127", fidlgen.Int8, 127},
+	} {
+		if u, err := constant(tc.value).AsUint64(tc.subtype); err != nil || u != tc.expected {
+			t.Errorf("AsUint64(%s, %s): got (%d, %v), want %d", tc.value, tc.subtype, u, err, tc.expected)
+		}
+	}
+	for _, tc := range []struct {
+		value   string
+		subtype fidlgen.PrimitiveSubtype
+	}{
+		{"256", fidlgen.Uint8},
+		{"-1", fidlgen.Uint32},
+		{"128", fidlgen.Int8},
+		{"1", fidlgen.Float32},
+		{"one", fidlgen.Uint8},
+		{"1_000", fidlgen.Uint32},
+		{"0o17", fidlgen.Uint32},
+		{"0x", fidlgen.Uint32},
+	} {
+		if _, err := constant(tc.value).AsUint64(tc.subtype); err == nil {
+			t.Errorf("AsUint64(%s, %s): expected an error", tc.value, tc.subtype)
+		}
+	}
+
+	for _, tc := range []struct {
+		value    string
+		subtype  fidlgen.PrimitiveSubtype
+		expected int64
+	}{
+		{"-128", fidlgen.Int8, -128},
+		{"-0x80", fidlgen.Int8, -128},
+		{"-010", fidlgen.Int8, -10},
+		{"0b111", fidlgen.Int8, 7},
+		{"65535", fidlgen.Uint16, 65535},
+		{"-9223372036854775808", fidlgen.Int64, math.MinInt64},
+	} {
+		if i, err := constant(tc.value).AsInt64(tc.subtype); err != nil || i != tc.expected {
+			t.Errorf("AsInt64(%s, %s): got (%d, %v), want %d", tc.value, tc.subtype, i, err, tc.expected)
+		}
+	}
+	for _, tc := range []struct {
+		value   string
+		subtype fidlgen.PrimitiveSubtype
+	}{
+		{"-129", fidlgen.Int8},
+		{"0x80", fidlgen.Int8},
+		{"-1_0", fidlgen.Int8},
+		{"+1", fidlgen.Int8},
+		{"18446744073709551615", fidlgen.Uint64},
+		{"true", fidlgen.Bool},
+	} {
+		if _, err := constant(tc.value).AsInt64(tc.subtype); err == nil {
+			t.Errorf("AsInt64(%s, %s): expected an error", tc.value, tc.subtype)
+		}
+	}
+
+	if f, err := constant("1.5").AsFloat(fidlgen.Float32); err != nil || f != 1.5 {
+		t.Errorf("AsFloat(1.5): got (%f, %v)", f, err)
+	}
+	if _, err := constant("1e39").AsFloat(fidlgen.Float32); err == nil {
+		t.Errorf("AsFloat(1e39, float32): expected an error")
+	}
+	if _, err := constant("1").AsFloat(fidlgen.Int32); err == nil {
+		t.Errorf("AsFloat(1, int32): expected an error")
+	}
+
+	if b, err := constant("true").AsBool(); err != nil || !b {
+		t.Errorf("AsBool(true): got (%t, %v)", b, err)
+	}
+	if _, err := constant("1").AsBool(); err == nil {
+		t.Errorf("AsBool(1): expected an error")
+	}
+
+	if s := constant(`a "quoted" string`).AsString(); s != `a "quoted" string` {
+		t.Errorf("AsString: got %q", s)
+	}
+}
+
+func TestDeprecation(t *testing.T) {
+	attr := func(name string, args ...fidlgen.AttributeArg) fidlgen.Attribute {
+		return fidlgen.Attribute{Name: fidlgen.Identifier(name), Args: args}
+	}
+	arg := func(name, value string) fidlgen.AttributeArg {
+		return fidlgen.AttributeArg{
+			Name:  fidlgen.Identifier(name),
+			Value: fidlgen.Constant{Kind: fidlgen.LiteralConstant, Value: value},
+		}
+	}
+
+	for _, tc := range []struct {
+		name       string
+		attrs      []fidlgen.Attribute
+		deprecated bool
+		note       string
+	}{
+		{"none", nil, false, ""},
+		{"available without deprecated", []fidlgen.Attribute{attr("available", arg("added", "1"), arg("note", "unused"))}, false, ""},
+		{"available", []fidlgen.Attribute{attr("available", arg("deprecated", "2"), arg("note", "use Bar"))}, true, "use Bar"},
+		{"deprecated", []fidlgen.Attribute{attr("deprecated", arg("value", "use Baz"))}, true, "use Baz"},
+		{"deprecated without note", []fidlgen.Attribute{attr("Deprecated")}, true, ""},
+		{"both", []fidlgen.Attribute{
+			attr("available", arg("deprecated", "2"), arg("note", "use Bar")),
+			attr("deprecated", arg("value", "use Baz")),
+		}, true, "use Baz"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Members embed Attributes just like declarations do.
+			member := fidlgen.StructMember{Attributes: fidlgen.Attributes{Attributes: tc.attrs}}
+			if member.IsDeprecated() != tc.deprecated {
+				t.Errorf("IsDeprecated: got %t, want %t", member.IsDeprecated(), tc.deprecated)
+			}
+			if member.DeprecationNote() != tc.note {
+				t.Errorf("DeprecationNote: got %q, want %q", member.DeprecationNote(), tc.note)
+			}
+		})
+	}
+}
+
+func TestSortedNames(t *testing.T) {
+	expected := []fidlgen.EncodedCompoundIdentifier{"a/A", "a/B", "b/A"}
+	declMap := fidlgen.DeclMap{
+		"b/A": fidlgen.StructDeclType,
+		"a/B": fidlgen.EnumDeclType,
+		"a/A": fidlgen.ProtocolDeclType,
+	}
+	declInfoMap := fidlgen.DeclInfoMap{
+		"a/B": {Type: fidlgen.EnumDeclType},
+		"b/A": {Type: fidlgen.StructDeclType},
+		"a/A": {Type: fidlgen.ProtocolDeclType},
+	}
+	set := fidlgen.EncodedCompoundIdentifierSet{"a/A": {}, "b/A": {}, "a/B": {}}
+	for i := 0; i < 10; i++ {
+		if diff := cmp.Diff(expected, declMap.SortedNames()); diff != "" {
+			t.Fatalf("DeclMap: unexpected diff (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(expected, declInfoMap.SortedNames()); diff != "" {
+			t.Fatalf("DeclInfoMap: unexpected diff (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(expected, set.SortedNames()); diff != "" {
+			t.Fatalf("EncodedCompoundIdentifierSet: unexpected diff (-want +got):\n%s", diff)
+		}
+	}
+	if names := (fidlgen.DeclMap{}).SortedNames(); len(names) != 0 {
+		t.Errorf("expected no names, got %v", names)
+	}
+}
+
+func TestMethodClassification(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	p := root.Protocols[0]
+	names := func(methods []fidlgen.Method) []fidlgen.Identifier {
+		var names []fidlgen.Identifier
+		for _, m := range methods {
+			names = append(names, m.Name)
+		}
+		return names
+	}
+	if diff := cmp.Diff([]fidlgen.Identifier{"OnEvent"}, names(p.Events())); diff != "" {
+		t.Errorf("events (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]fidlgen.Identifier{"OneWay"}, names(p.OneWayMethods())); diff != "" {
+		t.Errorf("one-way methods (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]fidlgen.Identifier{"TwoWay", "FlexibleTwoWay"}, names(p.TwoWayMethods())); diff != "" {
+		t.Errorf("two-way methods (-want +got):\n%s", diff)
+	}
+	for _, m := range p.Methods {
+		n := 0
+		for _, is := range []bool{m.IsEvent(), m.IsOneWay(), m.IsTwoWay()} {
+			if is {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Errorf("%s is in %d classes", m.Name, n)
+		}
+	}
+}
+
+func TestFrameworkErrSpellings(t *testing.T) {
+	for _, subtype := range []string{"framework_error", "transport_error"} {
+		var typ fidlgen.Type
+		if err := json.Unmarshal([]byte(`{"kind": "internal", "subtype": "`+subtype+`", "nullable": false, "type_shape_v2": {}}`), &typ); err != nil {
+			t.Fatal(err)
+		}
+		if typ.InternalSubtype != fidlgen.FrameworkErr {
+			t.Errorf("%s: got internal subtype %s, want %s", subtype, typ.InternalSubtype, fidlgen.FrameworkErr)
+		}
+	}
+
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	for _, m := range root.Protocols[0].Methods {
+		if m.HasFrameworkError() != m.HasTransportError() {
+			t.Errorf("%s: HasFrameworkError and HasTransportError disagree", m.Name)
+		}
+		if got, want := m.HasFrameworkError(), m.Name == "FlexibleTwoWay"; got != want {
+			t.Errorf("%s: got HasFrameworkError() = %t, want %t", m.Name, got, want)
+		}
+	}
+	for _, u := range root.Unions {
+		for _, m := range u.Members {
+			if m.Type.Kind == fidlgen.InternalType && m.Type.InternalSubtype != fidlgen.FrameworkErr {
+				t.Errorf("%s.%s: got internal subtype %s", u.Name, m.Name, m.Type.InternalSubtype)
+			}
+		}
+	}
+}
+
+func TestRootLookupDecl(t *testing.T) {
+	root := fidlgen.Root{
+		Name: "example",
+		Consts: []fidlgen.Const{
+			{Decl: fidlgen.Decl{Name: "example/MAX"}},
+		},
+		Enums: []fidlgen.Enum{
+			{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Color"}}},
+		},
+		Structs: []fidlgen.Struct{
+			{ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+				LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Point"}},
+			}},
+		},
+		ExternalStructs: []fidlgen.Struct{
+			{ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+				LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "dep/Payload"}},
+			}},
+		},
+		// example/MAX is deliberately missing, as in hand-built IR.
+		Decls: fidlgen.DeclMap{
+			"example/Color": fidlgen.EnumDeclType,
+			"example/Point": fidlgen.StructDeclType,
+		},
+	}
+
+	for _, tc := range []struct {
+		name     fidlgen.EncodedCompoundIdentifier
+		expected fidlgen.Declaration
+	}{
+		{name: "example/Point", expected: &root.Structs[0]},
+		{name: "example/Color.RED", expected: &root.Enums[0]},
+		{name: "dep/Payload", expected: &root.ExternalStructs[0]},
+		{name: "example/MAX", expected: &root.Consts[0]},
+	} {
+		decl, ok := root.LookupDecl(tc.name)
+		if !ok {
+			t.Errorf("%s: not found", tc.name)
+			continue
+		}
+		if decl != tc.expected {
+			t.Errorf("%s: got %s, expected a pointer into the Root", tc.name, decl.GetName())
+		}
+	}
+
+	if decl, ok := root.LookupDecl("example/Missing"); ok {
+		t.Errorf("example/Missing: unexpectedly found %s", decl.GetName())
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+	"math"
+)
+
+// fidlc computes the TypeShape of every type and layout it emits, but backends
+// synthesizing types of their own (e.g. wrapper structs, or result unions for
+// legacy IR) need to compute them too. TypeShapeCalculator follows fidlc's
+// algorithm, so that the shapes of synthesized types are indistinguishable
+// from those fidlc would have emitted.
+
+// UnboundedTypeShapeSize is the value fidlc reports as the max_out_of_line,
+// max_handles or depth of types for which they are unbounded.
+const UnboundedTypeShapeSize = math.MaxUint32
+
+func clampedAdd(a, b int) int {
+	if a+b > UnboundedTypeShapeSize {
+		return UnboundedTypeShapeSize
+	}
+	return a + b
+}
+
+func clampedMul(a, b int) int {
+	if a != 0 && b > UnboundedTypeShapeSize/a {
+		return UnboundedTypeShapeSize
+	}
+	return a * b
+}
+
+func alignTo(size, alignment int) int {
+	return (size + alignment - 1) &^ (alignment - 1)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// TypeShapeCalculator computes type shapes for one wire format.
+type TypeShapeCalculator struct {
+	// WireFormat is the wire format of the computed shapes. Wire formats
+	// only differ in the size of envelopes.
+	WireFormat WireFormatVersion
+	// Decls resolves the declarations referenced by identifier types. It is
+	// only needed to compute shapes from types, i.e. by Type and by the
+	// methods computing the shapes of declarations.
+	Decls DeclResolver
+}
+
+var primitiveTypeSizes = map[PrimitiveSubtype]int{
+	Bool:    1,
+	Int8:    1,
+	Int16:   2,
+	Int32:   4,
+	Int64:   8,
+	Uint8:   1,
+	Uint16:  2,
+	Uint32:  4,
+	Uint64:  8,
+	Float32: 4,
+	Float64: 8,
+}
+
+// PrimitiveTypeShape returns the shape of a primitive type, which is the same
+// in all wire formats.
+func PrimitiveTypeShape(subtype PrimitiveSubtype) TypeShape {
+	size, ok := primitiveTypeSizes[subtype]
+	if !ok {
+		panic(fmt.Sprintf("unknown primitive subtype: %s", subtype))
+	}
+	return TypeShape{InlineSize: size, Alignment: size}
+}
+
+// HandleTypeShape is the shape of handles and of protocol endpoints, which is
+// the same in all wire formats.
+var HandleTypeShape = TypeShape{InlineSize: 4, Alignment: 4, MaxHandles: 1}
+
+// outOfLine returns the out-of-line contribution of an object of the given
+// shape: its inline size, padded to 8 bytes, and its own out-of-line objects.
+func outOfLine(s TypeShape) int {
+	return clampedAdd(alignTo(s.InlineSize, 8), s.MaxOutOfLine)
+}
+
+// envelopeOutOfLine returns the out-of-line contribution of a value of the
+// given shape stored in an envelope: none if the value is inlined in the
+// envelope, as per IsInlinedInEnvelope, and as for outOfLine otherwise.
+func (c TypeShapeCalculator) envelopeOutOfLine(s TypeShape) int {
+	if IsInlinedInEnvelope(s, c.WireFormat) {
+		return 0
+	}
+	return outOfLine(s)
+}
+
+// Vector returns the shape of a vector of at most max elements of the given
+// shape, or of any number of them if max is nil.
+func (c TypeShapeCalculator) Vector(elem TypeShape, max *int) TypeShape {
+	maxOutOfLine, maxHandles := UnboundedTypeShapeSize, 0
+	if elem.MaxHandles != 0 {
+		maxHandles = UnboundedTypeShapeSize
+	}
+	if max != nil {
+		maxOutOfLine = clampedAdd(alignTo(clampedMul(*max, elem.InlineSize), 8), clampedMul(*max, elem.MaxOutOfLine))
+		maxHandles = clampedMul(*max, elem.MaxHandles)
+	}
+	return TypeShape{
+		InlineSize:          16,
+		Alignment:           8,
+		Depth:               clampedAdd(elem.Depth, 1),
+		MaxHandles:          maxHandles,
+		MaxOutOfLine:        maxOutOfLine,
+		HasPadding:          elem.HasPadding || elem.InlineSize%8 != 0,
+		HasEnvelope:         elem.HasEnvelope,
+		HasFlexibleEnvelope: elem.HasFlexibleEnvelope,
+	}
+}
+
+// String returns the shape of a string of at most max bytes, or of any number
+// of them if max is nil.
+func (c TypeShapeCalculator) String(max *int) TypeShape {
+	return c.Vector(PrimitiveTypeShape(Uint8), max)
+}
+
+// Array returns the shape of an array of count elements of the given shape.
+func (c TypeShapeCalculator) Array(elem TypeShape, count int) TypeShape {
+	return TypeShape{
+		InlineSize:          clampedMul(count, elem.InlineSize),
+		Alignment:           elem.Alignment,
+		Depth:               elem.Depth,
+		MaxHandles:          clampedMul(count, elem.MaxHandles),
+		MaxOutOfLine:        clampedMul(count, elem.MaxOutOfLine),
+		HasPadding:          elem.HasPadding,
+		HasEnvelope:         elem.HasEnvelope,
+		HasFlexibleEnvelope: elem.HasFlexibleEnvelope,
+	}
+}
+
+// Box returns the shape of an optional struct of the given shape, i.e.
+// `box<T>`, which is stored out-of-line.
+func (c TypeShapeCalculator) Box(s TypeShape) TypeShape {
+	return TypeShape{
+		InlineSize:          8,
+		Alignment:           8,
+		Depth:               clampedAdd(s.Depth, 1),
+		MaxHandles:          s.MaxHandles,
+		MaxOutOfLine:        outOfLine(s),
+		HasPadding:          s.HasPadding || s.InlineSize%8 != 0,
+		HasEnvelope:         s.HasEnvelope,
+		HasFlexibleEnvelope: s.HasFlexibleEnvelope,
+	}
+}
+
+// Struct returns the shape of a struct with members of the given shapes, in
+// declaration order, along with the shapes of its fields.
+func (c TypeShapeCalculator) Struct(members []TypeShape) (TypeShape, []FieldShape) {
+	shape := TypeShape{Alignment: 1}
+	fields := make([]FieldShape, len(members))
+	offset := 0
+	for i, m := range members {
+		offset = alignTo(offset, m.Alignment)
+		fields[i].Offset = offset
+		offset += m.InlineSize
+		shape.Alignment = maxInt(shape.Alignment, m.Alignment)
+		shape.Depth = maxInt(shape.Depth, m.Depth)
+		shape.MaxHandles = clampedAdd(shape.MaxHandles, m.MaxHandles)
+		shape.MaxOutOfLine = clampedAdd(shape.MaxOutOfLine, m.MaxOutOfLine)
+		shape.HasPadding = shape.HasPadding || m.HasPadding
+		shape.HasEnvelope = shape.HasEnvelope || m.HasEnvelope
+		shape.HasFlexibleEnvelope = shape.HasFlexibleEnvelope || m.HasFlexibleEnvelope
+	}
+	// The empty struct is a single zero byte.
+	shape.InlineSize = maxInt(alignTo(offset, shape.Alignment), 1)
+	for i := range fields {
+		end := shape.InlineSize
+		if i+1 < len(fields) {
+			end = fields[i+1].Offset
+		}
+		fields[i].Padding = end - fields[i].Offset - members[i].InlineSize
+		shape.HasPadding = shape.HasPadding || fields[i].Padding != 0
+	}
+	return shape, fields
+}
+
+// Union returns the shape of a union with non-reserved members of the given
+// shapes. The result unions fidlc synthesizes for flexible methods are
+// flexible in that sense, even though they are declared strict.
+func (c TypeShapeCalculator) Union(flexible bool, members []TypeShape) TypeShape {
+	shape := TypeShape{
+		InlineSize:          8 + c.WireFormat.envelopeSize(),
+		Alignment:           8,
+		Depth:               1,
+		HasEnvelope:         true,
+		HasFlexibleEnvelope: flexible,
+	}
+	for _, m := range members {
+		shape.Depth = maxInt(shape.Depth, clampedAdd(m.Depth, 1))
+		shape.MaxHandles = maxInt(shape.MaxHandles, m.MaxHandles)
+		shape.MaxOutOfLine = maxInt(shape.MaxOutOfLine, c.envelopeOutOfLine(m))
+		shape.HasPadding = shape.HasPadding || m.HasPadding || m.InlineSize%8 != 0
+		shape.HasFlexibleEnvelope = shape.HasFlexibleEnvelope || m.HasFlexibleEnvelope
+	}
+	return shape
+}
+
+// Table returns the shape of a table with non-reserved members of the given
+// shapes, the highest ordinal of which is maxOrdinal.
+func (c TypeShapeCalculator) Table(maxOrdinal int, members []TypeShape) TypeShape {
+	shape := TypeShape{
+		InlineSize:          16,
+		Alignment:           8,
+		Depth:               2,
+		MaxOutOfLine:        clampedMul(maxOrdinal, c.WireFormat.envelopeSize()),
+		HasEnvelope:         true,
+		HasFlexibleEnvelope: true,
+	}
+	for _, m := range members {
+		shape.Depth = maxInt(shape.Depth, clampedAdd(m.Depth, 2))
+		shape.MaxHandles = clampedAdd(shape.MaxHandles, m.MaxHandles)
+		shape.MaxOutOfLine = clampedAdd(shape.MaxOutOfLine, c.envelopeOutOfLine(m))
+		shape.HasPadding = shape.HasPadding || m.HasPadding || m.InlineSize%8 != 0
+	}
+	return shape
+}
+
+// Type computes the shape of a type from its structure alone, disregarding
+// the shapes it may already carry. The shapes of the
+// structs, tables and unions it references are taken from their declarations,
+// looked up through Decls.
+func (c TypeShapeCalculator) Type(typ Type) (TypeShape, error) {
+	switch typ.Kind {
+	case PrimitiveType:
+		if _, ok := primitiveTypeSizes[typ.PrimitiveSubtype]; !ok {
+			return TypeShape{}, fmt.Errorf("unknown primitive subtype: %s", typ.PrimitiveSubtype)
+		}
+		return PrimitiveTypeShape(typ.PrimitiveSubtype), nil
+	case StringType:
+		return c.String(typ.ElementCount), nil
+	case VectorType, ArrayType:
+		if typ.ElementType == nil {
+			return TypeShape{}, fmt.Errorf("%s type without an element type", typ.Kind)
+		}
+		elem, err := c.Type(*typ.ElementType)
+		if err != nil {
+			return TypeShape{}, err
+		}
+		if typ.Kind == VectorType {
+			return c.Vector(elem, typ.ElementCount), nil
+		}
+		if typ.ElementCount == nil {
+			return TypeShape{}, fmt.Errorf("array type without an element count")
+		}
+		return c.Array(elem, *typ.ElementCount), nil
+	case HandleType, RequestType:
+		return HandleTypeShape, nil
+	case InternalType:
+		switch typ.InternalSubtype {
+		case FrameworkErr:
+			return PrimitiveTypeShape(Int32), nil
+		}
+		return TypeShape{}, fmt.Errorf("unknown internal subtype: %s", typ.InternalSubtype)
+	case IdentifierType:
+		if typ.ProtocolTransport != "" {
+			return HandleTypeShape, nil
+		}
+		if c.Decls == nil {
+			return TypeShape{}, fmt.Errorf("cannot resolve %s without declarations", typ.Identifier)
+		}
+		decl, ok := c.Decls.LookupDecl(typ.Identifier)
+		if !ok {
+			return TypeShape{}, fmt.Errorf("declaration %s not found", typ.Identifier)
+		}
+		switch decl := decl.(type) {
+		case *Struct:
+			shape := decl.Shape(c.WireFormat)
+			if typ.Nullable {
+				return c.Box(shape), nil
+			}
+			return shape, nil
+		case *Union:
+			return decl.Shape(c.WireFormat), nil
+		case *Table:
+			return decl.Shape(c.WireFormat), nil
+		case *Enum:
+			return PrimitiveTypeShape(decl.Type), nil
+		case *Bits:
+			return c.Type(decl.Type)
+		case *NewType:
+			return c.Type(decl.Type)
+		case *Protocol:
+			return HandleTypeShape, nil
+		}
+		return TypeShape{}, fmt.Errorf("%s does not declare a type", typ.Identifier)
+	}
+	return TypeShape{}, fmt.Errorf("unknown type kind: %s", typ.Kind)
+}
+
+// memberShapes computes the shapes of the given member types.
+func (c TypeShapeCalculator) memberShapes(types []Type) ([]TypeShape, error) {
+	var shapes []TypeShape
+	for _, typ := range types {
+		shape, err := c.Type(typ)
+		if err != nil {
+			return nil, err
+		}
+		shapes = append(shapes, shape)
+	}
+	return shapes, nil
+}
+
+// StructDecl computes the shape of a struct declaration from the types of its
+// members, along with the shapes of its fields.
+func (c TypeShapeCalculator) StructDecl(s *Struct) (TypeShape, []FieldShape, error) {
+	var types []Type
+	for _, m := range s.Members {
+		types = append(types, m.Type)
+	}
+	shapes, err := c.memberShapes(types)
+	if err != nil {
+		return TypeShape{}, nil, fmt.Errorf("%s: %w", s.Name, err)
+	}
+	shape, fields := c.Struct(shapes)
+	return shape, fields, nil
+}
+
+// UnionDecl computes the shape of a union declaration from the types of its
+// members. Unions carrying a framework_err, i.e. the result unions of flexible
+// methods, have a flexible envelope even though they are strict.
+func (c TypeShapeCalculator) UnionDecl(u *Union) (TypeShape, error) {
+	flexible := u.IsFlexible()
+	var types []Type
+	for _, m := range u.Members {
+		if !m.Reserved {
+			types = append(types, m.Type)
+			flexible = flexible || (m.Type.Kind == InternalType && m.Type.InternalSubtype == FrameworkErr)
+		}
+	}
+	shapes, err := c.memberShapes(types)
+	if err != nil {
+		return TypeShape{}, fmt.Errorf("%s: %w", u.Name, err)
+	}
+	return c.Union(flexible, shapes), nil
+}
+
+// TableDecl computes the shape of a table declaration from the types of its
+// members.
+func (c TypeShapeCalculator) TableDecl(t *Table) (TypeShape, error) {
+	maxOrdinal := 0
+	var types []Type
+	for _, m := range t.Members {
+		if !m.Reserved {
+			types = append(types, m.Type)
+			maxOrdinal = maxInt(maxOrdinal, m.Ordinal)
+		}
+	}
+	shapes, err := c.memberShapes(types)
+	if err != nil {
+		return TypeShape{}, fmt.Errorf("%s: %w", t.Name, err)
+	}
+	return c.Table(maxOrdinal, shapes), nil
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+// checkTypeShapes checks that the shapes computed for the layouts of a library
+// match those fidlc emitted in its IR.
+func checkTypeShapes(t *testing.T, root fidlgen.Root) {
+	t.Helper()
+	for _, wf := range fidlgen.WireFormatVersions {
+		c := fidlgen.TypeShapeCalculator{WireFormat: wf, Decls: &root}
+		for _, s := range root.Structs {
+			shape, fields, err := c.StructDecl(&s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(s.Shape(wf), shape); diff != "" {
+				t.Errorf("%s (%s): unexpected diff (-want +got):\n%s", s.Name, wf, diff)
+			}
+			for i, m := range s.Members {
+				if diff := cmp.Diff(m.FieldShape(wf), fields[i]); diff != "" {
+					t.Errorf("%s.%s (%s): unexpected diff (-want +got):\n%s", s.Name, m.Name, wf, diff)
+				}
+			}
+		}
+		for _, u := range root.Unions {
+			shape, err := c.UnionDecl(&u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(u.Shape(wf), shape); diff != "" {
+				t.Errorf("%s (%s): unexpected diff (-want +got):\n%s", u.Name, wf, diff)
+			}
+		}
+		for _, tbl := range root.Tables {
+			shape, err := c.TableDecl(&tbl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tbl.Shape(wf), shape); diff != "" {
+				t.Errorf("%s (%s): unexpected diff (-want +got):\n%s", tbl.Name, wf, diff)
+			}
+		}
+	}
+}
+
+func TestTypeShapeCalculatorMatchesFixtures(t *testing.T) {
+	for _, name := range irtestdata.Names() {
+		checkTypeShapes(t, irtestdata.Load(t, name))
+	}
+}
+
+// Envelopes are where the wire formats differ most, so the library has small
+// members, which the V2 wire format inlines in their envelopes, next to large
+// ones, which it stores out-of-line.
+func TestTypeShapeCalculatorMatchesFidlc(t *testing.T) {
+	root := fidlgentest.EndToEndTest{T: t}.Single(`
+library example;
+
+type Small = struct {
+	a uint8;
+	b uint16;
+};
+
+type Large = struct {
+	a uint64;
+	b uint32;
+};
+
+type AllInlined = strict union {
+	1: a uint8;
+	2: b uint32;
+	3: c Small;
+};
+
+type SomeOutOfLine = flexible union {
+	1: a uint32;
+	2: b Large;
+	3: c string:10;
+};
+
+type Tbl = table {
+	1: a uint32;
+	2: reserved;
+	3: b Small;
+	4: c Large;
+	5: d vector<uint16>:3;
+	6: e AllInlined;
+};
+`)
+	checkTypeShapes(t, root)
+}
+
+func TestTypeShapeCalculatorTable(t *testing.T) {
+	count := 4
+	table := fidlgen.Table{
+		Members: []fidlgen.TableMember{
+			{Ordinal: 1, Type: fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}},
+			{Ordinal: 2, Reserved: true},
+			{Ordinal: 3, Type: fidlgen.Type{
+				Kind:         fidlgen.VectorType,
+				ElementType:  &fidlgen.Type{Kind: fidlgen.HandleType},
+				ElementCount: &count,
+			}},
+		},
+	}
+	for _, ex := range []struct {
+		wireFormat   fidlgen.WireFormatVersion
+		maxOutOfLine int
+	}{
+		// 3 envelopes, the uint32, then the vector and its 4 handles.
+		{fidlgen.WireFormatVersionV1, 3*16 + 8 + 16 + 16},
+		// 3 envelopes, the first of which holds the uint32 itself, then the
+		// vector and its 4 handles.
+		{fidlgen.WireFormatVersionV2, 3*8 + 16 + 16},
+	} {
+		shape, err := fidlgen.TypeShapeCalculator{WireFormat: ex.wireFormat}.TableDecl(&table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := fidlgen.TypeShape{
+			InlineSize:          16,
+			Alignment:           8,
+			Depth:               3,
+			MaxHandles:          4,
+			MaxOutOfLine:        ex.maxOutOfLine,
+			HasPadding:          true,
+			HasEnvelope:         true,
+			HasFlexibleEnvelope: true,
+		}
+		if diff := cmp.Diff(expected, shape); diff != "" {
+			t.Errorf("%s: unexpected diff (-want +got):\n%s", ex.wireFormat, diff)
+		}
+	}
+}
+
+func TestTypeShapeCalculatorType(t *testing.T) {
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{structDecl("example/S")},
+		Enums: []fidlgen.Enum{
+			{LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/E"}}, Type: fidlgen.Uint16},
+		},
+	}
+	root.Structs[0].TypeShapeV2 = fidlgen.TypeShape{InlineSize: 4, Alignment: 4}
+	c := fidlgen.TypeShapeCalculator{WireFormat: fidlgen.WireFormatVersionV2, Decls: &root}
+
+	for _, ex := range []struct {
+		typ      fidlgen.Type
+		expected fidlgen.TypeShape
+	}{
+		{
+			typ:      *identifierType("example/E"),
+			expected: fidlgen.TypeShape{InlineSize: 2, Alignment: 2},
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/S", Nullable: true},
+			expected: fidlgen.TypeShape{InlineSize: 8, Alignment: 8, Depth: 1, MaxOutOfLine: 8, HasPadding: true},
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.ArrayType, ElementType: identifierType("example/S"), ElementCount: new(int)},
+			expected: fidlgen.TypeShape{Alignment: 4},
+		},
+		{
+			typ:      fidlgen.Type{Kind: fidlgen.StringType},
+			expected: fidlgen.TypeShape{InlineSize: 16, Alignment: 8, Depth: 1, MaxOutOfLine: fidlgen.UnboundedTypeShapeSize, HasPadding: true},
+		},
+	} {
+		shape, err := c.Type(ex.typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(ex.expected, shape); diff != "" {
+			t.Errorf("%s: unexpected diff (-want +got):\n%s", ex.typ, diff)
+		}
+	}
+
+	if _, err := c.Type(*identifierType("example/Missing")); err == nil {
+		t.Errorf("expected an error for a missing declaration")
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// Bindings dispatch on the ordinal of a union's envelope, which is often best
+// done with a jump table indexed by ordinal. Reserved members and removed ones
+// leave gaps in the ordinals: the helpers below describe them.
+
+// MaxOrdinal returns the largest ordinal of the union's members, excluding
+// reserved ones, or 0 if there are none.
+func (u *Union) MaxOrdinal() int {
+	max := 0
+	for _, m := range u.Members {
+		if !m.Reserved && m.Ordinal > max {
+			max = m.Ordinal
+		}
+	}
+	return max
+}
+
+// IsDense returns whether the ordinals of the union's members, excluding
+// reserved ones, are exactly 1 through the number of such members, i.e. there
+// are no gaps.
+func (u *Union) IsDense() bool {
+	n := 0
+	for _, m := range u.Members {
+		if !m.Reserved {
+			n++
+		}
+	}
+	return u.MaxOrdinal() == n
+}
+
+// MemberIndicesByOrdinal returns a jump table for the union: the element at
+// ordinal-1 is the index in Members of the member with that ordinal, or -1 if
+// there is no such member or it is reserved. The table has MaxOrdinal
+// elements. Ordinals are expected to be valid, as per ValidateOrdinals.
+func (u *Union) MemberIndicesByOrdinal() []int {
+	indices := make([]int, u.MaxOrdinal())
+	for i := range indices {
+		indices[i] = -1
+	}
+	for i, m := range u.Members {
+		if !m.Reserved {
+			indices[m.Ordinal-1] = i
+		}
+	}
+	return indices
+}
+
+// ValidateOrdinals checks that the ordinals of the union's members, including
+// reserved ones, are positive and unique.
+func (u *Union) ValidateOrdinals() error {
+	seen := make(map[int]struct{})
+	for _, m := range u.Members {
+		if m.Ordinal < 1 {
+			return fmt.Errorf("%s: ordinal %d is not positive", u.Name, m.Ordinal)
+		}
+		if _, ok := seen[m.Ordinal]; ok {
+			return fmt.Errorf("%s: ordinal %d is used more than once", u.Name, m.Ordinal)
+		}
+		seen[m.Ordinal] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestUnionOrdinals(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.ReservedUnion)
+	u := &root.Unions[0]
+	if err := u.ValidateOrdinals(); err != nil {
+		t.Fatal(err)
+	}
+	if got := u.MaxOrdinal(); got != 4 {
+		t.Errorf("got MaxOrdinal() = %d, want 4", got)
+	}
+	if u.IsDense() {
+		t.Errorf("union with reserved members is dense")
+	}
+	indices := u.MemberIndicesByOrdinal()
+	if len(indices) != 4 || indices[0] != -1 || indices[2] != -1 {
+		t.Fatalf("got indices %v, want gaps at ordinals 1 and 3", indices)
+	}
+	for ordinal, name := range map[int]fidlgen.Identifier{2: "a", 4: "b"} {
+		if got := u.Members[indices[ordinal-1]].Name; got != name {
+			t.Errorf("ordinal %d: got member %s, want %s", ordinal, got, name)
+		}
+	}
+}
+
+func TestUnionOrdinalsDense(t *testing.T) {
+	u := fidlgen.Union{Members: []fidlgen.UnionMember{
+		{Name: "b", Ordinal: 2},
+		{Name: "a", Ordinal: 1},
+		{Ordinal: 3, Reserved: true},
+	}}
+	if !u.IsDense() {
+		t.Errorf("union with a trailing reserved member is not dense")
+	}
+	if diff := cmp.Diff([]int{1, 0}, u.MemberIndicesByOrdinal()); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	var empty fidlgen.Union
+	if empty.MaxOrdinal() != 0 || !empty.IsDense() || len(empty.MemberIndicesByOrdinal()) != 0 {
+		t.Errorf("unexpected ordinals for an empty union")
+	}
+
+	for name, members := range map[string][]fidlgen.UnionMember{
+		"duplicate": {{Name: "a", Ordinal: 1}, {Ordinal: 1, Reserved: true}},
+		"zero":      {{Name: "a", Ordinal: 0}},
+	} {
+		bad := fidlgen.Union{Members: members}
+		if err := bad.ValidateOrdinals(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"sort"
+)
+
+// Under the unknown_interactions experiment, open and ajar protocols tolerate
+// messages with ordinals they do not know of, as long as those are flexible.
+// Bindings generate scaffolding for this: the set of known ordinals to tell
+// unknown ones apart, and handlers which users implement to be notified of
+// unknown interactions. UnknownInteractionsData computes what that scaffolding
+// needs once, so that backends do not derive it in their templates. fidlgen_go
+// does not use it, as it generates no bindings for open and ajar protocols.
+
+// FlexibleMethod identifies a flexible method or event of a protocol.
+type FlexibleMethod struct {
+	// Name is the name of the method.
+	Name Identifier
+	// Ordinal is the ordinal of the method.
+	Ordinal uint64
+}
+
+// UnknownInteractionsData describes the unknown interaction scaffolding of a
+// protocol.
+type UnknownInteractionsData struct {
+	// Protocol is the name of the protocol.
+	Protocol EncodedCompoundIdentifier
+	// Openness is the openness of the protocol.
+	Openness Openness
+	// KnownOrdinals lists the ordinals of all methods and events of the
+	// protocol, sorted. Any other ordinal is unknown.
+	KnownOrdinals []uint64
+	// FlexibleOneWayMethods lists the flexible one-way methods, in
+	// declaration order.
+	FlexibleOneWayMethods []FlexibleMethod
+	// FlexibleTwoWayMethods lists the flexible two-way methods, in declaration
+	// order. Their responses carry a framework_err variant.
+	FlexibleTwoWayMethods []FlexibleMethod
+	// FlexibleEvents lists the flexible events, in declaration order.
+	FlexibleEvents []FlexibleMethod
+	// ServerHandler indicates whether servers must implement a handler for
+	// unknown methods, i.e. whether the protocol is open or ajar.
+	ServerHandler bool
+	// ServerHandlerTwoWay indicates whether the server handler also receives
+	// unknown two-way methods, to which bindings reply with a framework error,
+	// i.e. whether the protocol is open.
+	ServerHandlerTwoWay bool
+	// ClientHandler indicates whether clients must implement a handler for
+	// unknown events, i.e. whether the protocol is open or ajar.
+	ClientHandler bool
+}
+
+// NewUnknownInteractionsData computes the unknown interaction scaffolding of a
+// protocol, regardless of whether the unknown_interactions experiment is
+// active. See Root.UnknownInteractionsData.
+func NewUnknownInteractionsData(p *Protocol) UnknownInteractionsData {
+	d := UnknownInteractionsData{
+		Protocol:            p.Name,
+		Openness:            p.Openness,
+		ServerHandler:       p.OneWayUnknownInteractions(),
+		ServerHandlerTwoWay: p.TwoWayUnknownInteractions(),
+		ClientHandler:       p.OneWayUnknownInteractions(),
+	}
+	for i := range p.Methods {
+		m := &p.Methods[i]
+		d.KnownOrdinals = append(d.KnownOrdinals, m.Ordinal)
+		if m.IsStrict() {
+			continue
+		}
+		flexible := FlexibleMethod{Name: m.Name, Ordinal: m.Ordinal}
+		switch {
+		case !m.HasRequest:
+			d.FlexibleEvents = append(d.FlexibleEvents, flexible)
+		case m.HasResponse:
+			d.FlexibleTwoWayMethods = append(d.FlexibleTwoWayMethods, flexible)
+		default:
+			d.FlexibleOneWayMethods = append(d.FlexibleOneWayMethods, flexible)
+		}
+	}
+	sort.Slice(d.KnownOrdinals, func(i, j int) bool {
+		return d.KnownOrdinals[i] < d.KnownOrdinals[j]
+	})
+	return d
+}
+
+// UnknownInteractionsData computes the unknown interaction scaffolding of one
+// of the library's protocols, if the unknown_interactions experiment is
+// active. Otherwise, bindings generate no such scaffolding and false is
+// returned.
+func (r *Root) UnknownInteractionsData(p *Protocol) (UnknownInteractionsData, bool) {
+	if !r.Experiments.Contains(ExperimentUnknownInteractions) {
+		return UnknownInteractionsData{}, false
+	}
+	return NewUnknownInteractionsData(p), true
+}
+
+// UnknownMethodHandling is how a peer which does not know a method, e.g. one
+// built against an older version of the protocol, handles its messages.
+type UnknownMethodHandling string
+
+const (
+	// UnknownMethodClose means the peer closes the channel, since the method
+	// is strict or the protocol does not tolerate its kind of unknown
+	// interactions.
+	UnknownMethodClose UnknownMethodHandling = "close"
+	// UnknownMethodOneWay means the server dispatches the message to its
+	// handler for unknown methods.
+	UnknownMethodOneWay UnknownMethodHandling = "one_way"
+	// UnknownMethodTwoWay means the server dispatches the message to its
+	// handler for unknown methods, and its bindings reply with a framework
+	// error.
+	UnknownMethodTwoWay UnknownMethodHandling = "two_way"
+	// UnknownMethodEvent means the client dispatches the message to its
+	// handler for unknown events.
+	UnknownMethodEvent UnknownMethodHandling = "event"
+)
+
+// MethodUnknownInteractions describes how the rules of unknown interactions
+// (RFC-0138) apply to a method of a protocol.
+type MethodUnknownInteractions struct {
+	// FlexibleHeader indicates whether the messages of the method have the
+	// flexible flag set in their header, which is how a peer that does not
+	// know the method tells whether it may tolerate it.
+	FlexibleHeader bool
+	// FrameworkError indicates whether the response carries a framework error
+	// variant, which clients must handle, i.e. whether the method is flexible
+	// and two-way. The server bindings reply with it on their own for unknown
+	// methods: the server implementation never does.
+	FrameworkError bool
+	// Handling is how a peer which does not know the method handles its
+	// messages.
+	Handling UnknownMethodHandling
+	// UnknownReplyOrdinal is the ordinal of the framework error a server
+	// which does not know the method replies with, if Handling is
+	// UnknownMethodTwoWay. The reply echoes the ordinal and the transaction ID
+	// of the request, so this is the ordinal of the method. It is 0 otherwise.
+	UnknownReplyOrdinal uint64
+}
+
+// MethodUnknownInteractions describes how the rules of unknown interactions
+// apply to one of the methods of the protocol, regardless of whether the
+// unknown_interactions experiment is active.
+func (p *Protocol) MethodUnknownInteractions(m *Method) MethodUnknownInteractions {
+	d := MethodUnknownInteractions{
+		FlexibleHeader: m.IsFlexible(),
+		FrameworkError: m.HasFrameworkError(),
+		Handling:       UnknownMethodClose,
+	}
+	if m.IsStrict() {
+		return d
+	}
+	switch {
+	case m.IsEvent():
+		if p.OneWayUnknownInteractions() {
+			d.Handling = UnknownMethodEvent
+		}
+	case m.IsOneWay():
+		if p.OneWayUnknownInteractions() {
+			d.Handling = UnknownMethodOneWay
+		}
+	case m.IsTwoWay():
+		if p.TwoWayUnknownInteractions() {
+			d.Handling = UnknownMethodTwoWay
+			d.UnknownReplyOrdinal = m.Ordinal
+		}
+	}
+	return d
+}
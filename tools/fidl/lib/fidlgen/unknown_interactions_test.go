@@ -0,0 +1,117 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestUnknownInteractionsData(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	p := &root.Protocols[0]
+	data, ok := root.UnknownInteractionsData(p)
+	if !ok {
+		t.Fatalf("expected the unknown_interactions experiment to be active")
+	}
+
+	ordinal := func(name fidlgen.Identifier) uint64 {
+		for _, m := range p.Methods {
+			if m.Name == name {
+				return m.Ordinal
+			}
+		}
+		t.Fatalf("method %s not found", name)
+		return 0
+	}
+	var known []uint64
+	for _, m := range p.Methods {
+		known = append(known, m.Ordinal)
+	}
+	expected := fidlgen.UnknownInteractionsData{
+		Protocol:              "test.openprotocol/P",
+		Openness:              fidlgen.Open,
+		KnownOrdinals:         known,
+		FlexibleOneWayMethods: []fidlgen.FlexibleMethod{{Name: "OneWay", Ordinal: ordinal("OneWay")}},
+		FlexibleTwoWayMethods: []fidlgen.FlexibleMethod{{Name: "FlexibleTwoWay", Ordinal: ordinal("FlexibleTwoWay")}},
+		FlexibleEvents:        []fidlgen.FlexibleMethod{{Name: "OnEvent", Ordinal: ordinal("OnEvent")}},
+		ServerHandler:         true,
+		ServerHandlerTwoWay:   true,
+		ClientHandler:         true,
+	}
+	opts := cmp.Transformer("sort", func(in []uint64) map[uint64]bool {
+		out := make(map[uint64]bool)
+		for _, o := range in {
+			out[o] = true
+		}
+		return out
+	})
+	if diff := cmp.Diff(expected, data, opts); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+	for i := 1; i < len(data.KnownOrdinals); i++ {
+		if data.KnownOrdinals[i-1] > data.KnownOrdinals[i] {
+			t.Errorf("known ordinals are not sorted: %v", data.KnownOrdinals)
+		}
+	}
+
+	root.Experiments = nil
+	if _, ok := root.UnknownInteractionsData(p); ok {
+		t.Errorf("expected no data without the unknown_interactions experiment")
+	}
+}
+
+func TestMethodUnknownInteractions(t *testing.T) {
+	root := irtestdata.Load(t, irtestdata.OpenProtocol)
+	p := root.Protocols[0]
+	ordinals := make(map[fidlgen.Identifier]uint64)
+	for _, m := range p.Methods {
+		ordinals[m.Name] = m.Ordinal
+	}
+	for _, tc := range []struct {
+		openness fidlgen.Openness
+		expected map[fidlgen.Identifier]fidlgen.MethodUnknownInteractions
+	}{
+		{
+			openness: fidlgen.Open,
+			expected: map[fidlgen.Identifier]fidlgen.MethodUnknownInteractions{
+				"OneWay":         {FlexibleHeader: true, Handling: fidlgen.UnknownMethodOneWay},
+				"TwoWay":         {Handling: fidlgen.UnknownMethodClose},
+				"FlexibleTwoWay": {FlexibleHeader: true, FrameworkError: true, Handling: fidlgen.UnknownMethodTwoWay, UnknownReplyOrdinal: ordinals["FlexibleTwoWay"]},
+				"OnEvent":        {FlexibleHeader: true, Handling: fidlgen.UnknownMethodEvent},
+			},
+		},
+		{
+			openness: fidlgen.Ajar,
+			expected: map[fidlgen.Identifier]fidlgen.MethodUnknownInteractions{
+				"OneWay":         {FlexibleHeader: true, Handling: fidlgen.UnknownMethodOneWay},
+				"TwoWay":         {Handling: fidlgen.UnknownMethodClose},
+				"FlexibleTwoWay": {FlexibleHeader: true, FrameworkError: true, Handling: fidlgen.UnknownMethodClose},
+				"OnEvent":        {FlexibleHeader: true, Handling: fidlgen.UnknownMethodEvent},
+			},
+		},
+		{
+			openness: fidlgen.Closed,
+			expected: map[fidlgen.Identifier]fidlgen.MethodUnknownInteractions{
+				"OneWay":         {FlexibleHeader: true, Handling: fidlgen.UnknownMethodClose},
+				"TwoWay":         {Handling: fidlgen.UnknownMethodClose},
+				"FlexibleTwoWay": {FlexibleHeader: true, FrameworkError: true, Handling: fidlgen.UnknownMethodClose},
+				"OnEvent":        {FlexibleHeader: true, Handling: fidlgen.UnknownMethodClose},
+			},
+		},
+	} {
+		p.Openness = tc.openness
+		actual := make(map[fidlgen.Identifier]fidlgen.MethodUnknownInteractions)
+		for i := range p.Methods {
+			actual[p.Methods[i].Name] = p.MethodUnknownInteractions(&p.Methods[i])
+		}
+		if diff := cmp.Diff(tc.expected, actual); diff != "" {
+			t.Errorf("%s: unexpected diff (-want +got):\n%s", tc.openness, diff)
+		}
+	}
+}
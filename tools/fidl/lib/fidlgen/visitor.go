@@ -0,0 +1,82 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import "fmt"
+
+// Visitor handles each kind of declaration. Implementations that handle every
+// kind should implement the interface directly, so that adding a new kind of
+// declaration breaks the build until it is handled; implementations that only
+// care about a few kinds can embed BaseVisitor instead.
+type Visitor interface {
+	VisitConst(*Const)
+	VisitBits(*Bits)
+	VisitEnum(*Enum)
+	VisitResource(*Resource)
+	VisitProtocol(*Protocol)
+	VisitService(*Service)
+	VisitStruct(*Struct)
+	VisitTable(*Table)
+	VisitUnion(*Union)
+	VisitTypeAlias(*TypeAlias)
+	VisitNewType(*NewType)
+}
+
+// BaseVisitor is a Visitor that ignores every declaration. It is meant to be
+// embedded by visitors that only override some of its methods.
+type BaseVisitor struct{}
+
+func (BaseVisitor) VisitConst(*Const)         {}
+func (BaseVisitor) VisitBits(*Bits)           {}
+func (BaseVisitor) VisitEnum(*Enum)           {}
+func (BaseVisitor) VisitResource(*Resource)   {}
+func (BaseVisitor) VisitProtocol(*Protocol)   {}
+func (BaseVisitor) VisitService(*Service)     {}
+func (BaseVisitor) VisitStruct(*Struct)       {}
+func (BaseVisitor) VisitTable(*Table)         {}
+func (BaseVisitor) VisitUnion(*Union)         {}
+func (BaseVisitor) VisitTypeAlias(*TypeAlias) {}
+func (BaseVisitor) VisitNewType(*NewType)     {}
+
+var _ Visitor = BaseVisitor{}
+
+// AcceptDecl dispatches a single declaration to the corresponding method of
+// the visitor.
+func AcceptDecl(decl Declaration, v Visitor) {
+	switch decl := decl.(type) {
+	case *Const:
+		v.VisitConst(decl)
+	case *Bits:
+		v.VisitBits(decl)
+	case *Enum:
+		v.VisitEnum(decl)
+	case *Resource:
+		v.VisitResource(decl)
+	case *Protocol:
+		v.VisitProtocol(decl)
+	case *Service:
+		v.VisitService(decl)
+	case *Struct:
+		v.VisitStruct(decl)
+	case *Table:
+		v.VisitTable(decl)
+	case *Union:
+		v.VisitUnion(decl)
+	case *TypeAlias:
+		v.VisitTypeAlias(decl)
+	case *NewType:
+		v.VisitNewType(decl)
+	default:
+		panic(fmt.Sprintf("unknown declaration type: %T", decl))
+	}
+}
+
+// Accept visits each declaration of the library, in the same order as
+// ForEachDecl. External structs are visited through VisitStruct.
+func (r *Root) Accept(v Visitor) {
+	r.ForEachDecl(func(decl Declaration) {
+		AcceptDecl(decl, v)
+	})
+}
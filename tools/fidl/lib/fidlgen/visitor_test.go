@@ -0,0 +1,49 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+type layoutCollector struct {
+	fidlgen.BaseVisitor
+	visited []fidlgen.EncodedCompoundIdentifier
+}
+
+func (c *layoutCollector) VisitStruct(s *fidlgen.Struct) {
+	c.visited = append(c.visited, s.Name)
+}
+
+func (c *layoutCollector) VisitUnion(u *fidlgen.Union) {
+	c.visited = append(c.visited, u.Name)
+}
+
+func TestAccept(t *testing.T) {
+	root := fidlgen.Root{
+		Name:            "example",
+		Consts:          []fidlgen.Const{{Decl: fidlgen.Decl{Name: "example/MAX"}}},
+		Structs:         []fidlgen.Struct{structDecl("example/Point")},
+		ExternalStructs: []fidlgen.Struct{structDecl("dep/Color")},
+		Unions: []fidlgen.Union{
+			{
+				ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+					LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Shape"}},
+				},
+			},
+		},
+		Protocols: []fidlgen.Protocol{{Decl: fidlgen.Decl{Name: "example/Canvas"}}},
+	}
+
+	var c layoutCollector
+	root.Accept(&c)
+	expected := []fidlgen.EncodedCompoundIdentifier{"example/Point", "dep/Color", "example/Shape"}
+	if diff := cmp.Diff(expected, c.visited); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+// WalkType calls visit on a type and then on its element types, outermost
+// first, e.g. on `vector<array<T, 2>>`, then `array<T, 2>`, then `T`. The walk
+// stops descending as soon as visit returns false.
+func WalkType(typ *Type, visit func(*Type) bool) {
+	for ; typ != nil; typ = typ.ElementType {
+		if !visit(typ) {
+			return
+		}
+	}
+}
+
+// WalkResolvedType is like WalkType, but further walks the types making up
+// the declarations that identifier types refer to, as resolved through the
+// given resolver: the member types of structs, tables and unions, and the
+// underlying types of bits and new types. Each declaration is walked at most
+// once, so that recursive types terminate, and protocols, whose identifier
+// types are client ends, are not walked. Declarations the resolver does not
+// know about, e.g. those of dependencies, are not walked either.
+func WalkResolvedType(typ *Type, resolver DeclResolver, visit func(*Type) bool) {
+	seen := make(map[EncodedCompoundIdentifier]struct{})
+	var walk func(*Type)
+	walk = func(typ *Type) {
+		WalkType(typ, func(typ *Type) bool {
+			if !visit(typ) {
+				return false
+			}
+			if typ.Kind != IdentifierType {
+				return true
+			}
+			if _, ok := seen[typ.Identifier]; ok {
+				return true
+			}
+			seen[typ.Identifier] = struct{}{}
+			decl, ok := resolver.LookupDecl(typ.Identifier)
+			if !ok {
+				return true
+			}
+			switch decl := decl.(type) {
+			case *Struct:
+				for i := range decl.Members {
+					walk(&decl.Members[i].Type)
+				}
+			case *Table:
+				for i := range decl.Members {
+					walk(&decl.Members[i].Type)
+				}
+			case *Union:
+				for i := range decl.Members {
+					walk(&decl.Members[i].Type)
+				}
+			case *Bits:
+				walk(&decl.Type)
+			case *NewType:
+				walk(&decl.Type)
+			}
+			return true
+		})
+	}
+	walk(typ)
+}
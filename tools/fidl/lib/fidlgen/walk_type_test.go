@@ -0,0 +1,107 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// typeLabel briefly describes a type, for comparing walks.
+func typeLabel(typ *fidlgen.Type) string {
+	if typ.Kind == fidlgen.IdentifierType {
+		return string(typ.Identifier)
+	}
+	if typ.Kind == fidlgen.PrimitiveType {
+		return string(typ.PrimitiveSubtype)
+	}
+	return string(typ.Kind)
+}
+
+func TestWalkType(t *testing.T) {
+	typ := fidlgen.Type{
+		Kind: fidlgen.VectorType,
+		ElementType: &fidlgen.Type{
+			Kind:        fidlgen.ArrayType,
+			ElementType: identifierType("example/Node"),
+		},
+	}
+
+	var walked []string
+	fidlgen.WalkType(&typ, func(typ *fidlgen.Type) bool {
+		walked = append(walked, typeLabel(typ))
+		return true
+	})
+	if diff := cmp.Diff([]string{"vector", "array", "example/Node"}, walked); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	walked = nil
+	fidlgen.WalkType(&typ, func(typ *fidlgen.Type) bool {
+		walked = append(walked, typeLabel(typ))
+		return typ.Kind != fidlgen.ArrayType
+	})
+	if diff := cmp.Diff([]string{"vector", "array"}, walked); diff != "" {
+		t.Errorf("pruned: unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestWalkResolvedType(t *testing.T) {
+	u32 := fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint32}
+	node := structDecl("example/Node", "children", "flags", "handle", "parent", "client", "external")
+	node.Members[0].Type = fidlgen.Type{Kind: fidlgen.VectorType, ElementType: identifierType("example/Node")}
+	node.Members[1].Type = *identifierType("example/Flags")
+	node.Members[2].Type = fidlgen.Type{Kind: fidlgen.HandleType}
+	node.Members[3].Type = *identifierType("example/Node")
+	node.Members[4].Type = *identifierType("example/Protocol")
+	node.Members[5].Type = *identifierType("dep/External")
+	root := fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{node},
+		Bits: []fidlgen.Bits{
+			{
+				LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: "example/Flags"}},
+				Type:       u32,
+			},
+		},
+		Protocols: []fidlgen.Protocol{
+			{
+				Decl: fidlgen.Decl{Name: "example/Protocol"},
+			},
+		},
+	}
+
+	var walked []string
+	fidlgen.WalkResolvedType(identifierType("example/Node"), &root, func(typ *fidlgen.Type) bool {
+		walked = append(walked, typeLabel(typ))
+		return true
+	})
+	expected := []string{
+		"example/Node",
+		"vector", "example/Node",
+		"example/Flags", "uint32",
+		"handle",
+		"example/Node",
+		"example/Protocol",
+		"dep/External",
+	}
+	if diff := cmp.Diff(expected, walked); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	// Counting handles is a typical use.
+	var handles int
+	fidlgen.WalkResolvedType(identifierType("example/Node"), &root, func(typ *fidlgen.Type) bool {
+		if typ.Kind == fidlgen.HandleType {
+			handles++
+		}
+		return true
+	})
+	if handles != 1 {
+		t.Errorf("got %d handles, want 1", handles)
+	}
+}
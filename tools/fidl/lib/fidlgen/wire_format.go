@@ -0,0 +1,162 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen
+
+import (
+	"fmt"
+)
+
+// The IR carries the type and field shapes of every wire format side by side,
+// e.g. TypeShapeV1 and TypeShapeV2. Code which depends on the wire format
+// should go through the Shape and FieldShape accessors below, given a
+// WireFormatVersion, rather than pick one of those fields: adding a wire format
+// is then a change local to this file.
+
+// WireFormatVersion identifies a version of the FIDL wire format.
+type WireFormatVersion int
+
+const (
+	_ = iota
+	WireFormatVersionV1
+	WireFormatVersionV2
+)
+
+// WireFormatVersions lists all wire format versions, oldest first.
+var WireFormatVersions = []WireFormatVersion{WireFormatVersionV1, WireFormatVersionV2}
+
+func (wf WireFormatVersion) String() string {
+	switch wf {
+	case WireFormatVersionV1:
+		return "v1"
+	case WireFormatVersionV2:
+		return "v2"
+	default:
+		return fmt.Sprintf("WireFormatVersion(%d)", int(wf))
+	}
+}
+
+// envelopeSize returns the inline size of an envelope.
+func (wf WireFormatVersion) envelopeSize() int {
+	switch wf {
+	case WireFormatVersionV1:
+		return 16
+	case WireFormatVersionV2:
+		return 8
+	default:
+		panic(fmt.Sprintf("unknown wire format version: %s", wf))
+	}
+}
+
+// maxInlineEnvelopeSize returns the maximum inline size of the values stored
+// in envelopes themselves rather than out-of-line, or 0 if there are none.
+func (wf WireFormatVersion) maxInlineEnvelopeSize() int {
+	switch wf {
+	case WireFormatVersionV1:
+		return 0
+	case WireFormatVersionV2:
+		return 4
+	default:
+		panic(fmt.Sprintf("unknown wire format version: %s", wf))
+	}
+}
+
+// selectTypeShape returns the field holding the type shape of a wire format.
+func selectTypeShape(wf WireFormatVersion, v1, v2 *TypeShape) *TypeShape {
+	switch wf {
+	case WireFormatVersionV1:
+		return v1
+	case WireFormatVersionV2:
+		return v2
+	default:
+		panic(fmt.Sprintf("unknown wire format version: %s", wf))
+	}
+}
+
+// selectFieldShape returns the field holding the field shape of a wire format.
+func selectFieldShape(wf WireFormatVersion, v1, v2 *FieldShape) *FieldShape {
+	switch wf {
+	case WireFormatVersionV1:
+		return v1
+	case WireFormatVersionV2:
+		return v2
+	default:
+		panic(fmt.Sprintf("unknown wire format version: %s", wf))
+	}
+}
+
+// Shape returns the shape of the type in the given wire format.
+func (t *Type) Shape(wf WireFormatVersion) TypeShape {
+	return *selectTypeShape(wf, &t.TypeShapeV1, &t.TypeShapeV2)
+}
+
+// SetShape sets the shape of the type in the given wire format.
+func (t *Type) SetShape(wf WireFormatVersion, shape TypeShape) {
+	*selectTypeShape(wf, &t.TypeShapeV1, &t.TypeShapeV2) = shape
+}
+
+// Shape returns the shape of the struct in the given wire format.
+func (s *Struct) Shape(wf WireFormatVersion) TypeShape {
+	return *selectTypeShape(wf, &s.TypeShapeV1, &s.TypeShapeV2)
+}
+
+// SetShape sets the shape of the struct in the given wire format.
+func (s *Struct) SetShape(wf WireFormatVersion, shape TypeShape) {
+	*selectTypeShape(wf, &s.TypeShapeV1, &s.TypeShapeV2) = shape
+}
+
+// FieldShape returns the shape of the member's field in the given wire format.
+func (m *StructMember) FieldShape(wf WireFormatVersion) FieldShape {
+	return *selectFieldShape(wf, &m.FieldShapeV1, &m.FieldShapeV2)
+}
+
+// SetFieldShape sets the shape of the member's field in the given wire format.
+func (m *StructMember) SetFieldShape(wf WireFormatVersion, shape FieldShape) {
+	*selectFieldShape(wf, &m.FieldShapeV1, &m.FieldShapeV2) = shape
+}
+
+// Shape returns the shape of the table in the given wire format.
+func (t *Table) Shape(wf WireFormatVersion) TypeShape {
+	return *selectTypeShape(wf, &t.TypeShapeV1, &t.TypeShapeV2)
+}
+
+// SetShape sets the shape of the table in the given wire format.
+func (t *Table) SetShape(wf WireFormatVersion, shape TypeShape) {
+	*selectTypeShape(wf, &t.TypeShapeV1, &t.TypeShapeV2) = shape
+}
+
+// Shape returns the shape of the union in the given wire format.
+func (u *Union) Shape(wf WireFormatVersion) TypeShape {
+	return *selectTypeShape(wf, &u.TypeShapeV1, &u.TypeShapeV2)
+}
+
+// SetShape sets the shape of the union in the given wire format.
+func (u *Union) SetShape(wf WireFormatVersion, shape TypeShape) {
+	*selectTypeShape(wf, &u.TypeShapeV1, &u.TypeShapeV2) = shape
+}
+
+// OmitTypeShapeV1 zeroes the V1 type and field shapes throughout the library,
+// so that consumers which only target the V2 wire format cannot come to depend
+// on them. Shape(WireFormatVersionV1) then returns the zero TypeShape.
+func (r *Root) OmitTypeShapeV1() {
+	r.forEachTopLevelType(func(typ *Type) {
+		WalkType(typ, func(typ *Type) bool {
+			typ.TypeShapeV1 = TypeShape{}
+			return true
+		})
+	})
+	r.ForEachDecl(func(decl Declaration) {
+		switch decl := decl.(type) {
+		case *Struct:
+			decl.TypeShapeV1 = TypeShape{}
+			for i := range decl.Members {
+				decl.Members[i].FieldShapeV1 = FieldShape{}
+			}
+		case *Table:
+			decl.TypeShapeV1 = TypeShape{}
+		case *Union:
+			decl.TypeShapeV1 = TypeShape{}
+		}
+	})
+}
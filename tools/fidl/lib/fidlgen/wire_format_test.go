@@ -0,0 +1,105 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest/irtestdata"
+)
+
+func TestWireFormatAccessors(t *testing.T) {
+	s := structDecl("example/S", "a")
+	for i, wf := range fidlgen.WireFormatVersions {
+		s.SetShape(wf, fidlgen.TypeShape{InlineSize: i + 1})
+		s.Members[0].SetFieldShape(wf, fidlgen.FieldShape{Offset: i + 1})
+		s.Members[0].Type.SetShape(wf, fidlgen.TypeShape{InlineSize: i + 1})
+	}
+	if s.TypeShapeV1.InlineSize != 1 || s.TypeShapeV2.InlineSize != 2 {
+		t.Errorf("got shapes %+v and %+v", s.TypeShapeV1, s.TypeShapeV2)
+	}
+	if s.Members[0].FieldShapeV1.Offset != 1 || s.Members[0].FieldShapeV2.Offset != 2 {
+		t.Errorf("got field shapes %+v and %+v", s.Members[0].FieldShapeV1, s.Members[0].FieldShapeV2)
+	}
+	for i, wf := range fidlgen.WireFormatVersions {
+		if got := s.Shape(wf).InlineSize; got != i+1 {
+			t.Errorf("%s: got struct size %d", wf, got)
+		}
+		if got := s.Members[0].FieldShape(wf).Offset; got != i+1 {
+			t.Errorf("%s: got member offset %d", wf, got)
+		}
+		if got := s.Members[0].Type.Shape(wf).InlineSize; got != i+1 {
+			t.Errorf("%s: got member size %d", wf, got)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for an unknown wire format")
+		}
+	}()
+	s.Shape(fidlgen.WireFormatVersion(0))
+}
+
+// stripV1Shapes removes the V1 type and field shapes from JSON IR.
+func stripV1Shapes(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		delete(v, "type_shape_v1")
+		delete(v, "field_shape_v1")
+		for _, child := range v {
+			stripV1Shapes(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			stripV1Shapes(child)
+		}
+	}
+}
+
+func TestOmitTypeShapeV1(t *testing.T) {
+	b, err := fs.ReadFile(irtestdata.FS(), irtestdata.ReservedUnion+".fidl.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := fidlgen.DecodeJSONIr(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	omitted, err := fidlgen.DecodeJSONIrWithOptions(bytes.NewReader(b), fidlgen.DecodeOptions{OmitTypeShapeV1: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ir interface{}
+	if err := json.Unmarshal(b, &ir); err != nil {
+		t.Fatal(err)
+	}
+	stripV1Shapes(ir)
+	if b, err = json.Marshal(ir); err != nil {
+		t.Fatal(err)
+	}
+	stripped, err := fidlgen.DecodeJSONIrWithOptions(bytes.NewReader(b), fidlgen.DecodeOptions{OmitTypeShapeV1: true})
+	if err != nil {
+		t.Fatalf("failed to decode IR without V1 shapes: %s", err)
+	}
+	if diff := cmp.Diff(omitted, stripped, fidlgentest.AllowUnexported()); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	u := omitted.Unions[0]
+	if u.TypeShapeV1 != (fidlgen.TypeShape{}) || u.Members[1].Type.TypeShapeV1 != (fidlgen.TypeShape{}) {
+		t.Errorf("expected V1 shapes to be omitted, got %+v and %+v", u.TypeShapeV1, u.Members[1].Type.TypeShapeV1)
+	}
+	if diff := cmp.Diff(full.Unions[0].TypeShapeV2, u.TypeShapeV2); diff != "" {
+		t.Errorf("V2 shape: unexpected diff (-want +got):\n%s", diff)
+	}
+}
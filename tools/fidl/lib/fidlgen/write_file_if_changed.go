@@ -6,12 +6,13 @@ package fidlgen
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 )
 
 // WriteFileIfChanged overwrite the filename with new contents unless the file already
-// has those contents.
+// has those contents. The file is replaced atomically.
 func WriteFileIfChanged(filename string, contents []byte) error {
 	var current []byte
 	stat, err := os.Stat(filename)
@@ -34,8 +35,88 @@ func WriteFileIfChanged(filename string, contents []byte) error {
 	}
 
 overwrite:
-	if err := os.MkdirAll(filepath.Dir(filename), os.FileMode(0777)); err != nil {
+	return writeFileAtomically(filename, contents)
+}
+
+// writeFileAtomically writes contents to a temporary file next to filename and
+// syncs it, then renames it to filename, so that readers never see a partially
+// written file, even if generation is interrupted. If filename is a symbolic
+// link, the file it points to is replaced, not the link. The file keeps its
+// permissions if it exists, and otherwise gets those os.WriteFile would give
+// it.
+func writeFileAtomically(filename string, contents []byte) error {
+	filename, err := resolveSymlinks(filename)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, os.FileMode(0777)); err != nil {
+		return err
+	}
+	// New files are created with 0666, which the umask filters.
+	perm, keepPerm := os.FileMode(0666), false
+	if stat, err := os.Stat(filename); err == nil {
+		perm, keepPerm = stat.Mode().Perm(), true
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	// os.CreateTemp would create the file with 0600 rather than perm, so the
+	// file is created in a temporary directory of its own instead.
+	tmpDir, err := os.MkdirTemp(dir, "."+filepath.Base(filename)+".")
+	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, contents, os.FileMode(0666))
+	defer os.RemoveAll(tmpDir)
+	tmp, err := os.OpenFile(filepath.Join(tmpDir, filepath.Base(filename)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		return err
+	}
+	// The umask also filters the permissions of an existing file, which must
+	// be restored as they are.
+	if keepPerm {
+		if err := tmp.Chmod(perm); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	// Without syncing, a crash shortly after the rename may leave an empty
+	// file behind on some filesystems.
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// resolveSymlinks follows filename while it is a symbolic link, and returns
+// the path of the file it points to, which may not exist yet.
+func resolveSymlinks(filename string) (string, error) {
+	for i := 0; i < 255; i++ {
+		stat, err := os.Lstat(filename)
+		if os.IsNotExist(err) {
+			return filename, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if stat.Mode()&os.ModeSymlink == 0 {
+			return filename, nil
+		}
+		target, err := os.Readlink(filename)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(filename), target)
+		}
+		filename = target
+	}
+	return "", fmt.Errorf("%s: too many levels of symbolic links", filename)
 }
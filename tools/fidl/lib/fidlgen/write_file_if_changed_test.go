@@ -0,0 +1,103 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func checkFile(t *testing.T, filename string, contents string, perm os.FileMode) {
+	t.Helper()
+	actual, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != contents {
+		t.Errorf("%s: got %q, want %q", filename, actual, contents)
+	}
+	stat, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode().Perm() != perm {
+		t.Errorf("%s: got permissions %s, want %s", filename, stat.Mode().Perm(), perm)
+	}
+}
+
+func TestWriteFileIfChangedPermissions(t *testing.T) {
+	dir := t.TempDir()
+
+	// New files get the permissions os.WriteFile gives them, i.e. 0666
+	// filtered by the umask.
+	reference := filepath.Join(dir, "reference")
+	if err := os.WriteFile(reference, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "out", "new.txt")
+	if err := fidlgen.WriteFileIfChanged(filename, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, filename, "new", stat.Mode().Perm())
+	// No temporary file or directory is left behind.
+	if entries, err := os.ReadDir(filepath.Dir(filename)); err != nil || len(entries) != 1 {
+		t.Errorf("got %v, %v, want only %s", entries, err, filename)
+	}
+
+	// Existing files keep their permissions, even those the umask filters.
+	filename = filepath.Join(dir, "existing.sh")
+	if err := os.WriteFile(filename, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filename, 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := fidlgen.WriteFileIfChanged(filename, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, filename, "new", 0777)
+}
+
+func TestWriteFileIfChangedSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	if err := os.WriteFile(target, []byte("old"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink("target.txt", link); err != nil {
+		t.Skipf("cannot create symbolic links: %s", err)
+	}
+	if err := fidlgen.WriteFileIfChanged(link, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("%s was replaced by a regular file", link)
+	}
+	checkFile(t, target, "new", 0640)
+
+	// Dangling links are written through, creating the file they point to.
+	dangling := filepath.Join(dir, "dangling.txt")
+	if err := os.Symlink("created.txt", dangling); err != nil {
+		t.Fatal(err)
+	}
+	if err := fidlgen.WriteFileIfChanged(dangling, []byte("created")); err != nil {
+		t.Fatal(err)
+	}
+	if contents, err := os.ReadFile(filepath.Join(dir, "created.txt")); err != nil || string(contents) != "created" {
+		t.Errorf("got %q, %v, want the contents written through %s", contents, err, dangling)
+	}
+}
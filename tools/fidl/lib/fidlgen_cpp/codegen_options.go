@@ -36,6 +36,8 @@ type CmdlineFlags struct {
 	clangFormatPath string
 	// Experiments is a list of experiments that are enabled.
 	experiments experiments
+	// statsFile is the path generation stats are appended to, if any.
+	statsFile string
 
 	// Configuration
 
@@ -43,6 +45,9 @@ type CmdlineFlags struct {
 	name string
 	// validExperiments is the list of supported experiments in this generator
 	validExperiments []string
+
+	// ir is the JSON IR, once loaded.
+	ir *fidlgen.Root
 }
 
 // NewCmdlineFlags returns a new instance of CmdlineFlags, which holds the
@@ -57,6 +62,8 @@ func NewCmdlineFlags(name string, validExperiments []string) *CmdlineFlags {
 		"where to generate the bindings.")
 	flag.StringVar(&flags.clangFormatPath, "clang-format-path", "",
 		"path to the clang-format tool.")
+	flag.StringVar(&flags.statsFile, "stats-file", "",
+		"path to append generation stats to, overriding $"+fidlgen.StatsFileEnvVar+".")
 	if len(validExperiments) > 0 {
 		flag.Var(&flags.experiments, "experiment",
 			"turn on an experiment, one of: "+strings.Join(validExperiments, ", "))
@@ -105,6 +112,8 @@ func (c *CmdlineFlags) ParseAndLoadIR() *Root {
 		log.Fatal("Missing required flag: --root")
 	}
 
+	c.ir = &ir
+
 	return compileFor(ir, c.name)
 }
 
@@ -37,6 +37,12 @@ func NewGenerator(flags *CmdlineFlags, templates fs.FS, extraFuncs template.Func
 
 	formatter := NewFormatter(flags.clangFormatPath)
 	gen.gen = fidlgen.NewGenerator(flags.name, templates, formatter, funcs)
+	if flags.statsFile != "" {
+		gen.gen.SetStatsFile(flags.statsFile)
+	}
+	if flags.ir != nil {
+		gen.gen.SetStatsLibrary(flags.ir)
+	}
 	return gen
 }
 
@@ -19,68 +19,36 @@ func (c *compiler) fieldHandleInformation(val *fidlgen.Type) *HandleInformation
 	if val.ElementType != nil {
 		return c.fieldHandleInformation(val.ElementType)
 	}
-	if val.Kind == fidlgen.RequestType {
+	if val.Endpoint != nil {
 		return &HandleInformation{
 			ObjectType: "ZX_OBJ_TYPE_CHANNEL",
 			Rights:     "ZX_DEFAULT_CHANNEL_RIGHTS",
 		}
 	}
 	if val.Kind == fidlgen.IdentifierType {
-		declInfo, ok := c.decls[val.Identifier]
-		if !ok {
+		if _, ok := c.decls[val.Identifier]; !ok {
 			panic(fmt.Sprintf("unknown identifier: %v", val.Identifier))
 		}
-		if declInfo.Type == fidlgen.ProtocolDeclType {
-			return &HandleInformation{
-				ObjectType: "ZX_OBJ_TYPE_CHANNEL",
-				Rights:     "ZX_DEFAULT_CHANNEL_RIGHTS",
-			}
-		}
 		// Handle rights are only attached to handle fields or vector/arrays thereof.
 		return nil
 	}
 	if val.Kind == fidlgen.HandleType {
-		subtype, ok := handleSubtypeConsts[val.HandleSubtype]
-		if !ok {
-			panic(fmt.Sprintf("unknown handle type for const: %v", val))
-		}
 		return &HandleInformation{
-			ObjectType: fmt.Sprintf("ZX_OBJ_TYPE_%s", subtype),
+			ObjectType: handleObjectType(val),
 			Rights:     fmt.Sprintf("0x%x", val.HandleRights),
 		}
 	}
 	return nil
 }
 
-var handleSubtypeConsts = map[fidlgen.HandleSubtype]string{
-	fidlgen.HandleSubtypeBti:          "BTI",
-	fidlgen.HandleSubtypeChannel:      "CHANNEL",
-	fidlgen.HandleSubtypeClock:        "CLOCK",
-	fidlgen.HandleSubtypeDebugLog:     "DEBUGLOG",
-	fidlgen.HandleSubtypeEvent:        "EVENT",
-	fidlgen.HandleSubtypeEventpair:    "EVENTPAIR",
-	fidlgen.HandleSubtypeException:    "EXCEPTION",
-	fidlgen.HandleSubtypeFifo:         "FIFO",
-	fidlgen.HandleSubtypeGuest:        "GUEST",
-	fidlgen.HandleSubtypeInterrupt:    "INTERRUPT",
-	fidlgen.HandleSubtypeIommu:        "IOMMU",
-	fidlgen.HandleSubtypeJob:          "JOB",
-	fidlgen.HandleSubtypeMsi:          "MSI",
-	fidlgen.HandleSubtypeNone:         "NONE",
-	fidlgen.HandleSubtypePager:        "PAGER",
-	fidlgen.HandleSubtypePmt:          "PMT",
-	fidlgen.HandleSubtypePort:         "PORT",
-	fidlgen.HandleSubtypeProcess:      "PROCESS",
-	fidlgen.HandleSubtypeProfile:      "PROFILE",
-	fidlgen.HandleSubtypeResource:     "RESOURCE",
-	fidlgen.HandleSubtypeSocket:       "SOCKET",
-	fidlgen.HandleSubtypeStream:       "STREAM",
-	fidlgen.HandleSubtypeSuspendToken: "SUSPEND_TOKEN",
-	fidlgen.HandleSubtypeThread:       "THREAD",
-	fidlgen.HandleSubtypeTime:         "TIMER",
-	fidlgen.HandleSubtypeVcpu:         "VCPU",
-	fidlgen.HandleSubtypeVmar:         "VMAR",
-	fidlgen.HandleSubtypeVmo:          "VMO",
+// handleObjectType returns the ZX_OBJ_TYPE_ constant of the object type of a
+// handle type, or the object type given by the IR for subtypes unknown to
+// fidlgen.
+func handleObjectType(val *fidlgen.Type) string {
+	if subtype, ok := val.HandleSubtype.ConstName(); ok {
+		return fmt.Sprintf("ZX_OBJ_TYPE_%s", subtype)
+	}
+	return fmt.Sprintf("%d", val.ObjType)
 }
 
 // Header names for to use for handles where the name isn't the same as HandleSubtype.
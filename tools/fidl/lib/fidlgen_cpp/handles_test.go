@@ -0,0 +1,61 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgen_cpp
+
+import (
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen/builders"
+)
+
+func TestHandleRightsConstants(t *testing.T) {
+	vmo := builders.Handle(fidlgen.HandleSubtypeVmo, fidlgen.HandleRightsRead|fidlgen.HandleRightsWrite)
+	vmo.ResourceIdentifier = "zx/handle"
+	s := builders.NewStruct("example/S").
+		Member("vmo", vmo).
+		Resource().
+		Build()
+	root := compile(fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{s},
+		Decls:   fidlgen.DeclMap{"example/S": fidlgen.StructDeclType},
+	})
+	var members []StructMember
+	for _, decl := range root.Decls {
+		if s, ok := decl.(*Struct); ok {
+			members = s.Members
+		}
+	}
+	assertEqual(t, len(members), 1)
+	m := members[0]
+	expectEqual(t, m.HandleInformation.Rights, "0xc")
+	expectEqual(t, m.Type.NaturalFieldConstraint, "fidl::internal::NaturalCodingConstraintHandle<ZX_OBJ_TYPE_VMO, 0xc, false>")
+	expectEqual(t, m.Type.WireFieldConstraint, "fidl::internal::WireCodingConstraintHandle<ZX_OBJ_TYPE_VMO, 0xc, false>")
+}
+
+func TestUnknownHandleSubtype(t *testing.T) {
+	counter := builders.HandleOf(fidlgen.HandleObjectTypes{"counter": 38}, "counter", fidlgen.HandleRightsSameRights)
+	counter.ResourceIdentifier = "zx/handle"
+	s := builders.NewStruct("example/S").
+		Member("counter", counter).
+		Resource().
+		Build()
+	root := compile(fidlgen.Root{
+		Name:    "example",
+		Structs: []fidlgen.Struct{s},
+		Decls:   fidlgen.DeclMap{"example/S": fidlgen.StructDeclType},
+	})
+	var members []StructMember
+	for _, decl := range root.Decls {
+		if s, ok := decl.(*Struct); ok {
+			members = s.Members
+		}
+	}
+	assertEqual(t, len(members), 1)
+	m := members[0]
+	expectEqual(t, m.HandleInformation.ObjectType, "38")
+	expectEqual(t, m.Type.WireFieldConstraint, "fidl::internal::WireCodingConstraintHandle<38, 0x80000000, false>")
+}
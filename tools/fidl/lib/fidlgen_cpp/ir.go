@@ -264,7 +264,7 @@ func (r *Root) ProtocolsForTransport() func(string) []*Protocol {
 		var ps []*Protocol
 		for _, k := range r.Protocols() {
 			p := k.(*Protocol)
-			_, ok := p.Transports()[t]
+			_, ok := p.Transports()[fidlgen.Transport(t)]
 			if ok {
 				ps = append(ps, p)
 			}
@@ -502,7 +502,7 @@ func (c *compiler) compileCodingTableType(eci fidlgen.EncodedCompoundIdentifier)
 func (c *compiler) compileType(val fidlgen.Type) Type {
 	r := Type{}
 	r.Nullable = val.Nullable
-	r.InlineInEnvelope = val.TypeShapeV2.InlineSize <= 4
+	r.InlineInEnvelope = val.Shape(fidlgen.WireFormatVersionV2).InlineSize <= 4
 	switch val.Kind {
 	case fidlgen.ArrayType:
 		t := c.compileType(*val.ElementType)
@@ -568,7 +568,7 @@ func (c *compiler) compileType(val fidlgen.Type) Type {
 		}
 	case fidlgen.HandleType:
 		c.handleTypes[val.HandleSubtype] = struct{}{}
-		if val.ResourceIdentifier == "fdf/handle" {
+		if val.IsDriverHandle() {
 			c.containsDriverReferences = true
 		}
 		r.nameVariants = nameVariantsForHandle(val.ResourceIdentifier, val.HandleSubtype)
@@ -577,15 +577,12 @@ func (c *compiler) compileType(val fidlgen.Type) Type {
 		r.Kind = TypeKinds.Handle
 		r.IsResource = true
 
-		subtype, ok := handleSubtypeConsts[val.HandleSubtype]
-		if !ok {
-			panic(fmt.Sprintf("unknown handle type for const: %v", val))
-		}
-		r.NaturalFieldConstraint = fmt.Sprintf("fidl::internal::NaturalCodingConstraintHandle<ZX_OBJ_TYPE_%s, 0x%x, %t>", subtype, val.HandleRights, val.Nullable)
-		r.WireFieldConstraint = fmt.Sprintf("fidl::internal::WireCodingConstraintHandle<ZX_OBJ_TYPE_%s, 0x%x, %t>", subtype, val.HandleRights, val.Nullable)
+		objType := handleObjectType(&val)
+		r.NaturalFieldConstraint = fmt.Sprintf("fidl::internal::NaturalCodingConstraintHandle<%s, 0x%x, %t>", objType, val.HandleRights, val.Nullable)
+		r.WireFieldConstraint = fmt.Sprintf("fidl::internal::WireCodingConstraintHandle<%s, 0x%x, %t>", objType, val.HandleRights, val.Nullable)
 	case fidlgen.RequestType:
 		p := c.compileNameVariants(val.RequestSubtype)
-		if val.ProtocolTransport == "Driver" {
+		if val.ProtocolTransport == fidlgen.DriverTransport {
 			c.containsDriverReferences = true
 		}
 		t, ok := transports[val.ProtocolTransport]
@@ -611,7 +608,7 @@ func (c *compiler) compileType(val fidlgen.Type) Type {
 		r.WireFieldConstraint = "fidl::internal::WireCodingConstraintEmpty"
 	case fidlgen.InternalType:
 		switch val.InternalSubtype {
-		case fidlgen.TransportErr:
+		case fidlgen.FrameworkErr:
 			hlcppVariant := makeName("fidl::TransportErr")
 			newcppVariant := makeName("fidl::internal::TransportErr")
 			r.nameVariants = nameVariants{
@@ -632,7 +629,7 @@ func (c *compiler) compileType(val fidlgen.Type) Type {
 		}
 		declType := declInfo.Type
 		if declType == fidlgen.ProtocolDeclType {
-			if val.ProtocolTransport == "Driver" {
+			if val.ProtocolTransport == fidlgen.DriverTransport {
 				c.containsDriverReferences = true
 			}
 			t, ok := transports[val.ProtocolTransport]
@@ -817,7 +814,8 @@ func compile(r fidlgen.Root) *Root {
 	// need to store the name and (optional) owning result type of the union,
 	// rather than the entire, flattenable declaration with all of its members.
 	for _, v := range r.Libraries {
-		for name, decl := range v.Decls {
+		for _, name := range v.Decls.SortedNames() {
+			decl := v.Decls[name]
 			if decl.Type == fidlgen.TableDeclType {
 				extDecls[name] = &TableName{nameVariants: c.compileNameVariants(name)}
 			} else if decl.Type == fidlgen.UnionDeclType {
@@ -828,7 +826,7 @@ func compile(r fidlgen.Root) *Root {
 
 	for _, v := range r.Protocols {
 		for _, m := range v.Methods {
-			if m.HasError || m.HasTransportError() {
+			if m.HasError || m.HasFrameworkError() {
 				var p Payloader
 				valueTypeDecl, ok := decls[m.ValueType.Identifier]
 				if ok {
@@ -869,7 +867,7 @@ func compile(r fidlgen.Root) *Root {
 		decls[v.Name] = c.compileService(v)
 	}
 
-	g := NewDeclDepGraph(r)
+	g := fidlgen.NewDeclDepGraph(r)
 	for _, v := range g.SortedDecls() {
 		// We process only a subset of declarations mentioned in the declaration
 		// order, ignore those we do not support.
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen/keywords"
 )
 
 func changeIfReserved(name string, ctx fidlgen.NameContext) string {
@@ -227,106 +228,7 @@ func memberNameContext(declType fidlgen.DeclType) memberContext {
 }
 
 func init() {
-	// C++ keywords from: https://en.cppreference.com/w/cpp/keyword.
-	cppKeywords := []string{
-		"alignas",
-		"alignof",
-		"and_eq",
-		"and",
-		"asm",
-		"atomic_cancel",
-		"atomic_commit",
-		"atomic_noexcept",
-		"auto",
-		"bitand",
-		"bitor",
-		"bool",
-		"break",
-		"case",
-		"catch",
-		"char",
-		"char16_t",
-		"char32_t",
-		"class",
-		"co_await",
-		"co_return",
-		"co_yield",
-		"compl",
-		"concept",
-		"const_cast",
-		"const",
-		"consteval",
-		"constexpr",
-		"constinit",
-		"continue",
-		"decltype",
-		"default",
-		"delete",
-		"do",
-		"double",
-		"dynamic_cast",
-		"else",
-		"enum",
-		"explicit",
-		"export",
-		"extern",
-		"false",
-		"float",
-		"for",
-		"friend",
-		"goto",
-		"if",
-		"inline",
-		"int",
-		"long",
-		"module",
-		"mutable",
-		"namespace",
-		"new",
-		"noexcept",
-		"not_eq",
-		"not",
-		"nullptr",
-		"operator",
-		"or_eq",
-		"or",
-		"private",
-		"protected",
-		"public",
-		"reflexexpr",
-		"register",
-		"reinterpret_cast",
-		"requires",
-		"return",
-		"short",
-		"signed",
-		"sizeof",
-		"static_assert",
-		"static_cast",
-		"static",
-		"struct",
-		"switch",
-		"synchronized",
-		"template",
-		"this",
-		"thread_local",
-		"throw",
-		"true",
-		"try",
-		"typedef",
-		"typeid",
-		"typename",
-		"union",
-		"unsigned",
-		"using",
-		"virtual",
-		"void",
-		"volatile",
-		"wchar_t",
-		"while",
-		"xor_eq",
-		"xor",
-	}
+	cppKeywords := keywords.Reserved(keywords.Cpp)
 
 	// All names from errno definitions.
 	errnos := []string{
@@ -235,7 +235,7 @@ func nameVariantsForHandle(resourceIdentifier string, t fidlgen.HandleSubtype) n
 			return commonNameVariants(zxNs.member(typeName))
 		}
 		return commonNameVariants(zxNs.member(string(t)))
-	} else if resourceIdentifier == "fdf/handle" {
+	} else if fidlgen.EncodedCompoundIdentifier(resourceIdentifier) == fidlgen.DriverHandleResource {
 		if t == fidlgen.HandleSubtypeChannel {
 			return commonNameVariants(fdfNs.member("Channel"))
 		}
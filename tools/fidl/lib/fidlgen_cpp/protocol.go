@@ -11,8 +11,6 @@ import (
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
 )
 
-const kMessageHeaderSize = 16
-
 //
 // Generate code for sending and receiving FIDL messages i.e. the messaging API.
 //
@@ -300,15 +298,15 @@ var driverTransport = Transport{
 	HasSyncClient: true,
 }
 
-var transports = map[string]*Transport{
-	"Channel": &channelTransport,
-	"Driver":  &driverTransport,
+var transports = map[fidlgen.Transport]*Transport{
+	fidlgen.ChannelTransport: &channelTransport,
+	fidlgen.DriverTransport:  &driverTransport,
 
 	// Banjo and Syscall transports are skipped in templates, however they are
 	// defined here to indicate they are known transports so that we can fail
 	// on unknown and unhandled transports.
-	"Banjo":   nil,
-	"Syscall": nil,
+	fidlgen.BanjoTransport:   nil,
+	fidlgen.SyscallTransport: nil,
 }
 
 // protocolInner contains information about a Protocol that should be
@@ -1107,7 +1105,7 @@ func (c *compiler) compileProtocol(p fidlgen.Protocol) *Protocol {
 		name := methodNameContext.transform(v.Name)
 
 		var result *Result
-		if v.HasError || v.HasTransportError() {
+		if v.HasError || v.HasFrameworkError() {
 			result = c.resultForUnion[v.ResultType.Identifier]
 		}
 
@@ -1121,8 +1119,8 @@ func (c *compiler) compileProtocol(p fidlgen.Protocol) *Protocol {
 		var requestPayloadArgs []Parameter
 		requestFlattened := true
 		if v.RequestPayload != nil {
-			requestTypeShapeV1 = v.RequestPayload.TypeShapeV1
-			requestTypeShapeV2 = v.RequestPayload.TypeShapeV2
+			requestTypeShapeV1 = v.RequestPayload.Shape(fidlgen.WireFormatVersionV1)
+			requestTypeShapeV2 = v.RequestPayload.Shape(fidlgen.WireFormatVersionV2)
 			if _, ok := c.messageBodyTypes[v.RequestPayload.Identifier]; ok {
 				requestPayloadName = v.RequestPayload.Identifier
 				if val, ok := c.messageBodyStructs[v.RequestPayload.Identifier]; ok {
@@ -1145,8 +1143,8 @@ func (c *compiler) compileProtocol(p fidlgen.Protocol) *Protocol {
 		var responsePayloadArgs []Parameter
 		responseFlattened := true
 		if v.ResponsePayload != nil {
-			responseTypeShapeV1 = v.ResponsePayload.TypeShapeV1
-			responseTypeShapeV2 = v.ResponsePayload.TypeShapeV2
+			responseTypeShapeV1 = v.ResponsePayload.Shape(fidlgen.WireFormatVersionV1)
+			responseTypeShapeV2 = v.ResponsePayload.Shape(fidlgen.WireFormatVersionV2)
 			if _, ok := c.messageBodyTypes[v.ResponsePayload.Identifier]; ok {
 				responsePayloadName = v.ResponsePayload.Identifier
 				if val, ok := c.messageBodyStructs[v.ResponsePayload.Identifier]; ok {
@@ -1304,8 +1302,8 @@ func (c *compiler) compileParameterArray(val fidlgen.Struct) []Parameter {
 		params = append(params, Parameter{
 			Type:              c.compileType(v.Type),
 			nameVariants:      structMemberContext.transform(v.Name),
-			OffsetV1:          v.FieldShapeV1.Offset,
-			OffsetV2:          v.FieldShapeV2.Offset,
+			OffsetV1:          v.FieldShape(fidlgen.WireFormatVersionV1).Offset,
+			OffsetV2:          v.FieldShape(fidlgen.WireFormatVersionV2).Offset,
 			HandleInformation: c.fieldHandleInformation(&v.Type),
 		})
 	}
@@ -1330,8 +1328,6 @@ const (
 // This value needs to be kept in sync with the one defined in
 // sdk/lib/fidl/cpp/wire/include/lib/fidl/cpp/wire/sync_call.h
 const llcppMaxStackAllocSize = 512
-const channelMaxMessageSize = 65536
-const channelMaxHandleCount = 64
 
 // allocation describes the allocation strategy of some operation, such as
 // sending requests, receiving responses, or handling events. Note that the
@@ -1369,20 +1365,20 @@ func computeAllocation(maxTotalSize int, maxTotalNumHandles int, boundedness bou
 	var numBytes int
 	var numHandles int
 	if boundedness == boundednessUnbounded {
-		numBytes = channelMaxMessageSize
-		numHandles = channelMaxHandleCount
+		numBytes = fidlgen.ChannelMaxMessageBytes
+		numHandles = fidlgen.ChannelMaxMessageHandles
 	} else {
-		numBytes = maxTotalSize + kMessageHeaderSize
+		numBytes = maxTotalSize + fidlgen.MessageHeaderSize
 		numHandles = maxTotalNumHandles
 	}
-	if numBytes >= channelMaxMessageSize {
-		numBytes = channelMaxMessageSize
+	if numBytes >= fidlgen.ChannelMaxMessageBytes {
+		numBytes = fidlgen.ChannelMaxMessageBytes
 		sizeString = "ZX_CHANNEL_MAX_MSG_BYTES"
 	} else {
 		sizeString = fmt.Sprintf("%d", numBytes)
 	}
-	if numHandles > channelMaxHandleCount {
-		numHandles = channelMaxHandleCount
+	if numHandles > fidlgen.ChannelMaxMessageHandles {
+		numHandles = fidlgen.ChannelMaxMessageHandles
 	}
 
 	if numBytes > llcppMaxStackAllocSize {
@@ -39,9 +39,12 @@ type ServiceMember struct {
 }
 
 func (c *compiler) compileService(val fidlgen.Service) *Service {
-	t := "Channel"
+	if err := val.Validate(); err != nil {
+		panic(err)
+	}
+	t := fidlgen.ChannelTransport
 	if len(val.Members) > 0 {
-		t = val.Members[0].Type.ProtocolTransport
+		t, _ = val.Members[0].MemberTransport()
 	}
 
 	transport, ok := transports[t]
@@ -62,13 +65,14 @@ func (c *compiler) compileService(val fidlgen.Service) *Service {
 }
 
 func (c *compiler) compileServiceMember(val fidlgen.ServiceMember, s *Service) ServiceMember {
+	clientEnd, _ := val.ClientEnd()
 	return ServiceMember{
 		Attributes:        Attributes{val.Attributes},
 		nameVariants:      serviceMemberContext.transform(val.Name),
 		ClassName:         serviceMemberTypeContext.transform(val.Name),
 		Service:           s,
-		ProtocolType:      c.compileNameVariants(val.Type.Identifier),
-		ProtocolTransport: *transports[val.Type.ProtocolTransport],
+		ProtocolType:      c.compileNameVariants(clientEnd.Protocol),
+		ProtocolTransport: *transports[clientEnd.Transport],
 	}
 }
 
@@ -70,6 +70,10 @@ func (s *Struct) IsEmpty() bool {
 var _ Kinded = (*Struct)(nil)
 var _ namespaced = (*Struct)(nil)
 
+// emptyStructRepresentation gives C++ empty structs a placeholder member, as
+// C++ has no zero-sized types.
+var emptyStructRepresentation = fidlgen.EmptyStructRepresentation{PlaceholderName: "__reserved"}
+
 type StructMember struct {
 	Attributes
 	nameVariants
@@ -154,8 +158,8 @@ func (c *compiler) compileStructMember(val fidlgen.StructMember) StructMember {
 		nameVariants:      structMemberContext.transform(val.Name),
 		Type:              t,
 		DefaultValue:      defaultValue,
-		OffsetV1:          val.FieldShapeV1.Offset,
-		OffsetV2:          val.FieldShapeV2.Offset,
+		OffsetV1:          val.FieldShape(fidlgen.WireFormatVersionV1).Offset,
+		OffsetV2:          val.FieldShape(fidlgen.WireFormatVersionV2).Offset,
 		HandleInformation: c.fieldHandleInformation(&val.Type),
 		NaturalConstraint: t.NaturalFieldConstraint,
 		WireConstraint:    t.WireFieldConstraint,
@@ -207,17 +211,17 @@ func (c *compiler) compileStruct(val fidlgen.Struct) *Struct {
 	r := Struct{
 		Attributes:        Attributes{val.Attributes},
 		AnonymousChildren: c.getAnonymousChildren(val.LayoutDecl),
-		TypeShapeV1:       TypeShape{val.TypeShapeV1},
-		TypeShapeV2:       TypeShape{val.TypeShapeV2},
+		TypeShapeV1:       TypeShape{val.Shape(fidlgen.WireFormatVersionV1)},
+		TypeShapeV2:       TypeShape{val.Shape(fidlgen.WireFormatVersionV2)},
 		Resourceness:      val.Resourceness,
 		nameVariants:      name,
 		CodingTableType:   codingTableType,
 		Members:           []StructMember{},
 		BackingBufferTypeV1: computeAllocation(
-			TypeShape{val.TypeShapeV1}.MaxTotalSize(), TypeShape{val.TypeShapeV1}.MaxHandles, boundednessBounded).
+			TypeShape{val.Shape(fidlgen.WireFormatVersionV1)}.MaxTotalSize(), TypeShape{val.Shape(fidlgen.WireFormatVersionV1)}.MaxHandles, boundednessBounded).
 			BackingBufferType(),
 		BackingBufferTypeV2: computeAllocation(
-			TypeShape{val.TypeShapeV2}.MaxTotalSize(), TypeShape{val.TypeShapeV2}.MaxHandles, boundednessBounded).
+			TypeShape{val.Shape(fidlgen.WireFormatVersionV2)}.MaxTotalSize(), TypeShape{val.Shape(fidlgen.WireFormatVersionV2)}.MaxHandles, boundednessBounded).
 			BackingBufferType(),
 		IsInResult: false,
 		PaddingV1:  toStructPaddings(val.BuildPaddingMarkers(fidlgen.WireFormatVersionV1)),
@@ -228,11 +232,9 @@ func (c *compiler) compileStruct(val fidlgen.Struct) *Struct {
 		r.Members = append(r.Members, c.compileStructMember(v))
 	}
 
-	if len(r.Members) == 0 {
+	if placeholder, ok := emptyStructRepresentation.Placeholder(val); ok {
 		r.isEmptyStruct = true
-		r.Members = []StructMember{
-			c.compileStructMember(fidlgen.EmptyStructMember("__reserved")),
-		}
+		r.Members = []StructMember{c.compileStructMember(placeholder)}
 	}
 
 	// Construct a deduped list of decls for IsMemcpyCompatible template definitions.
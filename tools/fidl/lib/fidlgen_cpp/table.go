@@ -136,17 +136,17 @@ func (c *compiler) compileTable(val fidlgen.Table) *Table {
 		TableName:         TableName{nameVariants: name},
 		Attributes:        Attributes{val.Attributes},
 		AnonymousChildren: c.getAnonymousChildren(val.LayoutDecl),
-		TypeShapeV1:       TypeShape{val.TypeShapeV1},
-		TypeShapeV2:       TypeShape{val.TypeShapeV2},
+		TypeShapeV1:       TypeShape{val.Shape(fidlgen.WireFormatVersionV1)},
+		TypeShapeV2:       TypeShape{val.Shape(fidlgen.WireFormatVersionV2)},
 		Resourceness:      val.Resourceness,
 		CodingTableType:   codingTableType,
 		Members:           nil,
 		BiggestOrdinal:    0,
 		BackingBufferTypeV1: computeAllocation(
-			TypeShape{val.TypeShapeV1}.MaxTotalSize(), TypeShape{val.TypeShapeV1}.MaxHandles, boundednessBounded).
+			TypeShape{val.Shape(fidlgen.WireFormatVersionV1)}.MaxTotalSize(), TypeShape{val.Shape(fidlgen.WireFormatVersionV1)}.MaxHandles, boundednessBounded).
 			BackingBufferType(),
 		BackingBufferTypeV2: computeAllocation(
-			TypeShape{val.TypeShapeV2}.MaxTotalSize(), TypeShape{val.TypeShapeV2}.MaxHandles, boundednessBounded).
+			TypeShape{val.Shape(fidlgen.WireFormatVersionV2)}.MaxTotalSize(), TypeShape{val.Shape(fidlgen.WireFormatVersionV2)}.MaxHandles, boundednessBounded).
 			BackingBufferType(),
 		WireTableFrame:           WireTableFrame.template(name.Wire),
 		WireTableBuilder:         WireTableBuilder.template(name.Wire),
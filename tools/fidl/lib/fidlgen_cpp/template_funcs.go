@@ -126,7 +126,7 @@ func ensureCodingTableDecl(codingTable *name) string {
 var currentTransport *Transport
 
 func setTransport(name string) string {
-	if transport, ok := transports[name]; ok {
+	if transport, ok := transports[fidlgen.Transport(name)]; ok {
 		currentTransport = transport
 		return ""
 	}
@@ -95,8 +95,8 @@ func (c *compiler) compileUnion(val fidlgen.Union) *Union {
 	u := Union{
 		UnionName:          UnionName{nameVariants: name},
 		Attributes:         Attributes{val.Attributes},
-		TypeShapeV1:        TypeShape{val.TypeShapeV1},
-		TypeShapeV2:        TypeShape{val.TypeShapeV2},
+		TypeShapeV1:        TypeShape{val.Shape(fidlgen.WireFormatVersionV1)},
+		TypeShapeV2:        TypeShape{val.Shape(fidlgen.WireFormatVersionV2)},
 		AnonymousChildren:  c.getAnonymousChildren(val.LayoutDecl),
 		Strictness:         val.Strictness,
 		Resourceness:       val.Resourceness,
@@ -107,10 +107,10 @@ func (c *compiler) compileUnion(val fidlgen.Union) *Union {
 		WireOrdinalEnum:    wireOrdinalEnum,
 		WireInvalidOrdinal: wireOrdinalEnum.nest("Invalid"),
 		BackingBufferTypeV1: computeAllocation(
-			TypeShape{val.TypeShapeV1}.MaxTotalSize(), TypeShape{val.TypeShapeV1}.MaxHandles, boundednessBounded).
+			TypeShape{val.Shape(fidlgen.WireFormatVersionV1)}.MaxTotalSize(), TypeShape{val.Shape(fidlgen.WireFormatVersionV1)}.MaxHandles, boundednessBounded).
 			BackingBufferType(),
 		BackingBufferTypeV2: computeAllocation(
-			TypeShape{val.TypeShapeV2}.MaxTotalSize(), TypeShape{val.TypeShapeV2}.MaxHandles, boundednessBounded).
+			TypeShape{val.Shape(fidlgen.WireFormatVersionV2)}.MaxTotalSize(), TypeShape{val.Shape(fidlgen.WireFormatVersionV2)}.MaxHandles, boundednessBounded).
 			BackingBufferType(),
 	}
 
@@ -149,7 +149,7 @@ func (c *compiler) compileResult(p Payloader, m *fidlgen.Method) *Result {
 		ValueTypeDecl:     valueType.nameVariants,
 		Value:             valueType,
 		HasError:          m.HasError,
-		HasTransportError: m.HasTransportError(),
+		HasTransportError: m.HasFrameworkError(),
 		valueTypeIsStruct: false,
 	}
 	if m.HasError {
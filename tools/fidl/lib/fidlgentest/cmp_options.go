@@ -0,0 +1,47 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgentest
+
+import (
+	"reflect"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+var fidlgenPkgPath = reflect.TypeOf(fidlgen.Root{}).PkgPath()
+
+// AllowUnexported lets go-cmp compare the unexported fields of IR types, e.g.
+// those of the unknown value of enums, which it otherwise panics on.
+func AllowUnexported() cmp.Option {
+	return cmp.Exporter(func(t reflect.Type) bool {
+		return t.PkgPath() == fidlgenPkgPath
+	})
+}
+
+// IgnoreShapes ignores the type and field shapes computed by fidlc, which are
+// noisy to spell out in expectations of tests not concerned with layout.
+func IgnoreShapes() cmp.Option {
+	return cmpopts.IgnoreTypes(fidlgen.TypeShape{}, fidlgen.FieldShape{})
+}
+
+// IgnoreLocations ignores source locations.
+func IgnoreLocations() cmp.Option {
+	return cmpopts.IgnoreTypes(fidlgen.Location{})
+}
+
+// IgnoreDerivedFields ignores the fields of types that are not part of the
+// JSON IR, but derived from it when decoding it, i.e. Type.Boxed and
+// Type.Endpoint. This lets expectations be written for IR built by hand.
+func IgnoreDerivedFields() cmp.Option {
+	return cmpopts.IgnoreFields(fidlgen.Type{}, "Boxed", "Endpoint")
+}
+
+// IROptions combines the options above, comparing IR values by their
+// substance only.
+func IROptions() cmp.Options {
+	return cmp.Options{AllowUnexported(), IgnoreShapes(), IgnoreLocations(), IgnoreDerivedFields()}
+}
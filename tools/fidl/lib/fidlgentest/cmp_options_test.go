@@ -0,0 +1,47 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlgentest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestIROptions(t *testing.T) {
+	decoded := fidlgen.Enum{
+		LayoutDecl: fidlgen.LayoutDecl{
+			Decl: fidlgen.Decl{
+				Name:     "example/E",
+				Location: fidlgen.Location{Filename: "example.fidl", Line: 3},
+			},
+		},
+		Type:            fidlgen.Uint8,
+		RawUnknownValue: fidlgen.Int64OrUint64FromUint64ForTesting(255),
+	}
+	expected := decoded
+	expected.Location = fidlgen.Location{}
+	if diff := cmp.Diff(expected, decoded, IROptions()); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+
+	expected.RawUnknownValue = fidlgen.Int64OrUint64FromUint64ForTesting(0)
+	if cmp.Equal(expected, decoded, IROptions()) {
+		t.Errorf("expected the unknown values to be compared")
+	}
+
+	member := fidlgen.StructMember{
+		Type:         fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/S", Nullable: true, Boxed: true, TypeShapeV2: fidlgen.TypeShape{InlineSize: 8}},
+		Name:         "s",
+		FieldShapeV2: fidlgen.FieldShape{Offset: 8},
+	}
+	if diff := cmp.Diff(fidlgen.StructMember{
+		Type: fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: "example/S", Nullable: true},
+		Name: "s",
+	}, member, IROptions()); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package irtestdata provides a corpus of small JSON IR fixtures, each
+// exercising a single feature of FIDL, for tests of fidlgen and of backends
+// that do not need to compile FIDL to get realistic IR.
+//
+// Fixtures are hand-maintained to match what fidlc emits, trimmed to the
+// declarations of interest. When a fixture changes in a way that tests may
+// observe, bump CorpusVersion.
+package irtestdata
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// CorpusVersion identifies the revision of the corpus.
+const CorpusVersion = 2
+
+const fixtureSuffix = ".fidl.json"
+
+// The fixtures in the corpus.
+const (
+	// ReservedUnion is library test.reservedunion:
+	//
+	//	type U = flexible union {
+	//	    1: reserved;
+	//	    2: a int32;
+	//	    3: reserved;
+	//	    4: b string;
+	//	};
+	ReservedUnion = "reserved_union"
+
+	// OpenProtocol is library test.openprotocol, with the
+	// unknown_interactions experiment:
+	//
+	//	open protocol P {
+	//	    flexible OneWay();
+	//	    strict TwoWay() -> ();
+	//	    flexible FlexibleTwoWay() -> ();
+	//	    flexible -> OnEvent();
+	//	};
+	OpenProtocol = "open_protocol"
+
+	// NewType is library test.newtype, with the allow_new_types experiment:
+	//
+	//	type Id = uint64;
+	//	type Wrapper = struct {
+	//	    id Id;
+	//	};
+	NewType = "new_type"
+
+	// DriverTransport is library test.drivertransport:
+	//
+	//	@transport("Driver")
+	//	protocol Device {
+	//	    Ping() -> ();
+	//	};
+	//	type Ends = resource struct {
+	//	    client client_end:Device;
+	//	    server server_end:Device;
+	//	};
+	DriverTransport = "driver_transport"
+)
+
+//go:embed *.fidl.json
+var corpus embed.FS
+
+// FS returns the corpus, as files named after the fixtures with a ".fidl.json"
+// suffix.
+func FS() fs.FS {
+	return corpus
+}
+
+// Names returns the names of all fixtures, sorted.
+func Names() []string {
+	entries, err := corpus.ReadDir(".")
+	if err != nil {
+		panic(fmt.Sprintf("reading the embedded corpus: %s", err))
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), fixtureSuffix))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Read decodes a fixture, as fidlgen.ReadJSONIr would.
+func Read(name string) (fidlgen.Root, error) {
+	b, err := corpus.ReadFile(name + fixtureSuffix)
+	if err != nil {
+		return fidlgen.Root{}, fmt.Errorf("unknown fixture %q: %w", name, err)
+	}
+	root, err := fidlgen.ReadJSONIrContent(b)
+	if err != nil {
+		return fidlgen.Root{}, fmt.Errorf("fixture %q: %w", name, err)
+	}
+	return root, nil
+}
+
+// Load is as Read, but fails the test on error.
+func Load(t testing.TB, name string) fidlgen.Root {
+	t.Helper()
+	root, err := Read(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
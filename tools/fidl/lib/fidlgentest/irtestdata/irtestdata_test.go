@@ -0,0 +1,74 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package irtestdata
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func TestNames(t *testing.T) {
+	expected := []string{DriverTransport, NewType, OpenProtocol, ReservedUnion}
+	if diff := cmp.Diff(expected, Names()); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			root := Load(t, name)
+			var decls []fidlgen.EncodedCompoundIdentifier
+			root.ForEachDecl(func(decl fidlgen.Declaration) {
+				decls = append(decls, decl.GetName())
+				if _, ok := root.Decls[decl.GetName()]; !ok {
+					t.Errorf("%s is missing from declarations", decl.GetName())
+				}
+			})
+			if len(decls) != len(root.Decls) || len(decls) != len(root.DeclOrder) {
+				t.Errorf("got %d declarations, but %d in declarations and %d in declaration_order",
+					len(decls), len(root.Decls), len(root.DeclOrder))
+			}
+		})
+	}
+
+	if _, err := Read("unknown"); err == nil {
+		t.Errorf("expected an error for an unknown fixture")
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	union := Load(t, ReservedUnion).Unions[0]
+	if len(union.Members) != 4 || !union.Members[0].Reserved || !union.Members[2].Reserved || union.IsStrict() {
+		t.Errorf("%s: expected a flexible union with reserved members, got %+v", ReservedUnion, union)
+	}
+
+	protocol := Load(t, OpenProtocol).Protocols[0]
+	if protocol.Openness != fidlgen.Open {
+		t.Errorf("%s: expected an open protocol", OpenProtocol)
+	}
+	for _, m := range protocol.Methods {
+		if m.Name == "FlexibleTwoWay" && !m.HasFrameworkError() {
+			t.Errorf("%s: expected FlexibleTwoWay to have a transport error", OpenProtocol)
+		}
+	}
+
+	newType := Load(t, NewType)
+	if len(newType.NewTypes) != 1 || newType.NewTypes[0].Type.PrimitiveSubtype != fidlgen.Uint64 {
+		t.Errorf("%s: expected a new type over uint64, got %+v", NewType, newType.NewTypes)
+	}
+
+	driver := Load(t, DriverTransport)
+	if _, ok := driver.Protocols[0].Transports()["Driver"]; !ok {
+		t.Errorf("%s: expected a protocol over the driver transport", DriverTransport)
+	}
+	for _, m := range driver.Structs[0].Members {
+		if m.Type.ProtocolTransport != "Driver" {
+			t.Errorf("%s: expected %s to be a driver endpoint, got %+v", DriverTransport, m.Name, m.Type)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// Package fidlquery selects the declarations and methods of a FIDL program
+// that match a query, e.g. to audit the platform's APIs without writing a new
+// analysis each time.
+//
+// A query names the kind of element to select, optionally followed by filters
+// in brackets, all of which must hold:
+//
+//	methods[unbounded]
+//	protocols[composes=fuchsia.unknown/Queryable][!library=fuchsia.unknown]
+//	unions[flexible][name~^fuchsia\.io/]
+//
+// The kinds are decls (all declarations), consts, bits, enums, structs,
+// tables, unions, protocols and methods. The filters are:
+//
+//	name~REGEXP     the fully qualified name matches REGEXP
+//	library=NAME    the element belongs to library NAME
+//	attribute=NAME  the element carries attribute NAME
+//	composes=NAME   the protocol composes protocol NAME, transitively
+//	unbounded       the element (for methods, one of its payloads) holds an
+//	                unbounded vector or string, transitively
+//	strict          the element is strict
+//	flexible        the element is flexible
+//	resource        the element is a resource type
+//
+// Any filter can be negated with a leading `!`.
+package fidlquery
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// Kind is a kind of element a query can select.
+type Kind string
+
+const (
+	Decls     Kind = "decls"
+	Consts    Kind = "consts"
+	Bits      Kind = "bits"
+	Enums     Kind = "enums"
+	Structs   Kind = "structs"
+	Tables    Kind = "tables"
+	Unions    Kind = "unions"
+	Protocols Kind = "protocols"
+	Methods   Kind = "methods"
+)
+
+// Match is an element selected by a query.
+type Match struct {
+	// Library is the name of the library declaring the element.
+	Library fidlgen.EncodedLibraryIdentifier `json:"library"`
+	// Name is the fully qualified name of the element. Methods are named after
+	// their protocol, e.g. `fuchsia.io/Node.Close`.
+	Name string `json:"name"`
+	// Kind is the kind of declaration of the element, or "method".
+	Kind string `json:"kind"`
+	// Path locates the element in the JSON IR of its library, in JSONPath
+	// notation, e.g. `$.protocol_declarations[2].methods[0]`.
+	Path string `json:"path"`
+	// Location is where the element is declared, if known.
+	Location *fidlgen.Location `json:"location,omitempty"`
+}
+
+// Query is a parsed query.
+type Query struct {
+	Kind    Kind
+	filters []filter
+}
+
+// element is a candidate for selection.
+type element struct {
+	decl fidlgen.Declaration
+	// method is set if the element is a method of the decl protocol.
+	method *fidlgen.Method
+}
+
+type filter func(p *fidlgen.Program, e element) bool
+
+var queryPattern = regexp.MustCompile(`^([a-z]+)((?:\[[^\]]*\])*)$`)
+
+// Parse parses a query.
+func Parse(query string) (Query, error) {
+	m := queryPattern.FindStringSubmatch(strings.TrimSpace(query))
+	if m == nil {
+		return Query{}, fmt.Errorf("malformed query: %q", query)
+	}
+	q := Query{Kind: Kind(m[1])}
+	switch q.Kind {
+	case Decls, Consts, Bits, Enums, Structs, Tables, Unions, Protocols, Methods:
+	default:
+		return Query{}, fmt.Errorf("unknown kind in query %q: %s", query, q.Kind)
+	}
+	if m[2] != "" {
+		for _, text := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(m[2], "["), "]"), "][") {
+			f, err := parseFilter(text)
+			if err != nil {
+				return Query{}, fmt.Errorf("in query %q: %w", query, err)
+			}
+			q.filters = append(q.filters, f)
+		}
+	}
+	return q, nil
+}
+
+func parseFilter(text string) (filter, error) {
+	if negated := strings.TrimPrefix(text, "!"); negated != text {
+		f, err := parseFilter(negated)
+		if err != nil {
+			return nil, err
+		}
+		return func(p *fidlgen.Program, e element) bool {
+			return !f(p, e)
+		}, nil
+	}
+
+	if pattern := strings.TrimPrefix(text, "name~"); pattern != text {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return func(_ *fidlgen.Program, e element) bool {
+			return re.MatchString(e.name())
+		}, nil
+	}
+	if parts := strings.SplitN(text, "=", 2); len(parts) == 2 {
+		name, value := parts[0], parts[1]
+		switch name {
+		case "library":
+			return func(_ *fidlgen.Program, e element) bool {
+				return string(e.decl.GetName().LibraryName()) == value
+			}, nil
+		case "attribute":
+			return func(_ *fidlgen.Program, e element) bool {
+				if e.method != nil {
+					return e.method.HasAttribute(fidlgen.Identifier(value))
+				}
+				return e.decl.GetAttributes().HasAttribute(fidlgen.Identifier(value))
+			}, nil
+		case "composes":
+			return func(p *fidlgen.Program, e element) bool {
+				protocol, ok := e.decl.(*fidlgen.Protocol)
+				if !ok || e.method != nil {
+					return false
+				}
+				return composes(p, protocol, fidlgen.EncodedCompoundIdentifier(value), map[fidlgen.EncodedCompoundIdentifier]bool{})
+			}, nil
+		}
+		return nil, fmt.Errorf("unknown filter: %s", name)
+	}
+
+	switch text {
+	case "unbounded":
+		return func(p *fidlgen.Program, e element) bool {
+			return e.unbounded(p)
+		}, nil
+	case "strict", "flexible":
+		return func(_ *fidlgen.Program, e element) bool {
+			strict, ok := e.strict()
+			return ok && strict == (text == "strict")
+		}, nil
+	case "resource":
+		return func(_ *fidlgen.Program, e element) bool {
+			decl, ok := e.decl.(fidlgen.ResourceableLayoutDeclaration)
+			return ok && e.method == nil && decl.GetResourceness().IsResourceType()
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown filter: %s", text)
+}
+
+// Run evaluates the query against a program, calling a provided callback on
+// each match as it is found. Libraries are visited in order of name and, within
+// a library, elements in IR order. Run stops at the first error returned by the
+// callback.
+func (q Query) Run(p *fidlgen.Program, cb func(Match) error) error {
+	var err error
+	p.ForEachLibrary(func(root *fidlgen.Root) {
+		if err != nil {
+			return
+		}
+		err = q.runLibrary(p, root, cb)
+	})
+	return err
+}
+
+func (q Query) runLibrary(p *fidlgen.Program, root *fidlgen.Root, cb func(Match) error) error {
+	visit := func(e element, path string) error {
+		for _, f := range q.filters {
+			if !f(p, e) {
+				return nil
+			}
+		}
+		m := Match{
+			Library: root.Name,
+			Name:    e.name(),
+			Kind:    "method",
+			Path:    path,
+		}
+		if e.method == nil {
+			m.Kind = string(fidlgen.GetDeclType(e.decl))
+			loc := e.decl.GetLocation()
+			m.Location = &loc
+		}
+		return cb(m)
+	}
+
+	for _, section := range q.sections(root) {
+		for i, decl := range section.decls {
+			path := fmt.Sprintf("$.%s[%d]", section.key, i)
+			if q.Kind != Methods {
+				if err := visit(element{decl: decl}, path); err != nil {
+					return err
+				}
+				continue
+			}
+			protocol := decl.(*fidlgen.Protocol)
+			for j := range protocol.Methods {
+				e := element{decl: decl, method: &protocol.Methods[j]}
+				if err := visit(e, fmt.Sprintf("%s.methods[%d]", path, j)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+type section struct {
+	// key is the JSON IR key of the section.
+	key   string
+	decls []fidlgen.Declaration
+}
+
+// sections returns the sections of the IR holding the kind of elements the
+// query selects.
+func (q Query) sections(root *fidlgen.Root) []section {
+	var sections []section
+	add := func(kinds []Kind, key string, n int, get func(int) fidlgen.Declaration) {
+		for _, kind := range kinds {
+			if kind == q.Kind {
+				s := section{key: key}
+				for i := 0; i < n; i++ {
+					s.decls = append(s.decls, get(i))
+				}
+				sections = append(sections, s)
+			}
+		}
+	}
+	add([]Kind{Decls, Consts}, "const_declarations", len(root.Consts), func(i int) fidlgen.Declaration { return &root.Consts[i] })
+	add([]Kind{Decls, Bits}, "bits_declarations", len(root.Bits), func(i int) fidlgen.Declaration { return &root.Bits[i] })
+	add([]Kind{Decls, Enums}, "enum_declarations", len(root.Enums), func(i int) fidlgen.Declaration { return &root.Enums[i] })
+	add([]Kind{Decls}, "experimental_resource_declarations", len(root.Resources), func(i int) fidlgen.Declaration { return &root.Resources[i] })
+	add([]Kind{Decls, Protocols, Methods}, "protocol_declarations", len(root.Protocols), func(i int) fidlgen.Declaration { return &root.Protocols[i] })
+	add([]Kind{Decls}, "service_declarations", len(root.Services), func(i int) fidlgen.Declaration { return &root.Services[i] })
+	add([]Kind{Decls, Structs}, "struct_declarations", len(root.Structs), func(i int) fidlgen.Declaration { return &root.Structs[i] })
+	add([]Kind{Decls, Tables}, "table_declarations", len(root.Tables), func(i int) fidlgen.Declaration { return &root.Tables[i] })
+	add([]Kind{Decls, Unions}, "union_declarations", len(root.Unions), func(i int) fidlgen.Declaration { return &root.Unions[i] })
+	add([]Kind{Decls}, "type_alias_declarations", len(root.TypeAliases), func(i int) fidlgen.Declaration { return &root.TypeAliases[i] })
+	add([]Kind{Decls}, "new_type_declarations", len(root.NewTypes), func(i int) fidlgen.Declaration { return &root.NewTypes[i] })
+	return sections
+}
+
+func (e element) name() string {
+	if e.method != nil {
+		return fmt.Sprintf("%s.%s", e.decl.GetName(), e.method.Name)
+	}
+	return string(e.decl.GetName())
+}
+
+// strict returns whether the element is strict, if it has a strictness.
+func (e element) strict() (bool, bool) {
+	if e.method != nil {
+		return e.method.IsStrict(), true
+	}
+	switch decl := e.decl.(type) {
+	case *fidlgen.Bits:
+		return decl.IsStrict(), true
+	case *fidlgen.Enum:
+		return decl.IsStrict(), true
+	case *fidlgen.Union:
+		return decl.IsStrict(), true
+	}
+	return false, false
+}
+
+func (e element) unbounded(p *fidlgen.Program) bool {
+	seen := map[fidlgen.EncodedCompoundIdentifier]bool{}
+	if e.method != nil {
+		for _, typ := range []*fidlgen.Type{e.method.RequestPayload, e.method.ResponsePayload} {
+			if typ != nil && unboundedType(p, *typ, seen) {
+				return true
+			}
+		}
+		return false
+	}
+	if protocol, ok := e.decl.(*fidlgen.Protocol); ok {
+		for i := range protocol.Methods {
+			if (element{decl: protocol, method: &protocol.Methods[i]}).unbounded(p) {
+				return true
+			}
+		}
+		return false
+	}
+	return unboundedDecl(p, e.decl, seen)
+}
+
+// unboundedType returns whether a type holds an unbounded vector or string.
+// Declarations in seen have already been (or are being) inspected.
+func unboundedType(p *fidlgen.Program, typ fidlgen.Type, seen map[fidlgen.EncodedCompoundIdentifier]bool) bool {
+	switch typ.Kind {
+	case fidlgen.StringType:
+		return typ.ElementCount == nil
+	case fidlgen.VectorType:
+		return typ.ElementCount == nil || unboundedType(p, *typ.ElementType, seen)
+	case fidlgen.ArrayType:
+		return unboundedType(p, *typ.ElementType, seen)
+	case fidlgen.IdentifierType:
+		if seen[typ.Identifier] {
+			return false
+		}
+		seen[typ.Identifier] = true
+		decl, ok := p.LookupDecl(typ.Identifier)
+		return ok && unboundedDecl(p, decl, seen)
+	}
+	return false
+}
+
+func unboundedDecl(p *fidlgen.Program, decl fidlgen.Declaration, seen map[fidlgen.EncodedCompoundIdentifier]bool) bool {
+	seen[decl.GetName()] = true
+	var types []fidlgen.Type
+	switch decl := decl.(type) {
+	case *fidlgen.Struct:
+		for _, m := range decl.Members {
+			types = append(types, m.Type)
+		}
+	case *fidlgen.Table:
+		for _, m := range decl.Members {
+			if !m.Reserved {
+				types = append(types, m.Type)
+			}
+		}
+	case *fidlgen.Union:
+		for _, m := range decl.Members {
+			if !m.Reserved {
+				types = append(types, m.Type)
+			}
+		}
+	case *fidlgen.NewType:
+		types = append(types, decl.Type)
+	}
+	for _, typ := range types {
+		if unboundedType(p, typ, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// composes returns whether a protocol composes another, transitively.
+func composes(p *fidlgen.Program, protocol *fidlgen.Protocol, name fidlgen.EncodedCompoundIdentifier, seen map[fidlgen.EncodedCompoundIdentifier]bool) bool {
+	for _, composed := range protocol.Composed {
+		if composed.Name == name {
+			return true
+		}
+		if seen[composed.Name] {
+			continue
+		}
+		seen[composed.Name] = true
+		if decl, ok := p.LookupDecl(composed.Name); ok {
+			if composed, ok := decl.(*fidlgen.Protocol); ok && composes(p, composed, name, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
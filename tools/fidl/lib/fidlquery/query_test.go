@@ -0,0 +1,164 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package fidlquery
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+func structDecl(name fidlgen.EncodedCompoundIdentifier, types ...fidlgen.Type) fidlgen.Struct {
+	s := fidlgen.Struct{
+		ResourceableLayoutDecl: fidlgen.ResourceableLayoutDecl{
+			LayoutDecl: fidlgen.LayoutDecl{Decl: fidlgen.Decl{Name: name}},
+		},
+	}
+	for _, typ := range types {
+		s.Members = append(s.Members, fidlgen.StructMember{Type: typ})
+	}
+	return s
+}
+
+func protocolDecl(name fidlgen.EncodedCompoundIdentifier, composed []fidlgen.EncodedCompoundIdentifier, methods ...fidlgen.Method) fidlgen.Protocol {
+	p := fidlgen.Protocol{Decl: fidlgen.Decl{Name: name}, Methods: methods}
+	for _, c := range composed {
+		p.Composed = append(p.Composed, fidlgen.Decl{Name: c})
+	}
+	return p
+}
+
+func identifierType(name fidlgen.EncodedCompoundIdentifier) *fidlgen.Type {
+	return &fidlgen.Type{Kind: fidlgen.IdentifierType, Identifier: name}
+}
+
+func testProgram(t *testing.T) *fidlgen.Program {
+	t.Helper()
+	bound := 10
+	bytes := fidlgen.Type{
+		Kind:        fidlgen.VectorType,
+		ElementType: &fidlgen.Type{Kind: fidlgen.PrimitiveType, PrimitiveSubtype: fidlgen.Uint8},
+	}
+	name := fidlgen.Type{Kind: fidlgen.StringType, ElementCount: &bound}
+	flexible := false
+
+	p, err := fidlgen.NewProgram([]fidlgen.Root{
+		{
+			Name: "fuchsia.unknown",
+			Protocols: []fidlgen.Protocol{
+				protocolDecl("fuchsia.unknown/Queryable", nil, fidlgen.Method{Name: "Query"}),
+			},
+		},
+		{
+			Name: "fuchsia.example",
+			Structs: []fidlgen.Struct{
+				structDecl("fuchsia.example/WriteRequest", bytes),
+				structDecl("fuchsia.example/Wrapper", *identifierType("fuchsia.example/WriteRequest")),
+				structDecl("fuchsia.example/NameRequest", name),
+			},
+			Protocols: []fidlgen.Protocol{
+				protocolDecl("fuchsia.example/File", []fidlgen.EncodedCompoundIdentifier{"fuchsia.example/Node"},
+					fidlgen.Method{Name: "Write", RequestPayload: identifierType("fuchsia.example/WriteRequest")},
+					fidlgen.Method{Name: "SetName", RequestPayload: identifierType("fuchsia.example/NameRequest"), MaybeStrict: &flexible},
+				),
+				protocolDecl("fuchsia.example/Node", []fidlgen.EncodedCompoundIdentifier{"fuchsia.unknown/Queryable"}),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func runQuery(t *testing.T, p *fidlgen.Program, query string) []Match {
+	t.Helper()
+	q, err := Parse(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var matches []Match
+	if err := q.Run(p, func(m Match) error {
+		matches = append(matches, m)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestQueryNames(t *testing.T) {
+	p := testProgram(t)
+	for _, tc := range []struct {
+		query    string
+		expected []string
+	}{
+		{
+			query:    "methods[unbounded]",
+			expected: []string{"fuchsia.example/File.Write"},
+		},
+		{
+			query:    "methods[flexible]",
+			expected: []string{"fuchsia.example/File.SetName"},
+		},
+		{
+			query:    "structs[unbounded]",
+			expected: []string{"fuchsia.example/WriteRequest", "fuchsia.example/Wrapper"},
+		},
+		{
+			query:    "protocols[composes=fuchsia.unknown/Queryable]",
+			expected: []string{"fuchsia.example/File", "fuchsia.example/Node"},
+		},
+		{
+			query:    "protocols[!composes=fuchsia.unknown/Queryable]",
+			expected: []string{"fuchsia.unknown/Queryable"},
+		},
+		{
+			query:    `decls[library=fuchsia.example][name~Request$]`,
+			expected: []string{"fuchsia.example/WriteRequest", "fuchsia.example/NameRequest"},
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			var names []string
+			for _, m := range runQuery(t, p, tc.query) {
+				names = append(names, m.Name)
+			}
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Errorf("unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	matches := runQuery(t, testProgram(t), "methods[name~SetName]")
+	expected := []Match{
+		{
+			Library: "fuchsia.example",
+			Name:    "fuchsia.example/File.SetName",
+			Kind:    "method",
+			Path:    "$.protocol_declarations[0].methods[1]",
+		},
+	}
+	if diff := cmp.Diff(expected, matches); diff != "" {
+		t.Errorf("unexpected diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"widgets",
+		"methods[",
+		"methods[bogus]",
+		"methods[colour=red]",
+		"methods[name~(]",
+	} {
+		if _, err := Parse(query); err == nil {
+			t.Errorf("%q: expected an error", query)
+		}
+	}
+}
@@ -46,6 +46,10 @@ type Decl string
 // Name is the fully qualified name of the element.
 type Name string
 
+// Trace is the @trace attribute of a method, asking bindings to instrument
+// calls to the method. For example, `@trace(category="fidl", name="Foo.Bar")`.
+type Trace string
+
 // Value is a string-serialized value of the element.
 // Since for the time being the typed value is not necessary, this is quite
 // enough to pipe the element value through where needed.
@@ -72,6 +76,7 @@ type ElementStr struct {
 	Name         `json:"name"`
 	Resourceness `json:"resourceness,omitempty"`
 	Strictness   `json:"strictness,omitempty"`
+	Trace        `json:"trace,omitempty"`
 	Value        `json:"value,omitempty"`
 }
 
@@ -96,6 +101,9 @@ func (e ElementStr) String() string {
 			p = append(p, string(e.Value))
 		}
 	}
+	if e.Trace != "" {
+		p = append(p, string(e.Trace))
+	}
 	return strings.Join(p, " ")
 }
 
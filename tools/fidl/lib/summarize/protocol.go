@@ -17,7 +17,12 @@ const protocolKind Kind = "protocol"
 func (s *summarizer) addProtocols(protocols []fidlgen.Protocol) {
 	for _, p := range protocols {
 		for _, m := range p.Methods {
-			s.addElement(newMethod(&s.symbols, p.Name, m))
+			method, err := newMethod(&s.symbols, p.Name, m)
+			if err != nil {
+				// The method is still summarized, without its @trace.
+				s.addDiagnostic(method.Name(), err)
+			}
+			s.addElement(method)
 		}
 		s.addElement(protocol{named: named{name: Name(p.Name)}})
 	}
@@ -54,10 +59,12 @@ type method struct {
 	method          fidlgen.Method
 	requestPayload  parameterizer
 	responsePayload parameterizer
+	trace           *fidlgen.MethodTrace
 }
 
-// newMethod creates a new protocol method element.
-func newMethod(s *symbolTable, parent fidlgen.EncodedCompoundIdentifier, m fidlgen.Method) method {
+// newMethod creates a new protocol method element. If its @trace attribute is
+// malformed, the element is returned without it, along with the error.
+func newMethod(s *symbolTable, parent fidlgen.EncodedCompoundIdentifier, m fidlgen.Method) (method, error) {
 	out := method{
 		membership: newIsMember(s, parent, m.Name, fidlgen.ProtocolDeclType /* default value */, nil),
 		method:     m,
@@ -68,7 +75,12 @@ func newMethod(s *symbolTable, parent fidlgen.EncodedCompoundIdentifier, m fidlg
 	if m.ResponsePayload != nil {
 		out.responsePayload = s.getPayload(m.ResponsePayload.Identifier)
 	}
-	return out
+	trace, err := m.Trace()
+	if err != nil {
+		return out, err
+	}
+	out.trace = trace
+	return out, nil
 }
 
 // Name implements Element.
@@ -81,6 +93,9 @@ func (m method) Name() Name {
 func (m method) String() string {
 	e := m.Serialize()
 	// Method serialization is custom because of different spacing.
+	if e.Trace != "" {
+		return fmt.Sprintf("%v %v%v %v", e.Kind, e.Name, e.Decl, e.Trace)
+	}
 	return fmt.Sprintf("%v %v%v", e.Kind, e.Name, e.Decl)
 }
 
@@ -112,6 +127,9 @@ func (m method) Serialize() ElementStr {
 	e := m.membership.Serialize()
 	e.Kind = "protocol/member"
 	e.Decl = m.getTypeSignature()
+	if m.trace != nil {
+		e.Trace = Trace(m.trace.String())
+	}
 	return e
 }
 
@@ -65,8 +65,9 @@ func GenerateSummary(root fidlgen.Root, format SummaryFormat) ([]byte, error) {
 }
 
 // WriteSummary summarizes root and writes the serialized result to the given Writer.
+// Problems found along the way do not prevent summarization; see Diagnose.
 func WriteSummary(w io.Writer, root fidlgen.Root, format SummaryFormat) error {
-	s := summarize(root)
+	s, _ := summarize(root)
 	f, err := format.formatter()
 	if err != nil {
 		return err
@@ -74,6 +75,26 @@ func WriteSummary(w io.Writer, root fidlgen.Root, format SummaryFormat) error {
 	return f(w, s)
 }
 
+// Diagnostic is a problem found in a FIDL library while summarizing it, e.g. a
+// malformed attribute, which the summary leaves out.
+type Diagnostic struct {
+	// Name is the fully-qualified name of the element the problem is in.
+	Name Name
+	// Message describes the problem.
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Name, d.Message)
+}
+
+// Diagnose summarizes root and returns the problems found along the way, in
+// declaration order.
+func Diagnose(root fidlgen.Root) []Diagnostic {
+	_, diagnostics := summarize(root)
+	return diagnostics
+}
+
 func formatTextSummary(w io.Writer, s summary) error {
 	for _, e := range s {
 		fmt.Fprintf(w, "%v\n", e)
@@ -118,8 +139,9 @@ var _ = []Element{
 }
 
 type summarizer struct {
-	elements elementSlice
-	symbols  symbolTable
+	elements    elementSlice
+	symbols     symbolTable
+	diagnostics []Diagnostic
 }
 
 // addElement adds an element for summarization.
@@ -127,6 +149,11 @@ func (s *summarizer) addElement(e Element) {
 	s.elements = append(s.elements, e)
 }
 
+// addDiagnostic reports a problem found in the named element.
+func (s *summarizer) addDiagnostic(name Name, err error) {
+	s.diagnostics = append(s.diagnostics, Diagnostic{Name: name, Message: err.Error()})
+}
+
 // Elements obtains the API elements in this summarizer.
 func (s *summarizer) Elements() []Element {
 	// Ensure predictable ordering of the reported Elements.
@@ -245,8 +272,8 @@ func processUnions(unions []fidlgen.Union, mtum fidlgen.MethodTypeUsageMap, payl
 }
 
 // Elements returns the API elements found in the supplied AST root in a
-// canonical ordering.
-func summarize(root fidlgen.Root) summary {
+// canonical ordering, and the problems found in them.
+func summarize(root fidlgen.Root) (summary, []Diagnostic) {
 	var s summarizer
 
 	// Do a first pass of the protocols, creating a map of all names of types that
@@ -274,5 +301,5 @@ func summarize(root fidlgen.Root) summary {
 	s.addProtocols(root.Protocols)
 	s.addElement(library{r: root})
 
-	return summary(s.Elements())
+	return summary(s.Elements()), s.diagnostics
 }
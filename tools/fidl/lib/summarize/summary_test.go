@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
 )
 
@@ -665,12 +666,53 @@ strict union l/E
 table/member l/T.e int32
 table l/T
 library l
+`,
+		},
+		{
+			name: "traced method",
+			fidl: `
+library l;
+protocol P {
+    @trace(category="fidl:l", name="P.M")
+    M();
+};
+`,
+			expected: `protocol/member l/P.M() @trace(category="fidl:l", name="P.M")
+protocol l/P
+library l
 `,
 		},
 	}
 	runGenerateSummaryTests(t, tests, TextSummaryFormat)
 }
 
+func TestInvalidTrace(t *testing.T) {
+	root := fidlgen.Root{
+		Name: "l",
+		Protocols: []fidlgen.Protocol{{
+			Decl: fidlgen.Decl{Name: "l/P"},
+			Methods: []fidlgen.Method{{
+				Name:       "M",
+				HasRequest: true,
+				Attributes: fidlgen.Attributes{Attributes: []fidlgen.Attribute{{Name: "trace"}}},
+			}},
+		}},
+	}
+	// The method is summarized without its @trace.
+	actual, err := GenerateSummary(root, TextSummaryFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "protocol/member l/P.M()\nprotocol l/P\nlibrary l\n"
+	if diff := cmp.Diff(expected, string(actual)); diff != "" {
+		t.Errorf("unexpected summary (-want +got):\n%s", diff)
+	}
+	diagnostics := Diagnose(root)
+	if len(diagnostics) != 1 || diagnostics[0].Name != "l/P.M" {
+		t.Errorf("got diagnostics %v, want one for l/P.M", diagnostics)
+	}
+}
+
 func TestJSONSummaryFormat(t *testing.T) {
 	tests := []summaryTestCase{
 		{
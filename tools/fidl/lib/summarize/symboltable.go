@@ -252,43 +252,10 @@ func (t typeString) String() string {
 	return strings.Join(ret, "")
 }
 
-// handleRightsNames maps each individual HandleRights bit to a name.
-var handleRightsNames map[fidlgen.HandleRights]string = map[fidlgen.HandleRights]string{
-	fidlgen.HandleRightsNone:          "zx.NONE",
-	fidlgen.HandleRightsDuplicate:     "zx.DUPLICATE",
-	fidlgen.HandleRightsTransfer:      "zx.TRANSFER",
-	fidlgen.HandleRightsRead:          "zx.READ",
-	fidlgen.HandleRightsWrite:         "zx.WRITE",
-	fidlgen.HandleRightsExecute:       "zx.EXECUTE",
-	fidlgen.HandleRightsMap:           "zx.MAP",
-	fidlgen.HandleRightsGetProperty:   "zx.GET_PROPERTY",
-	fidlgen.HandleRightsSetProperty:   "zx.SET_PROPERTY",
-	fidlgen.HandleRightsEnumerate:     "zx.ENUMERATE",
-	fidlgen.HandleRightsDestroy:       "zx.DESTROY",
-	fidlgen.HandleRightsSetPolicy:     "zx.SET_POLICY",
-	fidlgen.HandleRightsGetPolicy:     "zx.GET_POLICY",
-	fidlgen.HandleRightsSignal:        "zx.SIGNAL",
-	fidlgen.HandleRightsSignalPeer:    "zx.SIGNAL_PEER",
-	fidlgen.HandleRightsWait:          "zx.WAIT",
-	fidlgen.HandleRightsInspect:       "zx.INSPECT",
-	fidlgen.HandleRightsManageJob:     "zx.MANAGE_JOB",
-	fidlgen.HandleRightsManageProcess: "zx.MANAGE_PROCESS",
-	fidlgen.HandleRightsManageThread:  "zx.MANAGE_THREAD",
-	fidlgen.HandleRightsApplyProfile:  "zx.APPLY_PROFILE",
-}
-
 // addHandleRights adds string representation of handle rights r into the type
-// representation.
+// representation. Same rights are left out, as they are the default.
 func (t *typeString) addHandleRights(r fidlgen.HandleRights) {
-	var right fidlgen.HandleRights
-	for right = 1; right != 0; right = right << 1 {
-		if right&r != 0 {
-			// While not all HandleRights bits have a name, ostensibly the ones that
-			// are used do have a name, and the code below will work.  If ever there
-			// is a new bit introduced without giving it a name, this will fail. But
-			// since unnamed but used bit is a bug, failing is OK.  Fix by adding the
-			// missing name to handleRightsNames above.
-			t.addConstraint(handleRightsNames[right])
-		}
+	for _, name := range (r &^ fidlgen.HandleRightsSameRights).Names() {
+		t.addConstraint("zx." + name)
 	}
 }
@@ -205,11 +205,11 @@ func (m *Measurer) createStructMeasuringTape(decl fidlgen.Struct) (*MeasuringTap
 		kind:             Struct,
 		name:             fidlgen.MustReadName(string(decl.Name)),
 		decl:             decl,
-		inlineNumBytes:   decl.TypeShapeV2.InlineSize,
+		inlineNumBytes:   decl.Shape(fidlgen.WireFormatVersionV2).InlineSize,
 		members:          membersMt,
-		hasHandles:       decl.TypeShapeV2.MaxHandles != 0,
-		hasOutOfLine:     decl.TypeShapeV2.Depth > 0,
-		inlineNumHandles: decl.TypeShapeV2.MaxHandles,
+		hasHandles:       decl.Shape(fidlgen.WireFormatVersionV2).MaxHandles != 0,
+		hasOutOfLine:     decl.Shape(fidlgen.WireFormatVersionV2).Depth > 0,
+		inlineNumHandles: decl.Shape(fidlgen.WireFormatVersionV2).MaxHandles,
 	}, nil
 }
 
@@ -235,7 +235,7 @@ func (m *Measurer) createTableMeasuringTape(decl fidlgen.Table) (*MeasuringTape,
 		name:           fidlgen.MustReadName(string(decl.Name)),
 		decl:           decl,
 		members:        membersMt,
-		hasHandles:     decl.TypeShapeV2.MaxHandles != 0,
+		hasHandles:     decl.Shape(fidlgen.WireFormatVersionV2).MaxHandles != 0,
 		hasOutOfLine:   true,
 		inlineNumBytes: 16, // sizeof(fidl_vector_t)
 	}, nil
@@ -266,7 +266,7 @@ func (m *Measurer) createUnionMeasuringTape(decl fidlgen.Union) (*MeasuringTape,
 		decl:           decl,
 		members:        membersMt,
 		isFlexible:     decl.Strictness == fidlgen.IsFlexible,
-		hasHandles:     decl.TypeShapeV2.MaxHandles != 0,
+		hasHandles:     decl.Shape(fidlgen.WireFormatVersionV2).MaxHandles != 0,
 		hasOutOfLine:   true,
 		inlineNumBytes: 16, // sizeof(fidl_xunion_v2_t)
 	}, nil
@@ -0,0 +1,116 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package zither
+
+import (
+	"fmt"
+	"strings"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// archAttribute restricts a declaration to a set of target architectures,
+// for definitions that differ by architecture, e.g.
+//
+//	@arch("x86_64")
+//	type X86ExceptionContext = struct {
+//	    vector uint64;
+//	    err_code uint64;
+//	    cr2 uint64;
+//	};
+//
+//	@arch("arm64,riscv64")
+//	type PageFaultInfo = struct {
+//	    far uint64;
+//	};
+//
+// The standalone argument is a comma-separated list of architectures. Backends
+// guard the definitions accordingly, e.g. with `#if defined(...)` in C and
+// `#[cfg(target_arch = ...)]` in Rust.
+const archAttribute fidlgen.Identifier = "arch"
+
+// Arch is a target architecture, as named in an @arch attribute.
+type Arch string
+
+const (
+	ArchX64     Arch = "x86_64"
+	ArchArm64   Arch = "arm64"
+	ArchRiscv64 Arch = "riscv64"
+)
+
+// archInfo gives the ways an architecture is identified in generated code.
+type archInfo struct {
+	// cMacro is the preprocessor macro defined by C compilers when targeting
+	// the architecture.
+	cMacro string
+
+	// rustTargetArch is the Rust `target_arch` configuration value of the
+	// architecture.
+	rustTargetArch string
+
+	// goArch is the GOARCH value of the architecture.
+	goArch string
+}
+
+var arches = map[Arch]archInfo{
+	ArchX64:     {cMacro: "__x86_64__", rustTargetArch: "x86_64", goArch: "amd64"},
+	ArchArm64:   {cMacro: "__aarch64__", rustTargetArch: "aarch64", goArch: "arm64"},
+	ArchRiscv64: {cMacro: "__riscv", rustTargetArch: "riscv64", goArch: "riscv64"},
+}
+
+// CMacro returns the preprocessor macro that C compilers define when
+// targeting the architecture.
+func (a Arch) CMacro() string {
+	return arches[a].cMacro
+}
+
+// RustTargetArch returns the Rust `target_arch` value of the architecture.
+func (a Arch) RustTargetArch() string {
+	return arches[a].rustTargetArch
+}
+
+// GoArch returns the GOARCH value of the architecture.
+func (a Arch) GoArch() string {
+	return arches[a].goArch
+}
+
+// CArchCondition returns the preprocessor condition under which a declaration
+// restricted to the given architectures is defined in C and C++, e.g.
+// `defined(__x86_64__) || defined(__aarch64__)`.
+func CArchCondition(arches []Arch) string {
+	var conds []string
+	for _, arch := range arches {
+		conds = append(conds, fmt.Sprintf("defined(%s)", arch.CMacro()))
+	}
+	return strings.Join(conds, " || ")
+}
+
+// newArches returns the architectures to which an @arch attribute restricts
+// a declaration, in the order given, or nil if there is no such attribute.
+func newArches(attrs fidlgen.Attributes) ([]Arch, error) {
+	attr, ok := attrs.LookupAttribute(archAttribute)
+	if !ok {
+		return nil, nil
+	}
+	arg, ok := attr.LookupArgStandalone()
+	if !ok {
+		return nil, fmt.Errorf("@%s expects a single comma-separated list of architectures", archAttribute)
+	}
+
+	var res []Arch
+	seen := make(map[Arch]struct{})
+	for _, name := range strings.Split(arg.ValueString(), ",") {
+		arch := Arch(strings.TrimSpace(name))
+		if _, ok := arches[arch]; !ok {
+			return nil, fmt.Errorf("@%s has an unknown architecture %q", archAttribute, arch)
+		}
+		if _, ok := seen[arch]; ok {
+			return nil, fmt.Errorf("@%s lists %s more than once", archAttribute, arch)
+		}
+		seen[arch] = struct{}{}
+		res = append(res, arch)
+	}
+	return res, nil
+}
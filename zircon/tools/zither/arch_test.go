@@ -0,0 +1,175 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package zither_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
+	"go.fuchsia.dev/fuchsia/zircon/tools/zither"
+)
+
+func TestArches(t *testing.T) {
+	ir := fidlgentest.EndToEndTest{T: t}.Single(`
+library example;
+
+@arch("x86_64")
+const PAGE_SHIFT uint32 = 12;
+
+@arch("x86_64, arm64")
+type Flags = bits : uint8 {
+    A = 1;
+};
+
+@arch("riscv64")
+type Kind = enum : uint8 {
+    A = 1;
+};
+
+@arch("arm64,riscv64")
+type Context = struct {
+    far uint64;
+};
+
+type Everywhere = struct {
+    a uint64;
+};
+
+@arch("riscv64")
+type RiscvOnly = struct {
+    kind Kind;
+    context Context;
+    everywhere Everywhere;
+};
+`)
+
+	summaries, err := zither.Summarize(ir, zither.SourceDeclOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual := make(map[string][]zither.Arch)
+	for _, decl := range summaries[0].Decls {
+		actual[decl.Name().DeclarationName()] = decl.Arches()
+	}
+	expected := map[string][]zither.Arch{
+		"PAGE_SHIFT": {zither.ArchX64},
+		"Flags":      {zither.ArchX64, zither.ArchArm64},
+		"Kind":       {zither.ArchRiscv64},
+		"Context":    {zither.ArchArm64, zither.ArchRiscv64},
+		"Everywhere": nil,
+		"RiscvOnly":  {zither.ArchRiscv64},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestArchesErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fidl string
+	}{
+		{
+			name: "unknown architecture",
+			fidl: `
+library example;
+
+@arch("x86_64,mips")
+type A = struct { a uint8; };
+`,
+		},
+		{
+			name: "duplicate architecture",
+			fidl: `
+library example;
+
+@arch("arm64,arm64")
+type A = struct { a uint8; };
+`,
+		},
+		{
+			name: "empty list",
+			fidl: `
+library example;
+
+@arch("")
+const A uint8 = 1;
+`,
+		},
+		{
+			name: "unrestricted struct depends on restricted struct",
+			fidl: `
+library example;
+
+@arch("x86_64")
+type A = struct { a uint8; };
+
+type B = struct { a A; };
+`,
+		},
+		{
+			name: "unrestricted constant depends on restricted constant",
+			fidl: `
+library example;
+
+@arch("arm64")
+const A uint8 = 1;
+
+const B uint8 = A;
+`,
+		},
+		{
+			name: "dependent restricted to other architectures",
+			fidl: `
+library example;
+
+@arch("x86_64")
+type E = enum : uint8 { A = 1; };
+
+@arch("x86_64,arm64")
+type S = struct { e E; };
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ir := fidlgentest.EndToEndTest{T: t}.Single(tc.fidl)
+			if _, err := zither.Summarize(ir, zither.SourceDeclOrder); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestArchNames(t *testing.T) {
+	for _, tc := range []struct {
+		arch                           zither.Arch
+		cMacro, rustTargetArch, goArch string
+	}{
+		{zither.ArchX64, "__x86_64__", "x86_64", "amd64"},
+		{zither.ArchArm64, "__aarch64__", "aarch64", "arm64"},
+		{zither.ArchRiscv64, "__riscv", "riscv64", "riscv64"},
+	} {
+		if tc.arch.CMacro() != tc.cMacro || tc.arch.RustTargetArch() != tc.rustTargetArch || tc.arch.GoArch() != tc.goArch {
+			t.Errorf("%s: got %q, %q and %q, want %q, %q and %q", tc.arch,
+				tc.arch.CMacro(), tc.arch.RustTargetArch(), tc.arch.GoArch(),
+				tc.cMacro, tc.rustTargetArch, tc.goArch)
+		}
+	}
+}
+
+func TestCArchCondition(t *testing.T) {
+	for _, tc := range []struct {
+		arches   []zither.Arch
+		expected string
+	}{
+		{[]zither.Arch{zither.ArchX64}, "defined(__x86_64__)"},
+		{[]zither.Arch{zither.ArchArm64, zither.ArchRiscv64}, "defined(__aarch64__) || defined(__riscv)"},
+	} {
+		if actual := zither.CArchCondition(tc.arches); actual != tc.expected {
+			t.Errorf("%v: got %q, want %q", tc.arches, actual, tc.expected)
+		}
+	}
+}
@@ -40,6 +40,7 @@ func NewGenerator(formatter fidlgen.Formatter) *Generator {
 		"BitsMemberValue":      BitsMemberValue,
 		"StructName":           StructName,
 		"StructMemberTypeInfo": StructMemberTypeInfo,
+		"ArchCondition":        zither.CArchCondition,
 	})
 	return &Generator{*gen}
 }
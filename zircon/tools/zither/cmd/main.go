@@ -19,14 +19,16 @@ import (
 	"go.fuchsia.dev/fuchsia/zircon/tools/zither"
 	"go.fuchsia.dev/fuchsia/zircon/tools/zither/c"
 	"go.fuchsia.dev/fuchsia/zircon/tools/zither/golang"
+	"go.fuchsia.dev/fuchsia/zircon/tools/zither/ktrace"
 )
 
 const (
-	cBackend  string = "c"
-	goBackend string = "go"
+	cBackend      string = "c"
+	goBackend     string = "go"
+	ktraceBackend string = "ktrace"
 )
 
-var supportedBackends = []string{cBackend, goBackend}
+var supportedBackends = []string{cBackend, goBackend, ktraceBackend}
 
 // Flag values, grouped into a struct to be kept out of the global namespace.
 var flags struct {
@@ -36,6 +38,8 @@ var flags struct {
 	outputDir       string
 	clangFormat     string
 	clangFormatArgs flagmisc.StringsValue
+	rustfmt         string
+	rustfmtConfig   string
 }
 
 func init() {
@@ -45,6 +49,8 @@ func init() {
 	flag.StringVar(&flags.outputDir, "output-dir", "", "The directory to which the bindings will be written. (The layout is backend-specific.)")
 	flag.StringVar(&flags.clangFormat, "clang-format", "", "The path to `clang-format`, used to format bindings in the appropriate backends")
 	flag.Var(&flags.clangFormatArgs, "clang-format-args", "Arguments to pass to `clang-format`, when used")
+	flag.StringVar(&flags.rustfmt, "rustfmt", "", "The path to `rustfmt`, used to format bindings in the appropriate backends")
+	flag.StringVar(&flags.rustfmtConfig, "rustfmt-config", "", "The path to the `rustfmt` configuration, when used")
 }
 
 func main() {
@@ -65,6 +71,14 @@ func main() {
 		gen = c.NewGenerator(cf)
 	case goBackend:
 		gen = golang.NewGenerator(goFormatter{})
+	case ktraceBackend:
+		cf := fidlgen.NewFormatter(flags.clangFormat, flags.clangFormatArgs...)
+		var rustfmtArgs []string
+		if flags.rustfmtConfig != "" {
+			rustfmtArgs = append(rustfmtArgs, "--config-path", flags.rustfmtConfig)
+		}
+		rf := fidlgen.NewFormatter(flags.rustfmt, rustfmtArgs...)
+		gen = ktrace.NewGenerator(cf, rf)
 	default:
 		logger.Errorf(ctx, "unrecognized `-backend` value: %q", flags.backend)
 		os.Exit(1)
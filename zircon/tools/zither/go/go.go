@@ -8,7 +8,9 @@ import (
 	"embed"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 	"text/template"
 
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
@@ -21,6 +23,7 @@ var templates embed.FS
 // Generator provides go data layout bindings.
 type Generator struct {
 	fidlgen.Generator
+	formatter fidlgen.Formatter
 }
 
 func NewGenerator(formatter fidlgen.Formatter) *Generator {
@@ -37,7 +40,7 @@ func NewGenerator(formatter fidlgen.Formatter) *Generator {
 		"StructMemberName": StructMemberName,
 		"StructMemberType": StructMemberType,
 	})
-	return &Generator{*gen}
+	return &Generator{*gen, formatter}
 }
 
 func (gen Generator) DeclOrder() zither.DeclOrder {
@@ -60,15 +63,114 @@ func (gen *Generator) Generate(summaries []zither.FileSummary, outputDir string)
 	outputs = append(outputs, pkgName)
 
 	for _, summary := range summaries {
-		output := filepath.Join(outputDir, summary.Name+".go")
-		if err := gen.GenerateFile(output, "GenerateGoFile", summary); err != nil {
-			return nil, err
+		for _, part := range partitionByArches(summary) {
+			output := filepath.Join(outputDir, part.filename())
+			if err := gen.generateGoFile(output, part); err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, output)
 		}
-		outputs = append(outputs, output)
 	}
 	return outputs, nil
 }
 
+// generateGoFile generates the Go file for a partition of a summary, with a
+// build constraint if the partition is restricted to some architectures.
+func (gen *Generator) generateGoFile(output string, part archPartition) error {
+	if len(part.arches) == 0 {
+		return gen.GenerateFile(output, "GenerateGoFile", part.summary)
+	}
+	generated, err := gen.ExecuteTemplate("GenerateGoFile", part.summary)
+	if err != nil {
+		return fmt.Errorf("Error generating content: %w", err)
+	}
+	var goArches []string
+	for _, arch := range part.arches {
+		goArches = append(goArches, arch.GoArch())
+	}
+	file := fidlgen.NewGeneratedFile(fidlgen.FileBanner{}, gen.formatter)
+	fmt.Fprintf(file, "//go:build %s\n\n", strings.Join(goArches, " || "))
+	file.Write(generated)
+	return file.WriteFile(output)
+}
+
+// archPartition is the subset of the declarations of a file summary that are
+// defined for the same set of architectures. Go has no conditional
+// compilation within a file, so each such subset is emitted as its own file
+// under a build constraint.
+type archPartition struct {
+	summary zither.FileSummary
+
+	// arches is the set of architectures to which the declarations are
+	// restricted, ordered by GOARCH, or empty if they are unrestricted.
+	arches []zither.Arch
+}
+
+// filename returns the name of the file generated for the partition:
+// `${name}.go` if unrestricted, and otherwise `${name}.${goarch1}...go`. The
+// GOARCH values are separated by dots rather than underscores so as not to
+// form an implicit `_${GOARCH}.go` filename constraint.
+func (part archPartition) filename() string {
+	name := part.summary.Name
+	for _, arch := range part.arches {
+		name += "." + arch.GoArch()
+	}
+	return name + ".go"
+}
+
+// partitionByArches splits a file summary by the architectures to which its
+// declarations are restricted. The unrestricted partition always comes first,
+// even if empty, so that every summary yields a `${name}.go`; the restricted
+// partitions follow in the order in which their arch sets first appear.
+func partitionByArches(summary zither.FileSummary) []archPartition {
+	parts := []archPartition{{summary: emptySummary(summary)}}
+	index := map[string]int{"": 0}
+	for _, decl := range summary.Decls {
+		arches := append([]zither.Arch(nil), decl.Arches()...)
+		sort.Slice(arches, func(i, j int) bool {
+			return arches[i].GoArch() < arches[j].GoArch()
+		})
+		var key []string
+		for _, arch := range arches {
+			key = append(key, string(arch))
+		}
+		i, ok := index[strings.Join(key, ",")]
+		if !ok {
+			i = len(parts)
+			index[strings.Join(key, ",")] = i
+			parts = append(parts, archPartition{summary: emptySummary(summary), arches: arches})
+		}
+		parts[i].summary.Decls = append(parts[i].summary.Decls, decl)
+		addTypeKinds(parts[i].summary.TypeKinds, decl)
+	}
+	return parts
+}
+
+// emptySummary returns a copy of a summary without its declarations.
+func emptySummary(summary zither.FileSummary) zither.FileSummary {
+	summary.Decls = nil
+	summary.TypeKinds = make(map[zither.TypeKind]struct{})
+	return summary
+}
+
+// addTypeKinds records the kinds of types a declaration contains, as
+// zither.Summarize does for a whole file, so that a partition only makes the
+// imports its own declarations need.
+func addTypeKinds(kinds map[zither.TypeKind]struct{}, decl zither.Decl) {
+	switch {
+	case decl.IsConst():
+		kinds[decl.AsConst().Kind] = struct{}{}
+	case decl.IsEnum(), decl.IsBits():
+		kinds[zither.TypeKindInteger] = struct{}{}
+	case decl.IsStruct():
+		for _, member := range decl.AsStruct().Members {
+			for desc := &member.Type; desc != nil; desc = desc.ElementType {
+				kinds[desc.Kind] = struct{}{}
+			}
+		}
+	}
+}
+
 //
 // Template functions.
 //
@@ -0,0 +1,110 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package zither
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+)
+
+// ktraceRecordAttribute marks a struct as defining the layout of a kernel
+// trace record, e.g.
+//
+//	/// Records a context switch.
+//	@ktrace_record(0x12)
+//	type ContextSwitch = struct {
+//	    from_tid uint64;
+//	    to_tid uint64;
+//	};
+//
+// The standalone argument gives the record type, which must be unique within
+// the library.
+const ktraceRecordAttribute fidlgen.Identifier = "ktrace_record"
+
+// KtraceRecordInfo gives the particulars of a struct defining the layout of a
+// kernel trace (ktrace) record.
+type KtraceRecordInfo struct {
+	// Type is the record type, which identifies the layout of a record in a
+	// trace buffer.
+	Type uint32
+
+	// Size is the size of the record in bytes.
+	Size int
+
+	// MemberOffsets gives the byte offset of each member within the record,
+	// in member order. Writers copy the members one by one at these offsets,
+	// so that padding is never copied out of a record.
+	MemberOffsets []int
+}
+
+func newKtraceRecordInfo(attr fidlgen.Attribute, strct fidlgen.Struct) (*KtraceRecordInfo, error) {
+	arg, ok := attr.LookupArgStandalone()
+	if !ok {
+		return nil, fmt.Errorf("@%s expects a single record type argument", ktraceRecordAttribute)
+	}
+	typ, err := strconv.ParseUint(arg.ValueString(), 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("@%s has a malformed record type %q: %w", ktraceRecordAttribute, arg.ValueString(), err)
+	}
+	info := &KtraceRecordInfo{Type: uint32(typ), Size: strct.TypeShapeV2.InlineSize}
+	for _, m := range strct.Members {
+		info.MemberOffsets = append(info.MemberOffsets, m.FieldShapeV2.Offset)
+	}
+	return info, nil
+}
+
+// isKtraceRecordMemberType returns whether a type may be that of a member of a
+// ktrace record. Records are read from and written to trace buffers as raw
+// bytes, so their members are restricted to integers and arrays thereof, for
+// which any bit pattern is valid.
+func isKtraceRecordMemberType(desc TypeDescriptor) bool {
+	switch desc.Kind {
+	case TypeKindInteger:
+		return true
+	case TypeKindArray:
+		return isKtraceRecordMemberType(*desc.ElementType)
+	default:
+		return false
+	}
+}
+
+// KtraceRecords returns the structs of the given files that define ktrace
+// records, ordered by record type. This gives the record-type registry of the
+// library. An error is returned if two records share a type or if a record
+// has a member of an unsupported type.
+func KtraceRecords(summaries []FileSummary) ([]Struct, error) {
+	var records []Struct
+	for _, summary := range summaries {
+		for _, decl := range summary.Decls {
+			if !decl.IsStruct() {
+				continue
+			}
+			s := decl.AsStruct()
+			if s.KtraceRecord == nil {
+				continue
+			}
+			for _, m := range s.Members {
+				if !isKtraceRecordMemberType(m.Type) {
+					return nil, fmt.Errorf("%s.%s: ktrace record members must be integers or arrays of integers", s.Name, m.Name)
+				}
+			}
+			records = append(records, s)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].KtraceRecord.Type < records[j].KtraceRecord.Type
+	})
+	for i := 1; i < len(records); i++ {
+		if records[i-1].KtraceRecord.Type == records[i].KtraceRecord.Type {
+			return nil, fmt.Errorf("%s and %s have the same ktrace record type: %#x",
+				records[i-1].Name, records[i].Name, records[i].KtraceRecord.Type)
+		}
+	}
+	return records, nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package ktrace
+
+import (
+	"embed"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/zircon/tools/zither"
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+// Generator provides C++ and Rust readers and writers of kernel trace (ktrace)
+// records, along with the registry of record types.
+type Generator struct {
+	cpp  fidlgen.Generator
+	rust fidlgen.Generator
+}
+
+func NewGenerator(cppFormatter, rustFormatter fidlgen.Formatter) *Generator {
+	funcs := template.FuncMap{
+		"HeaderGuard":        HeaderGuard,
+		"CppNamespace":       CppNamespace,
+		"RecordName":         RecordName,
+		"RecordType":         RecordType,
+		"CppRecordTypeName":  CppRecordTypeName,
+		"CppMemberTypeInfo":  CppMemberTypeInfo,
+		"RustMemberTypeName": RustMemberTypeName,
+		"ArchCondition":      zither.CArchCondition,
+		"RustArchCfg":        RustArchCfg,
+	}
+	return &Generator{
+		cpp:  *fidlgen.NewGenerator("KtraceCppTemplates", templates, cppFormatter, funcs),
+		rust: *fidlgen.NewGenerator("KtraceRustTemplates", templates, rustFormatter, funcs),
+	}
+}
+
+func (gen Generator) DeclOrder() zither.DeclOrder {
+	return zither.DependencyDeclOrder
+}
+
+// File is the data from which the record definitions of a library are
+// generated.
+type File struct {
+	// Library is the FIDL library defining the records.
+	Library fidlgen.LibraryName
+
+	// Records are the structs defining the records, ordered by record type.
+	Records []zither.Struct
+}
+
+func (gen *Generator) Generate(summaries []zither.FileSummary, outputDir string) ([]string, error) {
+	records, err := zither.KtraceRecords(summaries)
+	if err != nil {
+		return nil, err
+	}
+	file := File{Library: summaries[0].Library, Records: records}
+	outputDir = filepath.Join(outputDir, filepath.Join(file.Library.Parts()...))
+
+	var outputs []string
+	cppOutput := filepath.Join(outputDir, "ktrace.h")
+	if err := gen.cpp.GenerateFile(cppOutput, "GenerateCppFile", file); err != nil {
+		return nil, err
+	}
+	outputs = append(outputs, cppOutput)
+
+	rustOutput := filepath.Join(outputDir, "ktrace.rs")
+	if err := gen.rust.GenerateFile(rustOutput, "GenerateRustFile", file); err != nil {
+		return nil, err
+	}
+	outputs = append(outputs, rustOutput)
+	return outputs, nil
+}
+
+//
+// Template functions.
+//
+
+// HeaderGuard returns the header guard preprocessor variable for the C++
+// header of a library.
+func HeaderGuard(lib fidlgen.LibraryName) string {
+	parts := append(lib.Parts(), "ktrace", "h")
+	return fidlgen.ConstNameToAllCapsSnake(strings.Join(parts, "_")) + "_"
+}
+
+// CppNamespace returns the C++ namespace in which the records of a library
+// are defined.
+func CppNamespace(lib fidlgen.LibraryName) string {
+	return strings.Join(lib.Parts(), "::")
+}
+
+// RecordName returns the type name of a generated record, in both C++ and
+// Rust.
+func RecordName(s zither.Struct) string {
+	return fidlgen.ToUpperCamelCase(s.Name.DeclarationName())
+}
+
+// RecordType returns the record type of a record, in hexadecimal.
+func RecordType(s zither.Struct) string {
+	return fmt.Sprintf("%#x", s.KtraceRecord.Type)
+}
+
+// CppRecordTypeName returns the name of the C++ enumerator of the record type
+// of a record.
+func CppRecordTypeName(s zither.Struct) string {
+	return "k" + RecordName(s)
+}
+
+// TypeInfo gives a basic description of a C++ type, accounting for array
+// nesting.
+type TypeInfo struct {
+	// Type is the underlying type, modulo array nesting.
+	Type string
+
+	// ArraySuffix is the suffix to append to the member name to indicate any
+	// array nesting, e.g. "[4][5]".
+	ArraySuffix string
+}
+
+func cppPrimitiveTypeName(typ fidlgen.PrimitiveSubtype) string {
+	switch typ {
+	case fidlgen.Int8, fidlgen.Int16, fidlgen.Int32, fidlgen.Int64,
+		fidlgen.Uint8, fidlgen.Uint16, fidlgen.Uint32, fidlgen.Uint64:
+		return string(typ) + "_t"
+	default:
+		panic(fmt.Sprintf("unsupported ktrace record member type: %s", typ))
+	}
+}
+
+// CppMemberTypeInfo returns the C++ type info of a record member.
+func CppMemberTypeInfo(member zither.StructMember) TypeInfo {
+	var suffix string
+	desc := member.Type
+	for desc.Kind == zither.TypeKindArray {
+		suffix += fmt.Sprintf("[%d]", *desc.ElementCount)
+		desc = *desc.ElementType
+	}
+	return TypeInfo{
+		Type:        cppPrimitiveTypeName(fidlgen.PrimitiveSubtype(desc.Type)),
+		ArraySuffix: suffix,
+	}
+}
+
+func rustTypeName(desc zither.TypeDescriptor) string {
+	switch desc.Kind {
+	case zither.TypeKindInteger:
+		// "uint32" -> "u32", "int32" -> "i32".
+		typ := string(desc.Type)
+		if strings.HasPrefix(typ, "u") {
+			return "u" + strings.TrimPrefix(typ, "uint")
+		}
+		return "i" + strings.TrimPrefix(typ, "int")
+	case zither.TypeKindArray:
+		return fmt.Sprintf("[%s; %d]", rustTypeName(*desc.ElementType), *desc.ElementCount)
+	default:
+		panic(fmt.Sprintf("unsupported ktrace record member type kind: %s", desc.Kind))
+	}
+}
+
+// RustMemberTypeName returns the Rust type name of a record member.
+func RustMemberTypeName(member zither.StructMember) string {
+	return rustTypeName(member.Type)
+}
+
+// RustArchCfg returns the cfg attribute restricting a Rust item to the given
+// architectures, e.g. `#[cfg(any(target_arch = "x86_64", target_arch =
+// "aarch64"))]`.
+func RustArchCfg(arches []zither.Arch) string {
+	var preds []string
+	for _, arch := range arches {
+		preds = append(preds, fmt.Sprintf("target_arch = %q", arch.RustTargetArch()))
+	}
+	if len(preds) == 1 {
+		return fmt.Sprintf("#[cfg(%s)]", preds[0])
+	}
+	return fmt.Sprintf("#[cfg(any(%s))]", strings.Join(preds, ", "))
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package zither_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
+	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgentest"
+	"go.fuchsia.dev/fuchsia/zircon/tools/zither"
+)
+
+func TestKtraceRecords(t *testing.T) {
+	ir := fidlgentest.EndToEndTest{T: t}.Single(`
+library example;
+
+/// Records a context switch.
+@ktrace_record(0x12)
+type ContextSwitch = struct {
+	from_tid uint64;
+	to_tid uint64;
+};
+
+@ktrace_record(0x3)
+type Irq = struct {
+	vectors array<uint32, 2>;
+};
+
+type NotARecord = struct {
+	b bool;
+};
+`)
+
+	summaries, err := zither.Summarize(ir, zither.SourceDeclOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := zither.KtraceRecords(summaries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	two := 2
+	expected := []zither.Struct{
+		{
+			Name: fidlgen.MustReadName("example/Irq"),
+			Members: []zither.StructMember{
+				{
+					Name: "vectors",
+					Type: zither.TypeDescriptor{
+						Kind: zither.TypeKindArray,
+						ElementType: &zither.TypeDescriptor{
+							Type: "uint32",
+							Kind: zither.TypeKindInteger,
+						},
+						ElementCount: &two,
+					},
+				},
+			},
+			KtraceRecord: &zither.KtraceRecordInfo{Type: 0x3, Size: 8, MemberOffsets: []int{0}},
+		},
+		{
+			Name: fidlgen.MustReadName("example/ContextSwitch"),
+			Members: []zither.StructMember{
+				{
+					Name: "from_tid",
+					Type: zither.TypeDescriptor{Type: "uint64", Kind: zither.TypeKindInteger},
+				},
+				{
+					Name: "to_tid",
+					Type: zither.TypeDescriptor{Type: "uint64", Kind: zither.TypeKindInteger},
+				},
+			},
+			KtraceRecord: &zither.KtraceRecordInfo{Type: 0x12, Size: 16, MemberOffsets: []int{0, 8}},
+			Comments:     []string{" Records a context switch."},
+		},
+	}
+	if diff := cmp.Diff(expected, records, cmpNameOpt); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestKtraceRecordsErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		fidl string
+	}{
+		{
+			name: "duplicate record type",
+			fidl: `
+library example;
+
+@ktrace_record(1)
+type A = struct { a uint8; };
+
+@ktrace_record(1)
+type B = struct { b uint8; };
+`,
+		},
+		{
+			name: "unsupported member type",
+			fidl: `
+library example;
+
+@ktrace_record(1)
+type A = struct { b bool; };
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ir := fidlgentest.EndToEndTest{T: t}.Single(tc.fidl)
+			summaries, err := zither.Summarize(ir, zither.SourceDeclOrder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := zither.KtraceRecords(summaries); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The Fuchsia Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+// DO NOT EDIT. Generated from FIDL library
+//   zither.arch
+// by zither, a Fuchsia platform tool.
+
+#ifndef ZITHER_ARCH_ARCH_H_
+#define ZITHER_ARCH_ARCH_H_
+
+#include <stdint.h>
+
+#if defined(__cplusplus)
+extern "C" {
+#endif
+
+// Defined for all architectures.
+#define ZITHER_ARCH_PAGE_SIZE_SHIFT ((uint32_t)(12u))
+
+#if defined(__x86_64__)
+
+// Only defined for x86-64.
+#define ZITHER_ARCH_X86_LARGE_PAGE_SIZE ((uint32_t)(0x200000u))
+
+#endif  // defined(__x86_64__)
+
+#if defined(__aarch64__) || defined(__riscv)
+
+typedef uint8_t zither_arch_fault_kind_t;
+
+#define ZITHER_ARCH_FAULT_KIND_READ ((zither_arch_fault_kind_t)(1u))
+#define ZITHER_ARCH_FAULT_KIND_WRITE ((zither_arch_fault_kind_t)(2u))
+
+#endif  // defined(__aarch64__) || defined(__riscv)
+
+#if defined(__aarch64__)
+
+typedef uint32_t zither_arch_arm_features_t;
+
+#define ZITHER_ARCH_ARM_FEATURES_FP ((zither_arch_arm_features_t)(1u << 0))
+#define ZITHER_ARCH_ARM_FEATURES_ASIMD ((zither_arch_arm_features_t)(1u << 1))
+
+#endif  // defined(__aarch64__)
+
+#if defined(__x86_64__)
+
+// The exception context on x86-64.
+typedef struct {
+  uint64_t vector;
+  uint64_t err_code;
+  uint64_t cr2;
+} zither_arch_x86_exception_context_t;
+
+#endif  // defined(__x86_64__)
+
+#if defined(__aarch64__) || defined(__riscv)
+
+typedef struct {
+  uint64_t far;
+  zither_arch_fault_kind_t kind;
+} zither_arch_page_fault_info_t;
+
+#endif  // defined(__aarch64__) || defined(__riscv)
+
+#if defined(__cplusplus)
+}
+#endif
+
+#endif  // ZITHER_ARCH_ARCH_H_
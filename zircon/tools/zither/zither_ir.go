@@ -7,15 +7,12 @@ package zither
 
 import (
 	"fmt"
-	"math/bits"
 	"path/filepath"
 	"reflect"
 	"sort"
-	"strconv"
 	"strings"
 
 	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen"
-	"go.fuchsia.dev/fuchsia/tools/fidl/lib/fidlgen_cpp"
 )
 
 // DeclOrder represents the ordering policy for declarations as they are
@@ -89,6 +86,23 @@ func (decl Decl) Name() fidlgen.Name {
 	}
 }
 
+// Arches returns the architectures to which the declaration is restricted by
+// an @arch attribute. If empty, the declaration is defined for all of them.
+func (decl Decl) Arches() []Arch {
+	switch decl := decl.value.(type) {
+	case *Const:
+		return decl.Arches
+	case *Enum:
+		return decl.Arches
+	case *Bits:
+		return decl.Arches
+	case *Struct:
+		return decl.Arches
+	default:
+		panic(fmt.Sprintf("unknown declaration type: %s", reflect.TypeOf(decl).Name()))
+	}
+}
+
 func (decl Decl) IsConst() bool {
 	_, ok := decl.value.(*Const)
 	return ok
@@ -140,9 +154,10 @@ func Summarize(ir fidlgen.Root, order DeclOrder) ([]FileSummary, error) {
 	// process a particular declaration we will have full knowledge of is
 	// dependencies, and by extension itself. This ordering exists just for
 	// ease of processing and is independent of that prescribed by `order`.
-	g := fidlgen_cpp.NewDeclDepGraph(ir)
+	g := fidlgen.NewDeclDepGraph(ir)
 	decls := g.SortedDecls()
 	processed := make(declMap)
+	archesByName := make(map[fidlgen.EncodedCompoundIdentifier][]Arch)
 
 	filesByName := make(map[string]*FileSummary)
 	getFile := func(decl fidlgen.Declaration) *FileSummary {
@@ -188,8 +203,13 @@ func Summarize(ir fidlgen.Root, order DeclOrder) ([]FileSummary, error) {
 			return nil, err
 		}
 
-		file := getFile(decl)
 		d := Decl{summarized}
+		if err := checkArchDeps(d, decl.GetName(), g, archesByName); err != nil {
+			return nil, err
+		}
+		archesByName[decl.GetName()] = d.Arches()
+
+		file := getFile(decl)
 		file.Decls = append(file.Decls, d)
 		for kind := range typeKinds {
 			file.TypeKinds[kind] = struct{}{}
@@ -267,10 +287,56 @@ type Const struct {
 	// included only when it meaningfully differs from the value.
 	Expression string
 
+	// Arches restricts the constant to these architectures, if any.
+	Arches []Arch
+
 	// Comments comprise the original docstring of the FIDL declaration.
 	Comments []string
 }
 
+// checkArchDeps ensures that a declaration is only defined where its
+// dependencies are: a declaration that references one restricted by @arch must
+// itself be restricted to a subset of the same architectures.
+func checkArchDeps(decl Decl, name fidlgen.EncodedCompoundIdentifier, g fidlgen.DeclDepGraph, archesByName map[fidlgen.EncodedCompoundIdentifier][]Arch) error {
+	deps, ok := g.GetDirectDependencies(name)
+	if !ok {
+		panic(fmt.Sprintf("%s not found in declaration graph", name))
+	}
+	for _, dep := range deps {
+		depArches := archesByName[dep]
+		if len(depArches) == 0 {
+			continue
+		}
+		arches := decl.Arches()
+		if len(arches) == 0 {
+			return fmt.Errorf("%s is defined for all architectures, but depends on %s, which is only defined for %s", name, dep, archList(depArches))
+		}
+		for _, arch := range arches {
+			if !containsArch(depArches, arch) {
+				return fmt.Errorf("%s is defined for %s, but depends on %s, which is only defined for %s", name, arch, dep, archList(depArches))
+			}
+		}
+	}
+	return nil
+}
+
+func containsArch(arches []Arch, arch Arch) bool {
+	for _, a := range arches {
+		if a == arch {
+			return true
+		}
+	}
+	return false
+}
+
+func archList(arches []Arch) string {
+	var names []string
+	for _, arch := range arches {
+		names = append(names, string(arch))
+	}
+	return strings.Join(names, ",")
+}
+
 func newConst(c fidlgen.Const, decls declMap) (*Const, error) {
 	var kind TypeKind
 	var typ string
@@ -317,7 +383,9 @@ func newConst(c fidlgen.Const, decls declMap) (*Const, error) {
 		// intended for the number to be understood in binary or hex, then the
 		// generated code should preserve that.
 		if kind == TypeKindInteger {
-			value = expr
+			if spelling, ok := c.Value.NumericSpelling(); ok {
+				value = spelling.String()
+			}
 		}
 		expr = ""
 	case fidlgen.IdentifierConstant:
@@ -329,6 +397,11 @@ func newConst(c fidlgen.Const, decls declMap) (*Const, error) {
 		expr = ""
 	}
 
+	arches, err := newArches(c.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
 	return &Const{
 		Kind:       kind,
 		Type:       typ,
@@ -336,6 +409,7 @@ func newConst(c fidlgen.Const, decls declMap) (*Const, error) {
 		Value:      value,
 		Identifier: ident,
 		Expression: expr,
+		Arches:     arches,
 		Comments:   c.DocComments(),
 	}, nil
 }
@@ -351,6 +425,9 @@ type Enum struct {
 	// Members is the list of member values of the enum.
 	Members []EnumMember
 
+	// Arches restricts the enum to these architectures, if any.
+	Arches []Arch
+
 	// Comments that comprise the original docstring of the FIDL declaration.
 	Comments []string
 }
@@ -373,9 +450,15 @@ func newEnum(enum fidlgen.Enum) (*Enum, error) {
 		return nil, err
 	}
 
+	arches, err := newArches(enum.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
 	e := &Enum{
 		Subtype:  enum.Type,
 		Name:     name,
+		Arches:   arches,
 		Comments: enum.DocComments(),
 	}
 	for _, member := range enum.Members {
@@ -399,6 +482,9 @@ type Bits struct {
 	// Members is the list of member values of the bitset.
 	Members []BitsMember
 
+	// Arches restricts the bitset to these architectures, if any.
+	Arches []Arch
+
 	// Comments that comprise the original docstring of the FIDL declaration.
 	Comments []string
 }
@@ -421,34 +507,33 @@ func newBits(bits fidlgen.Bits) (*Bits, error) {
 		return nil, err
 	}
 
+	arches, err := newArches(bits.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
 	b := &Bits{
 		Subtype:  bits.Type.PrimitiveSubtype,
 		Name:     name,
+		Arches:   arches,
 		Comments: bits.DocComments(),
 	}
 
 	for _, member := range bits.Members {
-		val, err := strconv.ParseUint(member.Value.Value, 10, 64)
+		index, err := member.Index()
 		if err != nil {
-			panic(fmt.Sprintf("%v member %s has bad value %q: %v", name, member.Name, member.Value.Value, err))
+			return nil, fmt.Errorf("%s: %w", name, err)
 		}
 
 		b.Members = append(b.Members, BitsMember{
 			Name:     string(member.Name),
-			Index:    log2(val),
+			Index:    index,
 			Comments: member.DocComments(),
 		})
 	}
 	return b, nil
 }
 
-func log2(n uint64) int {
-	if bits.OnesCount64(n) != 1 {
-		panic(fmt.Sprintf("%d is not a power of two", n))
-	}
-	return bits.TrailingZeros64(n)
-}
-
 // TypeDescriptor gives a straightforward encoding of a type, accounting for
 // any array nesting with a recursive pointer to a descriptor describing the
 // element type.
@@ -519,6 +604,13 @@ type Struct struct {
 	// Members is the list of the members of the layout.
 	Members []StructMember
 
+	// KtraceRecord is non-nil if the struct defines the layout of a kernel
+	// trace (ktrace) record, as marked by a @ktrace_record attribute.
+	KtraceRecord *KtraceRecordInfo
+
+	// Arches restricts the struct to these architectures, if any.
+	Arches []Arch
+
 	// Comments that comprise the original docstring of the FIDL declaration.
 	Comments []string
 }
@@ -560,6 +652,19 @@ func newStruct(strct fidlgen.Struct, decls declMap, typeKinds map[TypeKind]struc
 			Comments: m.DocComments(),
 		})
 	}
-	return s, nil
 
+	if attr, ok := strct.LookupAttribute(ktraceRecordAttribute); ok {
+		info, err := newKtraceRecordInfo(attr, strct)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		s.KtraceRecord = info
+	}
+
+	arches, err := newArches(strct.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Name, err)
+	}
+	s.Arches = arches
+	return s, nil
 }